@@ -7,39 +7,135 @@
 package config
 
 import (
+	"crypto"
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/joho/godotenv"
 )
 
+// DBConfig holds the connection settings and the limits of the connection
+// pool. At startup the database is pinged up to ConnectRetries more times,
+// with a wait starting at ConnectBackoff that doubles after every attempt.
 type DBConfig struct {
-	Host     string
-	Port     int
-	Username string
-	Password string
-	Database string
-	Driver   string
+	Host            string
+	Port            int
+	Username        string
+	Password        string
+	Database        string
+	Driver          string
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	ConnectRetries  int
+	ConnectBackoff  time.Duration
 }
 
+// APIConfig configures the HTTP server. ShutdownTimeout is how long in-flight
+// requests may take to finish after SIGTERM, keep it below the grace period
+// of the orchestrator.
 type APIConfig struct {
-	Port string
+	Port            string
+	TrustedProxies  []string
+	ShutdownTimeout time.Duration
 }
 
 type TokenConfig struct {
-	ApplicationName     string
-	JwtSignatureKey     []byte
-	JwtSignedMethod     *jwt.SigningMethodHMAC
-	AccessTokenLifetime time.Duration
+	ApplicationName      string
+	JwtSignatureKey      []byte
+	JwtSignedMethod      jwt.SigningMethod
+	JwtKeyID             string
+	JwtPrivateKey        crypto.PrivateKey
+	JwtVerificationKeys  map[string]crypto.PublicKey
+	AccessTokenLifetime  time.Duration
+	RefreshTokenLifetime time.Duration
+}
+
+// LoginThrottleConfig controls brute-force protection on /login. Store is
+// "postgres" (shared by every instance) or "memory".
+type LoginThrottleConfig struct {
+	Store           string
+	MaxAttempts     int
+	IPMaxAttempts   int
+	Window          time.Duration
+	LockoutDuration time.Duration
+	BaseDelay       time.Duration
+}
+
+type SecurityConfig struct {
+	PermissionCacheTTL time.Duration
+	// RevocationCacheTTL bounds how long an instance may keep accepting a
+	// token that was revoked on another instance.
+	RevocationCacheTTL time.Duration
+	MfaRequiredRoles   []string
+	LoginThrottle      LoginThrottleConfig
+}
+
+// OAuthConfig configures the OAuth2 authorization server. Issuer is the
+// public base URL of this service and ends up in the iss claim and the
+// discovery document.
+type OAuthConfig struct {
+	Issuer                    string
+	AuthorizationCodeLifetime time.Duration
+}
+
+// PasswordConfig selects the password hash and its parameters, and the policy
+// for new passwords. Argon2Memory is in KiB.
+type PasswordConfig struct {
+	Algorithm         string
+	BcryptCost        int
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+	MinLength         int
+	MaxLength         int
+	BreachedListFile  string
+}
+
+// MailConfig selects how mail is delivered. Driver is "smtp" or "outbox",
+// which writes every message to a file in OutboxDir instead of sending it.
+type MailConfig struct {
+	Driver       string
+	From         string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	OutboxDir    string
+}
+
+// AccountConfig configures the email verification and forgot password flows.
+// The token is appended to the URLs as the token query parameter.
+type AccountConfig struct {
+	VerifyEmailURL            string
+	ResetPasswordURL          string
+	VerificationTokenLifetime time.Duration
+	ResetTokenLifetime        time.Duration
+}
+
+// AuditConfig selects where audit events are written. Sink is "postgres" or
+// "file", which appends JSON lines to File. Only the postgres sink can be
+// searched through /audit-events.
+type AuditConfig struct {
+	Sink string
+	File string
 }
 
 type Config struct {
-	DB    DBConfig
-	API   APIConfig
-	Token TokenConfig
+	DB       DBConfig
+	API      APIConfig
+	Token    TokenConfig
+	Security SecurityConfig
+	OAuth    OAuthConfig
+	Password PasswordConfig
+	Mail     MailConfig
+	Account  AccountConfig
+	Audit    AuditConfig
 }
 
 func (c *Config) readConfig() error {
@@ -54,17 +150,200 @@ func (c *Config) readConfig() error {
 	c.DB.Password = os.Getenv("DB_PASSWORD")
 	c.DB.Database = os.Getenv("DB_DATABASE")
 	c.DB.Driver = os.Getenv("DB_DRIVER")
+	c.DB.MaxOpenConns, _ = strconv.Atoi(os.Getenv("DB_MAX_OPEN_CONNS"))
+	if c.DB.MaxOpenConns == 0 {
+		c.DB.MaxOpenConns = 25
+	}
+	c.DB.MaxIdleConns, _ = strconv.Atoi(os.Getenv("DB_MAX_IDLE_CONNS"))
+	if c.DB.MaxIdleConns == 0 {
+		c.DB.MaxIdleConns = 10
+	}
+	// recycling connections lets the pool follow failovers and rebalanced poolers
+	c.DB.ConnMaxLifetime, _ = time.ParseDuration(os.Getenv("DB_CONN_MAX_LIFETIME"))
+	if c.DB.ConnMaxLifetime == 0 {
+		c.DB.ConnMaxLifetime = 30 * time.Minute
+	}
+	c.DB.ConnMaxIdleTime, _ = time.ParseDuration(os.Getenv("DB_CONN_MAX_IDLE_TIME"))
+	if c.DB.ConnMaxIdleTime == 0 {
+		c.DB.ConnMaxIdleTime = 5 * time.Minute
+	}
+	// set DB_CONNECT_RETRIES=0 to fail right away when the database is down
+	c.DB.ConnectRetries = 10
+	if retries, ok := os.LookupEnv("DB_CONNECT_RETRIES"); ok {
+		c.DB.ConnectRetries, _ = strconv.Atoi(retries)
+	}
+	c.DB.ConnectBackoff, _ = time.ParseDuration(os.Getenv("DB_CONNECT_BACKOFF"))
+	if c.DB.ConnectBackoff == 0 {
+		c.DB.ConnectBackoff = time.Second
+	}
 
 	c.API.Port = os.Getenv("API_PORT")
+	// client IPs are taken from X-Forwarded-For only when the request comes through one of these proxies
+	c.API.TrustedProxies = splitList(os.Getenv("API_TRUSTED_PROXIES"))
+	c.API.ShutdownTimeout, _ = time.ParseDuration(os.Getenv("API_SHUTDOWN_TIMEOUT"))
+	if c.API.ShutdownTimeout == 0 {
+		c.API.ShutdownTimeout = 20 * time.Second
+	}
 
 	c.Token.ApplicationName = os.Getenv("TOKEN_APPLICATION_NAME")
 	c.Token.JwtSignatureKey = []byte(os.Getenv("TOKEN_JWT_SIGNATURE_KEY"))
-	c.Token.JwtSignedMethod = jwt.SigningMethodHS256
+	if err := c.readSigningKeys(); err != nil {
+		return err
+	}
 	c.Token.AccessTokenLifetime, _ = time.ParseDuration(os.Getenv("TOKEN_ACCESS_TOKEN_LIFETIME"))
+	if c.Token.AccessTokenLifetime == 0 {
+		c.Token.AccessTokenLifetime = time.Hour
+	}
+	c.Token.RefreshTokenLifetime, _ = time.ParseDuration(os.Getenv("TOKEN_REFRESH_TOKEN_LIFETIME"))
+	if c.Token.RefreshTokenLifetime == 0 {
+		c.Token.RefreshTokenLifetime = 7 * 24 * time.Hour
+	}
+
+	c.Security.PermissionCacheTTL, _ = time.ParseDuration(os.Getenv("SECURITY_PERMISSION_CACHE_TTL"))
+	if c.Security.PermissionCacheTTL == 0 {
+		c.Security.PermissionCacheTTL = 5 * time.Minute
+	}
+	c.Security.RevocationCacheTTL, _ = time.ParseDuration(os.Getenv("SECURITY_REVOCATION_CACHE_TTL"))
+	if c.Security.RevocationCacheTTL == 0 {
+		c.Security.RevocationCacheTTL = 10 * time.Second
+	}
+
+	// set SECURITY_MFA_REQUIRED_ROLES to an empty value to not require MFA for any role
+	c.Security.MfaRequiredRoles = []string{"admin"}
+	if roles, ok := os.LookupEnv("SECURITY_MFA_REQUIRED_ROLES"); ok {
+		c.Security.MfaRequiredRoles = splitList(roles)
+	}
+
+	c.Security.LoginThrottle.Store = os.Getenv("SECURITY_LOGIN_ATTEMPT_STORE")
+	if c.Security.LoginThrottle.Store == "" {
+		c.Security.LoginThrottle.Store = "postgres"
+	}
+	c.Security.LoginThrottle.MaxAttempts, _ = strconv.Atoi(os.Getenv("SECURITY_LOGIN_MAX_ATTEMPTS"))
+	if c.Security.LoginThrottle.MaxAttempts == 0 {
+		c.Security.LoginThrottle.MaxAttempts = 5
+	}
+	c.Security.LoginThrottle.IPMaxAttempts, _ = strconv.Atoi(os.Getenv("SECURITY_LOGIN_IP_MAX_ATTEMPTS"))
+	if c.Security.LoginThrottle.IPMaxAttempts == 0 {
+		c.Security.LoginThrottle.IPMaxAttempts = 20
+	}
+	c.Security.LoginThrottle.Window, _ = time.ParseDuration(os.Getenv("SECURITY_LOGIN_ATTEMPT_WINDOW"))
+	if c.Security.LoginThrottle.Window == 0 {
+		c.Security.LoginThrottle.Window = 15 * time.Minute
+	}
+	c.Security.LoginThrottle.LockoutDuration, _ = time.ParseDuration(os.Getenv("SECURITY_LOGIN_LOCKOUT_DURATION"))
+	if c.Security.LoginThrottle.LockoutDuration == 0 {
+		c.Security.LoginThrottle.LockoutDuration = 15 * time.Minute
+	}
+	c.Security.LoginThrottle.BaseDelay, _ = time.ParseDuration(os.Getenv("SECURITY_LOGIN_BASE_DELAY"))
+	if c.Security.LoginThrottle.BaseDelay == 0 {
+		c.Security.LoginThrottle.BaseDelay = time.Second
+	}
+
+	c.OAuth.Issuer = strings.TrimSuffix(os.Getenv("OAUTH_ISSUER"), "/")
+	if c.OAuth.Issuer == "" {
+		c.OAuth.Issuer = "http://localhost:" + c.API.Port
+	}
+	c.OAuth.AuthorizationCodeLifetime, _ = time.ParseDuration(os.Getenv("OAUTH_AUTHORIZATION_CODE_LIFETIME"))
+	if c.OAuth.AuthorizationCodeLifetime == 0 {
+		c.OAuth.AuthorizationCodeLifetime = time.Minute
+	}
+
+	// defaults follow the OWASP password storage recommendations
+	c.Password.Algorithm = os.Getenv("PASSWORD_HASH_ALGORITHM")
+	if c.Password.Algorithm == "" {
+		c.Password.Algorithm = "argon2id"
+	}
+	c.Password.BcryptCost, _ = strconv.Atoi(os.Getenv("PASSWORD_BCRYPT_COST"))
+	if c.Password.BcryptCost == 0 {
+		c.Password.BcryptCost = 10
+	}
+	memory, _ := strconv.ParseUint(os.Getenv("PASSWORD_ARGON2_MEMORY"), 10, 32)
+	c.Password.Argon2Memory = uint32(memory)
+	if c.Password.Argon2Memory == 0 {
+		c.Password.Argon2Memory = 19 * 1024
+	}
+	iterations, _ := strconv.ParseUint(os.Getenv("PASSWORD_ARGON2_ITERATIONS"), 10, 32)
+	c.Password.Argon2Iterations = uint32(iterations)
+	if c.Password.Argon2Iterations == 0 {
+		c.Password.Argon2Iterations = 2
+	}
+	parallelism, _ := strconv.ParseUint(os.Getenv("PASSWORD_ARGON2_PARALLELISM"), 10, 8)
+	c.Password.Argon2Parallelism = uint8(parallelism)
+	if c.Password.Argon2Parallelism == 0 {
+		c.Password.Argon2Parallelism = 1
+	}
+	c.Password.MinLength, _ = strconv.Atoi(os.Getenv("PASSWORD_MIN_LENGTH"))
+	if c.Password.MinLength == 0 {
+		c.Password.MinLength = 8
+	}
+	// bcrypt refuses passwords over 72 bytes, keep the maximum well below that when using it
+	c.Password.MaxLength, _ = strconv.Atoi(os.Getenv("PASSWORD_MAX_LENGTH"))
+	if c.Password.MaxLength == 0 {
+		c.Password.MaxLength = 64
+	}
+	c.Password.BreachedListFile = os.Getenv("PASSWORD_BREACHED_LIST_FILE")
+
+	// the outbox lets development and test environments run without a mail server
+	c.Mail.Driver = os.Getenv("MAIL_DRIVER")
+	if c.Mail.Driver == "" {
+		c.Mail.Driver = "outbox"
+	}
+	c.Mail.From = os.Getenv("MAIL_FROM")
+	if c.Mail.From == "" {
+		c.Mail.From = "no-reply@localhost"
+	}
+	c.Mail.SMTPHost = os.Getenv("MAIL_SMTP_HOST")
+	c.Mail.SMTPPort, _ = strconv.Atoi(os.Getenv("MAIL_SMTP_PORT"))
+	if c.Mail.SMTPPort == 0 {
+		c.Mail.SMTPPort = 587
+	}
+	c.Mail.SMTPUsername = os.Getenv("MAIL_SMTP_USERNAME")
+	c.Mail.SMTPPassword = os.Getenv("MAIL_SMTP_PASSWORD")
+	c.Mail.OutboxDir = os.Getenv("MAIL_OUTBOX_DIR")
+	if c.Mail.OutboxDir == "" {
+		c.Mail.OutboxDir = "outbox"
+	}
+
+	// point ACCOUNT_RESET_PASSWORD_URL at the page of the frontend that asks for the new password
+	c.Account.VerifyEmailURL = os.Getenv("ACCOUNT_VERIFY_EMAIL_URL")
+	if c.Account.VerifyEmailURL == "" {
+		c.Account.VerifyEmailURL = c.OAuth.Issuer + "/api/v1/verify-email"
+	}
+	c.Account.ResetPasswordURL = os.Getenv("ACCOUNT_RESET_PASSWORD_URL")
+	if c.Account.ResetPasswordURL == "" {
+		c.Account.ResetPasswordURL = c.OAuth.Issuer + "/reset-password"
+	}
+	c.Account.VerificationTokenLifetime, _ = time.ParseDuration(os.Getenv("ACCOUNT_VERIFICATION_TOKEN_LIFETIME"))
+	if c.Account.VerificationTokenLifetime == 0 {
+		c.Account.VerificationTokenLifetime = 24 * time.Hour
+	}
+	c.Account.ResetTokenLifetime, _ = time.ParseDuration(os.Getenv("ACCOUNT_RESET_TOKEN_LIFETIME"))
+	if c.Account.ResetTokenLifetime == 0 {
+		c.Account.ResetTokenLifetime = 30 * time.Minute
+	}
+
+	c.Audit.Sink = os.Getenv("AUDIT_SINK")
+	if c.Audit.Sink == "" {
+		c.Audit.Sink = "postgres"
+	}
+	c.Audit.File = os.Getenv("AUDIT_FILE")
+	if c.Audit.File == "" {
+		c.Audit.File = "audit.log"
+	}
 
 	return nil
 }
 
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func NewConfig() (*Config, error) {
 	var c Config
 	err := c.readConfig()
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"crypto"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// readSigningKeys loads the JWT signing method and, for asymmetric methods,
+// the PEM encoded private key plus any previous public keys that must still
+// verify tokens during a key rotation.
+func (c *Config) readSigningKeys() error {
+	method, err := parseSigningMethod(os.Getenv("TOKEN_SIGNING_METHOD"))
+	if err != nil {
+		return err
+	}
+	c.Token.JwtSignedMethod = method
+
+	c.Token.JwtKeyID = os.Getenv("TOKEN_KEY_ID")
+	if c.Token.JwtKeyID == "" {
+		c.Token.JwtKeyID = "default"
+	}
+
+	if _, ok := method.(*jwt.SigningMethodHMAC); !ok {
+		c.Token.JwtPrivateKey, err = LoadPrivateKey(os.Getenv("TOKEN_PRIVATE_KEY_FILE"), method)
+		if err != nil {
+			return err
+		}
+	}
+
+	c.Token.JwtVerificationKeys, err = LoadVerificationKeys(os.Getenv("TOKEN_VERIFICATION_KEY_FILES"))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func parseSigningMethod(name string) (jwt.SigningMethod, error) {
+	switch name {
+	case "", "HS256":
+		return jwt.SigningMethodHS256, nil
+	case "RS256":
+		return jwt.SigningMethodRS256, nil
+	case "EdDSA":
+		return jwt.SigningMethodEdDSA, nil
+	}
+	return nil, fmt.Errorf("unsupported token signing method %s", name)
+}
+
+// LoadPrivateKey reads a PEM encoded private key that matches the given signing method.
+func LoadPrivateKey(path string, method jwt.SigningMethod) (crypto.PrivateKey, error) {
+	if path == "" {
+		return nil, fmt.Errorf("private key file is required for signing method %s", method.Alg())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch method {
+	case jwt.SigningMethodRS256:
+		return jwt.ParseRSAPrivateKeyFromPEM(data)
+	case jwt.SigningMethodEdDSA:
+		return jwt.ParseEdPrivateKeyFromPEM(data)
+	}
+	return nil, fmt.Errorf("unsupported token signing method %s", method.Alg())
+}
+
+// LoadVerificationKeys parses a comma separated list of kid=path pairs
+// pointing at PEM encoded RSA or Ed25519 public keys.
+func LoadVerificationKeys(spec string) (map[string]crypto.PublicKey, error) {
+	keys := map[string]crypto.PublicKey{}
+	if strings.TrimSpace(spec) == "" {
+		return keys, nil
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		kid, path, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || kid == "" || path == "" {
+			return nil, fmt.Errorf("invalid verification key entry %q, expected kid=path", entry)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if key, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+			keys[kid] = key
+			continue
+		}
+		key, err := jwt.ParseEdPublicKeyFromPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("verification key %s is neither an RSA nor an Ed25519 public key", kid)
+		}
+		keys[kid] = key
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/suite"
+)
+
+type KeysSuite struct {
+	suite.Suite
+	dir string
+}
+
+func TestKeysSuite(t *testing.T) {
+	suite.Run(t, new(KeysSuite))
+}
+
+func (k *KeysSuite) SetupTest() {
+	k.dir = k.T().TempDir()
+}
+
+func (k *KeysSuite) writePEM(name, blockType string, der []byte) string {
+	path := filepath.Join(k.dir, name)
+	err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600)
+	k.Require().NoError(err)
+	return path
+}
+
+func (k *KeysSuite) TestLoadPrivateKey_RSA() {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	path := k.writePEM("rsa.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key))
+
+	loaded, err := LoadPrivateKey(path, jwt.SigningMethodRS256)
+	k.NoError(err)
+	k.IsType(&rsa.PrivateKey{}, loaded)
+}
+
+func (k *KeysSuite) TestLoadPrivateKey_EdDSA() {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	path := k.writePEM("ed.pem", "PRIVATE KEY", der)
+
+	loaded, err := LoadPrivateKey(path, jwt.SigningMethodEdDSA)
+	k.NoError(err)
+	k.IsType(ed25519.PrivateKey{}, loaded)
+}
+
+func (k *KeysSuite) TestLoadPrivateKey_MissingPath() {
+	_, err := LoadPrivateKey("", jwt.SigningMethodRS256)
+	k.Error(err)
+}
+
+func (k *KeysSuite) TestLoadVerificationKeys_Success() {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	rsaDER, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	edPublic, _, _ := ed25519.GenerateKey(rand.Reader)
+	edDER, _ := x509.MarshalPKIXPublicKey(edPublic)
+
+	rsaPath := k.writePEM("old-rsa.pem", "PUBLIC KEY", rsaDER)
+	edPath := k.writePEM("old-ed.pem", "PUBLIC KEY", edDER)
+
+	keys, err := LoadVerificationKeys("old-rsa=" + rsaPath + ", old-ed=" + edPath)
+	k.NoError(err)
+	k.Len(keys, 2)
+	k.IsType(&rsa.PublicKey{}, keys["old-rsa"])
+	k.IsType(ed25519.PublicKey{}, keys["old-ed"])
+}
+
+func (k *KeysSuite) TestLoadVerificationKeys_Empty() {
+	keys, err := LoadVerificationKeys("")
+	k.NoError(err)
+	k.Empty(keys)
+}
+
+func (k *KeysSuite) TestLoadVerificationKeys_InvalidEntry() {
+	_, err := LoadVerificationKeys("missing-path")
+	k.Error(err)
+}
+
+func (k *KeysSuite) TestParseSigningMethod() {
+	method, err := parseSigningMethod("")
+	k.NoError(err)
+	k.Equal(jwt.SigningMethodHS256, method)
+
+	method, err = parseSigningMethod("EdDSA")
+	k.NoError(err)
+	k.Equal(jwt.SigningMethodEdDSA, method)
+
+	_, err = parseSigningMethod("none")
+	k.Error(err)
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"basic-JWT/middleware"
+	"basic-JWT/model"
+	"basic-JWT/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccountController struct {
+	accountUc usecase.AccountUsecase
+	rg        *gin.RouterGroup
+}
+
+func (ac *AccountController) Route() {
+	// GET serves the link in the verification mail, POST is for frontends
+	ac.rg.GET("/verify-email", ac.verifyEmailHandler)
+	ac.rg.POST("/verify-email", ac.verifyEmailHandler)
+	ac.rg.POST("/forgot-password", ac.forgotPasswordHandler)
+	ac.rg.POST("/reset-password", ac.resetPasswordHandler)
+}
+
+func (ac *AccountController) verifyEmailHandler(c *gin.Context) {
+	request := model.VerifyEmailRequest{Token: c.Query("token")}
+	if request.Token == "" {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			middleware.AbortWithBindError(c, err)
+			return
+		}
+	}
+
+	err := ac.accountUc.VerifyEmail(request.Token)
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to verify email")
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "success",
+	})
+}
+
+// forgotPasswordHandler answers the same way whether or not the address is
+// registered.
+func (ac *AccountController) forgotPasswordHandler(c *gin.Context) {
+	var request model.ForgotPasswordRequest
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		middleware.AbortWithBindError(c, err)
+		return
+	}
+
+	err = ac.accountUc.ForgotPassword(request.Email)
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to send reset link")
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "success",
+	})
+}
+
+func (ac *AccountController) resetPasswordHandler(c *gin.Context) {
+	var request model.ResetPasswordRequest
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		middleware.AbortWithBindError(c, err)
+		return
+	}
+
+	err = ac.accountUc.ResetPassword(request.Token, request.Password)
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to reset password")
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "success",
+	})
+}
+
+func NewAccountController(rg *gin.RouterGroup, accountUc usecase.AccountUsecase) *AccountController {
+	return &AccountController{accountUc: accountUc, rg: rg}
+}
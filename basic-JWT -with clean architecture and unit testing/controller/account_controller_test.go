@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"basic-JWT/middleware"
+	"basic-JWT/mock/controller_mock"
+	"basic-JWT/model"
+	"basic-JWT/usecase"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type AccountControllerTest struct {
+	suite.Suite
+	accountUc *controller_mock.AccountUsecaseMock
+	router    *gin.Engine
+}
+
+func TestAccountControllerSuite(t *testing.T) {
+	suite.Run(t, new(AccountControllerTest))
+}
+
+func (ac *AccountControllerTest) SetupTest() {
+	ac.accountUc = new(controller_mock.AccountUsecaseMock)
+	ac.router = gin.Default()
+	ac.router.Use(middleware.ErrorHandler())
+	NewAccountController(ac.router.Group("/api/v1"), ac.accountUc).Route()
+}
+
+func (ac *AccountControllerTest) post(path string, body interface{}) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	req, _ := http.NewRequest(http.MethodPost, path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ac.router.ServeHTTP(w, req)
+	return w
+}
+
+func (ac *AccountControllerTest) TestVerifyEmail_FromLink() {
+	ac.accountUc.On("VerifyEmail", "token").Return(nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/verify-email?token=token", nil)
+	w := httptest.NewRecorder()
+	ac.router.ServeHTTP(w, req)
+
+	ac.Equal(http.StatusOK, w.Code)
+	ac.Contains(w.Body.String(), "success")
+}
+
+func (ac *AccountControllerTest) TestVerifyEmail_FromBody() {
+	ac.accountUc.On("VerifyEmail", "token").Return(nil)
+
+	w := ac.post("/api/v1/verify-email", model.VerifyEmailRequest{Token: "token"})
+	ac.Equal(http.StatusOK, w.Code)
+}
+
+func (ac *AccountControllerTest) TestVerifyEmail_MissingToken() {
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/verify-email", nil)
+	w := httptest.NewRecorder()
+	ac.router.ServeHTTP(w, req)
+
+	ac.Equal(http.StatusBadRequest, w.Code)
+	ac.accountUc.AssertNotCalled(ac.T(), "VerifyEmail", "")
+}
+
+func (ac *AccountControllerTest) TestVerifyEmail_InvalidToken() {
+	ac.accountUc.On("VerifyEmail", "token").Return(usecase.ErrInvalidAccountToken)
+
+	w := ac.post("/api/v1/verify-email", model.VerifyEmailRequest{Token: "token"})
+	ac.Equal(http.StatusBadRequest, w.Code)
+	ac.Contains(w.Body.String(), "invalid or expired token")
+}
+
+func (ac *AccountControllerTest) TestForgotPassword_Success() {
+	ac.accountUc.On("ForgotPassword", "alice@example.com").Return(nil)
+
+	w := ac.post("/api/v1/forgot-password", model.ForgotPasswordRequest{Email: "alice@example.com"})
+	ac.Equal(http.StatusOK, w.Code)
+	ac.Contains(w.Body.String(), "success")
+}
+
+func (ac *AccountControllerTest) TestForgotPassword_BadRequest() {
+	w := ac.post("/api/v1/forgot-password", map[string]string{})
+	ac.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (ac *AccountControllerTest) TestForgotPassword_Failed() {
+	ac.accountUc.On("ForgotPassword", "alice@example.com").Return(errors.New("smtp down"))
+
+	w := ac.post("/api/v1/forgot-password", model.ForgotPasswordRequest{Email: "alice@example.com"})
+	ac.Equal(http.StatusInternalServerError, w.Code)
+	ac.Contains(w.Body.String(), "failed to send reset link")
+}
+
+func (ac *AccountControllerTest) TestResetPassword_Success() {
+	ac.accountUc.On("ResetPassword", "token", "correct horse battery").Return(nil)
+
+	w := ac.post("/api/v1/reset-password", model.ResetPasswordRequest{Token: "token", Password: "correct horse battery"})
+	ac.Equal(http.StatusOK, w.Code)
+}
+
+func (ac *AccountControllerTest) TestResetPassword_InvalidToken() {
+	ac.accountUc.On("ResetPassword", "token", "correct horse battery").Return(usecase.ErrInvalidAccountToken)
+
+	w := ac.post("/api/v1/reset-password", model.ResetPasswordRequest{Token: "token", Password: "correct horse battery"})
+	ac.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (ac *AccountControllerTest) TestResetPassword_WeakPassword() {
+	ac.accountUc.On("ResetPassword", "token", "short").Return(usecase.NewDomainError(usecase.KindValidation, "password is too short: it must be at least 8 characters long"))
+
+	w := ac.post("/api/v1/reset-password", model.ResetPasswordRequest{Token: "token", Password: "short"})
+	ac.Equal(http.StatusBadRequest, w.Code)
+	ac.Contains(w.Body.String(), "at least 8 characters")
+}
+
+func (ac *AccountControllerTest) TestResetPassword_AccountDisabled() {
+	ac.accountUc.On("ResetPassword", "token", "correct horse battery").Return(usecase.ErrAccountDisabled)
+
+	w := ac.post("/api/v1/reset-password", model.ResetPasswordRequest{Token: "token", Password: "correct horse battery"})
+	ac.Equal(http.StatusForbidden, w.Code)
+}
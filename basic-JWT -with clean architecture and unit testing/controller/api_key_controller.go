@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"basic-JWT/middleware"
+	"basic-JWT/model"
+	"basic-JWT/usecase"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyController struct {
+	apiKeyUc       usecase.APIKeyUsecase
+	rg             *gin.RouterGroup
+	authMiddleware *middleware.AuthMiddleware
+}
+
+func (ac *APIKeyController) Route() {
+	manage := ac.authMiddleware.RequirePermission("api-keys:manage")
+
+	ac.rg.GET("/api-keys", manage, ac.getAllHandler)
+	ac.rg.POST("/api-keys", manage, ac.createHandler)
+	ac.rg.DELETE("/api-keys/:id", manage, ac.deleteHandler)
+}
+
+func (ac *APIKeyController) getAllHandler(c *gin.Context) {
+	apiKeys, err := ac.apiKeyUc.GetAll()
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to get api keys")
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"apiKeys": apiKeys,
+	})
+}
+
+// createHandler returns the full key once. Only its hash is stored, so a lost
+// key cannot be shown again and has to be replaced.
+func (ac *APIKeyController) createHandler(c *gin.Context) {
+	var request model.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		middleware.AbortWithBindError(c, err)
+		return
+	}
+
+	credentials, err := ac.apiKeyUc.Create(request)
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to create api key")
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"apiKey": credentials,
+	})
+}
+
+func (ac *APIKeyController) deleteHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.AbortWithBindError(c, err)
+		return
+	}
+
+	if err := ac.apiKeyUc.Delete(id); err != nil {
+		middleware.AbortWithError(c, err, "failed to delete api key")
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "success",
+	})
+}
+
+func NewAPIKeyController(rg *gin.RouterGroup, apiKeyUc usecase.APIKeyUsecase, authMiddleware *middleware.AuthMiddleware) *APIKeyController {
+	return &APIKeyController{apiKeyUc: apiKeyUc, rg: rg, authMiddleware: authMiddleware}
+}
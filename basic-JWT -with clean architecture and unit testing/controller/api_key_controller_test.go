@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"basic-JWT/middleware"
+	"basic-JWT/mock/controller_mock"
+	"basic-JWT/mock/service_mock"
+	"basic-JWT/model"
+	"basic-JWT/usecase"
+	modelutils "basic-JWT/utils/model_utils"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type APIKeyControllerTest struct {
+	suite.Suite
+	apiKeyUc   *controller_mock.APIKeyUsecaseMock
+	rbacUc     *controller_mock.RbacUsecaseMock
+	jwtService *service_mock.JWTServiceMock
+	router     *gin.Engine
+}
+
+func TestAPIKeyControllerSuite(t *testing.T) {
+	suite.Run(t, new(APIKeyControllerTest))
+}
+
+func (ac *APIKeyControllerTest) SetupTest() {
+	ac.apiKeyUc = new(controller_mock.APIKeyUsecaseMock)
+	ac.rbacUc = new(controller_mock.RbacUsecaseMock)
+	ac.jwtService = new(service_mock.JWTServiceMock)
+	ac.router = gin.Default()
+	ac.router.Use(middleware.ErrorHandler())
+	userUc := new(controller_mock.UserUsecaseMock)
+	userUc.On("GetUserByID", 1).Return(model.User{ID: 1, Username: "admin", Role: "admin"}, nil)
+	userUc.On("GetUserByID", 2).Return(model.User{ID: 2, Username: "user", Role: "user"}, nil)
+	authMiddleware := middleware.NewAuthMiddleware(ac.jwtService, ac.rbacUc, userUc, new(controller_mock.TokenRevocationUsecaseMock), new(controller_mock.SessionUsecaseMock), ac.apiKeyUc, nil)
+	NewAPIKeyController(ac.router.Group("/api/v1"), ac.apiKeyUc, authMiddleware).Route()
+
+	ac.jwtService.On("VerifyToken", "dummy_admin_token").Return(&modelutils.JwtPayloadClaims{UserId: 1, Role: "admin"}, nil)
+	ac.jwtService.On("VerifyToken", "dummy_user_token").Return(&modelutils.JwtPayloadClaims{UserId: 2, Role: "user"}, nil)
+	ac.rbacUc.On("HasPermission", "admin", "api-keys:manage").Return(true, nil)
+	ac.rbacUc.On("HasPermission", "user", "api-keys:manage").Return(false, nil)
+}
+
+func (ac *APIKeyControllerTest) request(method string, path string, token string, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	ac.router.ServeHTTP(w, req)
+	return w
+}
+
+func (ac *APIKeyControllerTest) TestGetAllHandler_Success() {
+	apiKeys := []model.APIKey{{ID: 1, Prefix: "abcd", Name: "backup job", Scopes: []string{"users:read"}, SecretHash: "hash"}}
+	ac.apiKeyUc.On("GetAll").Return(apiKeys, nil).Once()
+
+	w := ac.request(http.MethodGet, "/api/v1/api-keys", "dummy_admin_token", nil)
+
+	ac.Equal(http.StatusOK, w.Code)
+	ac.Contains(w.Body.String(), "backup job")
+	ac.NotContains(w.Body.String(), "hash")
+}
+
+func (ac *APIKeyControllerTest) TestGetAllHandler_Forbidden() {
+	w := ac.request(http.MethodGet, "/api/v1/api-keys", "dummy_user_token", nil)
+
+	ac.Equal(http.StatusForbidden, w.Code)
+	ac.apiKeyUc.AssertNotCalled(ac.T(), "GetAll")
+}
+
+func (ac *APIKeyControllerTest) TestCreateHandler_Success() {
+	request := model.CreateAPIKeyRequest{Name: "backup job", Username: "robot", Scopes: []string{"users:read"}}
+	credentials := model.APIKeyCredentials{APIKey: model.APIKey{ID: 1, Prefix: "abcd", Name: "backup job"}, Key: "bjk_abcd.secret"}
+	ac.apiKeyUc.On("Create", request).Return(credentials, nil).Once()
+
+	w := ac.request(http.MethodPost, "/api/v1/api-keys", "dummy_admin_token", request)
+
+	ac.Equal(http.StatusCreated, w.Code)
+	ac.Contains(w.Body.String(), "bjk_abcd.secret")
+}
+
+func (ac *APIKeyControllerTest) TestCreateHandler_InvalidRequest() {
+	w := ac.request(http.MethodPost, "/api/v1/api-keys", "dummy_admin_token", map[string]string{"name": "backup job"})
+
+	ac.Equal(http.StatusBadRequest, w.Code)
+	ac.apiKeyUc.AssertNotCalled(ac.T(), "Create", mock.Anything)
+}
+
+func (ac *APIKeyControllerTest) TestCreateHandler_ScopeNotGranted() {
+	request := model.CreateAPIKeyRequest{Name: "backup job", Username: "robot", Scopes: []string{"rbac:manage"}}
+	ac.apiKeyUc.On("Create", request).Return(model.APIKeyCredentials{}, usecase.NewDomainError(usecase.KindValidation, "role 'user' does not have the permission 'rbac:manage'")).Once()
+
+	w := ac.request(http.MethodPost, "/api/v1/api-keys", "dummy_admin_token", request)
+
+	ac.Equal(http.StatusBadRequest, w.Code)
+	ac.Contains(w.Body.String(), "rbac:manage")
+}
+
+func (ac *APIKeyControllerTest) TestDeleteHandler_Success() {
+	ac.apiKeyUc.On("Delete", 1).Return(nil).Once()
+
+	w := ac.request(http.MethodDelete, "/api/v1/api-keys/1", "dummy_admin_token", nil)
+
+	ac.Equal(http.StatusOK, w.Code)
+	ac.apiKeyUc.AssertExpectations(ac.T())
+}
+
+func (ac *APIKeyControllerTest) TestDeleteHandler_NotFound() {
+	ac.apiKeyUc.On("Delete", 2).Return(usecase.ErrAPIKeyNotFound).Once()
+
+	w := ac.request(http.MethodDelete, "/api/v1/api-keys/2", "dummy_admin_token", nil)
+
+	ac.Equal(http.StatusNotFound, w.Code)
+}
+
+func (ac *APIKeyControllerTest) TestDeleteHandler_InvalidID() {
+	w := ac.request(http.MethodDelete, "/api/v1/api-keys/abc", "dummy_admin_token", nil)
+
+	ac.Equal(http.StatusBadRequest, w.Code)
+	ac.apiKeyUc.AssertNotCalled(ac.T(), "Delete", mock.Anything)
+}
+
+func (ac *APIKeyControllerTest) TestDeleteHandler_Failed() {
+	ac.apiKeyUc.On("Delete", 3).Return(errors.New("db down")).Once()
+
+	w := ac.request(http.MethodDelete, "/api/v1/api-keys/3", "dummy_admin_token", nil)
+
+	ac.Equal(http.StatusInternalServerError, w.Code)
+}
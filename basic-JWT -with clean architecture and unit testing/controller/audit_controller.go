@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"basic-JWT/middleware"
+	"basic-JWT/model"
+	"basic-JWT/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditController struct {
+	auditUc        usecase.AuditUsecase
+	rg             *gin.RouterGroup
+	authMiddleware *middleware.AuthMiddleware
+}
+
+func (ac *AuditController) Route() {
+	ac.rg.GET("/audit-events", ac.authMiddleware.RequirePermission("audit:read"), ac.searchHandler)
+}
+
+// searchHandler returns the newest events first. from and to are RFC 3339
+// timestamps, e.g. 2024-05-01T00:00:00Z.
+func (ac *AuditController) searchHandler(c *gin.Context) {
+	var filter model.AuditEventFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		middleware.AbortWithBindError(c, err)
+		return
+	}
+
+	page, err := ac.auditUc.Search(filter)
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to get audit events")
+		return
+	}
+
+	c.JSON(200, page)
+}
+
+func NewAuditController(rg *gin.RouterGroup, auditUc usecase.AuditUsecase, authMiddleware *middleware.AuthMiddleware) *AuditController {
+	return &AuditController{auditUc: auditUc, rg: rg, authMiddleware: authMiddleware}
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"basic-JWT/middleware"
+	"basic-JWT/mock/controller_mock"
+	"basic-JWT/mock/service_mock"
+	"basic-JWT/model"
+	"basic-JWT/usecase"
+	modelutils "basic-JWT/utils/model_utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditControllerTest struct {
+	suite.Suite
+	auditUc    *controller_mock.AuditUsecaseMock
+	rbacUc     *controller_mock.RbacUsecaseMock
+	jwtService *service_mock.JWTServiceMock
+	router     *gin.Engine
+}
+
+func TestAuditControllerSuite(t *testing.T) {
+	suite.Run(t, new(AuditControllerTest))
+}
+
+func (ac *AuditControllerTest) SetupTest() {
+	ac.auditUc = new(controller_mock.AuditUsecaseMock)
+	ac.rbacUc = new(controller_mock.RbacUsecaseMock)
+	ac.jwtService = new(service_mock.JWTServiceMock)
+	ac.router = gin.Default()
+	ac.router.Use(middleware.ErrorHandler())
+	userUc := new(controller_mock.UserUsecaseMock)
+	userUc.On("GetUserByID", 1).Return(model.User{ID: 1, Username: "admin", Role: "admin"}, nil)
+	userUc.On("GetUserByID", 2).Return(model.User{ID: 2, Username: "user", Role: "user"}, nil)
+	authMiddleware := middleware.NewAuthMiddleware(ac.jwtService, ac.rbacUc, userUc, new(controller_mock.TokenRevocationUsecaseMock), new(controller_mock.SessionUsecaseMock), new(controller_mock.APIKeyUsecaseMock), nil)
+	NewAuditController(ac.router.Group("/api/v1"), ac.auditUc, authMiddleware).Route()
+
+	ac.jwtService.On("VerifyToken", "dummy_admin_token").Return(&modelutils.JwtPayloadClaims{UserId: 1, Role: "admin"}, nil)
+	ac.jwtService.On("VerifyToken", "dummy_user_token").Return(&modelutils.JwtPayloadClaims{UserId: 2, Role: "user"}, nil)
+	ac.rbacUc.On("HasPermission", "admin", "audit:read").Return(true, nil)
+	ac.rbacUc.On("HasPermission", "user", "audit:read").Return(false, nil)
+}
+
+func (ac *AuditControllerTest) request(path string, token string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	ac.router.ServeHTTP(w, req)
+	return w
+}
+
+func (ac *AuditControllerTest) TestSearchHandler_Success() {
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	filter := model.AuditEventFilter{Actor: "admin", Action: model.AuditActionUserDelete, From: from, Page: 2, Limit: 10}
+	page := model.AuditEventPage{
+		Events: []model.AuditEvent{{ID: 3, ActorID: 1, Actor: "admin", Action: model.AuditActionUserDelete, Target: "bob", Outcome: model.AuditOutcomeSuccess}},
+		Page:   2,
+		Limit:  10,
+		Total:  11,
+	}
+	ac.auditUc.On("Search", mock.MatchedBy(func(f model.AuditEventFilter) bool {
+		return f.Actor == filter.Actor && f.Action == filter.Action && f.From.Equal(from) && f.To.IsZero() && f.Page == 2 && f.Limit == 10
+	})).Return(page, nil).Once()
+
+	w := ac.request("/api/v1/audit-events?actor=admin&action=user.delete&from=2024-05-01T00:00:00Z&page=2&limit=10", "dummy_admin_token")
+
+	ac.Equal(http.StatusOK, w.Code)
+	ac.Contains(w.Body.String(), `"target":"bob"`)
+	ac.Contains(w.Body.String(), `"total":11`)
+	ac.auditUc.AssertExpectations(ac.T())
+}
+
+func (ac *AuditControllerTest) TestSearchHandler_Forbidden() {
+	w := ac.request("/api/v1/audit-events", "dummy_user_token")
+
+	ac.Equal(http.StatusForbidden, w.Code)
+	ac.auditUc.AssertNotCalled(ac.T(), "Search", mock.Anything)
+}
+
+func (ac *AuditControllerTest) TestSearchHandler_InvalidTime() {
+	w := ac.request("/api/v1/audit-events?from=yesterday", "dummy_admin_token")
+
+	ac.Equal(http.StatusBadRequest, w.Code)
+	ac.auditUc.AssertNotCalled(ac.T(), "Search", mock.Anything)
+}
+
+func (ac *AuditControllerTest) TestSearchHandler_FileSink() {
+	ac.auditUc.On("Search", mock.Anything).Return(model.AuditEventPage{}, usecase.ErrAuditSearchUnavailable).Once()
+
+	w := ac.request("/api/v1/audit-events", "dummy_admin_token")
+
+	ac.Equal(http.StatusServiceUnavailable, w.Code)
+	ac.Contains(w.Body.String(), "cannot be searched")
+}
@@ -1,9 +1,9 @@
 package controller
 
 import (
+	"basic-JWT/middleware"
 	"basic-JWT/model"
 	"basic-JWT/usecase"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,27 +16,22 @@ type AuthController struct {
 func (ac *AuthController) Route() {
 	ac.rg.POST("/register", ac.registerHandler)
 	ac.rg.POST("/login", ac.loginHandler)
+	ac.rg.POST("/login/verify", ac.loginVerifyHandler)
+	ac.rg.POST("/refresh", ac.refreshHandler)
+	ac.rg.POST("/logout", ac.logoutHandler)
 }
 
 func (ac *AuthController) registerHandler(c *gin.Context) {
-	var user model.User
-	err := c.ShouldBindJSON(&user)
+	var request model.RegisterRequest
+	err := c.ShouldBindJSON(&request)
 	if err != nil {
-		c.JSON(400, gin.H{
-			"message": "bad request",
-		})
+		middleware.AbortWithBindError(c, err)
 		return
 	}
 
-	user, err = ac.authUc.Register(user.Username, user.Password)
+	_, err = ac.authUc.Register(request.Username, request.Password, request.Email, clientInfo(c))
 	if err != nil {
-		if strings.Contains(err.Error(), "is already taken") {
-			c.JSON(409, gin.H{"error": err.Error()}) // 409 Conflict
-			return
-		}
-		c.JSON(500, gin.H{
-			"error": "failed to register user",
-		})
+		middleware.AbortWithError(c, err, "failed to register user")
 		return
 	}
 
@@ -46,28 +41,79 @@ func (ac *AuthController) registerHandler(c *gin.Context) {
 }
 
 func (ac *AuthController) loginHandler(c *gin.Context) {
-	var user model.User
-	err := c.ShouldBindJSON(&user)
+	var request model.CredentialsRequest
+	err := c.ShouldBindJSON(&request)
 	if err != nil {
-		c.JSON(400, gin.H{
-			"message": "bad request",
-		})
+		middleware.AbortWithBindError(c, err)
 		return
 	}
 
-	token, err := ac.authUc.Login(user.Username, user.Password)
+	result, err := ac.authUc.Login(request.Username, request.Password, clientInfo(c))
 	if err != nil {
-		c.JSON(500, gin.H{
-			"message": "failed to login user",
-		})
+		middleware.AbortWithError(c, err, "failed to login user")
+		return
+	}
+
+	c.JSON(200, result)
+}
+
+func (ac *AuthController) loginVerifyHandler(c *gin.Context) {
+	var request model.MfaVerifyRequest
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		middleware.AbortWithBindError(c, err)
+		return
+	}
+
+	tokens, err := ac.authUc.VerifyMfa(request.MfaToken, request.Code, clientInfo(c))
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to verify mfa code")
+		return
+	}
+
+	c.JSON(200, tokens)
+}
+
+func (ac *AuthController) refreshHandler(c *gin.Context) {
+	var request model.RefreshTokenRequest
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		middleware.AbortWithBindError(c, err)
+		return
+	}
+
+	tokens, err := ac.authUc.Refresh(request.RefreshToken, clientInfo(c))
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to refresh token")
+		return
+	}
+
+	c.JSON(200, tokens)
+}
+
+func (ac *AuthController) logoutHandler(c *gin.Context) {
+	var request model.RefreshTokenRequest
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		middleware.AbortWithBindError(c, err)
+		return
+	}
+
+	err = ac.authUc.Logout(request.RefreshToken)
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to logout user")
 		return
 	}
 
 	c.JSON(200, gin.H{
-		"token": token,
+		"message": "success",
 	})
 }
 
+func clientInfo(c *gin.Context) model.ClientInfo {
+	return model.ClientInfo{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
+
 func NewAuthController(rg *gin.RouterGroup, authUc usecase.AuthenticationUsecase) *AuthController {
 	return &AuthController{authUc: authUc, rg: rg}
 }
@@ -1,16 +1,20 @@
 package controller
 
 import (
+	"basic-JWT/middleware"
 	"basic-JWT/mock/controller_mock"
 	"basic-JWT/model"
+	"basic-JWT/usecase"
 	"bytes"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -28,14 +32,15 @@ func TestAuthControllerSuite(t *testing.T) {
 func (ac *AuthControllerTest) SetupTest() {
 	ac.authUc = new(controller_mock.AuthenticationUsecaseMock)
 	ac.router = gin.Default()
+	ac.router.Use(middleware.ErrorHandler())
 	rg := ac.router.Group("/api/v1")
 	ac.ac = NewAuthController(rg, ac.authUc)
 	ac.ac.Route()
 }
 
 func (ac *AuthControllerTest) TestRegisterHandler_Success() {
-	user := model.User{Username: "testuser", Password: "testpassword"}
-	ac.authUc.On("Register", user.Username, user.Password).Return(user, nil)
+	user := model.RegisterRequest{Username: "testuser", Password: "testpassword", Email: "test@example.com"}
+	ac.authUc.On("Register", user.Username, user.Password, user.Email, mock.Anything).Return(model.User{Username: user.Username}, nil)
 
 	requestBody, _ := json.Marshal(user)
 	req, _ := http.NewRequest(http.MethodPost, "/api/v1/register", bytes.NewBuffer(requestBody))
@@ -60,8 +65,8 @@ func (ac *AuthControllerTest) TestRegisterHandler_BadRequest() {
 }
 
 func (ac *AuthControllerTest) TestRegisterHandler_UsernameTaken() {
-	user := model.User{Username: "existinguser", Password: "testpassword"}
-	ac.authUc.On("Register", user.Username, user.Password).Return(model.User{}, errors.New("username 'existinguser' is already taken"))
+	user := model.RegisterRequest{Username: "existinguser", Password: "testpassword", Email: "test@example.com"}
+	ac.authUc.On("Register", user.Username, user.Password, user.Email, mock.Anything).Return(model.User{}, usecase.NewDomainError(usecase.KindConflict, "username 'existinguser' is already taken"))
 
 	requestBody, _ := json.Marshal(user)
 	req, _ := http.NewRequest(http.MethodPost, "/api/v1/register", bytes.NewBuffer(requestBody))
@@ -74,9 +79,39 @@ func (ac *AuthControllerTest) TestRegisterHandler_UsernameTaken() {
 	ac.Contains(w.Body.String(), "is already taken")
 }
 
+func (ac *AuthControllerTest) TestRegisterHandler_WeakPassword() {
+	user := model.RegisterRequest{Username: "newuser", Password: "short", Email: "test@example.com"}
+	ac.authUc.On("Register", user.Username, user.Password, user.Email, mock.Anything).Return(model.User{}, usecase.NewDomainError(usecase.KindValidation, "password is too short: it must be at least 8 characters long"))
+
+	requestBody, _ := json.Marshal(user)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/register", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ac.router.ServeHTTP(w, req)
+
+	ac.Equal(http.StatusBadRequest, w.Code)
+	ac.Contains(w.Body.String(), "at least 8 characters")
+}
+
+func (ac *AuthControllerTest) TestRegisterHandler_InvalidEmail() {
+	user := model.RegisterRequest{Username: "newuser", Password: "testpassword", Email: "not an email"}
+	ac.authUc.On("Register", user.Username, user.Password, user.Email, mock.Anything).Return(model.User{}, usecase.ErrInvalidEmail)
+
+	requestBody, _ := json.Marshal(user)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/register", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ac.router.ServeHTTP(w, req)
+
+	ac.Equal(http.StatusBadRequest, w.Code)
+	ac.Contains(w.Body.String(), "invalid email address")
+}
+
 func (ac *AuthControllerTest) TestRegisterHandler_Failed() {
-	user := model.User{Username: "testuser", Password: "testpassword"}
-	ac.authUc.On("Register", user.Username, user.Password).Return(model.User{}, errors.New("some database error"))
+	user := model.RegisterRequest{Username: "testuser", Password: "testpassword", Email: "test@example.com"}
+	ac.authUc.On("Register", user.Username, user.Password, user.Email, mock.Anything).Return(model.User{}, errors.New("some database error"))
 
 	requestBody, _ := json.Marshal(user)
 	req, _ := http.NewRequest(http.MethodPost, "/api/v1/register", bytes.NewBuffer(requestBody))
@@ -90,8 +125,8 @@ func (ac *AuthControllerTest) TestRegisterHandler_Failed() {
 }
 
 func (ac *AuthControllerTest) TestLoginHandler_Success() {
-	user := model.User{Username: "testuser", Password: "testpassword"}
-	ac.authUc.On("Login", user.Username, user.Password).Return("testtoken", nil)
+	user := model.CredentialsRequest{Username: "testuser", Password: "testpassword"}
+	ac.authUc.On("Login", user.Username, user.Password, mock.Anything).Return(model.LoginResult{TokenPair: model.TokenPair{AccessToken: "testtoken", RefreshToken: "testrefresh"}}, nil)
 
 	requestBody, _ := json.Marshal(user)
 	req, _ := http.NewRequest(http.MethodPost, "/api/v1/login", bytes.NewBuffer(requestBody))
@@ -102,6 +137,7 @@ func (ac *AuthControllerTest) TestLoginHandler_Success() {
 
 	ac.Equal(http.StatusOK, w.Code)
 	ac.Contains(w.Body.String(), "testtoken")
+	ac.Contains(w.Body.String(), "testrefresh")
 }
 
 func (ac *AuthControllerTest) TestLoginHandler_BadRequest() {
@@ -115,9 +151,41 @@ func (ac *AuthControllerTest) TestLoginHandler_BadRequest() {
 	ac.Contains(w.Body.String(), "bad request")
 }
 
+func (ac *AuthControllerTest) TestLoginHandler_InvalidCredentials() {
+	user := model.CredentialsRequest{Username: "testuser", Password: "wrongpassword"}
+	ac.authUc.On("Login", user.Username, user.Password, mock.Anything).Return(model.LoginResult{}, usecase.ErrInvalidCredentials)
+
+	requestBody, _ := json.Marshal(user)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/login", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ac.router.ServeHTTP(w, req)
+
+	ac.Equal(http.StatusUnauthorized, w.Code)
+	ac.Contains(w.Body.String(), "invalid username or password")
+}
+
+func (ac *AuthControllerTest) TestLoginHandler_TooManyAttempts() {
+	user := model.CredentialsRequest{Username: "testuser", Password: "testpassword"}
+	ac.authUc.On("Login", user.Username, user.Password, model.ClientInfo{IP: "192.0.2.1"}).
+		Return(model.LoginResult{}, &usecase.TooManyAttemptsError{RetryAfter: 1500 * time.Millisecond})
+
+	requestBody, _ := json.Marshal(user)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/login", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "192.0.2.1:1234"
+
+	w := httptest.NewRecorder()
+	ac.router.ServeHTTP(w, req)
+
+	ac.Equal(http.StatusTooManyRequests, w.Code)
+	ac.Equal("2", w.Header().Get("Retry-After"))
+}
+
 func (ac *AuthControllerTest) TestLoginHandler_Failed() {
-	user := model.User{Username: "testuser", Password: "testpassword"}
-	ac.authUc.On("Login", user.Username, user.Password).Return("", errors.New("some database error"))
+	user := model.CredentialsRequest{Username: "testuser", Password: "testpassword"}
+	ac.authUc.On("Login", user.Username, user.Password, mock.Anything).Return(model.LoginResult{}, errors.New("some database error"))
 
 	requestBody, _ := json.Marshal(user)
 	req, _ := http.NewRequest(http.MethodPost, "/api/v1/login", bytes.NewBuffer(requestBody))
@@ -129,3 +197,168 @@ func (ac *AuthControllerTest) TestLoginHandler_Failed() {
 	ac.Equal(http.StatusInternalServerError, w.Code)
 	ac.Contains(w.Body.String(), "failed to login user")
 }
+
+func (ac *AuthControllerTest) TestLoginHandler_AccountDisabled() {
+	user := model.CredentialsRequest{Username: "testuser", Password: "testpassword"}
+	ac.authUc.On("Login", user.Username, user.Password, mock.Anything).Return(model.LoginResult{}, usecase.ErrAccountDisabled)
+
+	requestBody, _ := json.Marshal(user)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/login", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ac.router.ServeHTTP(w, req)
+
+	ac.Equal(http.StatusForbidden, w.Code)
+	ac.Contains(w.Body.String(), "account is disabled")
+}
+
+func (ac *AuthControllerTest) TestLoginHandler_EmailNotVerified() {
+	user := model.CredentialsRequest{Username: "testuser", Password: "testpassword"}
+	ac.authUc.On("Login", user.Username, user.Password, mock.Anything).Return(model.LoginResult{}, usecase.ErrEmailNotVerified)
+
+	requestBody, _ := json.Marshal(user)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/login", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ac.router.ServeHTTP(w, req)
+
+	ac.Equal(http.StatusForbidden, w.Code)
+	ac.Contains(w.Body.String(), "email address is not verified")
+}
+
+func (ac *AuthControllerTest) TestLoginHandler_MfaRequired() {
+	user := model.CredentialsRequest{Username: "admin", Password: "testpassword"}
+	ac.authUc.On("Login", user.Username, user.Password, mock.Anything).Return(model.LoginResult{MfaRequired: true, MfaToken: "mfatoken"}, nil)
+
+	requestBody, _ := json.Marshal(user)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/login", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ac.router.ServeHTTP(w, req)
+
+	ac.Equal(http.StatusOK, w.Code)
+	ac.JSONEq(`{"mfaRequired":true,"mfaToken":"mfatoken"}`, w.Body.String())
+}
+
+func (ac *AuthControllerTest) TestLoginVerifyHandler_Success() {
+	ac.authUc.On("VerifyMfa", "mfatoken", "123456", mock.Anything).Return(model.TokenPair{AccessToken: "testtoken", RefreshToken: "testrefresh"}, nil)
+
+	requestBody, _ := json.Marshal(model.MfaVerifyRequest{MfaToken: "mfatoken", Code: "123456"})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/login/verify", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ac.router.ServeHTTP(w, req)
+
+	ac.Equal(http.StatusOK, w.Code)
+	ac.Contains(w.Body.String(), "testtoken")
+}
+
+func (ac *AuthControllerTest) TestLoginVerifyHandler_BadRequest() {
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/login/verify", bytes.NewBuffer([]byte(`{"mfaToken":"mfatoken"}`)))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ac.router.ServeHTTP(w, req)
+
+	ac.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (ac *AuthControllerTest) TestLoginVerifyHandler_InvalidCode() {
+	ac.authUc.On("VerifyMfa", "mfatoken", "000000", mock.Anything).Return(model.TokenPair{}, usecase.ErrInvalidMfaCode)
+
+	requestBody, _ := json.Marshal(model.MfaVerifyRequest{MfaToken: "mfatoken", Code: "000000"})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/login/verify", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ac.router.ServeHTTP(w, req)
+
+	ac.Equal(http.StatusUnauthorized, w.Code)
+	ac.Contains(w.Body.String(), "invalid mfa code")
+}
+
+func (ac *AuthControllerTest) TestRefreshHandler_Success() {
+	client := model.ClientInfo{IP: "192.0.2.1", UserAgent: "curl/8.0"}
+	ac.authUc.On("Refresh", "oldrefresh", client).Return(model.TokenPair{AccessToken: "newtoken", RefreshToken: "newrefresh"}, nil)
+
+	requestBody, _ := json.Marshal(model.RefreshTokenRequest{RefreshToken: "oldrefresh"})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/refresh", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "curl/8.0")
+	req.RemoteAddr = "192.0.2.1:1234"
+
+	w := httptest.NewRecorder()
+	ac.router.ServeHTTP(w, req)
+
+	ac.Equal(http.StatusOK, w.Code)
+	ac.Contains(w.Body.String(), "newtoken")
+	ac.Contains(w.Body.String(), "newrefresh")
+}
+
+func (ac *AuthControllerTest) TestRefreshHandler_BadRequest() {
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/refresh", bytes.NewBuffer([]byte("{}")))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ac.router.ServeHTTP(w, req)
+
+	ac.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (ac *AuthControllerTest) TestRefreshHandler_Reused() {
+	ac.authUc.On("Refresh", "usedrefresh", mock.Anything).Return(model.TokenPair{}, usecase.ErrRefreshTokenReused)
+
+	requestBody, _ := json.Marshal(model.RefreshTokenRequest{RefreshToken: "usedrefresh"})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/refresh", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ac.router.ServeHTTP(w, req)
+
+	ac.Equal(http.StatusUnauthorized, w.Code)
+}
+
+func (ac *AuthControllerTest) TestRefreshHandler_Failed() {
+	ac.authUc.On("Refresh", "oldrefresh", mock.Anything).Return(model.TokenPair{}, errors.New("some database error"))
+
+	requestBody, _ := json.Marshal(model.RefreshTokenRequest{RefreshToken: "oldrefresh"})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/refresh", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ac.router.ServeHTTP(w, req)
+
+	ac.Equal(http.StatusInternalServerError, w.Code)
+	ac.Contains(w.Body.String(), "failed to refresh token")
+}
+
+func (ac *AuthControllerTest) TestLogoutHandler_Success() {
+	ac.authUc.On("Logout", "refresh").Return(nil)
+
+	requestBody, _ := json.Marshal(model.RefreshTokenRequest{RefreshToken: "refresh"})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/logout", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ac.router.ServeHTTP(w, req)
+
+	ac.Equal(http.StatusOK, w.Code)
+	ac.Contains(w.Body.String(), "success")
+}
+
+func (ac *AuthControllerTest) TestLogoutHandler_InvalidToken() {
+	ac.authUc.On("Logout", "unknown").Return(usecase.ErrInvalidRefreshToken)
+
+	requestBody, _ := json.Marshal(model.RefreshTokenRequest{RefreshToken: "unknown"})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/logout", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ac.router.ServeHTTP(w, req)
+
+	ac.Equal(http.StatusUnauthorized, w.Code)
+}
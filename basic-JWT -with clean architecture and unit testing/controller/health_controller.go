@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"basic-JWT/middleware"
+	"basic-JWT/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+	healthUc usecase.HealthUsecase
+	rg       *gin.RouterGroup
+}
+
+func (hc *HealthController) Route() {
+	hc.rg.GET("/healthz", hc.livenessHandler)
+	hc.rg.GET("/readyz", hc.readinessHandler)
+}
+
+// livenessHandler only reports that the process serves requests. It does not
+// check the database, an outage there must not get every instance restarted.
+func (hc *HealthController) livenessHandler(c *gin.Context) {
+	c.JSON(200, gin.H{
+		"status": "ok",
+	})
+}
+
+// readinessHandler answers 503 while the database is unreachable, so the
+// orchestrator stops sending traffic to this instance.
+func (hc *HealthController) readinessHandler(c *gin.Context) {
+	if err := hc.healthUc.Ready(c.Request.Context()); err != nil {
+		middleware.AbortWithError(c, err, "not ready")
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"status": "ready",
+	})
+}
+
+func NewHealthController(rg *gin.RouterGroup, healthUc usecase.HealthUsecase) *HealthController {
+	return &HealthController{healthUc: healthUc, rg: rg}
+}
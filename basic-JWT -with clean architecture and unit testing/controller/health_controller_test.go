@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"basic-JWT/middleware"
+	"basic-JWT/mock/controller_mock"
+	"basic-JWT/usecase"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type HealthControllerTest struct {
+	suite.Suite
+	healthUc *controller_mock.HealthUsecaseMock
+	router   *gin.Engine
+}
+
+func TestHealthControllerSuite(t *testing.T) {
+	suite.Run(t, new(HealthControllerTest))
+}
+
+func (hc *HealthControllerTest) SetupTest() {
+	hc.healthUc = new(controller_mock.HealthUsecaseMock)
+	hc.router = gin.Default()
+	hc.router.Use(middleware.ErrorHandler())
+	NewHealthController(hc.router.Group(""), hc.healthUc).Route()
+}
+
+func (hc *HealthControllerTest) request(path string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+	w := httptest.NewRecorder()
+	hc.router.ServeHTTP(w, req)
+	return w
+}
+
+func (hc *HealthControllerTest) TestLiveness_DoesNotCheckDatabase() {
+	w := hc.request("/healthz")
+
+	hc.Equal(http.StatusOK, w.Code)
+	hc.JSONEq(`{"status":"ok"}`, w.Body.String())
+	hc.healthUc.AssertNotCalled(hc.T(), "Ready", mock.Anything)
+}
+
+func (hc *HealthControllerTest) TestReadiness_Ready() {
+	hc.healthUc.On("Ready", mock.Anything).Return(nil).Once()
+
+	w := hc.request("/readyz")
+
+	hc.Equal(http.StatusOK, w.Code)
+	hc.JSONEq(`{"status":"ready"}`, w.Body.String())
+}
+
+func (hc *HealthControllerTest) TestReadiness_DatabaseDown() {
+	hc.healthUc.On("Ready", mock.Anything).Return(usecase.NewDomainError(usecase.KindUnavailable, "database is unavailable")).Once()
+
+	w := hc.request("/readyz")
+
+	hc.Equal(http.StatusServiceUnavailable, w.Code)
+	hc.Contains(w.Body.String(), "database is unavailable")
+}
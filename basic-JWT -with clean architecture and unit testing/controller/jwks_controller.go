@@ -0,0 +1,26 @@
+package controller
+
+import (
+	"basic-JWT/utils/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JWKSController struct {
+	jwtSvc service.JWTservice
+	rg     *gin.RouterGroup
+}
+
+func (jc *JWKSController) Route() {
+	jc.rg.GET("/jwks.json", jc.jwksHandler)
+}
+
+func (jc *JWKSController) jwksHandler(c *gin.Context) {
+	// verifiers cache the set, keep it short so rotated keys are picked up quickly
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(200, jc.jwtSvc.JWKS())
+}
+
+func NewJWKSController(rg *gin.RouterGroup, jwtSvc service.JWTservice) *JWKSController {
+	return &JWKSController{jwtSvc: jwtSvc, rg: rg}
+}
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"basic-JWT/mock/service_mock"
+	modelutils "basic-JWT/utils/model_utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type JWKSControllerTest struct {
+	suite.Suite
+	jwtService *service_mock.JWTServiceMock
+	router     *gin.Engine
+}
+
+func TestJWKSControllerSuite(t *testing.T) {
+	suite.Run(t, new(JWKSControllerTest))
+}
+
+func (jc *JWKSControllerTest) SetupTest() {
+	jc.jwtService = new(service_mock.JWTServiceMock)
+	jc.router = gin.Default()
+	NewJWKSController(jc.router.Group("/.well-known"), jc.jwtService).Route()
+}
+
+func (jc *JWKSControllerTest) TestJWKSHandler_Success() {
+	jc.jwtService.On("JWKS").Return(modelutils.JSONWebKeySet{Keys: []modelutils.JSONWebKey{{Kty: "OKP", Kid: "key-1", Alg: "EdDSA", Crv: "Ed25519", X: "abc"}}})
+
+	req, _ := http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	w := httptest.NewRecorder()
+	jc.router.ServeHTTP(w, req)
+
+	jc.Equal(http.StatusOK, w.Code)
+	jc.Contains(w.Body.String(), `"kid":"key-1"`)
+	jc.NotEmpty(w.Header().Get("Cache-Control"))
+}
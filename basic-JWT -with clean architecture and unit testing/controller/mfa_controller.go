@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"basic-JWT/middleware"
+	"basic-JWT/model"
+	"basic-JWT/usecase"
+
+	modelutils "basic-JWT/utils/model_utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MfaController struct {
+	mfaUc          usecase.MfaUsecase
+	rg             *gin.RouterGroup
+	authMiddleware *middleware.AuthMiddleware
+}
+
+func (mc *MfaController) Route() {
+	// enrollment stays reachable for roles that are blocked until they set up MFA
+	mc.rg.POST("/mfa/enroll", mc.authMiddleware.RequireTokenForMfaSetup(), mc.enrollHandler)
+	mc.rg.POST("/mfa/confirm", mc.authMiddleware.RequireTokenForMfaSetup(), mc.confirmHandler)
+	mc.rg.DELETE("/users/:username/mfa", mc.authMiddleware.RequirePermission("mfa:reset"), mc.resetHandler)
+}
+
+func (mc *MfaController) enrollHandler(c *gin.Context) {
+	claims := c.MustGet(middleware.ClaimsKey).(*modelutils.JwtPayloadClaims)
+
+	enrollment, err := mc.mfaUc.Enroll(claims.UserId)
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to enroll mfa")
+		return
+	}
+
+	c.JSON(200, enrollment)
+}
+
+func (mc *MfaController) confirmHandler(c *gin.Context) {
+	claims := c.MustGet(middleware.ClaimsKey).(*modelutils.JwtPayloadClaims)
+
+	var request model.MfaCodeRequest
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		middleware.AbortWithBindError(c, err)
+		return
+	}
+
+	codes, err := mc.mfaUc.Confirm(claims.UserId, request.Code)
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to confirm mfa")
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"recoveryCodes": codes,
+	})
+}
+
+func (mc *MfaController) resetHandler(c *gin.Context) {
+	err := mc.mfaUc.Reset(c.Param("username"))
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to reset mfa")
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "success",
+	})
+}
+
+func NewMfaController(rg *gin.RouterGroup, mfaUc usecase.MfaUsecase, authMiddleware *middleware.AuthMiddleware) *MfaController {
+	return &MfaController{mfaUc: mfaUc, rg: rg, authMiddleware: authMiddleware}
+}
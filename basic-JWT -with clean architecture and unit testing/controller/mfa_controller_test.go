@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"basic-JWT/middleware"
+	"basic-JWT/mock/controller_mock"
+	"basic-JWT/mock/service_mock"
+	"basic-JWT/model"
+	"basic-JWT/usecase"
+	modelutils "basic-JWT/utils/model_utils"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type MfaControllerTest struct {
+	suite.Suite
+	mfaUc      *controller_mock.MfaUsecaseMock
+	rbacUc     *controller_mock.RbacUsecaseMock
+	jwtService *service_mock.JWTServiceMock
+	router     *gin.Engine
+}
+
+func TestMfaControllerSuite(t *testing.T) {
+	suite.Run(t, new(MfaControllerTest))
+}
+
+func (mc *MfaControllerTest) SetupTest() {
+	mc.mfaUc = new(controller_mock.MfaUsecaseMock)
+	mc.rbacUc = new(controller_mock.RbacUsecaseMock)
+	mc.jwtService = new(service_mock.JWTServiceMock)
+	mc.router = gin.Default()
+	mc.router.Use(middleware.ErrorHandler())
+	userUc := new(controller_mock.UserUsecaseMock)
+	userUc.On("GetUserByID", 1).Return(model.User{ID: 1, Username: "admin", Role: "admin"}, nil)
+	authMiddleware := middleware.NewAuthMiddleware(mc.jwtService, mc.rbacUc, userUc, new(controller_mock.TokenRevocationUsecaseMock), new(controller_mock.SessionUsecaseMock), new(controller_mock.APIKeyUsecaseMock), []string{"admin"})
+	NewMfaController(mc.router.Group("/api/v1"), mc.mfaUc, authMiddleware).Route()
+
+	// admin without an mfa claim, as right after the first password-only login
+	mc.jwtService.On("VerifyToken", "dummy_admin_token").Return(&modelutils.JwtPayloadClaims{UserId: 1, Role: "admin"}, nil)
+	mc.jwtService.On("VerifyToken", "dummy_mfa_admin_token").Return(&modelutils.JwtPayloadClaims{UserId: 1, Role: "admin", Mfa: true}, nil)
+	mc.rbacUc.On("HasPermission", "admin", "mfa:reset").Return(true, nil)
+}
+
+func (mc *MfaControllerTest) request(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	mc.router.ServeHTTP(w, req)
+	return w
+}
+
+func (mc *MfaControllerTest) TestEnroll_Success() {
+	mc.mfaUc.On("Enroll", 1).Return(model.MfaEnrollment{Secret: "SECRET", URI: "otpauth://totp/basic-JWT:admin?secret=SECRET"}, nil)
+
+	w := mc.request(http.MethodPost, "/api/v1/mfa/enroll", "dummy_admin_token", nil)
+
+	mc.Equal(http.StatusOK, w.Code)
+	mc.Contains(w.Body.String(), "otpauth://totp/")
+}
+
+func (mc *MfaControllerTest) TestEnroll_AlreadyEnabled() {
+	mc.mfaUc.On("Enroll", 1).Return(model.MfaEnrollment{}, usecase.ErrMfaAlreadyEnabled)
+
+	w := mc.request(http.MethodPost, "/api/v1/mfa/enroll", "dummy_mfa_admin_token", nil)
+
+	mc.Equal(http.StatusConflict, w.Code)
+}
+
+func (mc *MfaControllerTest) TestConfirm_Success() {
+	mc.mfaUc.On("Confirm", 1, "123456").Return([]string{"abcd-efgh"}, nil)
+
+	w := mc.request(http.MethodPost, "/api/v1/mfa/confirm", "dummy_admin_token", model.MfaCodeRequest{Code: "123456"})
+
+	mc.Equal(http.StatusOK, w.Code)
+	mc.Contains(w.Body.String(), "abcd-efgh")
+}
+
+func (mc *MfaControllerTest) TestConfirm_InvalidCode() {
+	mc.mfaUc.On("Confirm", 1, "000000").Return(nil, usecase.NewDomainError(usecase.KindValidation, "invalid mfa code"))
+
+	w := mc.request(http.MethodPost, "/api/v1/mfa/confirm", "dummy_admin_token", model.MfaCodeRequest{Code: "000000"})
+
+	mc.Equal(http.StatusBadRequest, w.Code)
+	mc.Contains(w.Body.String(), "invalid mfa code")
+}
+
+func (mc *MfaControllerTest) TestConfirm_BadRequest() {
+	w := mc.request(http.MethodPost, "/api/v1/mfa/confirm", "dummy_admin_token", nil)
+
+	mc.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (mc *MfaControllerTest) TestReset_Success() {
+	mc.mfaUc.On("Reset", "alice").Return(nil)
+
+	w := mc.request(http.MethodDelete, "/api/v1/users/alice/mfa", "dummy_mfa_admin_token", nil)
+
+	mc.Equal(http.StatusOK, w.Code)
+	mc.Contains(w.Body.String(), "success")
+}
+
+func (mc *MfaControllerTest) TestReset_RequiresMfa() {
+	w := mc.request(http.MethodDelete, "/api/v1/users/alice/mfa", "dummy_admin_token", nil)
+
+	mc.Equal(http.StatusForbidden, w.Code)
+	mc.mfaUc.AssertNotCalled(mc.T(), "Reset", "alice")
+}
+
+func (mc *MfaControllerTest) TestReset_UserNotFound() {
+	mc.mfaUc.On("Reset", "ghost").Return(usecase.NewDomainError(usecase.KindNotFound, "user with username ghost not found"))
+
+	w := mc.request(http.MethodDelete, "/api/v1/users/ghost/mfa", "dummy_mfa_admin_token", nil)
+
+	mc.Equal(http.StatusNotFound, w.Code)
+}
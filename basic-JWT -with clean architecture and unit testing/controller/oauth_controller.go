@@ -0,0 +1,290 @@
+package controller
+
+import (
+	"basic-JWT/middleware"
+	"basic-JWT/model"
+	"basic-JWT/usecase"
+	"errors"
+	"net/url"
+	"strings"
+
+	modelutils "basic-JWT/utils/model_utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type OAuthController struct {
+	oauthUc        usecase.OAuthUsecase
+	rg             *gin.RouterGroup
+	wellKnown      *gin.RouterGroup
+	authMiddleware *middleware.AuthMiddleware
+	issuer         string
+	signingAlg     string
+}
+
+func (oc *OAuthController) Route() {
+	// the protocol endpoints answer with RFC 6749 error objects instead of
+	// problem details, which is what OAuth client libraries understand.
+	// The user approves by calling /authorize with their own access token.
+	// There is no browser login or consent screen, so only first-party apps
+	// that already hold a bearer token can use it; see the README.
+	oc.rg.GET("/oauth/authorize", oc.authMiddleware.RequireAuthenticated(), oc.authorizeHandler)
+	oc.rg.POST("/oauth/token", oc.tokenHandler)
+	oc.rg.GET("/oauth/userinfo", oc.userInfoHandler)
+	oc.rg.POST("/oauth/userinfo", oc.userInfoHandler)
+	oc.rg.POST("/oauth/introspect", oc.introspectHandler)
+
+	manage := oc.authMiddleware.RequirePermission("oauth:manage")
+	oc.rg.GET("/oauth/clients", manage, oc.getAllClientsHandler)
+	oc.rg.POST("/oauth/clients", manage, oc.createClientHandler)
+	oc.rg.DELETE("/oauth/clients/:clientId", manage, oc.deleteClientHandler)
+
+	oc.wellKnown.GET("/openid-configuration", oc.discoveryHandler)
+}
+
+// authorizeHandler redirects back to the client with a code. Errors are only
+// redirected once the redirect_uri is known to belong to the client.
+func (oc *OAuthController) authorizeHandler(c *gin.Context) {
+	claims := c.MustGet(middleware.ClaimsKey).(*modelutils.JwtPayloadClaims)
+
+	var request model.AuthorizeRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		c.JSON(400, gin.H{
+			"error": "invalid_request",
+		})
+		return
+	}
+
+	response, err := oc.oauthUc.Authorize(claims.UserId, claims.Mfa, request)
+	if err != nil {
+		var oauthErr *usecase.OAuthError
+		if !errors.As(err, &oauthErr) {
+			oauthErr = &usecase.OAuthError{Code: "server_error", Description: "failed to authorize client"}
+		}
+		if response.RedirectURI == "" {
+			status := 400
+			if oauthErr.Code == "server_error" {
+				status = 500
+			}
+			c.JSON(status, gin.H{"error": oauthErr.Code, "error_description": oauthErr.Description})
+			return
+		}
+		c.Redirect(302, withQuery(response.RedirectURI, map[string]string{
+			"error":             oauthErr.Code,
+			"error_description": oauthErr.Description,
+			"state":             response.State,
+		}))
+		return
+	}
+
+	c.Redirect(302, withQuery(response.RedirectURI, map[string]string{
+		"code":  response.Code,
+		"state": response.State,
+	}))
+}
+
+// tokenHandler takes a form encoded body as required by RFC 6749. Clients
+// authenticate with HTTP Basic or client_id and client_secret in the body.
+func (oc *OAuthController) tokenHandler(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	var request model.TokenRequest
+	if err := c.ShouldBindWith(&request, binding.Form); err != nil {
+		c.JSON(400, gin.H{
+			"error": "invalid_request",
+		})
+		return
+	}
+
+	basicAuth := clientCredentials(c, &request.ClientID, &request.ClientSecret)
+
+	response, err := oc.oauthUc.Token(request)
+	if err != nil {
+		clientError(c, err, basicAuth)
+		return
+	}
+
+	c.JSON(200, response)
+}
+
+// introspectHandler implements RFC 7662. Only confidential clients may ask,
+// and every token they cannot use is answered with {"active": false}.
+func (oc *OAuthController) introspectHandler(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	var request model.IntrospectionRequest
+	if err := c.ShouldBindWith(&request, binding.Form); err != nil {
+		c.JSON(400, gin.H{
+			"error": "invalid_request",
+		})
+		return
+	}
+
+	basicAuth := clientCredentials(c, &request.ClientID, &request.ClientSecret)
+
+	response, err := oc.oauthUc.Introspect(request)
+	if err != nil {
+		clientError(c, err, basicAuth)
+		return
+	}
+
+	c.JSON(200, response)
+}
+
+func (oc *OAuthController) userInfoHandler(c *gin.Context) {
+	accessToken := c.GetHeader("Authorization")
+	if !strings.HasPrefix(accessToken, "Bearer ") {
+		c.Header("WWW-Authenticate", "Bearer")
+		c.JSON(401, gin.H{"error": "invalid_token"})
+		return
+	}
+
+	info, err := oc.oauthUc.UserInfo(accessToken[7:])
+	if err != nil {
+		var oauthErr *usecase.OAuthError
+		if !errors.As(err, &oauthErr) {
+			c.JSON(500, gin.H{"error": "server_error"})
+			return
+		}
+
+		status := 401
+		if oauthErr.Code == "insufficient_scope" {
+			status = 403
+		}
+		c.Header("WWW-Authenticate", `Bearer error="`+oauthErr.Code+`"`)
+		c.JSON(status, gin.H{"error": oauthErr.Code, "error_description": oauthErr.Description})
+		return
+	}
+
+	c.JSON(200, info)
+}
+
+func (oc *OAuthController) getAllClientsHandler(c *gin.Context) {
+	clients, err := oc.oauthUc.GetAllClients()
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to get oauth clients")
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"clients": clients,
+	})
+}
+
+func (oc *OAuthController) createClientHandler(c *gin.Context) {
+	var request model.CreateOAuthClientRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		middleware.AbortWithBindError(c, err)
+		return
+	}
+
+	credentials, err := oc.oauthUc.RegisterClient(request)
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to create oauth client")
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"client": credentials,
+	})
+}
+
+func (oc *OAuthController) deleteClientHandler(c *gin.Context) {
+	err := oc.oauthUc.DeleteClient(c.Param("clientId"))
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to delete oauth client")
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "success",
+	})
+}
+
+func (oc *OAuthController) discoveryHandler(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(200, model.OpenIDConfiguration{
+		Issuer:                            oc.issuer,
+		AuthorizationEndpoint:             oc.issuer + "/api/v1/oauth/authorize",
+		TokenEndpoint:                     oc.issuer + "/api/v1/oauth/token",
+		UserinfoEndpoint:                  oc.issuer + "/api/v1/oauth/userinfo",
+		IntrospectionEndpoint:             oc.issuer + "/api/v1/oauth/introspect",
+		JwksURI:                           oc.issuer + "/.well-known/jwks.json",
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{usecase.GrantTypeAuthorizationCode, usecase.GrantTypeRefreshToken, usecase.GrantTypeClientCredentials},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{oc.signingAlg},
+		ScopesSupported:                   []string{usecase.ScopeOpenID},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+	})
+}
+
+// clientCredentials takes the client id and secret from HTTP Basic when the
+// client sent them that way, and reports whether it did.
+func clientCredentials(c *gin.Context, clientID *string, clientSecret *string) bool {
+	id, secret, basicAuth := c.Request.BasicAuth()
+	if basicAuth {
+		*clientID = formUnescape(id)
+		*clientSecret = formUnescape(secret)
+	}
+	return basicAuth
+}
+
+// clientError writes an RFC 6749 error response for the endpoints that
+// authenticate the client. Failed client authentication is a 401.
+func clientError(c *gin.Context, err error, basicAuth bool) {
+	var oauthErr *usecase.OAuthError
+	if !errors.As(err, &oauthErr) {
+		c.JSON(500, gin.H{"error": "server_error"})
+		return
+	}
+
+	status := 400
+	if oauthErr.Code == "invalid_client" {
+		status = 401
+		if basicAuth {
+			c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+	}
+	c.JSON(status, gin.H{"error": oauthErr.Code, "error_description": oauthErr.Description})
+}
+
+// withQuery adds the non-empty params to the query of a redirect URI, keeping
+// any query it already has.
+func withQuery(redirectURI string, params map[string]string) string {
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+
+	query := target.Query()
+	for key, value := range params {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	target.RawQuery = query.Encode()
+	return target.String()
+}
+
+// formUnescape decodes Basic credentials, which RFC 6749 section 2.3.1 form encodes.
+func formUnescape(value string) string {
+	if unescaped, err := url.QueryUnescape(value); err == nil {
+		return unescaped
+	}
+	return value
+}
+
+func NewOAuthController(rg *gin.RouterGroup, wellKnown *gin.RouterGroup, oauthUc usecase.OAuthUsecase, authMiddleware *middleware.AuthMiddleware, issuer string, signingAlg string) *OAuthController {
+	return &OAuthController{
+		oauthUc:        oauthUc,
+		rg:             rg,
+		wellKnown:      wellKnown,
+		authMiddleware: authMiddleware,
+		issuer:         issuer,
+		signingAlg:     signingAlg,
+	}
+}
@@ -0,0 +1,306 @@
+package controller
+
+import (
+	"basic-JWT/middleware"
+	"basic-JWT/mock/controller_mock"
+	"basic-JWT/mock/service_mock"
+	"basic-JWT/model"
+	"basic-JWT/usecase"
+	modelutils "basic-JWT/utils/model_utils"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type OAuthControllerTest struct {
+	suite.Suite
+	oauthUc    *controller_mock.OAuthUsecaseMock
+	rbacUc     *controller_mock.RbacUsecaseMock
+	jwtService *service_mock.JWTServiceMock
+	router     *gin.Engine
+}
+
+func TestOAuthControllerSuite(t *testing.T) {
+	suite.Run(t, new(OAuthControllerTest))
+}
+
+func (oc *OAuthControllerTest) SetupTest() {
+	oc.oauthUc = new(controller_mock.OAuthUsecaseMock)
+	oc.rbacUc = new(controller_mock.RbacUsecaseMock)
+	oc.jwtService = new(service_mock.JWTServiceMock)
+	oc.router = gin.Default()
+	oc.router.Use(middleware.ErrorHandler())
+
+	userUc := new(controller_mock.UserUsecaseMock)
+	userUc.On("GetUserByID", 1).Return(model.User{ID: 1, Username: "admin", Role: "admin"}, nil)
+	userUc.On("GetUserByID", 7).Return(model.User{ID: 7, Username: "user", Role: "user"}, nil)
+	authMiddleware := middleware.NewAuthMiddleware(oc.jwtService, oc.rbacUc, userUc, new(controller_mock.TokenRevocationUsecaseMock), new(controller_mock.SessionUsecaseMock), new(controller_mock.APIKeyUsecaseMock), nil)
+	NewOAuthController(oc.router.Group("/api/v1"), oc.router.Group("/.well-known"), oc.oauthUc, authMiddleware, "https://auth.example.com", "RS256").Route()
+
+	oc.jwtService.On("VerifyToken", "dummy_user_token").Return(&modelutils.JwtPayloadClaims{UserId: 7, Role: "user"}, nil)
+	oc.jwtService.On("VerifyToken", "dummy_admin_token").Return(&modelutils.JwtPayloadClaims{UserId: 1, Role: "admin"}, nil)
+	oc.rbacUc.On("HasPermission", "admin", "oauth:manage").Return(true, nil)
+}
+
+func (oc *OAuthControllerTest) serve(req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	oc.router.ServeHTTP(w, req)
+	return w
+}
+
+func (oc *OAuthControllerTest) TestAuthorizeHandler_RedirectsWithCode() {
+	oc.oauthUc.On("Authorize", 7, false, mock.MatchedBy(func(request model.AuthorizeRequest) bool {
+		return request.ClientID == "portal" && request.CodeChallengeMethod == "S256"
+	})).Return(model.AuthorizeResponse{RedirectURI: "https://portal.example.com/callback", Code: "code", State: "xyz"}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/oauth/authorize?response_type=code&client_id=portal&redirect_uri=https%3A%2F%2Fportal.example.com%2Fcallback&state=xyz&code_challenge=abc&code_challenge_method=S256", nil)
+	req.Header.Set("Authorization", "Bearer dummy_user_token")
+	w := oc.serve(req)
+
+	oc.Equal(http.StatusFound, w.Code)
+	location, _ := url.Parse(w.Header().Get("Location"))
+	oc.Equal("portal.example.com", location.Host)
+	oc.Equal("code", location.Query().Get("code"))
+	oc.Equal("xyz", location.Query().Get("state"))
+}
+
+func (oc *OAuthControllerTest) TestAuthorizeHandler_RedirectsError() {
+	oc.oauthUc.On("Authorize", 7, false, mock.Anything).
+		Return(model.AuthorizeResponse{RedirectURI: "https://portal.example.com/callback", State: "xyz"}, &usecase.OAuthError{Code: "invalid_scope", Description: "scope not allowed"})
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/oauth/authorize?client_id=portal", nil)
+	req.Header.Set("Authorization", "Bearer dummy_user_token")
+	w := oc.serve(req)
+
+	oc.Equal(http.StatusFound, w.Code)
+	location, _ := url.Parse(w.Header().Get("Location"))
+	oc.Equal("invalid_scope", location.Query().Get("error"))
+	oc.Equal("xyz", location.Query().Get("state"))
+}
+
+func (oc *OAuthControllerTest) TestAuthorizeHandler_UnknownRedirectNotFollowed() {
+	oc.oauthUc.On("Authorize", 7, false, mock.Anything).
+		Return(model.AuthorizeResponse{}, &usecase.OAuthError{Code: "invalid_request", Description: "redirect_uri is not registered for the client"})
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/oauth/authorize?client_id=portal&redirect_uri=https%3A%2F%2Fevil.example.com", nil)
+	req.Header.Set("Authorization", "Bearer dummy_user_token")
+	w := oc.serve(req)
+
+	oc.Equal(http.StatusBadRequest, w.Code)
+	oc.Empty(w.Header().Get("Location"))
+}
+
+func (oc *OAuthControllerTest) TestAuthorizeHandler_RequiresLogin() {
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/oauth/authorize?client_id=portal", nil)
+	w := oc.serve(req)
+
+	oc.Equal(http.StatusUnauthorized, w.Code)
+	oc.oauthUc.AssertNotCalled(oc.T(), "Authorize", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (oc *OAuthControllerTest) tokenRequest(form url.Values) *http.Request {
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/oauth/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func (oc *OAuthControllerTest) TestTokenHandler_BasicAuth() {
+	oc.oauthUc.On("Token", model.TokenRequest{GrantType: "client_credentials", ClientID: "reporting", ClientSecret: "secret"}).
+		Return(model.OAuthTokenResponse{AccessToken: "access", TokenType: "Bearer", ExpiresIn: 3600}, nil)
+
+	req := oc.tokenRequest(url.Values{"grant_type": {"client_credentials"}})
+	req.SetBasicAuth("reporting", "secret")
+	w := oc.serve(req)
+
+	oc.Equal(http.StatusOK, w.Code)
+	oc.Equal("no-store", w.Header().Get("Cache-Control"))
+	oc.Contains(w.Body.String(), `"access_token":"access"`)
+	oc.Contains(w.Body.String(), `"token_type":"Bearer"`)
+}
+
+func (oc *OAuthControllerTest) TestTokenHandler_InvalidClient() {
+	oc.oauthUc.On("Token", mock.Anything).Return(model.OAuthTokenResponse{}, &usecase.OAuthError{Code: "invalid_client", Description: "client authentication failed"})
+
+	req := oc.tokenRequest(url.Values{"grant_type": {"client_credentials"}})
+	req.SetBasicAuth("reporting", "wrong")
+	w := oc.serve(req)
+
+	oc.Equal(http.StatusUnauthorized, w.Code)
+	oc.NotEmpty(w.Header().Get("WWW-Authenticate"))
+	oc.Contains(w.Body.String(), `"error":"invalid_client"`)
+}
+
+func (oc *OAuthControllerTest) TestTokenHandler_InvalidGrant() {
+	oc.oauthUc.On("Token", model.TokenRequest{GrantType: "authorization_code", Code: "code", RedirectURI: "https://portal.example.com/callback", CodeVerifier: "verifier", ClientID: "portal"}).
+		Return(model.OAuthTokenResponse{}, &usecase.OAuthError{Code: "invalid_grant", Description: "invalid authorization code"})
+
+	w := oc.serve(oc.tokenRequest(url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {"code"},
+		"redirect_uri":  {"https://portal.example.com/callback"},
+		"code_verifier": {"verifier"},
+		"client_id":     {"portal"},
+	}))
+
+	oc.Equal(http.StatusBadRequest, w.Code)
+	oc.JSONEq(`{"error":"invalid_grant","error_description":"invalid authorization code"}`, w.Body.String())
+}
+
+func (oc *OAuthControllerTest) TestTokenHandler_ServerError() {
+	oc.oauthUc.On("Token", mock.Anything).Return(model.OAuthTokenResponse{}, errors.New("db down"))
+
+	w := oc.serve(oc.tokenRequest(url.Values{"grant_type": {"refresh_token"}}))
+
+	oc.Equal(http.StatusInternalServerError, w.Code)
+	oc.NotContains(w.Body.String(), "db down")
+}
+
+func (oc *OAuthControllerTest) introspectRequest(form url.Values) *http.Request {
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/oauth/introspect", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func (oc *OAuthControllerTest) TestIntrospectHandler_Active() {
+	oc.oauthUc.On("Introspect", model.IntrospectionRequest{Token: "access", ClientID: "reporting", ClientSecret: "secret"}).
+		Return(model.IntrospectionResponse{Active: true, Username: "alice", TokenType: "Bearer"}, nil)
+
+	req := oc.introspectRequest(url.Values{"token": {"access"}})
+	req.SetBasicAuth("reporting", "secret")
+	w := oc.serve(req)
+
+	oc.Equal(http.StatusOK, w.Code)
+	oc.Equal("no-store", w.Header().Get("Cache-Control"))
+	oc.JSONEq(`{"active":true,"username":"alice","token_type":"Bearer"}`, w.Body.String())
+}
+
+func (oc *OAuthControllerTest) TestIntrospectHandler_Inactive() {
+	oc.oauthUc.On("Introspect", mock.Anything).Return(model.IntrospectionResponse{Active: false}, nil)
+
+	w := oc.serve(oc.introspectRequest(url.Values{"token": {"revoked"}, "client_id": {"reporting"}, "client_secret": {"secret"}}))
+
+	oc.Equal(http.StatusOK, w.Code)
+	oc.JSONEq(`{"active":false}`, w.Body.String())
+}
+
+func (oc *OAuthControllerTest) TestIntrospectHandler_InvalidClient() {
+	oc.oauthUc.On("Introspect", mock.Anything).Return(model.IntrospectionResponse{}, &usecase.OAuthError{Code: "invalid_client", Description: "client authentication failed"})
+
+	req := oc.introspectRequest(url.Values{"token": {"access"}})
+	req.SetBasicAuth("portal", "")
+	w := oc.serve(req)
+
+	oc.Equal(http.StatusUnauthorized, w.Code)
+	oc.Contains(w.Body.String(), `"error":"invalid_client"`)
+}
+
+func (oc *OAuthControllerTest) TestIntrospectHandler_MissingToken() {
+	w := oc.serve(oc.introspectRequest(url.Values{"client_id": {"reporting"}}))
+
+	oc.Equal(http.StatusBadRequest, w.Code)
+	oc.oauthUc.AssertNotCalled(oc.T(), "Introspect", mock.Anything)
+}
+
+func (oc *OAuthControllerTest) TestUserInfoHandler_Success() {
+	oc.oauthUc.On("UserInfo", "access").Return(model.UserInfo{Sub: "7", PreferredUsername: "alice", Role: "user"}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/oauth/userinfo", nil)
+	req.Header.Set("Authorization", "Bearer access")
+	w := oc.serve(req)
+
+	oc.Equal(http.StatusOK, w.Code)
+	oc.Contains(w.Body.String(), `"preferred_username":"alice"`)
+}
+
+func (oc *OAuthControllerTest) TestUserInfoHandler_InsufficientScope() {
+	oc.oauthUc.On("UserInfo", "access").Return(model.UserInfo{}, &usecase.OAuthError{Code: "insufficient_scope", Description: "the openid scope is required"})
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/oauth/userinfo", nil)
+	req.Header.Set("Authorization", "Bearer access")
+	w := oc.serve(req)
+
+	oc.Equal(http.StatusForbidden, w.Code)
+	oc.Contains(w.Header().Get("WWW-Authenticate"), "insufficient_scope")
+}
+
+func (oc *OAuthControllerTest) TestUserInfoHandler_MissingToken() {
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/oauth/userinfo", nil)
+	w := oc.serve(req)
+
+	oc.Equal(http.StatusUnauthorized, w.Code)
+}
+
+func (oc *OAuthControllerTest) TestCreateClientHandler_Success() {
+	request := model.CreateOAuthClientRequest{Name: "Reporting", GrantTypes: []string{"client_credentials"}, Confidential: true}
+	oc.oauthUc.On("RegisterClient", request).Return(model.OAuthClientCredentials{OAuthClient: model.OAuthClient{ClientID: "reporting"}, ClientSecret: "secret"}, nil)
+
+	payload, _ := json.Marshal(request)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/oauth/clients", bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer dummy_admin_token")
+	w := oc.serve(req)
+
+	oc.Equal(http.StatusCreated, w.Code)
+	oc.Contains(w.Body.String(), `"clientSecret":"secret"`)
+}
+
+func (oc *OAuthControllerTest) TestCreateClientHandler_InvalidMetadata() {
+	request := model.CreateOAuthClientRequest{Name: "Reporting", GrantTypes: []string{"client_credentials"}}
+	oc.oauthUc.On("RegisterClient", request).Return(model.OAuthClientCredentials{}, &usecase.DomainError{Kind: usecase.KindValidation, Message: "invalid_client_metadata: client_credentials requires a confidential client", Err: &usecase.OAuthError{Code: "invalid_client_metadata", Description: "client_credentials requires a confidential client"}})
+
+	payload, _ := json.Marshal(request)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/oauth/clients", bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer dummy_admin_token")
+	w := oc.serve(req)
+
+	oc.Equal(http.StatusBadRequest, w.Code)
+	oc.Contains(w.Body.String(), "confidential client")
+}
+
+func (oc *OAuthControllerTest) TestCreateClientHandler_MissingPermission() {
+	oc.rbacUc.On("HasPermission", "user", "oauth:manage").Return(false, nil)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/oauth/clients", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer dummy_user_token")
+	w := oc.serve(req)
+
+	oc.Equal(http.StatusForbidden, w.Code)
+	oc.oauthUc.AssertNotCalled(oc.T(), "RegisterClient", mock.Anything)
+}
+
+func (oc *OAuthControllerTest) TestDeleteClientHandler_NotFound() {
+	oc.oauthUc.On("DeleteClient", "ghost").Return(usecase.ErrOAuthClientNotFound)
+
+	req, _ := http.NewRequest(http.MethodDelete, "/api/v1/oauth/clients/ghost", nil)
+	req.Header.Set("Authorization", "Bearer dummy_admin_token")
+	w := oc.serve(req)
+
+	oc.Equal(http.StatusNotFound, w.Code)
+}
+
+func (oc *OAuthControllerTest) TestDiscoveryHandler() {
+	req, _ := http.NewRequest(http.MethodGet, "/.well-known/openid-configuration", nil)
+	w := oc.serve(req)
+
+	oc.Equal(http.StatusOK, w.Code)
+
+	var document model.OpenIDConfiguration
+	oc.NoError(json.Unmarshal(w.Body.Bytes(), &document))
+	oc.Equal("https://auth.example.com", document.Issuer)
+	oc.Equal("https://auth.example.com/api/v1/oauth/token", document.TokenEndpoint)
+	oc.Equal("https://auth.example.com/api/v1/oauth/introspect", document.IntrospectionEndpoint)
+	oc.Equal("https://auth.example.com/.well-known/jwks.json", document.JwksURI)
+	oc.Equal([]string{"S256"}, document.CodeChallengeMethodsSupported)
+	oc.Equal([]string{"RS256"}, document.IDTokenSigningAlgValuesSupported)
+}
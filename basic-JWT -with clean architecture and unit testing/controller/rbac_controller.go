@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"basic-JWT/middleware"
+	"basic-JWT/model"
+	"basic-JWT/usecase"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RbacController struct {
+	rbacUc         usecase.RbacUsecase
+	rg             *gin.RouterGroup
+	authMiddleware *middleware.AuthMiddleware
+}
+
+func (rc *RbacController) Route() {
+	manage := rc.authMiddleware.RequirePermission("rbac:manage")
+
+	rc.rg.GET("/roles", manage, rc.getAllRolesHandler)
+	rc.rg.POST("/roles", manage, rc.createRoleHandler)
+	rc.rg.DELETE("/roles/:id", manage, rc.deleteRoleHandler)
+	rc.rg.POST("/roles/:id/permissions", manage, rc.grantPermissionHandler)
+	rc.rg.DELETE("/roles/:id/permissions/:permissionId", manage, rc.revokePermissionHandler)
+
+	rc.rg.GET("/permissions", manage, rc.getAllPermissionsHandler)
+	rc.rg.POST("/permissions", manage, rc.createPermissionHandler)
+	rc.rg.DELETE("/permissions/:id", manage, rc.deletePermissionHandler)
+}
+
+func (rc *RbacController) getAllRolesHandler(c *gin.Context) {
+	roles, err := rc.rbacUc.GetAllRoles()
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to get roles")
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"roles": roles,
+	})
+}
+
+func (rc *RbacController) createRoleHandler(c *gin.Context) {
+	var role model.Role
+	if err := c.ShouldBindJSON(&role); err != nil {
+		middleware.AbortWithBindError(c, err)
+		return
+	}
+
+	created, err := rc.rbacUc.CreateRole(&role)
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to create role")
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"role": created,
+	})
+}
+
+func (rc *RbacController) deleteRoleHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.AbortWithBindError(c, err)
+		return
+	}
+
+	if err := rc.rbacUc.DeleteRole(id); err != nil {
+		middleware.AbortWithError(c, err, "failed to delete role")
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "success",
+	})
+}
+
+func (rc *RbacController) grantPermissionHandler(c *gin.Context) {
+	var request model.GrantPermissionRequest
+	roleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.AbortWithBindError(c, err)
+		return
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		middleware.AbortWithBindError(c, err)
+		return
+	}
+
+	if err := rc.rbacUc.GrantPermission(roleID, request.PermissionID); err != nil {
+		middleware.AbortWithError(c, err, "failed to grant permission")
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "success",
+	})
+}
+
+func (rc *RbacController) revokePermissionHandler(c *gin.Context) {
+	roleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.AbortWithBindError(c, err)
+		return
+	}
+	permissionID, err := strconv.Atoi(c.Param("permissionId"))
+	if err != nil {
+		middleware.AbortWithBindError(c, err)
+		return
+	}
+
+	if err := rc.rbacUc.RevokePermission(roleID, permissionID); err != nil {
+		middleware.AbortWithError(c, err, "failed to revoke permission")
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "success",
+	})
+}
+
+func (rc *RbacController) getAllPermissionsHandler(c *gin.Context) {
+	permissions, err := rc.rbacUc.GetAllPermissions()
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to get permissions")
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"permissions": permissions,
+	})
+}
+
+func (rc *RbacController) createPermissionHandler(c *gin.Context) {
+	var permission model.Permission
+	if err := c.ShouldBindJSON(&permission); err != nil {
+		middleware.AbortWithBindError(c, err)
+		return
+	}
+
+	created, err := rc.rbacUc.CreatePermission(&permission)
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to create permission")
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"permission": created,
+	})
+}
+
+func (rc *RbacController) deletePermissionHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.AbortWithBindError(c, err)
+		return
+	}
+
+	if err := rc.rbacUc.DeletePermission(id); err != nil {
+		middleware.AbortWithError(c, err, "failed to delete permission")
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "success",
+	})
+}
+
+func NewRbacController(rg *gin.RouterGroup, rbacUc usecase.RbacUsecase, authMiddleware *middleware.AuthMiddleware) *RbacController {
+	return &RbacController{rbacUc: rbacUc, rg: rg, authMiddleware: authMiddleware}
+}
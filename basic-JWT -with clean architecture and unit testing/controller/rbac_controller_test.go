@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"basic-JWT/middleware"
+	"basic-JWT/mock/controller_mock"
+	"basic-JWT/mock/service_mock"
+	"basic-JWT/model"
+	modelutils "basic-JWT/utils/model_utils"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type RbacControllerTest struct {
+	suite.Suite
+	rbacUc     *controller_mock.RbacUsecaseMock
+	jwtService *service_mock.JWTServiceMock
+	router     *gin.Engine
+}
+
+func TestRbacControllerSuite(t *testing.T) {
+	suite.Run(t, new(RbacControllerTest))
+}
+
+func (rc *RbacControllerTest) SetupTest() {
+	rc.rbacUc = new(controller_mock.RbacUsecaseMock)
+	rc.jwtService = new(service_mock.JWTServiceMock)
+	rc.router = gin.Default()
+	rc.router.Use(middleware.ErrorHandler())
+	userUc := new(controller_mock.UserUsecaseMock)
+	userUc.On("GetUserByID", 1).Return(model.User{ID: 1, Username: "admin", Role: "admin"}, nil)
+	userUc.On("GetUserByID", 2).Return(model.User{ID: 2, Username: "user", Role: "user"}, nil)
+	authMiddleware := middleware.NewAuthMiddleware(rc.jwtService, rc.rbacUc, userUc, new(controller_mock.TokenRevocationUsecaseMock), new(controller_mock.SessionUsecaseMock), new(controller_mock.APIKeyUsecaseMock), nil)
+	NewRbacController(rc.router.Group("/api/v1"), rc.rbacUc, authMiddleware).Route()
+
+	rc.jwtService.On("VerifyToken", "dummy_admin_token").Return(&modelutils.JwtPayloadClaims{UserId: 1, Role: "admin"}, nil)
+	rc.rbacUc.On("HasPermission", "admin", "rbac:manage").Return(true, nil)
+}
+
+func (rc *RbacControllerTest) request(method, path string, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer dummy_admin_token")
+
+	w := httptest.NewRecorder()
+	rc.router.ServeHTTP(w, req)
+	return w
+}
+
+func (rc *RbacControllerTest) TestGetAllRoles_Success() {
+	rc.rbacUc.On("GetAllRoles").Return([]model.Role{{ID: 1, Name: "admin", Permissions: []string{"users:create"}}}, nil)
+
+	w := rc.request(http.MethodGet, "/api/v1/roles", nil)
+
+	rc.Equal(http.StatusOK, w.Code)
+	rc.Contains(w.Body.String(), "users:create")
+}
+
+func (rc *RbacControllerTest) TestCreateRole_Success() {
+	rc.rbacUc.On("CreateRole", mock.MatchedBy(func(r *model.Role) bool { return r.Name == "editor" })).
+		Return(&model.Role{ID: 3, Name: "editor"}, nil)
+
+	w := rc.request(http.MethodPost, "/api/v1/roles", model.Role{Name: "editor"})
+
+	rc.Equal(http.StatusCreated, w.Code)
+	rc.Contains(w.Body.String(), "editor")
+}
+
+func (rc *RbacControllerTest) TestCreateRole_BadRequest() {
+	w := rc.request(http.MethodPost, "/api/v1/roles", gin.H{"description": "no name"})
+
+	rc.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (rc *RbacControllerTest) TestCreatePermission_Failed() {
+	rc.rbacUc.On("CreatePermission", mock.Anything).Return(nil, errors.New("duplicate"))
+
+	w := rc.request(http.MethodPost, "/api/v1/permissions", model.Permission{Name: "users:read"})
+
+	rc.Equal(http.StatusInternalServerError, w.Code)
+	rc.Contains(w.Body.String(), "failed to create permission")
+}
+
+func (rc *RbacControllerTest) TestGrantPermission_Success() {
+	rc.rbacUc.On("GrantPermission", 2, 5).Return(nil)
+
+	w := rc.request(http.MethodPost, "/api/v1/roles/2/permissions", model.GrantPermissionRequest{PermissionID: 5})
+
+	rc.Equal(http.StatusOK, w.Code)
+	rc.rbacUc.AssertCalled(rc.T(), "GrantPermission", 2, 5)
+}
+
+func (rc *RbacControllerTest) TestGrantPermission_BadRoleID() {
+	w := rc.request(http.MethodPost, "/api/v1/roles/abc/permissions", model.GrantPermissionRequest{PermissionID: 5})
+
+	rc.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (rc *RbacControllerTest) TestRevokePermission_Success() {
+	rc.rbacUc.On("RevokePermission", 2, 5).Return(nil)
+
+	w := rc.request(http.MethodDelete, "/api/v1/roles/2/permissions/5", nil)
+
+	rc.Equal(http.StatusOK, w.Code)
+}
+
+func (rc *RbacControllerTest) TestDeletePermission_Success() {
+	rc.rbacUc.On("DeletePermission", 5).Return(nil)
+
+	w := rc.request(http.MethodDelete, "/api/v1/permissions/5", nil)
+
+	rc.Equal(http.StatusOK, w.Code)
+}
+
+func (rc *RbacControllerTest) TestRoutes_RequireRbacManage() {
+	rc.jwtService.On("VerifyToken", "dummy_user_token").Return(&modelutils.JwtPayloadClaims{UserId: 2, Role: "user"}, nil)
+	rc.rbacUc.On("HasPermission", "user", "rbac:manage").Return(false, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/roles", nil)
+	req.Header.Set("Authorization", "Bearer dummy_user_token")
+	w := httptest.NewRecorder()
+	rc.router.ServeHTTP(w, req)
+
+	rc.Equal(http.StatusForbidden, w.Code)
+	rc.rbacUc.AssertNotCalled(rc.T(), "GetAllRoles")
+}
@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"basic-JWT/middleware"
+	"basic-JWT/usecase"
+	modelutils "basic-JWT/utils/model_utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SessionController struct {
+	sessionUc      usecase.SessionUsecase
+	rg             *gin.RouterGroup
+	authMiddleware *middleware.AuthMiddleware
+}
+
+func (sc *SessionController) Route() {
+	sc.rg.GET("/me/sessions", sc.authMiddleware.RequireAuthenticated(), sc.getAllHandler)
+	sc.rg.DELETE("/me/sessions", sc.authMiddleware.RequireAuthenticated(), sc.revokeAllHandler)
+	sc.rg.DELETE("/me/sessions/:id", sc.authMiddleware.RequireAuthenticated(), sc.revokeHandler)
+	sc.rg.POST("/users/:username/logout", sc.authMiddleware.RequirePermission("users:logout"), sc.forceLogoutHandler)
+}
+
+// getAllHandler lists the active sessions of the caller, the one making the
+// request marked as current.
+func (sc *SessionController) getAllHandler(c *gin.Context) {
+	claims := c.MustGet(middleware.ClaimsKey).(*modelutils.JwtPayloadClaims)
+	sessions, err := sc.sessionUc.GetAll(claims.UserId, claims.Sid)
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to get sessions")
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"sessions": sessions,
+	})
+}
+
+func (sc *SessionController) revokeHandler(c *gin.Context) {
+	claims := c.MustGet(middleware.ClaimsKey).(*modelutils.JwtPayloadClaims)
+	err := sc.sessionUc.Revoke(claims.UserId, c.Param("id"))
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to revoke session")
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "success",
+	})
+}
+
+// revokeAllHandler logs the caller out everywhere, including this device.
+func (sc *SessionController) revokeAllHandler(c *gin.Context) {
+	claims := c.MustGet(middleware.ClaimsKey).(*modelutils.JwtPayloadClaims)
+	err := sc.sessionUc.RevokeAll(claims.UserId)
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to revoke sessions")
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "success",
+	})
+}
+
+// forceLogoutHandler ends every session of the user. Unlike disabling the
+// account, the user can log in again right away.
+func (sc *SessionController) forceLogoutHandler(c *gin.Context) {
+	err := sc.sessionUc.ForceLogout(c.Param("username"))
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to logout user")
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "success",
+	})
+}
+
+func NewSessionController(rg *gin.RouterGroup, sessionUc usecase.SessionUsecase, authMiddleware *middleware.AuthMiddleware) *SessionController {
+	return &SessionController{sessionUc: sessionUc, rg: rg, authMiddleware: authMiddleware}
+}
@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"basic-JWT/middleware"
+	"basic-JWT/mock/controller_mock"
+	"basic-JWT/mock/service_mock"
+	"basic-JWT/model"
+	"basic-JWT/usecase"
+	modelutils "basic-JWT/utils/model_utils"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type SessionControllerTest struct {
+	suite.Suite
+	sessionUc  *controller_mock.SessionUsecaseMock
+	rbacUc     *controller_mock.RbacUsecaseMock
+	jwtService *service_mock.JWTServiceMock
+	router     *gin.Engine
+}
+
+func TestSessionControllerSuite(t *testing.T) {
+	suite.Run(t, new(SessionControllerTest))
+}
+
+func (sc *SessionControllerTest) SetupTest() {
+	sc.sessionUc = new(controller_mock.SessionUsecaseMock)
+	sc.rbacUc = new(controller_mock.RbacUsecaseMock)
+	sc.jwtService = new(service_mock.JWTServiceMock)
+	sc.router = gin.Default()
+	sc.router.Use(middleware.ErrorHandler())
+	userUc := new(controller_mock.UserUsecaseMock)
+	userUc.On("GetUserByID", 1).Return(model.User{ID: 1, Username: "admin", Role: "admin"}, nil)
+	userUc.On("GetUserByID", 2).Return(model.User{ID: 2, Username: "user", Role: "user"}, nil)
+	authMiddleware := middleware.NewAuthMiddleware(sc.jwtService, sc.rbacUc, userUc, new(controller_mock.TokenRevocationUsecaseMock), sc.sessionUc, new(controller_mock.APIKeyUsecaseMock), nil)
+	NewSessionController(sc.router.Group("/api/v1"), sc.sessionUc, authMiddleware).Route()
+
+	sc.jwtService.On("VerifyToken", "dummy_admin_token").Return(&modelutils.JwtPayloadClaims{UserId: 1, Role: "admin", Sid: "admin-sid"}, nil)
+	sc.jwtService.On("VerifyToken", "dummy_user_token").Return(&modelutils.JwtPayloadClaims{UserId: 2, Role: "user", Sid: "user-sid"}, nil)
+	sc.sessionUc.On("IsActive", mock.Anything).Return(true, nil)
+	sc.rbacUc.On("HasPermission", "admin", "users:logout").Return(true, nil)
+	sc.rbacUc.On("HasPermission", "user", "users:logout").Return(false, nil)
+}
+
+func (sc *SessionControllerTest) request(method string, path string, token string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	sc.router.ServeHTTP(w, req)
+	return w
+}
+
+func (sc *SessionControllerTest) TestGetAllHandler_Success() {
+	sessions := []model.Session{{ID: "user-sid", UserID: 2, UserAgent: "curl/8.0", Current: true}}
+	sc.sessionUc.On("GetAll", 2, "user-sid").Return(sessions, nil).Once()
+
+	w := sc.request(http.MethodGet, "/api/v1/me/sessions", "dummy_user_token")
+
+	sc.Equal(http.StatusOK, w.Code)
+	sc.Contains(w.Body.String(), "curl/8.0")
+	sc.Contains(w.Body.String(), `"current":true`)
+}
+
+func (sc *SessionControllerTest) TestGetAllHandler_Unauthorized() {
+	w := sc.request(http.MethodGet, "/api/v1/me/sessions", "")
+
+	sc.Equal(http.StatusUnauthorized, w.Code)
+	sc.sessionUc.AssertNotCalled(sc.T(), "GetAll", mock.Anything, mock.Anything)
+}
+
+func (sc *SessionControllerTest) TestRevokeHandler_Success() {
+	sc.sessionUc.On("Revoke", 2, "other-sid").Return(nil).Once()
+
+	w := sc.request(http.MethodDelete, "/api/v1/me/sessions/other-sid", "dummy_user_token")
+
+	sc.Equal(http.StatusOK, w.Code)
+	sc.sessionUc.AssertExpectations(sc.T())
+}
+
+func (sc *SessionControllerTest) TestRevokeHandler_NotFound() {
+	sc.sessionUc.On("Revoke", 2, "admin-sid").Return(usecase.ErrSessionNotFound).Once()
+
+	w := sc.request(http.MethodDelete, "/api/v1/me/sessions/admin-sid", "dummy_user_token")
+
+	sc.Equal(http.StatusNotFound, w.Code)
+	sc.Contains(w.Body.String(), "session not found")
+}
+
+func (sc *SessionControllerTest) TestRevokeAllHandler_Success() {
+	sc.sessionUc.On("RevokeAll", 2).Return(nil).Once()
+
+	w := sc.request(http.MethodDelete, "/api/v1/me/sessions", "dummy_user_token")
+
+	sc.Equal(http.StatusOK, w.Code)
+	sc.sessionUc.AssertExpectations(sc.T())
+}
+
+func (sc *SessionControllerTest) TestRevokeAllHandler_Failed() {
+	sc.sessionUc.On("RevokeAll", 2).Return(errors.New("db down")).Once()
+
+	w := sc.request(http.MethodDelete, "/api/v1/me/sessions", "dummy_user_token")
+
+	sc.Equal(http.StatusInternalServerError, w.Code)
+	sc.Contains(w.Body.String(), "failed to revoke sessions")
+}
+
+func (sc *SessionControllerTest) TestForceLogoutHandler_Success() {
+	sc.sessionUc.On("ForceLogout", "user1").Return(nil).Once()
+
+	w := sc.request(http.MethodPost, "/api/v1/users/user1/logout", "dummy_admin_token")
+
+	sc.Equal(http.StatusOK, w.Code)
+	sc.sessionUc.AssertExpectations(sc.T())
+}
+
+func (sc *SessionControllerTest) TestForceLogoutHandler_Forbidden() {
+	w := sc.request(http.MethodPost, "/api/v1/users/user1/logout", "dummy_user_token")
+
+	sc.Equal(http.StatusForbidden, w.Code)
+	sc.sessionUc.AssertNotCalled(sc.T(), "ForceLogout", mock.Anything)
+}
+
+func (sc *SessionControllerTest) TestForceLogoutHandler_UserNotFound() {
+	sc.sessionUc.On("ForceLogout", "ghost").Return(usecase.NewDomainError(usecase.KindNotFound, "user not found")).Once()
+
+	w := sc.request(http.MethodPost, "/api/v1/users/ghost/logout", "dummy_admin_token")
+
+	sc.Equal(http.StatusNotFound, w.Code)
+}
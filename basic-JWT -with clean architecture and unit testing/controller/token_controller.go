@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"basic-JWT/middleware"
+	"basic-JWT/model"
+	"basic-JWT/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TokenController struct {
+	tokenRevocationUc usecase.TokenRevocationUsecase
+	rg                *gin.RouterGroup
+	authMiddleware    *middleware.AuthMiddleware
+}
+
+func (tc *TokenController) Route() {
+	tc.rg.POST("/tokens/revoke", tc.authMiddleware.RequirePermission("tokens:revoke"), tc.revokeHandler)
+}
+
+// revokeHandler denylists an access token, for example one that was stolen.
+// The token stops working on every endpoint until it expires.
+func (tc *TokenController) revokeHandler(c *gin.Context) {
+	var request model.RevokeTokenRequest
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		middleware.AbortWithBindError(c, err)
+		return
+	}
+
+	err = tc.tokenRevocationUc.Revoke(request.Token)
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to revoke token")
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "success",
+	})
+}
+
+func NewTokenController(rg *gin.RouterGroup, tokenRevocationUc usecase.TokenRevocationUsecase, authMiddleware *middleware.AuthMiddleware) *TokenController {
+	return &TokenController{tokenRevocationUc: tokenRevocationUc, rg: rg, authMiddleware: authMiddleware}
+}
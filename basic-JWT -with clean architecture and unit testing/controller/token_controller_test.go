@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"basic-JWT/middleware"
+	"basic-JWT/mock/controller_mock"
+	"basic-JWT/mock/service_mock"
+	"basic-JWT/model"
+	"basic-JWT/usecase"
+	modelutils "basic-JWT/utils/model_utils"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type TokenControllerTest struct {
+	suite.Suite
+	tokenRevocationUc *controller_mock.TokenRevocationUsecaseMock
+	rbacUc            *controller_mock.RbacUsecaseMock
+	jwtService        *service_mock.JWTServiceMock
+	router            *gin.Engine
+}
+
+func TestTokenControllerSuite(t *testing.T) {
+	suite.Run(t, new(TokenControllerTest))
+}
+
+func (tc *TokenControllerTest) SetupTest() {
+	tc.tokenRevocationUc = new(controller_mock.TokenRevocationUsecaseMock)
+	tc.rbacUc = new(controller_mock.RbacUsecaseMock)
+	tc.jwtService = new(service_mock.JWTServiceMock)
+	tc.router = gin.Default()
+	tc.router.Use(middleware.ErrorHandler())
+	userUc := new(controller_mock.UserUsecaseMock)
+	userUc.On("GetUserByID", 1).Return(model.User{ID: 1, Username: "admin", Role: "admin"}, nil)
+	userUc.On("GetUserByID", 2).Return(model.User{ID: 2, Username: "user", Role: "user"}, nil)
+	authMiddleware := middleware.NewAuthMiddleware(tc.jwtService, tc.rbacUc, userUc, tc.tokenRevocationUc, new(controller_mock.SessionUsecaseMock), new(controller_mock.APIKeyUsecaseMock), nil)
+	NewTokenController(tc.router.Group("/api/v1"), tc.tokenRevocationUc, authMiddleware).Route()
+
+	tc.jwtService.On("VerifyToken", "dummy_admin_token").Return(&modelutils.JwtPayloadClaims{UserId: 1, Role: "admin"}, nil)
+	tc.jwtService.On("VerifyToken", "dummy_user_token").Return(&modelutils.JwtPayloadClaims{UserId: 2, Role: "user"}, nil)
+	tc.rbacUc.On("HasPermission", "admin", "tokens:revoke").Return(true, nil)
+	tc.rbacUc.On("HasPermission", "user", "tokens:revoke").Return(false, nil)
+}
+
+func (tc *TokenControllerTest) request(token string, body interface{}) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/tokens/revoke", bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	tc.router.ServeHTTP(w, req)
+	return w
+}
+
+func (tc *TokenControllerTest) TestRevokeHandler_Success() {
+	tc.tokenRevocationUc.On("Revoke", "stolen").Return(nil).Once()
+
+	w := tc.request("dummy_admin_token", model.RevokeTokenRequest{Token: "stolen"})
+
+	tc.Equal(http.StatusOK, w.Code)
+	tc.tokenRevocationUc.AssertExpectations(tc.T())
+}
+
+func (tc *TokenControllerTest) TestRevokeHandler_InvalidToken() {
+	tc.tokenRevocationUc.On("Revoke", "garbage").Return(usecase.ErrInvalidToken).Once()
+
+	w := tc.request("dummy_admin_token", model.RevokeTokenRequest{Token: "garbage"})
+
+	tc.Equal(http.StatusBadRequest, w.Code)
+	tc.Contains(w.Body.String(), "invalid token")
+}
+
+func (tc *TokenControllerTest) TestRevokeHandler_Failed() {
+	tc.tokenRevocationUc.On("Revoke", "stolen").Return(errors.New("db down")).Once()
+
+	w := tc.request("dummy_admin_token", model.RevokeTokenRequest{Token: "stolen"})
+
+	tc.Equal(http.StatusInternalServerError, w.Code)
+	tc.Contains(w.Body.String(), "failed to revoke token")
+}
+
+func (tc *TokenControllerTest) TestRevokeHandler_BadRequest() {
+	w := tc.request("dummy_admin_token", map[string]string{})
+
+	tc.Equal(http.StatusBadRequest, w.Code)
+	tc.tokenRevocationUc.AssertNotCalled(tc.T(), "Revoke", mock.Anything)
+}
+
+func (tc *TokenControllerTest) TestRevokeHandler_MissingPermission() {
+	w := tc.request("dummy_user_token", model.RevokeTokenRequest{Token: "stolen"})
+
+	tc.Equal(http.StatusForbidden, w.Code)
+	tc.tokenRevocationUc.AssertNotCalled(tc.T(), "Revoke", mock.Anything)
+}
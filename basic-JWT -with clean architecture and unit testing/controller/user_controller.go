@@ -4,37 +4,47 @@ import (
 	"basic-JWT/middleware"
 	"basic-JWT/model"
 	"basic-JWT/usecase"
+	modelutils "basic-JWT/utils/model_utils"
 
 	"github.com/gin-gonic/gin"
 )
 
+var (
+	errDisableOwnAccount = usecase.NewDomainError(usecase.KindValidation, "cannot disable your own account")
+	errDeleteOwnAccount  = usecase.NewDomainError(usecase.KindValidation, "cannot delete your own account")
+)
+
 type UserController struct {
-	userUc         usecase.UserUsecase
-	rg             *gin.RouterGroup
-	authMiddleware *middleware.AuthMiddleware
+	userUc          usecase.UserUsecase
+	loginThrottleUc usecase.LoginThrottleUsecase
+	rg              *gin.RouterGroup
+	authMiddleware  *middleware.AuthMiddleware
 }
 
 func (uc *UserController) Route() {
-	uc.rg.POST("/users", uc.authMiddleware.RequireToken("admin"), uc.createUserHandler)
-	uc.rg.GET("/users", uc.authMiddleware.RequireToken("user", "admin"), uc.getAllUsersHandler)
-	uc.rg.GET("/users/:username", uc.authMiddleware.RequireToken("user", "admin"), uc.getUserByUsernameHandler)
+	uc.rg.POST("/users", uc.authMiddleware.RequirePermission("users:create"), uc.createUserHandler)
+	uc.rg.GET("/users", uc.authMiddleware.RequirePermission("users:read"), uc.getAllUsersHandler)
+	uc.rg.GET("/users/:username", uc.authMiddleware.RequirePermission("users:read"), uc.getUserByUsernameHandler)
+	uc.rg.PUT("/users/:username", uc.authMiddleware.RequirePermission("users:update"), uc.updateUserHandler)
+	uc.rg.DELETE("/users/:username", uc.authMiddleware.RequirePermission("users:delete"), uc.deleteUserHandler)
+	uc.rg.POST("/users/:username/disable", uc.authMiddleware.RequirePermission("users:disable"), uc.disableUserHandler)
+	uc.rg.POST("/users/:username/enable", uc.authMiddleware.RequirePermission("users:disable"), uc.enableUserHandler)
+	uc.rg.POST("/users/:username/reset-password", uc.authMiddleware.RequirePermission("users:reset-password"), uc.resetPasswordHandler)
+	uc.rg.POST("/users/:username/unlock", uc.authMiddleware.RequirePermission("users:unlock"), uc.unlockUserHandler)
+	uc.rg.GET("/me", uc.authMiddleware.RequireAuthenticated(), uc.meHandler)
 }
 
 func (uc *UserController) createUserHandler(c *gin.Context) {
-	var user model.User
-	err := c.ShouldBindJSON(&user)
+	var request model.CreateUserRequest
+	err := c.ShouldBindJSON(&request)
 	if err != nil {
-		c.JSON(400, gin.H{
-			"message": "bad request",
-		})
+		middleware.AbortWithBindError(c, err)
 		return
 	}
 
-	_, err = uc.userUc.Create(&user)
+	_, err = uc.userUc.CreateUser(request, actor(c))
 	if err != nil {
-		c.JSON(500, gin.H{
-			"message": "failed to create user",
-		})
+		middleware.AbortWithError(c, err, "failed to create user")
 		return
 	}
 
@@ -46,9 +56,7 @@ func (uc *UserController) createUserHandler(c *gin.Context) {
 func (uc *UserController) getAllUsersHandler(c *gin.Context) {
 	users, err := uc.userUc.GetAllUsers()
 	if err != nil {
-		c.JSON(500, gin.H{
-			"message": "failed to get users",
-		})
+		middleware.AbortWithError(c, err, "failed to get users")
 		return
 	}
 
@@ -61,9 +69,109 @@ func (uc *UserController) getUserByUsernameHandler(c *gin.Context) {
 	username := c.Param("username")
 	user, err := uc.userUc.GetUserByUsername(username)
 	if err != nil {
-		c.JSON(500, gin.H{
-			"message": "failed to get user",
-		})
+		middleware.AbortWithError(c, err, "failed to get user")
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"user": user,
+	})
+}
+
+func (uc *UserController) updateUserHandler(c *gin.Context) {
+	var request model.UpdateUserRequest
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		middleware.AbortWithBindError(c, err)
+		return
+	}
+
+	user, err := uc.userUc.UpdateUser(c.Param("username"), request, actor(c))
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to update user")
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"user": user,
+	})
+}
+
+func (uc *UserController) disableUserHandler(c *gin.Context) {
+	if uc.isOwnAccount(c) {
+		middleware.AbortWithError(c, errDisableOwnAccount, "")
+		return
+	}
+	uc.setDisabled(c, true)
+}
+
+func (uc *UserController) enableUserHandler(c *gin.Context) {
+	uc.setDisabled(c, false)
+}
+
+func (uc *UserController) setDisabled(c *gin.Context, disabled bool) {
+	err := uc.userUc.SetDisabled(c.Param("username"), disabled, actor(c))
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to update user")
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "success",
+	})
+}
+
+func (uc *UserController) deleteUserHandler(c *gin.Context) {
+	if uc.isOwnAccount(c) {
+		middleware.AbortWithError(c, errDeleteOwnAccount, "")
+		return
+	}
+
+	err := uc.userUc.DeleteUser(c.Param("username"), actor(c))
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to delete user")
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "success",
+	})
+}
+
+// resetPasswordHandler returns the temporary password in the response. It is
+// not stored anywhere in plain text, so this is the only chance to read it.
+func (uc *UserController) resetPasswordHandler(c *gin.Context) {
+	temporaryPassword, err := uc.userUc.ResetPassword(c.Param("username"), actor(c))
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to reset password")
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"temporary_password": temporaryPassword,
+	})
+}
+
+// unlockUserHandler clears the failed login counter of the username. Locked
+// client IPs are not affected and expire on their own.
+func (uc *UserController) unlockUserHandler(c *gin.Context) {
+	err := uc.loginThrottleUc.Unlock(c.Param("username"))
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to unlock user")
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"message": "success",
+	})
+}
+
+// meHandler returns the profile of the caller, whatever their permissions.
+func (uc *UserController) meHandler(c *gin.Context) {
+	claims := c.MustGet(middleware.ClaimsKey).(*modelutils.JwtPayloadClaims)
+	user, err := uc.userUc.GetUserByID(claims.UserId)
+	if err != nil {
+		middleware.AbortWithError(c, err, "failed to get user")
 		return
 	}
 
@@ -72,6 +180,20 @@ func (uc *UserController) getUserByUsernameHandler(c *gin.Context) {
 	})
 }
 
-func NewUserController(rg *gin.RouterGroup, userUc usecase.UserUsecase, authMiddleware *middleware.AuthMiddleware) *UserController {
-	return &UserController{userUc: userUc, rg: rg, authMiddleware: authMiddleware}
+// isOwnAccount reports whether the :username parameter is the caller, so an
+// admin cannot lock themselves out.
+func (uc *UserController) isOwnAccount(c *gin.Context) bool {
+	claims := c.MustGet(middleware.ClaimsKey).(*modelutils.JwtPayloadClaims)
+	user, err := uc.userUc.GetUserByUsername(c.Param("username"))
+	return err == nil && user.ID == claims.UserId
+}
+
+// actor identifies the caller of an admin endpoint for the audit log.
+func actor(c *gin.Context) model.Actor {
+	claims := c.MustGet(middleware.ClaimsKey).(*modelutils.JwtPayloadClaims)
+	return model.Actor{UserID: claims.UserId, IP: c.ClientIP()}
+}
+
+func NewUserController(rg *gin.RouterGroup, userUc usecase.UserUsecase, loginThrottleUc usecase.LoginThrottleUsecase, authMiddleware *middleware.AuthMiddleware) *UserController {
+	return &UserController{userUc: userUc, loginThrottleUc: loginThrottleUc, rg: rg, authMiddleware: authMiddleware}
 }
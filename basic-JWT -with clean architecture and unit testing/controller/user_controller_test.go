@@ -5,6 +5,7 @@ import (
 	"basic-JWT/mock/controller_mock"
 	"basic-JWT/mock/service_mock"
 	"basic-JWT/model"
+	"basic-JWT/usecase"
 	modelutils "basic-JWT/utils/model_utils"
 	"bytes"
 	"encoding/json"
@@ -14,6 +15,7 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -21,9 +23,11 @@ import (
 type UserControllerTest struct {
 	suite.Suite
 	userUc         *controller_mock.UserUsecaseMock
+	throttleUc     *controller_mock.LoginThrottleUsecaseMock
 	rg             *gin.Engine
 	authMiddleware *middleware.AuthMiddleware
 	jwtService     *service_mock.JWTServiceMock
+	rbacUc         *controller_mock.RbacUsecaseMock
 	uc             *UserController
 }
 
@@ -34,25 +38,29 @@ func TestUserControllerSuite(t *testing.T) {
 func (uc *UserControllerTest) SetupTest() {
 	uc.userUc = new(controller_mock.UserUsecaseMock)
 	uc.rg = gin.Default()
+	uc.rg.Use(middleware.ErrorHandler())
 	rg := uc.rg.Group("/api/v1")
 	uc.jwtService = new(service_mock.JWTServiceMock)
-	uc.authMiddleware = middleware.NewAuthMiddleware(uc.jwtService)
-	uc.uc = NewUserController(rg, uc.userUc, uc.authMiddleware)
+	uc.rbacUc = new(controller_mock.RbacUsecaseMock)
+	uc.rbacUc.On("HasPermission", "admin", mock.Anything).Return(true, nil)
+	uc.userUc.On("GetUserByID", 1).Return(model.User{ID: 1, Username: "admin", Role: "admin"}, nil)
+	uc.userUc.On("GetUserByID", 2).Return(model.User{ID: 2, Username: "user", Role: "user"}, nil)
+	uc.authMiddleware = middleware.NewAuthMiddleware(uc.jwtService, uc.rbacUc, uc.userUc, new(controller_mock.TokenRevocationUsecaseMock), new(controller_mock.SessionUsecaseMock), new(controller_mock.APIKeyUsecaseMock), nil)
+	uc.throttleUc = new(controller_mock.LoginThrottleUsecaseMock)
+	uc.uc = NewUserController(rg, uc.userUc, uc.throttleUc, uc.authMiddleware)
 	uc.uc.Route() // Register routes
 }
 
 func (uc *UserControllerTest) TestCreateUserHandler_Success() {
 	user := model.User{Username: "username", Password: "password", Role: "user"}
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
-	user.Password = string(hashedPassword)
 
-	// Mock the userUsecase.Create method
-	uc.userUc.On("Create", &user).Return(&user, nil).Once()
+	// Mock the userUsecase.CreateUser method
+	uc.userUc.On("CreateUser", model.CreateUserRequest{Username: user.Username, Password: user.Password, Role: user.Role}, mock.Anything).Return(user, nil).Once()
 
 	// Mock the jwtService.VerifyToken method
-	uc.jwtService.On("VerifyToken", "dummy_admin_token").Return(&modelutils.JwtPayloadClaims{Role: "admin"}, nil).Once()
+	uc.jwtService.On("VerifyToken", "dummy_admin_token").Return(&modelutils.JwtPayloadClaims{UserId: 1, Role: "admin"}, nil).Once()
 
-	requestBody, _ := json.Marshal(user)
+	requestBody, _ := json.Marshal(model.CreateUserRequest{Username: user.Username, Password: user.Password, Role: user.Role})
 	req, _ := http.NewRequest(http.MethodPost, "/api/v1/users", bytes.NewBuffer(requestBody))
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer dummy_admin_token") // Add a dummy token for authentication
@@ -78,16 +86,14 @@ func (uc *UserControllerTest) TestCreateUserHandler_Success() {
 
 func (uc *UserControllerTest) TestCreateUserHandler_Failed() {
 	user := model.User{Username: "username", Password: "password", Role: "user"}
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
-	user.Password = string(hashedPassword)
 
-	// Mock the userUsecase.Create method
-	uc.userUc.On("Create", &user).Return(&user, errors.New("some database error"))
+	// Mock the userUsecase.CreateUser method
+	uc.userUc.On("CreateUser", model.CreateUserRequest{Username: user.Username, Password: user.Password, Role: user.Role}, mock.Anything).Return(model.User{}, errors.New("some database error"))
 
 	// Mock the jwtService.VerifyToken method
-	uc.jwtService.On("VerifyToken", "dummy_admin_token").Return(&modelutils.JwtPayloadClaims{Role: "admin"}, nil).Once()
+	uc.jwtService.On("VerifyToken", "dummy_admin_token").Return(&modelutils.JwtPayloadClaims{UserId: 1, Role: "admin"}, nil).Once()
 
-	requestBody, _ := json.Marshal(user)
+	requestBody, _ := json.Marshal(model.CreateUserRequest{Username: user.Username, Password: user.Password, Role: user.Role})
 	req, _ := http.NewRequest(http.MethodPost, "/api/v1/users", bytes.NewBuffer(requestBody))
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer dummy_admin_token") // Add a dummy token for authentication
@@ -104,7 +110,7 @@ func (uc *UserControllerTest) TestGetAllUsersHandler_Success() {
 	uc.userUc.On("GetAllUsers").Return([]model.User{{Username: "user1"}, {Username: "user2"}}, nil)
 
 	// Mock the jwtService.VerifyToken method
-	uc.jwtService.On("VerifyToken", "dummy_admin_token").Return(&modelutils.JwtPayloadClaims{Role: "admin"}, nil).Once()
+	uc.jwtService.On("VerifyToken", "dummy_admin_token").Return(&modelutils.JwtPayloadClaims{UserId: 1, Role: "admin"}, nil).Once()
 
 	req, _ := http.NewRequest(http.MethodGet, "/api/v1/users", nil)
 	req.Header.Set("Content-Type", "application/json")
@@ -141,7 +147,7 @@ func (uc *UserControllerTest) TestGetAllUsersHandler_Failed() {
 	uc.userUc.On("GetAllUsers").Return(user, errors.New("some database error"))
 
 	// Mock the jwtService.VerifyToken method
-	uc.jwtService.On("VerifyToken", "dummy_admin_token").Return(&modelutils.JwtPayloadClaims{Role: "admin"}, nil).Once()
+	uc.jwtService.On("VerifyToken", "dummy_admin_token").Return(&modelutils.JwtPayloadClaims{UserId: 1, Role: "admin"}, nil).Once()
 
 	req, _ := http.NewRequest(http.MethodGet, "/api/v1/users", nil)
 	req.Header.Set("Content-Type", "application/json")
@@ -173,7 +179,7 @@ func (uc *UserControllerTest) TestGetUserByUsernameHandler_Success() {
 	uc.userUc.On("GetUserByUsername", "user1").Return(user[0], nil)
 
 	// Mock the jwtService.VerifyToken method
-	uc.jwtService.On("VerifyToken", "dummy_admin_token").Return(&modelutils.JwtPayloadClaims{Role: "admin"}, nil).Once()
+	uc.jwtService.On("VerifyToken", "dummy_admin_token").Return(&modelutils.JwtPayloadClaims{UserId: 1, Role: "admin"}, nil).Once()
 
 	req, _ := http.NewRequest(http.MethodGet, "/api/v1/users/user1", nil)
 	req.Header.Set("Content-Type", "application/json")
@@ -184,7 +190,8 @@ func (uc *UserControllerTest) TestGetUserByUsernameHandler_Success() {
 
 	uc.Equal(http.StatusOK, w.Code)
 	uc.Contains(w.Body.String(), "user1")
-	uc.Contains(w.Body.String(), "user1password")
+	uc.NotContains(w.Body.String(), "user1password")
+	uc.NotContains(w.Body.String(), "password")
 }
 
 func (uc *UserControllerTest) TestGetUserByUsernameHandler_Failed() {
@@ -192,7 +199,7 @@ func (uc *UserControllerTest) TestGetUserByUsernameHandler_Failed() {
 	uc.userUc.On("GetUserByUsername", "user1").Return(model.User{}, errors.New("some database error"))
 
 	// Mock the jwtService.VerifyToken method
-	uc.jwtService.On("VerifyToken", "dummy_admin_token").Return(&modelutils.JwtPayloadClaims{Role: "admin"}, nil).Once()
+	uc.jwtService.On("VerifyToken", "dummy_admin_token").Return(&modelutils.JwtPayloadClaims{UserId: 1, Role: "admin"}, nil).Once()
 
 	req, _ := http.NewRequest(http.MethodGet, "/api/v1/users/user1", nil)
 	req.Header.Set("Content-Type", "application/json")
@@ -204,3 +211,215 @@ func (uc *UserControllerTest) TestGetUserByUsernameHandler_Failed() {
 	uc.Equal(http.StatusInternalServerError, w.Code)
 	uc.Contains(w.Body.String(), "failed to get user")
 }
+
+func (uc *UserControllerTest) TestCreateUserHandler_MissingPermission() {
+	uc.rbacUc.On("HasPermission", "user", "users:create").Return(false, nil)
+	uc.jwtService.On("VerifyToken", "dummy_user_token").Return(&modelutils.JwtPayloadClaims{UserId: 2, Role: "user"}, nil).Once()
+
+	requestBody, _ := json.Marshal(model.CreateUserRequest{Username: "username", Password: "password"})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/users", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer dummy_user_token")
+
+	w := httptest.NewRecorder()
+	uc.rg.ServeHTTP(w, req)
+
+	uc.Equal(http.StatusForbidden, w.Code)
+	uc.userUc.AssertNotCalled(uc.T(), "Create", mock.Anything)
+}
+
+func (uc *UserControllerTest) TestUnlockUserHandler_Success() {
+	uc.throttleUc.On("Unlock", "username").Return(nil).Once()
+	uc.jwtService.On("VerifyToken", "dummy_admin_token").Return(&modelutils.JwtPayloadClaims{UserId: 1, Role: "admin"}, nil).Once()
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/users/username/unlock", nil)
+	req.Header.Set("Authorization", "Bearer dummy_admin_token")
+
+	w := httptest.NewRecorder()
+	uc.rg.ServeHTTP(w, req)
+
+	uc.Equal(http.StatusOK, w.Code)
+	uc.throttleUc.AssertExpectations(uc.T())
+}
+
+func (uc *UserControllerTest) TestUnlockUserHandler_Failed() {
+	uc.throttleUc.On("Unlock", "username").Return(errors.New("error")).Once()
+	uc.jwtService.On("VerifyToken", "dummy_admin_token").Return(&modelutils.JwtPayloadClaims{UserId: 1, Role: "admin"}, nil).Once()
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/users/username/unlock", nil)
+	req.Header.Set("Authorization", "Bearer dummy_admin_token")
+
+	w := httptest.NewRecorder()
+	uc.rg.ServeHTTP(w, req)
+
+	uc.Equal(http.StatusInternalServerError, w.Code)
+	uc.Contains(w.Body.String(), "failed to unlock user")
+}
+
+func (uc *UserControllerTest) adminRequest(method, path string, body interface{}) *httptest.ResponseRecorder {
+	uc.jwtService.On("VerifyToken", "dummy_admin_token").Return(&modelutils.JwtPayloadClaims{UserId: 1, Role: "admin", Mfa: true}, nil).Once()
+
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer dummy_admin_token")
+
+	w := httptest.NewRecorder()
+	uc.rg.ServeHTTP(w, req)
+	return w
+}
+
+func (uc *UserControllerTest) TestGetAllUsersHandler_NeverSerializesPassword() {
+	uc.userUc.On("GetAllUsers").Return([]model.User{{ID: 2, Username: "user1", Password: "$2a$10$hash", Role: "user"}}, nil)
+
+	w := uc.adminRequest(http.MethodGet, "/api/v1/users", nil)
+
+	uc.Equal(http.StatusOK, w.Code)
+	uc.NotContains(w.Body.String(), "$2a$10$hash")
+	uc.NotContains(w.Body.String(), "password")
+}
+
+func (uc *UserControllerTest) TestUpdateUserHandler_Success() {
+	request := model.UpdateUserRequest{Role: "admin"}
+	uc.userUc.On("UpdateUser", "user1", request, mock.Anything).Return(model.User{ID: 2, Username: "user1", Role: "admin"}, nil).Once()
+
+	w := uc.adminRequest(http.MethodPut, "/api/v1/users/user1", request)
+
+	uc.Equal(http.StatusOK, w.Code)
+	uc.Contains(w.Body.String(), `"role":"admin"`)
+}
+
+func (uc *UserControllerTest) TestUpdateUserHandler_UsernameTaken() {
+	request := model.UpdateUserRequest{Username: "user2"}
+	uc.userUc.On("UpdateUser", "user1", request, mock.Anything).Return(model.User{}, usecase.NewDomainError(usecase.KindConflict, "username 'user2' is already taken")).Once()
+
+	w := uc.adminRequest(http.MethodPut, "/api/v1/users/user1", request)
+
+	uc.Equal(http.StatusConflict, w.Code)
+}
+
+func (uc *UserControllerTest) TestUpdateUserHandler_NotFound() {
+	request := model.UpdateUserRequest{Role: "admin"}
+	uc.userUc.On("UpdateUser", "ghost", request, mock.Anything).Return(model.User{}, usecase.NewDomainError(usecase.KindNotFound, "user with username ghost not found")).Once()
+
+	w := uc.adminRequest(http.MethodPut, "/api/v1/users/ghost", request)
+
+	uc.Equal(http.StatusNotFound, w.Code)
+	uc.Equal("application/problem+json", w.Header().Get("Content-Type"))
+	uc.JSONEq(`{"type":"about:blank","title":"Not Found","status":404,"detail":"user with username ghost not found","instance":"/api/v1/users/ghost"}`, w.Body.String())
+}
+
+func (uc *UserControllerTest) TestDisableUserHandler_Success() {
+	uc.userUc.On("GetUserByUsername", "user1").Return(model.User{ID: 2, Username: "user1"}, nil)
+	uc.userUc.On("SetDisabled", "user1", true, mock.Anything).Return(nil).Once()
+
+	w := uc.adminRequest(http.MethodPost, "/api/v1/users/user1/disable", nil)
+
+	uc.Equal(http.StatusOK, w.Code)
+	uc.userUc.AssertCalled(uc.T(), "SetDisabled", "user1", true, mock.Anything)
+}
+
+func (uc *UserControllerTest) TestDisableUserHandler_OwnAccount() {
+	uc.userUc.On("GetUserByUsername", "admin").Return(model.User{ID: 1, Username: "admin"}, nil)
+
+	w := uc.adminRequest(http.MethodPost, "/api/v1/users/admin/disable", nil)
+
+	uc.Equal(http.StatusBadRequest, w.Code)
+	uc.userUc.AssertNotCalled(uc.T(), "SetDisabled", "admin", true, mock.Anything)
+}
+
+func (uc *UserControllerTest) TestEnableUserHandler_Success() {
+	uc.userUc.On("SetDisabled", "user1", false, mock.Anything).Return(nil).Once()
+
+	w := uc.adminRequest(http.MethodPost, "/api/v1/users/user1/enable", nil)
+
+	uc.Equal(http.StatusOK, w.Code)
+}
+
+func (uc *UserControllerTest) TestDeleteUserHandler_Success() {
+	uc.userUc.On("GetUserByUsername", "user1").Return(model.User{ID: 2, Username: "user1"}, nil)
+	uc.userUc.On("DeleteUser", "user1", mock.Anything).Return(nil).Once()
+
+	w := uc.adminRequest(http.MethodDelete, "/api/v1/users/user1", nil)
+
+	uc.Equal(http.StatusOK, w.Code)
+	// the signed in admin is recorded as the actor in the audit log
+	uc.userUc.AssertCalled(uc.T(), "DeleteUser", "user1", mock.MatchedBy(func(actor model.Actor) bool { return actor.UserID == 1 }))
+}
+
+func (uc *UserControllerTest) TestDeleteUserHandler_OwnAccount() {
+	uc.userUc.On("GetUserByUsername", "admin").Return(model.User{ID: 1, Username: "admin"}, nil)
+
+	w := uc.adminRequest(http.MethodDelete, "/api/v1/users/admin", nil)
+
+	uc.Equal(http.StatusBadRequest, w.Code)
+	uc.userUc.AssertNotCalled(uc.T(), "DeleteUser", "admin", mock.Anything)
+}
+
+func (uc *UserControllerTest) TestDeleteUserHandler_NotFound() {
+	uc.userUc.On("GetUserByUsername", "ghost").Return(model.User{}, usecase.NewDomainError(usecase.KindNotFound, "user with username ghost not found"))
+	uc.userUc.On("DeleteUser", "ghost", mock.Anything).Return(usecase.NewDomainError(usecase.KindNotFound, "user with username ghost not found")).Once()
+
+	w := uc.adminRequest(http.MethodDelete, "/api/v1/users/ghost", nil)
+
+	uc.Equal(http.StatusNotFound, w.Code)
+}
+
+func (uc *UserControllerTest) TestResetPasswordHandler_Success() {
+	uc.userUc.On("ResetPassword", "user1", mock.Anything).Return("temporary-secret", nil).Once()
+
+	w := uc.adminRequest(http.MethodPost, "/api/v1/users/user1/reset-password", nil)
+
+	uc.Equal(http.StatusOK, w.Code)
+	uc.Contains(w.Body.String(), "temporary-secret")
+}
+
+func (uc *UserControllerTest) TestResetPasswordHandler_Failed() {
+	uc.userUc.On("ResetPassword", "user1", mock.Anything).Return("", errors.New("error")).Once()
+
+	w := uc.adminRequest(http.MethodPost, "/api/v1/users/user1/reset-password", nil)
+
+	uc.Equal(http.StatusInternalServerError, w.Code)
+	uc.Contains(w.Body.String(), "failed to reset password")
+}
+
+func (uc *UserControllerTest) TestMeHandler_Success() {
+	uc.userUc.ExpectedCalls = nil
+	uc.userUc.On("GetUserByID", 1).Return(model.User{ID: 1, Username: "admin", Password: "$2a$10$hash", Role: "admin"}, nil)
+
+	w := uc.adminRequest(http.MethodGet, "/api/v1/me", nil)
+
+	uc.Equal(http.StatusOK, w.Code)
+	uc.Contains(w.Body.String(), `"username":"admin"`)
+	uc.NotContains(w.Body.String(), "$2a$10$hash")
+}
+
+func (uc *UserControllerTest) TestMeHandler_Unauthenticated() {
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/me", nil)
+	w := httptest.NewRecorder()
+	uc.rg.ServeHTTP(w, req)
+
+	uc.Equal(http.StatusUnauthorized, w.Code)
+}
+
+func (uc *UserControllerTest) TestCreateUserHandler_WeakPassword() {
+	request := model.CreateUserRequest{Username: "username", Password: "short", Role: "user"}
+	uc.userUc.On("CreateUser", request, mock.Anything).Return(model.User{}, usecase.NewDomainError(usecase.KindValidation, "password is too short: it must be at least 8 characters long")).Once()
+
+	w := uc.adminRequest(http.MethodPost, "/api/v1/users", request)
+
+	uc.Equal(http.StatusBadRequest, w.Code)
+	uc.Contains(w.Body.String(), "at least 8 characters")
+}
+
+func (uc *UserControllerTest) TestCreateUserHandler_UsernameTaken() {
+	request := model.CreateUserRequest{Username: "user1", Password: "correct horse battery", Role: "user"}
+	uc.userUc.On("CreateUser", request, mock.Anything).Return(model.User{}, usecase.NewDomainError(usecase.KindConflict, "username 'user1' is already taken")).Once()
+
+	w := uc.adminRequest(http.MethodPost, "/api/v1/users", request)
+
+	uc.Equal(http.StatusConflict, w.Code)
+}
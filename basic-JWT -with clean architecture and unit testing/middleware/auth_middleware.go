@@ -1,40 +1,134 @@
 package middleware
 
 import (
+	"basic-JWT/usecase"
 	"basic-JWT/utils/service"
+	"slices"
 	"strings"
 
+	modelutils "basic-JWT/utils/model_utils"
+
 	"github.com/gin-gonic/gin"
 )
 
+// ClaimsKey is the gin context key holding the authenticated *modelutils.JwtPayloadClaims.
+const ClaimsKey = "claims"
+
+// APIKeyHeader carries an API key, the alternative to a bearer token on
+// routes protected by RequirePermission.
+const APIKeyHeader = "X-API-Key"
+
+var (
+	ErrMfaRequired       = usecase.NewDomainError(usecase.KindForbidden, "mfa required")
+	ErrInsufficientScope = usecase.NewDomainError(usecase.KindForbidden, "insufficient scope")
+)
+
 type AuthMiddleware struct {
+	// RequireToken only accepts bearer tokens. It checks roles, which API key
+	// scopes cannot be matched against.
 	RequireToken func(roles ...string) gin.HandlerFunc
+	// RequirePermission also accepts an API key in the X-API-Key header. The
+	// key must then list every permission in its scopes as well.
+	RequirePermission func(permissions ...string) gin.HandlerFunc
+	// RequireAuthenticated accepts any signed in user regardless of role or
+	// permissions, for endpoints that only act on the caller's own account.
+	RequireAuthenticated func() gin.HandlerFunc
+	// RequireTokenForMfaSetup accepts any valid access token, including one
+	// that does not meet the MFA requirement of its role. Only the MFA
+	// enrollment routes may use it.
+	RequireTokenForMfaSetup func() gin.HandlerFunc
 }
 
 type authMiddleware struct {
-	jwtService service.JWTservice
+	jwtService       service.JWTservice
+	rbacUsecase      usecase.RbacUsecase
+	userUsecase      usecase.UserUsecase
+	tokenRevocation  usecase.TokenRevocationUsecase
+	sessionUsecase   usecase.SessionUsecase
+	apiKeyUsecase    usecase.APIKeyUsecase
+	mfaRequiredRoles map[string]bool
 }
 
-func (a *authMiddleware) requireToken(roles ...string) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		tokenString := c.GetHeader("Authorization")
-		
-		if !strings.HasPrefix(tokenString, "Bearer ") {
-			c.JSON(401, gin.H{
-				"message": "unauthorized",
-			})
-			c.Abort()
-			return
+// authenticate verifies the bearer token and stores its claims in the
+// context. It aborts with a 401 when the token is missing, invalid or
+// revoked, when its session has ended, or when the account has been disabled
+// or deleted since the token was issued. The role in the claims is replaced
+// by the stored one, so a role change takes effect without waiting for the
+// token to expire.
+func (a *authMiddleware) authenticate(c *gin.Context) (*modelutils.JwtPayloadClaims, bool) {
+	tokenString := c.GetHeader("Authorization")
+
+	if !strings.HasPrefix(tokenString, "Bearer ") {
+		AbortWithError(c, usecase.ErrUnauthorized, "")
+		return nil, false
+	}
+	// trim bearer
+	tokenString = tokenString[7:]
+
+	claims, err := a.jwtService.VerifyToken(tokenString)
+	if err != nil {
+		AbortWithError(c, usecase.ErrUnauthorized, "")
+		return nil, false
+	}
+
+	// tokens issued before jti was added cannot be revoked and are let through until they expire
+	if claims.ID != "" {
+		revoked, err := a.tokenRevocation.IsRevoked(claims.ID)
+		if err != nil {
+			AbortWithError(c, err, "failed to verify token")
+			return nil, false
+		}
+		if revoked {
+			AbortWithError(c, usecase.ErrUnauthorized, "")
+			return nil, false
 		}
-		// trim bearer
-		tokenString = tokenString[7:]
+	}
 
-		claims, err := a.jwtService.VerifyToken(tokenString)
+	// likewise for tokens issued before logins were recorded as sessions
+	if claims.Sid != "" {
+		active, err := a.sessionUsecase.IsActive(claims.Sid)
 		if err != nil {
-			c.JSON(401, gin.H{
-				"message": "unauthorized",
-			})
-			c.Abort()
+			AbortWithError(c, err, "failed to verify session")
+			return nil, false
+		}
+		if !active {
+			AbortWithError(c, usecase.ErrUnauthorized, "")
+			return nil, false
+		}
+	}
+
+	// the lookup also fails for deleted users, so both cases end up here
+	user, err := a.userUsecase.GetUserByID(claims.UserId)
+	if err != nil || user.Disabled {
+		AbortWithError(c, usecase.ErrUnauthorized, "")
+		return nil, false
+	}
+	claims.Role = user.Role
+
+	c.Set(ClaimsKey, claims)
+	return claims, true
+}
+
+// authenticateWithMfa is authenticate plus the MFA requirement: roles listed
+// in mfaRequiredRoles need a token issued after a TOTP step.
+func (a *authMiddleware) authenticateWithMfa(c *gin.Context) (*modelutils.JwtPayloadClaims, bool) {
+	claims, ok := a.authenticate(c)
+	if !ok {
+		return nil, false
+	}
+
+	if a.mfaRequiredRoles[claims.Role] && !claims.Mfa {
+		AbortWithError(c, ErrMfaRequired, "")
+		return nil, false
+	}
+
+	return claims, true
+}
+
+func (a *authMiddleware) requireToken(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := a.authenticateWithMfa(c)
+		if !ok {
 			return
 		}
 
@@ -45,16 +139,99 @@ func (a *authMiddleware) requireToken(roles ...string) gin.HandlerFunc {
 			}
 		}
 
-		c.JSON(403, gin.H{
-			"message": "forbidden",
-		})
-		c.Abort()
+		AbortWithError(c, usecase.ErrForbidden, "")
 	}
 }
 
-func NewAuthMiddleware(jwtService service.JWTservice) *AuthMiddleware {
+// authenticateAPIKey resolves the API key to the claims of its owner and
+// stores them in the context like authenticate does. The MFA requirement does
+// not apply: a batch job cannot pass a TOTP step, and creating the key
+// already required an administrator.
+func (a *authMiddleware) authenticateAPIKey(c *gin.Context, apiKey string) (*modelutils.JwtPayloadClaims, bool) {
+	claims, err := a.apiKeyUsecase.Authenticate(apiKey)
+	if err != nil {
+		AbortWithError(c, err, "failed to verify api key")
+		return nil, false
+	}
+
+	c.Set(ClaimsKey, claims)
+	return claims, true
+}
+
+// requirePermission allows the request only when the caller's role has been
+// granted every listed permission. For an API key, each permission must be
+// one of its scopes too, so revoking a permission from the role of the owner
+// takes it away from the key as well.
+func (a *authMiddleware) requirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		apiKey := c.GetHeader(APIKeyHeader)
+
+		var claims *modelutils.JwtPayloadClaims
+		var ok bool
+		if apiKey != "" {
+			claims, ok = a.authenticateAPIKey(c, apiKey)
+		} else {
+			claims, ok = a.authenticateWithMfa(c)
+		}
+		if !ok {
+			return
+		}
+
+		for _, permission := range permissions {
+			allowed, err := a.rbacUsecase.HasPermission(claims.Role, permission)
+			if err != nil {
+				AbortWithError(c, err, "failed to resolve permissions")
+				return
+			}
+			if !allowed {
+				AbortWithError(c, usecase.ErrForbidden, "")
+				return
+			}
+			if apiKey != "" && !slices.Contains(strings.Fields(claims.Scope), permission) {
+				AbortWithError(c, ErrInsufficientScope, "")
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+func (a *authMiddleware) requireAuthenticated() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := a.authenticateWithMfa(c); !ok {
+			return
+		}
+		c.Next()
+	}
+}
+
+func (a *authMiddleware) requireTokenForMfaSetup() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := a.authenticate(c); !ok {
+			return
+		}
+		c.Next()
+	}
+}
+
+func NewAuthMiddleware(jwtService service.JWTservice, rbacUsecase usecase.RbacUsecase, userUsecase usecase.UserUsecase, tokenRevocation usecase.TokenRevocationUsecase, sessionUsecase usecase.SessionUsecase, apiKeyUsecase usecase.APIKeyUsecase, mfaRequiredRoles []string) *AuthMiddleware {
 	am := &authMiddleware{
-		jwtService: jwtService,
+		jwtService:       jwtService,
+		rbacUsecase:      rbacUsecase,
+		userUsecase:      userUsecase,
+		tokenRevocation:  tokenRevocation,
+		sessionUsecase:   sessionUsecase,
+		apiKeyUsecase:    apiKeyUsecase,
+		mfaRequiredRoles: map[string]bool{},
+	}
+	for _, role := range mfaRequiredRoles {
+		am.mfaRequiredRoles[role] = true
+	}
+	return &AuthMiddleware{
+		RequireToken:            am.requireToken,
+		RequirePermission:       am.requirePermission,
+		RequireAuthenticated:    am.requireAuthenticated,
+		RequireTokenForMfaSetup: am.requireTokenForMfaSetup,
 	}
-	return &AuthMiddleware{RequireToken: am.requireToken}
 }
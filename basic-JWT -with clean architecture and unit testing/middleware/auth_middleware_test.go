@@ -1,8 +1,10 @@
 package middleware
 
 import (
+	"basic-JWT/mock/controller_mock"
 	"basic-JWT/mock/service_mock"
 	"basic-JWT/model"
+	"basic-JWT/usecase"
 	modelutils "basic-JWT/utils/model_utils"
 	"errors"
 	"net/http"
@@ -10,14 +12,19 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
 
 type AuthMiddlewareSuite struct {
 	suite.Suite
-	authMiddleware *AuthMiddleware
-	jwtService     *service_mock.JWTServiceMock // Use the mock service
-
+	authMiddleware  *AuthMiddleware
+	jwtService      *service_mock.JWTServiceMock // Use the mock service
+	rbacUc          *controller_mock.RbacUsecaseMock
+	userUc          *controller_mock.UserUsecaseMock
+	tokenRevocation *controller_mock.TokenRevocationUsecaseMock
+	sessionUc       *controller_mock.SessionUsecaseMock
+	apiKeyUc        *controller_mock.APIKeyUsecaseMock
 }
 
 func TestAuthMiddlewareSuite(t *testing.T) {
@@ -26,7 +33,19 @@ func TestAuthMiddlewareSuite(t *testing.T) {
 
 func (a *AuthMiddlewareSuite) SetupTest() {
 	a.jwtService = new(service_mock.JWTServiceMock)
-	a.authMiddleware = NewAuthMiddleware(a.jwtService)
+	a.rbacUc = new(controller_mock.RbacUsecaseMock)
+	a.userUc = new(controller_mock.UserUsecaseMock)
+	a.tokenRevocation = new(controller_mock.TokenRevocationUsecaseMock)
+	a.sessionUc = new(controller_mock.SessionUsecaseMock)
+	a.apiKeyUc = new(controller_mock.APIKeyUsecaseMock)
+	a.authMiddleware = NewAuthMiddleware(a.jwtService, a.rbacUc, a.userUc, a.tokenRevocation, a.sessionUc, a.apiKeyUc, []string{"admin"})
+}
+
+// verifyToken makes "valid_token" verify to claims, issued to a user who
+// still exists and holds the role in the claims.
+func (a *AuthMiddlewareSuite) verifyToken(claims *modelutils.JwtPayloadClaims) {
+	a.jwtService.On("VerifyToken", "valid_token").Return(claims, nil).Once()
+	a.userUc.On("GetUserByID", claims.UserId).Return(model.User{ID: claims.UserId, Role: claims.Role}, nil).Once()
 }
 
 func (a *AuthMiddlewareSuite) TestRequireToken_Success() {
@@ -37,11 +56,11 @@ func (a *AuthMiddlewareSuite) TestRequireToken_Success() {
 	c.Request.Header.Set("Authorization", "Bearer valid_token")
 
 	// Mock the JWT service to return a valid token
-	a.jwtService.On("VerifyToken", "valid_token").Return(&modelutils.JwtPayloadClaims{Role: "user"}, nil).Once()
+	a.verifyToken(&modelutils.JwtPayloadClaims{Role: "user"})
 
 	// Call the middleware
 	handler := a.authMiddleware.RequireToken("user")
-	handler(c)
+	serve(c, handler)
 
 	// Assert that the request was not aborted and no error was returned
 	a.False(c.IsAborted())
@@ -56,7 +75,7 @@ func (a *AuthMiddlewareSuite) TestRequireToken_NoToken() {
 
 	// Call the middleware
 	handler := a.authMiddleware.RequireToken("user")
-	handler(c)
+	serve(c, handler)
 
 	// Assert that the request was aborted and an unauthorized response was returned
 	a.True(c.IsAborted())
@@ -76,7 +95,7 @@ func (a *AuthMiddlewareSuite) TestRequireToken_InvalidToken() {
 
 	// Call the middleware
 	handler := a.authMiddleware.RequireToken("user")
-	handler(c)
+	serve(c, handler)
 
 	// Assert that the request was aborted and an unauthorized response was returned
 	a.True(c.IsAborted())
@@ -92,11 +111,11 @@ func (a *AuthMiddlewareSuite) TestRequireToken_ForbiddenRole() {
 	c.Request.Header.Set("Authorization", "Bearer valid_token")
 
 	// Mock the JWT service to return a token with a 'user' role
-	a.jwtService.On("VerifyToken", "valid_token").Return(&modelutils.JwtPayloadClaims{Role: "user"}, nil).Once()
+	a.verifyToken(&modelutils.JwtPayloadClaims{Role: "user"})
 
 	// Call the middleware, requiring an 'admin' role
 	handler := a.authMiddleware.RequireToken("admin")
-	handler(c)
+	serve(c, handler)
 
 	// Assert that the request was aborted and a forbidden response was returned
 	a.True(c.IsAborted())
@@ -104,6 +123,68 @@ func (a *AuthMiddlewareSuite) TestRequireToken_ForbiddenRole() {
 	a.Contains(w.Body.String(), "forbidden")
 }
 
+func (a *AuthMiddlewareSuite) TestRequireToken_MfaRequired() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Authorization", "Bearer valid_token")
+
+	a.verifyToken(&modelutils.JwtPayloadClaims{Role: "admin"})
+
+	handler := a.authMiddleware.RequireToken("admin")
+	serve(c, handler)
+
+	a.True(c.IsAborted())
+	a.Equal(http.StatusForbidden, w.Code)
+	a.Contains(w.Body.String(), "mfa required")
+}
+
+func (a *AuthMiddlewareSuite) TestRequireToken_MfaSatisfied() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Authorization", "Bearer valid_token")
+
+	a.verifyToken(&modelutils.JwtPayloadClaims{Role: "admin", Mfa: true})
+
+	handler := a.authMiddleware.RequireToken("admin")
+	serve(c, handler)
+
+	a.False(c.IsAborted())
+}
+
+func (a *AuthMiddlewareSuite) TestRequireTokenForMfaSetup_SkipsMfa() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Authorization", "Bearer valid_token")
+
+	a.verifyToken(&modelutils.JwtPayloadClaims{Role: "admin"})
+
+	handler := a.authMiddleware.RequireTokenForMfaSetup()
+	serve(c, handler)
+
+	a.False(c.IsAborted())
+	_, exists := c.Get(ClaimsKey)
+	a.True(exists)
+}
+
+func (a *AuthMiddlewareSuite) TestRequirePermission_MfaRequired() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Authorization", "Bearer valid_token")
+
+	a.verifyToken(&modelutils.JwtPayloadClaims{Role: "admin"})
+
+	handler := a.authMiddleware.RequirePermission("rbac:manage")
+	serve(c, handler)
+
+	a.True(c.IsAborted())
+	a.Equal(http.StatusForbidden, w.Code)
+	a.rbacUc.AssertNotCalled(a.T(), "HasPermission", "admin", "rbac:manage")
+}
+
 func (a *AuthMiddlewareSuite) TestRequireToken_MultipleRoles_Success() {
 	// Create a dummy Gin context
 	w := httptest.NewRecorder()
@@ -112,11 +193,11 @@ func (a *AuthMiddlewareSuite) TestRequireToken_MultipleRoles_Success() {
 	c.Request.Header.Set("Authorization", "Bearer valid_token")
 
 	// Mock the JWT service to return a token with a 'user' role
-	a.jwtService.On("VerifyToken", "valid_token").Return(&modelutils.JwtPayloadClaims{Role: "user"}, nil).Once()
+	a.verifyToken(&modelutils.JwtPayloadClaims{Role: "user"})
 
 	// Call the middleware, requiring either 'admin' or 'user' role
 	handler := a.authMiddleware.RequireToken("admin", "user")
-	handler(c)
+	serve(c, handler)
 
 	// Assert that the request was not aborted
 	a.False(c.IsAborted())
@@ -143,7 +224,346 @@ func (a *AuthMiddlewareSuite) TestVerifyToken_Success() {
 
 }
 
+func (a *AuthMiddlewareSuite) TestRequirePermission_Success() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Authorization", "Bearer valid_token")
+
+	claims := &modelutils.JwtPayloadClaims{UserId: 1, Role: "editor"}
+	a.verifyToken(claims)
+	a.rbacUc.On("HasPermission", "editor", "users:create").Return(true, nil).Once()
+
+	handler := a.authMiddleware.RequirePermission("users:create")
+	serve(c, handler)
+
+	a.False(c.IsAborted())
+	stored, exists := c.Get(ClaimsKey)
+	a.True(exists)
+	a.Equal(claims, stored)
+}
+
+func (a *AuthMiddlewareSuite) TestRequirePermission_Forbidden() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Authorization", "Bearer valid_token")
+
+	a.verifyToken(&modelutils.JwtPayloadClaims{Role: "user"})
+	a.rbacUc.On("HasPermission", "user", "users:read").Return(true, nil).Once()
+	a.rbacUc.On("HasPermission", "user", "users:create").Return(false, nil).Once()
+
+	// every listed permission is required
+	handler := a.authMiddleware.RequirePermission("users:read", "users:create")
+	serve(c, handler)
+
+	a.True(c.IsAborted())
+	a.Equal(http.StatusForbidden, w.Code)
+	a.Contains(w.Body.String(), "forbidden")
+}
+
+func (a *AuthMiddlewareSuite) TestRequirePermission_NoToken() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+
+	handler := a.authMiddleware.RequirePermission("users:read")
+	serve(c, handler)
+
+	a.True(c.IsAborted())
+	a.Equal(http.StatusUnauthorized, w.Code)
+	a.rbacUc.AssertNotCalled(a.T(), "HasPermission", "", "users:read")
+}
+
+func (a *AuthMiddlewareSuite) TestRequirePermission_ResolveFailed() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Authorization", "Bearer valid_token")
+
+	a.verifyToken(&modelutils.JwtPayloadClaims{Role: "user"})
+	a.rbacUc.On("HasPermission", "user", "users:read").Return(false, errors.New("db down")).Once()
+
+	handler := a.authMiddleware.RequirePermission("users:read")
+	serve(c, handler)
+
+	a.True(c.IsAborted())
+	a.Equal(http.StatusInternalServerError, w.Code)
+}
+
+func (a *AuthMiddlewareSuite) TestRequirePermission_APIKey() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set(APIKeyHeader, "bjk_prefix.secret")
+
+	// mfa is required for admins, but not for their keys
+	claims := &modelutils.JwtPayloadClaims{UserId: 1, Role: "admin", Scope: "users:read users:create"}
+	a.apiKeyUc.On("Authenticate", "bjk_prefix.secret").Return(claims, nil).Once()
+	a.rbacUc.On("HasPermission", "admin", "users:read").Return(true, nil).Once()
+
+	handler := a.authMiddleware.RequirePermission("users:read")
+	serve(c, handler)
+
+	a.False(c.IsAborted())
+	stored, exists := c.Get(ClaimsKey)
+	a.True(exists)
+	a.Equal(claims, stored)
+	a.jwtService.AssertNotCalled(a.T(), "VerifyToken", mock.Anything)
+}
+
+func (a *AuthMiddlewareSuite) TestRequirePermission_APIKeyMissingScope() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set(APIKeyHeader, "bjk_prefix.secret")
+
+	claims := &modelutils.JwtPayloadClaims{UserId: 1, Role: "admin", Scope: "users:read"}
+	a.apiKeyUc.On("Authenticate", "bjk_prefix.secret").Return(claims, nil).Once()
+	a.rbacUc.On("HasPermission", "admin", "users:create").Return(true, nil).Once()
+
+	handler := a.authMiddleware.RequirePermission("users:create")
+	serve(c, handler)
+
+	a.True(c.IsAborted())
+	a.Equal(http.StatusForbidden, w.Code)
+	a.Contains(w.Body.String(), "insufficient scope")
+}
+
+func (a *AuthMiddlewareSuite) TestRequirePermission_InvalidAPIKey() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set(APIKeyHeader, "bjk_prefix.wrong")
+
+	a.apiKeyUc.On("Authenticate", "bjk_prefix.wrong").Return(nil, usecase.ErrInvalidAPIKey).Once()
+
+	handler := a.authMiddleware.RequirePermission("users:read")
+	serve(c, handler)
+
+	a.True(c.IsAborted())
+	a.Equal(http.StatusUnauthorized, w.Code)
+	a.rbacUc.AssertNotCalled(a.T(), "HasPermission", mock.Anything, mock.Anything)
+}
+
+func (a *AuthMiddlewareSuite) TestRequireToken_IgnoresAPIKey() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set(APIKeyHeader, "bjk_prefix.secret")
+
+	handler := a.authMiddleware.RequireToken("admin")
+	serve(c, handler)
+
+	a.True(c.IsAborted())
+	a.Equal(http.StatusUnauthorized, w.Code)
+	a.apiKeyUc.AssertNotCalled(a.T(), "Authenticate", mock.Anything)
+}
+
+func (a *AuthMiddlewareSuite) TestRequireToken_DisabledUser() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Authorization", "Bearer valid_token")
+
+	// the token itself is still valid, the account was disabled afterwards
+	a.jwtService.On("VerifyToken", "valid_token").Return(&modelutils.JwtPayloadClaims{UserId: 7, Role: "user"}, nil).Once()
+	a.userUc.On("GetUserByID", 7).Return(model.User{ID: 7, Username: "bob", Role: "user", Disabled: true}, nil).Once()
+
+	handler := a.authMiddleware.RequireToken("user")
+	serve(c, handler)
+
+	a.True(c.IsAborted())
+	a.Equal(http.StatusUnauthorized, w.Code)
+}
+
+func (a *AuthMiddlewareSuite) TestRequirePermission_DeletedUser() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Authorization", "Bearer valid_token")
+
+	a.jwtService.On("VerifyToken", "valid_token").Return(&modelutils.JwtPayloadClaims{UserId: 8, Role: "admin", Mfa: true}, nil).Once()
+	a.userUc.On("GetUserByID", 8).Return(model.User{}, errors.New("user with id 8 not found")).Once()
+
+	handler := a.authMiddleware.RequirePermission("users:read")
+	serve(c, handler)
+
+	a.True(c.IsAborted())
+	a.Equal(http.StatusUnauthorized, w.Code)
+	a.rbacUc.AssertNotCalled(a.T(), "HasPermission", "admin", "users:read")
+}
+
+func (a *AuthMiddlewareSuite) TestRequireToken_DemotedUser() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Authorization", "Bearer valid_token")
 
+	// the token was issued while the user was still an admin
+	a.jwtService.On("VerifyToken", "valid_token").Return(&modelutils.JwtPayloadClaims{UserId: 9, Role: "admin", Mfa: true}, nil).Once()
+	a.userUc.On("GetUserByID", 9).Return(model.User{ID: 9, Username: "carol", Role: "user"}, nil).Once()
 
+	handler := a.authMiddleware.RequireToken("admin")
+	serve(c, handler)
+
+	a.True(c.IsAborted())
+	a.Equal(http.StatusForbidden, w.Code)
+}
 
-	
+func (a *AuthMiddlewareSuite) TestRequirePermission_DemotedUser() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Authorization", "Bearer valid_token")
+
+	a.jwtService.On("VerifyToken", "valid_token").Return(&modelutils.JwtPayloadClaims{UserId: 9, Role: "admin", Mfa: true}, nil).Once()
+	a.userUc.On("GetUserByID", 9).Return(model.User{ID: 9, Username: "carol", Role: "user"}, nil).Once()
+	a.rbacUc.On("HasPermission", "user", "users:create").Return(false, nil).Once()
+
+	handler := a.authMiddleware.RequirePermission("users:create")
+	serve(c, handler)
+
+	a.True(c.IsAborted())
+	a.Equal(http.StatusForbidden, w.Code)
+	a.rbacUc.AssertNotCalled(a.T(), "HasPermission", "admin", "users:create")
+}
+
+func (a *AuthMiddlewareSuite) TestRequireToken_RevokedToken() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Authorization", "Bearer valid_token")
+
+	claims := &modelutils.JwtPayloadClaims{UserId: 7, Role: "user"}
+	claims.ID = "stolen"
+	a.verifyToken(claims)
+	a.tokenRevocation.On("IsRevoked", "stolen").Return(true, nil).Once()
+
+	handler := a.authMiddleware.RequireToken("user")
+	serve(c, handler)
+
+	a.True(c.IsAborted())
+	a.Equal(http.StatusUnauthorized, w.Code)
+	a.userUc.AssertNotCalled(a.T(), "GetUserByID", 7)
+}
+
+func (a *AuthMiddlewareSuite) TestRequireToken_NotRevoked() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Authorization", "Bearer valid_token")
+
+	claims := &modelutils.JwtPayloadClaims{UserId: 7, Role: "user"}
+	claims.ID = "jti"
+	a.verifyToken(claims)
+	a.tokenRevocation.On("IsRevoked", "jti").Return(false, nil).Once()
+
+	handler := a.authMiddleware.RequireToken("user")
+	serve(c, handler)
+
+	a.False(c.IsAborted())
+}
+
+func (a *AuthMiddlewareSuite) TestRequireToken_ActiveSession() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Authorization", "Bearer valid_token")
+
+	a.verifyToken(&modelutils.JwtPayloadClaims{UserId: 7, Role: "user", Sid: "sid"})
+	a.sessionUc.On("IsActive", "sid").Return(true, nil).Once()
+
+	handler := a.authMiddleware.RequireToken("user")
+	serve(c, handler)
+
+	a.False(c.IsAborted())
+	a.sessionUc.AssertExpectations(a.T())
+}
+
+func (a *AuthMiddlewareSuite) TestRequireToken_EndedSession() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Authorization", "Bearer valid_token")
+
+	a.verifyToken(&modelutils.JwtPayloadClaims{UserId: 7, Role: "user", Sid: "sid"})
+	a.sessionUc.On("IsActive", "sid").Return(false, nil).Once()
+
+	handler := a.authMiddleware.RequireToken("user")
+	serve(c, handler)
+
+	a.True(c.IsAborted())
+	a.Equal(http.StatusUnauthorized, w.Code)
+	a.userUc.AssertNotCalled(a.T(), "GetUserByID", 7)
+}
+
+func (a *AuthMiddlewareSuite) TestRequireToken_SessionCheckFailed() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Authorization", "Bearer valid_token")
+
+	a.verifyToken(&modelutils.JwtPayloadClaims{UserId: 7, Role: "user", Sid: "sid"})
+	a.sessionUc.On("IsActive", "sid").Return(false, errors.New("db down")).Once()
+
+	handler := a.authMiddleware.RequireToken("user")
+	serve(c, handler)
+
+	a.True(c.IsAborted())
+	a.Equal(http.StatusInternalServerError, w.Code)
+}
+
+func (a *AuthMiddlewareSuite) TestRequireToken_RevocationCheckFailed() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Authorization", "Bearer valid_token")
+
+	claims := &modelutils.JwtPayloadClaims{UserId: 7, Role: "user"}
+	claims.ID = "jti"
+	a.verifyToken(claims)
+	a.tokenRevocation.On("IsRevoked", "jti").Return(false, errors.New("error")).Once()
+
+	handler := a.authMiddleware.RequireToken("user")
+	serve(c, handler)
+
+	a.True(c.IsAborted())
+	a.Equal(http.StatusInternalServerError, w.Code)
+}
+
+func (a *AuthMiddlewareSuite) TestRequireAuthenticated_AnyRole() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Authorization", "Bearer valid_token")
+
+	a.verifyToken(&modelutils.JwtPayloadClaims{Role: "guest"})
+
+	handler := a.authMiddleware.RequireAuthenticated()
+	serve(c, handler)
+
+	a.False(c.IsAborted())
+}
+
+func (a *AuthMiddlewareSuite) TestRequireAuthenticated_MfaRequired() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Authorization", "Bearer valid_token")
+
+	a.verifyToken(&modelutils.JwtPayloadClaims{Role: "admin"})
+
+	handler := a.authMiddleware.RequireAuthenticated()
+	serve(c, handler)
+
+	a.True(c.IsAborted())
+	a.Equal(http.StatusForbidden, w.Code)
+}
+
+// serve runs handler the way the router does, with ErrorHandler rendering the
+// error it aborted with.
+func serve(c *gin.Context, handler gin.HandlerFunc) {
+	handler(c)
+	ErrorHandler()(c)
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"basic-JWT/model"
+	"basic-JWT/usecase"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const problemContentType = "application/problem+json"
+
+// ErrorHandler renders the last error attached to the context as a problem
+// details response, unless the handler already wrote a response. Handlers
+// attach errors with AbortWithError and AbortWithBindError.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		problem := problemFor(c, c.Errors.Last())
+		problem.Instance = c.Request.URL.Path
+		c.Header("Content-Type", problemContentType)
+		c.JSON(problem.Status, problem)
+	}
+}
+
+// AbortWithError stops the request with err. Domain errors from the usecase
+// layer are shown to the client, any other error is logged by gin and
+// answered with a 500 carrying fallback as detail.
+func AbortWithError(c *gin.Context, err error, fallback string) {
+	_ = c.Error(err).SetMeta(fallback)
+	c.Abort()
+}
+
+// AbortWithBindError stops the request with a 400 for a body or query that
+// failed to bind.
+func AbortWithBindError(c *gin.Context, err error) {
+	_ = c.Error(err).SetType(gin.ErrorTypeBind)
+	c.Abort()
+}
+
+func problemFor(c *gin.Context, ginErr *gin.Error) model.Problem {
+	if ginErr.IsType(gin.ErrorTypeBind) {
+		return newProblem(http.StatusBadRequest, "bad request")
+	}
+
+	var tooMany *usecase.TooManyAttemptsError
+	if errors.As(ginErr.Err, &tooMany) {
+		c.Header("Retry-After", retryAfterSeconds(tooMany.RetryAfter))
+		return newProblem(http.StatusTooManyRequests, tooMany.Error())
+	}
+
+	var domainErr *usecase.DomainError
+	if errors.As(ginErr.Err, &domainErr) {
+		return newProblem(statusForKind(domainErr.Kind), domainErr.Error())
+	}
+
+	// unexpected errors are not shown, they may leak internals
+	detail, _ := ginErr.Meta.(string)
+	if detail == "" {
+		detail = "internal server error"
+	}
+	return newProblem(http.StatusInternalServerError, detail)
+}
+
+func statusForKind(kind usecase.ErrorKind) int {
+	switch kind {
+	case usecase.KindValidation:
+		return http.StatusBadRequest
+	case usecase.KindUnauthorized:
+		return http.StatusUnauthorized
+	case usecase.KindForbidden:
+		return http.StatusForbidden
+	case usecase.KindNotFound:
+		return http.StatusNotFound
+	case usecase.KindConflict:
+		return http.StatusConflict
+	case usecase.KindUnavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+func newProblem(status int, detail string) model.Problem {
+	return model.Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// retryAfterSeconds formats a wait for the Retry-After header, rounding up so
+// clients never retry too early.
+func retryAfterSeconds(wait time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(wait.Seconds())))
+}
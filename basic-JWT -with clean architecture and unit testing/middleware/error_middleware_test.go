@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"basic-JWT/model"
+	"basic-JWT/usecase"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type ErrorMiddlewareSuite struct {
+	suite.Suite
+	router *gin.Engine
+}
+
+func TestErrorMiddlewareSuite(t *testing.T) {
+	suite.Run(t, new(ErrorMiddlewareSuite))
+}
+
+func (e *ErrorMiddlewareSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	e.router = gin.New()
+	e.router.Use(ErrorHandler())
+}
+
+func (e *ErrorMiddlewareSuite) serve(err error, fallback string) (*httptest.ResponseRecorder, model.Problem) {
+	e.router.GET("/resource", func(c *gin.Context) {
+		AbortWithError(c, err, fallback)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/resource", nil)
+	e.router.ServeHTTP(w, req)
+
+	var problem model.Problem
+	e.NoError(json.Unmarshal(w.Body.Bytes(), &problem))
+	return w, problem
+}
+
+func (e *ErrorMiddlewareSuite) TestDomainErrorKinds() {
+	cases := map[usecase.ErrorKind]int{
+		usecase.KindValidation:   http.StatusBadRequest,
+		usecase.KindUnauthorized: http.StatusUnauthorized,
+		usecase.KindForbidden:    http.StatusForbidden,
+		usecase.KindNotFound:     http.StatusNotFound,
+		usecase.KindConflict:     http.StatusConflict,
+		usecase.KindUnavailable:  http.StatusServiceUnavailable,
+	}
+	for kind, status := range cases {
+		e.SetupTest()
+		w, problem := e.serve(usecase.NewDomainError(kind, "something went wrong"), "")
+
+		e.Equal(status, w.Code)
+		e.Equal("application/problem+json", w.Header().Get("Content-Type"))
+		e.Equal(model.Problem{
+			Type:     "about:blank",
+			Title:    http.StatusText(status),
+			Status:   status,
+			Detail:   "something went wrong",
+			Instance: "/resource",
+		}, problem)
+	}
+}
+
+func (e *ErrorMiddlewareSuite) TestWrappedDomainError() {
+	w, problem := e.serve(fmt.Errorf("create user: %w", usecase.ErrInvalidEmail), "failed to create user")
+
+	e.Equal(http.StatusBadRequest, w.Code)
+	e.Equal("invalid email address", problem.Detail)
+}
+
+func (e *ErrorMiddlewareSuite) TestTooManyAttempts() {
+	w, problem := e.serve(&usecase.TooManyAttemptsError{RetryAfter: 1500 * time.Millisecond}, "failed to login user")
+
+	e.Equal(http.StatusTooManyRequests, w.Code)
+	e.Equal("2", w.Header().Get("Retry-After"))
+	e.Equal(http.StatusTooManyRequests, problem.Status)
+}
+
+func (e *ErrorMiddlewareSuite) TestUnexpectedErrorHidesDetails() {
+	w, problem := e.serve(errors.New("pq: connection refused"), "failed to create user")
+
+	e.Equal(http.StatusInternalServerError, w.Code)
+	e.Equal("failed to create user", problem.Detail)
+	e.NotContains(w.Body.String(), "connection refused")
+}
+
+func (e *ErrorMiddlewareSuite) TestUnexpectedErrorWithoutFallback() {
+	_, problem := e.serve(errors.New("boom"), "")
+
+	e.Equal("internal server error", problem.Detail)
+}
+
+func (e *ErrorMiddlewareSuite) TestBindError() {
+	e.router.POST("/resource", func(c *gin.Context) {
+		var request model.CredentialsRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			AbortWithBindError(c, err)
+			return
+		}
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/resource", nil)
+	e.router.ServeHTTP(w, req)
+
+	e.Equal(http.StatusBadRequest, w.Code)
+	e.Contains(w.Body.String(), `"detail":"bad request"`)
+}
+
+func (e *ErrorMiddlewareSuite) TestWrittenResponseIsKept() {
+	e.router.GET("/resource", func(c *gin.Context) {
+		_ = c.Error(errors.New("logged only"))
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/resource", nil)
+	e.router.ServeHTTP(w, req)
+
+	e.Equal(http.StatusOK, w.Code)
+	e.JSONEq(`{"message":"success"}`, w.Body.String())
+}
@@ -0,0 +1,36 @@
+package controller_mock
+
+import (
+	"basic-JWT/model"
+
+	modelutils "basic-JWT/utils/model_utils"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type APIKeyUsecaseMock struct {
+	mock.Mock
+}
+
+func (a *APIKeyUsecaseMock) Create(request model.CreateAPIKeyRequest) (model.APIKeyCredentials, error) {
+	args := a.Called(request)
+	return args.Get(0).(model.APIKeyCredentials), args.Error(1)
+}
+
+func (a *APIKeyUsecaseMock) GetAll() ([]model.APIKey, error) {
+	args := a.Called()
+	return args.Get(0).([]model.APIKey), args.Error(1)
+}
+
+func (a *APIKeyUsecaseMock) Delete(id int) error {
+	args := a.Called(id)
+	return args.Error(0)
+}
+
+func (a *APIKeyUsecaseMock) Authenticate(key string) (*modelutils.JwtPayloadClaims, error) {
+	args := a.Called(key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*modelutils.JwtPayloadClaims), args.Error(1)
+}
@@ -0,0 +1,20 @@
+package controller_mock
+
+import (
+	"basic-JWT/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type AuditUsecaseMock struct {
+	mock.Mock
+}
+
+func (a *AuditUsecaseMock) Record(event model.AuditEvent, err error) {
+	a.Called(event, err)
+}
+
+func (a *AuditUsecaseMock) Search(filter model.AuditEventFilter) (model.AuditEventPage, error) {
+	args := a.Called(filter)
+	return args.Get(0).(model.AuditEventPage), args.Error(1)
+}
@@ -10,12 +10,27 @@ type AuthenticationUsecaseMock struct {
 	mock.Mock
 }
 
-func (a *AuthenticationUsecaseMock) Login(username string, password string) (string, error) {
-	args := a.Called(username, password)
-	return args.String(0), args.Error(1)
+func (a *AuthenticationUsecaseMock) Login(username string, password string, client model.ClientInfo) (model.LoginResult, error) {
+	args := a.Called(username, password, client)
+	return args.Get(0).(model.LoginResult), args.Error(1)
 }
 
-func (a *AuthenticationUsecaseMock) Register(username string, password string) (model.User, error) {
-	args := a.Called(username, password)
+func (a *AuthenticationUsecaseMock) VerifyMfa(mfaToken string, code string, client model.ClientInfo) (model.TokenPair, error) {
+	args := a.Called(mfaToken, code, client)
+	return args.Get(0).(model.TokenPair), args.Error(1)
+}
+
+func (a *AuthenticationUsecaseMock) Refresh(refreshToken string, client model.ClientInfo) (model.TokenPair, error) {
+	args := a.Called(refreshToken, client)
+	return args.Get(0).(model.TokenPair), args.Error(1)
+}
+
+func (a *AuthenticationUsecaseMock) Logout(refreshToken string) error {
+	args := a.Called(refreshToken)
+	return args.Error(0)
+}
+
+func (a *AuthenticationUsecaseMock) Register(username string, password string, email string, client model.ClientInfo) (model.User, error) {
+	args := a.Called(username, password, email, client)
 	return args.Get(0).(model.User), args.Error(1)
 }
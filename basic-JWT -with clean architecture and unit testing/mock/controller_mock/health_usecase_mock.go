@@ -0,0 +1,16 @@
+package controller_mock
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type HealthUsecaseMock struct {
+	mock.Mock
+}
+
+func (h *HealthUsecaseMock) Ready(ctx context.Context) error {
+	args := h.Called(ctx)
+	return args.Error(0)
+}
@@ -0,0 +1,39 @@
+package controller_mock
+
+import (
+	"basic-JWT/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MfaUsecaseMock struct {
+	mock.Mock
+}
+
+func (m *MfaUsecaseMock) Enroll(userID int) (model.MfaEnrollment, error) {
+	args := m.Called(userID)
+	return args.Get(0).(model.MfaEnrollment), args.Error(1)
+}
+
+func (m *MfaUsecaseMock) Confirm(userID int, code string) ([]string, error) {
+	args := m.Called(userID, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MfaUsecaseMock) IsEnabled(userID int) (bool, error) {
+	args := m.Called(userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MfaUsecaseMock) Verify(userID int, code string) error {
+	args := m.Called(userID, code)
+	return args.Error(0)
+}
+
+func (m *MfaUsecaseMock) Reset(username string) error {
+	args := m.Called(username)
+	return args.Error(0)
+}
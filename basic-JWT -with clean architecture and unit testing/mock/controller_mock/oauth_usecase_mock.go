@@ -0,0 +1,46 @@
+package controller_mock
+
+import (
+	"basic-JWT/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type OAuthUsecaseMock struct {
+	mock.Mock
+}
+
+func (o *OAuthUsecaseMock) RegisterClient(request model.CreateOAuthClientRequest) (model.OAuthClientCredentials, error) {
+	args := o.Called(request)
+	return args.Get(0).(model.OAuthClientCredentials), args.Error(1)
+}
+
+func (o *OAuthUsecaseMock) GetAllClients() ([]model.OAuthClient, error) {
+	args := o.Called()
+	return args.Get(0).([]model.OAuthClient), args.Error(1)
+}
+
+func (o *OAuthUsecaseMock) DeleteClient(clientID string) error {
+	args := o.Called(clientID)
+	return args.Error(0)
+}
+
+func (o *OAuthUsecaseMock) Authorize(userID int, mfa bool, request model.AuthorizeRequest) (model.AuthorizeResponse, error) {
+	args := o.Called(userID, mfa, request)
+	return args.Get(0).(model.AuthorizeResponse), args.Error(1)
+}
+
+func (o *OAuthUsecaseMock) Token(request model.TokenRequest) (model.OAuthTokenResponse, error) {
+	args := o.Called(request)
+	return args.Get(0).(model.OAuthTokenResponse), args.Error(1)
+}
+
+func (o *OAuthUsecaseMock) UserInfo(accessToken string) (model.UserInfo, error) {
+	args := o.Called(accessToken)
+	return args.Get(0).(model.UserInfo), args.Error(1)
+}
+
+func (o *OAuthUsecaseMock) Introspect(request model.IntrospectionRequest) (model.IntrospectionResponse, error) {
+	args := o.Called(request)
+	return args.Get(0).(model.IntrospectionResponse), args.Error(1)
+}
@@ -23,4 +23,54 @@ func (u *UserUsecaseMock) GetAllUsers() ([]model.User, error) {
 func (u *UserUsecaseMock) GetUserByUsername(username string) (model.User, error) {
 	args := u.Called(username)
 	return args.Get(0).(model.User), args.Error(1)
-}
\ No newline at end of file
+}
+
+func (u *UserUsecaseMock) GetUserByID(id int) (model.User, error) {
+	args := u.Called(id)
+	return args.Get(0).(model.User), args.Error(1)
+}
+
+func (u *UserUsecaseMock) UpdateUser(username string, request model.UpdateUserRequest, actor model.Actor) (model.User, error) {
+	args := u.Called(username, request, actor)
+	return args.Get(0).(model.User), args.Error(1)
+}
+
+func (u *UserUsecaseMock) SetDisabled(username string, disabled bool, actor model.Actor) error {
+	args := u.Called(username, disabled, actor)
+	return args.Error(0)
+}
+
+func (u *UserUsecaseMock) DeleteUser(username string, actor model.Actor) error {
+	args := u.Called(username, actor)
+	return args.Error(0)
+}
+
+func (u *UserUsecaseMock) ResetPassword(username string, actor model.Actor) (string, error) {
+	args := u.Called(username, actor)
+	return args.String(0), args.Error(1)
+}
+
+func (u *UserUsecaseMock) CreateUser(request model.CreateUserRequest, actor model.Actor) (model.User, error) {
+	args := u.Called(request, actor)
+	return args.Get(0).(model.User), args.Error(1)
+}
+
+func (u *UserUsecaseMock) UpdatePasswordHash(id int, hash string) error {
+	args := u.Called(id, hash)
+	return args.Error(0)
+}
+
+func (u *UserUsecaseMock) GetUserByEmail(email string) (model.User, error) {
+	args := u.Called(email)
+	return args.Get(0).(model.User), args.Error(1)
+}
+
+func (u *UserUsecaseMock) ChangePassword(id int, password string) error {
+	args := u.Called(id, password)
+	return args.Error(0)
+}
+
+func (u *UserUsecaseMock) SetEmailVerified(id int) error {
+	args := u.Called(id)
+	return args.Error(0)
+}
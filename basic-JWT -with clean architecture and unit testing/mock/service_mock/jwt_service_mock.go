@@ -15,7 +15,27 @@ func (j *JWTServiceMock) CreateToken(user model.User) string{
 	args := j.Called(user)
 	return args.String(0)
 }
+func (j *JWTServiceMock) CreateTokenWithClaims(claims modelutils.JwtPayloadClaims) string {
+	args := j.Called(claims)
+	return args.String(0)
+}
+
 func  (j *JWTServiceMock) VerifyToken(tokenString string) (*modelutils.JwtPayloadClaims, error){
 	args := j.Called(tokenString)
 	return args.Get(0).(*modelutils.JwtPayloadClaims), args.Error(1)
-}
\ No newline at end of file
+}
+
+func (j *JWTServiceMock) JWKS() modelutils.JSONWebKeySet {
+	args := j.Called()
+	return args.Get(0).(modelutils.JSONWebKeySet)
+}
+
+func (j *JWTServiceMock) VerifyTokenWithPurpose(tokenString string, purpose string) (*modelutils.JwtPayloadClaims, error) {
+	args := j.Called(tokenString, purpose)
+	return args.Get(0).(*modelutils.JwtPayloadClaims), args.Error(1)
+}
+
+func (j *JWTServiceMock) ParseToken(tokenString string) (*modelutils.JwtPayloadClaims, error) {
+	args := j.Called(tokenString)
+	return args.Get(0).(*modelutils.JwtPayloadClaims), args.Error(1)
+}
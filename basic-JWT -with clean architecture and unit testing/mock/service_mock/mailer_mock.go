@@ -0,0 +1,16 @@
+package service_mock
+
+import (
+	"basic-JWT/utils/service"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MailerMock struct {
+	mock.Mock
+}
+
+func (m *MailerMock) Send(message service.MailMessage) error {
+	args := m.Called(message)
+	return args.Error(0)
+}
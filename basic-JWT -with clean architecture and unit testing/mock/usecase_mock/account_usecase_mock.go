@@ -0,0 +1,31 @@
+package usecase_mock
+
+import (
+	"basic-JWT/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type AccountUsecaseMock struct {
+	mock.Mock
+}
+
+func (a *AccountUsecaseMock) SendVerificationEmail(user model.User) error {
+	args := a.Called(user)
+	return args.Error(0)
+}
+
+func (a *AccountUsecaseMock) VerifyEmail(token string) error {
+	args := a.Called(token)
+	return args.Error(0)
+}
+
+func (a *AccountUsecaseMock) ForgotPassword(email string) error {
+	args := a.Called(email)
+	return args.Error(0)
+}
+
+func (a *AccountUsecaseMock) ResetPassword(token string, password string) error {
+	args := a.Called(token, password)
+	return args.Error(0)
+}
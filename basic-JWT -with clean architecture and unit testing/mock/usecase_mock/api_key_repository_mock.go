@@ -0,0 +1,40 @@
+package usecase_mock
+
+import (
+	"basic-JWT/model"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type APIKeyRepositoryMock struct {
+	mock.Mock
+}
+
+func (a *APIKeyRepositoryMock) Create(key *model.APIKey) (*model.APIKey, error) {
+	args := a.Called(key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.APIKey), args.Error(1)
+}
+
+func (a *APIKeyRepositoryMock) GetByPrefix(prefix string) (model.APIKey, error) {
+	args := a.Called(prefix)
+	return args.Get(0).(model.APIKey), args.Error(1)
+}
+
+func (a *APIKeyRepositoryMock) GetAll() ([]model.APIKey, error) {
+	args := a.Called()
+	return args.Get(0).([]model.APIKey), args.Error(1)
+}
+
+func (a *APIKeyRepositoryMock) Delete(id int) error {
+	args := a.Called(id)
+	return args.Error(0)
+}
+
+func (a *APIKeyRepositoryMock) UpdateLastUsed(id int, usedAt time.Time) error {
+	args := a.Called(id, usedAt)
+	return args.Error(0)
+}
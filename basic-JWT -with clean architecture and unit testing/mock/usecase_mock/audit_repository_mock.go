@@ -0,0 +1,21 @@
+package usecase_mock
+
+import (
+	"basic-JWT/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type AuditRepositoryMock struct {
+	mock.Mock
+}
+
+func (a *AuditRepositoryMock) Append(event *model.AuditEvent) error {
+	args := a.Called(event)
+	return args.Error(0)
+}
+
+func (a *AuditRepositoryMock) Find(filter model.AuditEventFilter) ([]model.AuditEvent, int, error) {
+	args := a.Called(filter)
+	return args.Get(0).([]model.AuditEvent), args.Int(1), args.Error(2)
+}
@@ -0,0 +1,16 @@
+package usecase_mock
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type HealthRepositoryMock struct {
+	mock.Mock
+}
+
+func (h *HealthRepositoryMock) Ping(ctx context.Context) error {
+	args := h.Called(ctx)
+	return args.Error(0)
+}
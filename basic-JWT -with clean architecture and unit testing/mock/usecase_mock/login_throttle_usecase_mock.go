@@ -0,0 +1,29 @@
+package usecase_mock
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+type LoginThrottleUsecaseMock struct {
+	mock.Mock
+}
+
+func (l *LoginThrottleUsecaseMock) Check(username string, ip string) error {
+	args := l.Called(username, ip)
+	return args.Error(0)
+}
+
+func (l *LoginThrottleUsecaseMock) RegisterFailure(username string, ip string) error {
+	args := l.Called(username, ip)
+	return args.Error(0)
+}
+
+func (l *LoginThrottleUsecaseMock) RegisterSuccess(username string) error {
+	args := l.Called(username)
+	return args.Error(0)
+}
+
+func (l *LoginThrottleUsecaseMock) Unlock(username string) error {
+	args := l.Called(username)
+	return args.Error(0)
+}
@@ -0,0 +1,41 @@
+package usecase_mock
+
+import (
+	"basic-JWT/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MfaRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *MfaRepositoryMock) Save(mfa *model.UserMfa) error {
+	args := m.Called(mfa)
+	return args.Error(0)
+}
+
+func (m *MfaRepositoryMock) GetByUserID(userID int) (model.UserMfa, error) {
+	args := m.Called(userID)
+	return args.Get(0).(model.UserMfa), args.Error(1)
+}
+
+func (m *MfaRepositoryMock) Enable(userID int, step int64, recoveryCodeHashes []string) error {
+	args := m.Called(userID, step, recoveryCodeHashes)
+	return args.Error(0)
+}
+
+func (m *MfaRepositoryMock) UpdateLastUsedStep(userID int, step int64) (bool, error) {
+	args := m.Called(userID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MfaRepositoryMock) UseRecoveryCode(userID int, codeHash string) (bool, error) {
+	args := m.Called(userID, codeHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MfaRepositoryMock) Delete(userID int) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
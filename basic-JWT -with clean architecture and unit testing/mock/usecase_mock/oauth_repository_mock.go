@@ -0,0 +1,44 @@
+package usecase_mock
+
+import (
+	"basic-JWT/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type OAuthRepositoryMock struct {
+	mock.Mock
+}
+
+func (o *OAuthRepositoryMock) CreateClient(client *model.OAuthClient) (*model.OAuthClient, error) {
+	args := o.Called(client)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.OAuthClient), args.Error(1)
+}
+
+func (o *OAuthRepositoryMock) GetClientByClientID(clientID string) (model.OAuthClient, error) {
+	args := o.Called(clientID)
+	return args.Get(0).(model.OAuthClient), args.Error(1)
+}
+
+func (o *OAuthRepositoryMock) GetAllClients() ([]model.OAuthClient, error) {
+	args := o.Called()
+	return args.Get(0).([]model.OAuthClient), args.Error(1)
+}
+
+func (o *OAuthRepositoryMock) DeleteClient(clientID string) error {
+	args := o.Called(clientID)
+	return args.Error(0)
+}
+
+func (o *OAuthRepositoryMock) CreateAuthorizationCode(code *model.AuthorizationCode) error {
+	args := o.Called(code)
+	return args.Error(0)
+}
+
+func (o *OAuthRepositoryMock) ConsumeAuthorizationCode(codeHash string) (model.AuthorizationCode, error) {
+	args := o.Called(codeHash)
+	return args.Get(0).(model.AuthorizationCode), args.Error(1)
+}
@@ -0,0 +1,21 @@
+package usecase_mock
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type OneTimeTokenRepositoryMock struct {
+	mock.Mock
+}
+
+func (o *OneTimeTokenRepositoryMock) MarkUsed(jti string, purpose string, expiresAt time.Time) (bool, error) {
+	args := o.Called(jti, purpose, expiresAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (o *OneTimeTokenRepositoryMock) DeleteExpired() error {
+	args := o.Called()
+	return args.Error(0)
+}
@@ -0,0 +1,77 @@
+package usecase_mock
+
+import (
+	"basic-JWT/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type RbacRepositoryMock struct {
+	mock.Mock
+}
+
+func (r *RbacRepositoryMock) CreateRole(role *model.Role) (*model.Role, error) {
+	args := r.Called(role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Role), args.Error(1)
+}
+
+func (r *RbacRepositoryMock) GetAllRoles() ([]model.Role, error) {
+	args := r.Called()
+	return args.Get(0).([]model.Role), args.Error(1)
+}
+
+func (r *RbacRepositoryMock) DeleteRole(id int) error {
+	args := r.Called(id)
+	return args.Error(0)
+}
+
+func (r *RbacRepositoryMock) CreatePermission(permission *model.Permission) (*model.Permission, error) {
+	args := r.Called(permission)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Permission), args.Error(1)
+}
+
+func (r *RbacRepositoryMock) GetAllPermissions() ([]model.Permission, error) {
+	args := r.Called()
+	return args.Get(0).([]model.Permission), args.Error(1)
+}
+
+func (r *RbacRepositoryMock) DeletePermission(id int) error {
+	args := r.Called(id)
+	return args.Error(0)
+}
+
+func (r *RbacRepositoryMock) GrantPermission(roleID int, permissionID int) error {
+	args := r.Called(roleID, permissionID)
+	return args.Error(0)
+}
+
+func (r *RbacRepositoryMock) RevokePermission(roleID int, permissionID int) error {
+	args := r.Called(roleID, permissionID)
+	return args.Error(0)
+}
+
+func (r *RbacRepositoryMock) GetPermissionsByRole(roleName string) ([]string, error) {
+	args := r.Called(roleName)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (r *RbacRepositoryMock) GetPermissionByID(id int) (model.Permission, error) {
+	args := r.Called(id)
+	return args.Get(0).(model.Permission), args.Error(1)
+}
+
+func (r *RbacRepositoryMock) GetRoleIDsWithPermission(permission string) ([]int, error) {
+	args := r.Called(permission)
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (r *RbacRepositoryMock) CountUsersWithRole(roleID int) (int, error) {
+	args := r.Called(roleID)
+	return args.Int(0), args.Error(1)
+}
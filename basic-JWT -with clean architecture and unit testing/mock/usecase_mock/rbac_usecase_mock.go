@@ -0,0 +1,62 @@
+package usecase_mock
+
+import (
+	"basic-JWT/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type RbacUsecaseMock struct {
+	mock.Mock
+}
+
+func (r *RbacUsecaseMock) CreateRole(role *model.Role) (*model.Role, error) {
+	args := r.Called(role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Role), args.Error(1)
+}
+
+func (r *RbacUsecaseMock) GetAllRoles() ([]model.Role, error) {
+	args := r.Called()
+	return args.Get(0).([]model.Role), args.Error(1)
+}
+
+func (r *RbacUsecaseMock) DeleteRole(id int) error {
+	args := r.Called(id)
+	return args.Error(0)
+}
+
+func (r *RbacUsecaseMock) CreatePermission(permission *model.Permission) (*model.Permission, error) {
+	args := r.Called(permission)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Permission), args.Error(1)
+}
+
+func (r *RbacUsecaseMock) GetAllPermissions() ([]model.Permission, error) {
+	args := r.Called()
+	return args.Get(0).([]model.Permission), args.Error(1)
+}
+
+func (r *RbacUsecaseMock) DeletePermission(id int) error {
+	args := r.Called(id)
+	return args.Error(0)
+}
+
+func (r *RbacUsecaseMock) GrantPermission(roleID int, permissionID int) error {
+	args := r.Called(roleID, permissionID)
+	return args.Error(0)
+}
+
+func (r *RbacUsecaseMock) RevokePermission(roleID int, permissionID int) error {
+	args := r.Called(roleID, permissionID)
+	return args.Error(0)
+}
+
+func (r *RbacUsecaseMock) HasPermission(role string, permission string) (bool, error) {
+	args := r.Called(role, permission)
+	return args.Bool(0), args.Error(1)
+}
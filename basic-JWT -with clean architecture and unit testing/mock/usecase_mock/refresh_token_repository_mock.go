@@ -0,0 +1,39 @@
+package usecase_mock
+
+import (
+	"basic-JWT/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type RefreshTokenRepositoryMock struct {
+	mock.Mock
+}
+
+func (r *RefreshTokenRepositoryMock) Create(token *model.RefreshToken) (*model.RefreshToken, error) {
+	args := r.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.RefreshToken), args.Error(1)
+}
+
+func (r *RefreshTokenRepositoryMock) GetByHash(tokenHash string) (model.RefreshToken, error) {
+	args := r.Called(tokenHash)
+	return args.Get(0).(model.RefreshToken), args.Error(1)
+}
+
+func (r *RefreshTokenRepositoryMock) Rotate(usedID int, next *model.RefreshToken) (bool, error) {
+	args := r.Called(usedID, next)
+	return args.Bool(0), args.Error(1)
+}
+
+func (r *RefreshTokenRepositoryMock) RevokeFamily(familyID string) error {
+	args := r.Called(familyID)
+	return args.Error(0)
+}
+
+func (r *RefreshTokenRepositoryMock) RevokeAllForUser(userID int) error {
+	args := r.Called(userID)
+	return args.Error(0)
+}
@@ -0,0 +1,41 @@
+package usecase_mock
+
+import (
+	"basic-JWT/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type RefreshTokenUsecaseMock struct {
+	mock.Mock
+}
+
+func (r *RefreshTokenUsecaseMock) Issue(userID int, familyID string, mfa bool) (string, error) {
+	args := r.Called(userID, familyID, mfa)
+	return args.String(0), args.Error(1)
+}
+
+func (r *RefreshTokenUsecaseMock) IssueForClient(userID int, clientID string, scope string, mfa bool) (string, error) {
+	args := r.Called(userID, clientID, scope, mfa)
+	return args.String(0), args.Error(1)
+}
+
+func (r *RefreshTokenUsecaseMock) Rotate(refreshToken string, clientID string) (model.RefreshToken, string, error) {
+	args := r.Called(refreshToken, clientID)
+	return args.Get(0).(model.RefreshToken), args.String(1), args.Error(2)
+}
+
+func (r *RefreshTokenUsecaseMock) Revoke(refreshToken string) error {
+	args := r.Called(refreshToken)
+	return args.Error(0)
+}
+
+func (r *RefreshTokenUsecaseMock) RevokeFamily(familyID string) error {
+	args := r.Called(familyID)
+	return args.Error(0)
+}
+
+func (r *RefreshTokenUsecaseMock) RevokeAllForUser(userID int) error {
+	args := r.Called(userID)
+	return args.Error(0)
+}
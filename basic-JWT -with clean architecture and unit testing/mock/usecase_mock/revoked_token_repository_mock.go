@@ -0,0 +1,26 @@
+package usecase_mock
+
+import (
+	"basic-JWT/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type RevokedTokenRepositoryMock struct {
+	mock.Mock
+}
+
+func (r *RevokedTokenRepositoryMock) Create(token *model.RevokedToken) error {
+	args := r.Called(token)
+	return args.Error(0)
+}
+
+func (r *RevokedTokenRepositoryMock) Exists(jti string) (bool, error) {
+	args := r.Called(jti)
+	return args.Bool(0), args.Error(1)
+}
+
+func (r *RevokedTokenRepositoryMock) DeleteExpired() error {
+	args := r.Called()
+	return args.Error(0)
+}
@@ -0,0 +1,35 @@
+package usecase_mock
+
+import (
+	"basic-JWT/model"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type SessionRepositoryMock struct {
+	mock.Mock
+}
+
+func (s *SessionRepositoryMock) Save(session *model.Session) (*model.Session, error) {
+	args := s.Called(session)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Session), args.Error(1)
+}
+
+func (s *SessionRepositoryMock) GetByID(id string) (model.Session, error) {
+	args := s.Called(id)
+	return args.Get(0).(model.Session), args.Error(1)
+}
+
+func (s *SessionRepositoryMock) GetActiveByUser(userID int) ([]model.Session, error) {
+	args := s.Called(userID)
+	return args.Get(0).([]model.Session), args.Error(1)
+}
+
+func (s *SessionRepositoryMock) Touch(id string, seenAt time.Time) (bool, error) {
+	args := s.Called(id, seenAt)
+	return args.Bool(0), args.Error(1)
+}
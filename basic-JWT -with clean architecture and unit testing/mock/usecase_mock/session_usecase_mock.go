@@ -0,0 +1,41 @@
+package usecase_mock
+
+import (
+	"basic-JWT/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type SessionUsecaseMock struct {
+	mock.Mock
+}
+
+func (s *SessionUsecaseMock) Record(sessionID string, userID int, client model.ClientInfo) (string, error) {
+	args := s.Called(sessionID, userID, client)
+	return args.String(0), args.Error(1)
+}
+
+func (s *SessionUsecaseMock) IsActive(sessionID string) (bool, error) {
+	args := s.Called(sessionID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (s *SessionUsecaseMock) GetAll(userID int, currentSessionID string) ([]model.Session, error) {
+	args := s.Called(userID, currentSessionID)
+	return args.Get(0).([]model.Session), args.Error(1)
+}
+
+func (s *SessionUsecaseMock) Revoke(userID int, sessionID string) error {
+	args := s.Called(userID, sessionID)
+	return args.Error(0)
+}
+
+func (s *SessionUsecaseMock) RevokeAll(userID int) error {
+	args := s.Called(userID)
+	return args.Error(0)
+}
+
+func (s *SessionUsecaseMock) ForceLogout(username string) error {
+	args := s.Called(username)
+	return args.Error(0)
+}
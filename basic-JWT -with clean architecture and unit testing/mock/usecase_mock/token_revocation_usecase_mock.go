@@ -0,0 +1,19 @@
+package usecase_mock
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+type TokenRevocationUsecaseMock struct {
+	mock.Mock
+}
+
+func (t *TokenRevocationUsecaseMock) Revoke(token string) error {
+	args := t.Called(token)
+	return args.Error(0)
+}
+
+func (t *TokenRevocationUsecaseMock) IsRevoked(jti string) (bool, error) {
+	args := t.Called(jti)
+	return args.Bool(0), args.Error(1)
+}
@@ -0,0 +1,64 @@
+package usecase_mock
+
+import (
+	"basic-JWT/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type UserRepositoryMock struct {
+	mock.Mock
+}
+
+func (u *UserRepositoryMock) Create(user *model.User) (*model.User, error) {
+	args := u.Called(user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (u *UserRepositoryMock) GetAllUsers() ([]model.User, error) {
+	args := u.Called()
+	return args.Get(0).([]model.User), args.Error(1)
+}
+
+func (u *UserRepositoryMock) GetUserByUsername(username string) (model.User, error) {
+	args := u.Called(username)
+	return args.Get(0).(model.User), args.Error(1)
+}
+
+func (u *UserRepositoryMock) GetUserByID(id int) (model.User, error) {
+	args := u.Called(id)
+	return args.Get(0).(model.User), args.Error(1)
+}
+
+func (u *UserRepositoryMock) Update(user *model.User) error {
+	args := u.Called(user)
+	return args.Error(0)
+}
+
+func (u *UserRepositoryMock) SetDisabled(id int, disabled bool) error {
+	args := u.Called(id, disabled)
+	return args.Error(0)
+}
+
+func (u *UserRepositoryMock) SoftDelete(id int) error {
+	args := u.Called(id)
+	return args.Error(0)
+}
+
+func (u *UserRepositoryMock) UpdatePassword(id int, passwordHash string) error {
+	args := u.Called(id, passwordHash)
+	return args.Error(0)
+}
+
+func (u *UserRepositoryMock) GetUserByEmail(email string) (model.User, error) {
+	args := u.Called(email)
+	return args.Get(0).(model.User), args.Error(1)
+}
+
+func (u *UserRepositoryMock) SetEmailVerified(id int) error {
+	args := u.Called(id)
+	return args.Error(0)
+}
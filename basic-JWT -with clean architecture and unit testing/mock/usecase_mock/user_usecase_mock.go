@@ -30,3 +30,53 @@ func (u *UserUseCaseMock) GetUserByUsername(username string) (model.User, error)
 	return args.Get(0).(model.User), args.Error(1)
 
 }
+
+func (u *UserUseCaseMock) GetUserByID(id int) (model.User, error) {
+	args := u.Called(id)
+	return args.Get(0).(model.User), args.Error(1)
+}
+
+func (u *UserUseCaseMock) UpdateUser(username string, request model.UpdateUserRequest, actor model.Actor) (model.User, error) {
+	args := u.Called(username, request, actor)
+	return args.Get(0).(model.User), args.Error(1)
+}
+
+func (u *UserUseCaseMock) SetDisabled(username string, disabled bool, actor model.Actor) error {
+	args := u.Called(username, disabled, actor)
+	return args.Error(0)
+}
+
+func (u *UserUseCaseMock) DeleteUser(username string, actor model.Actor) error {
+	args := u.Called(username, actor)
+	return args.Error(0)
+}
+
+func (u *UserUseCaseMock) ResetPassword(username string, actor model.Actor) (string, error) {
+	args := u.Called(username, actor)
+	return args.String(0), args.Error(1)
+}
+
+func (u *UserUseCaseMock) CreateUser(request model.CreateUserRequest, actor model.Actor) (model.User, error) {
+	args := u.Called(request, actor)
+	return args.Get(0).(model.User), args.Error(1)
+}
+
+func (u *UserUseCaseMock) UpdatePasswordHash(id int, hash string) error {
+	args := u.Called(id, hash)
+	return args.Error(0)
+}
+
+func (u *UserUseCaseMock) GetUserByEmail(email string) (model.User, error) {
+	args := u.Called(email)
+	return args.Get(0).(model.User), args.Error(1)
+}
+
+func (u *UserUseCaseMock) ChangePassword(id int, password string) error {
+	args := u.Called(id, password)
+	return args.Error(0)
+}
+
+func (u *UserUseCaseMock) SetEmailVerified(id int) error {
+	args := u.Called(id)
+	return args.Error(0)
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// APIKey is a long-lived credential for batch jobs and other services. It
+// acts as its owner, limited to the permissions listed in Scopes. Only the
+// hash of the secret part is stored.
+type APIKey struct {
+	ID         int        `json:"id"`
+	Prefix     string     `json:"prefix"`
+	SecretHash string     `json:"-"`
+	UserID     int        `json:"userId"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// CreateAPIKeyRequest creates a key owned by Username. A nil ExpiresAt makes
+// a key that is valid until it is deleted.
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required"`
+	Username  string     `json:"username" binding:"required"`
+	Scopes    []string   `json:"scopes" binding:"required"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// APIKeyCredentials is returned once when a key is created.
+type APIKeyCredentials struct {
+	APIKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// Audited actions. Logins waiting for the TOTP step are recorded once the
+// step is done, as auth.login.mfa.
+const (
+	AuditActionRegister          = "auth.register"
+	AuditActionLogin             = "auth.login"
+	AuditActionLoginMfa          = "auth.login.mfa"
+	AuditActionUserCreate        = "user.create"
+	AuditActionUserUpdate        = "user.update"
+	AuditActionUserDisable       = "user.disable"
+	AuditActionUserEnable        = "user.enable"
+	AuditActionUserDelete        = "user.delete"
+	AuditActionUserResetPassword = "user.reset-password"
+)
+
+// AuditEvent records who did what to whom, from where and whether it worked.
+// ActorID is 0 when the actor is not a known user, such as a login with an
+// unknown username. Reason explains a failure.
+type AuditEvent struct {
+	ID        int64     `json:"id,omitempty"`
+	ActorID   int       `json:"actorId"`
+	Actor     string    `json:"actor"`
+	Action    string    `json:"action"`
+	Target    string    `json:"target"`
+	IP        string    `json:"ip"`
+	Outcome   string    `json:"outcome"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Actor is the signed in user performing an admin action.
+type Actor struct {
+	UserID int
+	IP     string
+}
+
+// AuditEventFilter selects audit events. Empty fields match every event,
+// From and To are RFC 3339 timestamps bounding CreatedAt.
+type AuditEventFilter struct {
+	Actor   string    `form:"actor"`
+	Action  string    `form:"action"`
+	Target  string    `form:"target"`
+	Outcome string    `form:"outcome"`
+	From    time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To      time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Page    int       `form:"page"`
+	Limit   int       `form:"limit"`
+}
+
+// AuditEventPage is one page of audit events, newest first. Total counts
+// every event matching the filter.
+type AuditEventPage struct {
+	Events []AuditEvent `json:"events"`
+	Page   int          `json:"page"`
+	Limit  int          `json:"limit"`
+	Total  int          `json:"total"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// LoginAttempt counts recent failed logins for one throttle key, either a
+// username or a client IP.
+type LoginAttempt struct {
+	Key           string    `json:"key"`
+	Failures      int       `json:"failures"`
+	LastFailureAt time.Time `json:"lastFailureAt"`
+	LockedUntil   time.Time `json:"lockedUntil"`
+}
+
+// ClientInfo describes where a login request came from.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
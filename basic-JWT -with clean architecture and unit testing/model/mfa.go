@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+type UserMfa struct {
+	UserID       int        `json:"userId"`
+	Secret       string     `json:"-"`
+	Enabled      bool       `json:"enabled"`
+	LastUsedStep int64      `json:"-"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	EnabledAt    *time.Time `json:"enabledAt"`
+}
+
+type MfaEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type MfaCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type MfaVerifyRequest struct {
+	MfaToken string `json:"mfaToken" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// LoginResult carries either the token pair or, for accounts with MFA
+// enabled, a short-lived mfa token to be exchanged at /login/verify.
+type LoginResult struct {
+	TokenPair
+	MfaRequired bool   `json:"mfaRequired,omitempty"`
+	MfaToken    string `json:"mfaToken,omitempty"`
+}
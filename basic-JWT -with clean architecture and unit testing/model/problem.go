@@ -0,0 +1,11 @@
+package model
+
+// Problem is an RFC 7807 problem details response, sent with the
+// application/problem+json content type for every API error.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+type RefreshToken struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"userId"`
+	FamilyID  string    `json:"familyId"`
+	TokenHash string    `json:"-"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Used      bool      `json:"used"`
+	Revoked   bool      `json:"revoked"`
+	Mfa       bool      `json:"mfa"`
+	// ClientID and Scope are set for tokens issued to OAuth clients
+	ClientID  string    `json:"clientId"`
+	Scope     string    `json:"scope"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"token,omitempty"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// RevokedToken is a denylist entry for a JWT, keyed by its jti. The entry is
+// only needed until the token would have expired anyway.
+type RevokedToken struct {
+	JTI       string    `json:"jti"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	RevokedAt time.Time `json:"revokedAt"`
+}
+
+type RevokeTokenRequest struct {
+	Token string `json:"token" binding:"required"`
+}
@@ -0,0 +1,18 @@
+package model
+
+type Role struct {
+	ID          int      `json:"id"`
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type Permission struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+type GrantPermissionRequest struct {
+	PermissionID int `json:"permissionId" binding:"required"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// Session is one login on one device. Its ID is the family id of the refresh
+// tokens issued for the login and the sid claim of its access tokens, so the
+// session ends whenever the token family is revoked.
+type Session struct {
+	ID         string    `json:"id"`
+	UserID     int       `json:"userId"`
+	UserAgent  string    `json:"userAgent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	// Current marks the session of the token that made the request
+	Current bool `json:"current"`
+}
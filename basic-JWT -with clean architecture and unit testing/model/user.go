@@ -1,8 +1,41 @@
 package model
 
+// Password holds the password hash and is never serialized. Requests that carry
+// a password bind into one of the request types below instead.
+//
+// Email is empty for accounts created before email addresses were collected.
 type User struct {
-	ID       int    `json:"id"`
+	ID            int    `json:"id"`
+	Username      string `json:"username"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"emailVerified"`
+	Password      string `json:"-"`
+	Role          string `json:"role"`
+	Disabled      bool   `json:"disabled"`
+}
+
+type CredentialsRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type RegisterRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Email    string `json:"email"`
+}
+
+// CreateUserRequest is used by admins. Email is optional and is trusted
+// without a verification mail.
+type CreateUserRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+}
+
+// UpdateUserRequest changes the username and role. Empty fields keep their current value.
+type UpdateUserRequest struct {
+	Username string `json:"username"`
 	Role     string `json:"role"`
-}
\ No newline at end of file
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"basic-JWT/model"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+type APIKeyRepository interface {
+	Create(key *model.APIKey) (*model.APIKey, error)
+	GetByPrefix(prefix string) (model.APIKey, error)
+	GetAll() ([]model.APIKey, error)
+	Delete(id int) error
+	UpdateLastUsed(id int, usedAt time.Time) error
+}
+
+type apiKeyRepository struct {
+	db *sql.DB
+}
+
+// scopes are permission names, which contain no spaces, so they are stored
+// space separated like the scopes of OAuth clients
+func (r *apiKeyRepository) Create(key *model.APIKey) (*model.APIKey, error) {
+	err := r.db.QueryRow("INSERT INTO api_keys (prefix, secret_hash, user_id, name, scopes, expires_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at",
+		key.Prefix, key.SecretHash, key.UserID, key.Name, strings.Join(key.Scopes, " "), key.ExpiresAt).
+		Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (r *apiKeyRepository) GetByPrefix(prefix string) (model.APIKey, error) {
+	row := r.db.QueryRow("SELECT id, prefix, secret_hash, user_id, name, scopes, expires_at, last_used_at, created_at FROM api_keys WHERE prefix = $1", prefix)
+	return scanAPIKey(row)
+}
+
+func (r *apiKeyRepository) GetAll() ([]model.APIKey, error) {
+	rows, err := r.db.Query("SELECT id, prefix, secret_hash, user_id, name, scopes, expires_at, last_used_at, created_at FROM api_keys ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []model.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func (r *apiKeyRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM api_keys WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) UpdateLastUsed(id int, usedAt time.Time) error {
+	_, err := r.db.Exec("UPDATE api_keys SET last_used_at = $1 WHERE id = $2", usedAt, id)
+	return err
+}
+
+func scanAPIKey(row rowScanner) (model.APIKey, error) {
+	var key model.APIKey
+	var scopes string
+	var expiresAt, lastUsedAt sql.NullTime
+	if err := row.Scan(&key.ID, &key.Prefix, &key.SecretHash, &key.UserID, &key.Name, &scopes, &expiresAt, &lastUsedAt, &key.CreatedAt); err != nil {
+		return model.APIKey{}, err
+	}
+	key.Scopes = strings.Fields(scopes)
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	return key, nil
+}
+
+func NewAPIKeyRepository(db *sql.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
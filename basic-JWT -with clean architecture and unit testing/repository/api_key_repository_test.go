@@ -0,0 +1,111 @@
+package repository_test
+
+import (
+	"basic-JWT/model"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	. "basic-JWT/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+type apiKeyRepositorySuite struct {
+	suite.Suite
+	r       APIKeyRepository
+	mockDB  *sql.DB
+	mockSQL sqlmock.Sqlmock
+}
+
+func TestAPIKeyRepositorySuite(t *testing.T) {
+	suite.Run(t, new(apiKeyRepositorySuite))
+}
+
+func (a *apiKeyRepositorySuite) SetupTest() {
+	mockDB, mockSQL, err := sqlmock.New()
+	if err != nil {
+		a.T().Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	a.mockDB = mockDB
+	a.mockSQL = mockSQL
+	a.r = NewAPIKeyRepository(mockDB)
+}
+
+var apiKeyColumns = []string{"id", "prefix", "secret_hash", "user_id", "name", "scopes", "expires_at", "last_used_at", "created_at"}
+
+func (a *apiKeyRepositorySuite) TestCreate_Success() {
+	expiresAt := time.Now().Add(24 * time.Hour)
+	key := model.APIKey{
+		Prefix:     "prefix",
+		SecretHash: "hash",
+		UserID:     2,
+		Name:       "nightly export",
+		Scopes:     []string{"users:read", "users:disable"},
+		ExpiresAt:  &expiresAt,
+	}
+
+	a.mockSQL.ExpectQuery(regexp.QuoteMeta("INSERT INTO api_keys (prefix, secret_hash, user_id, name, scopes, expires_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at")).
+		WithArgs("prefix", "hash", 2, "nightly export", "users:read users:disable", &expiresAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+
+	result, err := a.r.Create(&key)
+	a.NoError(err)
+	a.Equal(1, result.ID)
+}
+
+func (a *apiKeyRepositorySuite) TestGetByPrefix_Success() {
+	a.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT id, prefix, secret_hash, user_id, name, scopes, expires_at, last_used_at, created_at FROM api_keys WHERE prefix = $1")).
+		WithArgs("prefix").
+		WillReturnRows(sqlmock.NewRows(apiKeyColumns).AddRow(1, "prefix", "hash", 2, "nightly export", "users:read users:disable", nil, nil, time.Now()))
+
+	key, err := a.r.GetByPrefix("prefix")
+	a.NoError(err)
+	a.Equal([]string{"users:read", "users:disable"}, key.Scopes)
+	a.Nil(key.ExpiresAt)
+	a.Nil(key.LastUsedAt)
+}
+
+func (a *apiKeyRepositorySuite) TestGetByPrefix_NotFound() {
+	a.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT id, prefix, secret_hash, user_id, name, scopes, expires_at, last_used_at, created_at FROM api_keys WHERE prefix = $1")).
+		WithArgs("ghost").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := a.r.GetByPrefix("ghost")
+	a.ErrorIs(err, sql.ErrNoRows)
+}
+
+func (a *apiKeyRepositorySuite) TestGetAll_Success() {
+	lastUsedAt := time.Now()
+	a.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT id, prefix, secret_hash, user_id, name, scopes, expires_at, last_used_at, created_at FROM api_keys ORDER BY id")).
+		WillReturnRows(sqlmock.NewRows(apiKeyColumns).
+			AddRow(1, "first", "hash", 2, "nightly export", "users:read", nil, lastUsedAt, time.Now()).
+			AddRow(2, "second", "hash", 3, "sync", "users:read", time.Now().Add(time.Hour), nil, time.Now()))
+
+	keys, err := a.r.GetAll()
+	a.NoError(err)
+	a.Len(keys, 2)
+	a.Equal(lastUsedAt, *keys[0].LastUsedAt)
+	a.NotNil(keys[1].ExpiresAt)
+}
+
+func (a *apiKeyRepositorySuite) TestDelete_NotFound() {
+	a.mockSQL.ExpectExec(regexp.QuoteMeta("DELETE FROM api_keys WHERE id = $1")).
+		WithArgs(9).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := a.r.Delete(9)
+	a.ErrorIs(err, sql.ErrNoRows)
+}
+
+func (a *apiKeyRepositorySuite) TestUpdateLastUsed_Success() {
+	usedAt := time.Now()
+	a.mockSQL.ExpectExec(regexp.QuoteMeta("UPDATE api_keys SET last_used_at = $1 WHERE id = $2")).
+		WithArgs(usedAt, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	a.NoError(a.r.UpdateLastUsed(1, usedAt))
+}
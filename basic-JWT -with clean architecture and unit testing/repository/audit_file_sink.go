@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"basic-JWT/model"
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// fileAuditSink writes every event as one JSON line, for shipping to a log
+// pipeline. The file only ever grows, rotating it is left to the operator.
+type fileAuditSink struct {
+	path string
+	mu   sync.Mutex
+}
+
+// Append opens the file for every event, so a file moved away by logrotate
+// is recreated on the next event.
+func (f *fileAuditSink) Append(event *model.AuditEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(line, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func NewFileAuditSink(path string) AuditSink {
+	return &fileAuditSink{path: path}
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"basic-JWT/model"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// AuditSink receives audit events. Events are only ever appended, never
+// changed or removed.
+type AuditSink interface {
+	Append(event *model.AuditEvent) error
+}
+
+// AuditRepository is the Postgres sink, which can also be searched.
+type AuditRepository interface {
+	AuditSink
+	Find(filter model.AuditEventFilter) ([]model.AuditEvent, int, error)
+}
+
+type auditRepository struct {
+	db *sql.DB
+}
+
+func (r *auditRepository) Append(event *model.AuditEvent) error {
+	return r.db.QueryRow("INSERT INTO audit_events (actor_id, actor, action, target, ip, outcome, reason, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id",
+		event.ActorID, event.Actor, event.Action, event.Target, event.IP, event.Outcome, event.Reason, event.CreatedAt).Scan(&event.ID)
+}
+
+// Find returns one page of the events matching the filter, newest first, and
+// the number of matching events. Page and Limit must already be valid.
+func (r *auditRepository) Find(filter model.AuditEventFilter) ([]model.AuditEvent, int, error) {
+	conditions := []string{}
+	args := []interface{}{}
+	where := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.Actor != "" {
+		where("actor = $%d", filter.Actor)
+	}
+	if filter.Action != "" {
+		where("action = $%d", filter.Action)
+	}
+	if filter.Target != "" {
+		where("target = $%d", filter.Target)
+	}
+	if filter.Outcome != "" {
+		where("outcome = $%d", filter.Outcome)
+	}
+	if !filter.From.IsZero() {
+		where("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		where("created_at < $%d", filter.To)
+	}
+
+	clause := ""
+	if len(conditions) > 0 {
+		clause = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM audit_events"+clause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+	rows, err := r.db.Query(fmt.Sprintf("SELECT id, actor_id, actor, action, target, ip, outcome, reason, created_at FROM audit_events%s ORDER BY id DESC LIMIT $%d OFFSET $%d", clause, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	events := []model.AuditEvent{}
+	for rows.Next() {
+		var event model.AuditEvent
+		if err := rows.Scan(&event.ID, &event.ActorID, &event.Actor, &event.Action, &event.Target, &event.IP, &event.Outcome, &event.Reason, &event.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	return &auditRepository{
+		db: db,
+	}
+}
@@ -0,0 +1,150 @@
+package repository_test
+
+import (
+	"basic-JWT/model"
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	. "basic-JWT/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+type auditRepositorySuite struct {
+	suite.Suite
+	r       AuditRepository
+	mockDB  *sql.DB
+	mockSQL sqlmock.Sqlmock
+}
+
+func TestAuditRepositorySuite(t *testing.T) {
+	suite.Run(t, new(auditRepositorySuite))
+}
+
+func (r *auditRepositorySuite) SetupTest() {
+	mockDB, mockSQL, err := sqlmock.New()
+	if err != nil {
+		r.T().Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	r.mockDB = mockDB
+	r.mockSQL = mockSQL
+	r.r = NewAuditRepository(mockDB)
+}
+
+func (r *auditRepositorySuite) TestAppend_Success() {
+	createdAt := time.Now()
+	event := model.AuditEvent{ActorID: 1, Actor: "admin", Action: model.AuditActionUserDelete, Target: "bob", IP: "10.0.0.1", Outcome: model.AuditOutcomeSuccess, CreatedAt: createdAt}
+
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("INSERT INTO audit_events (actor_id, actor, action, target, ip, outcome, reason, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id")).
+		WithArgs(1, "admin", model.AuditActionUserDelete, "bob", "10.0.0.1", model.AuditOutcomeSuccess, "", createdAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+
+	err := r.r.Append(&event)
+	r.NoError(err)
+	r.Equal(int64(7), event.ID)
+	r.NoError(r.mockSQL.ExpectationsWereMet())
+}
+
+func (r *auditRepositorySuite) TestAppend_Failed() {
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("INSERT INTO audit_events")).WillReturnError(errors.New("error"))
+
+	err := r.r.Append(&model.AuditEvent{})
+	r.EqualError(err, "error")
+}
+
+func (r *auditRepositorySuite) TestFind_NoFilter() {
+	createdAt := time.Now()
+
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM audit_events")).
+		WithArgs().
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT id, actor_id, actor, action, target, ip, outcome, reason, created_at FROM audit_events ORDER BY id DESC LIMIT $1 OFFSET $2")).
+		WithArgs(50, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "actor_id", "actor", "action", "target", "ip", "outcome", "reason", "created_at"}).
+			AddRow(1, 0, "alice", model.AuditActionLogin, "alice", "10.0.0.1", model.AuditOutcomeFailure, "invalid username or password", createdAt))
+
+	events, total, err := r.r.Find(model.AuditEventFilter{Page: 1, Limit: 50})
+	r.NoError(err)
+	r.Equal(1, total)
+	r.Equal([]model.AuditEvent{{ID: 1, Actor: "alice", Action: model.AuditActionLogin, Target: "alice", IP: "10.0.0.1", Outcome: model.AuditOutcomeFailure, Reason: "invalid username or password", CreatedAt: createdAt}}, events)
+	r.NoError(r.mockSQL.ExpectationsWereMet())
+}
+
+func (r *auditRepositorySuite) TestFind_Filtered() {
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	filter := model.AuditEventFilter{Actor: "admin", Action: model.AuditActionUserDelete, Outcome: model.AuditOutcomeSuccess, From: from, To: to, Page: 3, Limit: 10}
+	where := " WHERE actor = $1 AND action = $2 AND outcome = $3 AND created_at >= $4 AND created_at < $5"
+
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM audit_events"+where)).
+		WithArgs("admin", model.AuditActionUserDelete, model.AuditOutcomeSuccess, from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("FROM audit_events"+where+" ORDER BY id DESC LIMIT $6 OFFSET $7")).
+		WithArgs("admin", model.AuditActionUserDelete, model.AuditOutcomeSuccess, from, to, 10, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "actor_id", "actor", "action", "target", "ip", "outcome", "reason", "created_at"}))
+
+	events, total, err := r.r.Find(filter)
+	r.NoError(err)
+	r.Equal(0, total)
+	r.Empty(events)
+	r.NoError(r.mockSQL.ExpectationsWereMet())
+}
+
+func (r *auditRepositorySuite) TestFind_CountFailed() {
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM audit_events")).WillReturnError(errors.New("error"))
+
+	_, _, err := r.r.Find(model.AuditEventFilter{Page: 1, Limit: 50})
+	r.EqualError(err, "error")
+}
+
+type fileAuditSinkSuite struct {
+	suite.Suite
+	path string
+	sink AuditSink
+}
+
+func TestFileAuditSinkSuite(t *testing.T) {
+	suite.Run(t, new(fileAuditSinkSuite))
+}
+
+func (f *fileAuditSinkSuite) SetupTest() {
+	f.path = filepath.Join(f.T().TempDir(), "audit.log")
+	f.sink = NewFileAuditSink(f.path)
+}
+
+func (f *fileAuditSinkSuite) TestAppend_WritesJSONLines() {
+	f.NoError(f.sink.Append(&model.AuditEvent{Action: model.AuditActionLogin, Target: "alice", Outcome: model.AuditOutcomeSuccess}))
+	f.NoError(f.sink.Append(&model.AuditEvent{Action: model.AuditActionLogin, Target: "bob", Outcome: model.AuditOutcomeFailure}))
+
+	file, err := os.Open(f.path)
+	f.Require().NoError(err)
+	defer file.Close()
+
+	targets := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event model.AuditEvent
+		f.Require().NoError(json.Unmarshal(scanner.Bytes(), &event))
+		targets = append(targets, event.Target)
+	}
+	f.Equal([]string{"alice", "bob"}, targets)
+
+	info, err := os.Stat(f.path)
+	f.Require().NoError(err)
+	f.Equal(os.FileMode(0o600), info.Mode().Perm())
+}
+
+func (f *fileAuditSinkSuite) TestAppend_MissingDirectory() {
+	sink := NewFileAuditSink(filepath.Join(f.T().TempDir(), "missing", "audit.log"))
+
+	f.Error(sink.Append(&model.AuditEvent{}))
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"basic-JWT/config"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// maxConnectBackoff caps the wait between two connection attempts.
+const maxConnectBackoff = 30 * time.Second
+
+// NewDB opens the connection pool and waits until the database accepts
+// connections, so the service can start while Postgres is still booting.
+func NewDB(dbConfig config.DBConfig) (*sql.DB, error) {
+	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable", dbConfig.Host, dbConfig.Port, dbConfig.Username, dbConfig.Password, dbConfig.Database)
+	db, err := sql.Open(dbConfig.Driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(dbConfig.MaxOpenConns)
+	db.SetMaxIdleConns(dbConfig.MaxIdleConns)
+	db.SetConnMaxLifetime(dbConfig.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(dbConfig.ConnMaxIdleTime)
+
+	if err := WaitForDB(db, dbConfig.ConnectRetries, dbConfig.ConnectBackoff); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// WaitForDB pings db until it answers, retrying up to retries times. The wait
+// between attempts starts at backoff and doubles every time, up to
+// maxConnectBackoff.
+func WaitForDB(db *sql.DB, retries int, backoff time.Duration) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = db.Ping(); err == nil {
+			return nil
+		}
+		if attempt >= retries {
+			break
+		}
+
+		log.Printf("database is not ready (attempt %d of %d): %v, retrying in %s", attempt+1, retries+1, err, backoff)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
+	return fmt.Errorf("database is not reachable after %d attempts: %w", retries+1, err)
+}
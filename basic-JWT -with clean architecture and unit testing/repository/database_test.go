@@ -0,0 +1,45 @@
+package repository_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "basic-JWT/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitForDB_RetriesUntilReachable(t *testing.T) {
+	db, mockSQL, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mockSQL.ExpectPing().WillReturnError(errors.New("connection refused"))
+	mockSQL.ExpectPing().WillReturnError(errors.New("the database system is starting up"))
+	mockSQL.ExpectPing()
+
+	assert.NoError(t, WaitForDB(db, 5, time.Millisecond))
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestWaitForDB_GivesUp(t *testing.T) {
+	db, mockSQL, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	pingErr := errors.New("connection refused")
+	for i := 0; i < 3; i++ {
+		mockSQL.ExpectPing().WillReturnError(pingErr)
+	}
+
+	err = WaitForDB(db, 2, time.Millisecond)
+	assert.ErrorIs(t, err, pingErr)
+	assert.EqualError(t, err, "database is not reachable after 3 attempts: connection refused")
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// Constraint errors, so usecases can tell a duplicate value or a reference
+// to a missing row apart from a failed query.
+var (
+	ErrDuplicate        = errors.New("duplicate value")
+	ErrMissingReference = errors.New("referenced row does not exist")
+)
+
+// notFoundError describes a missing row in words while still matching
+// sql.ErrNoRows, which is what usecases check for.
+type notFoundError struct {
+	message string
+}
+
+func (e *notFoundError) Error() string {
+	return e.message
+}
+
+func (e *notFoundError) Unwrap() error {
+	return sql.ErrNoRows
+}
+
+func notFound(format string, args ...interface{}) error {
+	return &notFoundError{message: fmt.Sprintf(format, args...)}
+}
+
+// constraintError maps unique and foreign key violations reported by
+// postgres to ErrDuplicate and ErrMissingReference and passes every other
+// error through.
+func constraintError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	switch pqErr.Code.Name() {
+	case "unique_violation":
+		return ErrDuplicate
+	case "foreign_key_violation":
+		return ErrMissingReference
+	}
+	return err
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"basic-JWT/model"
+	"sync"
+	"time"
+)
+
+type memoryLoginAttempt struct {
+	attempt   model.LoginAttempt
+	expiresAt time.Time
+}
+
+type inMemoryLoginAttemptRepository struct {
+	mu        sync.Mutex
+	attempts  map[string]*memoryLoginAttempt
+	lastSweep time.Time
+}
+
+func (mr *inMemoryLoginAttemptRepository) Get(key string) (model.LoginAttempt, error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	entry, ok := mr.attempts[key]
+	if !ok {
+		return model.LoginAttempt{Key: key}, nil
+	}
+	return entry.attempt, nil
+}
+
+func (mr *inMemoryLoginAttemptRepository) RegisterFailure(key string, window time.Duration) (model.LoginAttempt, error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	now := time.Now()
+	mr.sweep(now, window)
+
+	entry, ok := mr.attempts[key]
+	if !ok {
+		entry = &memoryLoginAttempt{attempt: model.LoginAttempt{Key: key}}
+		mr.attempts[key] = entry
+	}
+
+	if entry.attempt.LastFailureAt.Before(now.Add(-window)) {
+		entry.attempt.Failures = 0
+	}
+	entry.attempt.Failures++
+	entry.attempt.LastFailureAt = now
+	entry.expiresAt = latest(now.Add(window), entry.attempt.LockedUntil)
+
+	return entry.attempt, nil
+}
+
+func (mr *inMemoryLoginAttemptRepository) Lock(key string, until time.Time) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	if entry, ok := mr.attempts[key]; ok {
+		entry.attempt.LockedUntil = until
+		entry.expiresAt = latest(entry.expiresAt, until)
+	}
+	return nil
+}
+
+func (mr *inMemoryLoginAttemptRepository) Reset(key string) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	delete(mr.attempts, key)
+	return nil
+}
+
+// sweep drops expired counters at most once per window, so guessing random
+// usernames cannot grow the map without bound.
+func (mr *inMemoryLoginAttemptRepository) sweep(now time.Time, window time.Duration) {
+	if now.Sub(mr.lastSweep) < window {
+		return
+	}
+	mr.lastSweep = now
+
+	for key, entry := range mr.attempts {
+		if entry.expiresAt.Before(now) {
+			delete(mr.attempts, key)
+		}
+	}
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func NewInMemoryLoginAttemptRepository() LoginAttemptRepository {
+	return &inMemoryLoginAttemptRepository{
+		attempts: map[string]*memoryLoginAttempt{},
+	}
+}
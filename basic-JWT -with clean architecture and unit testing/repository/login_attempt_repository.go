@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"basic-JWT/model"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// LoginAttemptRepository stores failed login counters. NewLoginAttemptRepository
+// keeps them in Postgres so every instance shares them,
+// NewInMemoryLoginAttemptRepository keeps them in the process.
+type LoginAttemptRepository interface {
+	// Get returns a zero attempt when the key has no recorded failures.
+	Get(key string) (model.LoginAttempt, error)
+	// RegisterFailure increments the counter, restarting it when the previous
+	// failure is older than window.
+	RegisterFailure(key string, window time.Duration) (model.LoginAttempt, error)
+	Lock(key string, until time.Time) error
+	Reset(key string) error
+}
+
+type loginAttemptRepository struct {
+	db *sql.DB
+}
+
+func (lr *loginAttemptRepository) Get(key string) (model.LoginAttempt, error) {
+	attempt := model.LoginAttempt{Key: key}
+	var lockedUntil sql.NullTime
+	row := lr.db.QueryRow("SELECT failures, last_failure_at, locked_until FROM login_attempts WHERE attempt_key = $1", key)
+	if err := row.Scan(&attempt.Failures, &attempt.LastFailureAt, &lockedUntil); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return attempt, nil
+		}
+		return model.LoginAttempt{}, err
+	}
+	attempt.LockedUntil = lockedUntil.Time
+	return attempt, nil
+}
+
+func (lr *loginAttemptRepository) RegisterFailure(key string, window time.Duration) (model.LoginAttempt, error) {
+	now := time.Now()
+	attempt := model.LoginAttempt{Key: key}
+	var lockedUntil sql.NullTime
+	err := lr.db.QueryRow(`INSERT INTO login_attempts (attempt_key, failures, last_failure_at) VALUES ($1, 1, $2)
+		ON CONFLICT (attempt_key) DO UPDATE SET
+			failures = CASE WHEN login_attempts.last_failure_at < $3 THEN 1 ELSE login_attempts.failures + 1 END,
+			last_failure_at = EXCLUDED.last_failure_at
+		RETURNING failures, last_failure_at, locked_until`, key, now, now.Add(-window)).
+		Scan(&attempt.Failures, &attempt.LastFailureAt, &lockedUntil)
+	if err != nil {
+		return model.LoginAttempt{}, err
+	}
+	attempt.LockedUntil = lockedUntil.Time
+	return attempt, nil
+}
+
+func (lr *loginAttemptRepository) Lock(key string, until time.Time) error {
+	_, err := lr.db.Exec("UPDATE login_attempts SET locked_until = $2 WHERE attempt_key = $1", key, until)
+	return err
+}
+
+func (lr *loginAttemptRepository) Reset(key string) error {
+	_, err := lr.db.Exec("DELETE FROM login_attempts WHERE attempt_key = $1", key)
+	return err
+}
+
+func NewLoginAttemptRepository(db *sql.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{
+		db: db,
+	}
+}
@@ -0,0 +1,143 @@
+package repository_test
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	. "basic-JWT/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+type loginAttemptRepositorySuite struct {
+	suite.Suite
+	r       LoginAttemptRepository
+	mockDB  *sql.DB
+	mockSQL sqlmock.Sqlmock
+}
+
+func TestLoginAttemptRepositorySuite(t *testing.T) {
+	suite.Run(t, new(loginAttemptRepositorySuite))
+}
+
+func (l *loginAttemptRepositorySuite) SetupTest() {
+	mockDB, mockSQL, err := sqlmock.New()
+	if err != nil {
+		l.T().Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	l.mockDB = mockDB
+	l.mockSQL = mockSQL
+	l.r = NewLoginAttemptRepository(mockDB)
+}
+
+func (l *loginAttemptRepositorySuite) TestGet_Success() {
+	lockedUntil := time.Now().Add(time.Minute)
+	l.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT failures, last_failure_at, locked_until FROM login_attempts WHERE attempt_key = $1")).
+		WithArgs("user:alice").
+		WillReturnRows(sqlmock.NewRows([]string{"failures", "last_failure_at", "locked_until"}).AddRow(5, time.Now(), lockedUntil))
+
+	attempt, err := l.r.Get("user:alice")
+	l.NoError(err)
+	l.Equal(5, attempt.Failures)
+	l.Equal(lockedUntil, attempt.LockedUntil)
+}
+
+func (l *loginAttemptRepositorySuite) TestGet_NoFailures() {
+	l.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT failures, last_failure_at, locked_until FROM login_attempts WHERE attempt_key = $1")).
+		WithArgs("user:alice").
+		WillReturnError(sql.ErrNoRows)
+
+	attempt, err := l.r.Get("user:alice")
+	l.NoError(err)
+	l.Equal("user:alice", attempt.Key)
+	l.Zero(attempt.Failures)
+}
+
+func (l *loginAttemptRepositorySuite) TestRegisterFailure_Success() {
+	l.mockSQL.ExpectQuery(regexp.QuoteMeta("INSERT INTO login_attempts (attempt_key, failures, last_failure_at) VALUES ($1, 1, $2)")).
+		WithArgs("ip:10.0.0.1", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"failures", "last_failure_at", "locked_until"}).AddRow(3, time.Now(), nil))
+
+	attempt, err := l.r.RegisterFailure("ip:10.0.0.1", 15*time.Minute)
+	l.NoError(err)
+	l.Equal(3, attempt.Failures)
+	l.True(attempt.LockedUntil.IsZero())
+}
+
+func (l *loginAttemptRepositorySuite) TestRegisterFailure_Failed() {
+	l.mockSQL.ExpectQuery(regexp.QuoteMeta("INSERT INTO login_attempts")).
+		WillReturnError(errors.New("error"))
+
+	_, err := l.r.RegisterFailure("ip:10.0.0.1", 15*time.Minute)
+	l.Error(err)
+}
+
+func (l *loginAttemptRepositorySuite) TestLock_Success() {
+	until := time.Now().Add(15 * time.Minute)
+	l.mockSQL.ExpectExec(regexp.QuoteMeta("UPDATE login_attempts SET locked_until = $2 WHERE attempt_key = $1")).
+		WithArgs("user:alice", until).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := l.r.Lock("user:alice", until)
+	l.NoError(err)
+}
+
+func (l *loginAttemptRepositorySuite) TestReset_Success() {
+	l.mockSQL.ExpectExec(regexp.QuoteMeta("DELETE FROM login_attempts WHERE attempt_key = $1")).
+		WithArgs("user:alice").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := l.r.Reset("user:alice")
+	l.NoError(err)
+}
+
+type inMemoryLoginAttemptRepositorySuite struct {
+	suite.Suite
+	r LoginAttemptRepository
+}
+
+func TestInMemoryLoginAttemptRepositorySuite(t *testing.T) {
+	suite.Run(t, new(inMemoryLoginAttemptRepositorySuite))
+}
+
+func (l *inMemoryLoginAttemptRepositorySuite) SetupTest() {
+	l.r = NewInMemoryLoginAttemptRepository()
+}
+
+func (l *inMemoryLoginAttemptRepositorySuite) TestRegisterFailure_Counts() {
+	l.r.RegisterFailure("user:alice", time.Minute)
+	attempt, err := l.r.RegisterFailure("user:alice", time.Minute)
+	l.NoError(err)
+	l.Equal(2, attempt.Failures)
+
+	stored, _ := l.r.Get("user:alice")
+	l.Equal(2, stored.Failures)
+}
+
+func (l *inMemoryLoginAttemptRepositorySuite) TestRegisterFailure_RestartsAfterWindow() {
+	l.r.RegisterFailure("user:alice", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	attempt, err := l.r.RegisterFailure("user:alice", time.Millisecond)
+	l.NoError(err)
+	l.Equal(1, attempt.Failures)
+}
+
+func (l *inMemoryLoginAttemptRepositorySuite) TestLockAndReset() {
+	until := time.Now().Add(time.Minute)
+	l.r.RegisterFailure("user:alice", time.Minute)
+	l.NoError(l.r.Lock("user:alice", until))
+
+	attempt, _ := l.r.Get("user:alice")
+	l.Equal(until, attempt.LockedUntil)
+
+	l.NoError(l.r.Reset("user:alice"))
+	attempt, _ = l.r.Get("user:alice")
+	l.Zero(attempt.Failures)
+	l.True(attempt.LockedUntil.IsZero())
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"basic-JWT/model"
+	"database/sql"
+)
+
+type MfaRepository interface {
+	Save(mfa *model.UserMfa) error
+	GetByUserID(userID int) (model.UserMfa, error)
+	Enable(userID int, step int64, recoveryCodeHashes []string) error
+	UpdateLastUsedStep(userID int, step int64) (bool, error)
+	UseRecoveryCode(userID int, codeHash string) (bool, error)
+	Delete(userID int) error
+}
+
+type mfaRepository struct {
+	db *sql.DB
+}
+
+// Save stores a pending (not yet confirmed) secret, replacing any earlier
+// pending enrollment of the same user.
+func (mr *mfaRepository) Save(mfa *model.UserMfa) error {
+	return mr.db.QueryRow(`INSERT INTO user_mfa (user_id, secret) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, enabled = FALSE, last_used_step = 0, created_at = NOW(), enabled_at = NULL
+		RETURNING created_at`, mfa.UserID, mfa.Secret).Scan(&mfa.CreatedAt)
+}
+
+func (mr *mfaRepository) GetByUserID(userID int) (model.UserMfa, error) {
+	var mfa model.UserMfa
+	row := mr.db.QueryRow("SELECT user_id, secret, enabled, last_used_step, created_at, enabled_at FROM user_mfa WHERE user_id = $1", userID)
+	if err := row.Scan(&mfa.UserID, &mfa.Secret, &mfa.Enabled, &mfa.LastUsedStep, &mfa.CreatedAt, &mfa.EnabledAt); err != nil {
+		return model.UserMfa{}, err
+	}
+	return mfa, nil
+}
+
+// Enable activates the enrollment and replaces the user's recovery codes in
+// one transaction.
+func (mr *mfaRepository) Enable(userID int, step int64, recoveryCodeHashes []string) error {
+	tx, err := mr.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE user_mfa SET enabled = TRUE, last_used_step = $2, enabled_at = NOW() WHERE user_id = $1", userID, step); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM mfa_recovery_codes WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+	for _, hash := range recoveryCodeHashes {
+		if _, err := tx.Exec("INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)", userID, hash); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UpdateLastUsedStep records the time step of an accepted code. It reports
+// false when that step, or a later one, was already used.
+func (mr *mfaRepository) UpdateLastUsedStep(userID int, step int64) (bool, error) {
+	result, err := mr.db.Exec("UPDATE user_mfa SET last_used_step = $2 WHERE user_id = $1 AND last_used_step < $2", userID, step)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// UseRecoveryCode consumes an unused recovery code. It reports false when the
+// code does not exist or was already used.
+func (mr *mfaRepository) UseRecoveryCode(userID int, codeHash string) (bool, error) {
+	result, err := mr.db.Exec("UPDATE mfa_recovery_codes SET used_at = NOW() WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL", userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (mr *mfaRepository) Delete(userID int) error {
+	tx, err := mr.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM mfa_recovery_codes WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM user_mfa WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func NewMfaRepository(db *sql.DB) MfaRepository {
+	return &mfaRepository{
+		db: db,
+	}
+}
@@ -0,0 +1,135 @@
+package repository_test
+
+import (
+	"basic-JWT/model"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	. "basic-JWT/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+type mfaRepositorySuite struct {
+	suite.Suite
+	r       MfaRepository
+	mockDB  *sql.DB
+	mockSQL sqlmock.Sqlmock
+}
+
+func TestMfaRepositorySuite(t *testing.T) {
+	suite.Run(t, new(mfaRepositorySuite))
+}
+
+func (m *mfaRepositorySuite) SetupTest() {
+	mockDB, mockSQL, err := sqlmock.New()
+	if err != nil {
+		m.T().Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	m.mockDB = mockDB
+	m.mockSQL = mockSQL
+	m.r = NewMfaRepository(mockDB)
+}
+
+func (m *mfaRepositorySuite) TestSave_Success() {
+	m.mockSQL.ExpectQuery(regexp.QuoteMeta("INSERT INTO user_mfa (user_id, secret) VALUES ($1, $2)")).
+		WithArgs(1, "SECRET").
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+
+	err := m.r.Save(&model.UserMfa{UserID: 1, Secret: "SECRET"})
+	m.NoError(err)
+}
+
+func (m *mfaRepositorySuite) TestGetByUserID_Success() {
+	m.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT user_id, secret, enabled, last_used_step, created_at, enabled_at FROM user_mfa WHERE user_id = $1")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "secret", "enabled", "last_used_step", "created_at", "enabled_at"}).
+			AddRow(1, "SECRET", true, 100, time.Now(), time.Now()))
+
+	mfa, err := m.r.GetByUserID(1)
+	m.NoError(err)
+	m.Equal("SECRET", mfa.Secret)
+	m.True(mfa.Enabled)
+	m.Equal(int64(100), mfa.LastUsedStep)
+}
+
+func (m *mfaRepositorySuite) TestGetByUserID_NotFound() {
+	m.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT user_id, secret, enabled, last_used_step, created_at, enabled_at FROM user_mfa WHERE user_id = $1")).
+		WithArgs(1).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := m.r.GetByUserID(1)
+	m.ErrorIs(err, sql.ErrNoRows)
+}
+
+func (m *mfaRepositorySuite) TestEnable_Success() {
+	m.mockSQL.ExpectBegin()
+	m.mockSQL.ExpectExec(regexp.QuoteMeta("UPDATE user_mfa SET enabled = TRUE, last_used_step = $2, enabled_at = NOW() WHERE user_id = $1")).
+		WithArgs(1, int64(100)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	m.mockSQL.ExpectExec(regexp.QuoteMeta("DELETE FROM mfa_recovery_codes WHERE user_id = $1")).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	m.mockSQL.ExpectExec(regexp.QuoteMeta("INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)")).
+		WithArgs(1, "hash1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	m.mockSQL.ExpectExec(regexp.QuoteMeta("INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)")).
+		WithArgs(1, "hash2").
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	m.mockSQL.ExpectCommit()
+
+	err := m.r.Enable(1, 100, []string{"hash1", "hash2"})
+	m.NoError(err)
+	m.NoError(m.mockSQL.ExpectationsWereMet())
+}
+
+func (m *mfaRepositorySuite) TestEnable_RollbackOnError() {
+	m.mockSQL.ExpectBegin()
+	m.mockSQL.ExpectExec(regexp.QuoteMeta("UPDATE user_mfa SET enabled = TRUE")).
+		WillReturnError(errors.New("error"))
+	m.mockSQL.ExpectRollback()
+
+	err := m.r.Enable(1, 100, []string{"hash1"})
+	m.Error(err)
+	m.NoError(m.mockSQL.ExpectationsWereMet())
+}
+
+func (m *mfaRepositorySuite) TestUpdateLastUsedStep_Replay() {
+	m.mockSQL.ExpectExec(regexp.QuoteMeta("UPDATE user_mfa SET last_used_step = $2 WHERE user_id = $1 AND last_used_step < $2")).
+		WithArgs(1, int64(100)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ok, err := m.r.UpdateLastUsedStep(1, 100)
+	m.NoError(err)
+	m.False(ok)
+}
+
+func (m *mfaRepositorySuite) TestUseRecoveryCode_Success() {
+	m.mockSQL.ExpectExec(regexp.QuoteMeta("UPDATE mfa_recovery_codes SET used_at = NOW() WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL")).
+		WithArgs(1, "hash").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ok, err := m.r.UseRecoveryCode(1, "hash")
+	m.NoError(err)
+	m.True(ok)
+}
+
+func (m *mfaRepositorySuite) TestDelete_Success() {
+	m.mockSQL.ExpectBegin()
+	m.mockSQL.ExpectExec(regexp.QuoteMeta("DELETE FROM mfa_recovery_codes WHERE user_id = $1")).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 10))
+	m.mockSQL.ExpectExec(regexp.QuoteMeta("DELETE FROM user_mfa WHERE user_id = $1")).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	m.mockSQL.ExpectCommit()
+
+	err := m.r.Delete(1)
+	m.NoError(err)
+	m.NoError(m.mockSQL.ExpectationsWereMet())
+}
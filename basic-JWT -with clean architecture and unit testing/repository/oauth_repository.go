@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"basic-JWT/model"
+	"database/sql"
+	"strings"
+)
+
+type OAuthRepository interface {
+	CreateClient(client *model.OAuthClient) (*model.OAuthClient, error)
+	GetClientByClientID(clientID string) (model.OAuthClient, error)
+	GetAllClients() ([]model.OAuthClient, error)
+	DeleteClient(clientID string) error
+	CreateAuthorizationCode(code *model.AuthorizationCode) error
+	ConsumeAuthorizationCode(codeHash string) (model.AuthorizationCode, error)
+}
+
+type oauthRepository struct {
+	db *sql.DB
+}
+
+// redirect URIs, grant types and scopes cannot contain spaces, so the lists
+// are stored space separated like the OAuth scope parameter
+func (or *oauthRepository) CreateClient(client *model.OAuthClient) (*model.OAuthClient, error) {
+	err := or.db.QueryRow("INSERT INTO oauth_clients (client_id, secret_hash, name, redirect_uris, grant_types, scopes, confidential) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at",
+		client.ClientID, client.SecretHash, client.Name, strings.Join(client.RedirectURIs, " "), strings.Join(client.GrantTypes, " "), strings.Join(client.Scopes, " "), client.Confidential).
+		Scan(&client.ID, &client.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+func (or *oauthRepository) GetClientByClientID(clientID string) (model.OAuthClient, error) {
+	row := or.db.QueryRow("SELECT id, client_id, secret_hash, name, redirect_uris, grant_types, scopes, confidential, created_at FROM oauth_clients WHERE client_id = $1", clientID)
+	return scanOAuthClient(row)
+}
+
+func (or *oauthRepository) GetAllClients() ([]model.OAuthClient, error) {
+	rows, err := or.db.Query("SELECT id, client_id, secret_hash, name, redirect_uris, grant_types, scopes, confidential, created_at FROM oauth_clients ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	clients := []model.OAuthClient{}
+	for rows.Next() {
+		client, err := scanOAuthClient(rows)
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, client)
+	}
+	return clients, rows.Err()
+}
+
+func (or *oauthRepository) DeleteClient(clientID string) error {
+	result, err := or.db.Exec("DELETE FROM oauth_clients WHERE client_id = $1", clientID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (or *oauthRepository) CreateAuthorizationCode(code *model.AuthorizationCode) error {
+	return or.db.QueryRow("INSERT INTO oauth_authorization_codes (code_hash, client_id, user_id, redirect_uri, scope, code_challenge, nonce, mfa, expires_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at",
+		code.CodeHash, code.ClientID, code.UserID, code.RedirectURI, code.Scope, code.CodeChallenge, code.Nonce, code.Mfa, code.ExpiresAt).
+		Scan(&code.ID, &code.CreatedAt)
+}
+
+// ConsumeAuthorizationCode marks the code as used and returns it in one
+// statement, so a code can be exchanged only once even under concurrent
+// requests. Unknown and already used codes return sql.ErrNoRows.
+func (or *oauthRepository) ConsumeAuthorizationCode(codeHash string) (model.AuthorizationCode, error) {
+	var code model.AuthorizationCode
+	row := or.db.QueryRow("UPDATE oauth_authorization_codes SET used = TRUE WHERE code_hash = $1 AND used = FALSE RETURNING id, code_hash, client_id, user_id, redirect_uri, scope, code_challenge, nonce, mfa, expires_at, created_at", codeHash)
+	if err := row.Scan(&code.ID, &code.CodeHash, &code.ClientID, &code.UserID, &code.RedirectURI, &code.Scope, &code.CodeChallenge, &code.Nonce, &code.Mfa, &code.ExpiresAt, &code.CreatedAt); err != nil {
+		return model.AuthorizationCode{}, err
+	}
+	return code, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanOAuthClient(row rowScanner) (model.OAuthClient, error) {
+	var client model.OAuthClient
+	var redirectURIs, grantTypes, scopes string
+	if err := row.Scan(&client.ID, &client.ClientID, &client.SecretHash, &client.Name, &redirectURIs, &grantTypes, &scopes, &client.Confidential, &client.CreatedAt); err != nil {
+		return model.OAuthClient{}, err
+	}
+	client.RedirectURIs = strings.Fields(redirectURIs)
+	client.GrantTypes = strings.Fields(grantTypes)
+	client.Scopes = strings.Fields(scopes)
+	return client, nil
+}
+
+func NewOAuthRepository(db *sql.DB) OAuthRepository {
+	return &oauthRepository{db: db}
+}
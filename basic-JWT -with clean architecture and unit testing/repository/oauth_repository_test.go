@@ -0,0 +1,151 @@
+package repository_test
+
+import (
+	"basic-JWT/model"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	. "basic-JWT/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+type oauthRepositorySuite struct {
+	suite.Suite
+	r       OAuthRepository
+	mockDB  *sql.DB
+	mockSQL sqlmock.Sqlmock
+}
+
+func TestOAuthRepositorySuite(t *testing.T) {
+	suite.Run(t, new(oauthRepositorySuite))
+}
+
+func (o *oauthRepositorySuite) SetupTest() {
+	mockDB, mockSQL, err := sqlmock.New()
+	if err != nil {
+		o.T().Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	o.mockDB = mockDB
+	o.mockSQL = mockSQL
+	o.r = NewOAuthRepository(mockDB)
+}
+
+var oauthClientColumns = []string{"id", "client_id", "secret_hash", "name", "redirect_uris", "grant_types", "scopes", "confidential", "created_at"}
+
+func (o *oauthRepositorySuite) TestCreateClient_Success() {
+	client := model.OAuthClient{
+		ClientID:     "client",
+		SecretHash:   "hash",
+		Name:         "Portal",
+		RedirectURIs: []string{"https://portal.example.com/callback", "http://localhost:3000/callback"},
+		GrantTypes:   []string{"authorization_code", "refresh_token"},
+		Scopes:       []string{"openid", "profile"},
+		Confidential: true,
+	}
+
+	o.mockSQL.ExpectQuery(regexp.QuoteMeta("INSERT INTO oauth_clients (client_id, secret_hash, name, redirect_uris, grant_types, scopes, confidential) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at")).
+		WithArgs("client", "hash", "Portal", "https://portal.example.com/callback http://localhost:3000/callback", "authorization_code refresh_token", "openid profile", true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+
+	result, err := o.r.CreateClient(&client)
+	o.NoError(err)
+	o.Equal(1, result.ID)
+}
+
+func (o *oauthRepositorySuite) TestCreateClient_Failed() {
+	o.mockSQL.ExpectQuery(regexp.QuoteMeta("INSERT INTO oauth_clients")).
+		WillReturnError(errors.New("error"))
+
+	_, err := o.r.CreateClient(&model.OAuthClient{ClientID: "client"})
+	o.Error(err)
+}
+
+func (o *oauthRepositorySuite) TestGetClientByClientID_Success() {
+	o.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT id, client_id, secret_hash, name, redirect_uris, grant_types, scopes, confidential, created_at FROM oauth_clients WHERE client_id = $1")).
+		WithArgs("client").
+		WillReturnRows(sqlmock.NewRows(oauthClientColumns).
+			AddRow(1, "client", "", "Portal", "https://portal.example.com/callback", "authorization_code", "openid profile", false, time.Now()))
+
+	client, err := o.r.GetClientByClientID("client")
+	o.NoError(err)
+	o.Equal([]string{"https://portal.example.com/callback"}, client.RedirectURIs)
+	o.Equal([]string{"authorization_code"}, client.GrantTypes)
+	o.Equal([]string{"openid", "profile"}, client.Scopes)
+	o.False(client.Confidential)
+}
+
+func (o *oauthRepositorySuite) TestGetClientByClientID_NotFound() {
+	o.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT id, client_id")).
+		WithArgs("client").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := o.r.GetClientByClientID("client")
+	o.ErrorIs(err, sql.ErrNoRows)
+}
+
+func (o *oauthRepositorySuite) TestGetAllClients_Success() {
+	o.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT id, client_id, secret_hash, name, redirect_uris, grant_types, scopes, confidential, created_at FROM oauth_clients ORDER BY id")).
+		WillReturnRows(sqlmock.NewRows(oauthClientColumns).
+			AddRow(1, "portal", "", "Portal", "https://portal.example.com/callback", "authorization_code", "openid", false, time.Now()).
+			AddRow(2, "reporting", "hash", "Reporting", "", "client_credentials", "reports:read", true, time.Now()))
+
+	clients, err := o.r.GetAllClients()
+	o.NoError(err)
+	o.Len(clients, 2)
+	o.Empty(clients[1].RedirectURIs)
+}
+
+func (o *oauthRepositorySuite) TestDeleteClient_Success() {
+	o.mockSQL.ExpectExec(regexp.QuoteMeta("DELETE FROM oauth_clients WHERE client_id = $1")).
+		WithArgs("client").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	o.NoError(o.r.DeleteClient("client"))
+}
+
+func (o *oauthRepositorySuite) TestDeleteClient_NotFound() {
+	o.mockSQL.ExpectExec(regexp.QuoteMeta("DELETE FROM oauth_clients WHERE client_id = $1")).
+		WithArgs("client").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	o.ErrorIs(o.r.DeleteClient("client"), sql.ErrNoRows)
+}
+
+func (o *oauthRepositorySuite) TestCreateAuthorizationCode_Success() {
+	expiresAt := time.Now().Add(time.Minute)
+	code := model.AuthorizationCode{CodeHash: "hash", ClientID: "client", UserID: 1, RedirectURI: "https://portal.example.com/callback", Scope: "openid", CodeChallenge: "challenge", Nonce: "nonce", Mfa: true, ExpiresAt: expiresAt}
+
+	o.mockSQL.ExpectQuery(regexp.QuoteMeta("INSERT INTO oauth_authorization_codes (code_hash, client_id, user_id, redirect_uri, scope, code_challenge, nonce, mfa, expires_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at")).
+		WithArgs("hash", "client", 1, "https://portal.example.com/callback", "openid", "challenge", "nonce", true, expiresAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+
+	o.NoError(o.r.CreateAuthorizationCode(&code))
+	o.Equal(1, code.ID)
+}
+
+func (o *oauthRepositorySuite) TestConsumeAuthorizationCode_Success() {
+	o.mockSQL.ExpectQuery(regexp.QuoteMeta("UPDATE oauth_authorization_codes SET used = TRUE WHERE code_hash = $1 AND used = FALSE RETURNING id, code_hash, client_id, user_id, redirect_uri, scope, code_challenge, nonce, mfa, expires_at, created_at")).
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "code_hash", "client_id", "user_id", "redirect_uri", "scope", "code_challenge", "nonce", "mfa", "expires_at", "created_at"}).
+			AddRow(1, "hash", "client", 2, "https://portal.example.com/callback", "openid", "challenge", "", false, time.Now(), time.Now()))
+
+	code, err := o.r.ConsumeAuthorizationCode("hash")
+	o.NoError(err)
+	o.Equal(2, code.UserID)
+	o.Equal("client", code.ClientID)
+}
+
+func (o *oauthRepositorySuite) TestConsumeAuthorizationCode_AlreadyUsed() {
+	o.mockSQL.ExpectQuery(regexp.QuoteMeta("UPDATE oauth_authorization_codes SET used = TRUE")).
+		WithArgs("hash").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := o.r.ConsumeAuthorizationCode("hash")
+	o.ErrorIs(err, sql.ErrNoRows)
+}
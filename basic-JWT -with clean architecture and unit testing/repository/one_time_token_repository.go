@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+// OneTimeTokenRepository records the jti of single use tokens, such as email
+// verification and password reset tokens, once they have been used.
+type OneTimeTokenRepository interface {
+	// MarkUsed records the jti and reports whether this call was the first to
+	// do so. The insert is atomic, so concurrent requests cannot both use a token.
+	MarkUsed(jti string, purpose string, expiresAt time.Time) (bool, error)
+	DeleteExpired() error
+}
+
+type oneTimeTokenRepository struct {
+	db *sql.DB
+}
+
+func (r *oneTimeTokenRepository) MarkUsed(jti string, purpose string, expiresAt time.Time) (bool, error) {
+	result, err := r.db.Exec("INSERT INTO one_time_tokens (jti, purpose, expires_at) VALUES ($1, $2, $3) ON CONFLICT (jti) DO NOTHING", jti, purpose, expiresAt)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *oneTimeTokenRepository) DeleteExpired() error {
+	_, err := r.db.Exec("DELETE FROM one_time_tokens WHERE expires_at < $1", time.Now())
+	return err
+}
+
+func NewOneTimeTokenRepository(db *sql.DB) OneTimeTokenRepository {
+	return &oneTimeTokenRepository{
+		db: db,
+	}
+}
@@ -0,0 +1,77 @@
+package repository_test
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	. "basic-JWT/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+type oneTimeTokenRepositorySuite struct {
+	suite.Suite
+	r       OneTimeTokenRepository
+	mockDB  *sql.DB
+	mockSQL sqlmock.Sqlmock
+}
+
+func TestOneTimeTokenRepositorySuite(t *testing.T) {
+	suite.Run(t, new(oneTimeTokenRepositorySuite))
+}
+
+func (r *oneTimeTokenRepositorySuite) SetupTest() {
+	mockDB, mockSQL, err := sqlmock.New()
+	if err != nil {
+		r.T().Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	r.mockDB = mockDB
+	r.mockSQL = mockSQL
+	r.r = NewOneTimeTokenRepository(mockDB)
+}
+
+func (r *oneTimeTokenRepositorySuite) TestMarkUsed_FirstUse() {
+	expiresAt := time.Now().Add(time.Hour)
+
+	r.mockSQL.ExpectExec(regexp.QuoteMeta("INSERT INTO one_time_tokens (jti, purpose, expires_at) VALUES ($1, $2, $3) ON CONFLICT (jti) DO NOTHING")).
+		WithArgs("jti", "password_reset", expiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	first, err := r.r.MarkUsed("jti", "password_reset", expiresAt)
+	r.NoError(err)
+	r.True(first)
+}
+
+func (r *oneTimeTokenRepositorySuite) TestMarkUsed_AlreadyUsed() {
+	expiresAt := time.Now().Add(time.Hour)
+
+	r.mockSQL.ExpectExec(regexp.QuoteMeta("INSERT INTO one_time_tokens (jti, purpose, expires_at) VALUES ($1, $2, $3) ON CONFLICT (jti) DO NOTHING")).
+		WithArgs("jti", "password_reset", expiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	first, err := r.r.MarkUsed("jti", "password_reset", expiresAt)
+	r.NoError(err)
+	r.False(first)
+}
+
+func (r *oneTimeTokenRepositorySuite) TestMarkUsed_Failed() {
+	r.mockSQL.ExpectExec(regexp.QuoteMeta("INSERT INTO one_time_tokens (jti, purpose, expires_at) VALUES ($1, $2, $3) ON CONFLICT (jti) DO NOTHING")).
+		WillReturnError(errors.New("error"))
+
+	_, err := r.r.MarkUsed("jti", "password_reset", time.Now())
+	r.Error(err)
+}
+
+func (r *oneTimeTokenRepositorySuite) TestDeleteExpired_Success() {
+	r.mockSQL.ExpectExec(regexp.QuoteMeta("DELETE FROM one_time_tokens WHERE expires_at < $1")).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	err := r.r.DeleteExpired()
+	r.NoError(err)
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"basic-JWT/model"
+	"database/sql"
+	"strings"
+)
+
+type RbacRepository interface {
+	CreateRole(role *model.Role) (*model.Role, error)
+	GetAllRoles() ([]model.Role, error)
+	DeleteRole(id int) error
+	CreatePermission(permission *model.Permission) (*model.Permission, error)
+	GetAllPermissions() ([]model.Permission, error)
+	DeletePermission(id int) error
+	GrantPermission(roleID int, permissionID int) error
+	RevokePermission(roleID int, permissionID int) error
+	GetPermissionsByRole(roleName string) ([]string, error)
+	GetPermissionByID(id int) (model.Permission, error)
+	// GetRoleIDsWithPermission lists the roles granted the named permission.
+	GetRoleIDsWithPermission(permission string) ([]int, error)
+	// CountUsersWithRole counts the users, deleted ones excluded, that hold
+	// the role.
+	CountUsersWithRole(roleID int) (int, error)
+}
+
+type rbacRepository struct {
+	db *sql.DB
+}
+
+func (rr *rbacRepository) CreateRole(role *model.Role) (*model.Role, error) {
+	err := rr.db.QueryRow("INSERT INTO roles (name, description) VALUES ($1, $2) RETURNING id", role.Name, role.Description).Scan(&role.ID)
+	if err != nil {
+		return nil, constraintError(err)
+	}
+	return role, nil
+}
+
+func (rr *rbacRepository) GetAllRoles() ([]model.Role, error) {
+	roles := []model.Role{}
+	rows, err := rr.db.Query(`SELECT r.id, r.name, r.description, COALESCE(string_agg(p.name, ',' ORDER BY p.name), '')
+		FROM roles r
+		LEFT JOIN role_permissions rp ON rp.role_id = r.id
+		LEFT JOIN permissions p ON p.id = rp.permission_id
+		GROUP BY r.id, r.name, r.description
+		ORDER BY r.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var role model.Role
+		var permissions string
+		if err := rows.Scan(&role.ID, &role.Name, &role.Description, &permissions); err != nil {
+			return nil, err
+		}
+		role.Permissions = []string{}
+		if permissions != "" {
+			role.Permissions = strings.Split(permissions, ",")
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
+
+func (rr *rbacRepository) DeleteRole(id int) error {
+	result, err := rr.db.Exec("DELETE FROM roles WHERE id = $1", id)
+	return deletedOne(result, err)
+}
+
+func (rr *rbacRepository) CreatePermission(permission *model.Permission) (*model.Permission, error) {
+	err := rr.db.QueryRow("INSERT INTO permissions (name, description) VALUES ($1, $2) RETURNING id", permission.Name, permission.Description).Scan(&permission.ID)
+	if err != nil {
+		return nil, constraintError(err)
+	}
+	return permission, nil
+}
+
+func (rr *rbacRepository) GetAllPermissions() ([]model.Permission, error) {
+	permissions := []model.Permission{}
+	rows, err := rr.db.Query("SELECT id, name, description FROM permissions ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var permission model.Permission
+		if err := rows.Scan(&permission.ID, &permission.Name, &permission.Description); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+	return permissions, rows.Err()
+}
+
+func (rr *rbacRepository) DeletePermission(id int) error {
+	result, err := rr.db.Exec("DELETE FROM permissions WHERE id = $1", id)
+	return deletedOne(result, err)
+}
+
+func (rr *rbacRepository) GrantPermission(roleID int, permissionID int) error {
+	_, err := rr.db.Exec("INSERT INTO role_permissions (role_id, permission_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", roleID, permissionID)
+	return constraintError(err)
+}
+
+func (rr *rbacRepository) RevokePermission(roleID int, permissionID int) error {
+	result, err := rr.db.Exec("DELETE FROM role_permissions WHERE role_id = $1 AND permission_id = $2", roleID, permissionID)
+	return deletedOne(result, err)
+}
+
+func (rr *rbacRepository) GetPermissionsByRole(roleName string) ([]string, error) {
+	permissions := []string{}
+	rows, err := rr.db.Query(`SELECT p.name FROM permissions p
+		JOIN role_permissions rp ON rp.permission_id = p.id
+		JOIN roles r ON r.id = rp.role_id
+		WHERE r.name = $1`, roleName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, name)
+	}
+	return permissions, rows.Err()
+}
+
+func (rr *rbacRepository) GetPermissionByID(id int) (model.Permission, error) {
+	var permission model.Permission
+	row := rr.db.QueryRow("SELECT id, name, description FROM permissions WHERE id = $1", id)
+	if err := row.Scan(&permission.ID, &permission.Name, &permission.Description); err != nil {
+		if err == sql.ErrNoRows {
+			return model.Permission{}, notFound("permission with id %d not found", id)
+		}
+		return model.Permission{}, err
+	}
+	return permission, nil
+}
+
+func (rr *rbacRepository) GetRoleIDsWithPermission(permission string) ([]int, error) {
+	roleIDs := []int{}
+	rows, err := rr.db.Query(`SELECT rp.role_id FROM role_permissions rp
+		JOIN permissions p ON p.id = rp.permission_id
+		WHERE p.name = $1`, permission)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var roleID int
+		if err := rows.Scan(&roleID); err != nil {
+			return nil, err
+		}
+		roleIDs = append(roleIDs, roleID)
+	}
+	return roleIDs, rows.Err()
+}
+
+func (rr *rbacRepository) CountUsersWithRole(roleID int) (int, error) {
+	var count int
+	err := rr.db.QueryRow(`SELECT COUNT(*) FROM users u
+		JOIN roles r ON r.name = u.role
+		WHERE r.id = $1 AND u.deleted_at IS NULL`, roleID).Scan(&count)
+	return count, err
+}
+
+// deletedOne reports sql.ErrNoRows when a delete matched no row.
+func deletedOne(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func NewRbacRepository(db *sql.DB) RbacRepository {
+	return &rbacRepository{
+		db: db,
+	}
+}
@@ -0,0 +1,198 @@
+package repository_test
+
+import (
+	"basic-JWT/model"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+
+	. "basic-JWT/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/suite"
+)
+
+type rbacRepositorySuite struct {
+	suite.Suite
+	r       RbacRepository
+	mockDB  *sql.DB
+	mockSQL sqlmock.Sqlmock
+}
+
+func TestRbacRepositorySuite(t *testing.T) {
+	suite.Run(t, new(rbacRepositorySuite))
+}
+
+func (r *rbacRepositorySuite) SetupTest() {
+	mockDB, mockSQL, err := sqlmock.New()
+	if err != nil {
+		r.T().Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	r.mockDB = mockDB
+	r.mockSQL = mockSQL
+	r.r = NewRbacRepository(mockDB)
+}
+
+func (r *rbacRepositorySuite) TestCreateRole_Success() {
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("INSERT INTO roles (name, description) VALUES ($1, $2) RETURNING id")).
+		WithArgs("editor", "can edit").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+
+	role, err := r.r.CreateRole(&model.Role{Name: "editor", Description: "can edit"})
+	r.NoError(err)
+	r.Equal(3, role.ID)
+}
+
+func (r *rbacRepositorySuite) TestCreateRole_Duplicate() {
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("INSERT INTO roles (name, description) VALUES ($1, $2) RETURNING id")).
+		WithArgs("admin", "").
+		WillReturnError(&pq.Error{Code: "23505"})
+
+	_, err := r.r.CreateRole(&model.Role{Name: "admin"})
+	r.ErrorIs(err, ErrDuplicate)
+}
+
+func (r *rbacRepositorySuite) TestGetAllRoles_Success() {
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT r.id, r.name, r.description")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "permissions"}).
+			AddRow(1, "admin", "", "users:create,users:read").
+			AddRow(2, "guest", "", ""))
+
+	roles, err := r.r.GetAllRoles()
+	r.NoError(err)
+	r.Len(roles, 2)
+	r.Equal([]string{"users:create", "users:read"}, roles[0].Permissions)
+	r.Empty(roles[1].Permissions)
+}
+
+func (r *rbacRepositorySuite) TestGetAllRoles_Failed() {
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT r.id, r.name, r.description")).
+		WillReturnError(errors.New("error"))
+
+	_, err := r.r.GetAllRoles()
+	r.Error(err)
+}
+
+func (r *rbacRepositorySuite) TestCreatePermission_Success() {
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("INSERT INTO permissions (name, description) VALUES ($1, $2) RETURNING id")).
+		WithArgs("reports:read", "").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+
+	permission, err := r.r.CreatePermission(&model.Permission{Name: "reports:read"})
+	r.NoError(err)
+	r.Equal(9, permission.ID)
+}
+
+func (r *rbacRepositorySuite) TestGetAllPermissions_Success() {
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT id, name, description FROM permissions ORDER BY name")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description"}).AddRow(1, "users:read", ""))
+
+	permissions, err := r.r.GetAllPermissions()
+	r.NoError(err)
+	r.Len(permissions, 1)
+}
+
+func (r *rbacRepositorySuite) TestGrantPermission_Success() {
+	r.mockSQL.ExpectExec(regexp.QuoteMeta("INSERT INTO role_permissions (role_id, permission_id) VALUES ($1, $2) ON CONFLICT DO NOTHING")).
+		WithArgs(1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := r.r.GrantPermission(1, 2)
+	r.NoError(err)
+}
+
+func (r *rbacRepositorySuite) TestGrantPermission_MissingReference() {
+	r.mockSQL.ExpectExec(regexp.QuoteMeta("INSERT INTO role_permissions (role_id, permission_id) VALUES ($1, $2) ON CONFLICT DO NOTHING")).
+		WithArgs(1, 99).
+		WillReturnError(&pq.Error{Code: "23503"})
+
+	err := r.r.GrantPermission(1, 99)
+	r.ErrorIs(err, ErrMissingReference)
+}
+
+func (r *rbacRepositorySuite) TestRevokePermission_Success() {
+	r.mockSQL.ExpectExec(regexp.QuoteMeta("DELETE FROM role_permissions WHERE role_id = $1 AND permission_id = $2")).
+		WithArgs(1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := r.r.RevokePermission(1, 2)
+	r.NoError(err)
+}
+
+func (r *rbacRepositorySuite) TestRevokePermission_NotGranted() {
+	r.mockSQL.ExpectExec(regexp.QuoteMeta("DELETE FROM role_permissions WHERE role_id = $1 AND permission_id = $2")).
+		WithArgs(1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := r.r.RevokePermission(1, 2)
+	r.ErrorIs(err, sql.ErrNoRows)
+}
+
+func (r *rbacRepositorySuite) TestDeleteRole_NotFound() {
+	r.mockSQL.ExpectExec(regexp.QuoteMeta("DELETE FROM roles WHERE id = $1")).
+		WithArgs(99).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := r.r.DeleteRole(99)
+	r.ErrorIs(err, sql.ErrNoRows)
+}
+
+func (r *rbacRepositorySuite) TestDeletePermission_NotFound() {
+	r.mockSQL.ExpectExec(regexp.QuoteMeta("DELETE FROM permissions WHERE id = $1")).
+		WithArgs(99).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := r.r.DeletePermission(99)
+	r.ErrorIs(err, sql.ErrNoRows)
+}
+
+func (r *rbacRepositorySuite) TestDeleteRole_Failed() {
+	r.mockSQL.ExpectExec(regexp.QuoteMeta("DELETE FROM roles WHERE id = $1")).
+		WithArgs(1).
+		WillReturnError(errors.New("error"))
+
+	err := r.r.DeleteRole(1)
+	r.Error(err)
+}
+
+func (r *rbacRepositorySuite) TestGetPermissionsByRole_Success() {
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT p.name FROM permissions p")).
+		WithArgs("admin").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("users:create").AddRow("users:read"))
+
+	permissions, err := r.r.GetPermissionsByRole("admin")
+	r.NoError(err)
+	r.Equal([]string{"users:create", "users:read"}, permissions)
+}
+
+func (r *rbacRepositorySuite) TestGetPermissionByID_NotFound() {
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT id, name, description FROM permissions WHERE id = $1")).
+		WithArgs(99).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := r.r.GetPermissionByID(99)
+	r.ErrorIs(err, sql.ErrNoRows)
+}
+
+func (r *rbacRepositorySuite) TestGetRoleIDsWithPermission_Success() {
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT rp.role_id FROM role_permissions rp")).
+		WithArgs("rbac:manage").
+		WillReturnRows(sqlmock.NewRows([]string{"role_id"}).AddRow(1).AddRow(4))
+
+	roleIDs, err := r.r.GetRoleIDsWithPermission("rbac:manage")
+	r.NoError(err)
+	r.Equal([]int{1, 4}, roleIDs)
+}
+
+func (r *rbacRepositorySuite) TestCountUsersWithRole_Success() {
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM users u")).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	count, err := r.r.CountUsersWithRole(2)
+	r.NoError(err)
+	r.Equal(3, count)
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"basic-JWT/model"
+	"database/sql"
+)
+
+type RefreshTokenRepository interface {
+	Create(token *model.RefreshToken) (*model.RefreshToken, error)
+	GetByHash(tokenHash string) (model.RefreshToken, error)
+	Rotate(usedID int, next *model.RefreshToken) (bool, error)
+	RevokeFamily(familyID string) error
+	RevokeAllForUser(userID int) error
+}
+
+type refreshTokenRepository struct {
+	db *sql.DB
+}
+
+func (r *refreshTokenRepository) Create(token *model.RefreshToken) (*model.RefreshToken, error) {
+	err := r.db.QueryRow("INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, mfa, client_id, scope) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at",
+		token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt, token.Mfa, token.ClientID, token.Scope).Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (r *refreshTokenRepository) GetByHash(tokenHash string) (model.RefreshToken, error) {
+	var token model.RefreshToken
+	row := r.db.QueryRow("SELECT id, user_id, family_id, token_hash, expires_at, used, revoked, mfa, client_id, scope, created_at FROM refresh_tokens WHERE token_hash = $1", tokenHash)
+	if err := row.Scan(&token.ID, &token.UserID, &token.FamilyID, &token.TokenHash, &token.ExpiresAt, &token.Used, &token.Revoked, &token.Mfa, &token.ClientID, &token.Scope, &token.CreatedAt); err != nil {
+		return model.RefreshToken{}, err
+	}
+	return token, nil
+}
+
+// Rotate flags the token usedID as consumed and stores its successor in one
+// transaction, so the family never appears to have no unused token and a
+// failed insert leaves the old token usable. It reports false when the token
+// was already used, so two concurrent refreshes cannot both succeed.
+func (r *refreshTokenRepository) Rotate(usedID int, next *model.RefreshToken) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("UPDATE refresh_tokens SET used = TRUE WHERE id = $1 AND used = FALSE", usedID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	err = tx.QueryRow("INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, mfa, client_id, scope) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at",
+		next.UserID, next.FamilyID, next.TokenHash, next.ExpiresAt, next.Mfa, next.ClientID, next.Scope).Scan(&next.ID, &next.CreatedAt)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (r *refreshTokenRepository) RevokeFamily(familyID string) error {
+	_, err := r.db.Exec("UPDATE refresh_tokens SET revoked = TRUE WHERE family_id = $1", familyID)
+	return err
+}
+
+func (r *refreshTokenRepository) RevokeAllForUser(userID int) error {
+	_, err := r.db.Exec("UPDATE refresh_tokens SET revoked = TRUE WHERE user_id = $1 AND revoked = FALSE", userID)
+	return err
+}
+
+func NewRefreshTokenRepository(db *sql.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{
+		db: db,
+	}
+}
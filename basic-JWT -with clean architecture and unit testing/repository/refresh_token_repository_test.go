@@ -0,0 +1,157 @@
+package repository_test
+
+import (
+	"basic-JWT/model"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	. "basic-JWT/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+type refreshTokenRepositorySuite struct {
+	suite.Suite
+	r       RefreshTokenRepository
+	mockDB  *sql.DB
+	mockSQL sqlmock.Sqlmock
+}
+
+func TestRefreshTokenRepositorySuite(t *testing.T) {
+	suite.Run(t, new(refreshTokenRepositorySuite))
+}
+
+func (r *refreshTokenRepositorySuite) SetupTest() {
+	mockDB, mockSQL, err := sqlmock.New()
+	if err != nil {
+		r.T().Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	r.mockDB = mockDB
+	r.mockSQL = mockSQL
+	r.r = NewRefreshTokenRepository(mockDB)
+}
+
+func (r *refreshTokenRepositorySuite) TestCreate_Success() {
+	expiresAt := time.Now().Add(time.Hour)
+	token := model.RefreshToken{UserID: 1, FamilyID: "family", TokenHash: "hash", ExpiresAt: expiresAt, Mfa: true, ClientID: "client", Scope: "openid"}
+
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, mfa, client_id, scope) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at")).
+		WithArgs(1, "family", "hash", expiresAt, true, "client", "openid").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+
+	result, err := r.r.Create(&token)
+	r.NoError(err)
+	r.Equal(1, result.ID)
+}
+
+func (r *refreshTokenRepositorySuite) TestCreate_Failed() {
+	token := model.RefreshToken{UserID: 1, FamilyID: "family", TokenHash: "hash", ExpiresAt: time.Now()}
+
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("INSERT INTO refresh_tokens")).
+		WillReturnError(errors.New("error"))
+
+	_, err := r.r.Create(&token)
+	r.Error(err)
+}
+
+func (r *refreshTokenRepositorySuite) TestGetByHash_Success() {
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, family_id, token_hash, expires_at, used, revoked, mfa, client_id, scope, created_at FROM refresh_tokens WHERE token_hash = $1")).
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "family_id", "token_hash", "expires_at", "used", "revoked", "mfa", "client_id", "scope", "created_at"}).
+			AddRow(1, 2, "family", "hash", time.Now(), false, false, true, "client", "openid", time.Now()))
+
+	token, err := r.r.GetByHash("hash")
+	r.NoError(err)
+	r.Equal(2, token.UserID)
+	r.Equal("family", token.FamilyID)
+	r.True(token.Mfa)
+	r.Equal("client", token.ClientID)
+}
+
+func (r *refreshTokenRepositorySuite) TestGetByHash_NotFound() {
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, family_id, token_hash, expires_at, used, revoked, mfa, client_id, scope, created_at FROM refresh_tokens WHERE token_hash = $1")).
+		WithArgs("hash").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := r.r.GetByHash("hash")
+	r.ErrorIs(err, sql.ErrNoRows)
+}
+
+func (r *refreshTokenRepositorySuite) TestRotate_Success() {
+	expiresAt := time.Now().Add(time.Hour)
+	next := model.RefreshToken{UserID: 2, FamilyID: "family", TokenHash: "next", ExpiresAt: expiresAt}
+
+	r.mockSQL.ExpectBegin()
+	r.mockSQL.ExpectExec(regexp.QuoteMeta("UPDATE refresh_tokens SET used = TRUE WHERE id = $1 AND used = FALSE")).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, mfa, client_id, scope) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at")).
+		WithArgs(2, "family", "next", expiresAt, false, "", "").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(2, time.Now()))
+	r.mockSQL.ExpectCommit()
+
+	ok, err := r.r.Rotate(1, &next)
+	r.NoError(err)
+	r.True(ok)
+	r.Equal(2, next.ID)
+	r.NoError(r.mockSQL.ExpectationsWereMet())
+}
+
+func (r *refreshTokenRepositorySuite) TestRotate_AlreadyUsed() {
+	r.mockSQL.ExpectBegin()
+	r.mockSQL.ExpectExec(regexp.QuoteMeta("UPDATE refresh_tokens SET used = TRUE WHERE id = $1 AND used = FALSE")).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	r.mockSQL.ExpectRollback()
+
+	ok, err := r.r.Rotate(1, &model.RefreshToken{FamilyID: "family"})
+	r.NoError(err)
+	r.False(ok)
+	r.NoError(r.mockSQL.ExpectationsWereMet())
+}
+
+func (r *refreshTokenRepositorySuite) TestRotate_InsertFailedRollsBack() {
+	r.mockSQL.ExpectBegin()
+	r.mockSQL.ExpectExec(regexp.QuoteMeta("UPDATE refresh_tokens SET used = TRUE WHERE id = $1 AND used = FALSE")).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("INSERT INTO refresh_tokens")).
+		WillReturnError(errors.New("error"))
+	r.mockSQL.ExpectRollback()
+
+	_, err := r.r.Rotate(1, &model.RefreshToken{FamilyID: "family"})
+	r.Error(err)
+	r.NoError(r.mockSQL.ExpectationsWereMet())
+}
+
+func (r *refreshTokenRepositorySuite) TestRevokeFamily_Success() {
+	r.mockSQL.ExpectExec(regexp.QuoteMeta("UPDATE refresh_tokens SET revoked = TRUE WHERE family_id = $1")).
+		WithArgs("family").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	err := r.r.RevokeFamily("family")
+	r.NoError(err)
+}
+
+func (r *refreshTokenRepositorySuite) TestRevokeFamily_Failed() {
+	r.mockSQL.ExpectExec(regexp.QuoteMeta("UPDATE refresh_tokens SET revoked = TRUE WHERE family_id = $1")).
+		WithArgs("family").
+		WillReturnError(errors.New("error"))
+
+	err := r.r.RevokeFamily("family")
+	r.Error(err)
+}
+
+func (r *refreshTokenRepositorySuite) TestRevokeAllForUser_Success() {
+	r.mockSQL.ExpectExec(regexp.QuoteMeta("UPDATE refresh_tokens SET revoked = TRUE WHERE user_id = $1 AND revoked = FALSE")).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	err := r.r.RevokeAllForUser(1)
+	r.NoError(err)
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"basic-JWT/model"
+	"database/sql"
+	"time"
+)
+
+type RevokedTokenRepository interface {
+	Create(token *model.RevokedToken) error
+	Exists(jti string) (bool, error)
+	// DeleteExpired removes entries for tokens that have expired, since the
+	// signature check already rejects those.
+	DeleteExpired() error
+}
+
+type revokedTokenRepository struct {
+	db *sql.DB
+}
+
+func (r *revokedTokenRepository) Create(token *model.RevokedToken) error {
+	_, err := r.db.Exec("INSERT INTO revoked_tokens (jti, expires_at) VALUES ($1, $2) ON CONFLICT (jti) DO NOTHING", token.JTI, token.ExpiresAt)
+	return err
+}
+
+func (r *revokedTokenRepository) Exists(jti string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow("SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)", jti).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (r *revokedTokenRepository) DeleteExpired() error {
+	_, err := r.db.Exec("DELETE FROM revoked_tokens WHERE expires_at < $1", time.Now())
+	return err
+}
+
+func NewRevokedTokenRepository(db *sql.DB) RevokedTokenRepository {
+	return &revokedTokenRepository{
+		db: db,
+	}
+}
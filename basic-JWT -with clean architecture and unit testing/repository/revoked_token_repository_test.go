@@ -0,0 +1,83 @@
+package repository_test
+
+import (
+	"basic-JWT/model"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	. "basic-JWT/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+type revokedTokenRepositorySuite struct {
+	suite.Suite
+	r       RevokedTokenRepository
+	mockDB  *sql.DB
+	mockSQL sqlmock.Sqlmock
+}
+
+func TestRevokedTokenRepositorySuite(t *testing.T) {
+	suite.Run(t, new(revokedTokenRepositorySuite))
+}
+
+func (r *revokedTokenRepositorySuite) SetupTest() {
+	mockDB, mockSQL, err := sqlmock.New()
+	if err != nil {
+		r.T().Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	r.mockDB = mockDB
+	r.mockSQL = mockSQL
+	r.r = NewRevokedTokenRepository(mockDB)
+}
+
+func (r *revokedTokenRepositorySuite) TestCreate_Success() {
+	expiresAt := time.Now().Add(time.Hour)
+
+	r.mockSQL.ExpectExec(regexp.QuoteMeta("INSERT INTO revoked_tokens (jti, expires_at) VALUES ($1, $2) ON CONFLICT (jti) DO NOTHING")).
+		WithArgs("jti", expiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := r.r.Create(&model.RevokedToken{JTI: "jti", ExpiresAt: expiresAt})
+	r.NoError(err)
+}
+
+func (r *revokedTokenRepositorySuite) TestCreate_Failed() {
+	r.mockSQL.ExpectExec(regexp.QuoteMeta("INSERT INTO revoked_tokens")).
+		WillReturnError(errors.New("error"))
+
+	err := r.r.Create(&model.RevokedToken{JTI: "jti", ExpiresAt: time.Now()})
+	r.Error(err)
+}
+
+func (r *revokedTokenRepositorySuite) TestExists() {
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)")).
+		WithArgs("jti").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	exists, err := r.r.Exists("jti")
+	r.NoError(err)
+	r.True(exists)
+}
+
+func (r *revokedTokenRepositorySuite) TestExists_Failed() {
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS")).
+		WillReturnError(errors.New("error"))
+
+	_, err := r.r.Exists("jti")
+	r.Error(err)
+}
+
+func (r *revokedTokenRepositorySuite) TestDeleteExpired() {
+	r.mockSQL.ExpectExec(regexp.QuoteMeta("DELETE FROM revoked_tokens WHERE expires_at < $1")).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	err := r.r.DeleteExpired()
+	r.NoError(err)
+}
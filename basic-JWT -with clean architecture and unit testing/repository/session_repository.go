@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"basic-JWT/model"
+	"database/sql"
+	"time"
+)
+
+// activeSession matches sessions whose refresh token family still has an
+// unused token that is neither revoked nor expired. Logout, reuse detection
+// and revoking every token of a user all end the session this way.
+const activeSession = "EXISTS (SELECT 1 FROM refresh_tokens t WHERE t.family_id = s.id AND t.used = FALSE AND t.revoked = FALSE AND t.expires_at > $2)"
+
+type SessionRepository interface {
+	Save(session *model.Session) (*model.Session, error)
+	GetByID(id string) (model.Session, error)
+	GetActiveByUser(userID int) ([]model.Session, error)
+	Touch(id string, seenAt time.Time) (bool, error)
+}
+
+type sessionRepository struct {
+	db *sql.DB
+}
+
+// Save creates the session or, when it already exists, records the client
+// and time it was last seen. Families started before sessions were recorded
+// get their row on the first refresh.
+func (r *sessionRepository) Save(session *model.Session) (*model.Session, error) {
+	err := r.db.QueryRow(`INSERT INTO sessions (id, user_id, user_agent, ip, last_seen_at) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (id) DO UPDATE SET user_agent = EXCLUDED.user_agent, ip = EXCLUDED.ip, last_seen_at = EXCLUDED.last_seen_at
+		RETURNING created_at`,
+		session.ID, session.UserID, session.UserAgent, session.IP, session.LastSeenAt).Scan(&session.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (r *sessionRepository) GetByID(id string) (model.Session, error) {
+	var session model.Session
+	row := r.db.QueryRow("SELECT id, user_id, user_agent, ip, created_at, last_seen_at FROM sessions WHERE id = $1", id)
+	if err := row.Scan(&session.ID, &session.UserID, &session.UserAgent, &session.IP, &session.CreatedAt, &session.LastSeenAt); err != nil {
+		return model.Session{}, err
+	}
+	return session, nil
+}
+
+func (r *sessionRepository) GetActiveByUser(userID int) ([]model.Session, error) {
+	rows, err := r.db.Query("SELECT s.id, s.user_id, s.user_agent, s.ip, s.created_at, s.last_seen_at FROM sessions s WHERE s.user_id = $1 AND "+activeSession+" ORDER BY s.last_seen_at DESC",
+		userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []model.Session{}
+	for rows.Next() {
+		var session model.Session
+		if err := rows.Scan(&session.ID, &session.UserID, &session.UserAgent, &session.IP, &session.CreatedAt, &session.LastSeenAt); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// Touch records that the session was seen at seenAt. It reports false when
+// the session does not exist or is no longer active.
+func (r *sessionRepository) Touch(id string, seenAt time.Time) (bool, error) {
+	result, err := r.db.Exec("UPDATE sessions s SET last_seen_at = $2 WHERE s.id = $1 AND "+activeSession, id, seenAt)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func NewSessionRepository(db *sql.DB) SessionRepository {
+	return &sessionRepository{
+		db: db,
+	}
+}
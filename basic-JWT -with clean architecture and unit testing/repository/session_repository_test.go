@@ -0,0 +1,131 @@
+package repository_test
+
+import (
+	"basic-JWT/model"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	. "basic-JWT/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+)
+
+type sessionRepositorySuite struct {
+	suite.Suite
+	r       SessionRepository
+	mockDB  *sql.DB
+	mockSQL sqlmock.Sqlmock
+}
+
+func TestSessionRepositorySuite(t *testing.T) {
+	suite.Run(t, new(sessionRepositorySuite))
+}
+
+func (r *sessionRepositorySuite) SetupTest() {
+	mockDB, mockSQL, err := sqlmock.New()
+	if err != nil {
+		r.T().Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	r.mockDB = mockDB
+	r.mockSQL = mockSQL
+	r.r = NewSessionRepository(mockDB)
+}
+
+func (r *sessionRepositorySuite) TestSave_Success() {
+	seenAt := time.Now()
+	createdAt := seenAt.Add(-time.Hour)
+	session := model.Session{ID: "sid", UserID: 1, UserAgent: "curl/8.0", IP: "10.0.0.1", LastSeenAt: seenAt}
+
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("INSERT INTO sessions (id, user_id, user_agent, ip, last_seen_at) VALUES ($1, $2, $3, $4, $5)")).
+		WithArgs("sid", 1, "curl/8.0", "10.0.0.1", seenAt).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(createdAt))
+
+	result, err := r.r.Save(&session)
+	r.NoError(err)
+	r.Equal(createdAt, result.CreatedAt)
+}
+
+func (r *sessionRepositorySuite) TestSave_Failed() {
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("INSERT INTO sessions")).
+		WillReturnError(errors.New("error"))
+
+	_, err := r.r.Save(&model.Session{ID: "sid", UserID: 1})
+	r.Error(err)
+}
+
+func (r *sessionRepositorySuite) TestGetByID_Success() {
+	now := time.Now()
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, user_agent, ip, created_at, last_seen_at FROM sessions WHERE id = $1")).
+		WithArgs("sid").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "user_agent", "ip", "created_at", "last_seen_at"}).
+			AddRow("sid", 1, "curl/8.0", "10.0.0.1", now, now))
+
+	session, err := r.r.GetByID("sid")
+	r.NoError(err)
+	r.Equal(1, session.UserID)
+	r.Equal("curl/8.0", session.UserAgent)
+}
+
+func (r *sessionRepositorySuite) TestGetByID_NotFound() {
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, user_agent, ip, created_at, last_seen_at FROM sessions")).
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := r.r.GetByID("missing")
+	r.ErrorIs(err, sql.ErrNoRows)
+}
+
+func (r *sessionRepositorySuite) TestGetActiveByUser_Success() {
+	now := time.Now()
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("FROM sessions s WHERE s.user_id = $1 AND EXISTS (SELECT 1 FROM refresh_tokens t WHERE t.family_id = s.id AND t.used = FALSE AND t.revoked = FALSE AND t.expires_at > $2) ORDER BY s.last_seen_at DESC")).
+		WithArgs(1, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "user_agent", "ip", "created_at", "last_seen_at"}).
+			AddRow("sid1", 1, "curl/8.0", "10.0.0.1", now, now).
+			AddRow("sid2", 1, "Firefox", "10.0.0.2", now, now))
+
+	sessions, err := r.r.GetActiveByUser(1)
+	r.NoError(err)
+	r.Len(sessions, 2)
+	r.Equal("sid2", sessions[1].ID)
+}
+
+func (r *sessionRepositorySuite) TestGetActiveByUser_Failed() {
+	r.mockSQL.ExpectQuery(regexp.QuoteMeta("FROM sessions s")).
+		WillReturnError(errors.New("error"))
+
+	_, err := r.r.GetActiveByUser(1)
+	r.Error(err)
+}
+
+func (r *sessionRepositorySuite) TestTouch_Active() {
+	seenAt := time.Now()
+	r.mockSQL.ExpectExec(regexp.QuoteMeta("UPDATE sessions s SET last_seen_at = $2 WHERE s.id = $1 AND EXISTS")).
+		WithArgs("sid", seenAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	active, err := r.r.Touch("sid", seenAt)
+	r.NoError(err)
+	r.True(active)
+}
+
+func (r *sessionRepositorySuite) TestTouch_Ended() {
+	r.mockSQL.ExpectExec(regexp.QuoteMeta("UPDATE sessions s SET last_seen_at")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	active, err := r.r.Touch("sid", time.Now())
+	r.NoError(err)
+	r.False(active)
+}
+
+func (r *sessionRepositorySuite) TestTouch_Failed() {
+	r.mockSQL.ExpectExec(regexp.QuoteMeta("UPDATE sessions s SET last_seen_at")).
+		WillReturnError(errors.New("error"))
+
+	_, err := r.r.Touch("sid", time.Now())
+	r.Error(err)
+}
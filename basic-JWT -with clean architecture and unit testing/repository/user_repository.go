@@ -5,13 +5,19 @@ package repository
 import (
 	"basic-JWT/model"
 	"database/sql"
-	"fmt"
 )
 
 type UserRepository interface {
 	Create(user *model.User) (*model.User, error)
 	GetAllUsers() ([]model.User, error)
 	GetUserByUsername(username string) (model.User, error)
+	GetUserByID(id int) (model.User, error)
+	GetUserByEmail(email string) (model.User, error)
+	Update(user *model.User) error
+	SetDisabled(id int, disabled bool) error
+	SoftDelete(id int) error
+	UpdatePassword(id int, passwordHash string) error
+	SetEmailVerified(id int) error
 }
 
 type userRepository struct {
@@ -19,7 +25,7 @@ type userRepository struct {
 }
 
 func (ur *userRepository) Create(user *model.User) (*model.User, error) {
-	err := ur.db.QueryRow("INSERT INTO users (username, password, role) VALUES ($1, $2, $3) RETURNING id", user.Username, user.Password, user.Role).Scan(&user.ID)
+	err := ur.db.QueryRow("INSERT INTO users (username, email, email_verified, password, role) VALUES ($1, $2, $3, $4, $5) RETURNING id", user.Username, user.Email, user.EmailVerified, user.Password, user.Role).Scan(&user.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -27,33 +33,88 @@ func (ur *userRepository) Create(user *model.User) (*model.User, error) {
 }
 
 func (ur *userRepository) GetAllUsers() ([]model.User, error) {
-	var users []model.User
-	rows, err := ur.db.Query("SELECT id, username, password, role FROM users")
+	users := []model.User{}
+	rows, err := ur.db.Query("SELECT id, username, email, email_verified, password, role, disabled FROM users WHERE deleted_at IS NULL")
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+
 	for rows.Next() {
 		var user model.User
-		if err := rows.Scan(&user.ID, &user.Username, &user.Password, &user.Role); err != nil {
+		if err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.EmailVerified, &user.Password, &user.Role, &user.Disabled); err != nil {
 			return nil, err
 		}
 		users = append(users, user)
 	}
-	return users, nil
+	return users, rows.Err()
 }
 
 func (ur *userRepository) GetUserByUsername(username string) (model.User, error) {
 	var user model.User
-	row := ur.db.QueryRow("SELECT id, username, password, role FROM users WHERE username = $1", username)
-	if err := row.Scan(&user.ID, &user.Username, &user.Password, &user.Role); err != nil {
+	row := ur.db.QueryRow("SELECT id, username, email, email_verified, password, role, disabled FROM users WHERE username = $1 AND deleted_at IS NULL", username)
+	if err := row.Scan(&user.ID, &user.Username, &user.Email, &user.EmailVerified, &user.Password, &user.Role, &user.Disabled); err != nil {
+		if err == sql.ErrNoRows {
+			return model.User{}, notFound("user with username %s not found", username)
+		}
+		return model.User{}, err
+	}
+	return user, nil
+}
+
+func (ur *userRepository) GetUserByID(id int) (model.User, error) {
+	var user model.User
+	row := ur.db.QueryRow("SELECT id, username, email, email_verified, password, role, disabled FROM users WHERE id = $1 AND deleted_at IS NULL", id)
+	if err := row.Scan(&user.ID, &user.Username, &user.Email, &user.EmailVerified, &user.Password, &user.Role, &user.Disabled); err != nil {
 		if err == sql.ErrNoRows {
-			return model.User{}, fmt.Errorf("user with username %s not found", username)
+			return model.User{}, notFound("user with id %d not found", id)
 		}
 		return model.User{}, err
 	}
 	return user, nil
 }
 
+// GetUserByEmail matches the address case-insensitively. Accounts without an
+// email address are never matched.
+func (ur *userRepository) GetUserByEmail(email string) (model.User, error) {
+	var user model.User
+	row := ur.db.QueryRow("SELECT id, username, email, email_verified, password, role, disabled FROM users WHERE LOWER(email) = LOWER($1) AND email <> '' AND deleted_at IS NULL", email)
+	if err := row.Scan(&user.ID, &user.Username, &user.Email, &user.EmailVerified, &user.Password, &user.Role, &user.Disabled); err != nil {
+		if err == sql.ErrNoRows {
+			return model.User{}, notFound("user with email %s not found", email)
+		}
+		return model.User{}, err
+	}
+	return user, nil
+}
+
+func (ur *userRepository) Update(user *model.User) error {
+	_, err := ur.db.Exec("UPDATE users SET username = $2, role = $3 WHERE id = $1 AND deleted_at IS NULL", user.ID, user.Username, user.Role)
+	return err
+}
+
+func (ur *userRepository) SetDisabled(id int, disabled bool) error {
+	_, err := ur.db.Exec("UPDATE users SET disabled = $2 WHERE id = $1 AND deleted_at IS NULL", id, disabled)
+	return err
+}
+
+// SoftDelete hides the user from every query but keeps the row, so audit
+// data and foreign keys stay intact.
+func (ur *userRepository) SoftDelete(id int) error {
+	_, err := ur.db.Exec("UPDATE users SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL", id)
+	return err
+}
+
+func (ur *userRepository) UpdatePassword(id int, passwordHash string) error {
+	_, err := ur.db.Exec("UPDATE users SET password = $2 WHERE id = $1 AND deleted_at IS NULL", id, passwordHash)
+	return err
+}
+
+func (ur *userRepository) SetEmailVerified(id int) error {
+	_, err := ur.db.Exec("UPDATE users SET email_verified = TRUE WHERE id = $1 AND deleted_at IS NULL", id)
+	return err
+}
+
 func NewUserRepository(db *sql.DB) UserRepository {
 	return &userRepository{
 		db: db,
@@ -44,8 +44,8 @@ func (u *userRepositorySuite) TestCreate_Success() {
 		Role:     "user",
 	}
 
-	u.mockSQL.ExpectQuery(regexp.QuoteMeta("INSERT INTO users (username, password, role) VALUES ($1, $2, $3) RETURNING id")).
-		WithArgs(user.Username, user.Password, user.Role).
+	u.mockSQL.ExpectQuery(regexp.QuoteMeta("INSERT INTO users (username, email, email_verified, password, role) VALUES ($1, $2, $3, $4, $5) RETURNING id")).
+		WithArgs(user.Username, user.Email, user.EmailVerified, user.Password, user.Role).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 
 	result, err := u.u.Create(&user)
@@ -60,8 +60,8 @@ func (u *userRepositorySuite) TestCreate_UsernameAlreadyExists() {
 		Role:     "user",
 	}
 
-	u.mockSQL.ExpectQuery("INSERT INTO users (username, password, role) VALUES ($1, $2, $3) RETURNING id").
-		WithArgs(user.Username, user.Password, user.Role).
+	u.mockSQL.ExpectQuery("INSERT INTO users (username, email, email_verified, password, role) VALUES ($1, $2, $3, $4, $5) RETURNING id").
+		WithArgs(user.Username, user.Email, user.EmailVerified, user.Password, user.Role).
 		WillReturnError(errors.New("username already exists"))
 
 	_, err := u.u.Create(&user)
@@ -75,8 +75,8 @@ func (u *userRepositorySuite) TestCreate_Failed() {
 		Role:     "user",
 	}
 
-	u.mockSQL.ExpectQuery("INSERT INTO users (username, password, role) VALUES ($1, $2, $3) RETURNING id").
-		WithArgs(user.Username, user.Password, user.Role).
+	u.mockSQL.ExpectQuery("INSERT INTO users (username, email, email_verified, password, role) VALUES ($1, $2, $3, $4, $5) RETURNING id").
+		WithArgs(user.Username, user.Email, user.EmailVerified, user.Password, user.Role).
 		WillReturnError(errors.New("error"))
 
 	_, err := u.u.Create(&user)
@@ -85,18 +85,40 @@ func (u *userRepositorySuite) TestCreate_Failed() {
 
 func (u *userRepositorySuite) TestGetAllUsers_Success() {
 
-	u.mockSQL.ExpectQuery("SELECT id, username, password, role FROM users").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "password", "role"}).
-			AddRow(1, "username", "password", "user"))
+	u.mockSQL.ExpectQuery("SELECT id, username, email, email_verified, password, role, disabled FROM users WHERE deleted_at IS NULL").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "email", "email_verified", "password", "role", "disabled"}).
+			AddRow(1, "username", "user@example.com", true, "password", "user", false))
 
 	users, err := u.u.GetAllUsers()
 	u.NoError(err)
 	u.Equal(1, len(users))
 }
 
+func (u *userRepositorySuite) TestGetAllUsers_Empty() {
+
+	u.mockSQL.ExpectQuery("SELECT id, username, email, email_verified, password, role, disabled FROM users WHERE deleted_at IS NULL").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "email", "email_verified", "password", "role", "disabled"}))
+
+	users, err := u.u.GetAllUsers()
+	u.NoError(err)
+	u.NotNil(users)
+	u.Empty(users)
+}
+
+func (u *userRepositorySuite) TestGetAllUsers_RowError() {
+
+	u.mockSQL.ExpectQuery("SELECT id, username, email, email_verified, password, role, disabled FROM users WHERE deleted_at IS NULL").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "email", "email_verified", "password", "role", "disabled"}).
+			AddRow(1, "username", "user@example.com", true, "password", "user", false).
+			RowError(0, errors.New("connection reset")))
+
+	_, err := u.u.GetAllUsers()
+	u.Error(err)
+}
+
 func (u *userRepositorySuite) TestGetAllUsers_Failed() {
 
-	u.mockSQL.ExpectQuery("SELECT id, username, password, role FROM users").
+	u.mockSQL.ExpectQuery("SELECT id, username, email, email_verified, password, role, disabled FROM users WHERE deleted_at IS NULL").
 		WillReturnError(errors.New("error"))
 
 	_, err := u.u.GetAllUsers()
@@ -105,10 +127,10 @@ func (u *userRepositorySuite) TestGetAllUsers_Failed() {
 
 func (u *userRepositorySuite) TestGetUserByUsername_Success() {
 
-	u.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT id, username, password, role FROM users WHERE username = $1")).
+	u.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT id, username, email, email_verified, password, role, disabled FROM users WHERE username = $1 AND deleted_at IS NULL")).
 		WithArgs("username").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "password", "role"}).
-			AddRow(1, "username", "password", "user"))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "email", "email_verified", "password", "role", "disabled"}).
+			AddRow(1, "username", "user@example.com", true, "password", "user", false))
 
 	user, err := u.u.GetUserByUsername("username")
 	u.NoError(err)
@@ -117,7 +139,7 @@ func (u *userRepositorySuite) TestGetUserByUsername_Success() {
 
 func (u *userRepositorySuite) TestGetUserByUsername_UserNotFound() {
 
-	u.mockSQL.ExpectQuery("SELECT id, username, password, role FROM users WHERE username = $1").
+	u.mockSQL.ExpectQuery("SELECT id, username, email, email_verified, password, role, disabled FROM users WHERE username = $1 AND deleted_at IS NULL").
 		WithArgs("username").
 		WillReturnError(sql.ErrNoRows)
 
@@ -127,10 +149,99 @@ func (u *userRepositorySuite) TestGetUserByUsername_UserNotFound() {
 
 func (u *userRepositorySuite) TestGetUserByUsername_Failed() {
 
-	u.mockSQL.ExpectQuery("SELECT id, username, password, role FROM users WHERE username = $1").
+	u.mockSQL.ExpectQuery("SELECT id, username, email, email_verified, password, role, disabled FROM users WHERE username = $1 AND deleted_at IS NULL").
 		WithArgs("username").
 		WillReturnError(errors.New("error"))
 
 	_, err := u.u.GetUserByUsername("username")
 	u.Error(err)
 }
+
+func (u *userRepositorySuite) TestGetUserByID_Success() {
+
+	u.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT id, username, email, email_verified, password, role, disabled FROM users WHERE id = $1 AND deleted_at IS NULL")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "email", "email_verified", "password", "role", "disabled"}).
+			AddRow(1, "username", "user@example.com", true, "password", "user", false))
+
+	user, err := u.u.GetUserByID(1)
+	u.NoError(err)
+	u.Equal("username", user.Username)
+}
+
+func (u *userRepositorySuite) TestGetUserByID_UserNotFound() {
+
+	u.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT id, username, email, email_verified, password, role, disabled FROM users WHERE id = $1 AND deleted_at IS NULL")).
+		WithArgs(1).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := u.u.GetUserByID(1)
+	u.EqualError(err, "user with id 1 not found")
+	u.ErrorIs(err, sql.ErrNoRows)
+}
+
+func (u *userRepositorySuite) TestGetUserByEmail_Success() {
+	u.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT id, username, email, email_verified, password, role, disabled FROM users WHERE LOWER(email) = LOWER($1) AND email <> '' AND deleted_at IS NULL")).
+		WithArgs("User@Example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "email", "email_verified", "password", "role", "disabled"}).
+			AddRow(1, "username", "user@example.com", true, "password", "user", false))
+
+	user, err := u.u.GetUserByEmail("User@Example.com")
+	u.NoError(err)
+	u.Equal("username", user.Username)
+	u.True(user.EmailVerified)
+}
+
+func (u *userRepositorySuite) TestGetUserByEmail_UserNotFound() {
+	u.mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT id, username, email, email_verified, password, role, disabled FROM users WHERE LOWER(email) = LOWER($1) AND email <> '' AND deleted_at IS NULL")).
+		WithArgs("user@example.com").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := u.u.GetUserByEmail("user@example.com")
+	u.EqualError(err, "user with email user@example.com not found")
+}
+
+func (u *userRepositorySuite) TestUpdate_Success() {
+	u.mockSQL.ExpectExec(regexp.QuoteMeta("UPDATE users SET username = $2, role = $3 WHERE id = $1 AND deleted_at IS NULL")).
+		WithArgs(1, "renamed", "admin").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := u.u.Update(&model.User{ID: 1, Username: "renamed", Role: "admin"})
+	u.NoError(err)
+}
+
+func (u *userRepositorySuite) TestSetDisabled_Success() {
+	u.mockSQL.ExpectExec(regexp.QuoteMeta("UPDATE users SET disabled = $2 WHERE id = $1 AND deleted_at IS NULL")).
+		WithArgs(1, true).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := u.u.SetDisabled(1, true)
+	u.NoError(err)
+}
+
+func (u *userRepositorySuite) TestSoftDelete_Success() {
+	u.mockSQL.ExpectExec(regexp.QuoteMeta("UPDATE users SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL")).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := u.u.SoftDelete(1)
+	u.NoError(err)
+}
+
+func (u *userRepositorySuite) TestUpdatePassword_Failed() {
+	u.mockSQL.ExpectExec(regexp.QuoteMeta("UPDATE users SET password = $2 WHERE id = $1 AND deleted_at IS NULL")).
+		WithArgs(1, "hash").
+		WillReturnError(errors.New("error"))
+
+	err := u.u.UpdatePassword(1, "hash")
+	u.Error(err)
+}
+
+func (u *userRepositorySuite) TestSetEmailVerified_Success() {
+	u.mockSQL.ExpectExec(regexp.QuoteMeta("UPDATE users SET email_verified = TRUE WHERE id = $1 AND deleted_at IS NULL")).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := u.u.SetEmailVerified(1)
+	u.NoError(err)
+}
@@ -6,35 +6,103 @@ import (
 	"basic-JWT/middleware"
 	"basic-JWT/repository"
 	"basic-JWT/usecase"
+	"basic-JWT/utils/security"
 	"basic-JWT/utils/service"
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"log"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/lib/pq"
 )
 
 type Server struct {
-	userUc usecase.UserUsecase
-	authUc usecase.AuthenticationUsecase
-	jwtSvc service.JWTservice
-	engine *gin.Engine
-	host   string
+	userUc            usecase.UserUsecase
+	authUc            usecase.AuthenticationUsecase
+	accountUc         usecase.AccountUsecase
+	rbacUc            usecase.RbacUsecase
+	mfaUc             usecase.MfaUsecase
+	loginThrottleUc   usecase.LoginThrottleUsecase
+	oauthUc           usecase.OAuthUsecase
+	tokenRevocationUc usecase.TokenRevocationUsecase
+	healthUc          usecase.HealthUsecase
+	apiKeyUc          usecase.APIKeyUsecase
+	sessionUc         usecase.SessionUsecase
+	auditUc           usecase.AuditUsecase
+	jwtSvc            service.JWTservice
+	db                *sql.DB
+	engine            *gin.Engine
+	host              string
+	shutdownTimeout   time.Duration
+	mfaRequiredRoles  []string
+	oauthIssuer       string
+	signingAlg        string
 }
 
 func (s *Server) initRoute() {
 	rg := s.engine.Group("/api/v1")
-	authMiddleware := middleware.NewAuthMiddleware(s.jwtSvc)
+	authMiddleware := middleware.NewAuthMiddleware(s.jwtSvc, s.rbacUc, s.userUc, s.tokenRevocationUc, s.sessionUc, s.apiKeyUc, s.mfaRequiredRoles)
 
-	controller.NewUserController(rg, s.userUc, authMiddleware).Route()
+	controller.NewUserController(rg, s.userUc, s.loginThrottleUc, authMiddleware).Route()
+	controller.NewRbacController(rg, s.rbacUc, authMiddleware).Route()
+	controller.NewMfaController(rg, s.mfaUc, authMiddleware).Route()
 	controller.NewAuthController(rg, s.authUc).Route()
+	controller.NewAccountController(rg, s.accountUc).Route()
+	controller.NewTokenController(rg, s.tokenRevocationUc, authMiddleware).Route()
+	controller.NewAPIKeyController(rg, s.apiKeyUc, authMiddleware).Route()
+	controller.NewSessionController(rg, s.sessionUc, authMiddleware).Route()
+	controller.NewAuditController(rg, s.auditUc, authMiddleware).Route()
+	controller.NewJWKSController(s.engine.Group("/.well-known"), s.jwtSvc).Route()
+	controller.NewOAuthController(rg, s.engine.Group("/.well-known"), s.oauthUc, authMiddleware, s.oauthIssuer, s.signingAlg).Route()
+	controller.NewHealthController(s.engine.Group(""), s.healthUc).Route()
 
 }
 
+// Run serves until SIGINT or SIGTERM. It then stops accepting connections and
+// waits up to shutdownTimeout for in-flight requests before closing the
+// database pool.
 func (s *Server) Run() {
 	s.initRoute()
-	if err := s.engine.Run(s.host); err != nil {
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	httpServer := &http.Server{
+		Addr:              s.host,
+		Handler:           s.engine,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
 		panic(fmt.Errorf("server not running on host %s, becauce error %v", s.host, err.Error()))
+	case <-ctx.Done():
+	}
+	// a second signal kills the process right away
+	stop()
+
+	log.Printf("shutting down, waiting up to %s for in-flight requests", s.shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("graceful shutdown did not finish: %v", err)
+	}
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("server stopped with error: %v", err)
+	}
+	if err := s.db.Close(); err != nil {
+		log.Printf("failed to close database: %v", err)
 	}
 }
 
@@ -44,30 +112,88 @@ func NewServer() *Server {
 		panic("failed to load config")
 	}
 
-	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable", cfg.DB.Host, cfg.DB.Port, cfg.DB.Username, cfg.DB.Password, cfg.DB.Database)
-	db, err := sql.Open(cfg.DB.Driver, dsn)
+	db, err := repository.NewDB(cfg.DB)
 	if err != nil {
-		fmt.Println(err)
-		panic("failed to connect to database")
+		panic(fmt.Errorf("failed to connect to database: %v", err))
 	}
 
-	err = db.Ping()
+	jwtService := service.NewJWTService(cfg.Token)
+	passwordHasher, err := service.NewPasswordHasher(cfg.Password)
 	if err != nil {
-		panic("failed to ping database")
+		panic(err)
+	}
+	breachedPasswords, err := security.LoadBreachedPasswords(cfg.Password.BreachedListFile)
+	if err != nil {
+		panic(err)
+	}
+	passwordPolicy := security.NewPasswordPolicy(cfg.Password.MinLength, cfg.Password.MaxLength, breachedPasswords)
+	mailer, err := service.NewMailer(cfg.Mail)
+	if err != nil {
+		panic(err)
 	}
-
-	jwtService := service.NewJWTService(cfg.Token)
 	userRepo := repository.NewUserRepository(db)
-	userUsecase := usecase.NewUserUsecase(userRepo)
-	authUsecase := usecase.NewAuthenticationUsecase(userUsecase, jwtService)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	rbacRepo := repository.NewRbacRepository(db)
+	mfaRepo := repository.NewMfaRepository(db)
+	oauthRepo := repository.NewOAuthRepository(db)
+	revokedTokenRepo := repository.NewRevokedTokenRepository(db)
+	oneTimeTokenRepo := repository.NewOneTimeTokenRepository(db)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db)
+	healthRepo := repository.NewHealthRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	var auditSink repository.AuditSink = repository.NewAuditRepository(db)
+	if cfg.Audit.Sink == "file" {
+		auditSink = repository.NewFileAuditSink(cfg.Audit.File)
+	}
+	if cfg.Security.LoginThrottle.Store == "memory" {
+		loginAttemptRepo = repository.NewInMemoryLoginAttemptRepository()
+	}
+	refreshTokenUsecase := usecase.NewRefreshTokenUsecase(refreshTokenRepo, cfg.Token.RefreshTokenLifetime)
+	auditUsecase := usecase.NewAuditUsecase(auditSink, userRepo)
+	userUsecase := usecase.NewUserUsecase(userRepo, rbacRepo, refreshTokenUsecase, passwordHasher, passwordPolicy, auditUsecase)
+	mfaUsecase := usecase.NewMfaUsecase(mfaRepo, userUsecase, cfg.Token.ApplicationName)
+	loginThrottleUsecase := usecase.NewLoginThrottleUsecase(loginAttemptRepo, cfg.Security.LoginThrottle)
+	sessionUsecase := usecase.NewSessionUsecase(sessionRepo, refreshTokenUsecase, userUsecase, cfg.Security.RevocationCacheTTL)
+	accountUsecase := usecase.NewAccountUsecase(userUsecase, oneTimeTokenRepo, jwtService, mailer, passwordPolicy, cfg.Account)
+	authUsecase := usecase.NewAuthenticationUsecase(userUsecase, jwtService, refreshTokenUsecase, mfaUsecase, loginThrottleUsecase, passwordHasher, passwordPolicy, accountUsecase, sessionUsecase, auditUsecase)
+	rbacUsecase := usecase.NewRbacUsecase(rbacRepo, cfg.Security.PermissionCacheTTL)
+	tokenRevocationUsecase := usecase.NewTokenRevocationUsecase(revokedTokenRepo, jwtService, cfg.Security.RevocationCacheTTL)
+	oauthUsecase := usecase.NewOAuthUsecase(oauthRepo, userUsecase, refreshTokenUsecase, tokenRevocationUsecase, sessionUsecase, jwtService, cfg.OAuth, cfg.Token.AccessTokenLifetime)
+	healthUsecase := usecase.NewHealthUsecase(healthRepo)
+	apiKeyUsecase := usecase.NewAPIKeyUsecase(apiKeyRepo, userUsecase, rbacUsecase)
 	JwtService := service.NewJWTService(cfg.Token)
 
+	engine := gin.New()
+	// probes hit the service every few seconds, logging them would drown the access log
+	engine.Use(gin.LoggerWithConfig(gin.LoggerConfig{SkipPaths: []string{"/healthz", "/readyz"}}), gin.Recovery())
+	// without trusted proxies c.ClientIP() ignores X-Forwarded-For, which clients could otherwise spoof to dodge the IP limit
+	if err := engine.SetTrustedProxies(cfg.API.TrustedProxies); err != nil {
+		panic(fmt.Errorf("invalid API_TRUSTED_PROXIES: %v", err))
+	}
+	engine.Use(middleware.ErrorHandler())
+
 	return &Server{
-		userUc: userUsecase,
-		authUc: authUsecase,
-		jwtSvc: JwtService,
-		engine: gin.Default(),
-		host:   ":" + cfg.API.Port,
+		userUc:            userUsecase,
+		authUc:            authUsecase,
+		accountUc:         accountUsecase,
+		rbacUc:            rbacUsecase,
+		mfaUc:             mfaUsecase,
+		loginThrottleUc:   loginThrottleUsecase,
+		oauthUc:           oauthUsecase,
+		tokenRevocationUc: tokenRevocationUsecase,
+		healthUc:          healthUsecase,
+		apiKeyUc:          apiKeyUsecase,
+		sessionUc:         sessionUsecase,
+		auditUc:           auditUsecase,
+		jwtSvc:            JwtService,
+		db:                db,
+		engine:            engine,
+		host:              ":" + cfg.API.Port,
+		shutdownTimeout:   cfg.API.ShutdownTimeout,
+		mfaRequiredRoles:  cfg.Security.MfaRequiredRoles,
+		oauthIssuer:       cfg.OAuth.Issuer,
+		signingAlg:        cfg.Token.JwtSignedMethod.Alg(),
 	}
 
 }
@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"basic-JWT/config"
+	"basic-JWT/model"
+	"basic-JWT/repository"
+	"basic-JWT/utils/security"
+	"basic-JWT/utils/service"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	modelutils "basic-JWT/utils/model_utils"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrInvalidAccountToken = NewDomainError(KindValidation, "invalid or expired token")
+
+// AccountUsecase handles the flows started from a link sent by mail: email
+// verification and resetting a forgotten password. The links carry signed
+// tokens bound to the user and the email address, usable once.
+type AccountUsecase interface {
+	SendVerificationEmail(user model.User) error
+	VerifyEmail(token string) error
+	ForgotPassword(email string) error
+	ResetPassword(token string, password string) error
+}
+
+type accountUsecase struct {
+	userUsecase            UserUsecase
+	oneTimeTokenRepository repository.OneTimeTokenRepository
+	jwtService             service.JWTservice
+	mailer                 service.Mailer
+	passwordPolicy         *security.PasswordPolicy
+	accountConfig          config.AccountConfig
+}
+
+func (au *accountUsecase) SendVerificationEmail(user model.User) error {
+	if user.Email == "" {
+		return nil
+	}
+
+	token := au.createToken(user, service.TokenPurposeEmailVerification, au.accountConfig.VerificationTokenLifetime)
+	link, err := linkWithToken(au.accountConfig.VerifyEmailURL, token)
+	if err != nil {
+		return err
+	}
+
+	return au.mailer.Send(service.MailMessage{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nOpen the link below to verify your email address and activate your account:\n\n%s\n\nThe link expires in %s.\n",
+			user.Username, link, au.accountConfig.VerificationTokenLifetime),
+	})
+}
+
+// VerifyEmail marks the address in the token as verified. Opening the link of
+// an already verified address again succeeds without doing anything.
+func (au *accountUsecase) VerifyEmail(token string) error {
+	claims, user, err := au.verifyToken(token, service.TokenPurposeEmailVerification)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return nil
+	}
+
+	if err := au.useToken(claims); err != nil {
+		return err
+	}
+	return au.userUsecase.SetEmailVerified(user.ID)
+}
+
+// ForgotPassword mails a reset link when the address belongs to an active
+// account. It returns nil for unknown addresses too, so callers cannot find
+// out which addresses are registered.
+func (au *accountUsecase) ForgotPassword(email string) error {
+	email, err := NormalizeEmail(email)
+	if err != nil {
+		return nil
+	}
+
+	user, err := au.userUsecase.GetUserByEmail(email)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.Disabled {
+		return nil
+	}
+
+	token := au.createToken(user, service.TokenPurposePasswordReset, au.accountConfig.ResetTokenLifetime)
+	link, err := linkWithToken(au.accountConfig.ResetPasswordURL, token)
+	if err != nil {
+		return err
+	}
+
+	return au.mailer.Send(service.MailMessage{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password of your account. Open the link below to choose a new password:\n\n%s\n\nThe link expires in %s. If you did not ask for this, you can ignore this mail.\n",
+			user.Username, link, au.accountConfig.ResetTokenLifetime),
+	})
+}
+
+// ResetPassword sets the new password and ends every session of the user.
+// Following the link proves the user owns the address, so an unverified
+// address becomes verified as well.
+func (au *accountUsecase) ResetPassword(token string, password string) error {
+	claims, user, err := au.verifyToken(token, service.TokenPurposePasswordReset)
+	if err != nil {
+		return err
+	}
+	if user.Disabled {
+		return ErrAccountDisabled
+	}
+
+	// a password the policy refuses must not use up the token
+	if err := au.passwordPolicy.Validate(password); err != nil {
+		return wrapError(KindValidation, err)
+	}
+	if err := au.useToken(claims); err != nil {
+		return err
+	}
+
+	if err := au.userUsecase.ChangePassword(user.ID, password); err != nil {
+		return err
+	}
+	if !user.EmailVerified {
+		return au.userUsecase.SetEmailVerified(user.ID)
+	}
+	return nil
+}
+
+func (au *accountUsecase) createToken(user model.User, purpose string, lifetime time.Duration) string {
+	return au.jwtService.CreateTokenWithClaims(modelutils.JwtPayloadClaims{
+		UserId:  user.ID,
+		Purpose: purpose,
+		Email:   user.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(lifetime)),
+		},
+	})
+}
+
+// verifyToken checks the signature, expiry and purpose of the token and that
+// the address it was sent to still belongs to the user.
+func (au *accountUsecase) verifyToken(token string, purpose string) (*modelutils.JwtPayloadClaims, model.User, error) {
+	claims, err := au.jwtService.VerifyTokenWithPurpose(token, purpose)
+	if err != nil || claims.ID == "" || claims.ExpiresAt == nil {
+		return nil, model.User{}, ErrInvalidAccountToken
+	}
+
+	user, err := au.userUsecase.GetUserByID(claims.UserId)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, model.User{}, ErrInvalidAccountToken
+		}
+		return nil, model.User{}, err
+	}
+	if user.Email == "" || user.Email != claims.Email {
+		return nil, model.User{}, ErrInvalidAccountToken
+	}
+
+	return claims, user, nil
+}
+
+// useToken records the jti so the token cannot be used a second time.
+func (au *accountUsecase) useToken(claims *modelutils.JwtPayloadClaims) error {
+	first, err := au.oneTimeTokenRepository.MarkUsed(claims.ID, claims.Purpose, claims.ExpiresAt.Time)
+	if err != nil {
+		return err
+	}
+	if !first {
+		return ErrInvalidAccountToken
+	}
+
+	// entries are only needed until the token expires. A failed cleanup is
+	// retried on the next use.
+	_ = au.oneTimeTokenRepository.DeleteExpired()
+	return nil
+}
+
+func linkWithToken(base string, token string) (string, error) {
+	link, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String(), nil
+}
+
+func NewAccountUsecase(userUsecase UserUsecase, oneTimeTokenRepository repository.OneTimeTokenRepository, jwtService service.JWTservice, mailer service.Mailer, passwordPolicy *security.PasswordPolicy, accountConfig config.AccountConfig) AccountUsecase {
+	return &accountUsecase{
+		userUsecase:            userUsecase,
+		oneTimeTokenRepository: oneTimeTokenRepository,
+		jwtService:             jwtService,
+		mailer:                 mailer,
+		passwordPolicy:         passwordPolicy,
+		accountConfig:          accountConfig,
+	}
+}
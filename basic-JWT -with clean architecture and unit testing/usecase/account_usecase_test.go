@@ -0,0 +1,231 @@
+package usecase_test
+
+import (
+	"basic-JWT/config"
+	"basic-JWT/mock/service_mock"
+	"basic-JWT/mock/usecase_mock"
+	"basic-JWT/model"
+	"basic-JWT/usecase"
+	"basic-JWT/utils/security"
+	"basic-JWT/utils/service"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	modelutils "basic-JWT/utils/model_utils"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type accountUcSuite struct {
+	suite.Suite
+	userUc           *usecase_mock.UserUseCaseMock
+	oneTimeTokenRepo *usecase_mock.OneTimeTokenRepositoryMock
+	jwtService       *service_mock.JWTServiceMock
+	mailer           *service_mock.MailerMock
+	accountUc        usecase.AccountUsecase
+	expiresAt        time.Time
+}
+
+func TestAccountUcSuite(t *testing.T) {
+	suite.Run(t, new(accountUcSuite))
+}
+
+func (a *accountUcSuite) SetupTest() {
+	a.userUc = new(usecase_mock.UserUseCaseMock)
+	a.oneTimeTokenRepo = new(usecase_mock.OneTimeTokenRepositoryMock)
+	a.jwtService = new(service_mock.JWTServiceMock)
+	a.mailer = new(service_mock.MailerMock)
+	// NumericDate keeps whole seconds only
+	a.expiresAt = time.Now().Add(time.Hour).Truncate(time.Second)
+	a.accountUc = usecase.NewAccountUsecase(a.userUc, a.oneTimeTokenRepo, a.jwtService, a.mailer, security.NewPasswordPolicy(8, 64, nil), config.AccountConfig{
+		VerifyEmailURL:            "https://auth.example.com/api/v1/verify-email",
+		ResetPasswordURL:          "https://app.example.com/reset-password?lang=id",
+		VerificationTokenLifetime: 24 * time.Hour,
+		ResetTokenLifetime:        30 * time.Minute,
+	})
+}
+
+func (a *accountUcSuite) claims(purpose string, email string) *modelutils.JwtPayloadClaims {
+	return &modelutils.JwtPayloadClaims{
+		UserId:  1,
+		Purpose: purpose,
+		Email:   email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "jti",
+			ExpiresAt: jwt.NewNumericDate(a.expiresAt),
+		},
+	}
+}
+
+func (a *accountUcSuite) TestSendVerificationEmail_Success() {
+	user := model.User{ID: 1, Username: "alice", Email: "alice@example.com"}
+	a.jwtService.On("CreateTokenWithClaims", mock.MatchedBy(func(claims modelutils.JwtPayloadClaims) bool {
+		return claims.UserId == 1 && claims.Purpose == service.TokenPurposeEmailVerification && claims.Email == "alice@example.com" &&
+			claims.ExpiresAt != nil && time.Until(claims.ExpiresAt.Time) > 23*time.Hour
+	})).Return("verify-token")
+	a.mailer.On("Send", mock.MatchedBy(func(message service.MailMessage) bool {
+		return message.To == "alice@example.com" && strings.Contains(message.Body, "https://auth.example.com/api/v1/verify-email?token=verify-token")
+	})).Return(nil).Once()
+
+	err := a.accountUc.SendVerificationEmail(user)
+	a.NoError(err)
+	a.mailer.AssertExpectations(a.T())
+}
+
+func (a *accountUcSuite) TestSendVerificationEmail_NoEmail() {
+	err := a.accountUc.SendVerificationEmail(model.User{ID: 1, Username: "alice"})
+	a.NoError(err)
+	a.mailer.AssertNotCalled(a.T(), "Send", mock.Anything)
+}
+
+func (a *accountUcSuite) TestVerifyEmail_Success() {
+	a.jwtService.On("VerifyTokenWithPurpose", "token", service.TokenPurposeEmailVerification).Return(a.claims(service.TokenPurposeEmailVerification, "alice@example.com"), nil)
+	a.userUc.On("GetUserByID", 1).Return(model.User{ID: 1, Email: "alice@example.com"}, nil)
+	a.oneTimeTokenRepo.On("MarkUsed", "jti", service.TokenPurposeEmailVerification, a.expiresAt).Return(true, nil)
+	a.oneTimeTokenRepo.On("DeleteExpired").Return(nil)
+	a.userUc.On("SetEmailVerified", 1).Return(nil).Once()
+
+	err := a.accountUc.VerifyEmail("token")
+	a.NoError(err)
+	a.userUc.AssertExpectations(a.T())
+}
+
+func (a *accountUcSuite) TestVerifyEmail_AlreadyVerified() {
+	a.jwtService.On("VerifyTokenWithPurpose", "token", service.TokenPurposeEmailVerification).Return(a.claims(service.TokenPurposeEmailVerification, "alice@example.com"), nil)
+	a.userUc.On("GetUserByID", 1).Return(model.User{ID: 1, Email: "alice@example.com", EmailVerified: true}, nil)
+
+	err := a.accountUc.VerifyEmail("token")
+	a.NoError(err)
+	a.userUc.AssertNotCalled(a.T(), "SetEmailVerified", mock.Anything)
+}
+
+func (a *accountUcSuite) TestVerifyEmail_InvalidToken() {
+	a.jwtService.On("VerifyTokenWithPurpose", "token", service.TokenPurposeEmailVerification).Return(&modelutils.JwtPayloadClaims{}, errors.New("token is expired"))
+
+	err := a.accountUc.VerifyEmail("token")
+	a.ErrorIs(err, usecase.ErrInvalidAccountToken)
+}
+
+func (a *accountUcSuite) TestVerifyEmail_EmailChanged() {
+	a.jwtService.On("VerifyTokenWithPurpose", "token", service.TokenPurposeEmailVerification).Return(a.claims(service.TokenPurposeEmailVerification, "old@example.com"), nil)
+	a.userUc.On("GetUserByID", 1).Return(model.User{ID: 1, Email: "alice@example.com"}, nil)
+
+	err := a.accountUc.VerifyEmail("token")
+	a.ErrorIs(err, usecase.ErrInvalidAccountToken)
+	a.userUc.AssertNotCalled(a.T(), "SetEmailVerified", mock.Anything)
+}
+
+func (a *accountUcSuite) TestVerifyEmail_UserDeleted() {
+	a.jwtService.On("VerifyTokenWithPurpose", "token", service.TokenPurposeEmailVerification).Return(a.claims(service.TokenPurposeEmailVerification, "alice@example.com"), nil)
+	a.userUc.On("GetUserByID", 1).Return(model.User{}, usecase.NewDomainError(usecase.KindNotFound, "user with id 1 not found"))
+
+	err := a.accountUc.VerifyEmail("token")
+	a.ErrorIs(err, usecase.ErrInvalidAccountToken)
+}
+
+func (a *accountUcSuite) TestVerifyEmail_TokenAlreadyUsed() {
+	a.jwtService.On("VerifyTokenWithPurpose", "token", service.TokenPurposeEmailVerification).Return(a.claims(service.TokenPurposeEmailVerification, "alice@example.com"), nil)
+	a.userUc.On("GetUserByID", 1).Return(model.User{ID: 1, Email: "alice@example.com"}, nil)
+	a.oneTimeTokenRepo.On("MarkUsed", "jti", service.TokenPurposeEmailVerification, a.expiresAt).Return(false, nil)
+
+	err := a.accountUc.VerifyEmail("token")
+	a.ErrorIs(err, usecase.ErrInvalidAccountToken)
+	a.userUc.AssertNotCalled(a.T(), "SetEmailVerified", mock.Anything)
+}
+
+func (a *accountUcSuite) TestForgotPassword_Success() {
+	a.userUc.On("GetUserByEmail", "alice@example.com").Return(model.User{ID: 1, Username: "alice", Email: "alice@example.com"}, nil)
+	a.jwtService.On("CreateTokenWithClaims", mock.MatchedBy(func(claims modelutils.JwtPayloadClaims) bool {
+		return claims.UserId == 1 && claims.Purpose == service.TokenPurposePasswordReset && claims.Email == "alice@example.com" &&
+			claims.ExpiresAt != nil && time.Until(claims.ExpiresAt.Time) <= 30*time.Minute
+	})).Return("reset-token")
+	a.mailer.On("Send", mock.MatchedBy(func(message service.MailMessage) bool {
+		return message.To == "alice@example.com" && strings.Contains(message.Body, "https://app.example.com/reset-password?lang=id&token=reset-token")
+	})).Return(nil).Once()
+
+	err := a.accountUc.ForgotPassword(" Alice@Example.com")
+	a.NoError(err)
+	a.mailer.AssertExpectations(a.T())
+}
+
+func (a *accountUcSuite) TestForgotPassword_UnknownEmail() {
+	a.userUc.On("GetUserByEmail", "nobody@example.com").Return(model.User{}, usecase.NewDomainError(usecase.KindNotFound, "user with email nobody@example.com not found"))
+
+	err := a.accountUc.ForgotPassword("nobody@example.com")
+	a.NoError(err)
+	a.mailer.AssertNotCalled(a.T(), "Send", mock.Anything)
+}
+
+func (a *accountUcSuite) TestForgotPassword_InvalidEmail() {
+	err := a.accountUc.ForgotPassword("not an email")
+	a.NoError(err)
+	a.userUc.AssertNotCalled(a.T(), "GetUserByEmail", mock.Anything)
+}
+
+func (a *accountUcSuite) TestForgotPassword_DisabledAccount() {
+	a.userUc.On("GetUserByEmail", "alice@example.com").Return(model.User{ID: 1, Email: "alice@example.com", Disabled: true}, nil)
+
+	err := a.accountUc.ForgotPassword("alice@example.com")
+	a.NoError(err)
+	a.mailer.AssertNotCalled(a.T(), "Send", mock.Anything)
+}
+
+func (a *accountUcSuite) TestForgotPassword_LookupFailed() {
+	a.userUc.On("GetUserByEmail", "alice@example.com").Return(model.User{}, errors.New("connection refused"))
+
+	err := a.accountUc.ForgotPassword("alice@example.com")
+	a.EqualError(err, "connection refused")
+}
+
+func (a *accountUcSuite) TestResetPassword_Success() {
+	a.jwtService.On("VerifyTokenWithPurpose", "token", service.TokenPurposePasswordReset).Return(a.claims(service.TokenPurposePasswordReset, "alice@example.com"), nil)
+	a.userUc.On("GetUserByID", 1).Return(model.User{ID: 1, Email: "alice@example.com"}, nil)
+	a.oneTimeTokenRepo.On("MarkUsed", "jti", service.TokenPurposePasswordReset, a.expiresAt).Return(true, nil)
+	a.oneTimeTokenRepo.On("DeleteExpired").Return(nil)
+	a.userUc.On("ChangePassword", 1, "correct horse battery").Return(nil).Once()
+	// following the link proves the address belongs to the user
+	a.userUc.On("SetEmailVerified", 1).Return(nil).Once()
+
+	err := a.accountUc.ResetPassword("token", "correct horse battery")
+	a.NoError(err)
+	a.userUc.AssertExpectations(a.T())
+}
+
+func (a *accountUcSuite) TestResetPassword_PolicyKeepsToken() {
+	a.jwtService.On("VerifyTokenWithPurpose", "token", service.TokenPurposePasswordReset).Return(a.claims(service.TokenPurposePasswordReset, "alice@example.com"), nil)
+	a.userUc.On("GetUserByID", 1).Return(model.User{ID: 1, Email: "alice@example.com"}, nil)
+
+	err := a.accountUc.ResetPassword("token", "short")
+	a.ErrorIs(err, security.ErrPasswordPolicy)
+	a.oneTimeTokenRepo.AssertNotCalled(a.T(), "MarkUsed", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (a *accountUcSuite) TestResetPassword_TokenAlreadyUsed() {
+	a.jwtService.On("VerifyTokenWithPurpose", "token", service.TokenPurposePasswordReset).Return(a.claims(service.TokenPurposePasswordReset, "alice@example.com"), nil)
+	a.userUc.On("GetUserByID", 1).Return(model.User{ID: 1, Email: "alice@example.com", EmailVerified: true}, nil)
+	a.oneTimeTokenRepo.On("MarkUsed", "jti", service.TokenPurposePasswordReset, a.expiresAt).Return(false, nil)
+
+	err := a.accountUc.ResetPassword("token", "correct horse battery")
+	a.ErrorIs(err, usecase.ErrInvalidAccountToken)
+	a.userUc.AssertNotCalled(a.T(), "ChangePassword", mock.Anything, mock.Anything)
+}
+
+func (a *accountUcSuite) TestResetPassword_AccountDisabled() {
+	a.jwtService.On("VerifyTokenWithPurpose", "token", service.TokenPurposePasswordReset).Return(a.claims(service.TokenPurposePasswordReset, "alice@example.com"), nil)
+	a.userUc.On("GetUserByID", 1).Return(model.User{ID: 1, Email: "alice@example.com", Disabled: true}, nil)
+
+	err := a.accountUc.ResetPassword("token", "correct horse battery")
+	a.ErrorIs(err, usecase.ErrAccountDisabled)
+	a.userUc.AssertNotCalled(a.T(), "ChangePassword", mock.Anything, mock.Anything)
+}
+
+func (a *accountUcSuite) TestResetPassword_VerificationTokenRefused() {
+	a.jwtService.On("VerifyTokenWithPurpose", "token", service.TokenPurposePasswordReset).Return(&modelutils.JwtPayloadClaims{}, errors.New(`unexpected token purpose "email_verification"`))
+
+	err := a.accountUc.ResetPassword("token", "correct horse battery")
+	a.ErrorIs(err, usecase.ErrInvalidAccountToken)
+}
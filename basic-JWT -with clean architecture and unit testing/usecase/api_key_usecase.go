@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"basic-JWT/model"
+	"basic-JWT/repository"
+	"basic-JWT/utils/security"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	modelutils "basic-JWT/utils/model_utils"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// APIKeyTag starts every API key, so leaked keys are easy to recognise by
+// secret scanners. The rest is <prefix>.<secret>; the base64url alphabet has
+// no dot, so the key always splits unambiguously.
+const APIKeyTag = "bjk_"
+
+// lastUsedResolution limits the writes of last_used_at to one per key per
+// interval, busy keys would otherwise write on every request.
+const lastUsedResolution = time.Minute
+
+var (
+	ErrInvalidAPIKey  = NewDomainError(KindUnauthorized, "invalid api key")
+	ErrAPIKeyNotFound = NewDomainError(KindNotFound, "api key not found")
+)
+
+type APIKeyUsecase interface {
+	Create(request model.CreateAPIKeyRequest) (model.APIKeyCredentials, error)
+	GetAll() ([]model.APIKey, error)
+	Delete(id int) error
+	// Authenticate resolves a key to the claims of its owner. Scope holds the
+	// scopes of the key, space separated.
+	Authenticate(key string) (*modelutils.JwtPayloadClaims, error)
+}
+
+type apiKeyUsecase struct {
+	apiKeyRepository repository.APIKeyRepository
+	userUsecase      UserUsecase
+	rbacUsecase      RbacUsecase
+}
+
+// Create issues a key for the user. Every scope must be a permission the role
+// of the owner holds, a key can never do more than its owner.
+func (au *apiKeyUsecase) Create(request model.CreateAPIKeyRequest) (model.APIKeyCredentials, error) {
+	if len(request.Scopes) == 0 {
+		return model.APIKeyCredentials{}, NewDomainError(KindValidation, "at least one scope is required")
+	}
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		return model.APIKeyCredentials{}, NewDomainError(KindValidation, "expiresAt must be in the future")
+	}
+
+	owner, err := au.userUsecase.GetUserByUsername(request.Username)
+	if err != nil {
+		return model.APIKeyCredentials{}, err
+	}
+	if owner.Disabled {
+		return model.APIKeyCredentials{}, ErrAccountDisabled
+	}
+
+	for _, scope := range request.Scopes {
+		allowed, err := au.rbacUsecase.HasPermission(owner.Role, scope)
+		if err != nil {
+			return model.APIKeyCredentials{}, err
+		}
+		if !allowed {
+			return model.APIKeyCredentials{}, NewDomainError(KindValidation, fmt.Sprintf("role '%s' does not have the permission '%s'", owner.Role, scope))
+		}
+	}
+
+	prefix, err := security.GenerateRandomToken(8)
+	if err != nil {
+		return model.APIKeyCredentials{}, err
+	}
+	secret, err := security.GenerateRandomToken(32)
+	if err != nil {
+		return model.APIKeyCredentials{}, err
+	}
+
+	created, err := au.apiKeyRepository.Create(&model.APIKey{
+		Prefix:     prefix,
+		SecretHash: security.HashToken(secret),
+		UserID:     owner.ID,
+		Name:       request.Name,
+		Scopes:     request.Scopes,
+		ExpiresAt:  request.ExpiresAt,
+	})
+	if err != nil {
+		return model.APIKeyCredentials{}, err
+	}
+
+	return model.APIKeyCredentials{APIKey: *created, Key: APIKeyTag + prefix + "." + secret}, nil
+}
+
+func (au *apiKeyUsecase) GetAll() ([]model.APIKey, error) {
+	return au.apiKeyRepository.GetAll()
+}
+
+func (au *apiKeyUsecase) Delete(id int) error {
+	err := au.apiKeyRepository.Delete(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAPIKeyNotFound
+	}
+	return err
+}
+
+// Authenticate rejects unknown, expired and malformed keys, and keys whose
+// owner has been disabled or deleted, all with ErrInvalidAPIKey.
+func (au *apiKeyUsecase) Authenticate(key string) (*modelutils.JwtPayloadClaims, error) {
+	rest, ok := strings.CutPrefix(key, APIKeyTag)
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+	prefix, secret, ok := strings.Cut(rest, ".")
+	if !ok || prefix == "" || secret == "" {
+		return nil, ErrInvalidAPIKey
+	}
+
+	apiKey, err := au.apiKeyRepository.GetByPrefix(prefix)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(security.HashToken(secret)), []byte(apiKey.SecretHash)) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if apiKey.ExpiresAt != nil && !now.Before(*apiKey.ExpiresAt) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	owner, err := au.userUsecase.GetUserByID(apiKey.UserID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	if owner.Disabled {
+		return nil, ErrInvalidAPIKey
+	}
+
+	// last_used_at is informational, a failed update must not fail the request
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= lastUsedResolution {
+		_ = au.apiKeyRepository.UpdateLastUsed(apiKey.ID, now)
+	}
+
+	return &modelutils.JwtPayloadClaims{
+		UserId: owner.ID,
+		Role:   owner.Role,
+		Scope:  strings.Join(apiKey.Scopes, " "),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: strconv.Itoa(owner.ID),
+		},
+	}, nil
+}
+
+func NewAPIKeyUsecase(apiKeyRepository repository.APIKeyRepository, userUsecase UserUsecase, rbacUsecase RbacUsecase) APIKeyUsecase {
+	return &apiKeyUsecase{
+		apiKeyRepository: apiKeyRepository,
+		userUsecase:      userUsecase,
+		rbacUsecase:      rbacUsecase,
+	}
+}
@@ -0,0 +1,163 @@
+package usecase_test
+
+import (
+	"basic-JWT/mock/usecase_mock"
+	"basic-JWT/model"
+	"basic-JWT/usecase"
+	"basic-JWT/utils/security"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type apiKeyUcSuite struct {
+	suite.Suite
+	apiKeyRepo  *usecase_mock.APIKeyRepositoryMock
+	userUsecase *usecase_mock.UserUseCaseMock
+	rbacUsecase *usecase_mock.RbacUsecaseMock
+	apiKeyUc    usecase.APIKeyUsecase
+	owner       model.User
+	stored      model.APIKey
+}
+
+func TestAPIKeyUcSuite(t *testing.T) {
+	suite.Run(t, new(apiKeyUcSuite))
+}
+
+func (a *apiKeyUcSuite) SetupTest() {
+	a.apiKeyRepo = new(usecase_mock.APIKeyRepositoryMock)
+	a.userUsecase = new(usecase_mock.UserUseCaseMock)
+	a.rbacUsecase = new(usecase_mock.RbacUsecaseMock)
+	a.apiKeyUc = usecase.NewAPIKeyUsecase(a.apiKeyRepo, a.userUsecase, a.rbacUsecase)
+
+	a.owner = model.User{ID: 7, Username: "batch", Role: "service"}
+	a.stored = model.APIKey{ID: 1, Prefix: "prefix", SecretHash: security.HashToken("secret"), UserID: 7, Scopes: []string{"users:read", "users:disable"}}
+}
+
+func (a *apiKeyUcSuite) TestCreate_Success() {
+	a.userUsecase.On("GetUserByUsername", "batch").Return(a.owner, nil)
+	a.rbacUsecase.On("HasPermission", "service", "users:read").Return(true, nil)
+	var stored *model.APIKey
+	a.apiKeyRepo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*model.APIKey)
+	}).Return(&model.APIKey{ID: 1}, nil).Once()
+
+	credentials, err := a.apiKeyUc.Create(model.CreateAPIKeyRequest{Name: "nightly export", Username: "batch", Scopes: []string{"users:read"}})
+	a.Require().NoError(err)
+
+	// the key is tag, prefix and secret, and only the hash of the secret is stored
+	a.True(strings.HasPrefix(credentials.Key, usecase.APIKeyTag+stored.Prefix+"."))
+	secret := strings.TrimPrefix(credentials.Key, usecase.APIKeyTag+stored.Prefix+".")
+	a.Equal(security.HashToken(secret), stored.SecretHash)
+	a.Equal(7, stored.UserID)
+	a.Equal("nightly export", stored.Name)
+	a.Equal(1, credentials.ID)
+}
+
+func (a *apiKeyUcSuite) TestCreate_ScopeNotHeldByOwner() {
+	a.userUsecase.On("GetUserByUsername", "batch").Return(a.owner, nil)
+	a.rbacUsecase.On("HasPermission", "service", "users:read").Return(true, nil)
+	a.rbacUsecase.On("HasPermission", "service", "rbac:manage").Return(false, nil)
+
+	_, err := a.apiKeyUc.Create(model.CreateAPIKeyRequest{Name: "sync", Username: "batch", Scopes: []string{"users:read", "rbac:manage"}})
+	a.ErrorIs(err, usecase.ErrValidation)
+	a.EqualError(err, "role 'service' does not have the permission 'rbac:manage'")
+	a.apiKeyRepo.AssertNotCalled(a.T(), "Create", mock.Anything)
+}
+
+func (a *apiKeyUcSuite) TestCreate_ExpiryInThePast() {
+	expiresAt := time.Now().Add(-time.Minute)
+
+	_, err := a.apiKeyUc.Create(model.CreateAPIKeyRequest{Name: "sync", Username: "batch", Scopes: []string{"users:read"}, ExpiresAt: &expiresAt})
+	a.ErrorIs(err, usecase.ErrValidation)
+}
+
+func (a *apiKeyUcSuite) TestCreate_UnknownOwner() {
+	a.userUsecase.On("GetUserByUsername", "ghost").Return(model.User{}, usecase.NewDomainError(usecase.KindNotFound, "user with username ghost not found"))
+
+	_, err := a.apiKeyUc.Create(model.CreateAPIKeyRequest{Name: "sync", Username: "ghost", Scopes: []string{"users:read"}})
+	a.ErrorIs(err, usecase.ErrNotFound)
+}
+
+func (a *apiKeyUcSuite) TestDelete_NotFound() {
+	a.apiKeyRepo.On("Delete", 9).Return(sql.ErrNoRows)
+
+	a.ErrorIs(a.apiKeyUc.Delete(9), usecase.ErrAPIKeyNotFound)
+}
+
+func (a *apiKeyUcSuite) TestAuthenticate_Success() {
+	a.apiKeyRepo.On("GetByPrefix", "prefix").Return(a.stored, nil)
+	a.userUsecase.On("GetUserByID", 7).Return(a.owner, nil)
+	a.apiKeyRepo.On("UpdateLastUsed", 1, mock.Anything).Return(nil)
+
+	claims, err := a.apiKeyUc.Authenticate(usecase.APIKeyTag + "prefix.secret")
+	a.Require().NoError(err)
+	a.Equal(7, claims.UserId)
+	a.Equal("service", claims.Role)
+	a.Equal("users:read users:disable", claims.Scope)
+	a.Equal("7", claims.Subject)
+	a.apiKeyRepo.AssertCalled(a.T(), "UpdateLastUsed", 1, mock.Anything)
+}
+
+func (a *apiKeyUcSuite) TestAuthenticate_RecentlyUsedSkipsUpdate() {
+	lastUsedAt := time.Now().Add(-10 * time.Second)
+	a.stored.LastUsedAt = &lastUsedAt
+	a.apiKeyRepo.On("GetByPrefix", "prefix").Return(a.stored, nil)
+	a.userUsecase.On("GetUserByID", 7).Return(a.owner, nil)
+
+	_, err := a.apiKeyUc.Authenticate(usecase.APIKeyTag + "prefix.secret")
+	a.NoError(err)
+	a.apiKeyRepo.AssertNotCalled(a.T(), "UpdateLastUsed", mock.Anything, mock.Anything)
+}
+
+func (a *apiKeyUcSuite) TestAuthenticate_WrongSecret() {
+	a.apiKeyRepo.On("GetByPrefix", "prefix").Return(a.stored, nil)
+
+	_, err := a.apiKeyUc.Authenticate(usecase.APIKeyTag + "prefix.guess")
+	a.ErrorIs(err, usecase.ErrInvalidAPIKey)
+}
+
+func (a *apiKeyUcSuite) TestAuthenticate_Malformed() {
+	for _, key := range []string{"", "prefix.secret", usecase.APIKeyTag + "prefix", usecase.APIKeyTag + ".secret", usecase.APIKeyTag + "prefix."} {
+		_, err := a.apiKeyUc.Authenticate(key)
+		a.ErrorIs(err, usecase.ErrInvalidAPIKey, key)
+	}
+	a.apiKeyRepo.AssertNotCalled(a.T(), "GetByPrefix", mock.Anything)
+}
+
+func (a *apiKeyUcSuite) TestAuthenticate_UnknownPrefix() {
+	a.apiKeyRepo.On("GetByPrefix", "ghost").Return(model.APIKey{}, sql.ErrNoRows)
+
+	_, err := a.apiKeyUc.Authenticate(usecase.APIKeyTag + "ghost.secret")
+	a.ErrorIs(err, usecase.ErrInvalidAPIKey)
+}
+
+func (a *apiKeyUcSuite) TestAuthenticate_Expired() {
+	expiresAt := time.Now().Add(-time.Second)
+	a.stored.ExpiresAt = &expiresAt
+	a.apiKeyRepo.On("GetByPrefix", "prefix").Return(a.stored, nil)
+
+	_, err := a.apiKeyUc.Authenticate(usecase.APIKeyTag + "prefix.secret")
+	a.ErrorIs(err, usecase.ErrInvalidAPIKey)
+}
+
+func (a *apiKeyUcSuite) TestAuthenticate_OwnerDisabled() {
+	a.owner.Disabled = true
+	a.apiKeyRepo.On("GetByPrefix", "prefix").Return(a.stored, nil)
+	a.userUsecase.On("GetUserByID", 7).Return(a.owner, nil)
+
+	_, err := a.apiKeyUc.Authenticate(usecase.APIKeyTag + "prefix.secret")
+	a.ErrorIs(err, usecase.ErrInvalidAPIKey)
+}
+
+func (a *apiKeyUcSuite) TestAuthenticate_OwnerDeleted() {
+	a.apiKeyRepo.On("GetByPrefix", "prefix").Return(a.stored, nil)
+	a.userUsecase.On("GetUserByID", 7).Return(model.User{}, usecase.NewDomainError(usecase.KindNotFound, "user with id 7 not found"))
+
+	_, err := a.apiKeyUc.Authenticate(usecase.APIKeyTag + "prefix.secret")
+	a.ErrorIs(err, usecase.ErrInvalidAPIKey)
+}
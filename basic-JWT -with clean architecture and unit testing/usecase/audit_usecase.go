@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"basic-JWT/model"
+	"basic-JWT/repository"
+	"errors"
+	"log"
+	"time"
+)
+
+const (
+	defaultAuditPageLimit = 50
+	maxAuditPageLimit     = 200
+)
+
+var ErrAuditSearchUnavailable = NewDomainError(KindUnavailable, "audit events are written to a file and cannot be searched")
+
+// AuditUsecase writes the security audit log. Recording never fails the
+// audited action: the event is written as soon as the outcome is known, and a
+// sink that cannot be written is reported in the application log instead.
+type AuditUsecase interface {
+	Record(event model.AuditEvent, err error)
+	Search(filter model.AuditEventFilter) (model.AuditEventPage, error)
+}
+
+type auditUsecase struct {
+	sink           repository.AuditSink
+	userRepository repository.UserRepository
+}
+
+// Record completes the event with the outcome of err and the current time,
+// and appends it to the sink. The reason of a failure is the message of a
+// DomainError, other errors are internal and not recorded in detail.
+func (au *auditUsecase) Record(event model.AuditEvent, err error) {
+	event.Outcome = model.AuditOutcomeSuccess
+	if err != nil {
+		event.Outcome = model.AuditOutcomeFailure
+		event.Reason = auditReason(err)
+	}
+	event.CreatedAt = time.Now()
+
+	if event.Actor == "" && event.ActorID != 0 {
+		if user, err := au.userRepository.GetUserByID(event.ActorID); err == nil {
+			event.Actor = user.Username
+		}
+	}
+
+	if err := au.sink.Append(&event); err != nil {
+		log.Printf("failed to write audit event %s on %q by %q: %v", event.Action, event.Target, event.Actor, err)
+	}
+}
+
+// Search needs a sink that can be queried, only the Postgres one can.
+func (au *auditUsecase) Search(filter model.AuditEventFilter) (model.AuditEventPage, error) {
+	repo, ok := au.sink.(repository.AuditRepository)
+	if !ok {
+		return model.AuditEventPage{}, ErrAuditSearchUnavailable
+	}
+
+	if filter.Outcome != "" && filter.Outcome != model.AuditOutcomeSuccess && filter.Outcome != model.AuditOutcomeFailure {
+		return model.AuditEventPage{}, NewDomainError(KindValidation, "outcome must be success or failure")
+	}
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 {
+		filter.Limit = defaultAuditPageLimit
+	}
+	if filter.Limit > maxAuditPageLimit {
+		filter.Limit = maxAuditPageLimit
+	}
+
+	events, total, err := repo.Find(filter)
+	if err != nil {
+		return model.AuditEventPage{}, err
+	}
+	return model.AuditEventPage{Events: events, Page: filter.Page, Limit: filter.Limit, Total: total}, nil
+}
+
+func auditReason(err error) string {
+	var domainError *DomainError
+	if errors.As(err, &domainError) {
+		return domainError.Error()
+	}
+	var tooManyAttempts *TooManyAttemptsError
+	if errors.As(err, &tooManyAttempts) {
+		return "too many login attempts"
+	}
+	return "internal error"
+}
+
+func NewAuditUsecase(sink repository.AuditSink, userRepository repository.UserRepository) AuditUsecase {
+	return &auditUsecase{
+		sink:           sink,
+		userRepository: userRepository,
+	}
+}
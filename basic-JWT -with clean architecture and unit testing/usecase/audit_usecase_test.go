@@ -0,0 +1,123 @@
+package usecase_test
+
+import (
+	"basic-JWT/mock/usecase_mock"
+	"basic-JWT/model"
+	"basic-JWT/repository"
+	"basic-JWT/usecase"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type auditUcSuite struct {
+	suite.Suite
+	auditRepo *usecase_mock.AuditRepositoryMock
+	userRepo  *usecase_mock.UserRepositoryMock
+	auditUc   usecase.AuditUsecase
+}
+
+func TestAuditUcSuite(t *testing.T) {
+	suite.Run(t, new(auditUcSuite))
+}
+
+func (a *auditUcSuite) SetupTest() {
+	a.auditRepo = new(usecase_mock.AuditRepositoryMock)
+	a.userRepo = new(usecase_mock.UserRepositoryMock)
+	a.auditUc = usecase.NewAuditUsecase(a.auditRepo, a.userRepo)
+}
+
+// appended captures the event handed to the sink.
+func (a *auditUcSuite) appended(err error) *model.AuditEvent {
+	event := &model.AuditEvent{}
+	a.auditRepo.On("Append", mock.Anything).Run(func(args mock.Arguments) {
+		*event = *args.Get(0).(*model.AuditEvent)
+	}).Return(err).Once()
+	return event
+}
+
+func (a *auditUcSuite) TestRecord_Success() {
+	event := a.appended(nil)
+
+	a.auditUc.Record(model.AuditEvent{Actor: "alice", Action: model.AuditActionLogin, Target: "alice", IP: "10.0.0.1"}, nil)
+	a.Equal(model.AuditOutcomeSuccess, event.Outcome)
+	a.Empty(event.Reason)
+	a.Equal("alice", event.Actor)
+	a.WithinDuration(time.Now(), event.CreatedAt, time.Second)
+	a.userRepo.AssertNotCalled(a.T(), "GetUserByID", mock.Anything)
+}
+
+func (a *auditUcSuite) TestRecord_DomainErrorReason() {
+	event := a.appended(nil)
+
+	a.auditUc.Record(model.AuditEvent{Action: model.AuditActionLogin, Target: "alice"}, usecase.ErrInvalidCredentials)
+	a.Equal(model.AuditOutcomeFailure, event.Outcome)
+	a.Equal(usecase.ErrInvalidCredentials.Error(), event.Reason)
+}
+
+func (a *auditUcSuite) TestRecord_TooManyAttemptsReason() {
+	event := a.appended(nil)
+
+	a.auditUc.Record(model.AuditEvent{Action: model.AuditActionLogin, Target: "alice"}, &usecase.TooManyAttemptsError{RetryAfter: time.Minute})
+	a.Equal("too many login attempts", event.Reason)
+}
+
+func (a *auditUcSuite) TestRecord_InternalErrorIsNotRecordedInDetail() {
+	event := a.appended(nil)
+
+	a.auditUc.Record(model.AuditEvent{Action: model.AuditActionUserDelete, Target: "bob"}, errors.New("pq: connection refused"))
+	a.Equal(model.AuditOutcomeFailure, event.Outcome)
+	a.Equal("internal error", event.Reason)
+}
+
+func (a *auditUcSuite) TestRecord_ResolvesActor() {
+	a.userRepo.On("GetUserByID", 1).Return(model.User{ID: 1, Username: "admin"}, nil)
+	event := a.appended(nil)
+
+	a.auditUc.Record(model.AuditEvent{ActorID: 1, Action: model.AuditActionUserDelete, Target: "bob"}, nil)
+	a.Equal("admin", event.Actor)
+	a.Equal(1, event.ActorID)
+}
+
+func (a *auditUcSuite) TestRecord_SinkErrorIsSwallowed() {
+	a.appended(errors.New("disk full"))
+
+	a.NotPanics(func() {
+		a.auditUc.Record(model.AuditEvent{Action: model.AuditActionLogin, Target: "alice"}, nil)
+	})
+	a.auditRepo.AssertExpectations(a.T())
+}
+
+func (a *auditUcSuite) TestSearch_Defaults() {
+	events := []model.AuditEvent{{ID: 2, Action: model.AuditActionLogin}}
+	a.auditRepo.On("Find", model.AuditEventFilter{Action: model.AuditActionLogin, Page: 1, Limit: 50}).Return(events, 51, nil)
+
+	page, err := a.auditUc.Search(model.AuditEventFilter{Action: model.AuditActionLogin})
+	a.NoError(err)
+	a.Equal(model.AuditEventPage{Events: events, Page: 1, Limit: 50, Total: 51}, page)
+}
+
+func (a *auditUcSuite) TestSearch_LimitIsCapped() {
+	a.auditRepo.On("Find", model.AuditEventFilter{Page: 3, Limit: 200}).Return([]model.AuditEvent{}, 0, nil)
+
+	page, err := a.auditUc.Search(model.AuditEventFilter{Page: 3, Limit: 10000})
+	a.NoError(err)
+	a.Equal(200, page.Limit)
+}
+
+func (a *auditUcSuite) TestSearch_InvalidOutcome() {
+	_, err := a.auditUc.Search(model.AuditEventFilter{Outcome: "maybe"})
+	a.ErrorIs(err, usecase.ErrValidation)
+	a.auditRepo.AssertNotCalled(a.T(), "Find", mock.Anything)
+}
+
+func (a *auditUcSuite) TestSearch_FileSinkCannotBeSearched() {
+	auditUc := usecase.NewAuditUsecase(repository.NewFileAuditSink(filepath.Join(a.T().TempDir(), "audit.log")), a.userRepo)
+
+	_, err := auditUc.Search(model.AuditEventFilter{})
+	a.ErrorIs(err, usecase.ErrAuditSearchUnavailable)
+}
@@ -2,68 +2,293 @@ package usecase
 
 import (
 	"basic-JWT/model"
+	"basic-JWT/utils/security"
 	"basic-JWT/utils/service"
+	"errors"
+	"time"
 
 	"fmt"
 
-	"golang.org/x/crypto/bcrypt"
+	modelutils "basic-JWT/utils/model_utils"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mfaTokenLifetime bounds the time between the password step and the TOTP step of a login.
+const mfaTokenLifetime = 5 * time.Minute
+
+var (
+	ErrInvalidMfaToken    = NewDomainError(KindUnauthorized, "invalid mfa token")
+	ErrInvalidCredentials = NewDomainError(KindUnauthorized, "invalid username or password")
+	ErrAccountDisabled    = NewDomainError(KindForbidden, "account is disabled")
+	ErrEmailNotVerified   = NewDomainError(KindForbidden, "email address is not verified")
 )
 
 type AuthenticationUsecase interface {
-	Register(username string, password string) (model.User, error)
-	Login(username string, password string) (string, error)
+	Register(username string, password string, email string, client model.ClientInfo) (model.User, error)
+	Login(username string, password string, client model.ClientInfo) (model.LoginResult, error)
+	VerifyMfa(mfaToken string, code string, client model.ClientInfo) (model.TokenPair, error)
+	Refresh(refreshToken string, client model.ClientInfo) (model.TokenPair, error)
+	Logout(refreshToken string) error
 }
 
 type authenticationUsecase struct {
-	userUsecase UserUsecase
-	jwtService  service.JWTservice
+	userUsecase         UserUsecase
+	jwtService          service.JWTservice
+	refreshTokenUsecase RefreshTokenUsecase
+	mfaUsecase          MfaUsecase
+	loginThrottle       LoginThrottleUsecase
+	passwordHasher      service.PasswordHasher
+	passwordPolicy      *security.PasswordPolicy
+	accountUsecase      AccountUsecase
+	sessionUsecase      SessionUsecase
+	auditUsecase        AuditUsecase
+	// dummyHash is verified against when the username does not exist, so
+	// the response time does not reveal which usernames are registered.
+	dummyHash string
+}
+
+// Login checks the password. Users with MFA enabled get an mfa token instead
+// of the token pair and finish the login with VerifyMfa.
+//
+// Unknown usernames and wrong passwords both return ErrInvalidCredentials,
+// take as long as each other, and count as a failed attempt for the username
+// and the client IP.
+func (au *authenticationUsecase) Login(username string, password string, client model.ClientInfo) (result model.LoginResult, err error) {
+	var user model.User
+	defer func() {
+		// a login waiting for the TOTP step is recorded by VerifyMfa
+		if err != nil || !result.MfaRequired {
+			au.auditUsecase.Record(model.AuditEvent{ActorID: user.ID, Actor: username, Action: model.AuditActionLogin, Target: username, IP: client.IP}, err)
+		}
+	}()
+
+	if err := au.loginThrottle.Check(username, client.IP); err != nil {
+		return model.LoginResult{}, err
+	}
+
+	user, err = au.userUsecase.GetUserByUsername(username)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return model.LoginResult{}, err
+	}
+
+	hash := user.Password
+	if err != nil {
+		hash = au.dummyHash
+	}
+	if !au.passwordHasher.Verify(password, hash) || err != nil {
+		if err := au.loginThrottle.RegisterFailure(username, client.IP); err != nil {
+			return model.LoginResult{}, err
+		}
+		return model.LoginResult{}, ErrInvalidCredentials
+	}
+
+	if user.Disabled {
+		return model.LoginResult{}, ErrAccountDisabled
+	}
+	// accounts without an email, legacy rows and users created by an admin
+	// without one, have nothing to verify
+	if user.Email != "" && !user.EmailVerified {
+		return model.LoginResult{}, ErrEmailNotVerified
+	}
+
+	au.rehashPassword(user, password)
+
+	mfaEnabled, err := au.mfaUsecase.IsEnabled(user.ID)
+	if err != nil {
+		return model.LoginResult{}, err
+	}
+
+	// the counter is only cleared once the login is complete, otherwise
+	// repeating the password step would reset the limit on TOTP guesses
+	if mfaEnabled {
+		return model.LoginResult{
+			MfaRequired: true,
+			MfaToken: au.jwtService.CreateTokenWithClaims(modelutils.JwtPayloadClaims{
+				UserId:  user.ID,
+				Role:    user.Role,
+				Purpose: service.TokenPurposeMfa,
+				RegisteredClaims: jwt.RegisteredClaims{
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(mfaTokenLifetime)),
+				},
+			}),
+		}, nil
+	}
+
+	if err := au.loginThrottle.RegisterSuccess(user.Username); err != nil {
+		return model.LoginResult{}, err
+	}
+
+	tokens, err := au.issueTokens(user, false, client)
+	if err != nil {
+		return model.LoginResult{}, err
+	}
+
+	return model.LoginResult{TokenPair: tokens}, nil
+
+}
+
+// rehashPassword upgrades a hash made with an outdated algorithm or outdated
+// parameters while the plain password is at hand. Failing to do so does not
+// fail the login, the next login simply tries again.
+func (au *authenticationUsecase) rehashPassword(user model.User, password string) {
+	if !au.passwordHasher.NeedsRehash(user.Password) {
+		return
+	}
+	hash, err := au.passwordHasher.Hash(password)
+	if err != nil {
+		return
+	}
+	_ = au.userUsecase.UpdatePasswordHash(user.ID, hash)
+}
+
+// VerifyMfa exchanges the mfa token from Login and a TOTP or recovery code for the token pair.
+// Wrong codes count against the same username and IP limits as wrong passwords.
+func (au *authenticationUsecase) VerifyMfa(mfaToken string, code string, client model.ClientInfo) (tokens model.TokenPair, err error) {
+	var user model.User
+	defer func() {
+		au.auditUsecase.Record(model.AuditEvent{ActorID: user.ID, Actor: user.Username, Action: model.AuditActionLoginMfa, Target: user.Username, IP: client.IP}, err)
+	}()
+
+	claims, err := au.jwtService.VerifyTokenWithPurpose(mfaToken, service.TokenPurposeMfa)
+	if err != nil {
+		return model.TokenPair{}, ErrInvalidMfaToken
+	}
+
+	user, err = au.userUsecase.GetUserByID(claims.UserId)
+	if err != nil {
+		return model.TokenPair{}, err
+	}
+	if user.Disabled {
+		return model.TokenPair{}, ErrAccountDisabled
+	}
+
+	if err := au.loginThrottle.Check(user.Username, client.IP); err != nil {
+		return model.TokenPair{}, err
+	}
+
+	if err := au.mfaUsecase.Verify(user.ID, code); err != nil {
+		if errors.Is(err, ErrInvalidMfaCode) {
+			if err := au.loginThrottle.RegisterFailure(user.Username, client.IP); err != nil {
+				return model.TokenPair{}, err
+			}
+		}
+		return model.TokenPair{}, err
+	}
+
+	if err := au.loginThrottle.RegisterSuccess(user.Username); err != nil {
+		return model.TokenPair{}, err
+	}
+
+	return au.issueTokens(user, true, client)
 }
 
-func (au *authenticationUsecase) Login(username string, password string) (string, error) {
-	user, err := au.userUsecase.GetUserByUsername(username)
+// issueTokens starts a new session for a completed login. The session id is
+// the family id of the refresh token and the sid claim of the access token.
+func (au *authenticationUsecase) issueTokens(user model.User, mfa bool, client model.ClientInfo) (model.TokenPair, error) {
+	sessionID, err := au.sessionUsecase.Record("", user.ID, client)
 	if err != nil {
-		return "Failed to get user by username", err
+		return model.TokenPair{}, err
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	refreshToken, err := au.refreshTokenUsecase.Issue(user.ID, sessionID, mfa)
 	if err != nil {
-		return "Failed to compare password", err
+		return model.TokenPair{}, err
 	}
 
-	token := au.jwtService.CreateToken(user)
+	return model.TokenPair{
+		AccessToken:  au.accessToken(user, sessionID, mfa),
+		RefreshToken: refreshToken,
+	}, nil
+}
 
-	return token, nil
+func (au *authenticationUsecase) accessToken(user model.User, sessionID string, mfa bool) string {
+	return au.jwtService.CreateTokenWithClaims(modelutils.JwtPayloadClaims{
+		UserId: user.ID,
+		Role:   user.Role,
+		Mfa:    mfa,
+		Sid:    sessionID,
+	})
+}
+
+// Refresh rotates the refresh token and records the client as the last seen
+// client of the session.
+func (au *authenticationUsecase) Refresh(refreshToken string, client model.ClientInfo) (model.TokenPair, error) {
+	previous, next, err := au.refreshTokenUsecase.Rotate(refreshToken, "")
+	if err != nil {
+		return model.TokenPair{}, err
+	}
 
+	// role may have changed since the family was started, so reload the user
+	user, err := au.userUsecase.GetUserByID(previous.UserID)
+	if err != nil {
+		return model.TokenPair{}, err
+	}
+	if user.Disabled {
+		return model.TokenPair{}, ErrAccountDisabled
+	}
+
+	if _, err := au.sessionUsecase.Record(previous.FamilyID, user.ID, client); err != nil {
+		return model.TokenPair{}, err
+	}
+
+	return model.TokenPair{
+		AccessToken:  au.accessToken(user, previous.FamilyID, previous.Mfa),
+		RefreshToken: next,
+	}, nil
+}
+
+func (au *authenticationUsecase) Logout(refreshToken string) error {
+	return au.refreshTokenUsecase.Revoke(refreshToken)
 }
 
-func (au *authenticationUsecase) Register(username, password string) (model.User, error) {
+// Register creates an account that can only log in once the email address is
+// verified, and mails the verification link.
+func (au *authenticationUsecase) Register(username, password, email string, client model.ClientInfo) (user model.User, err error) {
+	defer func() {
+		au.auditUsecase.Record(model.AuditEvent{ActorID: user.ID, Actor: username, Action: model.AuditActionRegister, Target: username, IP: client.IP}, err)
+	}()
+
 	// Check if username is taken
-	user, err := au.userUsecase.GetUserByUsername(username)
+	user, err = au.userUsecase.GetUserByUsername(username)
 	if err == nil {
-		return model.User{}, fmt.Errorf("username '%s' is already taken", username)
+		return model.User{}, NewDomainError(KindConflict, fmt.Sprintf("username '%s' is already taken", username))
 	}
 
 	// Check if username is empty
 	if username == "" {
-		return model.User{}, fmt.Errorf("username cannot be empty")
+		return model.User{}, NewDomainError(KindValidation, "username cannot be empty")
 	}
 
 	// Check if password is empty
 	if password == "" {
-		return model.User{}, fmt.Errorf("password cannot be empty")
+		return model.User{}, NewDomainError(KindValidation, "password cannot be empty")
+	}
+
+	if err := au.passwordPolicy.Validate(password); err != nil {
+		return model.User{}, wrapError(KindValidation, err)
+	}
+
+	email, err = NormalizeEmail(email)
+	if err != nil {
+		return model.User{}, err
+	}
+	if _, err := au.userUsecase.GetUserByEmail(email); err == nil {
+		return model.User{}, NewDomainError(KindConflict, fmt.Sprintf("email '%s' is already taken", email))
 	}
 
 	// Hash the password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword, err := au.passwordHasher.Hash(password)
 	if err != nil {
 		return model.User{}, fmt.Errorf("Failed to hashing password")
 	}
 
-	password = string(hashedPassword)
+	password = hashedPassword
 
 	// Create a new user
 	user = model.User{
 		Username: username,
+		Email:    email,
 		Password: password,
 		Role:     "user",
 	}
@@ -74,12 +299,29 @@ func (au *authenticationUsecase) Register(username, password string) (model.User
 		return model.User{}, err
 	}
 
+	// the account exists at this point, so a mail that could not be sent does
+	// not fail the registration. /forgot-password also verifies the address.
+	_ = au.accountUsecase.SendVerificationEmail(user)
+
 	return user, nil
 }
 
-func NewAuthenticationUsecase(userUsecase UserUsecase, jwtService service.JWTservice) AuthenticationUsecase {
+func NewAuthenticationUsecase(userUsecase UserUsecase, jwtService service.JWTservice, refreshTokenUsecase RefreshTokenUsecase, mfaUsecase MfaUsecase, loginThrottle LoginThrottleUsecase, passwordHasher service.PasswordHasher, passwordPolicy *security.PasswordPolicy, accountUsecase AccountUsecase, sessionUsecase SessionUsecase, auditUsecase AuditUsecase) AuthenticationUsecase {
+	// Hash only fails when the system random source does, Verify then
+	// rejects the empty hash right away
+	dummyHash, _ := passwordHasher.Hash("dummy password for unknown users")
+
 	return &authenticationUsecase{
-		userUsecase: userUsecase,
-		jwtService:  jwtService,
+		userUsecase:         userUsecase,
+		jwtService:          jwtService,
+		refreshTokenUsecase: refreshTokenUsecase,
+		mfaUsecase:          mfaUsecase,
+		loginThrottle:       loginThrottle,
+		passwordHasher:      passwordHasher,
+		passwordPolicy:      passwordPolicy,
+		accountUsecase:      accountUsecase,
+		sessionUsecase:      sessionUsecase,
+		auditUsecase:        auditUsecase,
+		dummyHash:           dummyHash,
 	}
 }
@@ -5,18 +5,30 @@ import (
 	"basic-JWT/mock/usecase_mock"
 	"basic-JWT/model"
 	"basic-JWT/usecase"
+	modelutils "basic-JWT/utils/model_utils"
+	"basic-JWT/utils/security"
+	"basic-JWT/utils/service"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type authUCSuite struct {
 	suite.Suite
-	authUC      usecase.AuthenticationUsecase
-	jwtService  *service_mock.JWTServiceMock
-	UserUsecase *usecase_mock.UserUseCaseMock
+	authUC         usecase.AuthenticationUsecase
+	jwtService     *service_mock.JWTServiceMock
+	UserUsecase    *usecase_mock.UserUseCaseMock
+	refreshTokenUC *usecase_mock.RefreshTokenUsecaseMock
+	mfaUC          *usecase_mock.MfaUsecaseMock
+	loginThrottle  *usecase_mock.LoginThrottleUsecaseMock
+	accountUC      *usecase_mock.AccountUsecaseMock
+	sessionUC      *usecase_mock.SessionUsecaseMock
+	auditUC        *usecase_mock.AuditUsecaseMock
+	client         model.ClientInfo
 }
 
 func TestAuthUcSuite(t *testing.T) {
@@ -26,43 +38,347 @@ func TestAuthUcSuite(t *testing.T) {
 func (a *authUCSuite) SetupTest() {
 	a.UserUsecase = new(usecase_mock.UserUseCaseMock)
 	a.jwtService = new(service_mock.JWTServiceMock)
-	a.authUC = usecase.NewAuthenticationUsecase(a.UserUsecase, a.jwtService)
+	a.refreshTokenUC = new(usecase_mock.RefreshTokenUsecaseMock)
+	a.mfaUC = new(usecase_mock.MfaUsecaseMock)
+	a.loginThrottle = new(usecase_mock.LoginThrottleUsecaseMock)
+	a.accountUC = new(usecase_mock.AccountUsecaseMock)
+	a.client = model.ClientInfo{IP: "10.0.0.1", UserAgent: "curl/8.0"}
+	a.sessionUC = new(usecase_mock.SessionUsecaseMock)
+	a.auditUC = new(usecase_mock.AuditUsecaseMock)
+	a.auditUC.On("Record", mock.Anything, mock.Anything).Return()
+	a.authUC = usecase.NewAuthenticationUsecase(a.UserUsecase, a.jwtService, a.refreshTokenUC, a.mfaUC, a.loginThrottle,
+		service.NewBcryptHasher(bcrypt.DefaultCost), security.NewPasswordPolicy(8, 64, []string{"password123"}), a.accountUC, a.sessionUC, a.auditUC)
+}
+
+// allowAttempts lets every attempt for the username through the throttle.
+func (a *authUCSuite) allowAttempts(username string) {
+	a.loginThrottle.On("Check", username, a.client.IP).Return(nil)
+	a.loginThrottle.On("RegisterFailure", username, a.client.IP).Return(nil)
+	a.loginThrottle.On("RegisterSuccess", username).Return(nil)
 }
 
 func (a *authUCSuite) TestLogin() {
 	username := "username"
 	password := "password"
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	user := model.User{Username: username, Password: string(hashedPassword), Role: "user"}
+	user := model.User{Username: username, EmailVerified: true, Password: string(hashedPassword), Role: "user"}
 
+	a.allowAttempts(username)
 	a.UserUsecase.On("GetUserByUsername", username).Return(user, nil)
-	a.jwtService.On("CreateToken", user).Return("token")
+	a.mfaUC.On("IsEnabled", user.ID).Return(false, nil)
+	a.jwtService.On("CreateTokenWithClaims", modelutils.JwtPayloadClaims{UserId: user.ID, Role: user.Role, Sid: "sid"}).Return("token")
+	a.sessionUC.On("Record", "", user.ID, a.client).Return("sid", nil)
+	a.refreshTokenUC.On("Issue", user.ID, "sid", false).Return("refresh", nil)
 
-	token, err := a.authUC.Login(username, password)
+	result, err := a.authUC.Login(username, password, a.client)
 	a.NoError(err)
-	a.Equal("token", token)
+	a.False(result.MfaRequired)
+	a.Equal("token", result.AccessToken)
+	a.Equal("refresh", result.RefreshToken)
+	a.loginThrottle.AssertCalled(a.T(), "RegisterSuccess", username)
+	a.UserUsecase.AssertNotCalled(a.T(), "UpdatePasswordHash", mock.Anything, mock.Anything)
 }
 
-func (a *authUCSuite) TestLogin_UserNotFound() {
+func (a *authUCSuite) TestLogin_RehashesOutdatedHash() {
+	username := "username"
+	password := "password"
+	// hashed with a lower cost than the hasher uses now
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	user := model.User{ID: 1, Username: username, EmailVerified: true, Password: string(hashedPassword), Role: "user"}
+
+	a.allowAttempts(username)
+	a.UserUsecase.On("GetUserByUsername", username).Return(user, nil)
+	a.UserUsecase.On("UpdatePasswordHash", 1, mock.MatchedBy(func(hash string) bool {
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err == nil && cost == bcrypt.DefaultCost && bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	})).Return(nil).Once()
+	a.mfaUC.On("IsEnabled", user.ID).Return(false, nil)
+	a.jwtService.On("CreateTokenWithClaims", modelutils.JwtPayloadClaims{UserId: user.ID, Role: user.Role, Sid: "sid"}).Return("token")
+	a.sessionUC.On("Record", "", user.ID, a.client).Return("sid", nil)
+	a.refreshTokenUC.On("Issue", user.ID, "sid", false).Return("refresh", nil)
+
+	_, err := a.authUC.Login(username, password, a.client)
+	a.NoError(err)
+	a.UserUsecase.AssertExpectations(a.T())
+}
+
+func (a *authUCSuite) TestLogin_RehashFailureDoesNotFailLogin() {
+	username := "username"
+	password := "password"
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	user := model.User{ID: 1, Username: username, EmailVerified: true, Password: string(hashedPassword), Role: "user"}
+
+	a.allowAttempts(username)
+	a.UserUsecase.On("GetUserByUsername", username).Return(user, nil)
+	a.UserUsecase.On("UpdatePasswordHash", 1, mock.Anything).Return(errors.New("db down"))
+	a.mfaUC.On("IsEnabled", user.ID).Return(false, nil)
+	a.jwtService.On("CreateTokenWithClaims", modelutils.JwtPayloadClaims{UserId: user.ID, Role: user.Role, Sid: "sid"}).Return("token")
+	a.sessionUC.On("Record", "", user.ID, a.client).Return("sid", nil)
+	a.refreshTokenUC.On("Issue", user.ID, "sid", false).Return("refresh", nil)
+
+	result, err := a.authUC.Login(username, password, a.client)
+	a.NoError(err)
+	a.Equal("token", result.AccessToken)
+}
+
+func (a *authUCSuite) TestLogin_Throttled() {
+	a.loginThrottle.On("Check", "username", a.client.IP).Return(&usecase.TooManyAttemptsError{RetryAfter: time.Minute})
+
+	_, err := a.authUC.Login("username", "password", a.client)
+	var tooMany *usecase.TooManyAttemptsError
+	a.ErrorAs(err, &tooMany)
+	a.UserUsecase.AssertNotCalled(a.T(), "GetUserByUsername", "username")
+}
+
+func (a *authUCSuite) TestLogin_AccountDisabled() {
 	username := "username"
 	password := "password"
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	user := model.User{ID: 1, Username: username, Password: string(hashedPassword), Role: "user", Disabled: true}
 
-	a.UserUsecase.On("GetUserByUsername", username).Return(model.User{}, nil)
+	a.allowAttempts(username)
+	a.UserUsecase.On("GetUserByUsername", username).Return(user, nil)
 
-	_, err := a.authUC.Login(username, password)
+	_, err := a.authUC.Login(username, password, a.client)
+	a.ErrorIs(err, usecase.ErrAccountDisabled)
+	a.refreshTokenUC.AssertNotCalled(a.T(), "Issue", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (a *authUCSuite) TestLogin_EmailNotVerified() {
+	username := "username"
+	password := "password"
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	user := model.User{ID: 1, Username: username, Email: "user@example.com", Password: string(hashedPassword), Role: "user"}
+
+	a.allowAttempts(username)
+	a.UserUsecase.On("GetUserByUsername", username).Return(user, nil)
+
+	_, err := a.authUC.Login(username, password, a.client)
+	a.ErrorIs(err, usecase.ErrEmailNotVerified)
+	a.refreshTokenUC.AssertNotCalled(a.T(), "Issue", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (a *authUCSuite) TestLogin_AdminCreatedUserWithoutEmail() {
+	userRepo := new(usecase_mock.UserRepositoryMock)
+	userUC := usecase.NewUserUsecase(userRepo, new(usecase_mock.RbacRepositoryMock), a.refreshTokenUC, service.NewBcryptHasher(bcrypt.MinCost), security.NewPasswordPolicy(8, 64, nil), a.auditUC)
+	authUC := usecase.NewAuthenticationUsecase(userUC, a.jwtService, a.refreshTokenUC, a.mfaUC, a.loginThrottle,
+		service.NewBcryptHasher(bcrypt.MinCost), security.NewPasswordPolicy(8, 64, nil), a.accountUC, a.sessionUC, a.auditUC)
+
+	var created model.User
+	userRepo.On("GetUserByUsername", "alice").Return(model.User{}, errors.New("user with username alice not found")).Once()
+	userRepo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		created = *args.Get(0).(*model.User)
+		created.ID = 7
+	}).Return(&model.User{ID: 7}, nil).Once()
+
+	_, err := userUC.CreateUser(model.CreateUserRequest{Username: "alice", Password: "correct horse battery"}, model.Actor{UserID: 1})
+	a.NoError(err)
+	a.Empty(created.Email)
+
+	a.allowAttempts("alice")
+	userRepo.On("GetUserByUsername", "alice").Return(created, nil)
+	a.mfaUC.On("IsEnabled", 7).Return(false, nil)
+	a.jwtService.On("CreateTokenWithClaims", modelutils.JwtPayloadClaims{UserId: 7, Role: "user", Sid: "sid"}).Return("token")
+	a.sessionUC.On("Record", "", 7, a.client).Return("sid", nil)
+	a.refreshTokenUC.On("Issue", 7, "sid", false).Return("refresh", nil)
+
+	result, err := authUC.Login("alice", "correct horse battery", a.client)
+	a.NoError(err)
+	a.Equal("token", result.AccessToken)
+}
+
+func (a *authUCSuite) TestLogin_MfaRequired() {
+	username := "admin"
+	password := "password"
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	user := model.User{ID: 1, Username: username, EmailVerified: true, Password: string(hashedPassword), Role: "admin"}
+
+	a.allowAttempts(username)
+	a.UserUsecase.On("GetUserByUsername", username).Return(user, nil)
+	a.mfaUC.On("IsEnabled", user.ID).Return(true, nil)
+	a.jwtService.On("CreateTokenWithClaims", mock.MatchedBy(func(claims modelutils.JwtPayloadClaims) bool {
+		return claims.UserId == 1 && claims.Purpose == service.TokenPurposeMfa && claims.ExpiresAt != nil
+	})).Return("mfa-token")
+
+	result, err := a.authUC.Login(username, password, a.client)
+	a.NoError(err)
+	a.True(result.MfaRequired)
+	a.Equal("mfa-token", result.MfaToken)
+	a.Empty(result.AccessToken)
+	a.refreshTokenUC.AssertNotCalled(a.T(), "Issue", mock.Anything, mock.Anything, mock.Anything)
+	a.loginThrottle.AssertNotCalled(a.T(), "RegisterSuccess", username)
+	a.auditUC.AssertNotCalled(a.T(), "Record", mock.Anything, mock.Anything)
+}
+
+func (a *authUCSuite) TestVerifyMfa_Success() {
+	user := model.User{ID: 1, Username: "admin", Role: "admin"}
+
+	a.allowAttempts("admin")
+	a.jwtService.On("VerifyTokenWithPurpose", "mfa-token", service.TokenPurposeMfa).Return(&modelutils.JwtPayloadClaims{UserId: 1, Role: "admin"}, nil)
+	a.mfaUC.On("Verify", 1, "123456").Return(nil)
+	a.UserUsecase.On("GetUserByID", 1).Return(user, nil)
+	a.sessionUC.On("Record", "", 1, a.client).Return("sid", nil)
+	a.refreshTokenUC.On("Issue", 1, "sid", true).Return("refresh", nil)
+	a.jwtService.On("CreateTokenWithClaims", modelutils.JwtPayloadClaims{UserId: 1, Role: "admin", Mfa: true, Sid: "sid"}).Return("token")
+
+	tokens, err := a.authUC.VerifyMfa("mfa-token", "123456", a.client)
+	a.NoError(err)
+	a.Equal("token", tokens.AccessToken)
+	a.Equal("refresh", tokens.RefreshToken)
+	a.auditUC.AssertCalled(a.T(), "Record", model.AuditEvent{ActorID: 1, Actor: "admin", Action: model.AuditActionLoginMfa, Target: "admin", IP: a.client.IP}, nil)
+}
+
+func (a *authUCSuite) TestVerifyMfa_InvalidToken() {
+	a.jwtService.On("VerifyTokenWithPurpose", "bad", service.TokenPurposeMfa).Return(&modelutils.JwtPayloadClaims{}, errors.New("invalid token"))
+
+	_, err := a.authUC.VerifyMfa("bad", "123456", a.client)
+	a.ErrorIs(err, usecase.ErrInvalidMfaToken)
+}
+
+func (a *authUCSuite) TestVerifyMfa_InvalidCode() {
+	a.allowAttempts("admin")
+	a.jwtService.On("VerifyTokenWithPurpose", "mfa-token", service.TokenPurposeMfa).Return(&modelutils.JwtPayloadClaims{UserId: 1}, nil)
+	a.UserUsecase.On("GetUserByID", 1).Return(model.User{ID: 1, Username: "admin", Role: "admin"}, nil)
+	a.mfaUC.On("Verify", 1, "000000").Return(usecase.ErrInvalidMfaCode)
+
+	_, err := a.authUC.VerifyMfa("mfa-token", "000000", a.client)
+	a.ErrorIs(err, usecase.ErrInvalidMfaCode)
+	a.refreshTokenUC.AssertNotCalled(a.T(), "Issue", mock.Anything, mock.Anything, mock.Anything)
+	a.loginThrottle.AssertCalled(a.T(), "RegisterFailure", "admin", a.client.IP)
+}
+
+func (a *authUCSuite) TestLogin_IssueRefreshTokenFailed() {
+	username := "username"
+	password := "password"
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	user := model.User{ID: 1, Username: username, EmailVerified: true, Password: string(hashedPassword), Role: "user"}
+
+	a.allowAttempts(username)
+	a.UserUsecase.On("GetUserByUsername", username).Return(user, nil)
+	a.mfaUC.On("IsEnabled", user.ID).Return(false, nil)
+	a.sessionUC.On("Record", "", user.ID, a.client).Return("sid", nil)
+	a.refreshTokenUC.On("Issue", user.ID, "sid", false).Return("", errors.New("error"))
+
+	_, err := a.authUC.Login(username, password, a.client)
 	a.Error(err)
 }
 
+func (a *authUCSuite) TestLogin_RecordSessionFailed() {
+	username := "username"
+	password := "password"
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	user := model.User{ID: 1, Username: username, EmailVerified: true, Password: string(hashedPassword), Role: "user"}
+
+	a.allowAttempts(username)
+	a.UserUsecase.On("GetUserByUsername", username).Return(user, nil)
+	a.mfaUC.On("IsEnabled", user.ID).Return(false, nil)
+	a.sessionUC.On("Record", "", user.ID, a.client).Return("", errors.New("db down"))
+
+	_, err := a.authUC.Login(username, password, a.client)
+	a.Error(err)
+	a.refreshTokenUC.AssertNotCalled(a.T(), "Issue", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (a *authUCSuite) TestRefresh_Success() {
+	user := model.User{ID: 1, Username: "username", Role: "admin"}
+
+	a.refreshTokenUC.On("Rotate", "old", "").Return(model.RefreshToken{UserID: 1, FamilyID: "family"}, "new", nil)
+	a.UserUsecase.On("GetUserByID", 1).Return(user, nil)
+	a.sessionUC.On("Record", "family", 1, a.client).Return("family", nil).Once()
+	a.jwtService.On("CreateTokenWithClaims", modelutils.JwtPayloadClaims{UserId: user.ID, Role: user.Role, Sid: "family"}).Return("token")
+
+	tokens, err := a.authUC.Refresh("old", a.client)
+	a.NoError(err)
+	a.Equal("token", tokens.AccessToken)
+	a.Equal("new", tokens.RefreshToken)
+}
+
+func (a *authUCSuite) TestRefresh_AccountDisabled() {
+	a.refreshTokenUC.On("Rotate", "old", "").Return(model.RefreshToken{UserID: 1, FamilyID: "family"}, "new", nil)
+	a.UserUsecase.On("GetUserByID", 1).Return(model.User{ID: 1, Username: "username", Role: "user", Disabled: true}, nil)
+
+	_, err := a.authUC.Refresh("old", a.client)
+	a.ErrorIs(err, usecase.ErrAccountDisabled)
+	a.jwtService.AssertNotCalled(a.T(), "CreateTokenWithClaims", mock.Anything)
+	a.sessionUC.AssertNotCalled(a.T(), "Record", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (a *authUCSuite) TestRefresh_KeepsMfa() {
+	user := model.User{ID: 1, Username: "username", Role: "admin"}
+
+	a.refreshTokenUC.On("Rotate", "old", "").Return(model.RefreshToken{UserID: 1, FamilyID: "family", Mfa: true}, "new", nil)
+	a.UserUsecase.On("GetUserByID", 1).Return(user, nil)
+	a.sessionUC.On("Record", "family", 1, a.client).Return("family", nil)
+	a.jwtService.On("CreateTokenWithClaims", modelutils.JwtPayloadClaims{UserId: 1, Role: "admin", Mfa: true, Sid: "family"}).Return("token")
+
+	tokens, err := a.authUC.Refresh("old", a.client)
+	a.NoError(err)
+	a.Equal("token", tokens.AccessToken)
+}
+
+func (a *authUCSuite) TestRefresh_Reused() {
+	a.refreshTokenUC.On("Rotate", "old", "").Return(model.RefreshToken{}, "", usecase.ErrRefreshTokenReused)
+
+	_, err := a.authUC.Refresh("old", a.client)
+	a.ErrorIs(err, usecase.ErrRefreshTokenReused)
+}
+
+func (a *authUCSuite) TestLogout() {
+	a.refreshTokenUC.On("Revoke", "refresh").Return(nil)
+
+	err := a.authUC.Logout("refresh")
+	a.NoError(err)
+	a.refreshTokenUC.AssertExpectations(a.T())
+}
+
+func (a *authUCSuite) TestLogin_UserNotFound() {
+	username := "username"
+	password := "password"
+
+	a.allowAttempts(username)
+	a.UserUsecase.On("GetUserByUsername", username).Return(model.User{}, usecase.NewDomainError(usecase.KindNotFound, "user with username username not found"))
+
+	_, err := a.authUC.Login(username, password, a.client)
+	a.ErrorIs(err, usecase.ErrInvalidCredentials)
+	a.loginThrottle.AssertCalled(a.T(), "RegisterFailure", username, a.client.IP)
+}
+
+func (a *authUCSuite) TestLogin_UserNotFoundVerifiesDummyHash() {
+	hasher := &verifyRecorder{PasswordHasher: service.NewBcryptHasher(bcrypt.MinCost)}
+	authUC := usecase.NewAuthenticationUsecase(a.UserUsecase, a.jwtService, a.refreshTokenUC, a.mfaUC, a.loginThrottle,
+		hasher, security.NewPasswordPolicy(8, 64, nil), a.accountUC, a.sessionUC, a.auditUC)
+
+	a.allowAttempts("username")
+	a.UserUsecase.On("GetUserByUsername", "username").Return(model.User{}, usecase.NewDomainError(usecase.KindNotFound, "user with username username not found"))
+
+	_, err := authUC.Login("username", "password", a.client)
+	a.ErrorIs(err, usecase.ErrInvalidCredentials)
+	a.Len(hasher.hashes, 1)
+	a.False(hasher.NeedsRehash(hasher.hashes[0]))
+}
+
+func (a *authUCSuite) TestLogin_LookupFailed() {
+	a.allowAttempts("username")
+	a.UserUsecase.On("GetUserByUsername", "username").Return(model.User{}, errors.New("connection refused"))
+
+	_, err := a.authUC.Login("username", "password", a.client)
+	a.EqualError(err, "connection refused")
+	a.loginThrottle.AssertNotCalled(a.T(), "RegisterFailure", "username", a.client.IP)
+}
+
 func (a *authUCSuite) TestLogin_PasswordMismatch() {
 	username := "username"
 	password := "password"
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	user := model.User{Username: username, Password: string(hashedPassword), Role: "user"}
 
+	a.allowAttempts(username)
 	a.UserUsecase.On("GetUserByUsername", username).Return(user, nil)
 
-	_, err := a.authUC.Login(username, "wrongpassword")
-	a.Error(err)
+	_, err := a.authUC.Login(username, "wrongpassword", a.client)
+	a.ErrorIs(err, usecase.ErrInvalidCredentials)
+	a.loginThrottle.AssertCalled(a.T(), "RegisterFailure", username, a.client.IP)
+	a.auditUC.AssertCalled(a.T(), "Record", model.AuditEvent{Actor: username, Action: model.AuditActionLogin, Target: username, IP: a.client.IP}, usecase.ErrInvalidCredentials)
 }
 
 func (a *authUCSuite) TestRegister_UsernameTaken() {
@@ -73,7 +389,7 @@ func (a *authUCSuite) TestRegister_UsernameTaken() {
 
 	a.UserUsecase.On("GetUserByUsername", username).Return(user, nil)
 
-	_, err := a.authUC.Register(username, password)
+	_, err := a.authUC.Register(username, password, "user@example.com", a.client)
 	a.Error(err)
 }
 
@@ -86,10 +402,69 @@ func (a *authUCSuite) TestRegister_Failed() {
 	a.UserUsecase.On("GetUserByUsername", username).Return(user, nil)
 	a.UserUsecase.On("Create", &model.User{Username: username, Password: string(hashedPassword), Role: "user"}).Return(nil, errors.New("error creating user"))
 
-	_, err := a.authUC.Register(username, password)
+	_, err := a.authUC.Register(username, password, "user@example.com", a.client)
 	a.Error(err)
 }
 
+func (a *authUCSuite) TestRegister_Success() {
+	a.UserUsecase.On("GetUserByUsername", "alice").Return(model.User{}, usecase.NewDomainError(usecase.KindNotFound, "user with username alice not found"))
+	a.UserUsecase.On("GetUserByEmail", "alice@example.com").Return(model.User{}, usecase.NewDomainError(usecase.KindNotFound, "user with email alice@example.com not found"))
+	a.UserUsecase.On("Create", mock.MatchedBy(func(user *model.User) bool {
+		return user.Username == "alice" && user.Role == "user" && user.Email == "alice@example.com" && !user.EmailVerified &&
+			bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("correct horse battery")) == nil
+	})).Return(&model.User{ID: 1}, nil).Once()
+	a.accountUC.On("SendVerificationEmail", mock.MatchedBy(func(user model.User) bool {
+		return user.Email == "alice@example.com"
+	})).Return(nil).Once()
+
+	_, err := a.authUC.Register("alice", "correct horse battery", " Alice@Example.com ", a.client)
+	a.NoError(err)
+	a.UserUsecase.AssertExpectations(a.T())
+	a.accountUC.AssertExpectations(a.T())
+	a.auditUC.AssertCalled(a.T(), "Record", mock.MatchedBy(func(event model.AuditEvent) bool {
+		return event.Action == model.AuditActionRegister && event.Actor == "alice" && event.Target == "alice" && event.IP == a.client.IP
+	}), nil)
+}
+
+func (a *authUCSuite) TestRegister_MailFailureDoesNotFailRegistration() {
+	a.UserUsecase.On("GetUserByUsername", "alice").Return(model.User{}, usecase.NewDomainError(usecase.KindNotFound, "user with username alice not found"))
+	a.UserUsecase.On("GetUserByEmail", "alice@example.com").Return(model.User{}, usecase.NewDomainError(usecase.KindNotFound, "user with email alice@example.com not found"))
+	a.UserUsecase.On("Create", mock.Anything).Return(&model.User{ID: 1}, nil)
+	a.accountUC.On("SendVerificationEmail", mock.Anything).Return(errors.New("smtp down"))
+
+	_, err := a.authUC.Register("alice", "correct horse battery", "alice@example.com", a.client)
+	a.NoError(err)
+}
+
+func (a *authUCSuite) TestRegister_InvalidEmail() {
+	a.UserUsecase.On("GetUserByUsername", "alice").Return(model.User{}, usecase.NewDomainError(usecase.KindNotFound, "user with username alice not found"))
+
+	for _, email := range []string{"", "not an email", "Alice <alice@example.com>"} {
+		_, err := a.authUC.Register("alice", "correct horse battery", email, a.client)
+		a.ErrorIs(err, usecase.ErrInvalidEmail)
+	}
+	a.UserUsecase.AssertNotCalled(a.T(), "Create", mock.Anything)
+}
+
+func (a *authUCSuite) TestRegister_EmailTaken() {
+	a.UserUsecase.On("GetUserByUsername", "alice").Return(model.User{}, usecase.NewDomainError(usecase.KindNotFound, "user with username alice not found"))
+	a.UserUsecase.On("GetUserByEmail", "alice@example.com").Return(model.User{ID: 2, Email: "alice@example.com"}, nil)
+
+	_, err := a.authUC.Register("alice", "correct horse battery", "alice@example.com", a.client)
+	a.EqualError(err, "email 'alice@example.com' is already taken")
+	a.UserUsecase.AssertNotCalled(a.T(), "Create", mock.Anything)
+}
+
+func (a *authUCSuite) TestRegister_PasswordPolicy() {
+	a.UserUsecase.On("GetUserByUsername", "alice").Return(model.User{}, usecase.NewDomainError(usecase.KindNotFound, "user with username alice not found"))
+
+	for _, password := range []string{"short", "Password123"} {
+		_, err := a.authUC.Register("alice", password, "alice@example.com", a.client)
+		a.ErrorIs(err, security.ErrPasswordPolicy)
+	}
+	a.UserUsecase.AssertNotCalled(a.T(), "Create", mock.Anything)
+}
+
 func (a *authUCSuite) TestRegister_EmptyUsername() {
 	username := ""
 	password := "password"
@@ -99,7 +474,7 @@ func (a *authUCSuite) TestRegister_EmptyUsername() {
 	a.UserUsecase.On("GetUserByUsername", username).Return(user, errors.New("username cannot be empty"))
 	a.UserUsecase.On("Create", &model.User{Username: username, Password: string(hashedPassword), Role: "user"}).Return(nil, errors.New("username cannot be empty"))
 
-	_, err := a.authUC.Register(username, password)
+	_, err := a.authUC.Register(username, password, "user@example.com", a.client)
 	a.Error(err)
 }
 
@@ -112,6 +487,17 @@ func (a *authUCSuite) TestRegister_EmptyPassword() {
 	a.UserUsecase.On("GetUserByUsername", username).Return(user, errors.New("password cannot be empty"))
 	a.UserUsecase.On("Create", &model.User{Username: username, Password: string(hashedPassword), Role: "user"}).Return(nil, errors.New("password cannot be empty"))
 
-	_, err := a.authUC.Register(username, password)
+	_, err := a.authUC.Register(username, password, "user@example.com", a.client)
 	a.Error(err)
 }
+
+// verifyRecorder records the hashes a password is verified against.
+type verifyRecorder struct {
+	service.PasswordHasher
+	hashes []string
+}
+
+func (v *verifyRecorder) Verify(password string, hash string) bool {
+	v.hashes = append(v.hashes, hash)
+	return v.PasswordHasher.Verify(password, hash)
+}
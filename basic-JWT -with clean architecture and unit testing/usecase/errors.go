@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// ErrorKind classifies a DomainError. The HTTP layer maps each kind to a
+// status code, so usecases never deal with HTTP themselves.
+type ErrorKind string
+
+const (
+	KindValidation   ErrorKind = "validation"
+	KindUnauthorized ErrorKind = "unauthorized"
+	KindForbidden    ErrorKind = "forbidden"
+	KindNotFound     ErrorKind = "not found"
+	KindConflict     ErrorKind = "conflict"
+	// KindUnavailable means a dependency such as the database is down, the
+	// request may succeed when retried later.
+	KindUnavailable ErrorKind = "unavailable"
+)
+
+// DomainError is an error the caller caused and can act on. Err optionally
+// holds the underlying error, such as a password policy violation.
+type DomainError struct {
+	Kind    ErrorKind
+	Message string
+	Err     error
+}
+
+func (e *DomainError) Error() string {
+	if e.Message == "" {
+		return string(e.Kind)
+	}
+	return e.Message
+}
+
+func (e *DomainError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches the kind sentinels below against every error of their kind, so
+// errors.Is(err, ErrNotFound) is true for any not found error.
+func (e *DomainError) Is(target error) bool {
+	kind, ok := target.(*DomainError)
+	return ok && kind.Message == "" && kind.Err == nil && kind.Kind == e.Kind
+}
+
+// Kind sentinels, for use with errors.Is
+var (
+	ErrValidation   = &DomainError{Kind: KindValidation}
+	ErrUnauthorized = &DomainError{Kind: KindUnauthorized}
+	ErrForbidden    = &DomainError{Kind: KindForbidden}
+	ErrNotFound     = &DomainError{Kind: KindNotFound}
+	ErrConflict     = &DomainError{Kind: KindConflict}
+	ErrUnavailable  = &DomainError{Kind: KindUnavailable}
+)
+
+func NewDomainError(kind ErrorKind, message string) *DomainError {
+	return &DomainError{Kind: kind, Message: message}
+}
+
+// wrapError keeps err reachable for errors.Is and errors.As while giving it a kind.
+func wrapError(kind ErrorKind, err error) error {
+	return &DomainError{Kind: kind, Message: err.Error(), Err: err}
+}
+
+// notFoundError turns a missing row reported by a repository into
+// ErrNotFound and passes every other error through.
+func notFoundError(err error) error {
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		return wrapError(KindNotFound, err)
+	}
+	return err
+}
@@ -0,0 +1,27 @@
+package usecase_test
+
+import (
+	"basic-JWT/usecase"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDomainError_IsMatchesKind(t *testing.T) {
+	err := fmt.Errorf("login: %w", usecase.ErrInvalidCredentials)
+
+	assert.ErrorIs(t, err, usecase.ErrUnauthorized)
+	assert.ErrorIs(t, err, usecase.ErrInvalidCredentials)
+	assert.NotErrorIs(t, err, usecase.ErrForbidden)
+	assert.NotErrorIs(t, err, usecase.ErrInvalidMfaToken)
+}
+
+func TestDomainError_As(t *testing.T) {
+	var domainErr *usecase.DomainError
+	assert.True(t, errors.As(usecase.NewDomainError(usecase.KindConflict, "taken"), &domainErr))
+	assert.Equal(t, usecase.KindConflict, domainErr.Kind)
+	assert.Equal(t, "taken", domainErr.Error())
+	assert.Equal(t, "not found", usecase.ErrNotFound.Error())
+}
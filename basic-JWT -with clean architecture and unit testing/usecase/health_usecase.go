@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"basic-JWT/repository"
+	"context"
+	"time"
+)
+
+// readinessTimeout bounds the database ping, probes give up after a few seconds.
+const readinessTimeout = 2 * time.Second
+
+// HealthUsecase reports whether the service can handle requests.
+type HealthUsecase interface {
+	Ready(ctx context.Context) error
+}
+
+type healthUsecase struct {
+	healthRepository repository.HealthRepository
+}
+
+// Ready pings the database and reports an ErrUnavailable error when it does
+// not answer in time.
+func (hu *healthUsecase) Ready(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	if err := hu.healthRepository.Ping(ctx); err != nil {
+		return &DomainError{Kind: KindUnavailable, Message: "database is unavailable", Err: err}
+	}
+	return nil
+}
+
+func NewHealthUsecase(healthRepository repository.HealthRepository) HealthUsecase {
+	return &healthUsecase{healthRepository: healthRepository}
+}
@@ -0,0 +1,47 @@
+package usecase_test
+
+import (
+	"basic-JWT/mock/usecase_mock"
+	"basic-JWT/usecase"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type healthUcSuite struct {
+	suite.Suite
+	healthRepo *usecase_mock.HealthRepositoryMock
+	healthUc   usecase.HealthUsecase
+}
+
+func TestHealthUcSuite(t *testing.T) {
+	suite.Run(t, new(healthUcSuite))
+}
+
+func (h *healthUcSuite) SetupTest() {
+	h.healthRepo = new(usecase_mock.HealthRepositoryMock)
+	h.healthUc = usecase.NewHealthUsecase(h.healthRepo)
+}
+
+func (h *healthUcSuite) TestReady_Success() {
+	h.healthRepo.On("Ping", mock.MatchedBy(func(ctx context.Context) bool {
+		_, hasDeadline := ctx.Deadline()
+		return hasDeadline
+	})).Return(nil)
+
+	h.NoError(h.healthUc.Ready(context.Background()))
+}
+
+func (h *healthUcSuite) TestReady_DatabaseDown() {
+	pingErr := errors.New("dial tcp 127.0.0.1:5432: connection refused")
+	h.healthRepo.On("Ping", mock.Anything).Return(pingErr)
+
+	err := h.healthUc.Ready(context.Background())
+	h.ErrorIs(err, usecase.ErrUnavailable)
+	h.ErrorIs(err, pingErr)
+	// the address of the database stays out of the probe response
+	h.EqualError(err, "database is unavailable")
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"basic-JWT/config"
+	"basic-JWT/model"
+	"basic-JWT/repository"
+	"fmt"
+	"time"
+)
+
+// TooManyAttemptsError is returned while a username or client IP is delayed
+// or locked out. RetryAfter tells the client when to try again.
+type TooManyAttemptsError struct {
+	RetryAfter time.Duration
+}
+
+func (e *TooManyAttemptsError) Error() string {
+	return fmt.Sprintf("too many login attempts, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+type LoginThrottleUsecase interface {
+	Check(username string, ip string) error
+	RegisterFailure(username string, ip string) error
+	RegisterSuccess(username string) error
+	Unlock(username string) error
+}
+
+type loginThrottleUsecase struct {
+	loginAttemptRepository repository.LoginAttemptRepository
+	config                 config.LoginThrottleConfig
+}
+
+// Check returns a *TooManyAttemptsError when the username or IP is locked,
+// or when the username is still inside its progressive delay. The delay
+// doubles with every failure: BaseDelay, 2*BaseDelay, 4*BaseDelay, ...
+func (lu *loginThrottleUsecase) Check(username string, ip string) error {
+	now := time.Now()
+
+	userAttempt, err := lu.loginAttemptRepository.Get(userAttemptKey(username))
+	if err != nil {
+		return err
+	}
+	if wait := lu.wait(userAttempt, lu.config.MaxAttempts, true, now); wait > 0 {
+		return &TooManyAttemptsError{RetryAfter: wait}
+	}
+
+	// many users can share one IP behind a NAT, so the IP only gets a lockout with a higher threshold
+	ipAttempt, err := lu.loginAttemptRepository.Get(ipAttemptKey(ip))
+	if err != nil {
+		return err
+	}
+	if wait := lu.wait(ipAttempt, lu.config.IPMaxAttempts, false, now); wait > 0 {
+		return &TooManyAttemptsError{RetryAfter: wait}
+	}
+
+	return nil
+}
+
+func (lu *loginThrottleUsecase) RegisterFailure(username string, ip string) error {
+	if err := lu.registerFailure(userAttemptKey(username), lu.config.MaxAttempts); err != nil {
+		return err
+	}
+	return lu.registerFailure(ipAttemptKey(ip), lu.config.IPMaxAttempts)
+}
+
+// RegisterSuccess clears the username counter only. Clearing the IP counter
+// would let an attacker reset it by logging into their own account.
+func (lu *loginThrottleUsecase) RegisterSuccess(username string) error {
+	return lu.loginAttemptRepository.Reset(userAttemptKey(username))
+}
+
+func (lu *loginThrottleUsecase) Unlock(username string) error {
+	return lu.loginAttemptRepository.Reset(userAttemptKey(username))
+}
+
+func (lu *loginThrottleUsecase) registerFailure(key string, maxAttempts int) error {
+	attempt, err := lu.loginAttemptRepository.RegisterFailure(key, lu.config.Window)
+	if err != nil {
+		return err
+	}
+	if attempt.Failures >= maxAttempts {
+		return lu.loginAttemptRepository.Lock(key, time.Now().Add(lu.config.LockoutDuration))
+	}
+	return nil
+}
+
+func (lu *loginThrottleUsecase) wait(attempt model.LoginAttempt, maxAttempts int, progressive bool, now time.Time) time.Duration {
+	if attempt.LockedUntil.After(now) {
+		return attempt.LockedUntil.Sub(now)
+	}
+	if !progressive || attempt.Failures == 0 || attempt.Failures >= maxAttempts {
+		return 0
+	}
+
+	delay := lu.config.BaseDelay << (attempt.Failures - 1)
+	if delay > lu.config.LockoutDuration {
+		delay = lu.config.LockoutDuration
+	}
+	return attempt.LastFailureAt.Add(delay).Sub(now)
+}
+
+func userAttemptKey(username string) string {
+	return "user:" + username
+}
+
+func ipAttemptKey(ip string) string {
+	return "ip:" + ip
+}
+
+func NewLoginThrottleUsecase(loginAttemptRepository repository.LoginAttemptRepository, config config.LoginThrottleConfig) LoginThrottleUsecase {
+	return &loginThrottleUsecase{
+		loginAttemptRepository: loginAttemptRepository,
+		config:                 config,
+	}
+}
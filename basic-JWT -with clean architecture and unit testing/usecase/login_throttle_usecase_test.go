@@ -0,0 +1,89 @@
+package usecase_test
+
+import (
+	"basic-JWT/config"
+	"basic-JWT/repository"
+	"basic-JWT/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type loginThrottleUcSuite struct {
+	suite.Suite
+	repo       repository.LoginAttemptRepository
+	throttleUc usecase.LoginThrottleUsecase
+}
+
+func TestLoginThrottleUcSuite(t *testing.T) {
+	suite.Run(t, new(loginThrottleUcSuite))
+}
+
+func (l *loginThrottleUcSuite) SetupTest() {
+	// the in-memory store behaves like the Postgres one and keeps these tests free of mock bookkeeping
+	l.repo = repository.NewInMemoryLoginAttemptRepository()
+	l.throttleUc = usecase.NewLoginThrottleUsecase(l.repo, config.LoginThrottleConfig{
+		MaxAttempts:     3,
+		IPMaxAttempts:   5,
+		Window:          time.Minute,
+		LockoutDuration: 10 * time.Minute,
+		BaseDelay:       time.Second,
+	})
+}
+
+func (l *loginThrottleUcSuite) TestCheck_NoFailures() {
+	l.NoError(l.throttleUc.Check("alice", "10.0.0.1"))
+}
+
+func (l *loginThrottleUcSuite) TestCheck_ProgressiveDelay() {
+	l.NoError(l.throttleUc.RegisterFailure("alice", "10.0.0.1"))
+	l.NoError(l.throttleUc.RegisterFailure("alice", "10.0.0.1"))
+
+	err := l.throttleUc.Check("alice", "10.0.0.1")
+	var tooMany *usecase.TooManyAttemptsError
+	l.ErrorAs(err, &tooMany)
+	// second failure waits 2 * BaseDelay
+	l.InDelta(2*time.Second, tooMany.RetryAfter, float64(100*time.Millisecond))
+}
+
+func (l *loginThrottleUcSuite) TestCheck_LockedAfterMaxAttempts() {
+	for i := 0; i < 3; i++ {
+		l.NoError(l.throttleUc.RegisterFailure("alice", "10.0.0.1"))
+	}
+
+	err := l.throttleUc.Check("alice", "10.0.0.2")
+	var tooMany *usecase.TooManyAttemptsError
+	l.ErrorAs(err, &tooMany)
+	l.Greater(tooMany.RetryAfter, 9*time.Minute)
+}
+
+func (l *loginThrottleUcSuite) TestCheck_IPLockedAcrossUsernames() {
+	for i := 0; i < 5; i++ {
+		l.NoError(l.throttleUc.RegisterFailure("user"+string(rune('a'+i)), "10.0.0.1"))
+	}
+
+	err := l.throttleUc.Check("zed", "10.0.0.1")
+	var tooMany *usecase.TooManyAttemptsError
+	l.ErrorAs(err, &tooMany)
+
+	l.NoError(l.throttleUc.Check("zed", "10.0.0.2"))
+}
+
+func (l *loginThrottleUcSuite) TestRegisterSuccess_KeepsIPCounter() {
+	l.NoError(l.throttleUc.RegisterFailure("alice", "10.0.0.1"))
+	l.NoError(l.throttleUc.RegisterSuccess("alice"))
+
+	l.NoError(l.throttleUc.Check("alice", "10.0.0.1"))
+	attempt, _ := l.repo.Get("ip:10.0.0.1")
+	l.Equal(1, attempt.Failures)
+}
+
+func (l *loginThrottleUcSuite) TestUnlock() {
+	for i := 0; i < 3; i++ {
+		l.NoError(l.throttleUc.RegisterFailure("alice", "10.0.0.1"))
+	}
+
+	l.NoError(l.throttleUc.Unlock("alice"))
+	l.NoError(l.throttleUc.Check("alice", "10.0.0.2"))
+}
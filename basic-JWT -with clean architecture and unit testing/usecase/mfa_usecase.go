@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"basic-JWT/model"
+	"basic-JWT/repository"
+	"basic-JWT/utils/security"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const recoveryCodeCount = 10
+
+// ErrMfaNotEnrolled and ErrInvalidMfaCode fail a login. Confirm reports them
+// as validation errors instead, the caller is already signed in there.
+var (
+	ErrMfaAlreadyEnabled = NewDomainError(KindConflict, "mfa already enabled")
+	ErrMfaNotEnrolled    = NewDomainError(KindUnauthorized, "mfa not enrolled")
+	ErrInvalidMfaCode    = NewDomainError(KindUnauthorized, "invalid mfa code")
+)
+
+type MfaUsecase interface {
+	Enroll(userID int) (model.MfaEnrollment, error)
+	Confirm(userID int, code string) ([]string, error)
+	IsEnabled(userID int) (bool, error)
+	Verify(userID int, code string) error
+	Reset(username string) error
+}
+
+type mfaUsecase struct {
+	mfaRepository repository.MfaRepository
+	userUsecase   UserUsecase
+	issuer        string
+}
+
+// Enroll generates a new secret for the user. It stays inactive until Confirm
+// receives a valid code for it.
+func (mu *mfaUsecase) Enroll(userID int) (model.MfaEnrollment, error) {
+	user, err := mu.userUsecase.GetUserByID(userID)
+	if err != nil {
+		return model.MfaEnrollment{}, err
+	}
+
+	current, err := mu.mfaRepository.GetByUserID(userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return model.MfaEnrollment{}, err
+	}
+	if current.Enabled {
+		return model.MfaEnrollment{}, ErrMfaAlreadyEnabled
+	}
+
+	secret, err := security.GenerateTOTPSecret()
+	if err != nil {
+		return model.MfaEnrollment{}, err
+	}
+
+	if err := mu.mfaRepository.Save(&model.UserMfa{UserID: userID, Secret: secret}); err != nil {
+		return model.MfaEnrollment{}, err
+	}
+
+	return model.MfaEnrollment{
+		Secret: secret,
+		URI:    security.TOTPURI(mu.issuer, user.Username, secret),
+	}, nil
+}
+
+// Confirm activates a pending enrollment and returns the plain recovery
+// codes. They are only stored hashed, so this is the only time they are shown.
+func (mu *mfaUsecase) Confirm(userID int, code string) ([]string, error) {
+	current, err := mu.mfaRepository.GetByUserID(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, wrapError(KindValidation, ErrMfaNotEnrolled)
+		}
+		return nil, err
+	}
+	if current.Enabled {
+		return nil, ErrMfaAlreadyEnabled
+	}
+
+	step, ok := security.ValidateTOTPCode(current.Secret, code, time.Now())
+	if !ok {
+		return nil, wrapError(KindValidation, ErrInvalidMfaCode)
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		recoveryCode, err := security.GenerateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, recoveryCode)
+		hashes = append(hashes, security.HashToken(security.NormalizeRecoveryCode(recoveryCode)))
+	}
+
+	if err := mu.mfaRepository.Enable(userID, step, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (mu *mfaUsecase) IsEnabled(userID int) (bool, error) {
+	current, err := mu.mfaRepository.GetByUserID(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return current.Enabled, nil
+}
+
+// Verify accepts either a TOTP code or an unused recovery code. A TOTP code
+// is rejected when its time step was already used, so an intercepted code
+// cannot be replayed within its validity window.
+func (mu *mfaUsecase) Verify(userID int, code string) error {
+	current, err := mu.mfaRepository.GetByUserID(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrMfaNotEnrolled
+		}
+		return err
+	}
+	if !current.Enabled {
+		return ErrMfaNotEnrolled
+	}
+
+	if step, ok := security.ValidateTOTPCode(current.Secret, code, time.Now()); ok {
+		fresh, err := mu.mfaRepository.UpdateLastUsedStep(userID, step)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			return ErrInvalidMfaCode
+		}
+		return nil
+	}
+
+	used, err := mu.mfaRepository.UseRecoveryCode(userID, security.HashToken(security.NormalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidMfaCode
+	}
+	return nil
+}
+
+// Reset removes the user's secret and recovery codes, e.g. after a lost
+// device. The user can enroll again on the next login.
+func (mu *mfaUsecase) Reset(username string) error {
+	user, err := mu.userUsecase.GetUserByUsername(username)
+	if err != nil {
+		return err
+	}
+	return mu.mfaRepository.Delete(user.ID)
+}
+
+func NewMfaUsecase(mfaRepository repository.MfaRepository, userUsecase UserUsecase, issuer string) MfaUsecase {
+	if issuer == "" {
+		issuer = "basic-JWT"
+	}
+	return &mfaUsecase{
+		mfaRepository: mfaRepository,
+		userUsecase:   userUsecase,
+		issuer:        issuer,
+	}
+}
@@ -0,0 +1,144 @@
+package usecase_test
+
+import (
+	"basic-JWT/mock/usecase_mock"
+	"basic-JWT/model"
+	"basic-JWT/usecase"
+	"basic-JWT/utils/security"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type mfaUcSuite struct {
+	suite.Suite
+	mfaRepo     *usecase_mock.MfaRepositoryMock
+	userUsecase *usecase_mock.UserUseCaseMock
+	mfaUc       usecase.MfaUsecase
+	secret      string
+}
+
+func TestMfaUcSuite(t *testing.T) {
+	suite.Run(t, new(mfaUcSuite))
+}
+
+func (m *mfaUcSuite) SetupTest() {
+	m.mfaRepo = new(usecase_mock.MfaRepositoryMock)
+	m.userUsecase = new(usecase_mock.UserUseCaseMock)
+	m.mfaUc = usecase.NewMfaUsecase(m.mfaRepo, m.userUsecase, "basic-JWT")
+	m.secret, _ = security.GenerateTOTPSecret()
+}
+
+func (m *mfaUcSuite) TestEnroll_Success() {
+	m.userUsecase.On("GetUserByID", 1).Return(model.User{ID: 1, Username: "alice"}, nil)
+	m.mfaRepo.On("GetByUserID", 1).Return(model.UserMfa{}, sql.ErrNoRows)
+	m.mfaRepo.On("Save", mock.MatchedBy(func(mfa *model.UserMfa) bool {
+		return mfa.UserID == 1 && mfa.Secret != ""
+	})).Return(nil)
+
+	enrollment, err := m.mfaUc.Enroll(1)
+	m.NoError(err)
+	m.NotEmpty(enrollment.Secret)
+	m.True(strings.HasPrefix(enrollment.URI, "otpauth://totp/basic-JWT:alice?"))
+}
+
+func (m *mfaUcSuite) TestEnroll_AlreadyEnabled() {
+	m.userUsecase.On("GetUserByID", 1).Return(model.User{ID: 1, Username: "alice"}, nil)
+	m.mfaRepo.On("GetByUserID", 1).Return(model.UserMfa{UserID: 1, Enabled: true}, nil)
+
+	_, err := m.mfaUc.Enroll(1)
+	m.ErrorIs(err, usecase.ErrMfaAlreadyEnabled)
+	m.mfaRepo.AssertNotCalled(m.T(), "Save", mock.Anything)
+}
+
+func (m *mfaUcSuite) TestConfirm_Success() {
+	code, _ := security.GenerateTOTPCode(m.secret, time.Now())
+	var hashes []string
+	m.mfaRepo.On("GetByUserID", 1).Return(model.UserMfa{UserID: 1, Secret: m.secret}, nil)
+	m.mfaRepo.On("Enable", 1, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		hashes = args.Get(2).([]string)
+	}).Return(nil)
+
+	codes, err := m.mfaUc.Confirm(1, code)
+	m.NoError(err)
+	m.Len(codes, 10)
+	m.Len(hashes, 10)
+	m.Equal(security.HashToken(security.NormalizeRecoveryCode(codes[0])), hashes[0])
+}
+
+func (m *mfaUcSuite) TestConfirm_InvalidCode() {
+	m.mfaRepo.On("GetByUserID", 1).Return(model.UserMfa{UserID: 1, Secret: m.secret}, nil)
+
+	_, err := m.mfaUc.Confirm(1, "000000x")
+	m.ErrorIs(err, usecase.ErrInvalidMfaCode)
+	m.mfaRepo.AssertNotCalled(m.T(), "Enable", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (m *mfaUcSuite) TestConfirm_NotEnrolled() {
+	m.mfaRepo.On("GetByUserID", 1).Return(model.UserMfa{}, sql.ErrNoRows)
+
+	_, err := m.mfaUc.Confirm(1, "123456")
+	m.ErrorIs(err, usecase.ErrMfaNotEnrolled)
+}
+
+func (m *mfaUcSuite) TestIsEnabled_NotEnrolled() {
+	m.mfaRepo.On("GetByUserID", 1).Return(model.UserMfa{}, sql.ErrNoRows)
+
+	enabled, err := m.mfaUc.IsEnabled(1)
+	m.NoError(err)
+	m.False(enabled)
+}
+
+func (m *mfaUcSuite) TestVerify_TOTP() {
+	code, _ := security.GenerateTOTPCode(m.secret, time.Now())
+	m.mfaRepo.On("GetByUserID", 1).Return(model.UserMfa{UserID: 1, Secret: m.secret, Enabled: true}, nil)
+	m.mfaRepo.On("UpdateLastUsedStep", 1, mock.Anything).Return(true, nil)
+
+	err := m.mfaUc.Verify(1, code)
+	m.NoError(err)
+}
+
+func (m *mfaUcSuite) TestVerify_TOTPReplay() {
+	code, _ := security.GenerateTOTPCode(m.secret, time.Now())
+	m.mfaRepo.On("GetByUserID", 1).Return(model.UserMfa{UserID: 1, Secret: m.secret, Enabled: true}, nil)
+	m.mfaRepo.On("UpdateLastUsedStep", 1, mock.Anything).Return(false, nil)
+
+	err := m.mfaUc.Verify(1, code)
+	m.ErrorIs(err, usecase.ErrInvalidMfaCode)
+}
+
+func (m *mfaUcSuite) TestVerify_RecoveryCode() {
+	m.mfaRepo.On("GetByUserID", 1).Return(model.UserMfa{UserID: 1, Secret: m.secret, Enabled: true}, nil)
+	m.mfaRepo.On("UseRecoveryCode", 1, security.HashToken("abcd2345")).Return(true, nil)
+
+	err := m.mfaUc.Verify(1, "ABCD-2345")
+	m.NoError(err)
+}
+
+func (m *mfaUcSuite) TestVerify_UnknownRecoveryCode() {
+	m.mfaRepo.On("GetByUserID", 1).Return(model.UserMfa{UserID: 1, Secret: m.secret, Enabled: true}, nil)
+	m.mfaRepo.On("UseRecoveryCode", 1, mock.Anything).Return(false, nil)
+
+	err := m.mfaUc.Verify(1, "abcd-2345")
+	m.ErrorIs(err, usecase.ErrInvalidMfaCode)
+}
+
+func (m *mfaUcSuite) TestVerify_PendingEnrollment() {
+	m.mfaRepo.On("GetByUserID", 1).Return(model.UserMfa{UserID: 1, Secret: m.secret}, nil)
+
+	err := m.mfaUc.Verify(1, "123456")
+	m.ErrorIs(err, usecase.ErrMfaNotEnrolled)
+}
+
+func (m *mfaUcSuite) TestReset_Success() {
+	m.userUsecase.On("GetUserByUsername", "alice").Return(model.User{ID: 1, Username: "alice"}, nil)
+	m.mfaRepo.On("Delete", 1).Return(nil)
+
+	err := m.mfaUc.Reset("alice")
+	m.NoError(err)
+	m.mfaRepo.AssertExpectations(m.T())
+}
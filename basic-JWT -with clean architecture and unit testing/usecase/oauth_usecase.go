@@ -0,0 +1,572 @@
+package usecase
+
+import (
+	"basic-JWT/config"
+	"basic-JWT/model"
+	"basic-JWT/repository"
+	"basic-JWT/utils/security"
+	"basic-JWT/utils/service"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	modelutils "basic-JWT/utils/model_utils"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+	GrantTypeClientCredentials = "client_credentials"
+
+	// ScopeOpenID asks for an ID token and access to /oauth/userinfo
+	ScopeOpenID = "openid"
+)
+
+var ErrOAuthClientNotFound = NewDomainError(KindNotFound, "oauth client not found")
+
+// OAuthError is an error response as defined by RFC 6749 (and RFC 6750 for
+// invalid_token and insufficient_scope). Code is sent as the error field.
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+func oauthError(code string, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description}
+}
+
+type OAuthUsecase interface {
+	RegisterClient(request model.CreateOAuthClientRequest) (model.OAuthClientCredentials, error)
+	GetAllClients() ([]model.OAuthClient, error)
+	DeleteClient(clientID string) error
+	Authorize(userID int, mfa bool, request model.AuthorizeRequest) (model.AuthorizeResponse, error)
+	Token(request model.TokenRequest) (model.OAuthTokenResponse, error)
+	UserInfo(accessToken string) (model.UserInfo, error)
+	Introspect(request model.IntrospectionRequest) (model.IntrospectionResponse, error)
+}
+
+type oauthUsecase struct {
+	oauthRepository     repository.OAuthRepository
+	userUsecase         UserUsecase
+	refreshTokenUsecase RefreshTokenUsecase
+	tokenRevocation     TokenRevocationUsecase
+	sessionUsecase      SessionUsecase
+	jwtService          service.JWTservice
+	config              config.OAuthConfig
+	accessTokenLifetime time.Duration
+}
+
+// RegisterClient validates the client metadata and returns the generated
+// credentials. Confidential clients get a secret that is shown only here.
+func (ou *oauthUsecase) RegisterClient(request model.CreateOAuthClientRequest) (model.OAuthClientCredentials, error) {
+	// the OAuthError stays reachable with errors.As for the error code
+	if err := validateClientMetadata(request); err != nil {
+		return model.OAuthClientCredentials{}, wrapError(KindValidation, err)
+	}
+
+	clientID, err := security.GenerateRandomToken(16)
+	if err != nil {
+		return model.OAuthClientCredentials{}, err
+	}
+
+	client := model.OAuthClient{
+		ClientID:     clientID,
+		Name:         request.Name,
+		RedirectURIs: request.RedirectURIs,
+		GrantTypes:   request.GrantTypes,
+		Scopes:       request.Scopes,
+		Confidential: request.Confidential,
+	}
+
+	var secret string
+	if client.Confidential {
+		secret, err = security.GenerateRandomToken(32)
+		if err != nil {
+			return model.OAuthClientCredentials{}, err
+		}
+		client.SecretHash = security.HashToken(secret)
+	}
+
+	created, err := ou.oauthRepository.CreateClient(&client)
+	if err != nil {
+		return model.OAuthClientCredentials{}, err
+	}
+
+	return model.OAuthClientCredentials{OAuthClient: *created, ClientSecret: secret}, nil
+}
+
+func validateClientMetadata(request model.CreateOAuthClientRequest) error {
+	for _, grantType := range request.GrantTypes {
+		switch grantType {
+		case GrantTypeAuthorizationCode, GrantTypeRefreshToken, GrantTypeClientCredentials:
+		default:
+			return oauthError("invalid_client_metadata", fmt.Sprintf("unsupported grant type %q", grantType))
+		}
+	}
+
+	if contains(request.GrantTypes, GrantTypeAuthorizationCode) && len(request.RedirectURIs) == 0 {
+		return oauthError("invalid_redirect_uri", "authorization_code clients need at least one redirect uri")
+	}
+	if contains(request.GrantTypes, GrantTypeRefreshToken) && !contains(request.GrantTypes, GrantTypeAuthorizationCode) {
+		return oauthError("invalid_client_metadata", "refresh_token is only available together with authorization_code")
+	}
+	// a public client cannot keep a secret, so it cannot authenticate on its own
+	if contains(request.GrantTypes, GrantTypeClientCredentials) && !request.Confidential {
+		return oauthError("invalid_client_metadata", "client_credentials requires a confidential client")
+	}
+
+	for _, redirectURI := range request.RedirectURIs {
+		parsed, err := url.Parse(redirectURI)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" || parsed.Fragment != "" || strings.ContainsAny(redirectURI, " \t\n") {
+			return oauthError("invalid_redirect_uri", fmt.Sprintf("invalid redirect uri %q", redirectURI))
+		}
+	}
+	for _, scope := range request.Scopes {
+		if scope == "" || strings.ContainsAny(scope, " \t\n") {
+			return oauthError("invalid_client_metadata", fmt.Sprintf("invalid scope %q", scope))
+		}
+	}
+
+	return nil
+}
+
+func (ou *oauthUsecase) GetAllClients() ([]model.OAuthClient, error) {
+	return ou.oauthRepository.GetAllClients()
+}
+
+func (ou *oauthUsecase) DeleteClient(clientID string) error {
+	err := ou.oauthRepository.DeleteClient(clientID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrOAuthClientNotFound
+	}
+	return err
+}
+
+// Authorize issues an authorization code for the signed in user. Only the
+// code flow with PKCE (S256) is supported.
+func (ou *oauthUsecase) Authorize(userID int, mfa bool, request model.AuthorizeRequest) (model.AuthorizeResponse, error) {
+	client, err := ou.oauthRepository.GetClientByClientID(request.ClientID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.AuthorizeResponse{}, oauthError("invalid_client", "unknown client")
+		}
+		return model.AuthorizeResponse{}, err
+	}
+
+	// never redirect to an address that was not registered, the error goes to the user instead
+	if !contains(client.RedirectURIs, request.RedirectURI) {
+		return model.AuthorizeResponse{}, oauthError("invalid_request", "redirect_uri is not registered for the client")
+	}
+
+	response := model.AuthorizeResponse{RedirectURI: request.RedirectURI, State: request.State}
+
+	if request.ResponseType != "code" {
+		return response, oauthError("unsupported_response_type", "only response_type=code is supported")
+	}
+	if !contains(client.GrantTypes, GrantTypeAuthorizationCode) {
+		return response, oauthError("unauthorized_client", "client may not use the authorization code grant")
+	}
+	if request.CodeChallenge == "" || request.CodeChallengeMethod != "S256" {
+		return response, oauthError("invalid_request", "code_challenge with code_challenge_method=S256 is required")
+	}
+
+	scope, err := grantScope(client.Scopes, request.Scope)
+	if err != nil {
+		return response, err
+	}
+
+	code, err := security.GenerateRandomToken(32)
+	if err != nil {
+		return response, err
+	}
+
+	err = ou.oauthRepository.CreateAuthorizationCode(&model.AuthorizationCode{
+		CodeHash:      security.HashToken(code),
+		ClientID:      client.ClientID,
+		UserID:        userID,
+		RedirectURI:   request.RedirectURI,
+		Scope:         scope,
+		CodeChallenge: request.CodeChallenge,
+		Nonce:         request.Nonce,
+		Mfa:           mfa,
+		ExpiresAt:     time.Now().Add(ou.config.AuthorizationCodeLifetime),
+	})
+	if err != nil {
+		return response, err
+	}
+
+	response.Code = code
+	return response, nil
+}
+
+// Token implements the token endpoint for the authorization_code,
+// refresh_token and client_credentials grants.
+func (ou *oauthUsecase) Token(request model.TokenRequest) (model.OAuthTokenResponse, error) {
+	switch request.GrantType {
+	case GrantTypeAuthorizationCode, GrantTypeRefreshToken, GrantTypeClientCredentials:
+	default:
+		return model.OAuthTokenResponse{}, oauthError("unsupported_grant_type", fmt.Sprintf("unsupported grant type %q", request.GrantType))
+	}
+
+	client, err := ou.authenticateClient(request.ClientID, request.ClientSecret)
+	if err != nil {
+		return model.OAuthTokenResponse{}, err
+	}
+
+	if !contains(client.GrantTypes, request.GrantType) {
+		return model.OAuthTokenResponse{}, oauthError("unauthorized_client", "client may not use this grant type")
+	}
+
+	switch request.GrantType {
+	case GrantTypeAuthorizationCode:
+		return ou.authorizationCodeGrant(client, request)
+	case GrantTypeRefreshToken:
+		return ou.refreshTokenGrant(client, request)
+	default:
+		return ou.clientCredentialsGrant(client, request)
+	}
+}
+
+func (ou *oauthUsecase) authenticateClient(clientID string, clientSecret string) (model.OAuthClient, error) {
+	client, err := ou.oauthRepository.GetClientByClientID(clientID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.OAuthClient{}, oauthError("invalid_client", "client authentication failed")
+		}
+		return model.OAuthClient{}, err
+	}
+
+	if client.Confidential {
+		hash := security.HashToken(clientSecret)
+		if clientSecret == "" || subtle.ConstantTimeCompare([]byte(hash), []byte(client.SecretHash)) != 1 {
+			return model.OAuthClient{}, oauthError("invalid_client", "client authentication failed")
+		}
+	}
+
+	return client, nil
+}
+
+func (ou *oauthUsecase) authorizationCodeGrant(client model.OAuthClient, request model.TokenRequest) (model.OAuthTokenResponse, error) {
+	if request.Code == "" || request.CodeVerifier == "" {
+		return model.OAuthTokenResponse{}, oauthError("invalid_request", "code and code_verifier are required")
+	}
+
+	code, err := ou.oauthRepository.ConsumeAuthorizationCode(security.HashToken(request.Code))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.OAuthTokenResponse{}, oauthError("invalid_grant", "invalid authorization code")
+		}
+		return model.OAuthTokenResponse{}, err
+	}
+
+	if code.ClientID != client.ClientID || code.RedirectURI != request.RedirectURI || time.Now().After(code.ExpiresAt) {
+		return model.OAuthTokenResponse{}, oauthError("invalid_grant", "invalid authorization code")
+	}
+	if !security.VerifyPKCE(request.CodeVerifier, code.CodeChallenge) {
+		return model.OAuthTokenResponse{}, oauthError("invalid_grant", "code_verifier does not match the code_challenge")
+	}
+
+	user, err := ou.activeUser(code.UserID)
+	if err != nil {
+		return model.OAuthTokenResponse{}, err
+	}
+
+	response := ou.userTokenResponse(client, user, code.Scope, code.Mfa)
+	if hasScope(code.Scope, ScopeOpenID) {
+		response.IDToken = ou.idToken(client, user, code.Nonce)
+	}
+
+	if contains(client.GrantTypes, GrantTypeRefreshToken) {
+		response.RefreshToken, err = ou.refreshTokenUsecase.IssueForClient(user.ID, client.ClientID, code.Scope, code.Mfa)
+		if err != nil {
+			return model.OAuthTokenResponse{}, err
+		}
+	}
+
+	return response, nil
+}
+
+// refreshTokenGrant rotates the refresh token. The client may ask for a
+// narrower scope, the refresh token itself keeps the original one.
+func (ou *oauthUsecase) refreshTokenGrant(client model.OAuthClient, request model.TokenRequest) (model.OAuthTokenResponse, error) {
+	if request.RefreshToken == "" {
+		return model.OAuthTokenResponse{}, oauthError("invalid_request", "refresh_token is required")
+	}
+
+	previous, next, err := ou.refreshTokenUsecase.Rotate(request.RefreshToken, client.ClientID)
+	if err != nil {
+		if errors.Is(err, ErrInvalidRefreshToken) || errors.Is(err, ErrRefreshTokenReused) {
+			return model.OAuthTokenResponse{}, oauthError("invalid_grant", err.Error())
+		}
+		return model.OAuthTokenResponse{}, err
+	}
+
+	scope := previous.Scope
+	if request.Scope != "" {
+		scope, err = grantScope(strings.Fields(previous.Scope), request.Scope)
+		if err != nil {
+			return model.OAuthTokenResponse{}, err
+		}
+	}
+
+	user, err := ou.activeUser(previous.UserID)
+	if err != nil {
+		return model.OAuthTokenResponse{}, err
+	}
+
+	response := ou.userTokenResponse(client, user, scope, previous.Mfa)
+	response.RefreshToken = next
+	return response, nil
+}
+
+// clientCredentialsGrant issues a token for the client itself, without a
+// user. The subject is the client id.
+func (ou *oauthUsecase) clientCredentialsGrant(client model.OAuthClient, request model.TokenRequest) (model.OAuthTokenResponse, error) {
+	scope, err := grantScope(client.Scopes, request.Scope)
+	if err != nil {
+		return model.OAuthTokenResponse{}, err
+	}
+
+	return model.OAuthTokenResponse{
+		AccessToken: ou.accessToken(modelutils.JwtPayloadClaims{
+			Scope:            scope,
+			ClientID:         client.ClientID,
+			RegisteredClaims: jwt.RegisteredClaims{Subject: client.ClientID},
+		}),
+		TokenType: "Bearer",
+		ExpiresIn: int64(ou.accessTokenLifetime.Seconds()),
+		Scope:     scope,
+	}, nil
+}
+
+// UserInfo returns the claims of the user an OAuth access token was issued
+// to. The token must carry the openid scope.
+func (ou *oauthUsecase) UserInfo(accessToken string) (model.UserInfo, error) {
+	claims, err := ou.jwtService.VerifyTokenWithPurpose(accessToken, service.TokenPurposeOAuthAccess)
+	if err != nil || claims.UserId == 0 {
+		return model.UserInfo{}, oauthError("invalid_token", "invalid access token")
+	}
+	if !hasScope(claims.Scope, ScopeOpenID) {
+		return model.UserInfo{}, oauthError("insufficient_scope", "the openid scope is required")
+	}
+	if claims.ID != "" {
+		revoked, err := ou.tokenRevocation.IsRevoked(claims.ID)
+		if err != nil {
+			return model.UserInfo{}, err
+		}
+		if revoked {
+			return model.UserInfo{}, oauthError("invalid_token", "invalid access token")
+		}
+	}
+
+	user, err := ou.userUsecase.GetUserByID(claims.UserId)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return model.UserInfo{}, oauthError("invalid_token", "invalid access token")
+		}
+		return model.UserInfo{}, err
+	}
+	if user.Disabled {
+		return model.UserInfo{}, oauthError("invalid_token", "invalid access token")
+	}
+
+	return model.UserInfo{
+		Sub:               strconv.Itoa(user.ID),
+		PreferredUsername: user.Username,
+		Role:              user.Role,
+	}, nil
+}
+
+// Introspect implements RFC 7662 for confidential clients. Access tokens of
+// this API and OAuth access tokens are reported as active while they are
+// validly signed, unexpired, not revoked, their session has not ended and
+// their user is still active.
+// Anything else, including refresh tokens, is reported as inactive.
+func (ou *oauthUsecase) Introspect(request model.IntrospectionRequest) (model.IntrospectionResponse, error) {
+	client, err := ou.authenticateClient(request.ClientID, request.ClientSecret)
+	if err != nil {
+		return model.IntrospectionResponse{}, err
+	}
+	// a public client has no secret, so anyone could introspect in its name
+	if !client.Confidential {
+		return model.IntrospectionResponse{}, oauthError("invalid_client", "introspection requires a confidential client")
+	}
+
+	inactive := model.IntrospectionResponse{Active: false}
+
+	claims, err := ou.jwtService.ParseToken(request.Token)
+	if err != nil {
+		return inactive, nil
+	}
+	if claims.Purpose != "" && claims.Purpose != service.TokenPurposeOAuthAccess {
+		return inactive, nil
+	}
+
+	if claims.ID != "" {
+		revoked, err := ou.tokenRevocation.IsRevoked(claims.ID)
+		if err != nil {
+			return model.IntrospectionResponse{}, err
+		}
+		if revoked {
+			return inactive, nil
+		}
+	}
+	// only access tokens of this API belong to a login session
+	if claims.Sid != "" {
+		active, err := ou.sessionUsecase.IsActive(claims.Sid)
+		if err != nil {
+			return model.IntrospectionResponse{}, err
+		}
+		if !active {
+			return inactive, nil
+		}
+	}
+
+	response := model.IntrospectionResponse{
+		Active:    true,
+		Scope:     claims.Scope,
+		ClientID:  claims.ClientID,
+		Role:      claims.Role,
+		TokenType: "Bearer",
+		Sub:       claims.Subject,
+		Iss:       claims.Issuer,
+		Jti:       claims.ID,
+	}
+	if claims.ExpiresAt != nil {
+		response.Exp = claims.ExpiresAt.Unix()
+	}
+	if claims.IssuedAt != nil {
+		response.Iat = claims.IssuedAt.Unix()
+	}
+
+	// client_credentials tokens have no user behind them
+	if claims.UserId != 0 {
+		user, err := ou.userUsecase.GetUserByID(claims.UserId)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return inactive, nil
+			}
+			return model.IntrospectionResponse{}, err
+		}
+		if user.Disabled {
+			return inactive, nil
+		}
+		response.Username = user.Username
+		response.Role = user.Role
+		if response.Sub == "" {
+			response.Sub = strconv.Itoa(user.ID)
+		}
+	}
+
+	return response, nil
+}
+
+func (ou *oauthUsecase) activeUser(userID int) (model.User, error) {
+	user, err := ou.userUsecase.GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return model.User{}, oauthError("invalid_grant", "user no longer exists")
+		}
+		return model.User{}, err
+	}
+	if user.Disabled {
+		return model.User{}, oauthError("invalid_grant", ErrAccountDisabled.Error())
+	}
+	return user, nil
+}
+
+func (ou *oauthUsecase) userTokenResponse(client model.OAuthClient, user model.User, scope string, mfa bool) model.OAuthTokenResponse {
+	return model.OAuthTokenResponse{
+		AccessToken: ou.accessToken(modelutils.JwtPayloadClaims{
+			UserId:           user.ID,
+			Role:             user.Role,
+			Mfa:              mfa,
+			Scope:            scope,
+			ClientID:         client.ClientID,
+			RegisteredClaims: jwt.RegisteredClaims{Subject: strconv.Itoa(user.ID)},
+		}),
+		TokenType: "Bearer",
+		ExpiresIn: int64(ou.accessTokenLifetime.Seconds()),
+		Scope:     scope,
+	}
+}
+
+func (ou *oauthUsecase) accessToken(claims modelutils.JwtPayloadClaims) string {
+	now := time.Now()
+	claims.Purpose = service.TokenPurposeOAuthAccess
+	claims.Issuer = ou.config.Issuer
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(ou.accessTokenLifetime))
+	return ou.jwtService.CreateTokenWithClaims(claims)
+}
+
+func (ou *oauthUsecase) idToken(client model.OAuthClient, user model.User, nonce string) string {
+	now := time.Now()
+	return ou.jwtService.CreateTokenWithClaims(modelutils.JwtPayloadClaims{
+		Purpose: service.TokenPurposeIDToken,
+		Nonce:   nonce,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    ou.config.Issuer,
+			Subject:   strconv.Itoa(user.ID),
+			Audience:  jwt.ClaimStrings{client.ClientID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ou.accessTokenLifetime)),
+		},
+	})
+}
+
+// grantScope checks the requested space separated scope against the allowed
+// scopes. An empty request grants every allowed scope.
+func grantScope(allowed []string, requested string) (string, error) {
+	if requested == "" {
+		return strings.Join(allowed, " "), nil
+	}
+
+	scopes := strings.Fields(requested)
+	for _, scope := range scopes {
+		if !contains(allowed, scope) {
+			return "", oauthError("invalid_scope", fmt.Sprintf("scope %q is not allowed for the client", scope))
+		}
+	}
+	return strings.Join(scopes, " "), nil
+}
+
+func hasScope(scope string, name string) bool {
+	return contains(strings.Fields(scope), name)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func NewOAuthUsecase(oauthRepository repository.OAuthRepository, userUsecase UserUsecase, refreshTokenUsecase RefreshTokenUsecase, tokenRevocation TokenRevocationUsecase, sessionUsecase SessionUsecase, jwtService service.JWTservice, config config.OAuthConfig, accessTokenLifetime time.Duration) OAuthUsecase {
+	if accessTokenLifetime == 0 {
+		accessTokenLifetime = time.Hour
+	}
+	return &oauthUsecase{
+		oauthRepository:     oauthRepository,
+		userUsecase:         userUsecase,
+		refreshTokenUsecase: refreshTokenUsecase,
+		tokenRevocation:     tokenRevocation,
+		sessionUsecase:      sessionUsecase,
+		jwtService:          jwtService,
+		config:              config,
+		accessTokenLifetime: accessTokenLifetime,
+	}
+}
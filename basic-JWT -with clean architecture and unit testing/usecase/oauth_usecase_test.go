@@ -0,0 +1,432 @@
+package usecase_test
+
+import (
+	"basic-JWT/config"
+	"basic-JWT/mock/service_mock"
+	"basic-JWT/mock/usecase_mock"
+	"basic-JWT/model"
+	"basic-JWT/usecase"
+	modelutils "basic-JWT/utils/model_utils"
+	"basic-JWT/utils/security"
+	"basic-JWT/utils/service"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	testRedirectURI  = "https://portal.example.com/callback"
+	testCodeVerifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+)
+
+type oauthUcSuite struct {
+	suite.Suite
+	oauthRepo       *usecase_mock.OAuthRepositoryMock
+	userUsecase     *usecase_mock.UserUseCaseMock
+	refreshTokenUc  *usecase_mock.RefreshTokenUsecaseMock
+	tokenRevocation *usecase_mock.TokenRevocationUsecaseMock
+	sessionUc       *usecase_mock.SessionUsecaseMock
+	jwtService      *service_mock.JWTServiceMock
+	oauthUc         usecase.OAuthUsecase
+	portal          model.OAuthClient
+	reporting       model.OAuthClient
+}
+
+func TestOAuthUcSuite(t *testing.T) {
+	suite.Run(t, new(oauthUcSuite))
+}
+
+func (o *oauthUcSuite) SetupTest() {
+	o.oauthRepo = new(usecase_mock.OAuthRepositoryMock)
+	o.userUsecase = new(usecase_mock.UserUseCaseMock)
+	o.refreshTokenUc = new(usecase_mock.RefreshTokenUsecaseMock)
+	o.tokenRevocation = new(usecase_mock.TokenRevocationUsecaseMock)
+	o.sessionUc = new(usecase_mock.SessionUsecaseMock)
+	o.jwtService = new(service_mock.JWTServiceMock)
+	o.oauthUc = usecase.NewOAuthUsecase(o.oauthRepo, o.userUsecase, o.refreshTokenUc, o.tokenRevocation, o.sessionUc, o.jwtService,
+		config.OAuthConfig{Issuer: "https://auth.example.com", AuthorizationCodeLifetime: time.Minute}, time.Hour)
+
+	o.portal = model.OAuthClient{
+		ClientID:     "portal",
+		RedirectURIs: []string{testRedirectURI},
+		GrantTypes:   []string{usecase.GrantTypeAuthorizationCode, usecase.GrantTypeRefreshToken},
+		Scopes:       []string{"openid", "profile"},
+	}
+	o.reporting = model.OAuthClient{
+		ClientID:     "reporting",
+		SecretHash:   security.HashToken("secret"),
+		GrantTypes:   []string{usecase.GrantTypeClientCredentials},
+		Scopes:       []string{"reports:read"},
+		Confidential: true,
+	}
+	o.oauthRepo.On("GetClientByClientID", "portal").Return(o.portal, nil)
+	o.oauthRepo.On("GetClientByClientID", "reporting").Return(o.reporting, nil)
+	o.oauthRepo.On("GetClientByClientID", mock.Anything).Return(model.OAuthClient{}, sql.ErrNoRows)
+}
+
+func (o *oauthUcSuite) requireOAuthError(err error, code string) {
+	var oauthErr *usecase.OAuthError
+	o.Require().ErrorAs(err, &oauthErr)
+	o.Equal(code, oauthErr.Code)
+}
+
+func (o *oauthUcSuite) TestRegisterClient_Confidential() {
+	var stored *model.OAuthClient
+	o.oauthRepo.On("CreateClient", mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*model.OAuthClient)
+	}).Return(&model.OAuthClient{ID: 1}, nil).Once()
+
+	credentials, err := o.oauthUc.RegisterClient(model.CreateOAuthClientRequest{
+		Name:         "Reporting",
+		GrantTypes:   []string{usecase.GrantTypeClientCredentials},
+		Scopes:       []string{"reports:read"},
+		Confidential: true,
+	})
+	o.NoError(err)
+	o.NotEmpty(credentials.ClientSecret)
+	o.NotEmpty(stored.ClientID)
+	o.Equal(security.HashToken(credentials.ClientSecret), stored.SecretHash)
+}
+
+func (o *oauthUcSuite) TestRegisterClient_PublicClientCredentials() {
+	_, err := o.oauthUc.RegisterClient(model.CreateOAuthClientRequest{
+		Name:       "Reporting",
+		GrantTypes: []string{usecase.GrantTypeClientCredentials},
+	})
+	o.requireOAuthError(err, "invalid_client_metadata")
+	o.oauthRepo.AssertNotCalled(o.T(), "CreateClient", mock.Anything)
+}
+
+func (o *oauthUcSuite) TestRegisterClient_InvalidRedirectURI() {
+	_, err := o.oauthUc.RegisterClient(model.CreateOAuthClientRequest{
+		Name:         "Portal",
+		GrantTypes:   []string{usecase.GrantTypeAuthorizationCode},
+		RedirectURIs: []string{"/callback"},
+	})
+	o.requireOAuthError(err, "invalid_redirect_uri")
+}
+
+func (o *oauthUcSuite) TestDeleteClient_NotFound() {
+	o.oauthRepo.On("DeleteClient", "ghost").Return(sql.ErrNoRows)
+
+	err := o.oauthUc.DeleteClient("ghost")
+	o.ErrorIs(err, usecase.ErrOAuthClientNotFound)
+}
+
+func (o *oauthUcSuite) authorizeRequest() model.AuthorizeRequest {
+	return model.AuthorizeRequest{
+		ResponseType:        "code",
+		ClientID:            "portal",
+		RedirectURI:         testRedirectURI,
+		Scope:               "openid",
+		State:               "xyz",
+		Nonce:               "n-0S6",
+		CodeChallenge:       security.PKCEChallengeS256(testCodeVerifier),
+		CodeChallengeMethod: "S256",
+	}
+}
+
+func (o *oauthUcSuite) TestAuthorize_Success() {
+	var stored *model.AuthorizationCode
+	o.oauthRepo.On("CreateAuthorizationCode", mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*model.AuthorizationCode)
+	}).Return(nil).Once()
+
+	response, err := o.oauthUc.Authorize(1, true, o.authorizeRequest())
+	o.NoError(err)
+	o.Equal(testRedirectURI, response.RedirectURI)
+	o.Equal("xyz", response.State)
+	o.NotEmpty(response.Code)
+	o.Equal(security.HashToken(response.Code), stored.CodeHash)
+	o.Equal("openid", stored.Scope)
+	o.True(stored.Mfa)
+}
+
+func (o *oauthUcSuite) TestAuthorize_UnregisteredRedirectURI() {
+	request := o.authorizeRequest()
+	request.RedirectURI = "https://evil.example.com/callback"
+
+	response, err := o.oauthUc.Authorize(1, false, request)
+	o.requireOAuthError(err, "invalid_request")
+	o.Empty(response.RedirectURI)
+}
+
+func (o *oauthUcSuite) TestAuthorize_MissingPKCE() {
+	request := o.authorizeRequest()
+	request.CodeChallengeMethod = "plain"
+
+	response, err := o.oauthUc.Authorize(1, false, request)
+	o.requireOAuthError(err, "invalid_request")
+	o.Equal(testRedirectURI, response.RedirectURI)
+}
+
+func (o *oauthUcSuite) TestAuthorize_InvalidScope() {
+	request := o.authorizeRequest()
+	request.Scope = "openid admin"
+
+	_, err := o.oauthUc.Authorize(1, false, request)
+	o.requireOAuthError(err, "invalid_scope")
+}
+
+func (o *oauthUcSuite) code() model.AuthorizationCode {
+	return model.AuthorizationCode{
+		ClientID:      "portal",
+		UserID:        1,
+		RedirectURI:   testRedirectURI,
+		Scope:         "openid",
+		CodeChallenge: security.PKCEChallengeS256(testCodeVerifier),
+		Nonce:         "n-0S6",
+		ExpiresAt:     time.Now().Add(time.Minute),
+	}
+}
+
+func (o *oauthUcSuite) TestToken_AuthorizationCode() {
+	o.oauthRepo.On("ConsumeAuthorizationCode", security.HashToken("code")).Return(o.code(), nil)
+	o.userUsecase.On("GetUserByID", 1).Return(model.User{ID: 1, Username: "alice", Role: "user"}, nil)
+	o.jwtService.On("CreateTokenWithClaims", mock.MatchedBy(func(claims modelutils.JwtPayloadClaims) bool {
+		return claims.Purpose == service.TokenPurposeOAuthAccess && claims.ClientID == "portal" && claims.Subject == "1" && claims.Issuer == "https://auth.example.com"
+	})).Return("access")
+	o.jwtService.On("CreateTokenWithClaims", mock.MatchedBy(func(claims modelutils.JwtPayloadClaims) bool {
+		return claims.Purpose == service.TokenPurposeIDToken && claims.Nonce == "n-0S6" && claims.Audience[0] == "portal"
+	})).Return("id")
+	o.refreshTokenUc.On("IssueForClient", 1, "portal", "openid", false).Return("refresh", nil)
+
+	response, err := o.oauthUc.Token(model.TokenRequest{
+		GrantType:    usecase.GrantTypeAuthorizationCode,
+		ClientID:     "portal",
+		Code:         "code",
+		RedirectURI:  testRedirectURI,
+		CodeVerifier: testCodeVerifier,
+	})
+	o.NoError(err)
+	o.Equal("access", response.AccessToken)
+	o.Equal("id", response.IDToken)
+	o.Equal("refresh", response.RefreshToken)
+	o.Equal("Bearer", response.TokenType)
+	o.Equal(int64(3600), response.ExpiresIn)
+}
+
+func (o *oauthUcSuite) TestToken_AuthorizationCode_WrongVerifier() {
+	o.oauthRepo.On("ConsumeAuthorizationCode", security.HashToken("code")).Return(o.code(), nil)
+
+	_, err := o.oauthUc.Token(model.TokenRequest{
+		GrantType:    usecase.GrantTypeAuthorizationCode,
+		ClientID:     "portal",
+		Code:         "code",
+		RedirectURI:  testRedirectURI,
+		CodeVerifier: "wrong-verifier-wrong-verifier-wrong-verifier",
+	})
+	o.requireOAuthError(err, "invalid_grant")
+	o.jwtService.AssertNotCalled(o.T(), "CreateTokenWithClaims", mock.Anything)
+}
+
+func (o *oauthUcSuite) TestToken_AuthorizationCode_Reused() {
+	o.oauthRepo.On("ConsumeAuthorizationCode", security.HashToken("code")).Return(model.AuthorizationCode{}, sql.ErrNoRows)
+
+	_, err := o.oauthUc.Token(model.TokenRequest{
+		GrantType:    usecase.GrantTypeAuthorizationCode,
+		ClientID:     "portal",
+		Code:         "code",
+		RedirectURI:  testRedirectURI,
+		CodeVerifier: testCodeVerifier,
+	})
+	o.requireOAuthError(err, "invalid_grant")
+}
+
+func (o *oauthUcSuite) TestToken_AuthorizationCode_DisabledUser() {
+	o.oauthRepo.On("ConsumeAuthorizationCode", security.HashToken("code")).Return(o.code(), nil)
+	o.userUsecase.On("GetUserByID", 1).Return(model.User{ID: 1, Username: "alice", Disabled: true}, nil)
+
+	_, err := o.oauthUc.Token(model.TokenRequest{
+		GrantType:    usecase.GrantTypeAuthorizationCode,
+		ClientID:     "portal",
+		Code:         "code",
+		RedirectURI:  testRedirectURI,
+		CodeVerifier: testCodeVerifier,
+	})
+	o.requireOAuthError(err, "invalid_grant")
+}
+
+func (o *oauthUcSuite) TestToken_RefreshToken() {
+	o.refreshTokenUc.On("Rotate", "old", "portal").Return(model.RefreshToken{UserID: 1, ClientID: "portal", Scope: "openid profile"}, "new", nil)
+	o.userUsecase.On("GetUserByID", 1).Return(model.User{ID: 1, Username: "alice", Role: "user"}, nil)
+	o.jwtService.On("CreateTokenWithClaims", mock.MatchedBy(func(claims modelutils.JwtPayloadClaims) bool {
+		return claims.Scope == "profile"
+	})).Return("access")
+
+	response, err := o.oauthUc.Token(model.TokenRequest{GrantType: usecase.GrantTypeRefreshToken, ClientID: "portal", RefreshToken: "old", Scope: "profile"})
+	o.NoError(err)
+	o.Equal("access", response.AccessToken)
+	o.Equal("new", response.RefreshToken)
+	o.Equal("profile", response.Scope)
+}
+
+func (o *oauthUcSuite) TestToken_RefreshToken_Reused() {
+	o.refreshTokenUc.On("Rotate", "old", "portal").Return(model.RefreshToken{}, "", usecase.ErrRefreshTokenReused)
+
+	_, err := o.oauthUc.Token(model.TokenRequest{GrantType: usecase.GrantTypeRefreshToken, ClientID: "portal", RefreshToken: "old"})
+	o.requireOAuthError(err, "invalid_grant")
+}
+
+func (o *oauthUcSuite) TestToken_ClientCredentials() {
+	o.jwtService.On("CreateTokenWithClaims", mock.MatchedBy(func(claims modelutils.JwtPayloadClaims) bool {
+		return claims.UserId == 0 && claims.Subject == "reporting" && claims.Scope == "reports:read"
+	})).Return("access")
+
+	response, err := o.oauthUc.Token(model.TokenRequest{GrantType: usecase.GrantTypeClientCredentials, ClientID: "reporting", ClientSecret: "secret"})
+	o.NoError(err)
+	o.Equal("access", response.AccessToken)
+	o.Empty(response.RefreshToken)
+}
+
+func (o *oauthUcSuite) TestToken_ClientCredentials_WrongSecret() {
+	_, err := o.oauthUc.Token(model.TokenRequest{GrantType: usecase.GrantTypeClientCredentials, ClientID: "reporting", ClientSecret: "wrong"})
+	o.requireOAuthError(err, "invalid_client")
+}
+
+func (o *oauthUcSuite) TestToken_UnknownClient() {
+	_, err := o.oauthUc.Token(model.TokenRequest{GrantType: usecase.GrantTypeClientCredentials, ClientID: "ghost", ClientSecret: "secret"})
+	o.requireOAuthError(err, "invalid_client")
+}
+
+func (o *oauthUcSuite) TestToken_GrantNotAllowedForClient() {
+	_, err := o.oauthUc.Token(model.TokenRequest{GrantType: usecase.GrantTypeClientCredentials, ClientID: "portal"})
+	o.requireOAuthError(err, "unauthorized_client")
+}
+
+func (o *oauthUcSuite) TestToken_UnsupportedGrantType() {
+	_, err := o.oauthUc.Token(model.TokenRequest{GrantType: "password", ClientID: "portal"})
+	o.requireOAuthError(err, "unsupported_grant_type")
+}
+
+func (o *oauthUcSuite) TestUserInfo_Success() {
+	o.jwtService.On("VerifyTokenWithPurpose", "access", service.TokenPurposeOAuthAccess).Return(&modelutils.JwtPayloadClaims{UserId: 1, Scope: "openid"}, nil)
+	o.userUsecase.On("GetUserByID", 1).Return(model.User{ID: 1, Username: "alice", Role: "user"}, nil)
+
+	info, err := o.oauthUc.UserInfo("access")
+	o.NoError(err)
+	o.Equal(model.UserInfo{Sub: "1", PreferredUsername: "alice", Role: "user"}, info)
+}
+
+func (o *oauthUcSuite) TestUserInfo_MissingScope() {
+	o.jwtService.On("VerifyTokenWithPurpose", "access", service.TokenPurposeOAuthAccess).Return(&modelutils.JwtPayloadClaims{UserId: 1, Scope: "profile"}, nil)
+
+	_, err := o.oauthUc.UserInfo("access")
+	o.requireOAuthError(err, "insufficient_scope")
+}
+
+func (o *oauthUcSuite) TestUserInfo_InvalidToken() {
+	o.jwtService.On("VerifyTokenWithPurpose", "bad", service.TokenPurposeOAuthAccess).Return(&modelutils.JwtPayloadClaims{}, errors.New("invalid token"))
+
+	_, err := o.oauthUc.UserInfo("bad")
+	o.requireOAuthError(err, "invalid_token")
+}
+
+func (o *oauthUcSuite) TestUserInfo_RevokedToken() {
+	claims := &modelutils.JwtPayloadClaims{UserId: 1, Scope: "openid"}
+	claims.ID = "jti"
+	o.jwtService.On("VerifyTokenWithPurpose", "access", service.TokenPurposeOAuthAccess).Return(claims, nil)
+	o.tokenRevocation.On("IsRevoked", "jti").Return(true, nil)
+
+	_, err := o.oauthUc.UserInfo("access")
+	o.requireOAuthError(err, "invalid_token")
+}
+
+func (o *oauthUcSuite) TestIntrospect_ActiveUserToken() {
+	claims := &modelutils.JwtPayloadClaims{UserId: 1, Role: "user"}
+	claims.ID = "jti"
+	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Hour))
+	o.jwtService.On("ParseToken", "access").Return(claims, nil)
+	o.tokenRevocation.On("IsRevoked", "jti").Return(false, nil)
+	o.userUsecase.On("GetUserByID", 1).Return(model.User{ID: 1, Username: "alice", Role: "user"}, nil)
+
+	response, err := o.oauthUc.Introspect(model.IntrospectionRequest{Token: "access", ClientID: "reporting", ClientSecret: "secret"})
+	o.NoError(err)
+	o.True(response.Active)
+	o.Equal("alice", response.Username)
+	o.Equal("1", response.Sub)
+	o.Equal("jti", response.Jti)
+	o.Equal(claims.ExpiresAt.Unix(), response.Exp)
+}
+
+func (o *oauthUcSuite) TestIntrospect_ClientCredentialsToken() {
+	claims := &modelutils.JwtPayloadClaims{Purpose: service.TokenPurposeOAuthAccess, ClientID: "reporting", Scope: "reports:read"}
+	claims.Subject = "reporting"
+	o.jwtService.On("ParseToken", "access").Return(claims, nil)
+
+	response, err := o.oauthUc.Introspect(model.IntrospectionRequest{Token: "access", ClientID: "reporting", ClientSecret: "secret"})
+	o.NoError(err)
+	o.True(response.Active)
+	o.Equal("reporting", response.Sub)
+	o.Equal("reports:read", response.Scope)
+	o.userUsecase.AssertNotCalled(o.T(), "GetUserByID", mock.Anything)
+}
+
+func (o *oauthUcSuite) TestIntrospect_RevokedToken() {
+	claims := &modelutils.JwtPayloadClaims{UserId: 1}
+	claims.ID = "jti"
+	o.jwtService.On("ParseToken", "access").Return(claims, nil)
+	o.tokenRevocation.On("IsRevoked", "jti").Return(true, nil)
+
+	response, err := o.oauthUc.Introspect(model.IntrospectionRequest{Token: "access", ClientID: "reporting", ClientSecret: "secret"})
+	o.NoError(err)
+	o.Equal(model.IntrospectionResponse{Active: false}, response)
+}
+
+func (o *oauthUcSuite) TestIntrospect_EndedSession() {
+	// the user logged out, or an admin ended the session
+	o.jwtService.On("ParseToken", "access").Return(&modelutils.JwtPayloadClaims{UserId: 1, Role: "user", Sid: "sid"}, nil)
+	o.sessionUc.On("IsActive", "sid").Return(false, nil).Once()
+
+	response, err := o.oauthUc.Introspect(model.IntrospectionRequest{Token: "access", ClientID: "reporting", ClientSecret: "secret"})
+	o.NoError(err)
+	o.Equal(model.IntrospectionResponse{Active: false}, response)
+	o.userUsecase.AssertNotCalled(o.T(), "GetUserByID", mock.Anything)
+}
+
+func (o *oauthUcSuite) TestIntrospect_ActiveSession() {
+	o.jwtService.On("ParseToken", "access").Return(&modelutils.JwtPayloadClaims{UserId: 1, Role: "user", Sid: "sid"}, nil)
+	o.sessionUc.On("IsActive", "sid").Return(true, nil).Once()
+	o.userUsecase.On("GetUserByID", 1).Return(model.User{ID: 1, Username: "alice", Role: "user"}, nil)
+
+	response, err := o.oauthUc.Introspect(model.IntrospectionRequest{Token: "access", ClientID: "reporting", ClientSecret: "secret"})
+	o.NoError(err)
+	o.True(response.Active)
+	o.sessionUc.AssertExpectations(o.T())
+}
+
+func (o *oauthUcSuite) TestIntrospect_InvalidOrOtherPurposeToken() {
+	o.jwtService.On("ParseToken", "bad").Return(&modelutils.JwtPayloadClaims{}, errors.New("invalid token"))
+	o.jwtService.On("ParseToken", "mfa").Return(&modelutils.JwtPayloadClaims{UserId: 1, Purpose: service.TokenPurposeMfa}, nil)
+
+	for _, token := range []string{"bad", "mfa"} {
+		response, err := o.oauthUc.Introspect(model.IntrospectionRequest{Token: token, ClientID: "reporting", ClientSecret: "secret"})
+		o.NoError(err)
+		o.False(response.Active)
+	}
+}
+
+func (o *oauthUcSuite) TestIntrospect_DisabledUser() {
+	o.jwtService.On("ParseToken", "access").Return(&modelutils.JwtPayloadClaims{UserId: 1}, nil)
+	o.userUsecase.On("GetUserByID", 1).Return(model.User{ID: 1, Disabled: true}, nil)
+
+	response, err := o.oauthUc.Introspect(model.IntrospectionRequest{Token: "access", ClientID: "reporting", ClientSecret: "secret"})
+	o.NoError(err)
+	o.False(response.Active)
+}
+
+func (o *oauthUcSuite) TestIntrospect_PublicClient() {
+	_, err := o.oauthUc.Introspect(model.IntrospectionRequest{Token: "access", ClientID: "portal"})
+	o.requireOAuthError(err, "invalid_client")
+	o.jwtService.AssertNotCalled(o.T(), "ParseToken", mock.Anything)
+}
+
+func (o *oauthUcSuite) TestIntrospect_WrongSecret() {
+	_, err := o.oauthUc.Introspect(model.IntrospectionRequest{Token: "access", ClientID: "reporting", ClientSecret: "wrong"})
+	o.requireOAuthError(err, "invalid_client")
+}
@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"basic-JWT/model"
+	"basic-JWT/repository"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// rbacManagePermission guards the RBAC endpoints themselves, so some role
+// must always keep it or nobody could manage roles again.
+const rbacManagePermission = "rbac:manage"
+
+var (
+	ErrRoleInUse          = NewDomainError(KindConflict, "role is still assigned to users")
+	ErrLastRbacManager    = NewDomainError(KindConflict, "at least one role must keep the "+rbacManagePermission+" permission")
+	ErrRoleNotFound       = NewDomainError(KindNotFound, "role not found")
+	ErrPermissionNotFound = NewDomainError(KindNotFound, "permission not found")
+	ErrGrantNotFound      = NewDomainError(KindNotFound, "role does not have this permission")
+	ErrGrantTargetMissing = NewDomainError(KindNotFound, "role or permission not found")
+)
+
+type RbacUsecase interface {
+	CreateRole(role *model.Role) (*model.Role, error)
+	GetAllRoles() ([]model.Role, error)
+	DeleteRole(id int) error
+	CreatePermission(permission *model.Permission) (*model.Permission, error)
+	GetAllPermissions() ([]model.Permission, error)
+	DeletePermission(id int) error
+	GrantPermission(roleID int, permissionID int) error
+	RevokePermission(roleID int, permissionID int) error
+	HasPermission(role string, permission string) (bool, error)
+}
+
+type cachedPermissions struct {
+	permissions map[string]bool
+	expiresAt   time.Time
+}
+
+type rbacUsecase struct {
+	rbacRepository repository.RbacRepository
+	cacheTTL       time.Duration
+
+	mu    sync.RWMutex
+	cache map[string]cachedPermissions
+	// generation changes on every invalidate, so a lookup that read the
+	// database before a change does not cache the stale result after it.
+	generation uint64
+}
+
+func (ru *rbacUsecase) CreateRole(role *model.Role) (*model.Role, error) {
+	created, err := ru.rbacRepository.CreateRole(role)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return nil, NewDomainError(KindConflict, fmt.Sprintf("role '%s' already exists", role.Name))
+	}
+	return created, err
+}
+
+func (ru *rbacUsecase) GetAllRoles() ([]model.Role, error) {
+	return ru.rbacRepository.GetAllRoles()
+}
+
+// DeleteRole refuses to delete a role that users still hold, or the last
+// role granted rbac:manage.
+func (ru *rbacUsecase) DeleteRole(id int) error {
+	users, err := ru.rbacRepository.CountUsersWithRole(id)
+	if err != nil {
+		return err
+	}
+	if users > 0 {
+		return ErrRoleInUse
+	}
+	if err := ru.keepRbacManager(id); err != nil {
+		return err
+	}
+
+	defer ru.invalidate()
+	err = ru.rbacRepository.DeleteRole(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrRoleNotFound
+	}
+	return err
+}
+
+func (ru *rbacUsecase) CreatePermission(permission *model.Permission) (*model.Permission, error) {
+	created, err := ru.rbacRepository.CreatePermission(permission)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return nil, NewDomainError(KindConflict, fmt.Sprintf("permission '%s' already exists", permission.Name))
+	}
+	return created, err
+}
+
+func (ru *rbacUsecase) GetAllPermissions() ([]model.Permission, error) {
+	return ru.rbacRepository.GetAllPermissions()
+}
+
+// DeletePermission refuses to delete rbac:manage while a role holds it.
+func (ru *rbacUsecase) DeletePermission(id int) error {
+	permission, err := ru.rbacRepository.GetPermissionByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrPermissionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if permission.Name == rbacManagePermission {
+		roleIDs, err := ru.rbacRepository.GetRoleIDsWithPermission(rbacManagePermission)
+		if err != nil {
+			return err
+		}
+		if len(roleIDs) > 0 {
+			return ErrLastRbacManager
+		}
+	}
+
+	defer ru.invalidate()
+	err = ru.rbacRepository.DeletePermission(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrPermissionNotFound
+	}
+	return err
+}
+
+func (ru *rbacUsecase) GrantPermission(roleID int, permissionID int) error {
+	defer ru.invalidate()
+	err := ru.rbacRepository.GrantPermission(roleID, permissionID)
+	if errors.Is(err, repository.ErrMissingReference) {
+		return ErrGrantTargetMissing
+	}
+	return err
+}
+
+// RevokePermission refuses to revoke rbac:manage from the last role holding it.
+func (ru *rbacUsecase) RevokePermission(roleID int, permissionID int) error {
+	permission, err := ru.rbacRepository.GetPermissionByID(permissionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrPermissionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if permission.Name == rbacManagePermission {
+		if err := ru.keepRbacManager(roleID); err != nil {
+			return err
+		}
+	}
+
+	defer ru.invalidate()
+	err = ru.rbacRepository.RevokePermission(roleID, permissionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrGrantNotFound
+	}
+	return err
+}
+
+// HasPermission resolves the permissions granted to a role, caching the
+// result for cacheTTL. Any change to roles or grants clears the cache.
+func (ru *rbacUsecase) HasPermission(role string, permission string) (bool, error) {
+	ru.mu.RLock()
+	entry, ok := ru.cache[role]
+	generation := ru.generation
+	ru.mu.RUnlock()
+
+	if !ok || time.Now().After(entry.expiresAt) {
+		names, err := ru.rbacRepository.GetPermissionsByRole(role)
+		if err != nil {
+			return false, err
+		}
+
+		entry = cachedPermissions{permissions: map[string]bool{}, expiresAt: time.Now().Add(ru.cacheTTL)}
+		for _, name := range names {
+			entry.permissions[name] = true
+		}
+
+		ru.mu.Lock()
+		if ru.generation == generation {
+			ru.cache[role] = entry
+		}
+		ru.mu.Unlock()
+	}
+
+	return entry.permissions[permission], nil
+}
+
+// keepRbacManager returns ErrLastRbacManager when roleID is the only role
+// granted rbac:manage.
+func (ru *rbacUsecase) keepRbacManager(roleID int) error {
+	roleIDs, err := ru.rbacRepository.GetRoleIDsWithPermission(rbacManagePermission)
+	if err != nil {
+		return err
+	}
+	if len(roleIDs) == 1 && roleIDs[0] == roleID {
+		return ErrLastRbacManager
+	}
+	return nil
+}
+
+func (ru *rbacUsecase) invalidate() {
+	ru.mu.Lock()
+	ru.cache = map[string]cachedPermissions{}
+	ru.generation++
+	ru.mu.Unlock()
+}
+
+func NewRbacUsecase(rbacRepository repository.RbacRepository, cacheTTL time.Duration) RbacUsecase {
+	return &rbacUsecase{
+		rbacRepository: rbacRepository,
+		cacheTTL:       cacheTTL,
+		cache:          map[string]cachedPermissions{},
+	}
+}
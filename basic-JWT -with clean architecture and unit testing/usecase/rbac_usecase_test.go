@@ -0,0 +1,228 @@
+package usecase_test
+
+import (
+	"basic-JWT/mock/usecase_mock"
+	"basic-JWT/model"
+	"basic-JWT/repository"
+	"basic-JWT/usecase"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type rbacUcSuite struct {
+	suite.Suite
+	rbacRepo *usecase_mock.RbacRepositoryMock
+	rbacUc   usecase.RbacUsecase
+}
+
+func TestRbacUcSuite(t *testing.T) {
+	suite.Run(t, new(rbacUcSuite))
+}
+
+func (r *rbacUcSuite) SetupTest() {
+	r.rbacRepo = new(usecase_mock.RbacRepositoryMock)
+	r.rbacUc = usecase.NewRbacUsecase(r.rbacRepo, time.Minute)
+}
+
+func (r *rbacUcSuite) TestHasPermission_Granted() {
+	r.rbacRepo.On("GetPermissionsByRole", "admin").Return([]string{"users:create", "users:read"}, nil)
+
+	allowed, err := r.rbacUc.HasPermission("admin", "users:create")
+	r.NoError(err)
+	r.True(allowed)
+}
+
+func (r *rbacUcSuite) TestHasPermission_NotGranted() {
+	r.rbacRepo.On("GetPermissionsByRole", "user").Return([]string{"users:read"}, nil)
+
+	allowed, err := r.rbacUc.HasPermission("user", "users:create")
+	r.NoError(err)
+	r.False(allowed)
+}
+
+func (r *rbacUcSuite) TestHasPermission_Cached() {
+	r.rbacRepo.On("GetPermissionsByRole", "admin").Return([]string{"users:read"}, nil).Once()
+
+	r.rbacUc.HasPermission("admin", "users:read")
+	allowed, err := r.rbacUc.HasPermission("admin", "users:read")
+	r.NoError(err)
+	r.True(allowed)
+	r.rbacRepo.AssertNumberOfCalls(r.T(), "GetPermissionsByRole", 1)
+}
+
+func (r *rbacUcSuite) TestHasPermission_ExpiredCacheReloads() {
+	r.rbacUc = usecase.NewRbacUsecase(r.rbacRepo, 0)
+	r.rbacRepo.On("GetPermissionsByRole", "admin").Return([]string{"users:read"}, nil)
+
+	r.rbacUc.HasPermission("admin", "users:read")
+	r.rbacUc.HasPermission("admin", "users:read")
+	r.rbacRepo.AssertNumberOfCalls(r.T(), "GetPermissionsByRole", 2)
+}
+
+func (r *rbacUcSuite) TestGrantPermission_InvalidatesCache() {
+	r.rbacRepo.On("GetPermissionsByRole", "user").Return([]string{}, nil).Once()
+	r.rbacRepo.On("GrantPermission", 2, 5).Return(nil)
+	r.rbacRepo.On("GetPermissionsByRole", "user").Return([]string{"users:create"}, nil).Once()
+
+	allowed, _ := r.rbacUc.HasPermission("user", "users:create")
+	r.False(allowed)
+
+	err := r.rbacUc.GrantPermission(2, 5)
+	r.NoError(err)
+
+	allowed, _ = r.rbacUc.HasPermission("user", "users:create")
+	r.True(allowed)
+}
+
+func (r *rbacUcSuite) TestRevokePermission_InvalidatesCache() {
+	r.rbacRepo.On("GetPermissionsByRole", "user").Return([]string{"users:create"}, nil).Once()
+	r.rbacRepo.On("GetPermissionByID", 5).Return(model.Permission{ID: 5, Name: "users:create"}, nil)
+	r.rbacRepo.On("RevokePermission", 2, 5).Return(nil)
+	r.rbacRepo.On("GetPermissionsByRole", "user").Return([]string{}, nil).Once()
+
+	allowed, _ := r.rbacUc.HasPermission("user", "users:create")
+	r.True(allowed)
+
+	r.NoError(r.rbacUc.RevokePermission(2, 5))
+
+	allowed, _ = r.rbacUc.HasPermission("user", "users:create")
+	r.False(allowed)
+}
+
+func (r *rbacUcSuite) TestHasPermission_ChangeDuringLookupIsNotCached() {
+	r.rbacRepo.On("GetPermissionsByRole", "user").Return([]string{"users:create"}, nil).Once().
+		Run(func(mock.Arguments) {
+			// a grant is revoked while the old permissions are being read
+			r.rbacRepo.On("GetPermissionByID", 5).Return(model.Permission{ID: 5, Name: "users:create"}, nil)
+			r.rbacRepo.On("RevokePermission", 2, 5).Return(nil)
+			r.NoError(r.rbacUc.RevokePermission(2, 5))
+		})
+	r.rbacRepo.On("GetPermissionsByRole", "user").Return([]string{}, nil).Once()
+
+	allowed, _ := r.rbacUc.HasPermission("user", "users:create")
+	r.True(allowed)
+
+	allowed, _ = r.rbacUc.HasPermission("user", "users:create")
+	r.False(allowed)
+}
+
+func (r *rbacUcSuite) TestHasPermission_Failed() {
+	r.rbacRepo.On("GetPermissionsByRole", "admin").Return([]string{}, errors.New("error"))
+
+	_, err := r.rbacUc.HasPermission("admin", "users:read")
+	r.Error(err)
+}
+
+func (r *rbacUcSuite) TestCreateRole_Duplicate() {
+	role := &model.Role{Name: "admin"}
+	r.rbacRepo.On("CreateRole", role).Return(nil, repository.ErrDuplicate)
+
+	_, err := r.rbacUc.CreateRole(role)
+	r.ErrorIs(err, usecase.ErrConflict)
+}
+
+func (r *rbacUcSuite) TestCreatePermission_Duplicate() {
+	permission := &model.Permission{Name: "users:read"}
+	r.rbacRepo.On("CreatePermission", permission).Return(nil, repository.ErrDuplicate)
+
+	_, err := r.rbacUc.CreatePermission(permission)
+	r.ErrorIs(err, usecase.ErrConflict)
+}
+
+func (r *rbacUcSuite) TestGrantPermission_MissingRoleOrPermission() {
+	r.rbacRepo.On("GrantPermission", 2, 99).Return(repository.ErrMissingReference)
+
+	err := r.rbacUc.GrantPermission(2, 99)
+	r.ErrorIs(err, usecase.ErrNotFound)
+}
+
+func (r *rbacUcSuite) TestRevokePermission_NotGranted() {
+	r.rbacRepo.On("GetPermissionByID", 5).Return(model.Permission{ID: 5, Name: "users:create"}, nil)
+	r.rbacRepo.On("RevokePermission", 2, 5).Return(sql.ErrNoRows)
+
+	err := r.rbacUc.RevokePermission(2, 5)
+	r.ErrorIs(err, usecase.ErrNotFound)
+}
+
+func (r *rbacUcSuite) TestDeleteRole_NotFound() {
+	r.rbacRepo.On("CountUsersWithRole", 99).Return(0, nil)
+	r.rbacRepo.On("GetRoleIDsWithPermission", "rbac:manage").Return([]int{1}, nil)
+	r.rbacRepo.On("DeleteRole", 99).Return(sql.ErrNoRows)
+
+	err := r.rbacUc.DeleteRole(99)
+	r.ErrorIs(err, usecase.ErrNotFound)
+}
+
+func (r *rbacUcSuite) TestDeletePermission_NotFound() {
+	r.rbacRepo.On("GetPermissionByID", 99).Return(model.Permission{}, sql.ErrNoRows)
+
+	err := r.rbacUc.DeletePermission(99)
+	r.ErrorIs(err, usecase.ErrNotFound)
+	r.rbacRepo.AssertNotCalled(r.T(), "DeletePermission", 99)
+}
+
+func (r *rbacUcSuite) TestDeleteRole_Success() {
+	r.rbacRepo.On("CountUsersWithRole", 3).Return(0, nil)
+	r.rbacRepo.On("GetRoleIDsWithPermission", "rbac:manage").Return([]int{1}, nil)
+	r.rbacRepo.On("DeleteRole", 3).Return(nil)
+
+	err := r.rbacUc.DeleteRole(3)
+	r.NoError(err)
+}
+
+func (r *rbacUcSuite) TestDeleteRole_AssignedToUsers() {
+	r.rbacRepo.On("CountUsersWithRole", 2).Return(4, nil)
+
+	err := r.rbacUc.DeleteRole(2)
+	r.ErrorIs(err, usecase.ErrConflict)
+	r.rbacRepo.AssertNotCalled(r.T(), "DeleteRole", 2)
+}
+
+func (r *rbacUcSuite) TestDeleteRole_LastRbacManager() {
+	r.rbacRepo.On("CountUsersWithRole", 1).Return(0, nil)
+	r.rbacRepo.On("GetRoleIDsWithPermission", "rbac:manage").Return([]int{1}, nil)
+
+	err := r.rbacUc.DeleteRole(1)
+	r.ErrorIs(err, usecase.ErrLastRbacManager)
+	r.rbacRepo.AssertNotCalled(r.T(), "DeleteRole", 1)
+}
+
+func (r *rbacUcSuite) TestRevokePermission_LastRbacManager() {
+	r.rbacRepo.On("GetPermissionByID", 3).Return(model.Permission{ID: 3, Name: "rbac:manage"}, nil)
+	r.rbacRepo.On("GetRoleIDsWithPermission", "rbac:manage").Return([]int{1}, nil)
+
+	err := r.rbacUc.RevokePermission(1, 3)
+	r.ErrorIs(err, usecase.ErrLastRbacManager)
+	r.rbacRepo.AssertNotCalled(r.T(), "RevokePermission", 1, 3)
+}
+
+func (r *rbacUcSuite) TestRevokePermission_RbacManageHeldElsewhere() {
+	r.rbacRepo.On("GetPermissionByID", 3).Return(model.Permission{ID: 3, Name: "rbac:manage"}, nil)
+	r.rbacRepo.On("GetRoleIDsWithPermission", "rbac:manage").Return([]int{1, 4}, nil)
+	r.rbacRepo.On("RevokePermission", 1, 3).Return(nil)
+
+	err := r.rbacUc.RevokePermission(1, 3)
+	r.NoError(err)
+}
+
+func (r *rbacUcSuite) TestDeletePermission_RbacManageStillGranted() {
+	r.rbacRepo.On("GetPermissionByID", 3).Return(model.Permission{ID: 3, Name: "rbac:manage"}, nil)
+	r.rbacRepo.On("GetRoleIDsWithPermission", "rbac:manage").Return([]int{1}, nil)
+
+	err := r.rbacUc.DeletePermission(3)
+	r.ErrorIs(err, usecase.ErrConflict)
+	r.rbacRepo.AssertNotCalled(r.T(), "DeletePermission", 3)
+}
+
+func (r *rbacUcSuite) TestDeletePermission_Success() {
+	r.rbacRepo.On("GetPermissionByID", 5).Return(model.Permission{ID: 5, Name: "reports:read"}, nil)
+	r.rbacRepo.On("DeletePermission", 5).Return(nil)
+
+	err := r.rbacUc.DeletePermission(5)
+	r.NoError(err)
+}
@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"basic-JWT/model"
+	"basic-JWT/repository"
+	"basic-JWT/utils/security"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidRefreshToken = NewDomainError(KindUnauthorized, "invalid refresh token")
+	ErrRefreshTokenReused  = NewDomainError(KindUnauthorized, "refresh token reused, token family revoked")
+)
+
+type RefreshTokenUsecase interface {
+	Issue(userID int, familyID string, mfa bool) (string, error)
+	IssueForClient(userID int, clientID string, scope string, mfa bool) (string, error)
+	Rotate(refreshToken string, clientID string) (model.RefreshToken, string, error)
+	Revoke(refreshToken string) error
+	RevokeFamily(familyID string) error
+	RevokeAllForUser(userID int) error
+}
+
+type refreshTokenUsecase struct {
+	refreshTokenRepository repository.RefreshTokenRepository
+	lifetime               time.Duration
+}
+
+// Issue creates a new refresh token for the user. An empty familyID starts a
+// new token family, which is what Login does. mfa records whether the login
+// that started the family passed the TOTP step.
+func (ru *refreshTokenUsecase) Issue(userID int, familyID string, mfa bool) (string, error) {
+	return ru.issue(model.RefreshToken{UserID: userID, FamilyID: familyID, Mfa: mfa})
+}
+
+// IssueForClient starts a token family bound to an OAuth client. Only that
+// client can rotate it, and the scope is carried over on every rotation.
+func (ru *refreshTokenUsecase) IssueForClient(userID int, clientID string, scope string, mfa bool) (string, error) {
+	return ru.issue(model.RefreshToken{UserID: userID, Mfa: mfa, ClientID: clientID, Scope: scope})
+}
+
+func (ru *refreshTokenUsecase) issue(token model.RefreshToken) (string, error) {
+	plain, err := ru.prepare(&token)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = ru.refreshTokenRepository.Create(&token)
+	if err != nil {
+		return "", err
+	}
+
+	return plain, nil
+}
+
+// prepare fills in the family, hash and expiry of a new token and returns
+// the plain token for the client.
+func (ru *refreshTokenUsecase) prepare(token *model.RefreshToken) (string, error) {
+	if token.FamilyID == "" {
+		id, err := security.GenerateRandomToken(16)
+		if err != nil {
+			return "", err
+		}
+		token.FamilyID = id
+	}
+
+	plain, err := security.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	token.TokenHash = security.HashToken(plain)
+	token.ExpiresAt = time.Now().Add(ru.lifetime)
+	return plain, nil
+}
+
+// Rotate consumes the presented refresh token and issues its successor in the
+// same family. Presenting a token that was already used revokes the whole
+// family, since one of the two holders must be an attacker.
+//
+// clientID is empty for the first-party /refresh endpoint. A token issued to
+// another client is rejected without being consumed.
+func (ru *refreshTokenUsecase) Rotate(refreshToken string, clientID string) (model.RefreshToken, string, error) {
+	token, err := ru.find(refreshToken)
+	if err != nil {
+		return model.RefreshToken{}, "", err
+	}
+
+	if token.ClientID != clientID || token.Revoked || time.Now().After(token.ExpiresAt) {
+		return model.RefreshToken{}, "", ErrInvalidRefreshToken
+	}
+
+	if token.Used {
+		return model.RefreshToken{}, "", ru.revokeReused(token)
+	}
+
+	next := model.RefreshToken{
+		UserID:   token.UserID,
+		FamilyID: token.FamilyID,
+		Mfa:      token.Mfa,
+		ClientID: token.ClientID,
+		Scope:    token.Scope,
+	}
+	plain, err := ru.prepare(&next)
+	if err != nil {
+		return model.RefreshToken{}, "", err
+	}
+
+	ok, err := ru.refreshTokenRepository.Rotate(token.ID, &next)
+	if err != nil {
+		return model.RefreshToken{}, "", err
+	}
+	if !ok {
+		return model.RefreshToken{}, "", ru.revokeReused(token)
+	}
+
+	return token, plain, nil
+}
+
+func (ru *refreshTokenUsecase) Revoke(refreshToken string) error {
+	token, err := ru.find(refreshToken)
+	if err != nil {
+		return err
+	}
+	return ru.refreshTokenRepository.RevokeFamily(token.FamilyID)
+}
+
+// RevokeFamily ends the session started by one login, without needing one
+// of its refresh tokens.
+func (ru *refreshTokenUsecase) RevokeFamily(familyID string) error {
+	return ru.refreshTokenRepository.RevokeFamily(familyID)
+}
+
+// RevokeAllForUser ends every session of the user, e.g. after the account is
+// disabled or its password is reset.
+func (ru *refreshTokenUsecase) RevokeAllForUser(userID int) error {
+	return ru.refreshTokenRepository.RevokeAllForUser(userID)
+}
+
+func (ru *refreshTokenUsecase) find(refreshToken string) (model.RefreshToken, error) {
+	token, err := ru.refreshTokenRepository.GetByHash(security.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.RefreshToken{}, ErrInvalidRefreshToken
+		}
+		return model.RefreshToken{}, err
+	}
+	return token, nil
+}
+
+func (ru *refreshTokenUsecase) revokeReused(token model.RefreshToken) error {
+	if err := ru.refreshTokenRepository.RevokeFamily(token.FamilyID); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+func NewRefreshTokenUsecase(refreshTokenRepository repository.RefreshTokenRepository, lifetime time.Duration) RefreshTokenUsecase {
+	return &refreshTokenUsecase{
+		refreshTokenRepository: refreshTokenRepository,
+		lifetime:               lifetime,
+	}
+}
@@ -0,0 +1,164 @@
+package usecase_test
+
+import (
+	"basic-JWT/mock/usecase_mock"
+	"basic-JWT/model"
+	"basic-JWT/usecase"
+	"basic-JWT/utils/security"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type refreshTokenUcSuite struct {
+	suite.Suite
+	repo    *usecase_mock.RefreshTokenRepositoryMock
+	tokenUc usecase.RefreshTokenUsecase
+}
+
+func TestRefreshTokenUcSuite(t *testing.T) {
+	suite.Run(t, new(refreshTokenUcSuite))
+}
+
+func (r *refreshTokenUcSuite) SetupTest() {
+	r.repo = new(usecase_mock.RefreshTokenRepositoryMock)
+	r.tokenUc = usecase.NewRefreshTokenUsecase(r.repo, time.Hour)
+}
+
+func (r *refreshTokenUcSuite) TestIssue_NewFamily() {
+	r.repo.On("Create", mock.MatchedBy(func(t *model.RefreshToken) bool {
+		return t.UserID == 1 && t.FamilyID != "" && t.TokenHash != "" && t.ExpiresAt.After(time.Now())
+	})).Return(&model.RefreshToken{}, nil)
+
+	token, err := r.tokenUc.Issue(1, "", false)
+	r.NoError(err)
+	r.NotEmpty(token)
+}
+
+func (r *refreshTokenUcSuite) TestIssue_StoresHashOnly() {
+	var stored *model.RefreshToken
+	r.repo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*model.RefreshToken)
+	}).Return(&model.RefreshToken{}, nil)
+
+	token, err := r.tokenUc.Issue(1, "family", true)
+	r.NoError(err)
+	r.Equal("family", stored.FamilyID)
+	r.True(stored.Mfa)
+	r.Equal(security.HashToken(token), stored.TokenHash)
+	r.NotEqual(token, stored.TokenHash)
+}
+
+func (r *refreshTokenUcSuite) TestIssue_Failed() {
+	r.repo.On("Create", mock.Anything).Return(nil, errors.New("error"))
+
+	_, err := r.tokenUc.Issue(1, "", false)
+	r.Error(err)
+}
+
+func (r *refreshTokenUcSuite) TestRotate_Success() {
+	current := model.RefreshToken{ID: 1, UserID: 2, FamilyID: "family", Mfa: true, ExpiresAt: time.Now().Add(time.Hour)}
+	r.repo.On("GetByHash", security.HashToken("old")).Return(current, nil)
+	r.repo.On("Rotate", 1, mock.MatchedBy(func(t *model.RefreshToken) bool {
+		return t.UserID == 2 && t.FamilyID == "family" && t.Mfa && t.TokenHash != ""
+	})).Return(true, nil)
+
+	previous, next, err := r.tokenUc.Rotate("old", "")
+	r.NoError(err)
+	r.Equal(2, previous.UserID)
+	r.NotEmpty(next)
+	r.NotEqual("old", next)
+}
+
+func (r *refreshTokenUcSuite) TestIssueForClient_KeepsClientOnRotate() {
+	current := model.RefreshToken{ID: 1, UserID: 2, FamilyID: "family", ClientID: "portal", Scope: "openid", ExpiresAt: time.Now().Add(time.Hour)}
+	r.repo.On("GetByHash", security.HashToken("old")).Return(current, nil)
+	r.repo.On("Rotate", 1, mock.MatchedBy(func(t *model.RefreshToken) bool {
+		return t.FamilyID == "family" && t.ClientID == "portal" && t.Scope == "openid"
+	})).Return(true, nil)
+
+	_, next, err := r.tokenUc.Rotate("old", "portal")
+	r.NoError(err)
+	r.NotEmpty(next)
+}
+
+func (r *refreshTokenUcSuite) TestRotate_OtherClient() {
+	current := model.RefreshToken{ID: 1, UserID: 2, FamilyID: "family", ClientID: "portal", ExpiresAt: time.Now().Add(time.Hour)}
+	r.repo.On("GetByHash", security.HashToken("old")).Return(current, nil)
+
+	_, _, err := r.tokenUc.Rotate("old", "")
+	r.ErrorIs(err, usecase.ErrInvalidRefreshToken)
+	r.repo.AssertNotCalled(r.T(), "Rotate", 1, mock.Anything)
+}
+
+func (r *refreshTokenUcSuite) TestRotate_Unknown() {
+	r.repo.On("GetByHash", security.HashToken("unknown")).Return(model.RefreshToken{}, sql.ErrNoRows)
+
+	_, _, err := r.tokenUc.Rotate("unknown", "")
+	r.ErrorIs(err, usecase.ErrInvalidRefreshToken)
+}
+
+func (r *refreshTokenUcSuite) TestRotate_Expired() {
+	current := model.RefreshToken{ID: 1, FamilyID: "family", ExpiresAt: time.Now().Add(-time.Minute)}
+	r.repo.On("GetByHash", security.HashToken("old")).Return(current, nil)
+
+	_, _, err := r.tokenUc.Rotate("old", "")
+	r.ErrorIs(err, usecase.ErrInvalidRefreshToken)
+}
+
+func (r *refreshTokenUcSuite) TestRotate_Revoked() {
+	current := model.RefreshToken{ID: 1, FamilyID: "family", Revoked: true, ExpiresAt: time.Now().Add(time.Hour)}
+	r.repo.On("GetByHash", security.HashToken("old")).Return(current, nil)
+
+	_, _, err := r.tokenUc.Rotate("old", "")
+	r.ErrorIs(err, usecase.ErrInvalidRefreshToken)
+}
+
+func (r *refreshTokenUcSuite) TestRotate_ReuseRevokesFamily() {
+	current := model.RefreshToken{ID: 1, FamilyID: "family", Used: true, ExpiresAt: time.Now().Add(time.Hour)}
+	r.repo.On("GetByHash", security.HashToken("old")).Return(current, nil)
+	r.repo.On("RevokeFamily", "family").Return(nil)
+
+	_, _, err := r.tokenUc.Rotate("old", "")
+	r.ErrorIs(err, usecase.ErrRefreshTokenReused)
+	r.repo.AssertCalled(r.T(), "RevokeFamily", "family")
+}
+
+func (r *refreshTokenUcSuite) TestRotate_ConcurrentReuseRevokesFamily() {
+	current := model.RefreshToken{ID: 1, FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour)}
+	r.repo.On("GetByHash", security.HashToken("old")).Return(current, nil)
+	r.repo.On("Rotate", 1, mock.Anything).Return(false, nil)
+	r.repo.On("RevokeFamily", "family").Return(nil)
+
+	_, _, err := r.tokenUc.Rotate("old", "")
+	r.ErrorIs(err, usecase.ErrRefreshTokenReused)
+}
+
+func (r *refreshTokenUcSuite) TestRotate_FailedKeepsSession() {
+	current := model.RefreshToken{ID: 1, FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour)}
+	r.repo.On("GetByHash", security.HashToken("old")).Return(current, nil)
+	r.repo.On("Rotate", 1, mock.Anything).Return(false, errors.New("error"))
+
+	_, _, err := r.tokenUc.Rotate("old", "")
+	r.Error(err)
+	r.repo.AssertNotCalled(r.T(), "RevokeFamily", "family")
+}
+
+func (r *refreshTokenUcSuite) TestRevoke_Success() {
+	r.repo.On("GetByHash", security.HashToken("refresh")).Return(model.RefreshToken{ID: 1, FamilyID: "family"}, nil)
+	r.repo.On("RevokeFamily", "family").Return(nil)
+
+	err := r.tokenUc.Revoke("refresh")
+	r.NoError(err)
+}
+
+func (r *refreshTokenUcSuite) TestRevoke_Unknown() {
+	r.repo.On("GetByHash", security.HashToken("unknown")).Return(model.RefreshToken{}, sql.ErrNoRows)
+
+	err := r.tokenUc.Revoke("unknown")
+	r.ErrorIs(err, usecase.ErrInvalidRefreshToken)
+}
@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"basic-JWT/model"
+	"basic-JWT/repository"
+	"basic-JWT/utils/security"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+)
+
+// maxUserAgentLength keeps absurdly long User-Agent headers out of the sessions table.
+const maxUserAgentLength = 255
+
+var ErrSessionNotFound = NewDomainError(KindNotFound, "session not found")
+
+// SessionUsecase records every login as a session and ends sessions on
+// request. A session lives as long as its refresh token family, see
+// model.Session.
+type SessionUsecase interface {
+	Record(sessionID string, userID int, client model.ClientInfo) (string, error)
+	IsActive(sessionID string) (bool, error)
+	GetAll(userID int, currentSessionID string) ([]model.Session, error)
+	Revoke(userID int, sessionID string) error
+	RevokeAll(userID int) error
+	ForceLogout(username string) error
+}
+
+type sessionUsecase struct {
+	sessionRepository   repository.SessionRepository
+	refreshTokenUsecase RefreshTokenUsecase
+	userUsecase         UserUsecase
+	cacheTTL            time.Duration
+
+	mu        sync.RWMutex
+	cache     map[string]cachedRevocation
+	nextSweep time.Time
+}
+
+// Record saves the client of a login or refresh as the last seen client of
+// the session. An empty sessionID starts a new session, which is what Login
+// does. The returned id doubles as the refresh token family id.
+func (su *sessionUsecase) Record(sessionID string, userID int, client model.ClientInfo) (string, error) {
+	if sessionID == "" {
+		id, err := security.GenerateRandomToken(16)
+		if err != nil {
+			return "", err
+		}
+		sessionID = id
+	}
+
+	userAgent := client.UserAgent
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	_, err := su.sessionRepository.Save(&model.Session{
+		ID:         sessionID,
+		UserID:     userID,
+		UserAgent:  userAgent,
+		IP:         client.IP,
+		LastSeenAt: time.Now(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return sessionID, nil
+}
+
+// IsActive reports whether the session has not ended. Answers are cached
+// for cacheTTL like those of TokenRevocationUsecase.IsRevoked, and each
+// lookup also records the session as last seen now.
+func (su *sessionUsecase) IsActive(sessionID string) (bool, error) {
+	su.mu.RLock()
+	entry, ok := su.cache[sessionID]
+	su.mu.RUnlock()
+
+	if ok && time.Now().Before(entry.expiresAt) {
+		return !entry.revoked, nil
+	}
+
+	active, err := su.sessionRepository.Touch(sessionID, time.Now())
+	if err != nil {
+		return false, err
+	}
+
+	su.store(sessionID, cachedRevocation{revoked: !active, expiresAt: time.Now().Add(su.cacheTTL)})
+	return active, nil
+}
+
+// GetAll lists the active sessions of the user, marking the one with
+// currentSessionID as current.
+func (su *sessionUsecase) GetAll(userID int, currentSessionID string) ([]model.Session, error) {
+	sessions, err := su.sessionRepository.GetActiveByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+	return sessions, nil
+}
+
+// Revoke ends one session of the user. Sessions of other users are reported
+// as not found, so their ids cannot be probed.
+func (su *sessionUsecase) Revoke(userID int, sessionID string) error {
+	session, err := su.sessionRepository.GetByID(sessionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+	if session.UserID != userID {
+		return ErrSessionNotFound
+	}
+
+	if err := su.refreshTokenUsecase.RevokeFamily(sessionID); err != nil {
+		return err
+	}
+	su.store(sessionID, cachedRevocation{revoked: true, expiresAt: time.Now().Add(su.cacheTTL)})
+	return nil
+}
+
+// RevokeAll ends every session of the user, the current one included.
+func (su *sessionUsecase) RevokeAll(userID int) error {
+	sessions, err := su.sessionRepository.GetActiveByUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := su.refreshTokenUsecase.RevokeAllForUser(userID); err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		su.store(session.ID, cachedRevocation{revoked: true, expiresAt: time.Now().Add(su.cacheTTL)})
+	}
+	return nil
+}
+
+// ForceLogout ends every session of the user with the given username.
+func (su *sessionUsecase) ForceLogout(username string) error {
+	user, err := su.userUsecase.GetUserByUsername(username)
+	if err != nil {
+		return err
+	}
+	return su.RevokeAll(user.ID)
+}
+
+// store caches an answer and, at most once per cacheTTL, drops expired entries.
+func (su *sessionUsecase) store(sessionID string, entry cachedRevocation) {
+	now := time.Now()
+
+	su.mu.Lock()
+	defer su.mu.Unlock()
+
+	if now.After(su.nextSweep) {
+		for key, cached := range su.cache {
+			if now.After(cached.expiresAt) {
+				delete(su.cache, key)
+			}
+		}
+		su.nextSweep = now.Add(su.cacheTTL)
+	}
+	su.cache[sessionID] = entry
+}
+
+func NewSessionUsecase(sessionRepository repository.SessionRepository, refreshTokenUsecase RefreshTokenUsecase, userUsecase UserUsecase, cacheTTL time.Duration) SessionUsecase {
+	return &sessionUsecase{
+		sessionRepository:   sessionRepository,
+		refreshTokenUsecase: refreshTokenUsecase,
+		userUsecase:         userUsecase,
+		cacheTTL:            cacheTTL,
+		cache:               map[string]cachedRevocation{},
+	}
+}
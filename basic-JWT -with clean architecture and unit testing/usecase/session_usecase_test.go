@@ -0,0 +1,175 @@
+package usecase_test
+
+import (
+	"basic-JWT/mock/usecase_mock"
+	"basic-JWT/model"
+	"basic-JWT/usecase"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type sessionUcSuite struct {
+	suite.Suite
+	sessionRepo    *usecase_mock.SessionRepositoryMock
+	refreshTokenUc *usecase_mock.RefreshTokenUsecaseMock
+	userUc         *usecase_mock.UserUseCaseMock
+	sessionUc      usecase.SessionUsecase
+}
+
+func TestSessionUcSuite(t *testing.T) {
+	suite.Run(t, new(sessionUcSuite))
+}
+
+func (s *sessionUcSuite) SetupTest() {
+	s.sessionRepo = new(usecase_mock.SessionRepositoryMock)
+	s.refreshTokenUc = new(usecase_mock.RefreshTokenUsecaseMock)
+	s.userUc = new(usecase_mock.UserUseCaseMock)
+	s.sessionUc = usecase.NewSessionUsecase(s.sessionRepo, s.refreshTokenUc, s.userUc, time.Minute)
+}
+
+func (s *sessionUcSuite) TestRecord_NewSession() {
+	var stored *model.Session
+	s.sessionRepo.On("Save", mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*model.Session)
+	}).Return(&model.Session{}, nil).Once()
+
+	id, err := s.sessionUc.Record("", 1, model.ClientInfo{IP: "10.0.0.1", UserAgent: "curl/8.0"})
+	s.NoError(err)
+	s.NotEmpty(id)
+	s.Equal(id, stored.ID)
+	s.Equal(1, stored.UserID)
+	s.Equal("10.0.0.1", stored.IP)
+	s.Equal("curl/8.0", stored.UserAgent)
+	s.WithinDuration(time.Now(), stored.LastSeenAt, time.Second)
+}
+
+func (s *sessionUcSuite) TestRecord_ExistingSession() {
+	var stored *model.Session
+	s.sessionRepo.On("Save", mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*model.Session)
+	}).Return(&model.Session{}, nil).Once()
+
+	id, err := s.sessionUc.Record("sid", 1, model.ClientInfo{UserAgent: strings.Repeat("a", 1000)})
+	s.NoError(err)
+	s.Equal("sid", id)
+	s.Equal("sid", stored.ID)
+	s.Len(stored.UserAgent, 255)
+}
+
+func (s *sessionUcSuite) TestRecord_Failed() {
+	s.sessionRepo.On("Save", mock.Anything).Return(nil, errors.New("db down")).Once()
+
+	_, err := s.sessionUc.Record("", 1, model.ClientInfo{})
+	s.Error(err)
+}
+
+func (s *sessionUcSuite) TestIsActive_CachesAnswer() {
+	s.sessionRepo.On("Touch", "sid", mock.Anything).Return(true, nil).Once()
+
+	active, err := s.sessionUc.IsActive("sid")
+	s.NoError(err)
+	s.True(active)
+
+	active, err = s.sessionUc.IsActive("sid")
+	s.NoError(err)
+	s.True(active)
+	s.sessionRepo.AssertNumberOfCalls(s.T(), "Touch", 1)
+}
+
+func (s *sessionUcSuite) TestIsActive_Ended() {
+	s.sessionRepo.On("Touch", "sid", mock.Anything).Return(false, nil).Once()
+
+	active, err := s.sessionUc.IsActive("sid")
+	s.NoError(err)
+	s.False(active)
+}
+
+func (s *sessionUcSuite) TestIsActive_Failed() {
+	s.sessionRepo.On("Touch", "sid", mock.Anything).Return(false, errors.New("db down")).Once()
+
+	_, err := s.sessionUc.IsActive("sid")
+	s.Error(err)
+}
+
+func (s *sessionUcSuite) TestGetAll_MarksCurrent() {
+	s.sessionRepo.On("GetActiveByUser", 1).Return([]model.Session{{ID: "sid1", UserID: 1}, {ID: "sid2", UserID: 1}}, nil)
+
+	sessions, err := s.sessionUc.GetAll(1, "sid2")
+	s.NoError(err)
+	s.False(sessions[0].Current)
+	s.True(sessions[1].Current)
+}
+
+func (s *sessionUcSuite) TestRevoke_Success() {
+	s.sessionRepo.On("GetByID", "sid").Return(model.Session{ID: "sid", UserID: 1}, nil)
+	s.refreshTokenUc.On("RevokeFamily", "sid").Return(nil).Once()
+
+	err := s.sessionUc.Revoke(1, "sid")
+	s.NoError(err)
+
+	// the revocation is visible on this instance without a database lookup
+	active, err := s.sessionUc.IsActive("sid")
+	s.NoError(err)
+	s.False(active)
+	s.sessionRepo.AssertNotCalled(s.T(), "Touch", "sid", mock.Anything)
+}
+
+func (s *sessionUcSuite) TestRevoke_OtherUser() {
+	s.sessionRepo.On("GetByID", "sid").Return(model.Session{ID: "sid", UserID: 2}, nil)
+
+	err := s.sessionUc.Revoke(1, "sid")
+	s.ErrorIs(err, usecase.ErrSessionNotFound)
+	s.refreshTokenUc.AssertNotCalled(s.T(), "RevokeFamily", mock.Anything)
+}
+
+func (s *sessionUcSuite) TestRevoke_NotFound() {
+	s.sessionRepo.On("GetByID", "missing").Return(model.Session{}, sql.ErrNoRows)
+
+	err := s.sessionUc.Revoke(1, "missing")
+	s.ErrorIs(err, usecase.ErrSessionNotFound)
+}
+
+func (s *sessionUcSuite) TestRevokeAll_Success() {
+	s.sessionRepo.On("GetActiveByUser", 1).Return([]model.Session{{ID: "sid1"}, {ID: "sid2"}}, nil)
+	s.refreshTokenUc.On("RevokeAllForUser", 1).Return(nil).Once()
+
+	err := s.sessionUc.RevokeAll(1)
+	s.NoError(err)
+
+	active, err := s.sessionUc.IsActive("sid2")
+	s.NoError(err)
+	s.False(active)
+	s.refreshTokenUc.AssertExpectations(s.T())
+}
+
+func (s *sessionUcSuite) TestRevokeAll_Failed() {
+	s.sessionRepo.On("GetActiveByUser", 1).Return([]model.Session{{ID: "sid1"}}, nil)
+	s.refreshTokenUc.On("RevokeAllForUser", 1).Return(errors.New("db down"))
+
+	err := s.sessionUc.RevokeAll(1)
+	s.Error(err)
+}
+
+func (s *sessionUcSuite) TestForceLogout_Success() {
+	s.userUc.On("GetUserByUsername", "user1").Return(model.User{ID: 3, Username: "user1"}, nil)
+	s.sessionRepo.On("GetActiveByUser", 3).Return([]model.Session{}, nil)
+	s.refreshTokenUc.On("RevokeAllForUser", 3).Return(nil).Once()
+
+	err := s.sessionUc.ForceLogout("user1")
+	s.NoError(err)
+	s.refreshTokenUc.AssertExpectations(s.T())
+}
+
+func (s *sessionUcSuite) TestForceLogout_UserNotFound() {
+	s.userUc.On("GetUserByUsername", "ghost").Return(model.User{}, usecase.NewDomainError(usecase.KindNotFound, "user not found"))
+
+	err := s.sessionUc.ForceLogout("ghost")
+	s.ErrorIs(err, usecase.ErrNotFound)
+	s.refreshTokenUc.AssertNotCalled(s.T(), "RevokeAllForUser", mock.Anything)
+}
@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"basic-JWT/model"
+	"basic-JWT/repository"
+	"basic-JWT/utils/service"
+	"sync"
+	"time"
+)
+
+var ErrInvalidToken = NewDomainError(KindValidation, "invalid token")
+
+// TokenRevocationUsecase keeps the denylist of JWTs that must stop working
+// before they expire, such as a stolen access token.
+type TokenRevocationUsecase interface {
+	Revoke(token string) error
+	IsRevoked(jti string) (bool, error)
+}
+
+type cachedRevocation struct {
+	revoked   bool
+	expiresAt time.Time
+}
+
+type tokenRevocationUsecase struct {
+	revokedTokenRepository repository.RevokedTokenRepository
+	jwtService             service.JWTservice
+	cacheTTL               time.Duration
+
+	mu        sync.RWMutex
+	cache     map[string]cachedRevocation
+	nextSweep time.Time
+}
+
+// Revoke denylists a signed token of any purpose until it expires. Tokens
+// issued before every token got a jti cannot be revoked.
+func (tu *tokenRevocationUsecase) Revoke(token string) error {
+	claims, err := tu.jwtService.ParseToken(token)
+	if err != nil || claims.ID == "" || claims.ExpiresAt == nil {
+		return ErrInvalidToken
+	}
+
+	err = tu.revokedTokenRepository.Create(&model.RevokedToken{JTI: claims.ID, ExpiresAt: claims.ExpiresAt.Time})
+	if err != nil {
+		return err
+	}
+	tu.store(claims.ID, cachedRevocation{revoked: true, expiresAt: claims.ExpiresAt.Time})
+
+	// revocations are rare, so cleaning up here keeps the table small without
+	// a background job. A failed cleanup does not undo the revocation.
+	_ = tu.revokedTokenRepository.DeleteExpired()
+	return nil
+}
+
+// IsRevoked checks the denylist through an in-memory cache. Answers are
+// cached for cacheTTL, so a token revoked on another instance is rejected
+// here at most cacheTTL later. Revocations made on this instance apply at once.
+func (tu *tokenRevocationUsecase) IsRevoked(jti string) (bool, error) {
+	tu.mu.RLock()
+	entry, ok := tu.cache[jti]
+	tu.mu.RUnlock()
+
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.revoked, nil
+	}
+
+	revoked, err := tu.revokedTokenRepository.Exists(jti)
+	if err != nil {
+		return false, err
+	}
+
+	tu.store(jti, cachedRevocation{revoked: revoked, expiresAt: time.Now().Add(tu.cacheTTL)})
+	return revoked, nil
+}
+
+// store caches an answer and, at most once per cacheTTL, drops expired
+// entries so the cache does not grow with every token ever seen.
+func (tu *tokenRevocationUsecase) store(jti string, entry cachedRevocation) {
+	now := time.Now()
+
+	tu.mu.Lock()
+	defer tu.mu.Unlock()
+
+	if now.After(tu.nextSweep) {
+		for key, cached := range tu.cache {
+			if now.After(cached.expiresAt) {
+				delete(tu.cache, key)
+			}
+		}
+		tu.nextSweep = now.Add(tu.cacheTTL)
+	}
+	tu.cache[jti] = entry
+}
+
+func NewTokenRevocationUsecase(revokedTokenRepository repository.RevokedTokenRepository, jwtService service.JWTservice, cacheTTL time.Duration) TokenRevocationUsecase {
+	return &tokenRevocationUsecase{
+		revokedTokenRepository: revokedTokenRepository,
+		jwtService:             jwtService,
+		cacheTTL:               cacheTTL,
+		cache:                  map[string]cachedRevocation{},
+	}
+}
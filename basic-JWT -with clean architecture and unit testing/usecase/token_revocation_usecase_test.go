@@ -0,0 +1,109 @@
+package usecase_test
+
+import (
+	"basic-JWT/mock/service_mock"
+	"basic-JWT/mock/usecase_mock"
+	"basic-JWT/model"
+	"basic-JWT/usecase"
+	"errors"
+	"testing"
+	"time"
+
+	modelutils "basic-JWT/utils/model_utils"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type tokenRevocationUcSuite struct {
+	suite.Suite
+	revokedTokenRepo  *usecase_mock.RevokedTokenRepositoryMock
+	jwtService        *service_mock.JWTServiceMock
+	tokenRevocationUc usecase.TokenRevocationUsecase
+}
+
+func TestTokenRevocationUcSuite(t *testing.T) {
+	suite.Run(t, new(tokenRevocationUcSuite))
+}
+
+func (t *tokenRevocationUcSuite) SetupTest() {
+	t.revokedTokenRepo = new(usecase_mock.RevokedTokenRepositoryMock)
+	t.jwtService = new(service_mock.JWTServiceMock)
+	t.tokenRevocationUc = usecase.NewTokenRevocationUsecase(t.revokedTokenRepo, t.jwtService, time.Minute)
+}
+
+func (t *tokenRevocationUcSuite) TestRevoke_Success() {
+	expiresAt := time.Now().Add(time.Hour)
+	t.jwtService.On("ParseToken", "token").Return(&modelutils.JwtPayloadClaims{
+		RegisteredClaims: jwt.RegisteredClaims{ID: "jti", ExpiresAt: jwt.NewNumericDate(expiresAt)},
+	}, nil)
+	t.revokedTokenRepo.On("Create", mock.MatchedBy(func(token *model.RevokedToken) bool {
+		return token.JTI == "jti" && token.ExpiresAt.Unix() == expiresAt.Unix()
+	})).Return(nil)
+	t.revokedTokenRepo.On("DeleteExpired").Return(nil)
+
+	err := t.tokenRevocationUc.Revoke("token")
+	t.NoError(err)
+
+	// the revocation is visible on this instance without a database lookup
+	revoked, err := t.tokenRevocationUc.IsRevoked("jti")
+	t.NoError(err)
+	t.True(revoked)
+	t.revokedTokenRepo.AssertNotCalled(t.T(), "Exists", "jti")
+}
+
+func (t *tokenRevocationUcSuite) TestRevoke_InvalidToken() {
+	t.jwtService.On("ParseToken", "token").Return(&modelutils.JwtPayloadClaims{}, errors.New("expired"))
+
+	err := t.tokenRevocationUc.Revoke("token")
+	t.ErrorIs(err, usecase.ErrInvalidToken)
+}
+
+func (t *tokenRevocationUcSuite) TestRevoke_TokenWithoutJti() {
+	t.jwtService.On("ParseToken", "token").Return(&modelutils.JwtPayloadClaims{
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	}, nil)
+
+	err := t.tokenRevocationUc.Revoke("token")
+	t.ErrorIs(err, usecase.ErrInvalidToken)
+	t.revokedTokenRepo.AssertNotCalled(t.T(), "Create", mock.Anything)
+}
+
+func (t *tokenRevocationUcSuite) TestRevoke_Failed() {
+	t.jwtService.On("ParseToken", "token").Return(&modelutils.JwtPayloadClaims{
+		RegisteredClaims: jwt.RegisteredClaims{ID: "jti", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	}, nil)
+	t.revokedTokenRepo.On("Create", mock.Anything).Return(errors.New("error"))
+
+	err := t.tokenRevocationUc.Revoke("token")
+	t.Error(err)
+}
+
+func (t *tokenRevocationUcSuite) TestIsRevoked_Cached() {
+	t.revokedTokenRepo.On("Exists", "jti").Return(false, nil).Once()
+
+	t.tokenRevocationUc.IsRevoked("jti")
+	revoked, err := t.tokenRevocationUc.IsRevoked("jti")
+	t.NoError(err)
+	t.False(revoked)
+	t.revokedTokenRepo.AssertNumberOfCalls(t.T(), "Exists", 1)
+}
+
+func (t *tokenRevocationUcSuite) TestIsRevoked_ExpiredCacheReloads() {
+	t.tokenRevocationUc = usecase.NewTokenRevocationUsecase(t.revokedTokenRepo, t.jwtService, 0)
+	t.revokedTokenRepo.On("Exists", "jti").Return(true, nil)
+
+	t.tokenRevocationUc.IsRevoked("jti")
+	revoked, err := t.tokenRevocationUc.IsRevoked("jti")
+	t.NoError(err)
+	t.True(revoked)
+	t.revokedTokenRepo.AssertNumberOfCalls(t.T(), "Exists", 2)
+}
+
+func (t *tokenRevocationUcSuite) TestIsRevoked_Failed() {
+	t.revokedTokenRepo.On("Exists", "jti").Return(false, errors.New("error"))
+
+	_, err := t.tokenRevocationUc.IsRevoked("jti")
+	t.Error(err)
+}
@@ -3,32 +3,272 @@ package usecase
 import (
 	"basic-JWT/model"
 	"basic-JWT/repository"
+	"basic-JWT/utils/security"
+	"basic-JWT/utils/service"
+	"fmt"
+	"net/mail"
+	"strings"
 )
 
+var ErrInvalidEmail = NewDomainError(KindValidation, "invalid email address")
+
 type UserUsecase interface {
 	Create(user *model.User) (*model.User, error)
+	CreateUser(request model.CreateUserRequest, actor model.Actor) (model.User, error)
 	GetAllUsers() ([]model.User, error)
 	GetUserByUsername(username string) (model.User, error)
+	GetUserByID(id int) (model.User, error)
+	GetUserByEmail(email string) (model.User, error)
+	UpdateUser(username string, request model.UpdateUserRequest, actor model.Actor) (model.User, error)
+	SetDisabled(username string, disabled bool, actor model.Actor) error
+	DeleteUser(username string, actor model.Actor) error
+	ResetPassword(username string, actor model.Actor) (string, error)
+	UpdatePasswordHash(id int, hash string) error
+	ChangePassword(id int, password string) error
+	SetEmailVerified(id int) error
 }
 
 type userUsecase struct {
-	userRepository repository.UserRepository
+	userRepository      repository.UserRepository
+	rbacRepository      repository.RbacRepository
+	refreshTokenUsecase RefreshTokenUsecase
+	passwordHasher      service.PasswordHasher
+	passwordPolicy      *security.PasswordPolicy
+	auditUsecase        AuditUsecase
 }
 
 func (uu *userUsecase) Create(user *model.User) (*model.User, error) {
 	return uu.userRepository.Create(user)
 }
 
+// CreateUser creates an account on behalf of an admin. The password has to
+// meet the same policy as a self registered one. The email address is
+// optional and counts as verified, since the admin vouches for it. Without
+// one the user logs in without email verification.
+func (uu *userUsecase) CreateUser(request model.CreateUserRequest, actor model.Actor) (user model.User, err error) {
+	defer func() { uu.audit(model.AuditActionUserCreate, request.Username, actor, err) }()
+
+	if request.Username == "" {
+		return model.User{}, NewDomainError(KindValidation, "username cannot be empty")
+	}
+	if err := uu.passwordPolicy.Validate(request.Password); err != nil {
+		return model.User{}, wrapError(KindValidation, err)
+	}
+	if _, err := uu.userRepository.GetUserByUsername(request.Username); err == nil {
+		return model.User{}, NewDomainError(KindConflict, fmt.Sprintf("username '%s' is already taken", request.Username))
+	}
+
+	email := ""
+	if request.Email != "" {
+		if email, err = NormalizeEmail(request.Email); err != nil {
+			return model.User{}, err
+		}
+		if _, err := uu.userRepository.GetUserByEmail(email); err == nil {
+			return model.User{}, NewDomainError(KindConflict, fmt.Sprintf("email '%s' is already taken", email))
+		}
+	}
+	if request.Role != "" {
+		if err := uu.validateRole(request.Role); err != nil {
+			return model.User{}, err
+		}
+	}
+
+	hashedPassword, err := uu.passwordHasher.Hash(request.Password)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	user = model.User{Username: request.Username, Email: email, EmailVerified: email != "", Password: hashedPassword, Role: request.Role}
+	if user.Role == "" {
+		user.Role = "user"
+	}
+	if _, err := uu.userRepository.Create(&user); err != nil {
+		return model.User{}, err
+	}
+	return user, nil
+}
+
 func (uu *userUsecase) GetAllUsers() ([]model.User, error) {
 	return uu.userRepository.GetAllUsers()
 }
 
+// The lookups report unknown users as ErrNotFound.
 func (uu *userUsecase) GetUserByUsername(username string) (model.User, error) {
-	return uu.userRepository.GetUserByUsername(username)
+	user, err := uu.userRepository.GetUserByUsername(username)
+	return user, notFoundError(err)
+}
+
+func (uu *userUsecase) GetUserByID(id int) (model.User, error) {
+	user, err := uu.userRepository.GetUserByID(id)
+	return user, notFoundError(err)
+}
+
+func (uu *userUsecase) GetUserByEmail(email string) (model.User, error) {
+	user, err := uu.userRepository.GetUserByEmail(email)
+	return user, notFoundError(err)
 }
 
-func NewUserUsecase(userRepository repository.UserRepository) UserUsecase {
+func (uu *userUsecase) UpdateUser(username string, request model.UpdateUserRequest, actor model.Actor) (user model.User, err error) {
+	defer func() { uu.audit(model.AuditActionUserUpdate, username, actor, err) }()
+
+	user, err = uu.GetUserByUsername(username)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	if request.Username != "" && request.Username != user.Username {
+		if _, err := uu.userRepository.GetUserByUsername(request.Username); err == nil {
+			return model.User{}, NewDomainError(KindConflict, fmt.Sprintf("username '%s' is already taken", request.Username))
+		}
+		user.Username = request.Username
+	}
+	if request.Role != "" {
+		if err := uu.validateRole(request.Role); err != nil {
+			return model.User{}, err
+		}
+		user.Role = request.Role
+	}
+
+	if err := uu.userRepository.Update(&user); err != nil {
+		return model.User{}, err
+	}
+	return user, nil
+}
+
+// SetDisabled blocks or re-enables the account. Disabling also revokes the
+// refresh tokens, and the auth middleware rejects access tokens that are still valid.
+func (uu *userUsecase) SetDisabled(username string, disabled bool, actor model.Actor) (err error) {
+	action := model.AuditActionUserEnable
+	if disabled {
+		action = model.AuditActionUserDisable
+	}
+	defer func() { uu.audit(action, username, actor, err) }()
+
+	user, err := uu.GetUserByUsername(username)
+	if err != nil {
+		return err
+	}
+
+	if err := uu.userRepository.SetDisabled(user.ID, disabled); err != nil {
+		return err
+	}
+	if disabled {
+		return uu.refreshTokenUsecase.RevokeAllForUser(user.ID)
+	}
+	return nil
+}
+
+func (uu *userUsecase) DeleteUser(username string, actor model.Actor) (err error) {
+	defer func() { uu.audit(model.AuditActionUserDelete, username, actor, err) }()
+
+	user, err := uu.GetUserByUsername(username)
+	if err != nil {
+		return err
+	}
+
+	if err := uu.userRepository.SoftDelete(user.ID); err != nil {
+		return err
+	}
+	return uu.refreshTokenUsecase.RevokeAllForUser(user.ID)
+}
+
+// ResetPassword replaces the password with a random temporary one and ends
+// every session of the user. The temporary password is returned once so the
+// admin can hand it over.
+func (uu *userUsecase) ResetPassword(username string, actor model.Actor) (temporaryPassword string, err error) {
+	defer func() { uu.audit(model.AuditActionUserResetPassword, username, actor, err) }()
+
+	user, err := uu.GetUserByUsername(username)
+	if err != nil {
+		return "", err
+	}
+
+	temporaryPassword, err = security.GenerateRandomToken(12)
+	if err != nil {
+		return "", err
+	}
+
+	hashedPassword, err := uu.passwordHasher.Hash(temporaryPassword)
+	if err != nil {
+		return "", err
+	}
+
+	if err := uu.userRepository.UpdatePassword(user.ID, hashedPassword); err != nil {
+		return "", err
+	}
+	if err := uu.refreshTokenUsecase.RevokeAllForUser(user.ID); err != nil {
+		return "", err
+	}
+
+	return temporaryPassword, nil
+}
+
+// UpdatePasswordHash stores a new hash of the current password, used to
+// upgrade outdated hashes. Sessions are left alone since the password is unchanged.
+func (uu *userUsecase) UpdatePasswordHash(id int, hash string) error {
+	return uu.userRepository.UpdatePassword(id, hash)
+}
+
+// ChangePassword sets a new password chosen by the user, checked against the
+// password policy, and ends every session of the user.
+func (uu *userUsecase) ChangePassword(id int, password string) error {
+	if err := uu.passwordPolicy.Validate(password); err != nil {
+		return wrapError(KindValidation, err)
+	}
+
+	hashedPassword, err := uu.passwordHasher.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	if err := uu.userRepository.UpdatePassword(id, hashedPassword); err != nil {
+		return err
+	}
+	return uu.refreshTokenUsecase.RevokeAllForUser(id)
+}
+
+func (uu *userUsecase) SetEmailVerified(id int) error {
+	return uu.userRepository.SetEmailVerified(id)
+}
+
+// validateRole refuses roles missing from the roles table, which would leave
+// the user without any permission.
+func (uu *userUsecase) validateRole(role string) error {
+	roles, err := uu.rbacRepository.GetAllRoles()
+	if err != nil {
+		return err
+	}
+	for _, r := range roles {
+		if r.Name == role {
+			return nil
+		}
+	}
+	return NewDomainError(KindValidation, fmt.Sprintf("role '%s' does not exist", role))
+}
+
+// audit records an admin action on the account with the given username.
+func (uu *userUsecase) audit(action string, username string, actor model.Actor, err error) {
+	uu.auditUsecase.Record(model.AuditEvent{ActorID: actor.UserID, Action: action, Target: username, IP: actor.IP}, err)
+}
+
+// NormalizeEmail trims and lowercases a bare email address. Addresses with a
+// display name, such as "Name <user@example.com>", are refused.
+func NormalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return "", ErrInvalidEmail
+	}
+	return email, nil
+}
+
+func NewUserUsecase(userRepository repository.UserRepository, rbacRepository repository.RbacRepository, refreshTokenUsecase RefreshTokenUsecase, passwordHasher service.PasswordHasher, passwordPolicy *security.PasswordPolicy, auditUsecase AuditUsecase) UserUsecase {
 	return &userUsecase{
-		userRepository: userRepository,
+		userRepository:      userRepository,
+		rbacRepository:      rbacRepository,
+		refreshTokenUsecase: refreshTokenUsecase,
+		passwordHasher:      passwordHasher,
+		passwordPolicy:      passwordPolicy,
+		auditUsecase:        auditUsecase,
 	}
-}
\ No newline at end of file
+}
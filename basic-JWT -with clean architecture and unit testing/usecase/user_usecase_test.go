@@ -3,9 +3,14 @@ package usecase_test
 import (
 	"basic-JWT/mock/usecase_mock"
 	"basic-JWT/model"
+	"basic-JWT/utils/security"
+	"basic-JWT/utils/service"
+	"database/sql"
 	"errors"
+	"fmt"
 	"testing"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"golang.org/x/crypto/bcrypt"
 
@@ -14,8 +19,12 @@ import (
 
 type userUcSuite struct {
 	suite.Suite
-	userRepo *usecase_mock.UserUseCaseMock
-	userUc   usecase.UserUsecase
+	userRepo       *usecase_mock.UserRepositoryMock
+	rbacRepo       *usecase_mock.RbacRepositoryMock
+	refreshTokenUc *usecase_mock.RefreshTokenUsecaseMock
+	auditUc        *usecase_mock.AuditUsecaseMock
+	actor          model.Actor
+	userUc         usecase.UserUsecase
 }
 
 func TestUserUcSuite(t *testing.T) {
@@ -23,8 +32,14 @@ func TestUserUcSuite(t *testing.T) {
 }
 
 func (u *userUcSuite) SetupTest() {
-	u.userRepo = new(usecase_mock.UserUseCaseMock)
-	u.userUc = usecase.NewUserUsecase(u.userRepo)
+	u.userRepo = new(usecase_mock.UserRepositoryMock)
+	u.refreshTokenUc = new(usecase_mock.RefreshTokenUsecaseMock)
+	u.auditUc = new(usecase_mock.AuditUsecaseMock)
+	u.actor = model.Actor{UserID: 1, IP: "10.0.0.1"}
+	u.auditUc.On("Record", mock.Anything, mock.Anything).Return()
+	u.rbacRepo = new(usecase_mock.RbacRepositoryMock)
+	u.rbacRepo.On("GetAllRoles").Return([]model.Role{{ID: 1, Name: "admin"}, {ID: 2, Name: "user"}}, nil)
+	u.userUc = usecase.NewUserUsecase(u.userRepo, u.rbacRepo, u.refreshTokenUc, service.NewBcryptHasher(bcrypt.MinCost), security.NewPasswordPolicy(8, 64, nil), u.auditUc)
 }
 
 func (u *userUcSuite) TestCreateUser_Success() {
@@ -156,11 +171,12 @@ func (u *userUcSuite) TestGetUserByUsername_Failed() {
 
 func (u *userUcSuite) TestGetUserByUsername_UserNotFound() {
 	// action
-	u.userRepo.On("GetUserByUsername", "username").Return(model.User{}, errors.New("user with username username not found"))
+	u.userRepo.On("GetUserByUsername", "username").Return(model.User{}, fmt.Errorf("user with username username not found: %w", sql.ErrNoRows))
 
 	// assert
 	_, err := u.userUc.GetUserByUsername("username")
-	u.EqualError(err, "user with username username not found")
+	u.ErrorIs(err, usecase.ErrNotFound)
+	u.ErrorIs(err, sql.ErrNoRows)
 }
 
 func (u *userUcSuite) TestGetUserByUsername_EmptyUsername() {
@@ -256,3 +272,264 @@ func (u *userUcSuite) TestGetUserByUsername_InvalidUsername() {
 	u.EqualError(err, "invalid username")
 }
 
+func (u *userUcSuite) TestGetUserByID_Success() {
+	// prepare
+	user := model.User{ID: 1, Username: "username", Role: "user"}
+
+	// action
+	u.userRepo.On("GetUserByID", 1).Return(user, nil)
+	foundUser, err := u.userUc.GetUserByID(1)
+
+	// assert
+	u.NoError(err)
+	u.Equal(user, foundUser)
+}
+
+func (u *userUcSuite) TestUpdateUser_Success() {
+	// prepare
+	user := model.User{ID: 1, Username: "username", Role: "user"}
+	updated := model.User{ID: 1, Username: "renamed", Role: "admin"}
+
+	// action
+	u.userRepo.On("GetUserByUsername", "username").Return(user, nil)
+	u.userRepo.On("GetUserByUsername", "renamed").Return(model.User{}, errors.New("user with username renamed not found"))
+	u.userRepo.On("Update", &updated).Return(nil)
+	result, err := u.userUc.UpdateUser("username", model.UpdateUserRequest{Username: "renamed", Role: "admin"}, u.actor)
+
+	// assert
+	u.NoError(err)
+	u.Equal(updated, result)
+}
+
+func (u *userUcSuite) TestUpdateUser_UsernameTaken() {
+	// prepare
+	user := model.User{ID: 1, Username: "username", Role: "user"}
+
+	// action
+	u.userRepo.On("GetUserByUsername", "username").Return(user, nil)
+	u.userRepo.On("GetUserByUsername", "other").Return(model.User{ID: 2, Username: "other"}, nil)
+	_, err := u.userUc.UpdateUser("username", model.UpdateUserRequest{Username: "other"}, u.actor)
+
+	// assert
+	u.EqualError(err, "username 'other' is already taken")
+	u.ErrorIs(err, usecase.ErrConflict)
+	u.userRepo.AssertNotCalled(u.T(), "Update")
+}
+
+func (u *userUcSuite) TestUpdateUser_UnknownRole() {
+	// prepare
+	user := model.User{ID: 1, Username: "username", Role: "user"}
+
+	// action
+	u.userRepo.On("GetUserByUsername", "username").Return(user, nil)
+	_, err := u.userUc.UpdateUser("username", model.UpdateUserRequest{Role: "superuser"}, u.actor)
+
+	// assert
+	u.EqualError(err, "role 'superuser' does not exist")
+	u.ErrorIs(err, usecase.ErrValidation)
+	u.userRepo.AssertNotCalled(u.T(), "Update", mock.Anything)
+}
+
+func (u *userUcSuite) TestUpdateUser_NotFound() {
+	// action
+	u.userRepo.On("GetUserByUsername", "username").Return(model.User{}, fmt.Errorf("user with username username not found: %w", sql.ErrNoRows))
+	_, err := u.userUc.UpdateUser("username", model.UpdateUserRequest{Role: "admin"}, u.actor)
+
+	// assert
+	u.ErrorIs(err, usecase.ErrNotFound)
+}
+
+func (u *userUcSuite) TestSetDisabled_DisableRevokesTokens() {
+	// action
+	u.userRepo.On("GetUserByUsername", "username").Return(model.User{ID: 1, Username: "username"}, nil)
+	u.userRepo.On("SetDisabled", 1, true).Return(nil)
+	u.refreshTokenUc.On("RevokeAllForUser", 1).Return(nil)
+	err := u.userUc.SetDisabled("username", true, u.actor)
+
+	// assert
+	u.NoError(err)
+	u.refreshTokenUc.AssertCalled(u.T(), "RevokeAllForUser", 1)
+}
+
+func (u *userUcSuite) TestSetDisabled_Enable() {
+	// action
+	u.userRepo.On("GetUserByUsername", "username").Return(model.User{ID: 1, Username: "username", Disabled: true}, nil)
+	u.userRepo.On("SetDisabled", 1, false).Return(nil)
+	err := u.userUc.SetDisabled("username", false, u.actor)
+
+	// assert
+	u.NoError(err)
+	u.refreshTokenUc.AssertNotCalled(u.T(), "RevokeAllForUser", 1)
+}
+
+func (u *userUcSuite) TestDeleteUser_Success() {
+	// action
+	u.userRepo.On("GetUserByUsername", "username").Return(model.User{ID: 1, Username: "username"}, nil)
+	u.userRepo.On("SoftDelete", 1).Return(nil)
+	u.refreshTokenUc.On("RevokeAllForUser", 1).Return(nil)
+	err := u.userUc.DeleteUser("username", u.actor)
+
+	// assert
+	u.NoError(err)
+	u.refreshTokenUc.AssertCalled(u.T(), "RevokeAllForUser", 1)
+	u.auditUc.AssertCalled(u.T(), "Record", model.AuditEvent{ActorID: 1, Action: model.AuditActionUserDelete, Target: "username", IP: "10.0.0.1"}, nil)
+}
+
+func (u *userUcSuite) TestDeleteUser_Failed() {
+	// action
+	u.userRepo.On("GetUserByUsername", "username").Return(model.User{ID: 1, Username: "username"}, nil)
+	u.userRepo.On("SoftDelete", 1).Return(errors.New("error"))
+	err := u.userUc.DeleteUser("username", u.actor)
+
+	// assert
+	u.EqualError(err, "error")
+	u.refreshTokenUc.AssertNotCalled(u.T(), "RevokeAllForUser", 1)
+	u.auditUc.AssertCalled(u.T(), "Record", mock.MatchedBy(func(event model.AuditEvent) bool {
+		return event.Action == model.AuditActionUserDelete && event.Target == "username"
+	}), err)
+}
+
+func (u *userUcSuite) TestResetPassword_Success() {
+	// prepare
+	var storedHash string
+
+	// action
+	u.userRepo.On("GetUserByUsername", "username").Return(model.User{ID: 1, Username: "username"}, nil)
+	u.userRepo.On("UpdatePassword", 1, mock.AnythingOfType("string")).Run(func(args mock.Arguments) {
+		storedHash = args.String(1)
+	}).Return(nil)
+	u.refreshTokenUc.On("RevokeAllForUser", 1).Return(nil)
+	temporaryPassword, err := u.userUc.ResetPassword("username", u.actor)
+
+	// assert
+	u.NoError(err)
+	u.NotEmpty(temporaryPassword)
+	u.NoError(bcrypt.CompareHashAndPassword([]byte(storedHash), []byte(temporaryPassword)))
+	u.refreshTokenUc.AssertCalled(u.T(), "RevokeAllForUser", 1)
+}
+
+func (u *userUcSuite) TestResetPassword_NotFound() {
+	// action
+	u.userRepo.On("GetUserByUsername", "username").Return(model.User{}, errors.New("user with username username not found"))
+	_, err := u.userUc.ResetPassword("username", u.actor)
+
+	// assert
+	u.EqualError(err, "user with username username not found")
+}
+
+func (u *userUcSuite) TestCreateUser_HashesPassword() {
+	// action
+	u.userRepo.On("GetUserByUsername", "alice").Return(model.User{}, errors.New("user with username alice not found"))
+	u.userRepo.On("Create", mock.MatchedBy(func(user *model.User) bool {
+		return user.Username == "alice" && user.Role == "user" &&
+			bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("correct horse battery")) == nil
+	})).Return(&model.User{ID: 1}, nil).Once()
+	_, err := u.userUc.CreateUser(model.CreateUserRequest{Username: "alice", Password: "correct horse battery"}, u.actor)
+
+	// assert
+	u.NoError(err)
+	u.userRepo.AssertExpectations(u.T())
+}
+
+func (u *userUcSuite) TestCreateUser_PasswordPolicy() {
+	// action
+	_, err := u.userUc.CreateUser(model.CreateUserRequest{Username: "alice", Password: "short", Role: "admin"}, u.actor)
+
+	// assert
+	u.ErrorIs(err, security.ErrPasswordPolicy)
+	u.ErrorIs(err, usecase.ErrValidation)
+	u.userRepo.AssertNotCalled(u.T(), "Create", mock.Anything)
+}
+
+func (u *userUcSuite) TestCreateUser_UnknownRole() {
+	// action
+	u.userRepo.On("GetUserByUsername", "alice").Return(model.User{}, errors.New("user with username alice not found"))
+	_, err := u.userUc.CreateUser(model.CreateUserRequest{Username: "alice", Password: "correct horse battery", Role: "superuser"}, u.actor)
+
+	// assert
+	u.EqualError(err, "role 'superuser' does not exist")
+	u.ErrorIs(err, usecase.ErrValidation)
+	u.userRepo.AssertNotCalled(u.T(), "Create", mock.Anything)
+}
+
+func (u *userUcSuite) TestCreateUser_UsernameTaken() {
+	// action
+	u.userRepo.On("GetUserByUsername", "alice").Return(model.User{ID: 1, Username: "alice"}, nil)
+	_, err := u.userUc.CreateUser(model.CreateUserRequest{Username: "alice", Password: "correct horse battery"}, u.actor)
+
+	// assert
+	u.EqualError(err, "username 'alice' is already taken")
+	u.userRepo.AssertNotCalled(u.T(), "Create", mock.Anything)
+}
+
+func (u *userUcSuite) TestUpdatePasswordHash() {
+	// action
+	u.userRepo.On("UpdatePassword", 1, "new-hash").Return(nil).Once()
+	err := u.userUc.UpdatePasswordHash(1, "new-hash")
+
+	// assert
+	u.NoError(err)
+	u.refreshTokenUc.AssertNotCalled(u.T(), "RevokeAllForUser", mock.Anything)
+}
+
+func (u *userUcSuite) TestCreateUser_EmailIsTrusted() {
+	// action
+	u.userRepo.On("GetUserByUsername", "alice").Return(model.User{}, errors.New("user with username alice not found"))
+	u.userRepo.On("GetUserByEmail", "alice@example.com").Return(model.User{}, errors.New("user with email alice@example.com not found"))
+	u.userRepo.On("Create", mock.MatchedBy(func(user *model.User) bool {
+		return user.Email == "alice@example.com" && user.EmailVerified
+	})).Return(&model.User{ID: 1}, nil).Once()
+	_, err := u.userUc.CreateUser(model.CreateUserRequest{Username: "alice", Password: "correct horse battery", Email: "Alice@Example.com"}, u.actor)
+
+	// assert
+	u.NoError(err)
+	u.userRepo.AssertExpectations(u.T())
+}
+
+func (u *userUcSuite) TestCreateUser_EmailTaken() {
+	// action
+	u.userRepo.On("GetUserByUsername", "alice").Return(model.User{}, errors.New("user with username alice not found"))
+	u.userRepo.On("GetUserByEmail", "alice@example.com").Return(model.User{ID: 2}, nil)
+	_, err := u.userUc.CreateUser(model.CreateUserRequest{Username: "alice", Password: "correct horse battery", Email: "alice@example.com"}, u.actor)
+
+	// assert
+	u.EqualError(err, "email 'alice@example.com' is already taken")
+	u.userRepo.AssertNotCalled(u.T(), "Create", mock.Anything)
+}
+
+func (u *userUcSuite) TestChangePassword_Success() {
+	// prepare
+	var storedHash string
+
+	// action
+	u.userRepo.On("UpdatePassword", 1, mock.AnythingOfType("string")).Run(func(args mock.Arguments) {
+		storedHash = args.String(1)
+	}).Return(nil)
+	u.refreshTokenUc.On("RevokeAllForUser", 1).Return(nil)
+	err := u.userUc.ChangePassword(1, "correct horse battery")
+
+	// assert
+	u.NoError(err)
+	u.NoError(bcrypt.CompareHashAndPassword([]byte(storedHash), []byte("correct horse battery")))
+	u.refreshTokenUc.AssertCalled(u.T(), "RevokeAllForUser", 1)
+}
+
+func (u *userUcSuite) TestChangePassword_PasswordPolicy() {
+	// action
+	err := u.userUc.ChangePassword(1, "short")
+
+	// assert
+	u.ErrorIs(err, security.ErrPasswordPolicy)
+	u.userRepo.AssertNotCalled(u.T(), "UpdatePassword", mock.Anything, mock.Anything)
+}
+
+func (u *userUcSuite) TestNormalizeEmail() {
+	email, err := usecase.NormalizeEmail("  Alice@Example.COM ")
+	u.NoError(err)
+	u.Equal("alice@example.com", email)
+
+	for _, invalid := range []string{"", "alice", "alice@", "Alice <alice@example.com>", "a@b.com, c@d.com"} {
+		_, err := usecase.NormalizeEmail(invalid)
+		u.ErrorIs(err, usecase.ErrInvalidEmail, invalid)
+	}
+}
@@ -5,5 +5,17 @@ import "github.com/golang-jwt/jwt/v5"
 type JwtPayloadClaims struct {
 	UserId int    `json:"userId"`
 	Role   string `json:"role"`
+	// Purpose is empty for access tokens. Tokens with a purpose are only
+	// accepted by VerifyTokenWithPurpose.
+	Purpose string `json:"purpose,omitempty"`
+	Mfa     bool   `json:"mfa,omitempty"`
+	// OAuth2 and OpenID Connect claims use their registered names
+	Scope    string `json:"scope,omitempty"`
+	ClientID string `json:"client_id,omitempty"`
+	Nonce    string `json:"nonce,omitempty"`
+	// Email binds an email verification token to the address it was sent to
+	Email string `json:"email,omitempty"`
+	// Sid is the session of the login that issued an access token
+	Sid string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
-}
\ No newline at end of file
+}
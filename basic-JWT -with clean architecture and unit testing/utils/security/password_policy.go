@@ -0,0 +1,75 @@
+package security
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrPasswordPolicy = errors.New("password does not meet the password policy")
+
+// PasswordPolicy checks new passwords for length and against a list of
+// passwords known from breaches. Length is counted in characters, not bytes.
+type PasswordPolicy struct {
+	minLength int
+	maxLength int
+	breached  map[string]struct{}
+}
+
+func NewPasswordPolicy(minLength int, maxLength int, breachedPasswords []string) *PasswordPolicy {
+	policy := &PasswordPolicy{
+		minLength: minLength,
+		maxLength: maxLength,
+		breached:  make(map[string]struct{}, len(breachedPasswords)),
+	}
+	for _, password := range breachedPasswords {
+		policy.breached[strings.ToLower(password)] = struct{}{}
+	}
+	return policy
+}
+
+// Validate returns an error wrapping ErrPasswordPolicy that says which rule
+// the password breaks.
+func (p *PasswordPolicy) Validate(password string) error {
+	length := utf8.RuneCountInString(password)
+	if length < p.minLength {
+		return fmt.Errorf("%w: it must be at least %d characters long", ErrPasswordPolicy, p.minLength)
+	}
+	if p.maxLength > 0 && length > p.maxLength {
+		return fmt.Errorf("%w: it must be at most %d characters long", ErrPasswordPolicy, p.maxLength)
+	}
+	// the comparison ignores case, a breached password is not safe in capitals either
+	if _, ok := p.breached[strings.ToLower(password)]; ok {
+		return fmt.Errorf("%w: it appears in a list of breached passwords", ErrPasswordPolicy)
+	}
+	return nil
+}
+
+// LoadBreachedPasswords reads a file with one password per line. Empty lines
+// are skipped. An empty path returns an empty list.
+func LoadBreachedPasswords(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password list: %v", err)
+	}
+	defer file.Close()
+
+	var passwords []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if password := strings.TrimRight(scanner.Text(), "\r"); password != "" {
+			passwords = append(passwords, password)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read breached password list: %v", err)
+	}
+	return passwords, nil
+}
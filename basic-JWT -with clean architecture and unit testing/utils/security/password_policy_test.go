@@ -0,0 +1,71 @@
+package security
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PasswordPolicySuite struct {
+	suite.Suite
+	policy *PasswordPolicy
+}
+
+func TestPasswordPolicySuite(t *testing.T) {
+	suite.Run(t, new(PasswordPolicySuite))
+}
+
+func (p *PasswordPolicySuite) SetupTest() {
+	p.policy = NewPasswordPolicy(8, 64, []string{"password123", "qwertyuiop"})
+}
+
+func (p *PasswordPolicySuite) TestValidate_Accepts() {
+	p.NoError(p.policy.Validate("correct horse battery staple"))
+}
+
+func (p *PasswordPolicySuite) TestValidate_TooShort() {
+	err := p.policy.Validate("short")
+	p.ErrorIs(err, ErrPasswordPolicy)
+	p.Contains(err.Error(), "at least 8 characters")
+}
+
+func (p *PasswordPolicySuite) TestValidate_CountsCharactersNotBytes() {
+	// seven characters, but more than eight bytes
+	p.ErrorIs(p.policy.Validate("pässwör"), ErrPasswordPolicy)
+}
+
+func (p *PasswordPolicySuite) TestValidate_TooLong() {
+	long := make([]byte, 65)
+	for i := range long {
+		long[i] = 'a'
+	}
+	p.ErrorIs(p.policy.Validate(string(long)), ErrPasswordPolicy)
+}
+
+func (p *PasswordPolicySuite) TestValidate_Breached() {
+	err := p.policy.Validate("Password123")
+	p.ErrorIs(err, ErrPasswordPolicy)
+	p.Contains(err.Error(), "breached")
+}
+
+func (p *PasswordPolicySuite) TestLoadBreachedPasswords() {
+	path := filepath.Join(p.T().TempDir(), "breached.txt")
+	p.NoError(os.WriteFile(path, []byte("123456\r\n\npassword1\n"), 0600))
+
+	passwords, err := LoadBreachedPasswords(path)
+	p.NoError(err)
+	p.Equal([]string{"123456", "password1"}, passwords)
+}
+
+func (p *PasswordPolicySuite) TestLoadBreachedPasswords_EmptyPath() {
+	passwords, err := LoadBreachedPasswords("")
+	p.NoError(err)
+	p.Empty(passwords)
+}
+
+func (p *PasswordPolicySuite) TestLoadBreachedPasswords_MissingFile() {
+	_, err := LoadBreachedPasswords(filepath.Join(p.T().TempDir(), "missing.txt"))
+	p.Error(err)
+}
@@ -0,0 +1,22 @@
+package security
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+)
+
+// PKCEChallengeS256 derives the S256 code challenge of RFC 7636 from a code verifier.
+func PKCEChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// VerifyPKCE reports whether the verifier matches an S256 code challenge.
+func VerifyPKCE(verifier, challenge string) bool {
+	// RFC 7636 requires 43 to 128 characters
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(PKCEChallengeS256(verifier)), []byte(challenge)) == 1
+}
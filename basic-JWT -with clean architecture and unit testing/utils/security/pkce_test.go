@@ -0,0 +1,34 @@
+package security
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PKCESuite struct {
+	suite.Suite
+}
+
+func TestPKCESuite(t *testing.T) {
+	suite.Run(t, new(PKCESuite))
+}
+
+// example from RFC 7636 appendix B
+const (
+	rfcVerifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	rfcChallenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+)
+
+func (p *PKCESuite) TestPKCEChallengeS256_RFCVector() {
+	p.Equal(rfcChallenge, PKCEChallengeS256(rfcVerifier))
+}
+
+func (p *PKCESuite) TestVerifyPKCE() {
+	p.True(VerifyPKCE(rfcVerifier, rfcChallenge))
+	p.False(VerifyPKCE(rfcVerifier+"x", rfcChallenge))
+}
+
+func (p *PKCESuite) TestVerifyPKCE_RejectsShortVerifier() {
+	p.False(VerifyPKCE("short", PKCEChallengeS256("short")))
+}
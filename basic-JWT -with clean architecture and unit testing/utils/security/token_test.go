@@ -0,0 +1,42 @@
+package security
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TokenSuite struct {
+	suite.Suite
+}
+
+func TestTokenSuite(t *testing.T) {
+	suite.Run(t, new(TokenSuite))
+}
+
+func (t *TokenSuite) TestGenerateRandomToken_Unique() {
+	first, err := GenerateRandomToken(32)
+	t.NoError(err)
+	second, err := GenerateRandomToken(32)
+	t.NoError(err)
+
+	t.Len(first, 43)
+	t.NotEqual(first, second)
+}
+
+func (t *TokenSuite) TestHashToken_Deterministic() {
+	t.Equal(HashToken("token"), HashToken("token"))
+	t.NotEqual(HashToken("token"), HashToken("other"))
+	t.Len(HashToken("token"), 64)
+}
+
+func (t *TokenSuite) TestGenerateRecoveryCode_Format() {
+	code, err := GenerateRecoveryCode()
+	t.NoError(err)
+	t.Regexp(`^[a-z2-7]{4}-[a-z2-7]{4}$`, code)
+}
+
+func (t *TokenSuite) TestNormalizeRecoveryCode() {
+	t.Equal("abcd2345", NormalizeRecoveryCode(" ABCD-2345 "))
+	t.Equal(NormalizeRecoveryCode("abcd-2345"), NormalizeRecoveryCode("abcd 2345"))
+}
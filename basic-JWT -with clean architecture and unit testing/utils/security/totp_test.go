@@ -0,0 +1,74 @@
+package security
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TOTPSuite struct {
+	suite.Suite
+	secret string
+}
+
+func TestTOTPSuite(t *testing.T) {
+	suite.Run(t, new(TOTPSuite))
+}
+
+func (t *TOTPSuite) SetupTest() {
+	// RFC 6238 appendix B SHA1 seed
+	t.secret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+}
+
+func (t *TOTPSuite) TestGenerateTOTPCode_RFCVectors() {
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unix, expected := range vectors {
+		code, err := GenerateTOTPCode(t.secret, time.Unix(unix, 0))
+		t.NoError(err)
+		t.Equal(expected, code, "time %d", unix)
+	}
+}
+
+func (t *TOTPSuite) TestValidateTOTPCode_AllowsOneStepDrift() {
+	now := time.Unix(1234567890, 0)
+	previous, _ := GenerateTOTPCode(t.secret, now.Add(-30*time.Second))
+
+	step, ok := ValidateTOTPCode(t.secret, previous, now)
+	t.True(ok)
+	t.Equal(now.Unix()/30-1, step)
+}
+
+func (t *TOTPSuite) TestValidateTOTPCode_RejectsOldCode() {
+	now := time.Unix(1234567890, 0)
+	old, _ := GenerateTOTPCode(t.secret, now.Add(-2*time.Minute))
+
+	_, ok := ValidateTOTPCode(t.secret, old, now)
+	t.False(ok)
+}
+
+func (t *TOTPSuite) TestGenerateTOTPSecret() {
+	secret, err := GenerateTOTPSecret()
+	t.NoError(err)
+	t.Len(secret, 32)
+
+	code, err := GenerateTOTPCode(secret, time.Now())
+	t.NoError(err)
+	t.Len(code, 6)
+}
+
+func (t *TOTPSuite) TestTOTPURI() {
+	uri := TOTPURI("basic-JWT", "alice", "JBSWY3DPEHPK3PXP")
+
+	t.True(strings.HasPrefix(uri, "otpauth://totp/basic-JWT:alice?"))
+	t.Contains(uri, "secret=JBSWY3DPEHPK3PXP")
+	t.Contains(uri, "issuer=basic-JWT")
+}
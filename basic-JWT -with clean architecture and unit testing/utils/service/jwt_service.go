@@ -9,42 +9,128 @@ package service
 import (
 	"basic-JWT/config"
 	"basic-JWT/model"
+	"basic-JWT/utils/security"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"sort"
 	"time"
-	
-	"github.com/golang-jwt/jwt/v5"
+
 	modelutils "basic-JWT/utils/model_utils"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// TokenPurposeMfa marks the short-lived token handed out between the
+	// password and the TOTP step of a login.
+	TokenPurposeMfa = "mfa_pending"
+	// TokenPurposeOAuthAccess marks access tokens issued to OAuth clients.
+	// They are meant for /oauth/userinfo and other resource servers, not for
+	// the endpoints of this API.
+	TokenPurposeOAuthAccess = "oauth_access"
+	// TokenPurposeIDToken marks OpenID Connect ID tokens.
+	TokenPurposeIDToken = "id_token"
+	// TokenPurposeEmailVerification and TokenPurposePasswordReset mark the
+	// single use tokens sent by mail. Their jti is recorded once used.
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposePasswordReset     = "password_reset"
 )
 
 type JWTservice interface {
 	CreateToken(user model.User) string
+	CreateTokenWithClaims(claims modelutils.JwtPayloadClaims) string
 	VerifyToken(tokenString string) (*modelutils.JwtPayloadClaims, error)
+	VerifyTokenWithPurpose(tokenString string, purpose string) (*modelutils.JwtPayloadClaims, error)
+	ParseToken(tokenString string) (*modelutils.JwtPayloadClaims, error)
+	JWKS() modelutils.JSONWebKeySet
+}
+
+type verificationKey struct {
+	method jwt.SigningMethod
+	key    interface{}
 }
 
 type jwtService struct {
-	tokenConfig config.TokenConfig
+	tokenConfig      config.TokenConfig
+	signingKey       interface{}
+	verificationKeys map[string]verificationKey
 }
 
 func NewJWTService(tokenConfig config.TokenConfig) JWTservice {
-	return &jwtService{
-		tokenConfig: tokenConfig,
+	if tokenConfig.JwtSignedMethod == nil {
+		tokenConfig.JwtSignedMethod = jwt.SigningMethodHS256
+	}
+	if tokenConfig.JwtKeyID == "" {
+		tokenConfig.JwtKeyID = "default"
+	}
+
+	j := &jwtService{
+		tokenConfig:      tokenConfig,
+		verificationKeys: map[string]verificationKey{},
+	}
+
+	// previous keys are registered first so the active key always wins on a kid clash
+	for kid, key := range tokenConfig.JwtVerificationKeys {
+		if method := methodForPublicKey(key); method != nil {
+			j.verificationKeys[kid] = verificationKey{method: method, key: key}
+		}
+	}
+
+	switch key := tokenConfig.JwtPrivateKey.(type) {
+	case *rsa.PrivateKey:
+		j.signingKey = key
+		j.verificationKeys[tokenConfig.JwtKeyID] = verificationKey{method: tokenConfig.JwtSignedMethod, key: &key.PublicKey}
+	case ed25519.PrivateKey:
+		j.signingKey = key
+		j.verificationKeys[tokenConfig.JwtKeyID] = verificationKey{method: tokenConfig.JwtSignedMethod, key: key.Public()}
+	default:
+		j.signingKey = []byte(tokenConfig.JwtSignatureKey)
+		j.verificationKeys[tokenConfig.JwtKeyID] = verificationKey{method: tokenConfig.JwtSignedMethod, key: []byte(tokenConfig.JwtSignatureKey)}
 	}
+
+	return j
 }
 
 func (j *jwtService) CreateToken(user model.User) string {
-	tokenKey := j.tokenConfig.JwtSignatureKey
-
-	claims := modelutils.JwtPayloadClaims{
-		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer:    "Enigma Camp",
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(1 * time.Hour)),
-		},
+	return j.CreateTokenWithClaims(modelutils.JwtPayloadClaims{
 		UserId: user.ID,
 		Role:   user.Role,
+	})
+}
+
+// CreateTokenWithClaims signs the given claims, filling in the issuer, issue
+// time, access token expiry and a random jti when they are not set.
+func (j *jwtService) CreateTokenWithClaims(claims modelutils.JwtPayloadClaims) string {
+	lifetime := j.tokenConfig.AccessTokenLifetime
+	if lifetime == 0 {
+		lifetime = time.Hour
+	}
+
+	if claims.Issuer == "" {
+		claims.Issuer = "Enigma Camp"
+	}
+	if claims.IssuedAt == nil {
+		claims.IssuedAt = jwt.NewNumericDate(time.Now())
+	}
+	if claims.ExpiresAt == nil {
+		claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(lifetime))
+	}
+	if claims.ID == "" {
+		jti, err := security.GenerateRandomToken(16)
+		if err != nil {
+			panic(err)
+		}
+		claims.ID = jti
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token := jwt.NewWithClaims(j.tokenConfig.JwtSignedMethod, claims)
+	token.Header["kid"] = j.tokenConfig.JwtKeyID
 
-	tokenString, err := token.SignedString([]byte(tokenKey))
+	tokenString, err := token.SignedString(j.signingKey)
 	if err != nil {
 		panic(err)
 	}
@@ -52,21 +138,105 @@ func (j *jwtService) CreateToken(user model.User) string {
 	return tokenString
 }
 
+// VerifyToken only accepts access tokens, so an mfa pending token cannot be
+// used as a bearer token.
 func (j *jwtService) VerifyToken(tokenString string) (*modelutils.JwtPayloadClaims, error) {
-	tokenKey := j.tokenConfig.JwtSignatureKey
+	return j.VerifyTokenWithPurpose(tokenString, "")
+}
+
+func (j *jwtService) VerifyTokenWithPurpose(tokenString string, purpose string) (*modelutils.JwtPayloadClaims, error) {
+	claims, err := j.ParseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Purpose != purpose {
+		return nil, fmt.Errorf("unexpected token purpose %q", claims.Purpose)
+	}
+
+	return claims, nil
+}
+
+// ParseToken checks the signature and expiry of a token whatever its purpose.
+// Callers have to look at the purpose themselves.
+func (j *jwtService) ParseToken(tokenString string) (*modelutils.JwtPayloadClaims, error) {
 	claims := &modelutils.JwtPayloadClaims{}
 
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(tokenKey), nil
-	})
-	
+	token, err := jwt.ParseWithClaims(tokenString, claims, j.keyFunc)
+
 	if err != nil {
 		return nil, err
 	}
 
 	if !token.Valid {
-		return nil, err
+		return nil, fmt.Errorf("invalid token")
 	}
 
 	return claims, nil
 }
+
+// keyFunc picks the verification key by the kid header. Tokens issued before
+// kid headers existed fall back to the active key.
+func (j *jwtService) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		kid = j.tokenConfig.JwtKeyID
+	}
+
+	key, ok := j.verificationKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %s", kid)
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s for key id %s", token.Method.Alg(), kid)
+	}
+
+	return key.key, nil
+}
+
+// JWKS publishes every asymmetric verification key. Shared HMAC secrets are never exposed.
+func (j *jwtService) JWKS() modelutils.JSONWebKeySet {
+	set := modelutils.JSONWebKeySet{Keys: []modelutils.JSONWebKey{}}
+
+	kids := make([]string, 0, len(j.verificationKeys))
+	for kid := range j.verificationKeys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	for _, kid := range kids {
+		switch key := j.verificationKeys[kid].key.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, modelutils.JSONWebKey{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				Alg: jwt.SigningMethodRS256.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, modelutils.JSONWebKey{
+				Kty: "OKP",
+				Kid: kid,
+				Use: "sig",
+				Alg: jwt.SigningMethodEdDSA.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(key),
+			})
+		}
+	}
+
+	return set
+}
+
+func methodForPublicKey(key crypto.PublicKey) jwt.SigningMethod {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA
+	}
+	return nil
+}
@@ -3,8 +3,16 @@ package service
 import (
 	"basic-JWT/config"
 	"basic-JWT/model"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
 	"testing"
+	"time"
 
+	modelutils "basic-JWT/utils/model_utils"
+
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -54,3 +62,139 @@ func (j *JWTServiceSuite) TestVerifyToken_InvalidToken() {
 	j.Error(err)
 	j.Nil(claims)
 }
+
+func (j *JWTServiceSuite) TestVerifyToken_RejectsPurposeToken() {
+	token := j.jwtSvc.CreateTokenWithClaims(modelutils.JwtPayloadClaims{UserId: 1, Role: "admin", Purpose: TokenPurposeMfa})
+
+	_, err := j.jwtSvc.VerifyToken(token)
+	j.Error(err)
+
+	claims, err := j.jwtSvc.VerifyTokenWithPurpose(token, TokenPurposeMfa)
+	j.NoError(err)
+	j.Equal(1, claims.UserId)
+}
+
+func (j *JWTServiceSuite) TestVerifyTokenWithPurpose_RejectsAccessToken() {
+	token := j.jwtSvc.CreateToken(model.User{ID: 1, Role: "admin"})
+
+	_, err := j.jwtSvc.VerifyTokenWithPurpose(token, TokenPurposeMfa)
+	j.Error(err)
+}
+
+func (j *JWTServiceSuite) TestCreateToken_UniqueJti() {
+	user := model.User{ID: 1, Role: "user"}
+
+	first, err := j.jwtSvc.VerifyToken(j.jwtSvc.CreateToken(user))
+	j.NoError(err)
+	second, err := j.jwtSvc.VerifyToken(j.jwtSvc.CreateToken(user))
+	j.NoError(err)
+
+	j.NotEmpty(first.ID)
+	j.NotEqual(first.ID, second.ID)
+}
+
+func (j *JWTServiceSuite) TestParseToken_AcceptsAnyPurpose() {
+	token := j.jwtSvc.CreateTokenWithClaims(modelutils.JwtPayloadClaims{UserId: 1, Purpose: TokenPurposeOAuthAccess})
+
+	claims, err := j.jwtSvc.ParseToken(token)
+	j.NoError(err)
+	j.Equal(TokenPurposeOAuthAccess, claims.Purpose)
+
+	_, err = j.jwtSvc.ParseToken("invalid.token.string")
+	j.Error(err)
+}
+
+func (j *JWTServiceSuite) TestCreateTokenWithClaims_KeepsExpiry() {
+	expiresAt := jwt.NewNumericDate(time.Now().Add(5 * time.Minute).Truncate(time.Second))
+	token := j.jwtSvc.CreateTokenWithClaims(modelutils.JwtPayloadClaims{
+		UserId:           1,
+		Mfa:              true,
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: expiresAt},
+	})
+
+	claims, err := j.jwtSvc.VerifyToken(token)
+	j.NoError(err)
+	j.True(claims.Mfa)
+	j.Equal(expiresAt.Unix(), claims.ExpiresAt.Unix())
+}
+
+func (j *JWTServiceSuite) TestCreateToken_HasKidHeader() {
+	token := j.jwtSvc.CreateToken(model.User{ID: 1, Role: "user"})
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	j.NoError(err)
+	j.Equal("default", parsed.Header["kid"])
+}
+
+func (j *JWTServiceSuite) TestJWKS_HidesHMACSecret() {
+	j.Empty(j.jwtSvc.JWKS().Keys)
+}
+
+func (j *JWTServiceSuite) TestRS256_RoundTrip() {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	svc := NewJWTService(config.TokenConfig{JwtSignedMethod: jwt.SigningMethodRS256, JwtKeyID: "rsa-1", JwtPrivateKey: key})
+
+	claims, err := svc.VerifyToken(svc.CreateToken(model.User{ID: 7, Role: "admin"}))
+	j.NoError(err)
+	j.Equal(7, claims.UserId)
+
+	jwks := svc.JWKS()
+	j.Len(jwks.Keys, 1)
+	j.Equal("RSA", jwks.Keys[0].Kty)
+	j.Equal("rsa-1", jwks.Keys[0].Kid)
+	j.Equal("AQAB", jwks.Keys[0].E)
+}
+
+func (j *JWTServiceSuite) TestEdDSA_RoundTrip() {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	svc := NewJWTService(config.TokenConfig{JwtSignedMethod: jwt.SigningMethodEdDSA, JwtKeyID: "ed-1", JwtPrivateKey: key})
+
+	claims, err := svc.VerifyToken(svc.CreateToken(model.User{ID: 3, Role: "user"}))
+	j.NoError(err)
+	j.Equal(3, claims.UserId)
+
+	jwks := svc.JWKS()
+	j.Len(jwks.Keys, 1)
+	j.Equal("OKP", jwks.Keys[0].Kty)
+	j.Equal("Ed25519", jwks.Keys[0].Crv)
+}
+
+func (j *JWTServiceSuite) TestVerifyToken_AcceptsPreviousKeyDuringRotation() {
+	_, oldKey, _ := ed25519.GenerateKey(rand.Reader)
+	oldSvc := NewJWTService(config.TokenConfig{JwtSignedMethod: jwt.SigningMethodEdDSA, JwtKeyID: "old", JwtPrivateKey: oldKey})
+	token := oldSvc.CreateToken(model.User{ID: 1, Role: "user"})
+
+	newKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	newSvc := NewJWTService(config.TokenConfig{
+		JwtSignedMethod:     jwt.SigningMethodRS256,
+		JwtKeyID:            "new",
+		JwtPrivateKey:       newKey,
+		JwtVerificationKeys: map[string]crypto.PublicKey{"old": oldKey.Public()},
+	})
+
+	claims, err := newSvc.VerifyToken(token)
+	j.NoError(err)
+	j.Equal(1, claims.UserId)
+	j.Len(newSvc.JWKS().Keys, 2)
+}
+
+func (j *JWTServiceSuite) TestVerifyToken_UnknownKid() {
+	_, otherKey, _ := ed25519.GenerateKey(rand.Reader)
+	other := NewJWTService(config.TokenConfig{JwtSignedMethod: jwt.SigningMethodEdDSA, JwtKeyID: "other", JwtPrivateKey: otherKey})
+
+	_, err := j.jwtSvc.VerifyToken(other.CreateToken(model.User{ID: 1}))
+	j.Error(err)
+}
+
+func (j *JWTServiceSuite) TestVerifyToken_RejectsAlgorithmMismatch() {
+	// an HS256 token signed with the published RSA modulus must not verify against the RSA key
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	svc := NewJWTService(config.TokenConfig{JwtSignedMethod: jwt.SigningMethodRS256, JwtKeyID: "rsa-1", JwtPrivateKey: key})
+
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"userId": 1, "role": "admin"})
+	forged.Header["kid"] = "rsa-1"
+	tokenString, _ := forged.SignedString(key.PublicKey.N.Bytes())
+
+	_, err := svc.VerifyToken(tokenString)
+	j.Error(err)
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"basic-JWT/config"
+	"basic-JWT/utils/security"
+	"bytes"
+	"fmt"
+	"mime"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	MailDriverSMTP   = "smtp"
+	MailDriverOutbox = "outbox"
+)
+
+// MailMessage is a plain text mail to a single recipient.
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(message MailMessage) error
+}
+
+type smtpMailer struct {
+	addr     string
+	from     string
+	envelope string
+	auth     smtp.Auth
+}
+
+// Send delivers the message through the SMTP server. net/smtp upgrades the
+// connection with STARTTLS whenever the server offers it.
+func (s *smtpMailer) Send(message MailMessage) error {
+	data, err := buildMailMessage(s.from, message, time.Now())
+	if err != nil {
+		return err
+	}
+	recipient, err := mail.ParseAddress(message.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient %q", message.To)
+	}
+	return smtp.SendMail(s.addr, s.auth, s.envelope, []string{recipient.Address}, data)
+}
+
+type outboxMailer struct {
+	dir  string
+	from string
+}
+
+// Send writes the message to its own .eml file in the outbox directory. The
+// files contain live tokens, so they are only readable by the owner.
+func (o *outboxMailer) Send(message MailMessage) error {
+	now := time.Now()
+	data, err := buildMailMessage(o.from, message, now)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(o.dir, 0o700); err != nil {
+		return err
+	}
+	suffix, err := security.GenerateRandomToken(6)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405.000000000Z"), suffix)
+	return os.WriteFile(filepath.Join(o.dir, name), data, 0o600)
+}
+
+// buildMailMessage renders the headers and body. Header values containing a
+// line break are refused, since they would let callers inject headers.
+func buildMailMessage(from string, message MailMessage, date time.Time) ([]byte, error) {
+	for _, value := range []string{from, message.To, message.Subject} {
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("mail header contains a line break")
+		}
+	}
+	if _, err := mail.ParseAddress(message.To); err != nil {
+		return nil, fmt.Errorf("invalid recipient %q", message.To)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("From: " + from + "\r\n")
+	buf.WriteString("To: " + message.To + "\r\n")
+	buf.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", message.Subject) + "\r\n")
+	buf.WriteString("Date: " + date.Format(time.RFC1123Z) + "\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+	body := strings.ReplaceAll(message.Body, "\r\n", "\n")
+	buf.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return buf.Bytes(), nil
+}
+
+func NewSMTPMailer(host string, port int, username string, password string, from string) (Mailer, error) {
+	address, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address %q", from)
+	}
+
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &smtpMailer{
+		addr:     host + ":" + strconv.Itoa(port),
+		from:     from,
+		envelope: address.Address,
+		auth:     auth,
+	}, nil
+}
+
+func NewOutboxMailer(dir string, from string) Mailer {
+	return &outboxMailer{dir: dir, from: from}
+}
+
+// NewMailer returns the mailer for the configured driver.
+func NewMailer(mailConfig config.MailConfig) (Mailer, error) {
+	switch mailConfig.Driver {
+	case MailDriverSMTP:
+		if mailConfig.SMTPHost == "" {
+			return nil, fmt.Errorf("MAIL_SMTP_HOST is required for the smtp mail driver")
+		}
+		return NewSMTPMailer(mailConfig.SMTPHost, mailConfig.SMTPPort, mailConfig.SMTPUsername, mailConfig.SMTPPassword, mailConfig.From)
+	case MailDriverOutbox:
+		return NewOutboxMailer(mailConfig.OutboxDir, mailConfig.From), nil
+	}
+	return nil, fmt.Errorf("unsupported mail driver %q", mailConfig.Driver)
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"basic-JWT/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MailerSuite struct {
+	suite.Suite
+}
+
+func TestMailerSuite(t *testing.T) {
+	suite.Run(t, new(MailerSuite))
+}
+
+func (m *MailerSuite) TestOutbox_WritesMessage() {
+	dir := filepath.Join(m.T().TempDir(), "outbox")
+	mailer := NewOutboxMailer(dir, "no-reply@example.com")
+
+	err := mailer.Send(MailMessage{To: "user@example.com", Subject: "Verify your email", Body: "line one\nline two"})
+	m.NoError(err)
+
+	files, err := os.ReadDir(dir)
+	m.NoError(err)
+	m.Len(files, 1)
+	m.True(strings.HasSuffix(files[0].Name(), ".eml"))
+
+	info, err := files[0].Info()
+	m.NoError(err)
+	m.Equal(os.FileMode(0o600), info.Mode().Perm())
+
+	data, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	m.NoError(err)
+	m.Contains(string(data), "From: no-reply@example.com\r\n")
+	m.Contains(string(data), "To: user@example.com\r\n")
+	m.Contains(string(data), "Subject: Verify your email\r\n")
+	m.True(strings.HasSuffix(string(data), "\r\n\r\nline one\r\nline two"))
+}
+
+func (m *MailerSuite) TestOutbox_OneFilePerMessage() {
+	dir := m.T().TempDir()
+	mailer := NewOutboxMailer(dir, "no-reply@example.com")
+
+	m.NoError(mailer.Send(MailMessage{To: "user@example.com", Subject: "first"}))
+	m.NoError(mailer.Send(MailMessage{To: "user@example.com", Subject: "second"}))
+
+	files, _ := os.ReadDir(dir)
+	m.Len(files, 2)
+}
+
+func (m *MailerSuite) TestBuildMailMessage_RejectsHeaderInjection() {
+	_, err := buildMailMessage("no-reply@example.com", MailMessage{To: "user@example.com", Subject: "hi\r\nBcc: victim@example.com"}, time.Now())
+	m.Error(err)
+
+	_, err = buildMailMessage("no-reply@example.com", MailMessage{To: "user@example.com\nBcc: victim@example.com"}, time.Now())
+	m.Error(err)
+}
+
+func (m *MailerSuite) TestBuildMailMessage_InvalidRecipient() {
+	_, err := buildMailMessage("no-reply@example.com", MailMessage{To: "not an address"}, time.Now())
+	m.Error(err)
+}
+
+func (m *MailerSuite) TestBuildMailMessage_EncodesSubject() {
+	data, err := buildMailMessage("no-reply@example.com", MailMessage{To: "user@example.com", Subject: "Atur ulang kata sandi ✓"}, time.Now())
+	m.NoError(err)
+	m.Contains(string(data), "Subject: =?utf-8?q?")
+}
+
+func (m *MailerSuite) TestNewMailer() {
+	mailer, err := NewMailer(config.MailConfig{Driver: MailDriverOutbox, OutboxDir: m.T().TempDir(), From: "no-reply@example.com"})
+	m.NoError(err)
+	m.IsType(&outboxMailer{}, mailer)
+
+	mailer, err = NewMailer(config.MailConfig{Driver: MailDriverSMTP, SMTPHost: "smtp.example.com", SMTPPort: 587, From: "App <no-reply@example.com>"})
+	m.NoError(err)
+	m.IsType(&smtpMailer{}, mailer)
+	m.Equal("no-reply@example.com", mailer.(*smtpMailer).envelope)
+
+	_, err = NewMailer(config.MailConfig{Driver: MailDriverSMTP, From: "no-reply@example.com"})
+	m.Error(err)
+
+	_, err = NewMailer(config.MailConfig{Driver: MailDriverSMTP, SMTPHost: "smtp.example.com", From: "not an address"})
+	m.Error(err)
+
+	_, err = NewMailer(config.MailConfig{Driver: "carrier-pigeon"})
+	m.Error(err)
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"basic-JWT/config"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	PasswordAlgorithmBcrypt   = "bcrypt"
+	PasswordAlgorithmArgon2id = "argon2id"
+
+	argon2idSaltLength = 16
+	argon2idKeyLength  = 32
+)
+
+// PasswordHasher hashes passwords into self-describing strings: bcrypt's
+// modular crypt format or the PHC string format for argon2id. Verify accepts
+// hashes of every supported algorithm, whichever one the hasher produces.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(password string, hash string) bool
+	// NeedsRehash reports whether the hash was made with another algorithm
+	// or other parameters than the ones Hash currently uses.
+	NeedsRehash(hash string) bool
+}
+
+type Argon2idParams struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+type bcryptHasher struct {
+	cost int
+}
+
+func (b *bcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), b.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func (b *bcryptHasher) Verify(password string, hash string) bool {
+	return verifyPassword(password, hash)
+}
+
+func (b *bcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != b.cost
+}
+
+type argon2idHasher struct {
+	params Argon2idParams
+}
+
+func (a *argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2idSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, a.params.Iterations, a.params.Memory, a.params.Parallelism, argon2idKeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, a.params.Memory, a.params.Iterations, a.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (a *argon2idHasher) Verify(password string, hash string) bool {
+	return verifyPassword(password, hash)
+}
+
+func (a *argon2idHasher) NeedsRehash(hash string) bool {
+	params, salt, key, err := decodeArgon2idHash(hash)
+	return err != nil || params != a.params || len(salt) != argon2idSaltLength || len(key) != argon2idKeyLength
+}
+
+// verifyPassword picks the algorithm from the hash prefix, so users keep
+// logging in while their hashes are migrated to another algorithm.
+func verifyPassword(password string, hash string) bool {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		params, salt, key, err := decodeArgon2idHash(hash)
+		if err != nil {
+			return false
+		}
+		computed := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+		return subtle.ConstantTimeCompare(computed, key) == 1
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// decodeArgon2idHash parses $argon2id$v=19$m=...,t=...,p=...$salt$key.
+func decodeArgon2idHash(hash string) (Argon2idParams, []byte, []byte, error) {
+	var params Argon2idParams
+
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != PasswordAlgorithmArgon2id {
+		return params, nil, nil, fmt.Errorf("invalid argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2 version")
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id parameters")
+	}
+	if params.Iterations == 0 || params.Parallelism == 0 {
+		return params, nil, nil, fmt.Errorf("invalid argon2id parameters")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, fmt.Errorf("invalid argon2id hash")
+	}
+
+	return params, salt, key, nil
+}
+
+func NewBcryptHasher(cost int) PasswordHasher {
+	return &bcryptHasher{cost: cost}
+}
+
+func NewArgon2idHasher(params Argon2idParams) PasswordHasher {
+	return &argon2idHasher{params: params}
+}
+
+// NewPasswordHasher returns the hasher for the configured algorithm.
+func NewPasswordHasher(passwordConfig config.PasswordConfig) (PasswordHasher, error) {
+	switch passwordConfig.Algorithm {
+	case PasswordAlgorithmBcrypt:
+		if passwordConfig.BcryptCost < bcrypt.MinCost || passwordConfig.BcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("invalid bcrypt cost %d", passwordConfig.BcryptCost)
+		}
+		return NewBcryptHasher(passwordConfig.BcryptCost), nil
+	case PasswordAlgorithmArgon2id:
+		if passwordConfig.Argon2Iterations == 0 || passwordConfig.Argon2Parallelism == 0 || passwordConfig.Argon2Memory < 8*uint32(passwordConfig.Argon2Parallelism) {
+			return nil, fmt.Errorf("invalid argon2id parameters")
+		}
+		return NewArgon2idHasher(Argon2idParams{
+			Memory:      passwordConfig.Argon2Memory,
+			Iterations:  passwordConfig.Argon2Iterations,
+			Parallelism: passwordConfig.Argon2Parallelism,
+		}), nil
+	}
+	return nil, fmt.Errorf("unsupported password hash algorithm %q", passwordConfig.Algorithm)
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"basic-JWT/config"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// small parameters keep the tests fast, production values come from config
+var testArgon2idParams = Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1}
+
+type PasswordHasherSuite struct {
+	suite.Suite
+}
+
+func TestPasswordHasherSuite(t *testing.T) {
+	suite.Run(t, new(PasswordHasherSuite))
+}
+
+func (p *PasswordHasherSuite) TestArgon2id_RoundTrip() {
+	hasher := NewArgon2idHasher(testArgon2idParams)
+
+	hash, err := hasher.Hash("password")
+	p.NoError(err)
+	p.True(strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$"))
+
+	p.True(hasher.Verify("password", hash))
+	p.False(hasher.Verify("wrong", hash))
+	p.False(hasher.NeedsRehash(hash))
+}
+
+func (p *PasswordHasherSuite) TestArgon2id_UniqueSalt() {
+	hasher := NewArgon2idHasher(testArgon2idParams)
+
+	first, _ := hasher.Hash("password")
+	second, _ := hasher.Hash("password")
+	p.NotEqual(first, second)
+}
+
+func (p *PasswordHasherSuite) TestArgon2id_VerifiesBcryptHash() {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	hasher := NewArgon2idHasher(testArgon2idParams)
+
+	p.True(hasher.Verify("password", string(hash)))
+	p.True(hasher.NeedsRehash(string(hash)))
+}
+
+func (p *PasswordHasherSuite) TestArgon2id_NeedsRehashOnNewParameters() {
+	hash, _ := NewArgon2idHasher(testArgon2idParams).Hash("password")
+	stronger := NewArgon2idHasher(Argon2idParams{Memory: 128, Iterations: 2, Parallelism: 1})
+
+	p.True(stronger.Verify("password", hash))
+	p.True(stronger.NeedsRehash(hash))
+}
+
+func (p *PasswordHasherSuite) TestArgon2id_MalformedHash() {
+	hasher := NewArgon2idHasher(testArgon2idParams)
+
+	p.False(hasher.Verify("password", "$argon2id$v=19$m=64,t=1,p=1$not-base64!$"))
+	p.False(hasher.Verify("password", "$argon2id$v=16$m=64,t=1,p=1$c2FsdA$a2V5"))
+	p.True(hasher.NeedsRehash("$argon2id$garbage"))
+}
+
+func (p *PasswordHasherSuite) TestBcrypt_RoundTrip() {
+	hasher := NewBcryptHasher(bcrypt.MinCost)
+
+	hash, err := hasher.Hash("password")
+	p.NoError(err)
+	p.True(hasher.Verify("password", hash))
+	p.False(hasher.Verify("wrong", hash))
+	p.False(hasher.NeedsRehash(hash))
+}
+
+func (p *PasswordHasherSuite) TestBcrypt_VerifiesArgon2idHash() {
+	hash, _ := NewArgon2idHasher(testArgon2idParams).Hash("password")
+	hasher := NewBcryptHasher(bcrypt.MinCost)
+
+	p.True(hasher.Verify("password", hash))
+	p.True(hasher.NeedsRehash(hash))
+}
+
+func (p *PasswordHasherSuite) TestBcrypt_NeedsRehashOnNewCost() {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+
+	p.True(NewBcryptHasher(bcrypt.MinCost + 1).NeedsRehash(string(hash)))
+}
+
+func (p *PasswordHasherSuite) TestNewPasswordHasher() {
+	hasher, err := NewPasswordHasher(config.PasswordConfig{Algorithm: PasswordAlgorithmArgon2id, Argon2Memory: 64, Argon2Iterations: 1, Argon2Parallelism: 1})
+	p.NoError(err)
+	p.IsType(&argon2idHasher{}, hasher)
+
+	hasher, err = NewPasswordHasher(config.PasswordConfig{Algorithm: PasswordAlgorithmBcrypt, BcryptCost: bcrypt.MinCost})
+	p.NoError(err)
+	p.IsType(&bcryptHasher{}, hasher)
+
+	_, err = NewPasswordHasher(config.PasswordConfig{Algorithm: "md5"})
+	p.Error(err)
+
+	_, err = NewPasswordHasher(config.PasswordConfig{Algorithm: PasswordAlgorithmBcrypt, BcryptCost: 99})
+	p.Error(err)
+}
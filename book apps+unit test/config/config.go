@@ -10,6 +10,9 @@ import (
 	"errors"
 	"os"
 	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
 )
 
 type DBConfig struct {
@@ -25,9 +28,37 @@ type APIConfig struct {
 	Port int
 }
 
+// LoanConfig mengatur peminjaman buku: lama pinjam dalam hari, denda
+// keterlambatan per hari dalam rupiah, dan batas hari untuk mengambil buku
+// yang sudah siap untuk reservasi.
+type LoanConfig struct {
+	PeriodDays     int
+	FinePerDay     int
+	HoldPickupDays int
+}
+
+// MetadataConfig berisi lokasi dump Open Library untuk melengkapi data buku
+// dari ISBN. Kosong berarti pencarian metadata tidak dipakai.
+type MetadataConfig struct {
+	DumpFile string
+}
+
+// TokenConfig mengatur JWT untuk login. AllowAnonymousRead membuka GET
+// katalog (buku, eksemplar, author, category) tanpa token.
+type TokenConfig struct {
+	ApplicationName     string
+	JwtSignatureKey     []byte
+	JwtSigningMethod    jwt.SigningMethod
+	AccessTokenLifetime time.Duration
+	AllowAnonymousRead  bool
+}
+
 type Config struct {
 	DBConfig
 	APIConfig
+	LoanConfig
+	MetadataConfig
+	TokenConfig
 }
 
 func (c *Config) readConfig() error {
@@ -41,9 +72,36 @@ func (c *Config) readConfig() error {
 	c.APIConfig = APIConfig{
 		Port: getEnvInt("API_PORT", 0),
 	}
+	c.LoanConfig = LoanConfig{
+		PeriodDays:     getEnvInt("LOAN_PERIOD_DAYS", 14),
+		FinePerDay:     getEnvInt("LOAN_FINE_PER_DAY", 1000),
+		HoldPickupDays: getEnvInt("HOLD_PICKUP_DAYS", 3),
+	}
+	c.MetadataConfig = MetadataConfig{
+		DumpFile: getEnv("METADATA_DUMP_FILE", ""),
+	}
+	c.TokenConfig = TokenConfig{
+		ApplicationName:     getEnv("TOKEN_APPLICATION_NAME", "simple-clean-architecture"),
+		JwtSignatureKey:     []byte(getEnv("TOKEN_JWT_SIGNATURE_KEY", "")),
+		JwtSigningMethod:    jwt.SigningMethodHS256,
+		AccessTokenLifetime: getEnvDuration("TOKEN_ACCESS_TOKEN_LIFETIME", time.Hour),
+		AllowAnonymousRead:  getEnvBool("ALLOW_ANONYMOUS_READ", true),
+	}
 	if c.DBConfig.Host == "" || c.DBConfig.Port == 0 || c.DBConfig.Username == "" || c.DBConfig.Password == "" || c.DBConfig.Database == "" {
 		return errors.New("must be filled")
 	}
+	if c.LoanConfig.PeriodDays <= 0 || c.LoanConfig.FinePerDay < 0 {
+		return errors.New("LOAN_PERIOD_DAYS must be positive and LOAN_FINE_PER_DAY cannot be negative")
+	}
+	if c.LoanConfig.HoldPickupDays <= 0 {
+		return errors.New("HOLD_PICKUP_DAYS must be positive")
+	}
+	if len(c.TokenConfig.JwtSignatureKey) == 0 {
+		return errors.New("TOKEN_JWT_SIGNATURE_KEY must be filled")
+	}
+	if c.TokenConfig.AccessTokenLifetime <= 0 {
+		return errors.New("TOKEN_ACCESS_TOKEN_LIFETIME must be positive")
+	}
 	return nil
 }
 
@@ -73,4 +131,28 @@ func getEnvInt(key string, defaultValue int) int {
 		return defaultValue
 	}
 	return i
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return defaultValue
+	}
+	return b
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultValue
+	}
+	return d
 }
\ No newline at end of file
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"simple-clean-architecture/middleware"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/usecase"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthController struct {
+	authUsecase    usecase.AuthUsecase
+	authMiddleware *middleware.AuthMiddleware
+	rg             *gin.RouterGroup
+}
+
+func (a *AuthController) Route() {
+	a.rg.POST("/auth/register", a.Register)
+	a.rg.POST("/auth/login", a.Login)
+	a.rg.PUT("/users/:id/role", a.authMiddleware.RequireToken(model.RoleLibrarian), a.UpdateUserRole)
+}
+
+func (a *AuthController) Register(c *gin.Context) {
+	var user model.User
+	if err := c.ShouldBindJSON(&user); err != nil {
+		c.JSON(400, gin.H{"message": err.Error()})
+		return
+	}
+
+	newUser, err := a.authUsecase.Register(user)
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(201, newUser)
+}
+
+func (a *AuthController) Login(c *gin.Context) {
+	var request model.LoginRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, gin.H{"message": err.Error()})
+		return
+	}
+
+	response, err := a.authUsecase.Login(request)
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(200, response)
+}
+
+func (a *AuthController) UpdateUserRole(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"message": "id must be a number"})
+		return
+	}
+
+	var request model.UserRoleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, gin.H{"message": err.Error()})
+		return
+	}
+
+	user, err := a.authUsecase.UpdateUserRole(id, request.Role)
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(200, user)
+}
+
+// currentUserId mengembalikan id user pemilik token, atau 0 jika request
+// tidak melewati middleware autentikasi.
+func currentUserId(c *gin.Context) int {
+	if value, ok := c.Get(middleware.UserKey); ok {
+		if user, ok := value.(model.User); ok {
+			return user.Id
+		}
+	}
+	return 0
+}
+
+func NewAuthController(authUsecase usecase.AuthUsecase, rg *gin.RouterGroup, authMiddleware *middleware.AuthMiddleware) *AuthController {
+	return &AuthController{authUsecase: authUsecase, rg: rg, authMiddleware: authMiddleware}
+}
@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"simple-clean-architecture/middleware"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/usecase"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuthUsecase struct {
+	mock.Mock
+}
+
+func (m *MockAuthUsecase) Register(user model.User) (model.User, error) {
+	args := m.Called(user)
+	return args.Get(0).(model.User), args.Error(1)
+}
+
+func (m *MockAuthUsecase) Login(request model.LoginRequest) (model.LoginResponse, error) {
+	args := m.Called(request)
+	return args.Get(0).(model.LoginResponse), args.Error(1)
+}
+
+func (m *MockAuthUsecase) GetUserById(id int) (model.User, error) {
+	args := m.Called(id)
+	return args.Get(0).(model.User), args.Error(1)
+}
+
+func (m *MockAuthUsecase) UpdateUserRole(id int, role string) (model.User, error) {
+	args := m.Called(id, role)
+	return args.Get(0).(model.User), args.Error(1)
+}
+
+// allowAllMiddleware meneruskan semua request agar test controller tidak
+// perlu membuat token. Pemeriksaan token dan role dites di package middleware.
+func allowAllMiddleware() *middleware.AuthMiddleware {
+	next := func(c *gin.Context) {
+		c.Next()
+	}
+	return &middleware.AuthMiddleware{
+		RequireToken: func(roles ...string) gin.HandlerFunc {
+			return next
+		},
+		CatalogRead: func() gin.HandlerFunc {
+			return next
+		},
+	}
+}
+
+func TestAuthController_Register(t *testing.T) {
+	mockUsecase := new(MockAuthUsecase)
+	router := gin.Default()
+	rg := router.Group("/api/v1")
+	NewAuthController(mockUsecase, rg, allowAllMiddleware()).Route()
+
+	// Happy Path
+	user := model.User{Username: "budi", Password: "rahasia123"}
+	mockUsecase.On("Register", user).Return(model.User{Id: 1, Username: "budi", PasswordHash: "hash", Role: model.RoleMember}, nil).Once()
+
+	body, err := json.Marshal(user)
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/auth/register", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"role":"member"`)
+	assert.NotContains(t, w.Body.String(), "hash")
+	assert.NotContains(t, w.Body.String(), "password")
+
+	// Sad Path: username sudah dipakai
+	mockUsecase.On("Register", user).Return(model.User{}, &usecase.ConflictError{Message: "username already taken"}).Once()
+
+	req, err = http.NewRequest(http.MethodPost, "/api/v1/auth/register", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestAuthController_Login(t *testing.T) {
+	mockUsecase := new(MockAuthUsecase)
+	router := gin.Default()
+	rg := router.Group("/api/v1")
+	NewAuthController(mockUsecase, rg, allowAllMiddleware()).Route()
+
+	// Happy Path
+	request := model.LoginRequest{Username: "budi", Password: "rahasia123"}
+	mockUsecase.On("Login", request).Return(model.LoginResponse{Token: "token"}, nil).Once()
+
+	body, err := json.Marshal(request)
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"token":"token"`)
+
+	// Sad Path: password salah
+	mockUsecase.On("Login", request).Return(model.LoginResponse{}, &usecase.UnauthorizedError{Message: "invalid username or password"}).Once()
+
+	req, err = http.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestAuthController_UpdateUserRole(t *testing.T) {
+	mockUsecase := new(MockAuthUsecase)
+	router := gin.Default()
+	rg := router.Group("/api/v1")
+	NewAuthController(mockUsecase, rg, allowAllMiddleware()).Route()
+
+	// Happy Path
+	mockUsecase.On("UpdateUserRole", 2, model.RoleLibrarian).Return(model.User{Id: 2, Username: "siti", Role: model.RoleLibrarian}, nil).Once()
+
+	req, err := http.NewRequest(http.MethodPut, "/api/v1/users/2/role", bytes.NewBufferString(`{"role":"librarian"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Sad Path: role tidak dikenal
+	mockUsecase.On("UpdateUserRole", 2, "admin").Return(model.User{}, &usecase.ValidationError{Message: "role must be librarian or member"}).Once()
+
+	req, err = http.NewRequest(http.MethodPut, "/api/v1/users/2/role", bytes.NewBufferString(`{"role":"admin"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
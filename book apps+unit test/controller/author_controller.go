@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"simple-clean-architecture/middleware"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/usecase"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthorController struct {
+	authorUsecase  usecase.AuthorUsecase
+	authMiddleware *middleware.AuthMiddleware
+	rg             *gin.RouterGroup
+}
+
+func (a *AuthorController) Route() {
+	a.rg.POST("/authors", a.authMiddleware.RequireToken(model.RoleLibrarian), a.CreateAuthor)
+	a.rg.GET("/authors", a.authMiddleware.CatalogRead(), a.GetAllAuthor)
+	a.rg.GET("/authors/:id", a.authMiddleware.CatalogRead(), a.GetAuthorById)
+	a.rg.PUT("/authors/:id", a.authMiddleware.RequireToken(model.RoleLibrarian), a.UpdateAuthor)
+	a.rg.DELETE("/authors/:id", a.authMiddleware.RequireToken(model.RoleLibrarian), a.DeleteAuthor)
+	a.rg.PUT("/books/:id/authors", a.authMiddleware.RequireToken(model.RoleLibrarian), a.SetBookAuthors)
+}
+
+func (a *AuthorController) CreateAuthor(c *gin.Context) {
+	var author model.Author
+	if err := c.ShouldBindJSON(&author); err != nil {
+		c.JSON(400, gin.H{"message": err.Error()})
+		return
+	}
+
+	newAuthor, err := a.authorUsecase.CreateAuthor(author)
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(201, newAuthor)
+}
+
+func (a *AuthorController) GetAllAuthor(c *gin.Context) {
+	authors, err := a.authorUsecase.GetAllAuthor()
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(200, authors)
+}
+
+func (a *AuthorController) GetAuthorById(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"message": "id must be a number"})
+		return
+	}
+
+	author, err := a.authorUsecase.GetAuthorById(id)
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(200, author)
+}
+
+func (a *AuthorController) UpdateAuthor(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"message": "id must be a number"})
+		return
+	}
+
+	var author model.Author
+	if err := c.ShouldBindJSON(&author); err != nil {
+		c.JSON(400, gin.H{"message": err.Error()})
+		return
+	}
+	author.Id = id
+
+	updatedAuthor, err := a.authorUsecase.UpdateAuthor(author, currentUserId(c))
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(200, updatedAuthor)
+}
+
+func (a *AuthorController) DeleteAuthor(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"message": "id must be a number"})
+		return
+	}
+
+	if err := a.authorUsecase.DeleteAuthor(id); err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Author deleted successfully"})
+}
+
+func (a *AuthorController) SetBookAuthors(c *gin.Context) {
+	bookId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"message": "id must be a number"})
+		return
+	}
+
+	var request model.BookRelationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, gin.H{"message": err.Error()})
+		return
+	}
+
+	authors, err := a.authorUsecase.SetBookAuthors(bookId, request.Ids, currentUserId(c))
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(200, authors)
+}
+
+func NewAuthorController(authorUsecase usecase.AuthorUsecase, rg *gin.RouterGroup, authMiddleware *middleware.AuthMiddleware) *AuthorController {
+	return &AuthorController{authorUsecase: authorUsecase, rg: rg, authMiddleware: authMiddleware}
+}
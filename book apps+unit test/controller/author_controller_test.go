@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/usecase"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuthorUsecase struct {
+	mock.Mock
+}
+
+func (m *MockAuthorUsecase) CreateAuthor(author model.Author) (model.Author, error) {
+	args := m.Called(author)
+	return args.Get(0).(model.Author), args.Error(1)
+}
+
+func (m *MockAuthorUsecase) GetAllAuthor() ([]model.Author, error) {
+	args := m.Called()
+	return args.Get(0).([]model.Author), args.Error(1)
+}
+
+func (m *MockAuthorUsecase) GetAuthorById(id int) (model.Author, error) {
+	args := m.Called(id)
+	return args.Get(0).(model.Author), args.Error(1)
+}
+
+func (m *MockAuthorUsecase) UpdateAuthor(author model.Author, userId int) (model.Author, error) {
+	args := m.Called(author, userId)
+	return args.Get(0).(model.Author), args.Error(1)
+}
+
+func (m *MockAuthorUsecase) DeleteAuthor(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockAuthorUsecase) SetBookAuthors(bookId int, authorIds []int, userId int) ([]model.Author, error) {
+	args := m.Called(bookId, authorIds, userId)
+	return args.Get(0).([]model.Author), args.Error(1)
+}
+
+func TestAuthorController_CreateAuthor(t *testing.T) {
+	mockUsecase := new(MockAuthorUsecase)
+	router := gin.Default()
+	NewAuthorController(mockUsecase, router.Group("/api/v1"), allowAllMiddleware()).Route()
+
+	// Happy Path
+	mockUsecase.On("CreateAuthor", model.Author{Name: "Alan Donovan"}).Return(model.Author{Id: 1, Name: "Alan Donovan"}, nil).Once()
+
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/authors", bytes.NewBufferString(`{"name": "Alan Donovan"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// Sad Path: nama sudah dipakai
+	mockUsecase.On("CreateAuthor", model.Author{Name: "Rob Pike"}).Return(model.Author{}, &usecase.ConflictError{Message: "author already exists"}).Once()
+
+	req, err = http.NewRequest(http.MethodPost, "/api/v1/authors", bytes.NewBufferString(`{"name": "Rob Pike"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestAuthorController_UpdateAndDeleteAuthor(t *testing.T) {
+	mockUsecase := new(MockAuthorUsecase)
+	router := gin.Default()
+	NewAuthorController(mockUsecase, router.Group("/api/v1"), allowAllMiddleware()).Route()
+
+	// Happy Path
+	mockUsecase.On("UpdateAuthor", model.Author{Id: 2, Name: "Rob Pike"}, 0).Return(model.Author{Id: 2, Name: "Rob Pike", BookCount: 3}, nil).Once()
+
+	req, err := http.NewRequest(http.MethodPut, "/api/v1/authors/2", bytes.NewBufferString(`{"name": "Rob Pike"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Sad Path: author masih punya buku
+	mockUsecase.On("DeleteAuthor", 2).Return(&usecase.ConflictError{Message: "author still has books"}).Once()
+
+	req, err = http.NewRequest(http.MethodDelete, "/api/v1/authors/2", nil)
+	assert.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Sad Path: id bukan angka
+	req, err = http.NewRequest(http.MethodGet, "/api/v1/authors/abc", nil)
+	assert.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestAuthorController_SetBookAuthors(t *testing.T) {
+	mockUsecase := new(MockAuthorUsecase)
+	router := gin.Default()
+	NewAuthorController(mockUsecase, router.Group("/api/v1"), allowAllMiddleware()).Route()
+
+	// Happy Path
+	expected := []model.Author{{Id: 2, Name: "Alan Donovan", BookCount: 1}, {Id: 3, Name: "Brian Kernighan", BookCount: 1}}
+	mockUsecase.On("SetBookAuthors", 1, []int{2, 3}, 0).Return(expected, nil).Once()
+
+	req, err := http.NewRequest(http.MethodPut, "/api/v1/books/1/authors", bytes.NewBufferString(`{"ids": [2, 3]}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var actual []model.Author
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &actual))
+	assert.Equal(t, expected, actual)
+
+	// Sad Path: author tidak ada
+	mockUsecase.On("SetBookAuthors", 1, []int{8}, 0).Return([]model.Author(nil), &usecase.ValidationError{Message: "author 8 does not exist"}).Once()
+
+	req, err = http.NewRequest(http.MethodPut, "/api/v1/books/1/authors", bytes.NewBufferString(`{"ids": [8]}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
@@ -8,24 +8,30 @@
 package controller
 
 import (
+	"simple-clean-architecture/middleware"
 	"simple-clean-architecture/model"
 	"simple-clean-architecture/usecase"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
 type BookController struct {
-	bookUsecase usecase.BookUsecase
-	rg          *gin.RouterGroup
+	bookUsecase    usecase.BookUsecase
+	authMiddleware *middleware.AuthMiddleware
+	rg             *gin.RouterGroup
 }
 
 func (b *BookController) Route() {
-	b.rg.POST("/books", b.CreateNewBook)
-	b.rg.GET("/books", b.GetAllBook)
-	b.rg.GET("/books/:id", b.GetBookById)
-	b.rg.PUT("/books/:id", b.UpdateBook)
-	b.rg.DELETE("/books/:id", b.DeleteBook)
+	b.rg.POST("/books", b.authMiddleware.RequireToken(model.RoleLibrarian), b.CreateNewBook)
+	b.rg.GET("/books", b.authMiddleware.CatalogRead(), b.GetAllBook)
+	b.rg.GET("/books/:id", b.authMiddleware.CatalogRead(), b.GetBookById)
+	b.rg.PUT("/books/:id", b.authMiddleware.RequireToken(model.RoleLibrarian), b.UpdateBook)
+	b.rg.DELETE("/books/:id", b.authMiddleware.RequireToken(model.RoleLibrarian), b.DeleteBook)
+	b.rg.POST("/books/:id/restore", b.authMiddleware.RequireToken(model.RoleLibrarian), b.RestoreBook)
+	b.rg.GET("/books/:id/history", b.authMiddleware.RequireToken(model.RoleLibrarian), b.GetBookHistory)
+	b.rg.GET("/books/metadata/:isbn", b.authMiddleware.CatalogRead(), b.LookupMetadata)
 }
 
 func (b *BookController) CreateNewBook(c *gin.Context) {
@@ -35,10 +41,10 @@ func (b *BookController) CreateNewBook(c *gin.Context) {
 		return
 	}
 
-	newBook, err := b.bookUsecase.CreateNewBook(book)
+	newBook, err := b.bookUsecase.CreateNewBook(book, currentUserId(c))
 
 	if err != nil {
-		c.JSON(500, gin.H{"message": err.Error()})
+		errorResponse(c, err)
 		return
 	}
 
@@ -46,10 +52,16 @@ func (b *BookController) CreateNewBook(c *gin.Context) {
 }
 
 func (b *BookController) GetAllBook(c *gin.Context) {
-	books, err := b.bookUsecase.GetAllBook()
+	var filter model.BookFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(400, gin.H{"message": err.Error()})
+		return
+	}
+
+	books, err := b.bookUsecase.GetAllBook(filter)
 
 	if err != nil {
-		c.JSON(500, gin.H{"message": err.Error()})
+		errorResponse(c, err)
 		return
 	}
 
@@ -63,31 +75,57 @@ func (b *BookController) GetBookById(c *gin.Context) {
 	book, err := b.bookUsecase.GetBookById(intID)
 
 	if err != nil {
-		c.JSON(500, gin.H{"message": err.Error()})
+		errorResponse(c, err)
+		return
+	}
+
+	etag := bookETag(book.Version, book)
+	c.Header("ETag", etag)
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(304)
 		return
 	}
 
 	c.JSON(200, book)
 }
 
+// UpdateBook mewajibkan header If-Match berisi ETag dari GET /books/:id agar
+// perubahan librarian lain sejak buku dibaca tidak tertimpa.
 func (b *BookController) UpdateBook(c *gin.Context) {
 	var book model.Book
 	id := c.Param("id")
 	intID, _ := strconv.Atoi(id)
 
+	ifMatch := strings.TrimSpace(c.GetHeader("If-Match"))
+	if ifMatch == "" {
+		c.JSON(428, gin.H{"message": "If-Match header is required"})
+		return
+	}
+
 	if err := c.ShouldBindJSON(&book); err != nil {
 		c.JSON(400, gin.H{"message": err.Error()})
 		return
 	}
 	book.Id = intID
+	// version 0 untuk If-Match: * berarti update tanpa melihat version
+	book.Version = 0
+	if ifMatch != "*" {
+		version, ok := etagVersion(ifMatch)
+		if !ok {
+			c.JSON(412, gin.H{"message": "If-Match does not match the current version of the book"})
+			return
+		}
+		book.Version = version
+	}
 
-	updatedBook, err := b.bookUsecase.UpdateBook(&book)
+	updatedBook, err := b.bookUsecase.UpdateBook(&book, currentUserId(c))
 
 	if err != nil {
-		c.JSON(500, gin.H{"message": err.Error()})
+		errorResponse(c, err)
 		return
 	}
 
+	c.Header("ETag", bookETag(updatedBook.Version, updatedBook))
 	c.JSON(200, updatedBook)
 }
 
@@ -95,16 +133,65 @@ func (b *BookController) DeleteBook(c *gin.Context) {
 	id := c.Param("id")
 	intID, _ := strconv.Atoi(id)
 
-	err := b.bookUsecase.DeleteBook(intID)
+	err := b.bookUsecase.DeleteBook(intID, currentUserId(c))
 
 	if err != nil {
-		c.JSON(500, gin.H{"message": err.Error()})
+		errorResponse(c, err)
 		return
 	}
 
 	c.JSON(200, gin.H{"message": "Book deleted successfully"})
 }
 
-func NewBookController(bookUsecase usecase.BookUsecase, rg *gin.RouterGroup) *BookController {
-	return &BookController{bookUsecase: bookUsecase, rg: rg}
+// RestoreBook mengembalikan buku yang sudah di-soft delete ke katalog.
+func (b *BookController) RestoreBook(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"message": "id must be a number"})
+		return
+	}
+
+	book, err := b.bookUsecase.RestoreBook(id, currentUserId(c))
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.Header("ETag", bookETag(book.Version, book))
+	c.JSON(200, book)
+}
+
+// GetBookHistory menampilkan perubahan per field dari setiap revisi buku,
+// dimulai dari revisi terbaru.
+func (b *BookController) GetBookHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"message": "id must be a number"})
+		return
+	}
+
+	history, err := b.bookUsecase.GetBookHistory(id)
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(200, history)
+}
+
+func (b *BookController) LookupMetadata(c *gin.Context) {
+	metadata, err := b.bookUsecase.LookupMetadata(c.Param("isbn"))
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(200, metadata)
+}
+
+func NewBookController(bookUsecase usecase.BookUsecase, rg *gin.RouterGroup, authMiddleware *middleware.AuthMiddleware) *BookController {
+	return &BookController{bookUsecase: bookUsecase, rg: rg, authMiddleware: authMiddleware}
 }
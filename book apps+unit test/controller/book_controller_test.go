@@ -6,7 +6,11 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"simple-clean-architecture/config"
+	"simple-clean-architecture/middleware"
 	"simple-clean-architecture/model"
+	"simple-clean-architecture/usecase"
+	"simple-clean-architecture/utils/service"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -18,36 +22,51 @@ type MockBookUsecase struct {
 	mock.Mock
 }
 
-func (m *MockBookUsecase) CreateNewBook(book model.Book) (model.Book, error) {
-	args := m.Called(book)
+func (m *MockBookUsecase) CreateNewBook(book model.Book, userId int) (model.Book, error) {
+	args := m.Called(book, userId)
 	return args.Get(0).(model.Book), args.Error(1)
 }
 
-func (m *MockBookUsecase) GetAllBook() ([]model.Book, error) {
-	args := m.Called()
-	return args.Get(0).([]model.Book), args.Error(1)
+func (m *MockBookUsecase) GetAllBook(filter model.BookFilter) (model.BookPage, error) {
+	args := m.Called(filter)
+	return args.Get(0).(model.BookPage), args.Error(1)
 }
 
-func (m *MockBookUsecase) GetBookById(id int) (model.Book, error) {
+func (m *MockBookUsecase) GetBookById(id int) (model.BookDetail, error) {
 	args := m.Called(id)
+	return args.Get(0).(model.BookDetail), args.Error(1)
+}
+
+func (m *MockBookUsecase) UpdateBook(book *model.Book, userId int) (model.Book, error) {
+	args := m.Called(book, userId)
 	return args.Get(0).(model.Book), args.Error(1)
 }
 
-func (m *MockBookUsecase) UpdateBook(book *model.Book) (model.Book, error) {
-	args := m.Called(book)
+func (m *MockBookUsecase) DeleteBook(id int, userId int) error {
+	args := m.Called(id, userId)
+	return args.Error(0)
+}
+
+func (m *MockBookUsecase) RestoreBook(id int, userId int) (model.Book, error) {
+	args := m.Called(id, userId)
 	return args.Get(0).(model.Book), args.Error(1)
 }
 
-func (m *MockBookUsecase) DeleteBook(id int) error {
+func (m *MockBookUsecase) GetBookHistory(id int) ([]model.BookHistoryEntry, error) {
 	args := m.Called(id)
-	return args.Error(0)
+	return args.Get(0).([]model.BookHistoryEntry), args.Error(1)
+}
+
+func (m *MockBookUsecase) LookupMetadata(isbn string) (model.BookMetadata, error) {
+	args := m.Called(isbn)
+	return args.Get(0).(model.BookMetadata), args.Error(1)
 }
 
 func TestBookController_CreateNewBook(t *testing.T) {
 	mockUsecase := new(MockBookUsecase)
 	router := gin.Default()
 	rg := router.Group("/api/v1")
-	NewBookController(mockUsecase, rg).Route()
+	NewBookController(mockUsecase, rg, allowAllMiddleware()).Route()
 
 	// Happy Path
 	book := model.Book{
@@ -61,7 +80,7 @@ func TestBookController_CreateNewBook(t *testing.T) {
 	// Kita gunakan mock.MatchedBy untuk memastikan argumen yang masuk sesuai
 	mockUsecase.On("CreateNewBook", mock.MatchedBy(func(b model.Book) bool {
 		return b.Title == book.Title
-	})).Return(model.Book{Id: 1, Title: "Test Book", Author: "Test Author", ReleaseYear: 2023, Pages: 100}, nil).Once()
+	}), 0).Return(model.Book{Id: 1, Title: "Test Book", Author: "Test Author", ReleaseYear: 2023, Pages: 100}, nil).Once()
 
 	body, err := json.Marshal(book)
 	assert.NoError(t, err)
@@ -91,11 +110,11 @@ func TestBookController_GetAllBook(t *testing.T) {
 	mockUsecase := new(MockBookUsecase)
 	router := gin.Default()
 	rg := router.Group("/api/v1")
-	NewBookController(mockUsecase, rg).Route()
+	NewBookController(mockUsecase, rg, allowAllMiddleware()).Route()
 
 	// Happy Path
-	expectedBooks := []model.Book{{Id: 1, Title: "Book 1"}}
-	mockUsecase.On("GetAllBook").Return(expectedBooks, nil).Once()
+	expectedPage := model.BookPage{Data: []model.Book{{Id: 1, Title: "Book 1"}}, Total: 1, Page: 1, Limit: 10}
+	mockUsecase.On("GetAllBook", model.BookFilter{}).Return(expectedPage, nil).Once()
 
 	req, err := http.NewRequest(http.MethodGet, "/api/v1/books", nil)
 	assert.NoError(t, err)
@@ -105,10 +124,56 @@ func TestBookController_GetAllBook(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	var actualBooks []model.Book
-	err = json.Unmarshal(w.Body.Bytes(), &actualBooks)
+	var actualPage model.BookPage
+	err = json.Unmarshal(w.Body.Bytes(), &actualPage)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedPage, actualPage)
+	mockUsecase.AssertExpectations(t)
+
+	// Happy Path: query parameter diteruskan ke usecase
+	filter := model.BookFilter{Title: "go", Author: "pike", AuthorId: 2, CategoryId: 4, MinYear: 2000, MaxYear: 2020, MinPages: 100, MaxPages: 500, Sort: "title", Order: "desc", Limit: 5, Cursor: "abc"}
+	mockUsecase.On("GetAllBook", filter).Return(model.BookPage{Data: []model.Book{}, Limit: 5}, nil).Once()
+
+	req, err = http.NewRequest(http.MethodGet, "/api/v1/books?title=go&author=pike&authorId=2&categoryId=4&minYear=2000&maxYear=2020&minPages=100&maxPages=500&sort=title&order=desc&limit=5&cursor=abc", nil)
+	assert.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUsecase.AssertExpectations(t)
+
+	// Sad Path: query parameter bukan angka
+	req, err = http.NewRequest(http.MethodGet, "/api/v1/books?minYear=abc", nil)
+	assert.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Sad Path: filter tidak valid
+	mockUsecase.On("GetAllBook", model.BookFilter{Sort: "price"}).Return(model.BookPage{}, &usecase.ValidationError{Message: "sort must be one of id, title, author, releaseYear or pages"}).Once()
+
+	req, err = http.NewRequest(http.MethodGet, "/api/v1/books?sort=price", nil)
 	assert.NoError(t, err)
-	assert.Equal(t, expectedBooks, actualBooks)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "sort must be one of")
+
+	// Sad Path: Usecase returns error
+	mockUsecase.On("GetAllBook", model.BookFilter{Page: 2}).Return(model.BookPage{}, errors.New("connection refused")).Once()
+
+	req, err = http.NewRequest(http.MethodGet, "/api/v1/books?page=2", nil)
+	assert.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
 	mockUsecase.AssertExpectations(t)
 }
 
@@ -116,10 +181,13 @@ func TestBookController_GetBookById(t *testing.T) {
 	mockUsecase := new(MockBookUsecase)
 	router := gin.Default()
 	rg := router.Group("/api/v1")
-	NewBookController(mockUsecase, rg).Route()
+	NewBookController(mockUsecase, rg, allowAllMiddleware()).Route()
 
 	// Happy Path
-	expectedBook := model.Book{Id: 1, Title: "Book 1"}
+	expectedBook := model.BookDetail{
+		Book:         model.Book{Id: 1, Title: "Book 1"},
+		Availability: model.BookAvailability{Total: 3, Available: 1, OnLoan: 1, Repair: 1},
+	}
 	mockUsecase.On("GetBookById", 1).Return(expectedBook, nil).Once()
 
 	req, err := http.NewRequest(http.MethodGet, "/api/v1/books/1", nil)
@@ -129,14 +197,57 @@ func TestBookController_GetBookById(t *testing.T) {
 	router.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
-	var actualBook model.Book
+	var actualBook model.BookDetail
 	err = json.Unmarshal(w.Body.Bytes(), &actualBook)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedBook, actualBook)
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+	mockUsecase.AssertExpectations(t)
+
+	// Happy Path: ETag masih sama dijawab 304 tanpa body
+	mockUsecase.On("GetBookById", 1).Return(expectedBook, nil).Once()
+
+	req, err = http.NewRequest(http.MethodGet, "/api/v1/books/1", nil)
+	assert.NoError(t, err)
+	req.Header.Set("If-None-Match", `"0-0000000000000000", `+etag)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+
+	// Happy Path: jumlah eksemplar berubah, ETag ikut berubah walaupun version sama
+	changed := expectedBook
+	changed.Availability.Available = 0
+	mockUsecase.On("GetBookById", 1).Return(changed, nil).Once()
+
+	req, err = http.NewRequest(http.MethodGet, "/api/v1/books/1", nil)
+	assert.NoError(t, err)
+	req.Header.Set("If-None-Match", etag)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+
+	// Sad Path: Book not found
+	mockUsecase.On("GetBookById", 3).Return(model.BookDetail{}, &usecase.NotFoundError{Message: "book not found"}).Once()
+
+	req, err = http.NewRequest(http.MethodGet, "/api/v1/books/3", nil)
+	assert.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
 	mockUsecase.AssertExpectations(t)
 
 	// Sad Path: Usecase returns error
-	mockUsecase.On("GetBookById", 2).Return(model.Book{}, errors.New("not found")).Once()
+	mockUsecase.On("GetBookById", 2).Return(model.BookDetail{}, errors.New("not found")).Once()
 
 	req, err = http.NewRequest(http.MethodGet, "/api/v1/books/2", nil)
 	assert.NoError(t, err)
@@ -152,7 +263,7 @@ func TestBookController_UpdateBook(t *testing.T) {
 	mockUsecase := new(MockBookUsecase)
 	router := gin.Default()
 	rg := router.Group("/api/v1")
-	NewBookController(mockUsecase, rg).Route()
+	NewBookController(mockUsecase, rg, allowAllMiddleware()).Route()
 
 	// Happy Path
 	book := model.Book{
@@ -162,19 +273,52 @@ func TestBookController_UpdateBook(t *testing.T) {
 		Pages:       100,
 	}
 
-	mockUsecase.On("UpdateBook", &model.Book{Id: 1, Title: "Test Book", Author: "Test Author", ReleaseYear: 2023, Pages: 100}).Return(model.Book{Id: 1, Title: "Test Book", Author: "Test Author", ReleaseYear: 2023, Pages: 100}, nil).Once()
+	mockUsecase.On("UpdateBook", &model.Book{Id: 1, Title: "Test Book", Author: "Test Author", ReleaseYear: 2023, Pages: 100, Version: 3}, 0).Return(model.Book{Id: 1, Title: "Test Book", Author: "Test Author", ReleaseYear: 2023, Pages: 100, Version: 4}, nil).Once()
 
 	body, err := json.Marshal(book)
 	assert.NoError(t, err)
 
-	req, err := http.NewRequest(http.MethodPut, "/api/v1/books/1", bytes.NewBuffer(body))
-	assert.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
+	put := func(ifMatch string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodPut, "/api/v1/books/1", bytes.NewBuffer(body))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
 
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
+	w := put(`"3-0123456789abcdef"`)
 
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Regexp(t, `^"4-[0-9a-f]{16}"$`, w.Header().Get("ETag"))
+
+	// Happy Path: If-Match: * menyimpan tanpa melihat version
+	mockUsecase.On("UpdateBook", &model.Book{Id: 1, Title: "Test Book", Author: "Test Author", ReleaseYear: 2023, Pages: 100}, 0).Return(model.Book{Id: 1, Version: 5}, nil).Once()
+
+	w = put("*")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Sad Path: tanpa If-Match
+	w = put("")
+
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+
+	// Sad Path: If-Match bukan ETag buku
+	w = put("not-an-etag")
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	// Sad Path: buku sudah diubah orang lain
+	mockUsecase.On("UpdateBook", &model.Book{Id: 1, Title: "Test Book", Author: "Test Author", ReleaseYear: 2023, Pages: 100, Version: 2}, 0).Return(model.Book{}, &usecase.PreconditionFailedError{Message: "book has been modified, reload it and try again"}).Once()
+
+	w = put(`"2-0123456789abcdef"`)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
 	mockUsecase.AssertExpectations(t)
 }
 
@@ -182,10 +326,10 @@ func TestBookController_DeleteBook(t *testing.T) {
 	mockUsecase := new(MockBookUsecase)
 	router := gin.Default()
 	rg := router.Group("/api/v1")
-	NewBookController(mockUsecase, rg).Route()
+	NewBookController(mockUsecase, rg, allowAllMiddleware()).Route()
 
 	// Happy Path
-	mockUsecase.On("DeleteBook", 1).Return(nil).Once()
+	mockUsecase.On("DeleteBook", 1, 0).Return(nil).Once()
 
 	req, err := http.NewRequest(http.MethodDelete, "/api/v1/books/1", nil)
 	assert.NoError(t, err)
@@ -198,4 +342,181 @@ func TestBookController_DeleteBook(t *testing.T) {
 	json.Unmarshal(w.Body.Bytes(), &response)
 	assert.Equal(t, "Book deleted successfully", response["message"])
 	mockUsecase.AssertExpectations(t)
+
+	// Sad Path: Book still has copies on loan
+	mockUsecase.On("DeleteBook", 2, 0).Return(&usecase.ConflictError{Message: "book still has copies on loan"}).Once()
+
+	req, err = http.NewRequest(http.MethodDelete, "/api/v1/books/2", nil)
+	assert.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestBookController_RestoreBook(t *testing.T) {
+	mockUsecase := new(MockBookUsecase)
+	router := gin.Default()
+	rg := router.Group("/api/v1")
+	NewBookController(mockUsecase, rg, allowAllMiddleware()).Route()
+
+	// Happy Path
+	restored := model.Book{Id: 1, Title: "Test Book", Author: "Test Author", ReleaseYear: 2023, Pages: 100, Version: 4}
+	mockUsecase.On("RestoreBook", 1, 0).Return(restored, nil).Once()
+
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/books/1/restore", nil)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, bookETag(4, restored), w.Header().Get("ETag"))
+	var actual model.Book
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &actual))
+	assert.Equal(t, restored, actual)
+
+	// Sad Path: buku tidak sedang dihapus
+	mockUsecase.On("RestoreBook", 2, 0).Return(model.Book{}, &usecase.ConflictError{Message: "book is not deleted"}).Once()
+
+	req, err = http.NewRequest(http.MethodPost, "/api/v1/books/2/restore", nil)
+	assert.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Sad Path: id bukan angka
+	req, err = http.NewRequest(http.MethodPost, "/api/v1/books/abc/restore", nil)
+	assert.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestBookController_GetBookHistory(t *testing.T) {
+	mockUsecase := new(MockBookUsecase)
+	router := gin.Default()
+	rg := router.Group("/api/v1")
+	NewBookController(mockUsecase, rg, allowAllMiddleware()).Route()
+
+	// Happy Path
+	expected := []model.BookHistoryEntry{
+		{Version: 2, Action: model.BookActionUpdate, UserId: 1, Username: "librarian", Changes: []model.BookFieldChange{{Field: "pages", Old: float64(100), New: float64(120)}}},
+		{Version: 1, Action: model.BookActionCreate, Changes: []model.BookFieldChange{{Field: "title", New: "Test Book"}}},
+	}
+	mockUsecase.On("GetBookHistory", 1).Return(expected, nil).Once()
+
+	req, err := http.NewRequest(http.MethodGet, "/api/v1/books/1/history", nil)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var actual []model.BookHistoryEntry
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &actual))
+	assert.Equal(t, expected, actual)
+
+	// Sad Path: buku tidak ditemukan
+	mockUsecase.On("GetBookHistory", 9).Return([]model.BookHistoryEntry(nil), &usecase.NotFoundError{Message: "book not found"}).Once()
+
+	req, err = http.NewRequest(http.MethodGet, "/api/v1/books/9/history", nil)
+	assert.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestBookController_LookupMetadata(t *testing.T) {
+	mockUsecase := new(MockBookUsecase)
+	router := gin.Default()
+	rg := router.Group("/api/v1")
+	NewBookController(mockUsecase, rg, allowAllMiddleware()).Route()
+
+	// Happy Path
+	expected := model.BookMetadata{Isbn: "9780134190440", Isbn10: "0134190440", Title: "The Go Programming Language", Author: "Alan Donovan", ReleaseYear: 2015, Pages: 380}
+	mockUsecase.On("LookupMetadata", "0-13-419044-0").Return(expected, nil).Once()
+
+	req, err := http.NewRequest(http.MethodGet, "/api/v1/books/metadata/0-13-419044-0", nil)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var actual model.BookMetadata
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &actual))
+	assert.Equal(t, expected, actual)
+
+	// Sad Path: isbn tidak ditemukan
+	mockUsecase.On("LookupMetadata", "9791000000008").Return(model.BookMetadata{}, &usecase.NotFoundError{Message: "metadata not found"}).Once()
+
+	req, err = http.NewRequest(http.MethodGet, "/api/v1/books/metadata/9791000000008", nil)
+	assert.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+// TestBookController_RouteAuthorization memakai middleware sungguhan untuk
+// memastikan route tulis dijaga role librarian dan GET katalog tetap bisa
+// dibaca tanpa token.
+func TestBookController_RouteAuthorization(t *testing.T) {
+	jwtService := service.NewJWTService(config.TokenConfig{ApplicationName: "test", JwtSignatureKey: []byte("secret")})
+	mockAuthUsecase := new(MockAuthUsecase)
+	mockAuthUsecase.On("GetUserById", 1).Return(model.User{Id: 1, Role: model.RoleLibrarian}, nil)
+	mockAuthUsecase.On("GetUserById", 2).Return(model.User{Id: 2, Role: model.RoleMember}, nil)
+
+	mockUsecase := new(MockBookUsecase)
+	mockUsecase.On("GetBookById", 1).Return(model.BookDetail{Book: model.Book{Id: 1}}, nil)
+	mockUsecase.On("DeleteBook", 1, 1).Return(nil)
+
+	router := gin.Default()
+	rg := router.Group("/api/v1")
+	NewBookController(mockUsecase, rg, middleware.NewAuthMiddleware(jwtService, mockAuthUsecase, true)).Route()
+
+	librarianToken, _, err := jwtService.CreateToken(model.User{Id: 1, Role: model.RoleLibrarian})
+	assert.NoError(t, err)
+	memberToken, _, err := jwtService.CreateToken(model.User{Id: 2, Role: model.RoleMember})
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name   string
+		method string
+		token  string
+		status int
+	}{
+		{"anonymous read", http.MethodGet, "", http.StatusOK},
+		{"anonymous delete", http.MethodDelete, "", http.StatusUnauthorized},
+		{"member delete", http.MethodDelete, memberToken, http.StatusForbidden},
+		{"librarian delete", http.MethodDelete, librarianToken, http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		req, err := http.NewRequest(tc.method, "/api/v1/books/1", nil)
+		assert.NoError(t, err)
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tc.status, w.Code, tc.name)
+	}
+
+	mockUsecase.AssertNumberOfCalls(t, "DeleteBook", 1)
 }
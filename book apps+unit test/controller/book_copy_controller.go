@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"simple-clean-architecture/middleware"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/usecase"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BookCopyController struct {
+	copyUsecase    usecase.BookCopyUsecase
+	authMiddleware *middleware.AuthMiddleware
+	rg             *gin.RouterGroup
+}
+
+func (b *BookCopyController) Route() {
+	b.rg.POST("/books/:id/copies", b.authMiddleware.RequireToken(model.RoleLibrarian), b.CreateCopy)
+	b.rg.GET("/books/:id/copies", b.authMiddleware.CatalogRead(), b.GetCopiesByBookId)
+	b.rg.PUT("/copies/:id", b.authMiddleware.RequireToken(model.RoleLibrarian), b.UpdateCopy)
+}
+
+func (b *BookCopyController) CreateCopy(c *gin.Context) {
+	bookId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"message": "id must be a number"})
+		return
+	}
+
+	var copy model.BookCopy
+	if err := c.ShouldBindJSON(&copy); err != nil {
+		c.JSON(400, gin.H{"message": err.Error()})
+		return
+	}
+
+	newCopy, err := b.copyUsecase.CreateCopy(bookId, copy)
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(201, newCopy)
+}
+
+func (b *BookCopyController) GetCopiesByBookId(c *gin.Context) {
+	bookId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"message": "id must be a number"})
+		return
+	}
+
+	copies, err := b.copyUsecase.GetCopiesByBookId(bookId)
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(200, copies)
+}
+
+func (b *BookCopyController) UpdateCopy(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"message": "id must be a number"})
+		return
+	}
+
+	var copy model.BookCopy
+	if err := c.ShouldBindJSON(&copy); err != nil {
+		c.JSON(400, gin.H{"message": err.Error()})
+		return
+	}
+	copy.Id = id
+
+	updatedCopy, err := b.copyUsecase.UpdateCopy(copy)
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(200, updatedCopy)
+}
+
+func NewBookCopyController(copyUsecase usecase.BookCopyUsecase, rg *gin.RouterGroup, authMiddleware *middleware.AuthMiddleware) *BookCopyController {
+	return &BookCopyController{copyUsecase: copyUsecase, rg: rg, authMiddleware: authMiddleware}
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/usecase"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBookCopyUsecase struct {
+	mock.Mock
+}
+
+func (m *MockBookCopyUsecase) CreateCopy(bookId int, copy model.BookCopy) (model.BookCopy, error) {
+	args := m.Called(bookId, copy)
+	return args.Get(0).(model.BookCopy), args.Error(1)
+}
+
+func (m *MockBookCopyUsecase) GetCopiesByBookId(bookId int) ([]model.BookCopy, error) {
+	args := m.Called(bookId)
+	return args.Get(0).([]model.BookCopy), args.Error(1)
+}
+
+func (m *MockBookCopyUsecase) UpdateCopy(copy model.BookCopy) (model.BookCopy, error) {
+	args := m.Called(copy)
+	return args.Get(0).(model.BookCopy), args.Error(1)
+}
+
+func TestBookCopyController_CreateCopy(t *testing.T) {
+	mockUsecase := new(MockBookCopyUsecase)
+	router := gin.Default()
+	rg := router.Group("/api/v1")
+	NewBookCopyController(mockUsecase, rg, allowAllMiddleware()).Route()
+
+	// Happy Path
+	copy := model.BookCopy{Barcode: "B-001", ShelfLocation: "A1"}
+	mockUsecase.On("CreateCopy", 1, copy).Return(model.BookCopy{Id: 7, BookId: 1, Barcode: "B-001", Condition: "good", ShelfLocation: "A1", Status: model.CopyStatusAvailable}, nil).Once()
+
+	body, err := json.Marshal(copy)
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/books/1/copies", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockUsecase.AssertExpectations(t)
+
+	// Sad Path: barcode sudah terdaftar
+	mockUsecase.On("CreateCopy", 1, copy).Return(model.BookCopy{}, &usecase.ConflictError{Message: "barcode is already registered"}).Once()
+
+	req, err = http.NewRequest(http.MethodPost, "/api/v1/books/1/copies", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestBookCopyController_GetCopiesByBookId(t *testing.T) {
+	mockUsecase := new(MockBookCopyUsecase)
+	router := gin.Default()
+	rg := router.Group("/api/v1")
+	NewBookCopyController(mockUsecase, rg, allowAllMiddleware()).Route()
+
+	// Happy Path
+	expected := []model.BookCopy{{Id: 1, BookId: 1, Barcode: "B-001"}, {Id: 2, BookId: 1, Barcode: "B-002"}}
+	mockUsecase.On("GetCopiesByBookId", 1).Return(expected, nil).Once()
+
+	req, err := http.NewRequest(http.MethodGet, "/api/v1/books/1/copies", nil)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var actual []model.BookCopy
+	err = json.Unmarshal(w.Body.Bytes(), &actual)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	// Sad Path: id bukan angka
+	req, err = http.NewRequest(http.MethodGet, "/api/v1/books/abc/copies", nil)
+	assert.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestBookCopyController_UpdateCopy(t *testing.T) {
+	mockUsecase := new(MockBookCopyUsecase)
+	router := gin.Default()
+	rg := router.Group("/api/v1")
+	NewBookCopyController(mockUsecase, rg, allowAllMiddleware()).Route()
+
+	// Happy Path
+	mockUsecase.On("UpdateCopy", model.BookCopy{Id: 2, Status: model.CopyStatusLost}).Return(model.BookCopy{Id: 2, Status: model.CopyStatusLost}, nil).Once()
+
+	req, err := http.NewRequest(http.MethodPut, "/api/v1/copies/2", bytes.NewBufferString(`{"status":"lost"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Sad Path: eksemplar sedang dipinjam
+	mockUsecase.On("UpdateCopy", model.BookCopy{Id: 3, Status: model.CopyStatusLost}).Return(model.BookCopy{}, &usecase.ConflictError{Message: "book copy is on loan"}).Once()
+
+	req, err = http.NewRequest(http.MethodPut, "/api/v1/copies/3", bytes.NewBufferString(`{"status":"lost"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
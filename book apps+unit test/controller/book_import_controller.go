@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"simple-clean-architecture/middleware"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/usecase"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var bookExportContentTypes = map[string]string{
+	"csv":  "text/csv; charset=utf-8",
+	"json": "application/json; charset=utf-8",
+}
+
+type BookImportController struct {
+	importUsecase  usecase.BookImportUsecase
+	authMiddleware *middleware.AuthMiddleware
+	rg             *gin.RouterGroup
+}
+
+func (b *BookImportController) Route() {
+	b.rg.POST("/books/import", b.authMiddleware.RequireToken(model.RoleLibrarian), b.ImportBooks)
+	b.rg.GET("/books/export", b.authMiddleware.CatalogRead(), b.ExportBooks)
+}
+
+// ImportBooks membaca body request sebagai CSV atau JSON. Format diambil dari
+// query parameter format, atau dari Content-Type jika tidak diisi.
+func (b *BookImportController) ImportBooks(c *gin.Context) {
+	format := c.Query("format")
+	if format == "" {
+		format = "json"
+		if strings.HasPrefix(c.ContentType(), "text/csv") {
+			format = "csv"
+		}
+	}
+
+	dryRun := false
+	if value := c.Query("dryRun"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			c.JSON(400, gin.H{"message": "dryRun must be true or false"})
+			return
+		}
+	}
+
+	report, err := b.importUsecase.ImportBooks(c.Request.Body, format, dryRun, currentUserId(c))
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(200, report)
+}
+
+// ExportBooks mengirim katalog sebagai file. Karena response sudah mulai
+// dikirim, error di tengah export hanya dicatat dan koneksi diakhiri.
+func (b *BookImportController) ExportBooks(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	contentType, ok := bookExportContentTypes[format]
+	if !ok {
+		c.JSON(400, gin.H{"message": "format must be csv or json"})
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="books.`+format+`"`)
+	c.Status(200)
+
+	if err := b.importUsecase.ExportBooks(c.Writer, format); err != nil {
+		c.Error(err)
+		c.Abort()
+	}
+}
+
+func NewBookImportController(importUsecase usecase.BookImportUsecase, rg *gin.RouterGroup, authMiddleware *middleware.AuthMiddleware) *BookImportController {
+	return &BookImportController{importUsecase: importUsecase, rg: rg, authMiddleware: authMiddleware}
+}
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/usecase"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBookImportUsecase struct {
+	mock.Mock
+}
+
+func (m *MockBookImportUsecase) ImportBooks(r io.Reader, format string, dryRun bool, userId int) (model.BookImportReport, error) {
+	args := m.Called(r, format, dryRun, userId)
+	return args.Get(0).(model.BookImportReport), args.Error(1)
+}
+
+// ExportBooks menulis string dari Return(output, err) ke w.
+func (m *MockBookImportUsecase) ExportBooks(w io.Writer, format string) error {
+	args := m.Called(w, format)
+	io.WriteString(w, args.String(0))
+	return args.Error(1)
+}
+
+func TestBookImportController_ImportBooks(t *testing.T) {
+	mockUsecase := new(MockBookImportUsecase)
+	router := gin.Default()
+	rg := router.Group("/api/v1")
+	NewBookImportController(mockUsecase, rg, allowAllMiddleware()).Route()
+
+	// Happy Path: format diambil dari Content-Type
+	report := model.BookImportReport{DryRun: true, Total: 1, Created: 1, Rows: []model.BookImportRow{{Row: 1, Title: "Book", Status: model.ImportStatusCreated}}}
+	mockUsecase.On("ImportBooks", mock.Anything, "csv", true, 0).Return(report, nil).Once()
+
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/books/import?dryRun=true", bytes.NewBufferString("title,author,releaseYear,pages\nBook,Author,2020,10\n"))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "text/csv")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"dryRun":true`)
+
+	// Sad Path: dryRun tidak valid
+	req, err = http.NewRequest(http.MethodPost, "/api/v1/books/import?dryRun=maybe", bytes.NewBufferString("[]"))
+	assert.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Sad Path: header file tidak valid
+	mockUsecase.On("ImportBooks", mock.Anything, "json", false, 0).Return(model.BookImportReport{}, &usecase.ValidationError{Message: "json body must be an array of books"}).Once()
+
+	req, err = http.NewRequest(http.MethodPost, "/api/v1/books/import", bytes.NewBufferString("{}"))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestBookImportController_ExportBooks(t *testing.T) {
+	mockUsecase := new(MockBookImportUsecase)
+	router := gin.Default()
+	rg := router.Group("/api/v1")
+	NewBookImportController(mockUsecase, rg, allowAllMiddleware()).Route()
+
+	// Happy Path
+	mockUsecase.On("ExportBooks", mock.Anything, "csv").Return("id,isbn,title,author,releaseYear,pages\n", nil).Once()
+
+	req, err := http.NewRequest(http.MethodGet, "/api/v1/books/export?format=csv", nil)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="books.csv"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "id,isbn,title,author,releaseYear,pages\n", w.Body.String())
+
+	// Sad Path: format tidak dikenal
+	req, err = http.NewRequest(http.MethodGet, "/api/v1/books/export?format=xml", nil)
+	assert.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"simple-clean-architecture/middleware"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/usecase"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryController struct {
+	categoryUsecase usecase.CategoryUsecase
+	authMiddleware  *middleware.AuthMiddleware
+	rg              *gin.RouterGroup
+}
+
+func (cc *CategoryController) Route() {
+	cc.rg.POST("/categories", cc.authMiddleware.RequireToken(model.RoleLibrarian), cc.CreateCategory)
+	cc.rg.GET("/categories", cc.authMiddleware.CatalogRead(), cc.GetAllCategory)
+	cc.rg.GET("/categories/:id", cc.authMiddleware.CatalogRead(), cc.GetCategoryById)
+	cc.rg.PUT("/categories/:id", cc.authMiddleware.RequireToken(model.RoleLibrarian), cc.UpdateCategory)
+	cc.rg.DELETE("/categories/:id", cc.authMiddleware.RequireToken(model.RoleLibrarian), cc.DeleteCategory)
+	cc.rg.PUT("/books/:id/categories", cc.authMiddleware.RequireToken(model.RoleLibrarian), cc.SetBookCategories)
+}
+
+func (cc *CategoryController) CreateCategory(c *gin.Context) {
+	var category model.Category
+	if err := c.ShouldBindJSON(&category); err != nil {
+		c.JSON(400, gin.H{"message": err.Error()})
+		return
+	}
+
+	newCategory, err := cc.categoryUsecase.CreateCategory(category)
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(201, newCategory)
+}
+
+func (cc *CategoryController) GetAllCategory(c *gin.Context) {
+	categories, err := cc.categoryUsecase.GetAllCategory()
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(200, categories)
+}
+
+func (cc *CategoryController) GetCategoryById(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"message": "id must be a number"})
+		return
+	}
+
+	category, err := cc.categoryUsecase.GetCategoryById(id)
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(200, category)
+}
+
+func (cc *CategoryController) UpdateCategory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"message": "id must be a number"})
+		return
+	}
+
+	var category model.Category
+	if err := c.ShouldBindJSON(&category); err != nil {
+		c.JSON(400, gin.H{"message": err.Error()})
+		return
+	}
+	category.Id = id
+
+	updatedCategory, err := cc.categoryUsecase.UpdateCategory(category)
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(200, updatedCategory)
+}
+
+func (cc *CategoryController) DeleteCategory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"message": "id must be a number"})
+		return
+	}
+
+	if err := cc.categoryUsecase.DeleteCategory(id); err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Category deleted successfully"})
+}
+
+func (cc *CategoryController) SetBookCategories(c *gin.Context) {
+	bookId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"message": "id must be a number"})
+		return
+	}
+
+	var request model.BookRelationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, gin.H{"message": err.Error()})
+		return
+	}
+
+	categories, err := cc.categoryUsecase.SetBookCategories(bookId, request.Ids)
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(200, categories)
+}
+
+func NewCategoryController(categoryUsecase usecase.CategoryUsecase, rg *gin.RouterGroup, authMiddleware *middleware.AuthMiddleware) *CategoryController {
+	return &CategoryController{categoryUsecase: categoryUsecase, rg: rg, authMiddleware: authMiddleware}
+}
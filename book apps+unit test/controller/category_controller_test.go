@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/usecase"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCategoryUsecase struct {
+	mock.Mock
+}
+
+func (m *MockCategoryUsecase) CreateCategory(category model.Category) (model.Category, error) {
+	args := m.Called(category)
+	return args.Get(0).(model.Category), args.Error(1)
+}
+
+func (m *MockCategoryUsecase) GetAllCategory() ([]model.Category, error) {
+	args := m.Called()
+	return args.Get(0).([]model.Category), args.Error(1)
+}
+
+func (m *MockCategoryUsecase) GetCategoryById(id int) (model.Category, error) {
+	args := m.Called(id)
+	return args.Get(0).(model.Category), args.Error(1)
+}
+
+func (m *MockCategoryUsecase) UpdateCategory(category model.Category) (model.Category, error) {
+	args := m.Called(category)
+	return args.Get(0).(model.Category), args.Error(1)
+}
+
+func (m *MockCategoryUsecase) DeleteCategory(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockCategoryUsecase) SetBookCategories(bookId int, categoryIds []int) ([]model.Category, error) {
+	args := m.Called(bookId, categoryIds)
+	return args.Get(0).([]model.Category), args.Error(1)
+}
+
+func TestCategoryController_GetAllCategory(t *testing.T) {
+	mockUsecase := new(MockCategoryUsecase)
+	router := gin.Default()
+	NewCategoryController(mockUsecase, router.Group("/api/v1"), allowAllMiddleware()).Route()
+
+	// Happy Path: jumlah buku per category
+	expected := []model.Category{{Id: 2, Name: "Fiction", BookCount: 12}, {Id: 4, Name: "Programming", BookCount: 3}}
+	mockUsecase.On("GetAllCategory").Return(expected, nil).Once()
+
+	req, err := http.NewRequest(http.MethodGet, "/api/v1/categories", nil)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var actual []model.Category
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &actual))
+	assert.Equal(t, expected, actual)
+
+	// Sad Path: category tidak ditemukan
+	mockUsecase.On("GetCategoryById", 9).Return(model.Category{}, &usecase.NotFoundError{Message: "category not found"}).Once()
+
+	req, err = http.NewRequest(http.MethodGet, "/api/v1/categories/9", nil)
+	assert.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestCategoryController_SetBookCategories(t *testing.T) {
+	mockUsecase := new(MockCategoryUsecase)
+	router := gin.Default()
+	NewCategoryController(mockUsecase, router.Group("/api/v1"), allowAllMiddleware()).Route()
+
+	// Happy Path
+	mockUsecase.On("SetBookCategories", 1, []int{4}).Return([]model.Category{{Id: 4, Name: "Programming", BookCount: 1}}, nil).Once()
+
+	req, err := http.NewRequest(http.MethodPut, "/api/v1/books/1/categories", bytes.NewBufferString(`{"ids": [4]}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Sad Path: body tidak valid
+	req, err = http.NewRequest(http.MethodPut, "/api/v1/books/1/categories", bytes.NewBufferString(`{"ids": "4"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"errors"
+	"simple-clean-architecture/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errorResponse menjawab error dari usecase dengan status code sesuai jenis
+// error-nya, error lain dijawab dengan 500.
+func errorResponse(c *gin.Context, err error) {
+	var validationErr *usecase.ValidationError
+	var notFoundErr *usecase.NotFoundError
+	var conflictErr *usecase.ConflictError
+	var unauthorizedErr *usecase.UnauthorizedError
+	var preconditionErr *usecase.PreconditionFailedError
+
+	status := 500
+	switch {
+	case errors.As(err, &validationErr):
+		status = 400
+	case errors.As(err, &notFoundErr):
+		status = 404
+	case errors.As(err, &conflictErr):
+		status = 409
+	case errors.As(err, &unauthorizedErr):
+		status = 401
+	case errors.As(err, &preconditionErr):
+		status = 412
+	}
+
+	c.JSON(status, gin.H{"message": err.Error()})
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// bookETag membuat ETag "<version>-<hash>". Version dipakai untuk If-Match
+// saat update, hash dari isi response membuat ETag ikut berubah jika data
+// yang tidak punya version seperti author atau jumlah eksemplar berubah,
+// sehingga If-None-Match tidak menjawab 304 untuk data yang sudah basi.
+func bookETag(version int, body interface{}) string {
+	raw, _ := json.Marshal(body)
+	sum := sha256.Sum256(raw)
+
+	return fmt.Sprintf(`"%d-%s"`, version, hex.EncodeToString(sum[:8]))
+}
+
+// etagVersion mengambil version dari ETag buatan bookETag.
+func etagVersion(tag string) (int, bool) {
+	tag = strings.TrimSpace(tag)
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+	version, _, _ := strings.Cut(tag[1:len(tag)-1], "-")
+
+	v, err := strconv.Atoi(version)
+	if err != nil || v <= 0 {
+		return 0, false
+	}
+	return v, true
+}
+
+// etagMatches memeriksa header If-None-Match, yang boleh berisi beberapa ETag
+// dan ETag lemah (W/).
+func etagMatches(header string, tag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"simple-clean-architecture/middleware"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/usecase"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HoldController struct {
+	holdUsecase    usecase.HoldUsecase
+	authMiddleware *middleware.AuthMiddleware
+	rg             *gin.RouterGroup
+}
+
+func (h *HoldController) Route() {
+	h.rg.POST("/holds", h.authMiddleware.RequireToken(model.RoleLibrarian), h.PlaceHold)
+	h.rg.GET("/holds/:id", h.authMiddleware.RequireToken(model.RoleLibrarian), h.GetHoldById)
+	h.rg.POST("/holds/:id/cancel", h.authMiddleware.RequireToken(model.RoleLibrarian), h.CancelHold)
+	h.rg.GET("/members/:id/holds", h.authMiddleware.RequireToken(model.RoleLibrarian), h.GetMemberHolds)
+}
+
+func (h *HoldController) PlaceHold(c *gin.Context) {
+	var request model.HoldRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, gin.H{"message": err.Error()})
+		return
+	}
+
+	hold, err := h.holdUsecase.PlaceHold(request)
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(201, hold)
+}
+
+func (h *HoldController) GetHoldById(c *gin.Context) {
+	h.withHoldId(c, h.holdUsecase.GetHoldById)
+}
+
+func (h *HoldController) CancelHold(c *gin.Context) {
+	h.withHoldId(c, h.holdUsecase.CancelHold)
+}
+
+func (h *HoldController) GetMemberHolds(c *gin.Context) {
+	memberId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"message": "id must be a number"})
+		return
+	}
+
+	holds, err := h.holdUsecase.GetMemberHolds(memberId)
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(200, holds)
+}
+
+// withHoldId menjalankan action untuk hold dengan id dari path dan menjawab
+// dengan hold hasilnya.
+func (h *HoldController) withHoldId(c *gin.Context, action func(id int) (model.Hold, error)) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"message": "id must be a number"})
+		return
+	}
+
+	hold, err := action(id)
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(200, hold)
+}
+
+func NewHoldController(holdUsecase usecase.HoldUsecase, rg *gin.RouterGroup, authMiddleware *middleware.AuthMiddleware) *HoldController {
+	return &HoldController{holdUsecase: holdUsecase, rg: rg, authMiddleware: authMiddleware}
+}
@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/usecase"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockHoldUsecase struct {
+	mock.Mock
+}
+
+func (m *MockHoldUsecase) PlaceHold(request model.HoldRequest) (model.Hold, error) {
+	args := m.Called(request)
+	return args.Get(0).(model.Hold), args.Error(1)
+}
+
+func (m *MockHoldUsecase) CancelHold(id int) (model.Hold, error) {
+	args := m.Called(id)
+	return args.Get(0).(model.Hold), args.Error(1)
+}
+
+func (m *MockHoldUsecase) GetHoldById(id int) (model.Hold, error) {
+	args := m.Called(id)
+	return args.Get(0).(model.Hold), args.Error(1)
+}
+
+func (m *MockHoldUsecase) GetMemberHolds(memberId int) ([]model.Hold, error) {
+	args := m.Called(memberId)
+	return args.Get(0).([]model.Hold), args.Error(1)
+}
+
+func TestHoldController_PlaceHold(t *testing.T) {
+	mockUsecase := new(MockHoldUsecase)
+	router := gin.Default()
+	rg := router.Group("/api/v1")
+	NewHoldController(mockUsecase, rg, allowAllMiddleware()).Route()
+
+	// Happy Path
+	request := model.HoldRequest{BookId: 1, MemberId: 2}
+	mockUsecase.On("PlaceHold", request).Return(model.Hold{Id: 8, BookId: 1, MemberId: 2, Status: model.HoldStatusWaiting, Position: 1}, nil).Once()
+
+	body, err := json.Marshal(request)
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/holds", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"position":1`)
+
+	// Sad Path: masih ada eksemplar tersedia
+	mockUsecase.On("PlaceHold", request).Return(model.Hold{}, &usecase.ConflictError{Message: "a copy of the book is available, borrow it instead"}).Once()
+
+	req, err = http.NewRequest(http.MethodPost, "/api/v1/holds", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestHoldController_CancelHold(t *testing.T) {
+	mockUsecase := new(MockHoldUsecase)
+	router := gin.Default()
+	rg := router.Group("/api/v1")
+	NewHoldController(mockUsecase, rg, allowAllMiddleware()).Route()
+
+	// Happy Path
+	mockUsecase.On("CancelHold", 8).Return(model.Hold{Id: 8, Status: model.HoldStatusCancelled}, nil).Once()
+
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/holds/8/cancel", nil)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Sad Path: hold tidak ditemukan
+	mockUsecase.On("CancelHold", 9).Return(model.Hold{}, &usecase.NotFoundError{Message: "hold not found"}).Once()
+
+	req, err = http.NewRequest(http.MethodPost, "/api/v1/holds/9/cancel", nil)
+	assert.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestHoldController_GetMemberHolds(t *testing.T) {
+	mockUsecase := new(MockHoldUsecase)
+	router := gin.Default()
+	rg := router.Group("/api/v1")
+	NewHoldController(mockUsecase, rg, allowAllMiddleware()).Route()
+
+	// Happy Path
+	expected := []model.Hold{{Id: 8, BookId: 1, MemberId: 2, Status: model.HoldStatusWaiting, Position: 2}}
+	mockUsecase.On("GetMemberHolds", 2).Return(expected, nil).Once()
+
+	req, err := http.NewRequest(http.MethodGet, "/api/v1/members/2/holds", nil)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var actual []model.Hold
+	err = json.Unmarshal(w.Body.Bytes(), &actual)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	// Sad Path: id bukan angka
+	req, err = http.NewRequest(http.MethodGet, "/api/v1/members/abc/holds", nil)
+	assert.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"simple-clean-architecture/middleware"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/usecase"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LoanController struct {
+	loanUsecase    usecase.LoanUsecase
+	authMiddleware *middleware.AuthMiddleware
+	rg             *gin.RouterGroup
+}
+
+func (l *LoanController) Route() {
+	l.rg.POST("/loans", l.authMiddleware.RequireToken(model.RoleLibrarian), l.BorrowBook)
+	l.rg.GET("/loans", l.authMiddleware.RequireToken(model.RoleLibrarian), l.GetAllLoan)
+	l.rg.GET("/loans/:id", l.authMiddleware.RequireToken(model.RoleLibrarian), l.GetLoanById)
+	l.rg.POST("/loans/:id/return", l.authMiddleware.RequireToken(model.RoleLibrarian), l.ReturnBook)
+	l.rg.POST("/loans/:id/pay", l.authMiddleware.RequireToken(model.RoleLibrarian), l.PayFine)
+}
+
+func (l *LoanController) BorrowBook(c *gin.Context) {
+	var request model.LoanRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, gin.H{"message": err.Error()})
+		return
+	}
+
+	loan, err := l.loanUsecase.BorrowBook(request)
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(201, loan)
+}
+
+func (l *LoanController) GetAllLoan(c *gin.Context) {
+	var filter model.LoanFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(400, gin.H{"message": err.Error()})
+		return
+	}
+
+	loans, err := l.loanUsecase.GetAllLoan(filter)
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(200, loans)
+}
+
+func (l *LoanController) GetLoanById(c *gin.Context) {
+	l.withLoanId(c, l.loanUsecase.GetLoanById)
+}
+
+func (l *LoanController) ReturnBook(c *gin.Context) {
+	l.withLoanId(c, l.loanUsecase.ReturnBook)
+}
+
+func (l *LoanController) PayFine(c *gin.Context) {
+	l.withLoanId(c, l.loanUsecase.PayFine)
+}
+
+// withLoanId menjalankan action untuk loan dengan id dari path dan menjawab
+// dengan loan hasilnya.
+func (l *LoanController) withLoanId(c *gin.Context, action func(id int) (model.Loan, error)) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"message": "id must be a number"})
+		return
+	}
+
+	loan, err := action(id)
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(200, loan)
+}
+
+func NewLoanController(loanUsecase usecase.LoanUsecase, rg *gin.RouterGroup, authMiddleware *middleware.AuthMiddleware) *LoanController {
+	return &LoanController{loanUsecase: loanUsecase, rg: rg, authMiddleware: authMiddleware}
+}
@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/usecase"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLoanUsecase struct {
+	mock.Mock
+}
+
+func (m *MockLoanUsecase) BorrowBook(request model.LoanRequest) (model.Loan, error) {
+	args := m.Called(request)
+	return args.Get(0).(model.Loan), args.Error(1)
+}
+
+func (m *MockLoanUsecase) ReturnBook(id int) (model.Loan, error) {
+	args := m.Called(id)
+	return args.Get(0).(model.Loan), args.Error(1)
+}
+
+func (m *MockLoanUsecase) PayFine(id int) (model.Loan, error) {
+	args := m.Called(id)
+	return args.Get(0).(model.Loan), args.Error(1)
+}
+
+func (m *MockLoanUsecase) GetAllLoan(filter model.LoanFilter) ([]model.Loan, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]model.Loan), args.Error(1)
+}
+
+func (m *MockLoanUsecase) GetLoanById(id int) (model.Loan, error) {
+	args := m.Called(id)
+	return args.Get(0).(model.Loan), args.Error(1)
+}
+
+func TestLoanController_BorrowBook(t *testing.T) {
+	mockUsecase := new(MockLoanUsecase)
+	router := gin.Default()
+	rg := router.Group("/api/v1")
+	NewLoanController(mockUsecase, rg, allowAllMiddleware()).Route()
+
+	// Happy Path
+	request := model.LoanRequest{BookId: 1, MemberId: 2}
+	mockUsecase.On("BorrowBook", request).Return(model.Loan{Id: 5, BookId: 1, MemberId: 2}, nil).Once()
+
+	body, err := json.Marshal(request)
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/loans", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// Sad Path: member diblokir
+	blocked := model.LoanRequest{BookId: 1, MemberId: 3}
+	mockUsecase.On("BorrowBook", blocked).Return(model.Loan{}, &usecase.ConflictError{Message: "member has unpaid fines"}).Once()
+
+	body, err = json.Marshal(blocked)
+	assert.NoError(t, err)
+
+	req, err = http.NewRequest(http.MethodPost, "/api/v1/loans", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "member has unpaid fines")
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestLoanController_GetAllLoan(t *testing.T) {
+	mockUsecase := new(MockLoanUsecase)
+	router := gin.Default()
+	rg := router.Group("/api/v1")
+	NewLoanController(mockUsecase, rg, allowAllMiddleware()).Route()
+
+	// Happy Path
+	mockUsecase.On("GetAllLoan", model.LoanFilter{MemberId: 2, Status: "overdue"}).Return([]model.Loan{{Id: 5}}, nil).Once()
+
+	req, err := http.NewRequest(http.MethodGet, "/api/v1/loans?memberId=2&status=overdue", nil)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUsecase.AssertExpectations(t)
+
+	// Sad Path: memberId bukan angka
+	req, err = http.NewRequest(http.MethodGet, "/api/v1/loans?memberId=abc", nil)
+	assert.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestLoanController_ReturnBook(t *testing.T) {
+	mockUsecase := new(MockLoanUsecase)
+	router := gin.Default()
+	rg := router.Group("/api/v1")
+	NewLoanController(mockUsecase, rg, allowAllMiddleware()).Route()
+
+	// Happy Path
+	mockUsecase.On("ReturnBook", 5).Return(model.Loan{Id: 5, Fine: 3000}, nil).Once()
+
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/loans/5/return", nil)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var loan model.Loan
+	err = json.Unmarshal(w.Body.Bytes(), &loan)
+	assert.NoError(t, err)
+	assert.Equal(t, 3000, loan.Fine)
+
+	// Sad Path: loan tidak ada
+	mockUsecase.On("ReturnBook", 6).Return(model.Loan{}, &usecase.NotFoundError{Message: "loan not found"}).Once()
+
+	req, err = http.NewRequest(http.MethodPost, "/api/v1/loans/6/return", nil)
+	assert.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestLoanController_PayFine(t *testing.T) {
+	mockUsecase := new(MockLoanUsecase)
+	router := gin.Default()
+	rg := router.Group("/api/v1")
+	NewLoanController(mockUsecase, rg, allowAllMiddleware()).Route()
+
+	// Happy Path
+	mockUsecase.On("PayFine", 5).Return(model.Loan{Id: 5, Fine: 3000, FinePaid: true}, nil).Once()
+
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/loans/5/pay", nil)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Sad Path: Usecase returns error
+	mockUsecase.On("PayFine", 6).Return(model.Loan{}, errors.New("connection refused")).Once()
+
+	req, err = http.NewRequest(http.MethodPost, "/api/v1/loans/6/pay", nil)
+	assert.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"simple-clean-architecture/middleware"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/usecase"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MemberController struct {
+	memberUsecase  usecase.MemberUsecase
+	authMiddleware *middleware.AuthMiddleware
+	rg             *gin.RouterGroup
+}
+
+func (m *MemberController) Route() {
+	m.rg.POST("/members", m.authMiddleware.RequireToken(model.RoleLibrarian), m.CreateNewMember)
+	m.rg.GET("/members", m.authMiddleware.RequireToken(model.RoleLibrarian), m.GetAllMember)
+	m.rg.GET("/members/:id", m.authMiddleware.RequireToken(model.RoleLibrarian), m.GetMemberById)
+}
+
+func (m *MemberController) CreateNewMember(c *gin.Context) {
+	var member model.Member
+	if err := c.ShouldBindJSON(&member); err != nil {
+		c.JSON(400, gin.H{"message": err.Error()})
+		return
+	}
+
+	newMember, err := m.memberUsecase.CreateNewMember(member)
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(201, newMember)
+}
+
+func (m *MemberController) GetAllMember(c *gin.Context) {
+	members, err := m.memberUsecase.GetAllMember()
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(200, members)
+}
+
+func (m *MemberController) GetMemberById(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"message": "id must be a number"})
+		return
+	}
+
+	member, err := m.memberUsecase.GetMemberById(id)
+
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(200, member)
+}
+
+func NewMemberController(memberUsecase usecase.MemberUsecase, rg *gin.RouterGroup, authMiddleware *middleware.AuthMiddleware) *MemberController {
+	return &MemberController{memberUsecase: memberUsecase, rg: rg, authMiddleware: authMiddleware}
+}
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/usecase"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMemberUsecase struct {
+	mock.Mock
+}
+
+func (m *MockMemberUsecase) CreateNewMember(member model.Member) (model.Member, error) {
+	args := m.Called(member)
+	return args.Get(0).(model.Member), args.Error(1)
+}
+
+func (m *MockMemberUsecase) GetAllMember() ([]model.Member, error) {
+	args := m.Called()
+	return args.Get(0).([]model.Member), args.Error(1)
+}
+
+func (m *MockMemberUsecase) GetMemberById(id int) (model.Member, error) {
+	args := m.Called(id)
+	return args.Get(0).(model.Member), args.Error(1)
+}
+
+func TestMemberController_CreateNewMember(t *testing.T) {
+	mockUsecase := new(MockMemberUsecase)
+	router := gin.Default()
+	rg := router.Group("/api/v1")
+	NewMemberController(mockUsecase, rg, allowAllMiddleware()).Route()
+
+	// Happy Path
+	member := model.Member{Name: "Budi", Email: "budi@example.com"}
+	mockUsecase.On("CreateNewMember", member).Return(model.Member{Id: 1, Name: "Budi", Email: "budi@example.com"}, nil).Once()
+
+	body, err := json.Marshal(member)
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/members", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockUsecase.AssertExpectations(t)
+
+	// Sad Path: email tidak valid
+	invalid := model.Member{Name: "Budi", Email: "budi"}
+	mockUsecase.On("CreateNewMember", invalid).Return(model.Member{}, &usecase.ValidationError{Message: "invalid email address"}).Once()
+
+	body, err = json.Marshal(invalid)
+	assert.NoError(t, err)
+
+	req, err = http.NewRequest(http.MethodPost, "/api/v1/members", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid email address")
+}
+
+func TestMemberController_GetMemberById(t *testing.T) {
+	mockUsecase := new(MockMemberUsecase)
+	router := gin.Default()
+	rg := router.Group("/api/v1")
+	NewMemberController(mockUsecase, rg, allowAllMiddleware()).Route()
+
+	// Happy Path
+	mockUsecase.On("GetMemberById", 1).Return(model.Member{Id: 1, Name: "Budi"}, nil).Once()
+
+	req, err := http.NewRequest(http.MethodGet, "/api/v1/members/1", nil)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Sad Path: member tidak ada
+	mockUsecase.On("GetMemberById", 2).Return(model.Member{}, &usecase.NotFoundError{Message: "member not found"}).Once()
+
+	req, err = http.NewRequest(http.MethodGet, "/api/v1/members/2", nil)
+	assert.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Sad Path: id bukan angka
+	req, err = http.NewRequest(http.MethodGet, "/api/v1/members/abc", nil)
+	assert.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
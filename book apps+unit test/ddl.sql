@@ -0,0 +1,190 @@
+-- Tabel yang dipakai aplikasi (PostgreSQL)
+
+-- isbn disimpan sebagai ISBN-13 tanpa tanda hubung, NULL untuk buku tanpa isbn.
+-- isbn10 adalah padanan ISBN-10-nya, NULL untuk ISBN-13 yang tidak berawalan 978
+-- version naik setiap kali buku diubah, dipakai untuk ETag dan If-Match
+-- deleted_at terisi untuk buku yang dihapus, buku tersebut tidak tampil di
+-- katalog tetapi masih bisa dikembalikan
+CREATE TABLE mst_book (
+    id SERIAL PRIMARY KEY,
+    title VARCHAR(255) NOT NULL,
+    author VARCHAR(255) NOT NULL,
+    release_year INT NOT NULL,
+    pages INT NOT NULL,
+    isbn VARCHAR(13) NULL UNIQUE,
+    isbn10 VARCHAR(10) NULL UNIQUE,
+    version INT NOT NULL DEFAULT 1,
+    deleted_at TIMESTAMP NULL
+);
+
+-- Nama author dan category unik tanpa membedakan huruf besar kecil
+CREATE TABLE mst_author (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL
+);
+
+CREATE UNIQUE INDEX ux_mst_author_name ON mst_author(LOWER(name));
+
+CREATE TABLE mst_category (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL
+);
+
+CREATE UNIQUE INDEX ux_mst_category_name ON mst_category(LOWER(name));
+
+-- position menyimpan urutan penulis pada buku. Kolom mst_book.author berisi
+-- gabungan nama author sesuai urutan ini. Jika kolom author diisi langsung,
+-- hubungan ini dibangun ulang dari kolom tersebut.
+CREATE TABLE mst_book_author (
+    book_id INT NOT NULL REFERENCES mst_book(id) ON DELETE CASCADE,
+    author_id INT NOT NULL REFERENCES mst_author(id),
+    position INT NOT NULL,
+    PRIMARY KEY (book_id, author_id)
+);
+
+CREATE INDEX idx_mst_book_author_author_id ON mst_book_author(author_id);
+
+CREATE TABLE mst_book_category (
+    book_id INT NOT NULL REFERENCES mst_book(id) ON DELETE CASCADE,
+    category_id INT NOT NULL REFERENCES mst_category(id) ON DELETE CASCADE,
+    PRIMARY KEY (book_id, category_id)
+);
+
+CREATE INDEX idx_mst_book_category_category_id ON mst_book_category(category_id);
+
+CREATE TABLE mst_member (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    joined_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Setiap eksemplar fisik buku dicatat terpisah
+-- status: available, on_loan, on_hold, lost, repair
+CREATE TABLE mst_book_copy (
+    id SERIAL PRIMARY KEY,
+    book_id INT NOT NULL REFERENCES mst_book(id) ON DELETE CASCADE,
+    barcode VARCHAR(50) NOT NULL UNIQUE,
+    condition VARCHAR(50) NOT NULL DEFAULT 'good',
+    shelf_location VARCHAR(50) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'available'
+        CHECK (status IN ('available', 'on_loan', 'on_hold', 'lost', 'repair'))
+);
+
+CREATE INDEX idx_mst_book_copy_book_id ON mst_book_copy(book_id);
+
+-- Denda dihitung saat buku dikembalikan, dalam rupiah
+CREATE TABLE trx_loan (
+    id SERIAL PRIMARY KEY,
+    book_id INT NOT NULL REFERENCES mst_book(id),
+    copy_id INT NOT NULL REFERENCES mst_book_copy(id),
+    member_id INT NOT NULL REFERENCES mst_member(id),
+    borrowed_at TIMESTAMP NOT NULL,
+    due_at TIMESTAMP NOT NULL,
+    returned_at TIMESTAMP NULL,
+    fine INT NOT NULL DEFAULT 0,
+    fine_paid BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+-- Satu eksemplar hanya bisa dipinjam oleh satu member pada satu waktu
+CREATE UNIQUE INDEX ux_trx_loan_active_copy ON trx_loan(copy_id) WHERE returned_at IS NULL;
+CREATE INDEX idx_trx_loan_member_id ON trx_loan(member_id);
+
+-- Antrian reservasi buku, dilayani berurutan berdasarkan id
+-- status: waiting, ready, fulfilled, cancelled, expired
+-- copy_id dan expires_at terisi saat eksemplar disiapkan untuk member
+CREATE TABLE trx_hold (
+    id SERIAL PRIMARY KEY,
+    book_id INT NOT NULL REFERENCES mst_book(id) ON DELETE CASCADE,
+    member_id INT NOT NULL REFERENCES mst_member(id),
+    copy_id INT NULL REFERENCES mst_book_copy(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'waiting'
+        CHECK (status IN ('waiting', 'ready', 'fulfilled', 'cancelled', 'expired')),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    ready_at TIMESTAMP NULL,
+    expires_at TIMESTAMP NULL
+);
+
+-- Satu member hanya punya satu hold aktif per buku
+CREATE UNIQUE INDEX ux_trx_hold_active_member_book ON trx_hold(member_id, book_id) WHERE status IN ('waiting', 'ready');
+CREATE INDEX idx_trx_hold_book_status ON trx_hold(book_id, status);
+
+-- User API. Librarian boleh mengubah data, member hanya membaca katalog.
+-- Registrasi selalu membuat member, librarian pertama dibuat dengan
+-- UPDATE mst_user SET role = 'librarian' WHERE username = '...';
+CREATE TABLE mst_user (
+    id SERIAL PRIMARY KEY,
+    username VARCHAR(50) NOT NULL,
+    password_hash VARCHAR(100) NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'member' CHECK (role IN ('librarian', 'member')),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX ux_mst_user_username ON mst_user(LOWER(username));
+
+-- Isi buku setelah setiap perubahan, satu baris per version
+-- changed_by NULL untuk perubahan yang tidak dilakukan lewat API
+CREATE TABLE book_revisions (
+    id SERIAL PRIMARY KEY,
+    book_id INT NOT NULL REFERENCES mst_book(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    author VARCHAR(255) NOT NULL,
+    release_year INT NOT NULL,
+    pages INT NOT NULL,
+    isbn VARCHAR(13) NULL,
+    isbn10 VARCHAR(10) NULL,
+    deleted_at TIMESTAMP NULL,
+    action VARCHAR(20) NOT NULL
+        CHECK (action IN ('create', 'update', 'import', 'delete', 'restore')),
+    changed_by INT NULL REFERENCES mst_user(id),
+    changed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (book_id, version)
+);
+
+-- Untuk database yang sudah berjalan: buat tabel mst_book_copy di atas, lalu
+-- setiap buku yang pernah dipinjam diberi satu eksemplar agar loan lama tetap
+-- punya copy_id.
+-- INSERT INTO mst_book_copy(book_id, barcode, status)
+--     SELECT b.id, 'BOOK-' || b.id,
+--         CASE WHEN EXISTS (SELECT 1 FROM trx_loan l WHERE l.book_id = b.id AND l.returned_at IS NULL) THEN 'on_loan' ELSE 'available' END
+--     FROM mst_book b WHERE EXISTS (SELECT 1 FROM trx_loan l WHERE l.book_id = b.id);
+-- ALTER TABLE trx_loan ADD COLUMN copy_id INT REFERENCES mst_book_copy(id);
+-- UPDATE trx_loan l SET copy_id = c.id FROM mst_book_copy c WHERE c.book_id = l.book_id;
+-- ALTER TABLE trx_loan ALTER COLUMN copy_id SET NOT NULL;
+-- DROP INDEX ux_trx_loan_active_book;
+-- CREATE UNIQUE INDEX ux_trx_loan_active_copy ON trx_loan(copy_id) WHERE returned_at IS NULL;
+
+-- Kolom isbn untuk database yang sudah berjalan
+-- ALTER TABLE mst_book ADD COLUMN isbn VARCHAR(13) NULL UNIQUE;
+
+-- Kolom isbn10 untuk database yang sudah berjalan. Isbn yang masih tersimpan
+-- sebagai ISBN-10 dipindah ke isbn10 lalu isbn diisi dengan ISBN-13-nya.
+-- ALTER TABLE mst_book ADD COLUMN isbn10 VARCHAR(10) NULL UNIQUE;
+-- UPDATE mst_book SET isbn10 = isbn, isbn = '978' || LEFT(isbn, 9) || (10 - (
+--     SELECT SUM(SUBSTR('978' || LEFT(isbn, 9), i, 1)::INT * CASE WHEN i % 2 = 0 THEN 3 ELSE 1 END)
+--     FROM generate_series(1, 12) i) % 10) % 10
+-- WHERE LENGTH(isbn) = 10;
+
+-- Author untuk database yang sudah berjalan: buat tabel author dan category di
+-- atas, lalu pecah kolom author setiap buku berdasarkan koma, titik koma, "&",
+-- "and", atau "dan" menjadi baris mst_author yang dihubungkan ke bukunya.
+-- INSERT INTO mst_author(name)
+--     SELECT DISTINCT ON (LOWER(TRIM(s.name))) TRIM(s.name)
+--     FROM mst_book b CROSS JOIN LATERAL regexp_split_to_table(b.author, '\s*(,|;|&|\s+and\s+|\s+dan\s+)\s*', 'i') AS s(name)
+--     WHERE TRIM(s.name) <> ''
+--     ON CONFLICT DO NOTHING;
+-- INSERT INTO mst_book_author(book_id, author_id, position)
+--     SELECT b.id, a.id, MIN(s.position)
+--     FROM mst_book b CROSS JOIN LATERAL regexp_split_to_table(b.author, '\s*(,|;|&|\s+and\s+|\s+dan\s+)\s*', 'i') WITH ORDINALITY AS s(name, position)
+--     JOIN mst_author a ON LOWER(a.name) = LOWER(TRIM(s.name))
+--     GROUP BY b.id, a.id;
+
+-- Kolom version untuk database yang sudah berjalan
+-- ALTER TABLE mst_book ADD COLUMN version INT NOT NULL DEFAULT 1;
+
+-- Soft delete dan riwayat untuk database yang sudah berjalan: buat tabel
+-- book_revisions di atas, lalu catat isi buku saat ini sebagai revisi pertama.
+-- ALTER TABLE mst_book ADD COLUMN deleted_at TIMESTAMP NULL;
+-- INSERT INTO book_revisions(book_id, version, title, author, release_year, pages, isbn, isbn10, action)
+--     SELECT id, version, title, author, release_year, pages, isbn, isbn10, 'create' FROM mst_book;
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"errors"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/usecase"
+	"simple-clean-architecture/utils/service"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserKey adalah key gin context yang berisi model.User pemilik token.
+const UserKey = "user"
+
+type AuthMiddleware struct {
+	// RequireToken hanya meneruskan request dengan token yang valid milik user
+	// dengan salah satu role yang disebut. Tanpa role, semua role diterima.
+	RequireToken func(roles ...string) gin.HandlerFunc
+	// CatalogRead dipakai untuk GET katalog. Jika anonymous read diizinkan
+	// request tanpa token diteruskan, selain itu sama dengan RequireToken().
+	CatalogRead func() gin.HandlerFunc
+}
+
+type authMiddleware struct {
+	jwtService         service.JWTService
+	authUsecase        usecase.AuthUsecase
+	allowAnonymousRead bool
+}
+
+// authenticate memeriksa bearer token lalu menyimpan user-nya di context.
+// Role diambil dari database, bukan dari token, agar perubahan role langsung
+// berlaku tanpa menunggu token kedaluwarsa.
+func (a *authMiddleware) authenticate(c *gin.Context) (model.User, bool) {
+	tokenString, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !found || tokenString == "" {
+		c.AbortWithStatusJSON(401, gin.H{"message": "missing bearer token"})
+		return model.User{}, false
+	}
+
+	claims, err := a.jwtService.VerifyToken(tokenString)
+	if err != nil {
+		c.AbortWithStatusJSON(401, gin.H{"message": "invalid token"})
+		return model.User{}, false
+	}
+
+	user, err := a.authUsecase.GetUserById(claims.UserId)
+	if err != nil {
+		var notFoundErr *usecase.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			c.AbortWithStatusJSON(401, gin.H{"message": "invalid token"})
+		} else {
+			c.AbortWithStatusJSON(500, gin.H{"message": err.Error()})
+		}
+		return model.User{}, false
+	}
+
+	c.Set(UserKey, user)
+	return user, true
+}
+
+func (a *authMiddleware) requireToken(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := a.authenticate(c)
+		if !ok {
+			return
+		}
+
+		if len(roles) == 0 {
+			c.Next()
+			return
+		}
+
+		for _, role := range roles {
+			if user.Role == role {
+				c.Next()
+				return
+			}
+		}
+
+		c.AbortWithStatusJSON(403, gin.H{"message": "forbidden"})
+	}
+}
+
+func (a *authMiddleware) catalogRead() gin.HandlerFunc {
+	if a.allowAnonymousRead {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+	return a.requireToken()
+}
+
+func NewAuthMiddleware(jwtService service.JWTService, authUsecase usecase.AuthUsecase, allowAnonymousRead bool) *AuthMiddleware {
+	am := &authMiddleware{
+		jwtService:         jwtService,
+		authUsecase:        authUsecase,
+		allowAnonymousRead: allowAnonymousRead,
+	}
+	return &AuthMiddleware{
+		RequireToken: am.requireToken,
+		CatalogRead:  am.catalogRead,
+	}
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"simple-clean-architecture/config"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/usecase"
+	"simple-clean-architecture/utils/service"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuthUsecase struct {
+	mock.Mock
+}
+
+func (m *MockAuthUsecase) Register(user model.User) (model.User, error) {
+	args := m.Called(user)
+	return args.Get(0).(model.User), args.Error(1)
+}
+
+func (m *MockAuthUsecase) Login(request model.LoginRequest) (model.LoginResponse, error) {
+	args := m.Called(request)
+	return args.Get(0).(model.LoginResponse), args.Error(1)
+}
+
+func (m *MockAuthUsecase) GetUserById(id int) (model.User, error) {
+	args := m.Called(id)
+	return args.Get(0).(model.User), args.Error(1)
+}
+
+func (m *MockAuthUsecase) UpdateUserRole(id int, role string) (model.User, error) {
+	args := m.Called(id, role)
+	return args.Get(0).(model.User), args.Error(1)
+}
+
+func newTestRouter(handler gin.HandlerFunc) *gin.Engine {
+	router := gin.New()
+	router.GET("/", handler, func(c *gin.Context) {
+		user, _ := c.Get(UserKey)
+		c.JSON(200, gin.H{"user": user})
+	})
+	return router
+}
+
+func serve(router *gin.Engine, token string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthMiddleware_RequireToken(t *testing.T) {
+	jwtService := service.NewJWTService(config.TokenConfig{ApplicationName: "test", JwtSignatureKey: []byte("secret")})
+	authUsecase := new(MockAuthUsecase)
+	authUsecase.On("GetUserById", 1).Return(model.User{Id: 1, Username: "budi", Role: model.RoleLibrarian}, nil)
+	// token masih menyebut librarian tapi role di database sudah diturunkan
+	authUsecase.On("GetUserById", 2).Return(model.User{Id: 2, Username: "siti", Role: model.RoleMember}, nil)
+	authUsecase.On("GetUserById", 3).Return(model.User{}, &usecase.NotFoundError{Message: "user not found"})
+	authUsecase.On("GetUserById", 4).Return(model.User{}, errors.New("connection refused"))
+	authMiddleware := NewAuthMiddleware(jwtService, authUsecase, true)
+	router := newTestRouter(authMiddleware.RequireToken(model.RoleLibrarian))
+
+	token := func(id int) string {
+		token, _, err := jwtService.CreateToken(model.User{Id: id, Role: model.RoleLibrarian})
+		assert.NoError(t, err)
+		return token
+	}
+
+	// Happy Path
+	w := serve(router, token(1))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"username":"budi"`)
+
+	// Sad Path: role di database bukan librarian
+	w = serve(router, token(2))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Sad Path: user sudah dihapus
+	w = serve(router, token(3))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Sad Path: database error
+	w = serve(router, token(4))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	// Sad Path: tanpa token atau token tidak valid
+	w = serve(router, "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = serve(router, "invalid")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuthMiddleware_RequireToken_AnyRole(t *testing.T) {
+	jwtService := service.NewJWTService(config.TokenConfig{ApplicationName: "test", JwtSignatureKey: []byte("secret")})
+	authUsecase := new(MockAuthUsecase)
+	authUsecase.On("GetUserById", 2).Return(model.User{Id: 2, Role: model.RoleMember}, nil)
+	router := newTestRouter(NewAuthMiddleware(jwtService, authUsecase, true).RequireToken())
+
+	token, _, err := jwtService.CreateToken(model.User{Id: 2, Role: model.RoleMember})
+	assert.NoError(t, err)
+
+	w := serve(router, token)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAuthMiddleware_CatalogRead(t *testing.T) {
+	jwtService := service.NewJWTService(config.TokenConfig{ApplicationName: "test", JwtSignatureKey: []byte("secret")})
+	authUsecase := new(MockAuthUsecase)
+	authUsecase.On("GetUserById", 2).Return(model.User{Id: 2, Role: model.RoleMember}, nil)
+
+	token, _, err := jwtService.CreateToken(model.User{Id: 2, Role: model.RoleMember})
+	assert.NoError(t, err)
+
+	// Happy Path: anonymous read diizinkan
+	router := newTestRouter(NewAuthMiddleware(jwtService, authUsecase, true).CatalogRead())
+	w := serve(router, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Happy Path: anonymous read ditutup, member tetap bisa membaca
+	router = newTestRouter(NewAuthMiddleware(jwtService, authUsecase, false).CatalogRead())
+	w = serve(router, token)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Sad Path: anonymous read ditutup dan tanpa token
+	w = serve(router, "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
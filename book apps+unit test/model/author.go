@@ -0,0 +1,9 @@
+package model
+
+// Author adalah penulis yang bisa dihubungkan ke banyak Book. BookCount hanya
+// diisi saat membaca dan tidak dipakai saat menyimpan.
+type Author struct {
+	Id        int    `json:"id"`
+	Name      string `json:"name"`
+	BookCount int    `json:"bookCount"`
+}
@@ -1,9 +1,60 @@
 package model
 
+// Book adalah satu judul di katalog. Isbn boleh kosong, jika diisi disimpan
+// sebagai ISBN-13 tanpa tanda hubung dan unik untuk setiap buku. Isbn10 hanya
+// terisi untuk ISBN-13 berawalan 978 yang punya padanan ISBN-10. Version naik
+// setiap kali buku diubah dan dipakai untuk ETag.
 type Book struct {
 	Id          int    `json:"id"`
+	Isbn        string `json:"isbn"`
+	Isbn10      string `json:"isbn10,omitempty"`
 	Title       string `json:"title"`
 	Author      string `json:"author"`
 	ReleaseYear int    `json:"releaseYear"`
 	Pages       int    `json:"pages"`
+	Version     int    `json:"version"`
+}
+
+// BookFilter berisi query parameter GET /books. Nilai 0 atau kosong berarti
+// filter tidak dipakai. Offset dan After diisi oleh usecase, bukan oleh client.
+type BookFilter struct {
+	Title      string `form:"title"`
+	Author     string `form:"author"`
+	AuthorId   int    `form:"authorId"`
+	CategoryId int    `form:"categoryId"`
+	MinYear    int    `form:"minYear"`
+	MaxYear    int    `form:"maxYear"`
+	MinPages   int    `form:"minPages"`
+	MaxPages   int    `form:"maxPages"`
+	Sort       string `form:"sort"`
+	Order      string `form:"order"`
+	Page       int    `form:"page"`
+	Limit      int    `form:"limit"`
+	Cursor     string `form:"cursor"`
+
+	Offset int         `form:"-"`
+	After  *BookCursor `form:"-"`
+}
+
+// BookCursor menandai buku terakhir di halaman sebelumnya. Value adalah nilai
+// kolom Sort dari buku tersebut.
+type BookCursor struct {
+	Sort  string      `json:"sort"`
+	Order string      `json:"order"`
+	Value interface{} `json:"value"`
+	Id    int         `json:"id"`
+}
+
+// BookRelationRequest berisi id author atau category yang dihubungkan ke
+// sebuah buku, menggantikan hubungan sebelumnya.
+type BookRelationRequest struct {
+	Ids []int `json:"ids"`
+}
+
+type BookPage struct {
+	Data       []Book `json:"data"`
+	Total      int    `json:"total"`
+	Page       int    `json:"page,omitempty"`
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"nextCursor,omitempty"`
 }
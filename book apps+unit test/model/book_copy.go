@@ -0,0 +1,37 @@
+package model
+
+const (
+	CopyStatusAvailable = "available"
+	CopyStatusOnLoan    = "on_loan"
+	CopyStatusOnHold    = "on_hold"
+	CopyStatusLost      = "lost"
+	CopyStatusRepair    = "repair"
+)
+
+// BookCopy adalah satu eksemplar fisik dari sebuah Book. Status on_loan dan
+// on_hold hanya diatur lewat peminjaman, pengembalian, dan reservasi.
+type BookCopy struct {
+	Id            int    `json:"id"`
+	BookId        int    `json:"bookId"`
+	Barcode       string `json:"barcode"`
+	Condition     string `json:"condition"`
+	ShelfLocation string `json:"shelfLocation"`
+	Status        string `json:"status"`
+}
+
+// BookAvailability berisi jumlah eksemplar sebuah Book per status.
+type BookAvailability struct {
+	Total     int `json:"total"`
+	Available int `json:"available"`
+	OnLoan    int `json:"onLoan"`
+	OnHold    int `json:"onHold"`
+	Lost      int `json:"lost"`
+	Repair    int `json:"repair"`
+}
+
+type BookDetail struct {
+	Book
+	Authors      []Author         `json:"authors"`
+	Categories   []Category       `json:"categories"`
+	Availability BookAvailability `json:"availability"`
+}
@@ -0,0 +1,39 @@
+package model
+
+const (
+	ImportStatusCreated = "created"
+	ImportStatusUpdated = "updated"
+	ImportStatusInvalid = "invalid"
+	ImportStatusFailed  = "failed"
+)
+
+// BookImportRow adalah hasil satu baris file import. Row dihitung dari 1 tanpa
+// baris header. Pada dry run, status created atau updated berarti baris tersebut
+// akan dibuat atau diperbarui.
+type BookImportRow struct {
+	Row    int    `json:"row"`
+	Isbn   string `json:"isbn,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Status string `json:"status"`
+	Id     int    `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type BookImportReport struct {
+	DryRun  bool            `json:"dryRun"`
+	Total   int             `json:"total"`
+	Created int             `json:"created"`
+	Updated int             `json:"updated"`
+	Invalid int             `json:"invalid"`
+	Failed  int             `json:"failed"`
+	Rows    []BookImportRow `json:"rows"`
+}
+
+// BookUpsertResult adalah hasil upsert satu buku: id buku dan apakah buku
+// tersebut baru dibuat. Error terisi jika baris tersebut gagal disimpan,
+// sementara baris lain dalam batch yang sama tetap disimpan.
+type BookUpsertResult struct {
+	Id      int
+	Created bool
+	Error   error
+}
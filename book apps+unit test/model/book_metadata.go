@@ -0,0 +1,12 @@
+package model
+
+// BookMetadata adalah data buku yang ditemukan dari sumber metadata untuk satu
+// ISBN. Field yang tidak diketahui sumbernya dibiarkan kosong.
+type BookMetadata struct {
+	Isbn        string `json:"isbn"`
+	Isbn10      string `json:"isbn10,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Author      string `json:"author,omitempty"`
+	ReleaseYear int    `json:"releaseYear,omitempty"`
+	Pages       int    `json:"pages,omitempty"`
+}
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+const (
+	BookActionCreate  = "create"
+	BookActionUpdate  = "update"
+	BookActionImport  = "import"
+	BookActionDelete  = "delete"
+	BookActionRestore = "restore"
+)
+
+// BookRevision adalah isi buku setelah satu perubahan, dicatat setiap kali
+// version buku naik. UserId 0 berarti perubahan tidak dilakukan lewat API,
+// misalnya dari migration.
+type BookRevision struct {
+	Id        int
+	Book      Book
+	DeletedAt *time.Time
+	Action    string
+	UserId    int
+	Username  string
+	ChangedAt time.Time
+}
+
+// BookFieldChange adalah perubahan satu field dibanding revisi sebelumnya.
+// Old bernilai null untuk revisi pertama.
+type BookFieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+type BookHistoryEntry struct {
+	Version   int               `json:"version"`
+	Action    string            `json:"action"`
+	UserId    int               `json:"userId,omitempty"`
+	Username  string            `json:"username,omitempty"`
+	ChangedAt time.Time         `json:"changedAt"`
+	Changes   []BookFieldChange `json:"changes"`
+}
@@ -0,0 +1,9 @@
+package model
+
+// Category adalah klasifikasi buku, misalnya genre. Satu Book boleh masuk ke
+// beberapa Category. BookCount hanya diisi saat membaca.
+type Category struct {
+	Id        int    `json:"id"`
+	Name      string `json:"name"`
+	BookCount int    `json:"bookCount"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+const (
+	HoldStatusWaiting   = "waiting"
+	HoldStatusReady     = "ready"
+	HoldStatusFulfilled = "fulfilled"
+	HoldStatusCancelled = "cancelled"
+	HoldStatusExpired   = "expired"
+)
+
+// Hold adalah reservasi member untuk sebuah Book yang semua eksemplarnya
+// sedang keluar. Antrian dilayani berurutan. Saat eksemplar dikembalikan,
+// hold terdepan menjadi ready dengan CopyId terisi dan harus diambil sebelum
+// ExpiresAt. Position adalah urutan dalam antrian, 0 jika tidak menunggu.
+type Hold struct {
+	Id        int        `json:"id"`
+	BookId    int        `json:"bookId"`
+	MemberId  int        `json:"memberId"`
+	CopyId    *int       `json:"copyId"`
+	Status    string     `json:"status"`
+	Position  int        `json:"position"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ReadyAt   *time.Time `json:"readyAt"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+type HoldRequest struct {
+	BookId   int `json:"bookId"`
+	MemberId int `json:"memberId"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// Loan adalah satu peminjaman eksemplar buku oleh member. ReturnedAt kosong
+// selama buku belum dikembalikan. Fine dihitung saat pengembalian, dalam rupiah.
+type Loan struct {
+	Id         int        `json:"id"`
+	BookId     int        `json:"bookId"`
+	CopyId     int        `json:"copyId"`
+	MemberId   int        `json:"memberId"`
+	BorrowedAt time.Time  `json:"borrowedAt"`
+	DueAt      time.Time  `json:"dueAt"`
+	ReturnedAt *time.Time `json:"returnedAt"`
+	Fine       int        `json:"fine"`
+	FinePaid   bool       `json:"finePaid"`
+}
+
+// LoanRequest meminjam eksemplar tertentu jika Barcode diisi, selain itu
+// eksemplar tersedia pertama dari BookId yang dipinjamkan.
+type LoanRequest struct {
+	BookId   int    `json:"bookId"`
+	MemberId int    `json:"memberId"`
+	Barcode  string `json:"barcode"`
+}
+
+// LoanFilter berisi query parameter GET /loans. Status bernilai active,
+// overdue, atau returned.
+type LoanFilter struct {
+	MemberId int    `form:"memberId"`
+	Status   string `form:"status"`
+}
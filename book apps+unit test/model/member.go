@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+type Member struct {
+	Id       int       `json:"id"`
+	Name     string    `json:"name"`
+	Email    string    `json:"email"`
+	JoinedAt time.Time `json:"joinedAt"`
+}
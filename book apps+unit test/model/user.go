@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+const (
+	RoleLibrarian = "librarian"
+	RoleMember    = "member"
+)
+
+// User adalah akun untuk memakai API, bukan member perpustakaan. Password
+// hanya diisi pada request, yang disimpan hanya PasswordHash dan keduanya tidak
+// pernah dikirim balik ke client.
+type User struct {
+	Id           int       `json:"id"`
+	Username     string    `json:"username"`
+	Password     string    `json:"password,omitempty"`
+	PasswordHash string    `json:"-"`
+	Role         string    `json:"role"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type LoginResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type UserRoleRequest struct {
+	Role string `json:"role"`
+}
@@ -0,0 +1,220 @@
+package repositori
+
+import (
+	"database/sql"
+	"fmt"
+	"simple-clean-architecture/model"
+)
+
+const authorColumns = "a.id, a.name, (SELECT COUNT(*) FROM mst_book_author x WHERE x.author_id = a.id)"
+
+// syncBookAuthorText mengisi ulang kolom author di mst_book dengan nama author
+// yang terhubung sesuai urutannya, sehingga pencarian dan tampilan lama tetap
+// memakai nama terbaru. Hanya buku yang kolom author-nya berubah yang disentuh
+// dan dinaikkan version-nya. %s diisi book_id atau author_id.
+const syncBookAuthorText = `UPDATE mst_book b SET author = s.author, version = b.version + 1
+	FROM (SELECT x.book_id, LEFT(string_agg(a.name, ', ' ORDER BY x.position), 255) AS author
+		FROM mst_book_author x JOIN mst_author a ON a.id = x.author_id
+		WHERE x.book_id IN (SELECT book_id FROM mst_book_author WHERE %s = $1) GROUP BY x.book_id) s
+	WHERE b.id = s.book_id AND b.author <> s.author
+	RETURNING b.id`
+
+// authorSeparator memisahkan nama author di kolom author mst_book, sama
+// dengan migrasi author di ddl.sql.
+const authorSeparator = `\s*(,|;|&|\s+and\s+|\s+dan\s+)\s*`
+
+type authorRepositori struct {
+	db *sql.DB
+}
+
+type AuthorRepositori interface {
+	CreateAuthor(author model.Author) (model.Author, error)
+	GetAllAuthor() ([]model.Author, error)
+	GetAuthorById(id int) (model.Author, error)
+	GetAuthorByName(name string) (model.Author, error)
+	UpdateAuthor(author model.Author, userId int) error
+	DeleteAuthor(id int) error
+	GetAuthorsByBookId(bookId int) ([]model.Author, error)
+	SetBookAuthors(bookId int, authorIds []int, userId int) error
+}
+
+func (a *authorRepositori) CreateAuthor(author model.Author) (model.Author, error) {
+	err := a.db.QueryRow("INSERT INTO mst_author(name) VALUES($1) RETURNING id", author.Name).Scan(&author.Id)
+
+	if err != nil {
+		return model.Author{}, err
+	}
+
+	return author, nil
+}
+
+func (a *authorRepositori) GetAllAuthor() ([]model.Author, error) {
+	return a.queryAuthors("SELECT " + authorColumns + " FROM mst_author a ORDER BY a.name, a.id")
+}
+
+func (a *authorRepositori) GetAuthorById(id int) (model.Author, error) {
+	return scanAuthor(a.db.QueryRow("SELECT "+authorColumns+" FROM mst_author a WHERE a.id = $1", id))
+}
+
+// GetAuthorByName tidak membedakan huruf besar kecil, sama seperti unique
+// index pada mst_author.
+func (a *authorRepositori) GetAuthorByName(name string) (model.Author, error) {
+	return scanAuthor(a.db.QueryRow("SELECT "+authorColumns+" FROM mst_author a WHERE LOWER(a.name) = LOWER($1)", name))
+}
+
+func (a *authorRepositori) UpdateAuthor(author model.Author, userId int) error {
+	tx, err := a.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE mst_author SET name = $1 WHERE id = $2", author.Name, author.Id); err != nil {
+		return err
+	}
+	if err := syncBookAuthors(tx, "author_id", author.Id, userId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (a *authorRepositori) DeleteAuthor(id int) error {
+	_, err := a.db.Exec("DELETE FROM mst_author WHERE id = $1", id)
+
+	return err
+}
+
+func (a *authorRepositori) GetAuthorsByBookId(bookId int) ([]model.Author, error) {
+	return a.queryAuthors("SELECT "+authorColumns+" FROM mst_author a JOIN mst_book_author ba ON ba.author_id = a.id WHERE ba.book_id = $1 ORDER BY ba.position", bookId)
+}
+
+// SetBookAuthors mengganti semua author sebuah buku dalam satu transaksi.
+// Urutan authorIds disimpan sebagai urutan penulis. Jika authorIds kosong,
+// kolom author di mst_book tidak diubah.
+func (a *authorRepositori) SetBookAuthors(bookId int, authorIds []int, userId int) error {
+	tx, err := a.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM mst_book_author WHERE book_id = $1", bookId); err != nil {
+		return err
+	}
+	for i, authorId := range authorIds {
+		_, err := tx.Exec("INSERT INTO mst_book_author(book_id, author_id, position) VALUES($1, $2, $3)", bookId, authorId, i+1)
+		if err != nil {
+			return err
+		}
+	}
+	if len(authorIds) > 0 {
+		if err := syncBookAuthors(tx, "book_id", bookId, userId); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// syncBookAuthors menjalankan syncBookAuthorText lalu mencatat revisi untuk
+// setiap buku yang berubah.
+func syncBookAuthors(tx *sql.Tx, column string, id int, userId int) error {
+	rows, err := tx.Query(fmt.Sprintf(syncBookAuthorText, column), id)
+	if err != nil {
+		return err
+	}
+
+	bookIds := []int{}
+	for rows.Next() {
+		var bookId int
+		if err := rows.Scan(&bookId); err != nil {
+			rows.Close()
+			return err
+		}
+		bookIds = append(bookIds, bookId)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, bookId := range bookIds {
+		if _, err := tx.Exec(insertBookRevision, bookId, model.BookActionUpdate, userId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// linkBookAuthors menghubungkan buku ke mst_author sesuai kolom author-nya,
+// dan membuat author yang belum ada. Jika kolom author masih sama dengan
+// gabungan author yang terhubung, hubungan yang ada dibiarkan agar urutan dan
+// nama yang diatur lewat SetBookAuthors tidak dipecah ulang.
+func linkBookAuthors(tx *sql.Tx, bookId int) error {
+	var linked bool
+	err := tx.QueryRow(`SELECT b.author = COALESCE((SELECT LEFT(string_agg(a.name, ', ' ORDER BY x.position), 255)
+		FROM mst_book_author x JOIN mst_author a ON a.id = x.author_id WHERE x.book_id = b.id), '')
+		FROM mst_book b WHERE b.id = $1`, bookId).Scan(&linked)
+	if err != nil || linked {
+		return err
+	}
+
+	if _, err := tx.Exec(`INSERT INTO mst_author(name)
+		SELECT DISTINCT ON (LOWER(TRIM(s.name))) TRIM(s.name)
+		FROM mst_book b CROSS JOIN LATERAL regexp_split_to_table(b.author, $2, 'i') AS s(name)
+		WHERE b.id = $1 AND TRIM(s.name) <> ''
+		ON CONFLICT DO NOTHING`, bookId, authorSeparator); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM mst_book_author WHERE book_id = $1", bookId); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO mst_book_author(book_id, author_id, position)
+		SELECT b.id, a.id, MIN(s.position)
+		FROM mst_book b CROSS JOIN LATERAL regexp_split_to_table(b.author, $2, 'i') WITH ORDINALITY AS s(name, position)
+		JOIN mst_author a ON LOWER(a.name) = LOWER(TRIM(s.name))
+		WHERE b.id = $1
+		GROUP BY b.id, a.id`, bookId, authorSeparator)
+
+	return err
+}
+
+func (a *authorRepositori) queryAuthors(query string, args ...interface{}) ([]model.Author, error) {
+	rows, err := a.db.Query(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	authors := []model.Author{}
+	for rows.Next() {
+		author, err := scanAuthor(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		authors = append(authors, author)
+	}
+
+	return authors, rows.Err()
+}
+
+func scanAuthor(row rowScanner) (model.Author, error) {
+	var author model.Author
+
+	err := row.Scan(&author.Id, &author.Name, &author.BookCount)
+
+	if err != nil {
+		return model.Author{}, err
+	}
+
+	return author, nil
+}
+
+func NewAuthorRepositori(db *sql.DB) AuthorRepositori {
+	return &authorRepositori{db: db}
+}
@@ -0,0 +1,163 @@
+package repositori
+
+import (
+	"regexp"
+	"simple-clean-architecture/model"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+const authorSelect = "SELECT a.id, a.name, (SELECT COUNT(*) FROM mst_book_author x WHERE x.author_id = a.id) FROM mst_author a"
+
+func TestCreateAuthor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewAuthorRepositori(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO mst_author(name) VALUES($1) RETURNING id")).
+		WithArgs("Alan Donovan").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	author, err := repo.CreateAuthor(model.Author{Name: "Alan Donovan"})
+	assert.NoError(t, err)
+	assert.Equal(t, model.Author{Id: 1, Name: "Alan Donovan"}, author)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAllAuthor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewAuthorRepositori(db)
+
+	rows := sqlmock.NewRows([]string{"id", "name", "book_count"}).
+		AddRow(1, "Alan Donovan", 1).
+		AddRow(2, "Rob Pike", 0)
+	mock.ExpectQuery(regexp.QuoteMeta(authorSelect + " ORDER BY a.name, a.id")).WillReturnRows(rows)
+
+	authors, err := repo.GetAllAuthor()
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Author{{Id: 1, Name: "Alan Donovan", BookCount: 1}, {Id: 2, Name: "Rob Pike"}}, authors)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAuthorByName(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewAuthorRepositori(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(authorSelect + " WHERE LOWER(a.name) = LOWER($1)")).
+		WithArgs("rob pike").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "book_count"}).AddRow(2, "Rob Pike", 3))
+
+	author, err := repo.GetAuthorByName("rob pike")
+	assert.NoError(t, err)
+	assert.Equal(t, model.Author{Id: 2, Name: "Rob Pike", BookCount: 3}, author)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateAuthor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewAuthorRepositori(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE mst_author SET name = $1 WHERE id = $2")).
+		WithArgs("Rob Pike", 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE mst_book b SET author =")).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(4))
+	mock.ExpectExec(regexp.QuoteMeta(insertBookRevision)).WithArgs(1, model.BookActionUpdate, 5).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(insertBookRevision)).WithArgs(4, model.BookActionUpdate, 5).WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	err = repo.UpdateAuthor(model.Author{Id: 2, Name: "Rob Pike"}, 5)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAuthorsByBookId(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewAuthorRepositori(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(authorSelect + " JOIN mst_book_author ba ON ba.author_id = a.id WHERE ba.book_id = $1 ORDER BY ba.position")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "book_count"}).AddRow(3, "Brian Kernighan", 2))
+
+	authors, err := repo.GetAuthorsByBookId(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Author{{Id: 3, Name: "Brian Kernighan", BookCount: 2}}, authors)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetBookAuthors(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewAuthorRepositori(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM mst_book_author WHERE book_id = $1")).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO mst_book_author(book_id, author_id, position) VALUES($1, $2, $3)")).
+		WithArgs(1, 2, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO mst_book_author(book_id, author_id, position) VALUES($1, $2, $3)")).
+		WithArgs(1, 3, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE mst_book b SET author =")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec(regexp.QuoteMeta(insertBookRevision)).WithArgs(1, model.BookActionUpdate, 5).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = repo.SetBookAuthors(1, []int{2, 3}, 5)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetBookAuthors_Fail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewAuthorRepositori(db)
+
+	// Sad Path: author dihapus di tengah jalan, transaksi di-rollback
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM mst_book_author WHERE book_id = $1")).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO mst_book_author(book_id, author_id, position) VALUES($1, $2, $3)")).
+		WithArgs(1, 2, 1).
+		WillReturnError(sqlmock.ErrCancelled)
+	mock.ExpectRollback()
+
+	err = repo.SetBookAuthors(1, []int{2}, 5)
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthorSeparator(t *testing.T) {
+	// pemisah yang sama dipakai PostgreSQL dengan flag 'i'
+	separator := regexp.MustCompile("(?i)" + authorSeparator)
+
+	assert.Equal(t, []string{"Alan Donovan", "Brian Kernighan"}, separator.Split("Alan Donovan AND Brian Kernighan", -1))
+	assert.Equal(t, []string{"A", "B", "C", "D", "E"}, separator.Split("A, B; C & D dan E", -1))
+	assert.Equal(t, []string{"Sandra Anderson"}, separator.Split("Sandra Anderson", -1))
+}
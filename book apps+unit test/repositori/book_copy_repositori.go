@@ -0,0 +1,192 @@
+package repositori
+
+import (
+	"database/sql"
+	"errors"
+	"simple-clean-architecture/model"
+	"time"
+)
+
+var ErrCopyStatusChanged = errors.New("book copy status has changed")
+
+const copyColumns = "id, book_id, barcode, condition, shelf_location, status"
+
+type bookCopyRepositori struct {
+	db *sql.DB
+}
+
+// CreateCopy dan UpdateCopy memberikan eksemplar yang menjadi available kepada
+// antrian hold terdepan dengan batas ambil holdExpiresAt, sehingga status yang
+// dikembalikan bisa berubah menjadi on_hold. UpdateCopy hanya berhasil jika
+// status eksemplar masih fromStatus.
+type BookCopyRepositori interface {
+	CreateCopy(copy model.BookCopy, now time.Time, holdExpiresAt time.Time) (model.BookCopy, error)
+	GetCopiesByBookId(bookId int) ([]model.BookCopy, error)
+	GetCopyById(id int) (model.BookCopy, error)
+	GetCopyByBarcode(barcode string) (model.BookCopy, error)
+	GetAvailableCopy(bookId int) (model.BookCopy, error)
+	GetAvailability(bookId int) (model.BookAvailability, error)
+	UpdateCopy(copy model.BookCopy, fromStatus string, now time.Time, holdExpiresAt time.Time) (model.BookCopy, error)
+}
+
+func (b *bookCopyRepositori) CreateCopy(copy model.BookCopy, now time.Time, holdExpiresAt time.Time) (model.BookCopy, error) {
+	tx, err := b.db.Begin()
+	if err != nil {
+		return model.BookCopy{}, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow("INSERT INTO mst_book_copy(book_id, barcode, condition, shelf_location, status) VALUES($1, $2, $3, $4, $5) RETURNING id",
+		copy.BookId, copy.Barcode, copy.Condition, copy.ShelfLocation, copy.Status).Scan(&copy.Id)
+
+	if err != nil {
+		return model.BookCopy{}, err
+	}
+
+	if err := releaseCopy(tx, &copy, now, holdExpiresAt); err != nil {
+		return model.BookCopy{}, err
+	}
+
+	return copy, tx.Commit()
+}
+
+func (b *bookCopyRepositori) GetCopiesByBookId(bookId int) ([]model.BookCopy, error) {
+	rows, err := b.db.Query("SELECT "+copyColumns+" FROM mst_book_copy WHERE book_id = $1 ORDER BY id", bookId)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	copies := []model.BookCopy{}
+	for rows.Next() {
+		copy, err := scanCopy(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		copies = append(copies, copy)
+	}
+
+	return copies, rows.Err()
+}
+
+func (b *bookCopyRepositori) GetCopyById(id int) (model.BookCopy, error) {
+	return scanCopy(b.db.QueryRow("SELECT "+copyColumns+" FROM mst_book_copy WHERE id = $1", id))
+}
+
+func (b *bookCopyRepositori) GetCopyByBarcode(barcode string) (model.BookCopy, error) {
+	return scanCopy(b.db.QueryRow("SELECT "+copyColumns+" FROM mst_book_copy WHERE barcode = $1", barcode))
+}
+
+// GetAvailableCopy mengembalikan eksemplar tersedia dengan id terkecil, atau
+// sql.ErrNoRows jika tidak ada.
+func (b *bookCopyRepositori) GetAvailableCopy(bookId int) (model.BookCopy, error) {
+	return scanCopy(b.db.QueryRow("SELECT "+copyColumns+" FROM mst_book_copy WHERE book_id = $1 AND status = $2 ORDER BY id LIMIT 1", bookId, model.CopyStatusAvailable))
+}
+
+func (b *bookCopyRepositori) GetAvailability(bookId int) (model.BookAvailability, error) {
+	var availability model.BookAvailability
+
+	rows, err := b.db.Query("SELECT status, COUNT(*) FROM mst_book_copy WHERE book_id = $1 GROUP BY status", bookId)
+
+	if err != nil {
+		return model.BookAvailability{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status string
+		var count int
+
+		if err := rows.Scan(&status, &count); err != nil {
+			return model.BookAvailability{}, err
+		}
+
+		availability.Total += count
+		switch status {
+		case model.CopyStatusAvailable:
+			availability.Available = count
+		case model.CopyStatusOnLoan:
+			availability.OnLoan = count
+		case model.CopyStatusOnHold:
+			availability.OnHold = count
+		case model.CopyStatusLost:
+			availability.Lost = count
+		case model.CopyStatusRepair:
+			availability.Repair = count
+		}
+	}
+
+	return availability, rows.Err()
+}
+
+// UpdateCopy menyimpan perubahan eksemplar selama statusnya masih fromStatus,
+// sehingga peminjaman atau hold yang terjadi sejak eksemplar dibaca tidak
+// tertimpa. Jika status sudah berubah, ErrCopyStatusChanged dikembalikan.
+// Eksemplar on_loan yang ditandai lost menutup loan aktifnya dengan waktu now.
+func (b *bookCopyRepositori) UpdateCopy(copy model.BookCopy, fromStatus string, now time.Time, holdExpiresAt time.Time) (model.BookCopy, error) {
+	tx, err := b.db.Begin()
+	if err != nil {
+		return model.BookCopy{}, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("UPDATE mst_book_copy SET condition = $1, shelf_location = $2, status = $3 WHERE id = $4 AND status = $5", copy.Condition, copy.ShelfLocation, copy.Status, copy.Id, fromStatus)
+	if err != nil {
+		return model.BookCopy{}, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return model.BookCopy{}, err
+	}
+	if affected == 0 {
+		return model.BookCopy{}, ErrCopyStatusChanged
+	}
+
+	if fromStatus == model.CopyStatusOnLoan && copy.Status == model.CopyStatusLost {
+		_, err = tx.Exec("UPDATE trx_loan SET returned_at = $1 WHERE copy_id = $2 AND returned_at IS NULL", now, copy.Id)
+		if err != nil {
+			return model.BookCopy{}, err
+		}
+	}
+
+	if err := releaseCopy(tx, &copy, now, holdExpiresAt); err != nil {
+		return model.BookCopy{}, err
+	}
+
+	return copy, tx.Commit()
+}
+
+// releaseCopy meneruskan eksemplar available ke antrian hold lewat
+// assignCopyToNextHold, agar member di antrian tidak didahului peminjam yang
+// datang langsung, lalu mengisi status akhir eksemplar.
+func releaseCopy(tx *sql.Tx, copy *model.BookCopy, now time.Time, holdExpiresAt time.Time) error {
+	if copy.Status != model.CopyStatusAvailable {
+		return nil
+	}
+
+	if err := assignCopyToNextHold(tx, copy.Id, copy.BookId, now, holdExpiresAt); err != nil {
+		return err
+	}
+
+	return tx.QueryRow("SELECT status FROM mst_book_copy WHERE id = $1", copy.Id).Scan(&copy.Status)
+}
+
+func scanCopy(row rowScanner) (model.BookCopy, error) {
+	var copy model.BookCopy
+
+	err := row.Scan(&copy.Id, &copy.BookId, &copy.Barcode, &copy.Condition, &copy.ShelfLocation, &copy.Status)
+
+	if err != nil {
+		return model.BookCopy{}, err
+	}
+
+	return copy, nil
+}
+
+func NewBookCopyRepositori(db *sql.DB) BookCopyRepositori {
+	return &bookCopyRepositori{db: db}
+}
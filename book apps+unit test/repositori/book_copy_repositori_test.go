@@ -0,0 +1,259 @@
+package repositori
+
+import (
+	"database/sql"
+	"regexp"
+	"simple-clean-architecture/model"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var copyRowColumns = []string{"id", "book_id", "barcode", "condition", "shelf_location", "status"}
+
+func TestCreateCopy(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookCopyRepositori(db)
+
+	now := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(72 * time.Hour)
+
+	copy := model.BookCopy{BookId: 1, Barcode: "B-001", Condition: "good", ShelfLocation: "A1", Status: model.CopyStatusAvailable}
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO mst_book_copy(book_id, barcode, condition, shelf_location, status) VALUES($1, $2, $3, $4, $5) RETURNING id")).
+		WithArgs(1, "B-001", "good", "A1", model.CopyStatusAvailable).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM trx_hold WHERE book_id = $1 AND status = $2 ORDER BY id LIMIT 1 FOR UPDATE SKIP LOCKED")).
+		WithArgs(1, model.HoldStatusWaiting).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE mst_book_copy SET status = $1 WHERE id = $2")).
+		WithArgs(model.CopyStatusAvailable, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status FROM mst_book_copy WHERE id = $1")).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.CopyStatusAvailable))
+	mock.ExpectCommit()
+
+	created, err := repo.CreateCopy(copy, now, expiresAt)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, created.Id)
+	assert.Equal(t, "B-001", created.Barcode)
+	assert.Equal(t, model.CopyStatusAvailable, created.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateCopy_WaitingHold(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookCopyRepositori(db)
+
+	now := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(72 * time.Hour)
+
+	// hold 4 menunggu, eksemplar baru langsung disiapkan untuknya
+	copy := model.BookCopy{BookId: 1, Barcode: "B-002", Condition: "good", Status: model.CopyStatusAvailable}
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO mst_book_copy")).
+		WithArgs(1, "B-002", "good", "", model.CopyStatusAvailable).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(8))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM trx_hold WHERE book_id = $1 AND status = $2 ORDER BY id LIMIT 1 FOR UPDATE SKIP LOCKED")).
+		WithArgs(1, model.HoldStatusWaiting).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE trx_hold SET status = $1, copy_id = $2, ready_at = $3, expires_at = $4 WHERE id = $5")).
+		WithArgs(model.HoldStatusReady, 8, now, expiresAt, 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE mst_book_copy SET status = $1 WHERE id = $2")).
+		WithArgs(model.CopyStatusOnHold, 8).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status FROM mst_book_copy WHERE id = $1")).
+		WithArgs(8).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.CopyStatusOnHold))
+	mock.ExpectCommit()
+
+	created, err := repo.CreateCopy(copy, now, expiresAt)
+	assert.NoError(t, err)
+	assert.Equal(t, model.CopyStatusOnHold, created.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetCopiesByBookId(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookCopyRepositori(db)
+
+	rows := sqlmock.NewRows(copyRowColumns).
+		AddRow(1, 1, "B-001", "good", "A1", model.CopyStatusAvailable).
+		AddRow(2, 1, "B-002", "worn", "A1", model.CopyStatusOnLoan)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, book_id, barcode, condition, shelf_location, status FROM mst_book_copy WHERE book_id = $1 ORDER BY id")).
+		WithArgs(1).
+		WillReturnRows(rows)
+
+	copies, err := repo.GetCopiesByBookId(1)
+	assert.NoError(t, err)
+	assert.Len(t, copies, 2)
+	assert.Equal(t, model.CopyStatusOnLoan, copies[1].Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetCopyByBarcode_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookCopyRepositori(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM mst_book_copy WHERE barcode = $1")).WithArgs("B-404").WillReturnRows(sqlmock.NewRows(copyRowColumns))
+
+	_, err = repo.GetCopyByBarcode("B-404")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestGetAvailableCopy(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookCopyRepositori(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM mst_book_copy WHERE book_id = $1 AND status = $2 ORDER BY id LIMIT 1")).
+		WithArgs(1, model.CopyStatusAvailable).
+		WillReturnRows(sqlmock.NewRows(copyRowColumns).AddRow(3, 1, "B-003", "good", "A2", model.CopyStatusAvailable))
+
+	copy, err := repo.GetAvailableCopy(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, copy.Id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAvailability(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookCopyRepositori(db)
+
+	rows := sqlmock.NewRows([]string{"status", "count"}).
+		AddRow(model.CopyStatusAvailable, 2).
+		AddRow(model.CopyStatusOnLoan, 1).
+		AddRow(model.CopyStatusLost, 1)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status, COUNT(*) FROM mst_book_copy WHERE book_id = $1 GROUP BY status")).
+		WithArgs(1).
+		WillReturnRows(rows)
+
+	availability, err := repo.GetAvailability(1)
+	assert.NoError(t, err)
+	assert.Equal(t, model.BookAvailability{Total: 4, Available: 2, OnLoan: 1, Lost: 1}, availability)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateCopy(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookCopyRepositori(db)
+
+	now := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+
+	// eksemplar yang tidak available tidak menyentuh antrian hold
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE mst_book_copy SET condition = $1, shelf_location = $2, status = $3 WHERE id = $4 AND status = $5")).
+		WithArgs("damaged", "R1", model.CopyStatusRepair, 2, model.CopyStatusAvailable).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	updated, err := repo.UpdateCopy(model.BookCopy{Id: 2, Condition: "damaged", ShelfLocation: "R1", Status: model.CopyStatusRepair}, model.CopyStatusAvailable, now, now.Add(72*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, model.CopyStatusRepair, updated.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateCopy_StatusChanged(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookCopyRepositori(db)
+
+	now := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+
+	// eksemplar sudah dipinjam sejak dibaca, loan tidak boleh tertimpa
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE mst_book_copy SET condition = $1, shelf_location = $2, status = $3 WHERE id = $4 AND status = $5")).
+		WithArgs("damaged", "R1", model.CopyStatusAvailable, 2, model.CopyStatusAvailable).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	_, err = repo.UpdateCopy(model.BookCopy{Id: 2, Condition: "damaged", ShelfLocation: "R1", Status: model.CopyStatusAvailable}, model.CopyStatusAvailable, now, now.Add(72*time.Hour))
+	assert.ErrorIs(t, err, ErrCopyStatusChanged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateCopy_LostOnLoan(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookCopyRepositori(db)
+
+	now := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+
+	// eksemplar hilang saat dipinjam, loan aktifnya ditutup
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE mst_book_copy SET condition = $1, shelf_location = $2, status = $3 WHERE id = $4 AND status = $5")).
+		WithArgs("good", "A1", model.CopyStatusLost, 3, model.CopyStatusOnLoan).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE trx_loan SET returned_at = $1 WHERE copy_id = $2 AND returned_at IS NULL")).
+		WithArgs(now, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	updated, err := repo.UpdateCopy(model.BookCopy{Id: 3, Condition: "good", ShelfLocation: "A1", Status: model.CopyStatusLost}, model.CopyStatusOnLoan, now, now.Add(72*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, model.CopyStatusLost, updated.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateCopy_WaitingHold(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookCopyRepositori(db)
+
+	now := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(72 * time.Hour)
+
+	// eksemplar selesai diperbaiki saat hold 4 menunggu
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE mst_book_copy SET condition = $1, shelf_location = $2, status = $3 WHERE id = $4 AND status = $5")).
+		WithArgs("good", "A1", model.CopyStatusAvailable, 2, model.CopyStatusRepair).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM trx_hold WHERE book_id = $1 AND status = $2 ORDER BY id LIMIT 1 FOR UPDATE SKIP LOCKED")).
+		WithArgs(1, model.HoldStatusWaiting).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE trx_hold SET status = $1, copy_id = $2, ready_at = $3, expires_at = $4 WHERE id = $5")).
+		WithArgs(model.HoldStatusReady, 2, now, expiresAt, 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE mst_book_copy SET status = $1 WHERE id = $2")).
+		WithArgs(model.CopyStatusOnHold, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status FROM mst_book_copy WHERE id = $1")).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.CopyStatusOnHold))
+	mock.ExpectCommit()
+
+	updated, err := repo.UpdateCopy(model.BookCopy{Id: 2, BookId: 1, Condition: "good", ShelfLocation: "A1", Status: model.CopyStatusAvailable}, model.CopyStatusRepair, now, expiresAt)
+	assert.NoError(t, err)
+	assert.Equal(t, model.CopyStatusOnHold, updated.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
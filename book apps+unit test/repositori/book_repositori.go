@@ -9,89 +9,417 @@ package repositori
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 	"simple-clean-architecture/model"
+	"strings"
+)
+
+const bookColumns = "id, title, author, release_year, pages, COALESCE(isbn, ''), COALESCE(isbn10, ''), version"
+
+// insertBookRevision menyalin isi buku saat ini ke book_revisions. Dijalankan
+// dalam transaksi yang sama dengan perubahan yang menaikkan version buku.
+const insertBookRevision = `INSERT INTO book_revisions(book_id, version, title, author, release_year, pages, isbn, isbn10, deleted_at, action, changed_by)
+	SELECT id, version, title, author, release_year, pages, isbn, isbn10, deleted_at, $2, NULLIF($3, 0) FROM mst_book WHERE id = $1`
+
+var (
+	ErrBookCopiesOnLoan = errors.New("book still has copies on loan")
+	ErrBookCopiesOnHold = errors.New("book still has copies on hold")
 )
 
 type bookRepositori struct {
 	db *sql.DB
 }
 
+// BookRepositori hanya membaca buku yang belum dihapus, kecuali
+// GetBookRevisions. userId adalah user yang melakukan perubahan dan dicatat di
+// book_revisions. Buku yang dibuat atau diubah dihubungkan ke mst_author
+// sesuai kolom author-nya.
 type BookRepositori interface {
-	CreateNewBook(book model.Book) (model.Book, error)
-	GetAllBook() ([]model.Book, error)
+	CreateNewBook(book model.Book, userId int) (model.Book, error)
+	GetAllBook(filter model.BookFilter) ([]model.Book, int, error)
 	GetBookById(id int) (model.Book, error)
-	UpdateBook(book *model.Book) (model.Book, error)
-	DeleteBook(id int) error
+	GetBookIdByIsbn(isbn string) (id int, deleted bool, err error)
+	UpdateBook(book *model.Book, userId int) (model.Book, error)
+	DeleteBook(id int, userId int) error
+	RestoreBook(id int, userId int) (model.Book, error)
+	UpsertBooks(books []model.Book, dryRun bool, userId int) ([]model.BookUpsertResult, error)
+	StreamBooks(fn func(book model.Book) error) error
+	GetBookRevisions(bookId int) ([]model.BookRevision, error)
 }
 
-func (b *bookRepositori) CreateNewBook(book model.Book) (model.Book, error) {
+func (b *bookRepositori) CreateNewBook(book model.Book, userId int) (model.Book, error) {
 	var bookId int
 
-	err := b.db.QueryRow("INSERT INTO mst_book(title, author, release_year, pages) VALUES($1, $2, $3, $4) RETURNING id", book.Title, book.Author, book.ReleaseYear, book.Pages).Scan(&bookId) 
+	tx, err := b.db.Begin()
+	if err != nil {
+		return model.Book{}, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow("INSERT INTO mst_book(title, author, release_year, pages, isbn, isbn10) VALUES($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, '')) RETURNING id, version", book.Title, book.Author, book.ReleaseYear, book.Pages, book.Isbn, book.Isbn10).Scan(&bookId, &book.Version)
 
 	if err != nil {
 		return model.Book{}, err
 	}
 	book.Id = bookId
 
-	return book, nil
+	if err := linkBookAuthors(tx, bookId); err != nil {
+		return model.Book{}, err
+	}
+	if _, err := tx.Exec(insertBookRevision, bookId, model.BookActionCreate, userId); err != nil {
+		return model.Book{}, err
+	}
+
+	return book, tx.Commit()
 }
 
-func (b *bookRepositori) GetAllBook() ([]model.Book, error) {
-	var books []model.Book
+// bookSortColumns memetakan nilai query parameter sort ke nama kolom, sehingga
+// input client tidak pernah masuk ke SQL.
+var bookSortColumns = map[string]string{
+	"id":          "id",
+	"title":       "title",
+	"author":      "author",
+	"releaseYear": "release_year",
+	"pages":       "pages",
+}
 
-	rows, err := b.db.Query("SELECT * FROM mst_book")
+// GetAllBook mengembalikan buku yang cocok dengan filter beserta jumlah semua
+// buku yang cocok. Total tidak dipengaruhi oleh cursor maupun offset.
+func (b *bookRepositori) GetAllBook(filter model.BookFilter) ([]model.Book, int, error) {
+	conditions := []string{"deleted_at IS NULL"}
+	args := []interface{}{}
+	where := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.Title != "" {
+		where("title ILIKE $%d", "%"+escapeLike(filter.Title)+"%")
+	}
+	if filter.Author != "" {
+		where("author ILIKE $%d", "%"+escapeLike(filter.Author)+"%")
+	}
+	if filter.AuthorId != 0 {
+		where("id IN (SELECT book_id FROM mst_book_author WHERE author_id = $%d)", filter.AuthorId)
+	}
+	if filter.CategoryId != 0 {
+		where("id IN (SELECT book_id FROM mst_book_category WHERE category_id = $%d)", filter.CategoryId)
+	}
+	if filter.MinYear != 0 {
+		where("release_year >= $%d", filter.MinYear)
+	}
+	if filter.MaxYear != 0 {
+		where("release_year <= $%d", filter.MaxYear)
+	}
+	if filter.MinPages != 0 {
+		where("pages >= $%d", filter.MinPages)
+	}
+	if filter.MaxPages != 0 {
+		where("pages <= $%d", filter.MaxPages)
+	}
+
+	var total int
+	err := b.db.QueryRow("SELECT COUNT(*) FROM mst_book"+whereClause(conditions), args...).Scan(&total)
 
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	for rows.Next() {
-		var book model.Book
+	column, ok := bookSortColumns[filter.Sort]
+	if !ok {
+		column = "id"
+	}
+	direction, comparison := "ASC", ">"
+	if filter.Order == "desc" {
+		direction, comparison = "DESC", "<"
+	}
+
+	if filter.After != nil {
+		args = append(args, filter.After.Value, filter.After.Id)
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s ($%d, $%d)", column, comparison, len(args)-1, len(args)))
+	}
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf("SELECT %s FROM mst_book%s ORDER BY %s %s, id %s LIMIT $%d OFFSET $%d",
+		bookColumns, whereClause(conditions), column, direction, direction, len(args)-1, len(args))
 
-		err := rows.Scan(&book.Id, &book.Title, &book.Author, &book.ReleaseYear, &book.Pages)
+	rows, err := b.db.Query(query, args...)
+
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	books := []model.Book{}
+	for rows.Next() {
+		book, err := scanBook(rows)
 
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 
 		books = append(books, book)
 	}
 
-	return books, nil
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return books, total, nil
 }
 
 func (b *bookRepositori) GetBookById(id int) (model.Book, error) {
-	var book model.Book
+	return scanBook(b.db.QueryRow("SELECT "+bookColumns+" FROM mst_book WHERE id = $1 AND deleted_at IS NULL", id))
+}
 
-	err := b.db.QueryRow("SELECT * FROM mst_book WHERE id = $1", id).Scan(&book.Id, &book.Title, &book.Author, &book.ReleaseYear, &book.Pages)
+// GetBookIdByIsbn mencari buku dengan isbn tersebut, termasuk yang sudah
+// dihapus karena isbn tetap unik di tabel. sql.ErrNoRows berarti isbn belum
+// dipakai.
+func (b *bookRepositori) GetBookIdByIsbn(isbn string) (id int, deleted bool, err error) {
+	err = b.db.QueryRow("SELECT id, deleted_at IS NOT NULL FROM mst_book WHERE isbn = $1", isbn).Scan(&id, &deleted)
+	return id, deleted, err
+}
 
+// UpdateBook hanya mengubah buku yang version-nya masih sama dengan
+// book.Version lalu menaikkan version-nya. sql.ErrNoRows berarti buku sudah
+// diubah orang lain atau tidak ada.
+func (b *bookRepositori) UpdateBook(book *model.Book, userId int) (model.Book, error) {
+	tx, err := b.db.Begin()
 	if err != nil {
 		return model.Book{}, err
 	}
+	defer tx.Rollback()
 
-	return book, nil
+	err = tx.QueryRow("UPDATE mst_book SET title = $1, author = $2, release_year = $3, pages = $4, isbn = NULLIF($5, ''), isbn10 = NULLIF($6, ''), version = version + 1 WHERE id = $7 AND version = $8 AND deleted_at IS NULL RETURNING version", book.Title, book.Author, book.ReleaseYear, book.Pages, book.Isbn, book.Isbn10, book.Id, book.Version).Scan(&book.Version)
+
+	if err != nil {
+		return model.Book{}, err
+	}
+
+	if err := linkBookAuthors(tx, book.Id); err != nil {
+		return model.Book{}, err
+	}
+	if _, err := tx.Exec(insertBookRevision, book.Id, model.BookActionUpdate, userId); err != nil {
+		return model.Book{}, err
+	}
+
+	return *book, tx.Commit()
 }
 
-func (b *bookRepositori) UpdateBook(book *model.Book) (model.Book, error) {
-	_, err := b.db.Exec("UPDATE mst_book SET title = $1, author = $2, release_year = $3, pages = $4 WHERE id = $5", book.Title, book.Author, book.ReleaseYear, book.Pages, book.Id)
+// DeleteBook hanya menandai buku sebagai dihapus sehingga bisa dikembalikan
+// dengan RestoreBook. Buku yang masih punya eksemplar dipinjam atau menunggu
+// diambil ditolak dengan ErrBookCopiesOnLoan atau ErrBookCopiesOnHold, dicek
+// dalam statement yang sama dengan penghapusannya. Reservasi yang masih
+// menunggu dibatalkan karena bukunya tidak bisa dipinjam lagi. sql.ErrNoRows
+// berarti buku tidak ada atau sudah dihapus.
+func (b *bookRepositori) DeleteBook(id int, userId int) error {
+	tx, err := b.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE mst_book SET deleted_at = NOW(), version = version + 1 WHERE id = $1 AND deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM mst_book_copy WHERE book_id = $1 AND status IN ($2, $3))`, id, model.CopyStatusOnLoan, model.CopyStatusOnHold)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		var onLoan, onHold bool
+		err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM mst_book_copy WHERE book_id = $1 AND status = $2),
+			EXISTS (SELECT 1 FROM mst_book_copy WHERE book_id = $1 AND status = $3)`, id, model.CopyStatusOnLoan, model.CopyStatusOnHold).Scan(&onLoan, &onHold)
+		switch {
+		case err != nil:
+			return err
+		case onLoan:
+			return ErrBookCopiesOnLoan
+		case onHold:
+			return ErrBookCopiesOnHold
+		}
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.Exec("UPDATE trx_hold SET status = $1 WHERE book_id = $2 AND status = $3", model.HoldStatusCancelled, id, model.HoldStatusWaiting); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(insertBookRevision, id, model.BookActionDelete, userId); err != nil {
+		return err
+	}
 
+	return tx.Commit()
+}
+
+// RestoreBook mengembalikan buku yang sudah dihapus. sql.ErrNoRows berarti
+// buku tidak ada atau tidak sedang dihapus.
+func (b *bookRepositori) RestoreBook(id int, userId int) (model.Book, error) {
+	tx, err := b.db.Begin()
 	if err != nil {
 		return model.Book{}, err
 	}
+	defer tx.Rollback()
 
-	return *book, nil
+	book, err := scanBook(tx.QueryRow("UPDATE mst_book SET deleted_at = NULL, version = version + 1 WHERE id = $1 AND deleted_at IS NOT NULL RETURNING "+bookColumns, id))
+	if err != nil {
+		return model.Book{}, err
+	}
+
+	if _, err := tx.Exec(insertBookRevision, id, model.BookActionRestore, userId); err != nil {
+		return model.Book{}, err
+	}
+
+	return book, tx.Commit()
+}
+
+// UpsertBooks menyimpan satu batch buku dalam satu transaksi. Buku dengan isbn
+// yang sudah ada diperbarui, termasuk yang sudah dihapus sehingga ikut
+// dikembalikan, selain itu dibuat baru. Buku tanpa isbn dicocokkan dengan id
+// dari file export, asalkan buku dengan id tersebut juga tidak punya isbn.
+// Setiap baris dibungkus savepoint, sehingga baris yang ditolak database hanya
+// menggagalkan dirinya sendiri dan error-nya dilaporkan di hasil baris
+// tersebut. Jika dryRun, transaksi di-rollback sehingga hasilnya hanya
+// menunjukkan apa yang akan terjadi.
+func (b *bookRepositori) UpsertBooks(books []model.Book, dryRun bool, userId int) ([]model.BookUpsertResult, error) {
+	tx, err := b.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// xmax bernilai 0 untuk baris yang baru di-insert, bukan hasil update
+	stmt, err := tx.Prepare(`INSERT INTO mst_book(title, author, release_year, pages, isbn, isbn10) VALUES($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''))
+		ON CONFLICT (isbn) DO UPDATE SET title = EXCLUDED.title, author = EXCLUDED.author, release_year = EXCLUDED.release_year, pages = EXCLUDED.pages, isbn10 = EXCLUDED.isbn10, version = mst_book.version + 1, deleted_at = NULL
+		RETURNING id, xmax = 0`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	revisionStmt, err := tx.Prepare(insertBookRevision)
+	if err != nil {
+		return nil, err
+	}
+	defer revisionStmt.Close()
+
+	results := make([]model.BookUpsertResult, 0, len(books))
+	for _, book := range books {
+		var result model.BookUpsertResult
+
+		if _, err := tx.Exec("SAVEPOINT book_row"); err != nil {
+			return nil, err
+		}
+
+		err := sql.ErrNoRows
+		if book.Isbn == "" && book.Id != 0 {
+			err = tx.QueryRow(`UPDATE mst_book SET title = $2, author = $3, release_year = $4, pages = $5, version = version + 1, deleted_at = NULL
+				WHERE id = $1 AND isbn IS NULL RETURNING id`, book.Id, book.Title, book.Author, book.ReleaseYear, book.Pages).Scan(&result.Id)
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			err = stmt.QueryRow(book.Title, book.Author, book.ReleaseYear, book.Pages, book.Isbn, book.Isbn10).Scan(&result.Id, &result.Created)
+		}
+		if err == nil {
+			err = linkBookAuthors(tx, result.Id)
+		}
+		if err == nil {
+			_, err = revisionStmt.Exec(result.Id, model.BookActionImport, userId)
+		}
+
+		if err != nil {
+			if _, err := tx.Exec("ROLLBACK TO SAVEPOINT book_row"); err != nil {
+				return nil, err
+			}
+			result = model.BookUpsertResult{Error: err}
+		} else if _, err := tx.Exec("RELEASE SAVEPOINT book_row"); err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	if dryRun {
+		return results, nil
+	}
+
+	return results, tx.Commit()
 }
 
-func (b *bookRepositori) DeleteBook(id int) error {
-	_, err := b.db.Exec("DELETE FROM mst_book WHERE id = $1", id)
+// StreamBooks memanggil fn untuk setiap buku berurutan berdasarkan id tanpa
+// memuat seluruh tabel ke memori. Error dari fn menghentikan proses.
+func (b *bookRepositori) StreamBooks(fn func(book model.Book) error) error {
+	rows, err := b.db.Query("SELECT " + bookColumns + " FROM mst_book WHERE deleted_at IS NULL ORDER BY id")
 
 	if err != nil {
 		return err
 	}
+	defer rows.Close()
+
+	for rows.Next() {
+		book, err := scanBook(rows)
+
+		if err != nil {
+			return err
+		}
+		if err := fn(book); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// GetBookRevisions mengembalikan semua revisi buku berurutan dari yang
+// pertama, termasuk untuk buku yang sudah dihapus.
+func (b *bookRepositori) GetBookRevisions(bookId int) ([]model.BookRevision, error) {
+	rows, err := b.db.Query(`SELECT r.id, r.book_id, r.title, r.author, r.release_year, r.pages, COALESCE(r.isbn, ''), COALESCE(r.isbn10, ''), r.version,
+		r.deleted_at, r.action, COALESCE(r.changed_by, 0), COALESCE(u.username, ''), r.changed_at
+		FROM book_revisions r LEFT JOIN mst_user u ON u.id = r.changed_by WHERE r.book_id = $1 ORDER BY r.version`, bookId)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []model.BookRevision{}
+	for rows.Next() {
+		var revision model.BookRevision
+		book := &revision.Book
+
+		err := rows.Scan(&revision.Id, &book.Id, &book.Title, &book.Author, &book.ReleaseYear, &book.Pages, &book.Isbn, &book.Isbn10, &book.Version,
+			&revision.DeletedAt, &revision.Action, &revision.UserId, &revision.Username, &revision.ChangedAt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, rows.Err()
+}
+
+func scanBook(row rowScanner) (model.Book, error) {
+	var book model.Book
+
+	err := row.Scan(&book.Id, &book.Title, &book.Author, &book.ReleaseYear, &book.Pages, &book.Isbn, &book.Isbn10, &book.Version)
+
+	if err != nil {
+		return model.Book{}, err
+	}
 
-	return nil
+	return book, nil
+}
+
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
 
+// escapeLike meng-escape karakter wildcard agar pencarian "50%" mencari teks
+// "50%" secara harfiah.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
 }
 
 func NewBookRepositori(db *sql.DB) BookRepositori {
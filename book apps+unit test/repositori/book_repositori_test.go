@@ -1,9 +1,11 @@
 package repositori
 
 import (
+	"database/sql"
 	"regexp"
 	"simple-clean-architecture/model"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
@@ -23,18 +25,43 @@ func TestCreateNewBook(t *testing.T) {
 		Pages:       100,
 	}
 
-	rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
-	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO mst_book(title, author, release_year, pages) VALUES($1, $2, $3, $4) RETURNING id")).
-		WithArgs(book.Title, book.Author, book.ReleaseYear, book.Pages).
+	rows := sqlmock.NewRows([]string{"id", "version"}).AddRow(1, 1)
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO mst_book(title, author, release_year, pages, isbn, isbn10) VALUES($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, '')) RETURNING id, version")).
+		WithArgs(book.Title, book.Author, book.ReleaseYear, book.Pages, book.Isbn, book.Isbn10).
 		WillReturnRows(rows)
+	expectLinkBookAuthors(mock, 1, false)
+	mock.ExpectExec(regexp.QuoteMeta(insertBookRevision)).WithArgs(1, model.BookActionCreate, 5).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 
-	createdBook, err := repo.CreateNewBook(book)
+	createdBook, err := repo.CreateNewBook(book, 5)
 	assert.NoError(t, err)
 	assert.Equal(t, 1, createdBook.Id)
+	assert.Equal(t, 1, createdBook.Version)
 	assert.Equal(t, book.Title, createdBook.Title)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+// expectLinkBookAuthors mengharapkan query dari linkBookAuthors. Jika linked,
+// kolom author sudah sesuai dengan author yang terhubung.
+func expectLinkBookAuthors(mock sqlmock.Sqlmock, bookId int, linked bool) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT b.author = COALESCE(")).
+		WithArgs(bookId).
+		WillReturnRows(sqlmock.NewRows([]string{"linked"}).AddRow(linked))
+	if linked {
+		return
+	}
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO mst_author(name)")).
+		WithArgs(bookId, authorSeparator).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM mst_book_author WHERE book_id = $1")).
+		WithArgs(bookId).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO mst_book_author(book_id, author_id, position)")).
+		WithArgs(bookId, authorSeparator).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
 func TestCreateNewBook_Error(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -44,12 +71,15 @@ func TestCreateNewBook_Error(t *testing.T) {
 
 	book := model.Book{Title: "Test Book", Author: "Test Author", ReleaseYear: 2023, Pages: 100}
 
-	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO mst_book(title, author, release_year, pages) VALUES($1, $2, $3, $4) RETURNING id")).
-		WithArgs(book.Title, book.Author, book.ReleaseYear, book.Pages).
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO mst_book(title, author, release_year, pages, isbn, isbn10) VALUES($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, '')) RETURNING id")).
+		WithArgs(book.Title, book.Author, book.ReleaseYear, book.Pages, book.Isbn, book.Isbn10).
 		WillReturnError(sqlmock.ErrCancelled)
+	mock.ExpectRollback()
 
-	_, err = repo.CreateNewBook(book)
+	_, err = repo.CreateNewBook(book, 5)
 	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestGetAllBook(t *testing.T) {
@@ -59,14 +89,18 @@ func TestGetAllBook(t *testing.T) {
 
 	repo := NewBookRepositori(db)
 
-	rows := sqlmock.NewRows([]string{"id", "title", "author", "release_year", "pages"}).
-		AddRow(1, "Book 1", "Author 1", 2020, 150).
-		AddRow(2, "Book 2", "Author 2", 2021, 200)
+	rows := sqlmock.NewRows([]string{"id", "title", "author", "release_year", "pages", "isbn", "isbn10", "version"}).
+		AddRow(1, "Book 1", "Author 1", 2020, 150, "9780134190440", "0134190440", 1).
+		AddRow(2, "Book 2", "Author 2", 2021, 200, "", "", 1)
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM mst_book")).WillReturnRows(rows)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM mst_book WHERE deleted_at IS NULL")).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, title, author, release_year, pages, COALESCE(isbn, ''), COALESCE(isbn10, ''), version FROM mst_book WHERE deleted_at IS NULL ORDER BY id ASC, id ASC LIMIT $1 OFFSET $2")).
+		WithArgs(10, 0).
+		WillReturnRows(rows)
 
-	books, err := repo.GetAllBook()
+	books, total, err := repo.GetAllBook(model.BookFilter{Limit: 10})
 	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
 	assert.Len(t, books, 2)
 	assert.Equal(t, 1, books[0].Id)
 	assert.Equal(t, "Book 1", books[0].Title)
@@ -83,13 +117,75 @@ func TestGetAllBook_Error(t *testing.T) {
 
 	repo := NewBookRepositori(db)
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM mst_book")).WillReturnError(sqlmock.ErrCancelled)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM mst_book")).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, title, author, release_year, pages, COALESCE(isbn, ''), COALESCE(isbn10, ''), version FROM mst_book")).WillReturnError(sqlmock.ErrCancelled)
 
-	_, err = repo.GetAllBook()
+	_, _, err = repo.GetAllBook(model.BookFilter{Limit: 10})
 	assert.Error(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestGetAllBook_CountError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookRepositori(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM mst_book")).WillReturnError(sqlmock.ErrCancelled)
+
+	_, _, err = repo.GetAllBook(model.BookFilter{Limit: 10})
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAllBook_Filtered(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookRepositori(db)
+
+	filter := model.BookFilter{Title: "50%_off", Author: "pike", MinYear: 2000, MaxYear: 2020, MinPages: 100, MaxPages: 500, Sort: "releaseYear", Order: "desc", Limit: 5, Offset: 10}
+	where := " WHERE deleted_at IS NULL AND title ILIKE $1 AND author ILIKE $2 AND release_year >= $3 AND release_year <= $4 AND pages >= $5 AND pages <= $6"
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM mst_book"+where)).
+		WithArgs(`%50\%\_off%`, "%pike%", 2000, 2020, 100, 500).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, title, author, release_year, pages, COALESCE(isbn, ''), COALESCE(isbn10, ''), version FROM mst_book"+where+" ORDER BY release_year DESC, id DESC LIMIT $7 OFFSET $8")).
+		WithArgs(`%50\%\_off%`, "%pike%", 2000, 2020, 100, 500, 5, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "author", "release_year", "pages", "isbn", "isbn10", "version"}).AddRow(3, "50%_off", "Rob Pike", 2015, 300, "", "", 1))
+
+	books, total, err := repo.GetAllBook(filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 12, total)
+	assert.Equal(t, []model.Book{{Id: 3, Title: "50%_off", Author: "Rob Pike", ReleaseYear: 2015, Pages: 300, Version: 1}}, books)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAllBook_Cursor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookRepositori(db)
+
+	filter := model.BookFilter{Author: "pike", Sort: "title", Order: "asc", Limit: 3, After: &model.BookCursor{Sort: "title", Order: "asc", Value: "Go", Id: 7}}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM mst_book WHERE deleted_at IS NULL AND author ILIKE $1")).
+		WithArgs("%pike%").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, title, author, release_year, pages, COALESCE(isbn, ''), COALESCE(isbn10, ''), version FROM mst_book WHERE deleted_at IS NULL AND author ILIKE $1 AND (title, id) > ($2, $3) ORDER BY title ASC, id ASC LIMIT $4 OFFSET $5")).
+		WithArgs("%pike%", "Go", 7, 3, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "author", "release_year", "pages", "isbn", "isbn10", "version"}))
+
+	books, total, err := repo.GetAllBook(filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, total)
+	assert.Empty(t, books)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestGetBookById(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -97,10 +193,10 @@ func TestGetBookById(t *testing.T) {
 
 	repo := NewBookRepositori(db)
 
-	rows := sqlmock.NewRows([]string{"id", "title", "author", "release_year", "pages"}).
-		AddRow(1, "Book 1", "Author 1", 2020, 150)
+	rows := sqlmock.NewRows([]string{"id", "title", "author", "release_year", "pages", "isbn", "isbn10", "version"}).
+		AddRow(1, "Book 1", "Author 1", 2020, 150, "9780134190440", "0134190440", 1)
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM mst_book WHERE id = $1")).WithArgs(1).WillReturnRows(rows)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, title, author, release_year, pages, COALESCE(isbn, ''), COALESCE(isbn10, ''), version FROM mst_book WHERE id = $1 AND deleted_at IS NULL")).WithArgs(1).WillReturnRows(rows)
 
 	book, err := repo.GetBookById(1)
 	assert.NoError(t, err)
@@ -109,6 +205,9 @@ func TestGetBookById(t *testing.T) {
 	assert.Equal(t, "Author 1", book.Author)
 	assert.Equal(t, 2020, book.ReleaseYear)
 	assert.Equal(t, 150, book.Pages)
+	assert.Equal(t, "9780134190440", book.Isbn)
+	assert.Equal(t, "0134190440", book.Isbn10)
+	assert.Equal(t, 1, book.Version)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -119,13 +218,32 @@ func TestGetBookById_Error(t *testing.T) {
 
 	repo := NewBookRepositori(db)
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM mst_book WHERE id = $1")).WithArgs(1).WillReturnError(sqlmock.ErrCancelled)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, title, author, release_year, pages, COALESCE(isbn, ''), COALESCE(isbn10, ''), version FROM mst_book WHERE id = $1 AND deleted_at IS NULL")).WithArgs(1).WillReturnError(sqlmock.ErrCancelled)
 
 	_, err = repo.GetBookById(1)
 	assert.Error(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestGetBookIdByIsbn(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookRepositori(db)
+
+	// buku yang sudah dihapus juga ditemukan
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, deleted_at IS NOT NULL FROM mst_book WHERE isbn = $1")).
+		WithArgs("9780134190440").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "deleted"}).AddRow(4, true))
+
+	id, deleted, err := repo.GetBookIdByIsbn("9780134190440")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, id)
+	assert.True(t, deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUpdateBook(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -133,13 +251,18 @@ func TestUpdateBook(t *testing.T) {
 
 	repo := NewBookRepositori(db)
 
-	mock.ExpectExec(regexp.QuoteMeta("UPDATE mst_book SET title = $1, author = $2, release_year = $3, pages = $4 WHERE id = $5")).
-		WithArgs("Updated Book", "Updated Author", 2021, 200, 1).
-		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE mst_book SET title = $1, author = $2, release_year = $3, pages = $4, isbn = NULLIF($5, ''), isbn10 = NULLIF($6, ''), version = version + 1 WHERE id = $7 AND version = $8 AND deleted_at IS NULL RETURNING version")).
+		WithArgs("Updated Book", "Updated Author", 2021, 200, "", "", 1, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(4))
+	expectLinkBookAuthors(mock, 1, true)
+	mock.ExpectExec(regexp.QuoteMeta(insertBookRevision)).WithArgs(1, model.BookActionUpdate, 5).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 
-	updatedBook, err := repo.UpdateBook(&model.Book{Id: 1, Title: "Updated Book", Author: "Updated Author", ReleaseYear: 2021, Pages: 200})
+	updatedBook, err := repo.UpdateBook(&model.Book{Id: 1, Title: "Updated Book", Author: "Updated Author", ReleaseYear: 2021, Pages: 200, Version: 3}, 5)
 	assert.NoError(t, err)
 	assert.Equal(t, 1, updatedBook.Id)
+	assert.Equal(t, 4, updatedBook.Version)
 	assert.Equal(t, "Updated Book", updatedBook.Title)
 	assert.Equal(t, "Updated Author", updatedBook.Author)
 	assert.Equal(t, 2021, updatedBook.ReleaseYear)
@@ -154,12 +277,33 @@ func TestUpdateBook_Error(t *testing.T) {
 
 	repo := NewBookRepositori(db)
 
-	mock.ExpectExec(regexp.QuoteMeta("UPDATE mst_book SET title = $1, author = $2, release_year = $3, pages = $4 WHERE id = $5")).
-		WithArgs("Updated Book", "Updated Author", 2021, 200, 1).
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE mst_book SET title = $1, author = $2, release_year = $3, pages = $4, isbn = NULLIF($5, ''), isbn10 = NULLIF($6, ''), version = version + 1 WHERE id = $7 AND version = $8 AND deleted_at IS NULL RETURNING version")).
+		WithArgs("Updated Book", "Updated Author", 2021, 200, "", "", 1, 3).
 		WillReturnError(sqlmock.ErrCancelled)
+	mock.ExpectRollback()
 
-	_, err = repo.UpdateBook(&model.Book{Id: 1, Title: "Updated Book", Author: "Updated Author", ReleaseYear: 2021, Pages: 200})
+	_, err = repo.UpdateBook(&model.Book{Id: 1, Title: "Updated Book", Author: "Updated Author", ReleaseYear: 2021, Pages: 200, Version: 3}, 5)
 	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateBook_VersionMismatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookRepositori(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE mst_book SET")).
+		WithArgs("Updated Book", "Updated Author", 2021, 200, "", "", 1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}))
+	mock.ExpectRollback()
+
+	_, err = repo.UpdateBook(&model.Book{Id: 1, Title: "Updated Book", Author: "Updated Author", ReleaseYear: 2021, Pages: 200, Version: 2}, 5)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestDeleteBook(t *testing.T) {
@@ -169,10 +313,72 @@ func TestDeleteBook(t *testing.T) {
 
 	repo := NewBookRepositori(db)
 
-	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM mst_book WHERE id = $1")).WithArgs(1).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE mst_book SET deleted_at = NOW(), version = version + 1 WHERE id = $1 AND deleted_at IS NULL")).
+		WithArgs(1, model.CopyStatusOnLoan, model.CopyStatusOnHold).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE trx_hold SET status = $1 WHERE book_id = $2 AND status = $3")).
+		WithArgs(model.HoldStatusCancelled, 1, model.HoldStatusWaiting).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(insertBookRevision)).WithArgs(1, model.BookActionDelete, 5).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = repo.DeleteBook(1, 5)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
 
-	err = repo.DeleteBook(1)
+func TestDeleteBook_AlreadyDeleted(t *testing.T) {
+	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookRepositori(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE mst_book SET deleted_at = NOW()")).WithArgs(1, model.CopyStatusOnLoan, model.CopyStatusOnHold).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM mst_book_copy WHERE book_id = $1 AND status = $2)")).
+		WithArgs(1, model.CopyStatusOnLoan, model.CopyStatusOnHold).
+		WillReturnRows(sqlmock.NewRows([]string{"on_loan", "on_hold"}).AddRow(false, false))
+	mock.ExpectRollback()
+
+	err = repo.DeleteBook(1, 5)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteBook_CopiesInUse(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookRepositori(db)
+
+	// Sad Path: eksemplar masih dipinjam, buku tidak dihapus
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("AND NOT EXISTS (SELECT 1 FROM mst_book_copy WHERE book_id = $1 AND status IN ($2, $3))")).
+		WithArgs(1, model.CopyStatusOnLoan, model.CopyStatusOnHold).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS")).
+		WithArgs(1, model.CopyStatusOnLoan, model.CopyStatusOnHold).
+		WillReturnRows(sqlmock.NewRows([]string{"on_loan", "on_hold"}).AddRow(true, true))
+	mock.ExpectRollback()
+
+	err = repo.DeleteBook(1, 5)
+	assert.ErrorIs(t, err, ErrBookCopiesOnLoan)
+
+	// Sad Path: eksemplar menunggu diambil pemegang reservasi
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("AND NOT EXISTS")).
+		WithArgs(2, model.CopyStatusOnLoan, model.CopyStatusOnHold).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS")).
+		WithArgs(2, model.CopyStatusOnLoan, model.CopyStatusOnHold).
+		WillReturnRows(sqlmock.NewRows([]string{"on_loan", "on_hold"}).AddRow(false, true))
+	mock.ExpectRollback()
+
+	err = repo.DeleteBook(2, 5)
+	assert.ErrorIs(t, err, ErrBookCopiesOnHold)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -183,9 +389,291 @@ func TestDeleteBook_Error(t *testing.T) {
 
 	repo := NewBookRepositori(db)
 
-	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM mst_book WHERE id = $1")).WithArgs(1).WillReturnError(sqlmock.ErrCancelled)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE mst_book SET deleted_at = NOW()")).WithArgs(1, model.CopyStatusOnLoan, model.CopyStatusOnHold).WillReturnError(sqlmock.ErrCancelled)
+	mock.ExpectRollback()
+
+	err = repo.DeleteBook(1, 5)
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertBooks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookRepositori(db)
+
+	books := []model.Book{
+		{Isbn: "9780134190440", Isbn10: "0134190440", Title: "The Go Programming Language", Author: "Alan Donovan", ReleaseYear: 2015, Pages: 380},
+		{Title: "Untitled Notes", Author: "Anonymous", ReleaseYear: 2020, Pages: 50},
+	}
+
+	mock.ExpectBegin()
+	prepared := mock.ExpectPrepare(regexp.QuoteMeta("INSERT INTO mst_book(title, author, release_year, pages, isbn, isbn10) VALUES($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''))"))
+	revision := mock.ExpectPrepare(regexp.QuoteMeta(insertBookRevision))
+	mock.ExpectExec(regexp.QuoteMeta("SAVEPOINT book_row")).WillReturnResult(sqlmock.NewResult(0, 0))
+	prepared.ExpectQuery().
+		WithArgs("The Go Programming Language", "Alan Donovan", 2015, 380, "9780134190440", "0134190440").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created"}).AddRow(4, false))
+	expectLinkBookAuthors(mock, 4, true)
+	revision.ExpectExec().WithArgs(4, model.BookActionImport, 5).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("RELEASE SAVEPOINT book_row")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("SAVEPOINT book_row")).WillReturnResult(sqlmock.NewResult(0, 0))
+	prepared.ExpectQuery().
+		WithArgs("Untitled Notes", "Anonymous", 2020, 50, "", "").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created"}).AddRow(9, true))
+	expectLinkBookAuthors(mock, 9, true)
+	revision.ExpectExec().WithArgs(9, model.BookActionImport, 5).WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectExec(regexp.QuoteMeta("RELEASE SAVEPOINT book_row")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	results, err := repo.UpsertBooks(books, false, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.BookUpsertResult{{Id: 4, Created: false}, {Id: 9, Created: true}}, results)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertBooks_DryRun(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookRepositori(db)
+
+	mock.ExpectBegin()
+	prepared := mock.ExpectPrepare(regexp.QuoteMeta("INSERT INTO mst_book"))
+	revision := mock.ExpectPrepare(regexp.QuoteMeta("INSERT INTO book_revisions"))
+	mock.ExpectExec(regexp.QuoteMeta("SAVEPOINT book_row")).WillReturnResult(sqlmock.NewResult(0, 0))
+	prepared.ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"id", "created"}).AddRow(9, true))
+	expectLinkBookAuthors(mock, 9, true)
+	revision.ExpectExec().WithArgs(9, model.BookActionImport, 5).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("RELEASE SAVEPOINT book_row")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	results, err := repo.UpsertBooks([]model.Book{{Title: "Book", Author: "Author", ReleaseYear: 2020, Pages: 10}}, true, 5)
+	assert.NoError(t, err)
+	assert.True(t, results[0].Created)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertBooks_MatchById(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookRepositori(db)
+
+	// Happy Path: buku tanpa isbn dari file export diperbarui berdasarkan id,
+	// dan dibuat baru jika id tersebut tidak ada
+	mock.ExpectBegin()
+	prepared := mock.ExpectPrepare(regexp.QuoteMeta("INSERT INTO mst_book"))
+	revision := mock.ExpectPrepare(regexp.QuoteMeta("INSERT INTO book_revisions"))
+	mock.ExpectExec(regexp.QuoteMeta("SAVEPOINT book_row")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE mst_book SET title = $2, author = $3, release_year = $4, pages = $5, version = version + 1, deleted_at = NULL")).
+		WithArgs(9, "Untitled Notes", "Anonymous", 2020, 50).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+	expectLinkBookAuthors(mock, 9, true)
+	revision.ExpectExec().WithArgs(9, model.BookActionImport, 5).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("RELEASE SAVEPOINT book_row")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("SAVEPOINT book_row")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE mst_book SET")).
+		WithArgs(12, "Other Notes", "Anonymous", 2021, 60).
+		WillReturnError(sql.ErrNoRows)
+	prepared.ExpectQuery().
+		WithArgs("Other Notes", "Anonymous", 2021, 60, "", "").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created"}).AddRow(13, true))
+	expectLinkBookAuthors(mock, 13, true)
+	revision.ExpectExec().WithArgs(13, model.BookActionImport, 5).WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectExec(regexp.QuoteMeta("RELEASE SAVEPOINT book_row")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	results, err := repo.UpsertBooks([]model.Book{
+		{Id: 9, Title: "Untitled Notes", Author: "Anonymous", ReleaseYear: 2020, Pages: 50},
+		{Id: 12, Title: "Other Notes", Author: "Anonymous", ReleaseYear: 2021, Pages: 60},
+	}, false, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.BookUpsertResult{{Id: 9, Created: false}, {Id: 13, Created: true}}, results)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertBooks_RowError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookRepositori(db)
+
+	// Sad Path: baris pertama ditolak database, baris kedua tetap disimpan
+	mock.ExpectBegin()
+	prepared := mock.ExpectPrepare(regexp.QuoteMeta("INSERT INTO mst_book"))
+	revision := mock.ExpectPrepare(regexp.QuoteMeta("INSERT INTO book_revisions"))
+	mock.ExpectExec(regexp.QuoteMeta("SAVEPOINT book_row")).WillReturnResult(sqlmock.NewResult(0, 0))
+	prepared.ExpectQuery().WithArgs("Bad Book", "Author", 2020, 10, "", "").WillReturnError(sqlmock.ErrCancelled)
+	mock.ExpectExec(regexp.QuoteMeta("ROLLBACK TO SAVEPOINT book_row")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("SAVEPOINT book_row")).WillReturnResult(sqlmock.NewResult(0, 0))
+	prepared.ExpectQuery().WithArgs("Book", "Author", 2020, 10, "", "").WillReturnRows(sqlmock.NewRows([]string{"id", "created"}).AddRow(9, true))
+	expectLinkBookAuthors(mock, 9, true)
+	revision.ExpectExec().WithArgs(9, model.BookActionImport, 5).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("RELEASE SAVEPOINT book_row")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	results, err := repo.UpsertBooks([]model.Book{
+		{Title: "Bad Book", Author: "Author", ReleaseYear: 2020, Pages: 10},
+		{Title: "Book", Author: "Author", ReleaseYear: 2020, Pages: 10},
+	}, false, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.BookUpsertResult{{Error: sqlmock.ErrCancelled}, {Id: 9, Created: true}}, results)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertBooks_Error(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookRepositori(db)
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare(regexp.QuoteMeta("INSERT INTO mst_book"))
+	mock.ExpectPrepare(regexp.QuoteMeta("INSERT INTO book_revisions"))
+	mock.ExpectExec(regexp.QuoteMeta("SAVEPOINT book_row")).WillReturnError(sqlmock.ErrCancelled)
+	mock.ExpectRollback()
 
-	err = repo.DeleteBook(1)
+	_, err = repo.UpsertBooks([]model.Book{{Title: "Book", Author: "Author", ReleaseYear: 2020, Pages: 10}}, false, 5)
 	assert.Error(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestStreamBooks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookRepositori(db)
+
+	rows := sqlmock.NewRows([]string{"id", "title", "author", "release_year", "pages", "isbn", "isbn10", "version"}).
+		AddRow(1, "Book 1", "Author 1", 2020, 150, "9780134190440", "0134190440", 1).
+		AddRow(2, "Book 2", "Author 2", 2021, 200, "", "", 1)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, title, author, release_year, pages, COALESCE(isbn, ''), COALESCE(isbn10, ''), version FROM mst_book WHERE deleted_at IS NULL ORDER BY id")).WillReturnRows(rows)
+
+	titles := []string{}
+	err = repo.StreamBooks(func(book model.Book) error {
+		titles = append(titles, book.Title)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Book 1", "Book 2"}, titles)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStreamBooks_CallbackError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookRepositori(db)
+
+	rows := sqlmock.NewRows([]string{"id", "title", "author", "release_year", "pages", "isbn", "isbn10", "version"}).
+		AddRow(1, "Book 1", "Author 1", 2020, 150, "", "", 1).
+		AddRow(2, "Book 2", "Author 2", 2021, 200, "", "", 1)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM mst_book WHERE deleted_at IS NULL ORDER BY id")).WillReturnRows(rows)
+
+	calls := 0
+	err = repo.StreamBooks(func(book model.Book) error {
+		calls++
+		return sqlmock.ErrCancelled
+	})
+	assert.ErrorIs(t, err, sqlmock.ErrCancelled)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRestoreBook(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookRepositori(db)
+
+	rows := sqlmock.NewRows([]string{"id", "title", "author", "release_year", "pages", "isbn", "isbn10", "version"}).
+		AddRow(1, "Book 1", "Author 1", 2020, 150, "", "", 3)
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE mst_book SET deleted_at = NULL, version = version + 1 WHERE id = $1 AND deleted_at IS NOT NULL RETURNING id, title, author, release_year, pages, COALESCE(isbn, ''), COALESCE(isbn10, ''), version")).
+		WithArgs(1).
+		WillReturnRows(rows)
+	mock.ExpectExec(regexp.QuoteMeta(insertBookRevision)).WithArgs(1, model.BookActionRestore, 5).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	book, err := repo.RestoreBook(1, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, model.Book{Id: 1, Title: "Book 1", Author: "Author 1", ReleaseYear: 2020, Pages: 150, Version: 3}, book)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestoreBook_NotDeleted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookRepositori(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE mst_book SET deleted_at = NULL")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "author", "release_year", "pages", "isbn", "isbn10", "version"}))
+	mock.ExpectRollback()
+
+	_, err = repo.RestoreBook(1, 5)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetBookRevisions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookRepositori(db)
+
+	changedAt := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	deletedAt := changedAt.Add(time.Hour)
+	rows := sqlmock.NewRows([]string{"id", "book_id", "title", "author", "release_year", "pages", "isbn", "isbn10", "version", "deleted_at", "action", "changed_by", "username", "changed_at"}).
+		AddRow(1, 1, "Book 1", "Author 1", 2020, 150, "", "", 1, nil, "create", 0, "", changedAt).
+		AddRow(2, 1, "Book 1", "Author 1", 2020, 150, "", "", 2, deletedAt, "delete", 5, "librarian", deletedAt)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM book_revisions r LEFT JOIN mst_user u ON u.id = r.changed_by WHERE r.book_id = $1 ORDER BY r.version")).
+		WithArgs(1).
+		WillReturnRows(rows)
+
+	revisions, err := repo.GetBookRevisions(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.BookRevision{
+		{Id: 1, Book: model.Book{Id: 1, Title: "Book 1", Author: "Author 1", ReleaseYear: 2020, Pages: 150, Version: 1}, Action: model.BookActionCreate, ChangedAt: changedAt},
+		{Id: 2, Book: model.Book{Id: 1, Title: "Book 1", Author: "Author 1", ReleaseYear: 2020, Pages: 150, Version: 2}, DeletedAt: &deletedAt, Action: model.BookActionDelete, UserId: 5, Username: "librarian", ChangedAt: deletedAt},
+	}, revisions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAllBook_AuthorAndCategory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBookRepositori(db)
+
+	filter := model.BookFilter{AuthorId: 2, CategoryId: 5, Limit: 10}
+	where := " WHERE deleted_at IS NULL AND id IN (SELECT book_id FROM mst_book_author WHERE author_id = $1) AND id IN (SELECT book_id FROM mst_book_category WHERE category_id = $2)"
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM mst_book"+where)).
+		WithArgs(2, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, title, author, release_year, pages, COALESCE(isbn, ''), COALESCE(isbn10, ''), version FROM mst_book"+where+" ORDER BY id ASC, id ASC LIMIT $3 OFFSET $4")).
+		WithArgs(2, 5, 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "author", "release_year", "pages", "isbn", "isbn10", "version"}).AddRow(3, "Go", "Alan Donovan", 2015, 380, "", "", 1))
+
+	books, total, err := repo.GetAllBook(filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Len(t, books, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,124 @@
+package repositori
+
+import (
+	"database/sql"
+	"simple-clean-architecture/model"
+)
+
+const categoryColumns = "c.id, c.name, (SELECT COUNT(*) FROM mst_book_category x WHERE x.category_id = c.id)"
+
+type categoryRepositori struct {
+	db *sql.DB
+}
+
+type CategoryRepositori interface {
+	CreateCategory(category model.Category) (model.Category, error)
+	GetAllCategory() ([]model.Category, error)
+	GetCategoryById(id int) (model.Category, error)
+	GetCategoryByName(name string) (model.Category, error)
+	UpdateCategory(category model.Category) error
+	DeleteCategory(id int) error
+	GetCategoriesByBookId(bookId int) ([]model.Category, error)
+	SetBookCategories(bookId int, categoryIds []int) error
+}
+
+func (c *categoryRepositori) CreateCategory(category model.Category) (model.Category, error) {
+	err := c.db.QueryRow("INSERT INTO mst_category(name) VALUES($1) RETURNING id", category.Name).Scan(&category.Id)
+
+	if err != nil {
+		return model.Category{}, err
+	}
+
+	return category, nil
+}
+
+// GetAllCategory juga menjadi laporan jumlah buku per category lewat
+// BookCount.
+func (c *categoryRepositori) GetAllCategory() ([]model.Category, error) {
+	return c.queryCategories("SELECT " + categoryColumns + " FROM mst_category c ORDER BY c.name, c.id")
+}
+
+func (c *categoryRepositori) GetCategoryById(id int) (model.Category, error) {
+	return scanCategory(c.db.QueryRow("SELECT "+categoryColumns+" FROM mst_category c WHERE c.id = $1", id))
+}
+
+func (c *categoryRepositori) GetCategoryByName(name string) (model.Category, error) {
+	return scanCategory(c.db.QueryRow("SELECT "+categoryColumns+" FROM mst_category c WHERE LOWER(c.name) = LOWER($1)", name))
+}
+
+func (c *categoryRepositori) UpdateCategory(category model.Category) error {
+	_, err := c.db.Exec("UPDATE mst_category SET name = $1 WHERE id = $2", category.Name, category.Id)
+
+	return err
+}
+
+// DeleteCategory juga melepas category dari semua buku lewat ON DELETE
+// CASCADE.
+func (c *categoryRepositori) DeleteCategory(id int) error {
+	_, err := c.db.Exec("DELETE FROM mst_category WHERE id = $1", id)
+
+	return err
+}
+
+func (c *categoryRepositori) GetCategoriesByBookId(bookId int) ([]model.Category, error) {
+	return c.queryCategories("SELECT "+categoryColumns+" FROM mst_category c JOIN mst_book_category bc ON bc.category_id = c.id WHERE bc.book_id = $1 ORDER BY c.name, c.id", bookId)
+}
+
+// SetBookCategories mengganti semua category sebuah buku dalam satu transaksi.
+func (c *categoryRepositori) SetBookCategories(bookId int, categoryIds []int) error {
+	tx, err := c.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM mst_book_category WHERE book_id = $1", bookId); err != nil {
+		return err
+	}
+	for _, categoryId := range categoryIds {
+		_, err := tx.Exec("INSERT INTO mst_book_category(book_id, category_id) VALUES($1, $2)", bookId, categoryId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (c *categoryRepositori) queryCategories(query string, args ...interface{}) ([]model.Category, error) {
+	rows, err := c.db.Query(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []model.Category{}
+	for rows.Next() {
+		category, err := scanCategory(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		categories = append(categories, category)
+	}
+
+	return categories, rows.Err()
+}
+
+func scanCategory(row rowScanner) (model.Category, error) {
+	var category model.Category
+
+	err := row.Scan(&category.Id, &category.Name, &category.BookCount)
+
+	if err != nil {
+		return model.Category{}, err
+	}
+
+	return category, nil
+}
+
+func NewCategoryRepositori(db *sql.DB) CategoryRepositori {
+	return &categoryRepositori{db: db}
+}
@@ -0,0 +1,82 @@
+package repositori
+
+import (
+	"regexp"
+	"simple-clean-architecture/model"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+const categorySelect = "SELECT c.id, c.name, (SELECT COUNT(*) FROM mst_book_category x WHERE x.category_id = c.id) FROM mst_category c"
+
+func TestCreateCategory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryRepositori(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO mst_category(name) VALUES($1) RETURNING id")).
+		WithArgs("Programming").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+
+	category, err := repo.CreateCategory(model.Category{Name: "Programming"})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, category.Id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAllCategory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryRepositori(db)
+
+	rows := sqlmock.NewRows([]string{"id", "name", "book_count"}).
+		AddRow(2, "Fiction", 12).
+		AddRow(4, "Programming", 3)
+	mock.ExpectQuery(regexp.QuoteMeta(categorySelect + " ORDER BY c.name, c.id")).WillReturnRows(rows)
+
+	categories, err := repo.GetAllCategory()
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Category{{Id: 2, Name: "Fiction", BookCount: 12}, {Id: 4, Name: "Programming", BookCount: 3}}, categories)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetCategoryById_Fail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryRepositori(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(categorySelect + " WHERE c.id = $1")).WithArgs(9).WillReturnError(sqlmock.ErrCancelled)
+
+	_, err = repo.GetCategoryById(9)
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetBookCategories(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryRepositori(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM mst_book_category WHERE book_id = $1")).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO mst_book_category(book_id, category_id) VALUES($1, $2)")).
+		WithArgs(1, 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = repo.SetBookCategories(1, []int{4})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
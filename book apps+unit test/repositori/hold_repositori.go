@@ -0,0 +1,201 @@
+package repositori
+
+import (
+	"database/sql"
+	"errors"
+	"simple-clean-architecture/model"
+	"time"
+)
+
+var ErrHoldNotActive = errors.New("hold is no longer active")
+
+// holdColumns menghitung posisi antrian: jumlah hold waiting untuk buku yang
+// sama yang dibuat lebih dulu, termasuk hold itu sendiri.
+const holdColumns = `h.id, h.book_id, h.member_id, h.copy_id, h.status,
+	CASE WHEN h.status = 'waiting' THEN (SELECT COUNT(*) FROM trx_hold w WHERE w.book_id = h.book_id AND w.status = 'waiting' AND w.id <= h.id) ELSE 0 END,
+	h.created_at, h.ready_at, h.expires_at`
+
+type holdRepositori struct {
+	db *sql.DB
+}
+
+type HoldRepositori interface {
+	CreateHold(hold model.Hold) (model.Hold, error)
+	GetHoldById(id int) (model.Hold, error)
+	GetHoldsByMemberId(memberId int) ([]model.Hold, error)
+	GetActiveHold(memberId int, bookId int) (model.Hold, error)
+	CancelHold(hold model.Hold, now time.Time, expiresAt time.Time) error
+	ExpireHolds(now time.Time, expiresAt time.Time) (int, error)
+}
+
+func (h *holdRepositori) CreateHold(hold model.Hold) (model.Hold, error) {
+	err := h.db.QueryRow("INSERT INTO trx_hold(book_id, member_id, status) VALUES($1, $2, $3) RETURNING id, created_at",
+		hold.BookId, hold.MemberId, model.HoldStatusWaiting).Scan(&hold.Id, &hold.CreatedAt)
+
+	if err != nil {
+		return model.Hold{}, err
+	}
+
+	hold.Status = model.HoldStatusWaiting
+	return hold, nil
+}
+
+func (h *holdRepositori) GetHoldById(id int) (model.Hold, error) {
+	return scanHold(h.db.QueryRow("SELECT "+holdColumns+" FROM trx_hold h WHERE h.id = $1", id))
+}
+
+// GetHoldsByMemberId mengembalikan hold member yang masih aktif, yaitu yang
+// menunggu antrian atau siap diambil.
+func (h *holdRepositori) GetHoldsByMemberId(memberId int) ([]model.Hold, error) {
+	rows, err := h.db.Query("SELECT "+holdColumns+" FROM trx_hold h WHERE h.member_id = $1 AND h.status IN ('waiting', 'ready') ORDER BY h.id", memberId)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	holds := []model.Hold{}
+	for rows.Next() {
+		hold, err := scanHold(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		holds = append(holds, hold)
+	}
+
+	return holds, rows.Err()
+}
+
+func (h *holdRepositori) GetActiveHold(memberId int, bookId int) (model.Hold, error) {
+	return scanHold(h.db.QueryRow("SELECT "+holdColumns+" FROM trx_hold h WHERE h.member_id = $1 AND h.book_id = $2 AND h.status IN ('waiting', 'ready')", memberId, bookId))
+}
+
+// CancelHold membatalkan hold yang masih aktif. Jika hold sudah ready,
+// eksemplarnya diteruskan ke antrian berikutnya dengan batas ambil expiresAt.
+func (h *holdRepositori) CancelHold(hold model.Hold, now time.Time, expiresAt time.Time) error {
+	tx, err := h.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("UPDATE trx_hold SET status = $1 WHERE id = $2 AND status IN ('waiting', 'ready')", model.HoldStatusCancelled, hold.Id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrHoldNotActive
+	}
+
+	if hold.Status == model.HoldStatusReady && hold.CopyId != nil {
+		if err := assignCopyToNextHold(tx, *hold.CopyId, hold.BookId, now, expiresAt); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ExpireHolds menandai hold ready yang lewat batas ambil sebagai expired dan
+// meneruskan eksemplarnya ke antrian berikutnya. Jumlah hold yang kedaluwarsa
+// dikembalikan.
+func (h *holdRepositori) ExpireHolds(now time.Time, expiresAt time.Time) (int, error) {
+	tx, err := h.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT id, book_id, copy_id FROM trx_hold WHERE status = $1 AND expires_at < $2 ORDER BY id FOR UPDATE", model.HoldStatusReady, now)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := []model.Hold{}
+	for rows.Next() {
+		var hold model.Hold
+		var copyId int
+		if err := rows.Scan(&hold.Id, &hold.BookId, &copyId); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		hold.CopyId = &copyId
+		expired = append(expired, hold)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, hold := range expired {
+		if _, err := tx.Exec("UPDATE trx_hold SET status = $1 WHERE id = $2", model.HoldStatusExpired, hold.Id); err != nil {
+			return 0, err
+		}
+		if err := assignCopyToNextHold(tx, *hold.CopyId, hold.BookId, now, expiresAt); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(expired), tx.Commit()
+}
+
+// assignCopyToNextHold memberikan eksemplar yang baru kembali kepada hold
+// waiting terdepan untuk buku tersebut. Jika antrian kosong, eksemplar menjadi
+// tersedia. Hold yang sedang dikunci transaksi lain (dibatalkan atau menerima
+// eksemplar lain) dilewati, agar eksemplar tidak menjadi available selama
+// masih ada hold waiting di belakangnya.
+func assignCopyToNextHold(tx *sql.Tx, copyId int, bookId int, readyAt time.Time, expiresAt time.Time) error {
+	var holdId int
+	err := tx.QueryRow("SELECT id FROM trx_hold WHERE book_id = $1 AND status = $2 ORDER BY id LIMIT 1 FOR UPDATE SKIP LOCKED", bookId, model.HoldStatusWaiting).Scan(&holdId)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		_, err = tx.Exec("UPDATE mst_book_copy SET status = $1 WHERE id = $2", model.CopyStatusAvailable, copyId)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE trx_hold SET status = $1, copy_id = $2, ready_at = $3, expires_at = $4 WHERE id = $5", model.HoldStatusReady, copyId, readyAt, expiresAt, holdId)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE mst_book_copy SET status = $1 WHERE id = $2", model.CopyStatusOnHold, copyId)
+	return err
+}
+
+func scanHold(row rowScanner) (model.Hold, error) {
+	var hold model.Hold
+	var copyId sql.NullInt64
+	var readyAt, expiresAt sql.NullTime
+
+	err := row.Scan(&hold.Id, &hold.BookId, &hold.MemberId, &copyId, &hold.Status, &hold.Position, &hold.CreatedAt, &readyAt, &expiresAt)
+
+	if err != nil {
+		return model.Hold{}, err
+	}
+	if copyId.Valid {
+		id := int(copyId.Int64)
+		hold.CopyId = &id
+	}
+	if readyAt.Valid {
+		hold.ReadyAt = &readyAt.Time
+	}
+	if expiresAt.Valid {
+		hold.ExpiresAt = &expiresAt.Time
+	}
+
+	return hold, nil
+}
+
+func NewHoldRepositori(db *sql.DB) HoldRepositori {
+	return &holdRepositori{db: db}
+}
@@ -0,0 +1,148 @@
+package repositori
+
+import (
+	"database/sql"
+	"regexp"
+	"simple-clean-architecture/model"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var holdRowColumns = []string{"id", "book_id", "member_id", "copy_id", "status", "position", "created_at", "ready_at", "expires_at"}
+
+func TestCreateHold(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewHoldRepositori(db)
+
+	createdAt := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO trx_hold(book_id, member_id, status) VALUES($1, $2, $3) RETURNING id, created_at")).
+		WithArgs(1, 2, model.HoldStatusWaiting).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(8, createdAt))
+
+	hold, err := repo.CreateHold(model.Hold{BookId: 1, MemberId: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, model.Hold{Id: 8, BookId: 1, MemberId: 2, Status: model.HoldStatusWaiting, CreatedAt: createdAt}, hold)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetHoldsByMemberId(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewHoldRepositori(db)
+
+	now := time.Now()
+	expiresAt := now.Add(72 * time.Hour)
+	rows := sqlmock.NewRows(holdRowColumns).
+		AddRow(8, 1, 2, nil, model.HoldStatusWaiting, 3, now, nil, nil).
+		AddRow(9, 4, 2, 6, model.HoldStatusReady, 0, now, now, expiresAt)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM trx_hold h WHERE h.member_id = $1 AND h.status IN ('waiting', 'ready') ORDER BY h.id")).
+		WithArgs(2).
+		WillReturnRows(rows)
+
+	holds, err := repo.GetHoldsByMemberId(2)
+	assert.NoError(t, err)
+	assert.Len(t, holds, 2)
+	assert.Equal(t, 3, holds[0].Position)
+	assert.Nil(t, holds[0].CopyId)
+	assert.Equal(t, 6, *holds[1].CopyId)
+	assert.Equal(t, expiresAt, *holds[1].ExpiresAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetActiveHold_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewHoldRepositori(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM trx_hold h WHERE h.member_id = $1 AND h.book_id = $2")).
+		WithArgs(2, 1).
+		WillReturnRows(sqlmock.NewRows(holdRowColumns))
+
+	_, err = repo.GetActiveHold(2, 1)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestCancelHold_Ready(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewHoldRepositori(db)
+
+	now := time.Now()
+	copyId := 6
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE trx_hold SET status = $1 WHERE id = $2 AND status IN ('waiting', 'ready')")).
+		WithArgs(model.HoldStatusCancelled, 9).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM trx_hold WHERE book_id = $1 AND status = $2")).
+		WithArgs(4, model.HoldStatusWaiting).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE mst_book_copy SET status = $1 WHERE id = $2")).
+		WithArgs(model.CopyStatusAvailable, 6).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = repo.CancelHold(model.Hold{Id: 9, BookId: 4, CopyId: &copyId, Status: model.HoldStatusReady}, now, now.Add(72*time.Hour))
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCancelHold_NotActive(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewHoldRepositori(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE trx_hold SET status = $1")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = repo.CancelHold(model.Hold{Id: 9, Status: model.HoldStatusWaiting}, time.Now(), time.Now())
+	assert.ErrorIs(t, err, ErrHoldNotActive)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExpireHolds(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewHoldRepositori(db)
+
+	now := time.Now()
+	expiresAt := now.Add(72 * time.Hour)
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, book_id, copy_id FROM trx_hold WHERE status = $1 AND expires_at < $2 ORDER BY id FOR UPDATE")).
+		WithArgs(model.HoldStatusReady, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "book_id", "copy_id"}).AddRow(9, 4, 6))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE trx_hold SET status = $1 WHERE id = $2")).
+		WithArgs(model.HoldStatusExpired, 9).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM trx_hold WHERE book_id = $1 AND status = $2")).
+		WithArgs(4, model.HoldStatusWaiting).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE trx_hold SET status = $1, copy_id = $2, ready_at = $3, expires_at = $4 WHERE id = $5")).
+		WithArgs(model.HoldStatusReady, 6, now, expiresAt, 10).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE mst_book_copy SET status = $1 WHERE id = $2")).
+		WithArgs(model.CopyStatusOnHold, 6).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	expired, err := repo.ExpireHolds(now, expiresAt)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, expired)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
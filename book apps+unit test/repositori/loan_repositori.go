@@ -0,0 +1,204 @@
+package repositori
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"simple-clean-architecture/model"
+	"time"
+)
+
+var (
+	ErrCopyNotAvailable = errors.New("book copy is not available")
+	ErrLoanNotActive    = errors.New("loan has already been returned")
+)
+
+const loanColumns = "id, book_id, copy_id, member_id, borrowed_at, due_at, returned_at, fine, fine_paid"
+
+type loanRepositori struct {
+	db *sql.DB
+}
+
+type LoanRepositori interface {
+	CreateLoan(loan model.Loan, holdId int) (model.Loan, error)
+	GetAllLoan(filter model.LoanFilter, now time.Time) ([]model.Loan, error)
+	GetLoanById(id int) (model.Loan, error)
+	GetMemberStanding(memberId int, now time.Time) (overdue int, unpaidFines int, err error)
+	ReturnLoan(loan model.Loan, holdExpiresAt time.Time) error
+	PayFine(id int) error
+}
+
+// CreateLoan menandai eksemplar sebagai dipinjam lalu menyimpan loan dalam satu
+// transaksi. Jika holdId diisi, eksemplar diambil dari hold ready tersebut dan
+// hold ditandai fulfilled. Jika eksemplar sedang tidak tersedia,
+// ErrCopyNotAvailable dikembalikan.
+func (l *loanRepositori) CreateLoan(loan model.Loan, holdId int) (model.Loan, error) {
+	tx, err := l.db.Begin()
+	if err != nil {
+		return model.Loan{}, err
+	}
+	defer tx.Rollback()
+
+	fromStatus := model.CopyStatusAvailable
+	if holdId != 0 {
+		fromStatus = model.CopyStatusOnHold
+	}
+
+	result, err := tx.Exec("UPDATE mst_book_copy SET status = $1 WHERE id = $2 AND status = $3", model.CopyStatusOnLoan, loan.CopyId, fromStatus)
+	if err != nil {
+		return model.Loan{}, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return model.Loan{}, err
+	}
+	if affected == 0 {
+		return model.Loan{}, ErrCopyNotAvailable
+	}
+
+	if holdId != 0 {
+		result, err := tx.Exec("UPDATE trx_hold SET status = $1 WHERE id = $2 AND status = $3", model.HoldStatusFulfilled, holdId, model.HoldStatusReady)
+		if err != nil {
+			return model.Loan{}, err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return model.Loan{}, err
+		}
+		if affected == 0 {
+			return model.Loan{}, ErrHoldNotActive
+		}
+	}
+
+	err = tx.QueryRow("INSERT INTO trx_loan(book_id, copy_id, member_id, borrowed_at, due_at) VALUES($1, $2, $3, $4, $5) RETURNING id",
+		loan.BookId, loan.CopyId, loan.MemberId, loan.BorrowedAt, loan.DueAt).Scan(&loan.Id)
+	if err != nil {
+		return model.Loan{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return model.Loan{}, err
+	}
+
+	return loan, nil
+}
+
+func (l *loanRepositori) GetAllLoan(filter model.LoanFilter, now time.Time) ([]model.Loan, error) {
+	conditions := []string{}
+	args := []interface{}{}
+	if filter.MemberId != 0 {
+		args = append(args, filter.MemberId)
+		conditions = append(conditions, "member_id = $1")
+	}
+	switch filter.Status {
+	case "active":
+		conditions = append(conditions, "returned_at IS NULL")
+	case "overdue":
+		args = append(args, now)
+		conditions = append(conditions, fmt.Sprintf("returned_at IS NULL AND due_at < $%d", len(args)))
+	case "returned":
+		conditions = append(conditions, "returned_at IS NOT NULL")
+	}
+
+	rows, err := l.db.Query("SELECT "+loanColumns+" FROM trx_loan"+whereClause(conditions)+" ORDER BY id DESC", args...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	loans := []model.Loan{}
+	for rows.Next() {
+		loan, err := scanLoan(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		loans = append(loans, loan)
+	}
+
+	return loans, rows.Err()
+}
+
+func (l *loanRepositori) GetLoanById(id int) (model.Loan, error) {
+	return scanLoan(l.db.QueryRow("SELECT "+loanColumns+" FROM trx_loan WHERE id = $1", id))
+}
+
+// GetMemberStanding menghitung jumlah pinjaman member yang sudah lewat jatuh
+// tempo dan total denda yang belum dibayar.
+func (l *loanRepositori) GetMemberStanding(memberId int, now time.Time) (int, int, error) {
+	var overdue, unpaidFines int
+
+	err := l.db.QueryRow(`SELECT COUNT(*) FILTER (WHERE returned_at IS NULL AND due_at < $2), COALESCE(SUM(fine) FILTER (WHERE NOT fine_paid), 0)
+		FROM trx_loan WHERE member_id = $1`, memberId, now).Scan(&overdue, &unpaidFines)
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return overdue, unpaidFines, nil
+}
+
+// ReturnLoan menyimpan waktu pengembalian dan denda, lalu memberikan eksemplar
+// kepada antrian hold berikutnya dengan batas ambil holdExpiresAt, atau
+// menjadikannya tersedia. ErrLoanNotActive dikembalikan jika loan sudah
+// dikembalikan sebelumnya.
+func (l *loanRepositori) ReturnLoan(loan model.Loan, holdExpiresAt time.Time) error {
+	tx, err := l.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("UPDATE trx_loan SET returned_at = $1, fine = $2, fine_paid = $3 WHERE id = $4 AND returned_at IS NULL", loan.ReturnedAt, loan.Fine, loan.FinePaid, loan.Id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrLoanNotActive
+	}
+
+	if err := assignCopyToNextHold(tx, loan.CopyId, loan.BookId, *loan.ReturnedAt, holdExpiresAt); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (l *loanRepositori) PayFine(id int) error {
+	_, err := l.db.Exec("UPDATE trx_loan SET fine_paid = TRUE WHERE id = $1", id)
+
+	return err
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanLoan(row rowScanner) (model.Loan, error) {
+	var loan model.Loan
+	var returnedAt sql.NullTime
+
+	err := row.Scan(&loan.Id, &loan.BookId, &loan.CopyId, &loan.MemberId, &loan.BorrowedAt, &loan.DueAt, &returnedAt, &loan.Fine, &loan.FinePaid)
+
+	if err != nil {
+		return model.Loan{}, err
+	}
+	if returnedAt.Valid {
+		loan.ReturnedAt = &returnedAt.Time
+	}
+
+	return loan, nil
+}
+
+func NewLoanRepositori(db *sql.DB) LoanRepositori {
+	return &loanRepositori{db: db}
+}
@@ -0,0 +1,239 @@
+package repositori
+
+import (
+	"database/sql"
+	"regexp"
+	"simple-clean-architecture/model"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var loanRowColumns = []string{"id", "book_id", "copy_id", "member_id", "borrowed_at", "due_at", "returned_at", "fine", "fine_paid"}
+
+func TestCreateLoan(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewLoanRepositori(db)
+
+	borrowedAt := time.Now()
+	loan := model.Loan{BookId: 1, CopyId: 4, MemberId: 2, BorrowedAt: borrowedAt, DueAt: borrowedAt.Add(14 * 24 * time.Hour)}
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE mst_book_copy SET status = $1 WHERE id = $2 AND status = $3")).
+		WithArgs(model.CopyStatusOnLoan, 4, model.CopyStatusAvailable).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO trx_loan(book_id, copy_id, member_id, borrowed_at, due_at)")).
+		WithArgs(1, 4, 2, loan.BorrowedAt, loan.DueAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+	mock.ExpectCommit()
+
+	created, err := repo.CreateLoan(loan, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, created.Id)
+	assert.Equal(t, loan.DueAt, created.DueAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateLoan_FromHold(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewLoanRepositori(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE mst_book_copy SET status = $1 WHERE id = $2 AND status = $3")).
+		WithArgs(model.CopyStatusOnLoan, 4, model.CopyStatusOnHold).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE trx_hold SET status = $1 WHERE id = $2 AND status = $3")).
+		WithArgs(model.HoldStatusFulfilled, 8, model.HoldStatusReady).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO trx_loan")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+	mock.ExpectCommit()
+
+	created, err := repo.CreateLoan(model.Loan{BookId: 1, CopyId: 4, MemberId: 2}, 8)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, created.Id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateLoan_CopyNotAvailable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewLoanRepositori(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE mst_book_copy SET status = $1")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	_, err = repo.CreateLoan(model.Loan{BookId: 1, CopyId: 4, MemberId: 2}, 0)
+	assert.ErrorIs(t, err, ErrCopyNotAvailable)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAllLoan(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewLoanRepositori(db)
+
+	now := time.Now()
+	returnedAt := now.Add(-time.Hour)
+	rows := sqlmock.NewRows(loanRowColumns).
+		AddRow(2, 1, 4, 3, now, now, nil, 0, false).
+		AddRow(1, 1, 4, 3, now, now, returnedAt, 2000, false)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, book_id, copy_id, member_id, borrowed_at, due_at, returned_at, fine, fine_paid FROM trx_loan WHERE member_id = $1 ORDER BY id DESC")).
+		WithArgs(3).
+		WillReturnRows(rows)
+
+	loans, err := repo.GetAllLoan(model.LoanFilter{MemberId: 3}, now)
+	assert.NoError(t, err)
+	assert.Len(t, loans, 2)
+	assert.Nil(t, loans[0].ReturnedAt)
+	assert.Equal(t, 4, loans[0].CopyId)
+	assert.Equal(t, returnedAt, *loans[1].ReturnedAt)
+	assert.Equal(t, 2000, loans[1].Fine)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAllLoan_Overdue(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewLoanRepositori(db)
+
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM trx_loan WHERE member_id = $1 AND returned_at IS NULL AND due_at < $2 ORDER BY id DESC")).
+		WithArgs(3, now).
+		WillReturnRows(sqlmock.NewRows(loanRowColumns))
+
+	loans, err := repo.GetAllLoan(model.LoanFilter{MemberId: 3, Status: "overdue"}, now)
+	assert.NoError(t, err)
+	assert.Empty(t, loans)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetLoanById_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewLoanRepositori(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM trx_loan WHERE id = $1")).WithArgs(9).WillReturnRows(sqlmock.NewRows(loanRowColumns))
+
+	_, err = repo.GetLoanById(9)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestGetMemberStanding(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewLoanRepositori(db)
+
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM trx_loan WHERE member_id = $1")).
+		WithArgs(3, now).
+		WillReturnRows(sqlmock.NewRows([]string{"overdue", "unpaid_fines"}).AddRow(1, 3000))
+
+	overdue, unpaidFines, err := repo.GetMemberStanding(3, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, overdue)
+	assert.Equal(t, 3000, unpaidFines)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReturnLoan(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewLoanRepositori(db)
+
+	returnedAt := time.Now()
+	loan := model.Loan{Id: 5, BookId: 1, CopyId: 4, ReturnedAt: &returnedAt, Fine: 2000}
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE trx_loan SET returned_at = $1, fine = $2, fine_paid = $3 WHERE id = $4 AND returned_at IS NULL")).
+		WithArgs(&returnedAt, 2000, false, 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM trx_hold WHERE book_id = $1 AND status = $2 ORDER BY id LIMIT 1 FOR UPDATE SKIP LOCKED")).
+		WithArgs(1, model.HoldStatusWaiting).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE mst_book_copy SET status = $1 WHERE id = $2")).
+		WithArgs(model.CopyStatusAvailable, 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = repo.ReturnLoan(loan, returnedAt.Add(72*time.Hour))
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReturnLoan_AssignsNextHold(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewLoanRepositori(db)
+
+	returnedAt := time.Now()
+	expiresAt := returnedAt.Add(72 * time.Hour)
+	loan := model.Loan{Id: 5, BookId: 1, CopyId: 4, ReturnedAt: &returnedAt}
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE trx_loan SET returned_at")).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM trx_hold WHERE book_id = $1 AND status = $2")).
+		WithArgs(1, model.HoldStatusWaiting).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(8))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE trx_hold SET status = $1, copy_id = $2, ready_at = $3, expires_at = $4 WHERE id = $5")).
+		WithArgs(model.HoldStatusReady, 4, returnedAt, expiresAt, 8).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE mst_book_copy SET status = $1 WHERE id = $2")).
+		WithArgs(model.CopyStatusOnHold, 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = repo.ReturnLoan(loan, expiresAt)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReturnLoan_AlreadyReturned(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewLoanRepositori(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE trx_loan SET returned_at")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = repo.ReturnLoan(model.Loan{Id: 5}, time.Now())
+	assert.ErrorIs(t, err, ErrLoanNotActive)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPayFine(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewLoanRepositori(db)
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE trx_loan SET fine_paid = TRUE WHERE id = $1")).WithArgs(5).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.PayFine(5)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,66 @@
+package repositori
+
+import (
+	"database/sql"
+	"simple-clean-architecture/model"
+)
+
+type memberRepositori struct {
+	db *sql.DB
+}
+
+type MemberRepositori interface {
+	CreateNewMember(member model.Member) (model.Member, error)
+	GetAllMember() ([]model.Member, error)
+	GetMemberById(id int) (model.Member, error)
+}
+
+func (m *memberRepositori) CreateNewMember(member model.Member) (model.Member, error) {
+	err := m.db.QueryRow("INSERT INTO mst_member(name, email) VALUES($1, $2) RETURNING id, joined_at", member.Name, member.Email).Scan(&member.Id, &member.JoinedAt)
+
+	if err != nil {
+		return model.Member{}, err
+	}
+
+	return member, nil
+}
+
+func (m *memberRepositori) GetAllMember() ([]model.Member, error) {
+	rows, err := m.db.Query("SELECT id, name, email, joined_at FROM mst_member ORDER BY id")
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []model.Member{}
+	for rows.Next() {
+		var member model.Member
+
+		err := rows.Scan(&member.Id, &member.Name, &member.Email, &member.JoinedAt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+func (m *memberRepositori) GetMemberById(id int) (model.Member, error) {
+	var member model.Member
+
+	err := m.db.QueryRow("SELECT id, name, email, joined_at FROM mst_member WHERE id = $1", id).Scan(&member.Id, &member.Name, &member.Email, &member.JoinedAt)
+
+	if err != nil {
+		return model.Member{}, err
+	}
+
+	return member, nil
+}
+
+func NewMemberRepositori(db *sql.DB) MemberRepositori {
+	return &memberRepositori{db: db}
+}
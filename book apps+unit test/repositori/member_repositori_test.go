@@ -0,0 +1,94 @@
+package repositori
+
+import (
+	"regexp"
+	"simple-clean-architecture/model"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateNewMember(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMemberRepositori(db)
+
+	joinedAt := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO mst_member(name, email) VALUES($1, $2) RETURNING id, joined_at")).
+		WithArgs("Budi", "budi@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "joined_at"}).AddRow(1, joinedAt))
+
+	member, err := repo.CreateNewMember(model.Member{Name: "Budi", Email: "budi@example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, model.Member{Id: 1, Name: "Budi", Email: "budi@example.com", JoinedAt: joinedAt}, member)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateNewMember_Error(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMemberRepositori(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO mst_member")).WillReturnError(sqlmock.ErrCancelled)
+
+	_, err = repo.CreateNewMember(model.Member{Name: "Budi", Email: "budi@example.com"})
+	assert.Error(t, err)
+}
+
+func TestGetAllMember(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMemberRepositori(db)
+
+	joinedAt := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "name", "email", "joined_at"}).
+		AddRow(1, "Budi", "budi@example.com", joinedAt).
+		AddRow(2, "Sari", "sari@example.com", joinedAt)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, name, email, joined_at FROM mst_member ORDER BY id")).WillReturnRows(rows)
+
+	members, err := repo.GetAllMember()
+	assert.NoError(t, err)
+	assert.Len(t, members, 2)
+	assert.Equal(t, "Sari", members[1].Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetMemberById(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMemberRepositori(db)
+
+	joinedAt := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, name, email, joined_at FROM mst_member WHERE id = $1")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "joined_at"}).AddRow(1, "Budi", "budi@example.com", joinedAt))
+
+	member, err := repo.GetMemberById(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Budi", member.Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetMemberById_Error(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMemberRepositori(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, name, email, joined_at FROM mst_member WHERE id = $1")).WithArgs(1).WillReturnError(sqlmock.ErrCancelled)
+
+	_, err = repo.GetMemberById(1)
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
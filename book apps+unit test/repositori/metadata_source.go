@@ -0,0 +1,160 @@
+package repositori
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"regexp"
+	"simple-clean-architecture/model"
+	"strconv"
+	"strings"
+)
+
+var ErrMetadataNotFound = errors.New("metadata not found")
+
+// MetadataSource mencari data buku berdasarkan ISBN tanpa tanda hubung, baik
+// ISBN-10 maupun ISBN-13. Jika ISBN tidak dikenal, ErrMetadataNotFound
+// dikembalikan.
+type MetadataSource interface {
+	LookupIsbn(isbn string) (model.BookMetadata, error)
+}
+
+// maxDumpLine adalah panjang maksimal satu baris dump, beberapa record edition
+// Open Library berukuran ratusan KB.
+const maxDumpLine = 16 * 1024 * 1024
+
+var publishYearPattern = regexp.MustCompile(`\b\d{4}\b`)
+
+type openLibrarySource struct {
+	books map[string]model.BookMetadata
+}
+
+type openLibraryEdition struct {
+	Title         string   `json:"title"`
+	NumberOfPages int      `json:"number_of_pages"`
+	PublishDate   string   `json:"publish_date"`
+	ByStatement   string   `json:"by_statement"`
+	Isbn10        []string `json:"isbn_10"`
+	Isbn13        []string `json:"isbn_13"`
+	Authors       []struct {
+		Key string `json:"key"`
+	} `json:"authors"`
+}
+
+func (o *openLibrarySource) LookupIsbn(isbn string) (model.BookMetadata, error) {
+	book, ok := o.books[cleanIsbn(isbn)]
+	if !ok {
+		return model.BookMetadata{}, ErrMetadataNotFound
+	}
+	return book, nil
+}
+
+// loadOpenLibraryDump membaca dump Open Library berformat TSV
+// (type, key, revision, last_modified, json) dan mengindeks setiap edition
+// berdasarkan semua ISBN-nya. Nama penulis diambil dari record /type/author di
+// dump yang sama, jika tidak ada dipakai by_statement dari edition.
+func loadOpenLibraryDump(r io.Reader) (*openLibrarySource, error) {
+	source := &openLibrarySource{books: map[string]model.BookMetadata{}}
+	authorNames := map[string]string{}
+	editionAuthors := map[string][]string{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxDumpLine)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), "\t", 5)
+		if len(fields) != 5 {
+			continue
+		}
+
+		switch fields[0] {
+		case "/type/author":
+			var author struct {
+				Name string `json:"name"`
+			}
+			// record yang rusak dilewati, dump Open Library tidak selalu bersih
+			if err := json.Unmarshal([]byte(fields[4]), &author); err != nil || author.Name == "" {
+				continue
+			}
+			authorNames[fields[1]] = strings.TrimSpace(author.Name)
+		case "/type/edition":
+			var edition openLibraryEdition
+			if err := json.Unmarshal([]byte(fields[4]), &edition); err != nil {
+				continue
+			}
+			if len(edition.Isbn10) == 0 && len(edition.Isbn13) == 0 {
+				continue
+			}
+
+			book := model.BookMetadata{
+				Title:  strings.TrimSpace(edition.Title),
+				Author: strings.TrimSuffix(strings.TrimSpace(edition.ByStatement), "."),
+				Pages:  edition.NumberOfPages,
+			}
+			if year := publishYearPattern.FindString(edition.PublishDate); year != "" {
+				book.ReleaseYear, _ = strconv.Atoi(year)
+			}
+
+			keys := []string{}
+			for _, author := range edition.Authors {
+				keys = append(keys, author.Key)
+			}
+			for _, isbn := range append(edition.Isbn13, edition.Isbn10...) {
+				if isbn = cleanIsbn(isbn); isbn != "" {
+					source.books[isbn] = book
+					editionAuthors[isbn] = keys
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// author bisa muncul setelah edition di dump, sehingga nama baru diisi di akhir
+	for isbn, keys := range editionAuthors {
+		names := []string{}
+		for _, key := range keys {
+			if name, ok := authorNames[key]; ok {
+				names = append(names, name)
+			}
+		}
+		if len(names) > 0 {
+			book := source.books[isbn]
+			book.Author = strings.Join(names, ", ")
+			source.books[isbn] = book
+		}
+	}
+
+	return source, nil
+}
+
+func cleanIsbn(isbn string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(isbn))
+}
+
+// NewOpenLibrarySource memuat dump Open Library dari file lokal, boleh
+// terkompresi gzip (.gz), sehingga pencarian metadata tidak butuh akses
+// jaringan. Seluruh edition yang punya ISBN disimpan di memori, jadi gunakan
+// dump yang sudah disaring sesuai koleksi perpustakaan.
+func NewOpenLibrarySource(path string) (MetadataSource, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	return loadOpenLibraryDump(r)
+}
@@ -0,0 +1,67 @@
+package repositori
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"simple-clean-architecture/model"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const openLibraryDump = "/type/edition\t/books/OL1M\t3\t2010-01-01T00:00:00\t" +
+	`{"title": "The Go Programming Language", "number_of_pages": 380, "publish_date": "Oct 26, 2015", "authors": [{"key": "/authors/OL1A"}, {"key": "/authors/OL2A"}], "isbn_10": ["0134190440"], "isbn_13": ["978-0-13-419044-0"]}` + "\n" +
+	"/type/edition\t/books/OL2M\t1\t2010-01-01T00:00:00\t" +
+	`{"title": "Old Notes", "publish_date": "1999", "by_statement": "Someone.", "isbn_10": ["080442957x"]}` + "\n" +
+	"/type/edition\t/books/OL3M\t1\t2010-01-01T00:00:00\t" +
+	`{"title": "No Isbn"}` + "\n" +
+	"/type/edition\t/books/OL4M\t1\t2010-01-01T00:00:00\t{broken\n" +
+	"/type/author\t/authors/OL1A\t1\t2010-01-01T00:00:00\t" + `{"name": "Alan Donovan"}` + "\n" +
+	"/type/author\t/authors/OL2A\t1\t2010-01-01T00:00:00\t" + `{"name": "Brian Kernighan"}` + "\n"
+
+func TestLoadOpenLibraryDump(t *testing.T) {
+	source, err := loadOpenLibraryDump(strings.NewReader(openLibraryDump))
+	assert.NoError(t, err)
+
+	expected := model.BookMetadata{Title: "The Go Programming Language", Author: "Alan Donovan, Brian Kernighan", ReleaseYear: 2015, Pages: 380}
+	book, err := source.LookupIsbn("9780134190440")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, book)
+
+	book, err = source.LookupIsbn("0134190440")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, book)
+
+	// author tidak ada di dump, by_statement yang dipakai
+	book, err = source.LookupIsbn("080442957X")
+	assert.NoError(t, err)
+	assert.Equal(t, model.BookMetadata{Title: "Old Notes", Author: "Someone", ReleaseYear: 1999}, book)
+
+	_, err = source.LookupIsbn("9791000000008")
+	assert.ErrorIs(t, err, ErrMetadataNotFound)
+	assert.Len(t, source.books, 3)
+}
+
+func TestNewOpenLibrarySource_Gzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "editions.txt.gz")
+	file, err := os.Create(path)
+	assert.NoError(t, err)
+	gz := gzip.NewWriter(file)
+	_, err = gz.Write([]byte(openLibraryDump))
+	assert.NoError(t, err)
+	assert.NoError(t, gz.Close())
+	assert.NoError(t, file.Close())
+
+	source, err := NewOpenLibrarySource(path)
+	assert.NoError(t, err)
+
+	book, err := source.LookupIsbn("978-0-13-419044-0")
+	assert.NoError(t, err)
+	assert.Equal(t, "The Go Programming Language", book.Title)
+
+	// Sad Path: file tidak ada
+	_, err = NewOpenLibrarySource(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+}
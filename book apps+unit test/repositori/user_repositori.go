@@ -0,0 +1,59 @@
+package repositori
+
+import (
+	"database/sql"
+	"simple-clean-architecture/model"
+)
+
+const userColumns = "id, username, password_hash, role, created_at"
+
+type userRepositori struct {
+	db *sql.DB
+}
+
+type UserRepositori interface {
+	CreateUser(user model.User) (model.User, error)
+	GetUserById(id int) (model.User, error)
+	GetUserByUsername(username string) (model.User, error)
+	UpdateUserRole(id int, role string) (model.User, error)
+}
+
+func (u *userRepositori) CreateUser(user model.User) (model.User, error) {
+	err := u.db.QueryRow("INSERT INTO mst_user(username, password_hash, role) VALUES($1, $2, $3) RETURNING id, created_at", user.Username, user.PasswordHash, user.Role).Scan(&user.Id, &user.CreatedAt)
+
+	if err != nil {
+		return model.User{}, err
+	}
+
+	return user, nil
+}
+
+func (u *userRepositori) GetUserById(id int) (model.User, error) {
+	return scanUser(u.db.QueryRow("SELECT "+userColumns+" FROM mst_user WHERE id = $1", id))
+}
+
+// GetUserByUsername tidak membedakan huruf besar dan kecil, sama seperti
+// index unik username.
+func (u *userRepositori) GetUserByUsername(username string) (model.User, error) {
+	return scanUser(u.db.QueryRow("SELECT "+userColumns+" FROM mst_user WHERE LOWER(username) = LOWER($1)", username))
+}
+
+func (u *userRepositori) UpdateUserRole(id int, role string) (model.User, error) {
+	return scanUser(u.db.QueryRow("UPDATE mst_user SET role = $1 WHERE id = $2 RETURNING "+userColumns, role, id))
+}
+
+func scanUser(row rowScanner) (model.User, error) {
+	var user model.User
+
+	err := row.Scan(&user.Id, &user.Username, &user.PasswordHash, &user.Role, &user.CreatedAt)
+
+	if err != nil {
+		return model.User{}, err
+	}
+
+	return user, nil
+}
+
+func NewUserRepositori(db *sql.DB) UserRepositori {
+	return &userRepositori{db: db}
+}
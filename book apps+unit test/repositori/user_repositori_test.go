@@ -0,0 +1,87 @@
+package repositori
+
+import (
+	"database/sql"
+	"regexp"
+	"simple-clean-architecture/model"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewUserRepositori(db)
+
+	createdAt := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO mst_user(username, password_hash, role) VALUES($1, $2, $3) RETURNING id, created_at")).
+		WithArgs("budi", "hash", model.RoleMember).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, createdAt))
+
+	user, err := repo.CreateUser(model.User{Username: "budi", PasswordHash: "hash", Role: model.RoleMember})
+	assert.NoError(t, err)
+	assert.Equal(t, model.User{Id: 1, Username: "budi", PasswordHash: "hash", Role: model.RoleMember, CreatedAt: createdAt}, user)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUserByUsername(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewUserRepositori(db)
+
+	createdAt := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, username, password_hash, role, created_at FROM mst_user WHERE LOWER(username) = LOWER($1)")).
+		WithArgs("Budi").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "password_hash", "role", "created_at"}).AddRow(1, "budi", "hash", model.RoleLibrarian, createdAt))
+
+	user, err := repo.GetUserByUsername("Budi")
+	assert.NoError(t, err)
+	assert.Equal(t, model.User{Id: 1, Username: "budi", PasswordHash: "hash", Role: model.RoleLibrarian, CreatedAt: createdAt}, user)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM mst_user WHERE LOWER(username)")).WithArgs("siti").WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.GetUserByUsername("siti")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUserById(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewUserRepositori(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, username, password_hash, role, created_at FROM mst_user WHERE id = $1")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "password_hash", "role", "created_at"}).AddRow(1, "budi", "hash", model.RoleMember, time.Now()))
+
+	user, err := repo.GetUserById(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "budi", user.Username)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateUserRole(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewUserRepositori(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE mst_user SET role = $1 WHERE id = $2 RETURNING id, username, password_hash, role, created_at")).
+		WithArgs(model.RoleLibrarian, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "password_hash", "role", "created_at"}).AddRow(2, "siti", "hash", model.RoleLibrarian, time.Now()))
+
+	user, err := repo.UpdateUserRole(2, model.RoleLibrarian)
+	assert.NoError(t, err)
+	assert.Equal(t, model.RoleLibrarian, user.Role)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
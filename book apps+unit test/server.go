@@ -10,10 +10,13 @@ package main
 import (
 	"simple-clean-architecture/config"
 	"simple-clean-architecture/controller"
+	"simple-clean-architecture/middleware"
 	"strconv"
+	"time"
 
 	"simple-clean-architecture/repositori"
 	"simple-clean-architecture/usecase"
+	"simple-clean-architecture/utils/service"
 
 	"database/sql"
 
@@ -22,15 +25,34 @@ import (
 )
 
 type Server struct {
-	bookUsecase usecase.BookUsecase
-	engine      *gin.Engine
-	host        string
+	bookUsecase     usecase.BookUsecase
+	bookCopyUsecase usecase.BookCopyUsecase
+	importUsecase   usecase.BookImportUsecase
+	authorUsecase   usecase.AuthorUsecase
+	categoryUsecase usecase.CategoryUsecase
+	memberUsecase   usecase.MemberUsecase
+	loanUsecase     usecase.LoanUsecase
+	holdUsecase     usecase.HoldUsecase
+	authUsecase     usecase.AuthUsecase
+	jwtService      service.JWTService
+	tokenConfig     config.TokenConfig
+	engine          *gin.Engine
+	host            string
 }
 
 func (s *Server) initRoute() {
 	rg := s.engine.Group("api/v1")
-
-	controller.NewBookController(s.bookUsecase, rg).Route()
+	authMiddleware := middleware.NewAuthMiddleware(s.jwtService, s.authUsecase, s.tokenConfig.AllowAnonymousRead)
+
+	controller.NewAuthController(s.authUsecase, rg, authMiddleware).Route()
+	controller.NewBookController(s.bookUsecase, rg, authMiddleware).Route()
+	controller.NewBookCopyController(s.bookCopyUsecase, rg, authMiddleware).Route()
+	controller.NewBookImportController(s.importUsecase, rg, authMiddleware).Route()
+	controller.NewAuthorController(s.authorUsecase, rg, authMiddleware).Route()
+	controller.NewCategoryController(s.categoryUsecase, rg, authMiddleware).Route()
+	controller.NewMemberController(s.memberUsecase, rg, authMiddleware).Route()
+	controller.NewLoanController(s.loanUsecase, rg, authMiddleware).Route()
+	controller.NewHoldController(s.holdUsecase, rg, authMiddleware).Route()
 }
 
 func (s *Server) Run() {
@@ -63,14 +85,47 @@ func NewServer() *Server {
 	}
 
 	bookRepositori := repositori.NewBookRepositori(db)
-	bookUsecase := usecase.NewBookUsecase(bookRepositori)
+	bookCopyRepositori := repositori.NewBookCopyRepositori(db)
+	authorRepositori := repositori.NewAuthorRepositori(db)
+	categoryRepositori := repositori.NewCategoryRepositori(db)
+	var metadataSource repositori.MetadataSource
+	if cfg.MetadataConfig.DumpFile != "" {
+		metadataSource, err = repositori.NewOpenLibrarySource(cfg.MetadataConfig.DumpFile)
+		if err != nil {
+			panic(err)
+		}
+	}
+	bookUsecase := usecase.NewBookUsecase(bookRepositori, bookCopyRepositori, authorRepositori, categoryRepositori, metadataSource)
+	importUsecase := usecase.NewBookImportUsecase(bookRepositori, metadataSource)
+	authorUsecase := usecase.NewAuthorUsecase(authorRepositori, bookRepositori)
+	categoryUsecase := usecase.NewCategoryUsecase(categoryRepositori, bookRepositori)
+	memberRepositori := repositori.NewMemberRepositori(db)
+	memberUsecase := usecase.NewMemberUsecase(memberRepositori)
+	holdRepositori := repositori.NewHoldRepositori(db)
+	holdPeriod := time.Duration(cfg.LoanConfig.HoldPickupDays) * 24 * time.Hour
+	bookCopyUsecase := usecase.NewBookCopyUsecase(bookCopyRepositori, bookRepositori, holdPeriod)
+	holdUsecase := usecase.NewHoldUsecase(holdRepositori, bookRepositori, bookCopyRepositori, memberRepositori, holdPeriod)
+	loanRepositori := repositori.NewLoanRepositori(db)
+	loanUsecase := usecase.NewLoanUsecase(loanRepositori, bookRepositori, bookCopyRepositori, memberRepositori, holdRepositori, time.Duration(cfg.LoanConfig.PeriodDays)*24*time.Hour, cfg.LoanConfig.FinePerDay, holdPeriod)
+	jwtService := service.NewJWTService(cfg.TokenConfig)
+	authUsecase := usecase.NewAuthUsecase(repositori.NewUserRepositori(db), jwtService)
 
 	engine := gin.Default()
 
 	return &Server{
-		bookUsecase: bookUsecase,
-		engine:      engine,
-		host:        cfg.APIConfig.Host + ":" + strconv.Itoa(cfg.APIConfig.Port),
+		bookUsecase:     bookUsecase,
+		bookCopyUsecase: bookCopyUsecase,
+		importUsecase:   importUsecase,
+		authorUsecase:   authorUsecase,
+		categoryUsecase: categoryUsecase,
+		memberUsecase:   memberUsecase,
+		loanUsecase:     loanUsecase,
+		holdUsecase:     holdUsecase,
+		authUsecase:     authUsecase,
+		jwtService:      jwtService,
+		tokenConfig:     cfg.TokenConfig,
+		engine:          engine,
+		host:            cfg.APIConfig.Host + ":" + strconv.Itoa(cfg.APIConfig.Port),
 	}
 
 }
@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/repositori"
+	"simple-clean-architecture/utils/service"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	minUsernameLength = 3
+	maxUsernameLength = 50
+	minPasswordLength = 8
+	// bcrypt hanya memakai 72 byte pertama password
+	maxPasswordLength = 72
+	// dummyPasswordHash dibandingkan saat username tidak ada, dengan cost yang
+	// sama seperti Register, agar waktu respons login tidak membocorkan
+	// username yang terdaftar
+	dummyPasswordHash = "$2a$10$NHrtXC04cXDZINSY6t787umXdFU6b6yDl1k4fH6CD5TPnpsk3SvXS"
+)
+
+type authUsecase struct {
+	userRepositori repositori.UserRepositori
+	jwtService     service.JWTService
+}
+
+type AuthUsecase interface {
+	Register(user model.User) (model.User, error)
+	Login(request model.LoginRequest) (model.LoginResponse, error)
+	GetUserById(id int) (model.User, error)
+	UpdateUserRole(id int, role string) (model.User, error)
+}
+
+// Register selalu membuat user dengan role member, role librarian hanya bisa
+// diberikan oleh librarian lain lewat UpdateUserRole.
+func (a *authUsecase) Register(user model.User) (model.User, error) {
+	user.Username = strings.TrimSpace(user.Username)
+	if len(user.Username) < minUsernameLength || len(user.Username) > maxUsernameLength {
+		return model.User{}, &ValidationError{Message: "username must be between 3 and 50 characters"}
+	}
+	if strings.ContainsAny(user.Username, " \t\r\n") {
+		return model.User{}, &ValidationError{Message: "username cannot contain spaces"}
+	}
+	if len(user.Password) < minPasswordLength || len(user.Password) > maxPasswordLength {
+		return model.User{}, &ValidationError{Message: "password must be between 8 and 72 characters"}
+	}
+
+	_, err := a.userRepositori.GetUserByUsername(user.Username)
+	if err == nil {
+		return model.User{}, &ConflictError{Message: "username already taken"}
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return model.User{}, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return model.User{}, err
+	}
+	user.Password = ""
+	user.PasswordHash = string(hash)
+	user.Role = model.RoleMember
+
+	return a.userRepositori.CreateUser(user)
+}
+
+// Login menjawab username yang tidak ada dan password yang salah dengan error
+// dan waktu respons yang sama agar username yang terdaftar tidak bisa ditebak.
+func (a *authUsecase) Login(request model.LoginRequest) (model.LoginResponse, error) {
+	user, err := a.userRepositori.GetUserByUsername(strings.TrimSpace(request.Username))
+	if errors.Is(err, sql.ErrNoRows) {
+		bcrypt.CompareHashAndPassword([]byte(dummyPasswordHash), []byte(request.Password))
+		return model.LoginResponse{}, &UnauthorizedError{Message: "invalid username or password"}
+	}
+	if err != nil {
+		return model.LoginResponse{}, err
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(request.Password)) != nil {
+		return model.LoginResponse{}, &UnauthorizedError{Message: "invalid username or password"}
+	}
+
+	token, expiresAt, err := a.jwtService.CreateToken(user)
+	if err != nil {
+		return model.LoginResponse{}, err
+	}
+
+	return model.LoginResponse{Token: token, ExpiresAt: expiresAt}, nil
+}
+
+func (a *authUsecase) GetUserById(id int) (model.User, error) {
+	user, err := a.userRepositori.GetUserById(id)
+	if err != nil {
+		return model.User{}, notFound(err, "user not found")
+	}
+
+	return user, nil
+}
+
+func (a *authUsecase) UpdateUserRole(id int, role string) (model.User, error) {
+	if role != model.RoleLibrarian && role != model.RoleMember {
+		return model.User{}, &ValidationError{Message: "role must be librarian or member"}
+	}
+
+	user, err := a.userRepositori.UpdateUserRole(id, role)
+	if err != nil {
+		return model.User{}, notFound(err, "user not found")
+	}
+
+	return user, nil
+}
+
+func NewAuthUsecase(userRepositori repositori.UserRepositori, jwtService service.JWTService) AuthUsecase {
+	return &authUsecase{userRepositori: userRepositori, jwtService: jwtService}
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/utils/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) CreateUser(user model.User) (model.User, error) {
+	args := m.Called(user)
+	return args.Get(0).(model.User), args.Error(1)
+}
+
+func (m *MockUserRepository) GetUserById(id int) (model.User, error) {
+	args := m.Called(id)
+	return args.Get(0).(model.User), args.Error(1)
+}
+
+func (m *MockUserRepository) GetUserByUsername(username string) (model.User, error) {
+	args := m.Called(username)
+	return args.Get(0).(model.User), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateUserRole(id int, role string) (model.User, error) {
+	args := m.Called(id, role)
+	return args.Get(0).(model.User), args.Error(1)
+}
+
+type MockJWTService struct {
+	mock.Mock
+}
+
+func (m *MockJWTService) CreateToken(user model.User) (string, time.Time, error) {
+	args := m.Called(user)
+	return args.String(0), args.Get(1).(time.Time), args.Error(2)
+}
+
+func (m *MockJWTService) VerifyToken(tokenString string) (*service.JwtClaims, error) {
+	args := m.Called(tokenString)
+	return args.Get(0).(*service.JwtClaims), args.Error(1)
+}
+
+func TestAuthUsecase_Register(t *testing.T) {
+	repo := new(MockUserRepository)
+	usecase := NewAuthUsecase(repo, new(MockJWTService))
+
+	// Happy Path: role dari request diabaikan, user baru selalu member
+	repo.On("GetUserByUsername", "budi").Return(model.User{}, sql.ErrNoRows).Once()
+	repo.On("CreateUser", mock.MatchedBy(func(user model.User) bool {
+		return user.Username == "budi" && user.Password == "" && user.Role == model.RoleMember &&
+			bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte("rahasia123")) == nil
+	})).Return(model.User{Id: 1, Username: "budi", Role: model.RoleMember}, nil).Once()
+
+	user, err := usecase.Register(model.User{Username: " budi ", Password: "rahasia123", Role: model.RoleLibrarian})
+	assert.NoError(t, err)
+	assert.Equal(t, model.RoleMember, user.Role)
+	repo.AssertExpectations(t)
+
+	// Sad Path: username sudah dipakai
+	repo.On("GetUserByUsername", "siti").Return(model.User{Id: 2, Username: "Siti"}, nil).Once()
+
+	_, err = usecase.Register(model.User{Username: "siti", Password: "rahasia123"})
+	var conflictErr *ConflictError
+	assert.ErrorAs(t, err, &conflictErr)
+
+	// Sad Path: input tidak valid
+	for _, user := range []model.User{{Username: "bu", Password: "rahasia123"}, {Username: "bu di", Password: "rahasia123"}, {Username: "budi", Password: "pendek"}} {
+		_, err := usecase.Register(user)
+
+		var validationErr *ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+	}
+	repo.AssertNumberOfCalls(t, "CreateUser", 1)
+}
+
+func TestAuthUsecase_Login(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("rahasia123"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	stored := model.User{Id: 1, Username: "budi", PasswordHash: string(hash), Role: model.RoleLibrarian}
+
+	repo := new(MockUserRepository)
+	jwtService := new(MockJWTService)
+	usecase := NewAuthUsecase(repo, jwtService)
+
+	// Happy Path
+	expiresAt := time.Now().Add(time.Hour)
+	repo.On("GetUserByUsername", "budi").Return(stored, nil)
+	jwtService.On("CreateToken", stored).Return("token", expiresAt, nil).Once()
+
+	response, err := usecase.Login(model.LoginRequest{Username: "budi", Password: "rahasia123"})
+	assert.NoError(t, err)
+	assert.Equal(t, model.LoginResponse{Token: "token", ExpiresAt: expiresAt}, response)
+
+	// Sad Path: password salah dan username tidak ada dijawab sama
+	repo.On("GetUserByUsername", "siti").Return(model.User{}, sql.ErrNoRows).Once()
+
+	for _, request := range []model.LoginRequest{{Username: "budi", Password: "salah12345"}, {Username: "siti", Password: "rahasia123"}} {
+		_, err := usecase.Login(request)
+
+		var unauthorizedErr *UnauthorizedError
+		assert.ErrorAs(t, err, &unauthorizedErr)
+		assert.EqualError(t, err, "invalid username or password")
+	}
+
+	// username yang tidak ada dibandingkan dengan hash dummy ber-cost sama
+	cost, err := bcrypt.Cost([]byte(dummyPasswordHash))
+	assert.NoError(t, err)
+	assert.Equal(t, bcrypt.DefaultCost, cost)
+
+	// Sad Path: database error
+	repo.On("GetUserByUsername", "andi").Return(model.User{}, errors.New("connection refused")).Once()
+
+	_, err = usecase.Login(model.LoginRequest{Username: "andi", Password: "rahasia123"})
+	assert.EqualError(t, err, "connection refused")
+	jwtService.AssertExpectations(t)
+}
+
+func TestAuthUsecase_UpdateUserRole(t *testing.T) {
+	repo := new(MockUserRepository)
+	usecase := NewAuthUsecase(repo, new(MockJWTService))
+
+	// Happy Path
+	repo.On("UpdateUserRole", 2, model.RoleLibrarian).Return(model.User{Id: 2, Role: model.RoleLibrarian}, nil).Once()
+
+	user, err := usecase.UpdateUserRole(2, model.RoleLibrarian)
+	assert.NoError(t, err)
+	assert.Equal(t, model.RoleLibrarian, user.Role)
+
+	// Sad Path: role tidak dikenal
+	_, err = usecase.UpdateUserRole(2, "admin")
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+
+	// Sad Path: user tidak ada
+	repo.On("UpdateUserRole", 9, model.RoleMember).Return(model.User{}, sql.ErrNoRows).Once()
+
+	_, err = usecase.UpdateUserRole(9, model.RoleMember)
+	var notFoundErr *NotFoundError
+	assert.ErrorAs(t, err, &notFoundErr)
+	repo.AssertExpectations(t)
+}
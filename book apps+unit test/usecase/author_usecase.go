@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/repositori"
+	"strings"
+)
+
+type authorUsecase struct {
+	authorRepositori repositori.AuthorRepositori
+	bookRepositori   repositori.BookRepositori
+}
+
+type AuthorUsecase interface {
+	CreateAuthor(author model.Author) (model.Author, error)
+	GetAllAuthor() ([]model.Author, error)
+	GetAuthorById(id int) (model.Author, error)
+	UpdateAuthor(author model.Author, userId int) (model.Author, error)
+	DeleteAuthor(id int) error
+	SetBookAuthors(bookId int, authorIds []int, userId int) ([]model.Author, error)
+}
+
+func (a *authorUsecase) CreateAuthor(author model.Author) (model.Author, error) {
+	if err := a.validateAuthor(&author); err != nil {
+		return model.Author{}, err
+	}
+
+	return a.authorRepositori.CreateAuthor(author)
+}
+
+func (a *authorUsecase) GetAllAuthor() ([]model.Author, error) {
+	return a.authorRepositori.GetAllAuthor()
+}
+
+func (a *authorUsecase) GetAuthorById(id int) (model.Author, error) {
+	author, err := a.authorRepositori.GetAuthorById(id)
+
+	if err != nil {
+		return model.Author{}, notFound(err, "author not found")
+	}
+
+	return author, nil
+}
+
+// UpdateAuthor mengganti nama author. Kolom author pada buku-bukunya ikut
+// diperbarui oleh repositori dan dicatat sebagai perubahan oleh userId.
+func (a *authorUsecase) UpdateAuthor(author model.Author, userId int) (model.Author, error) {
+	existing, err := a.GetAuthorById(author.Id)
+	if err != nil {
+		return model.Author{}, err
+	}
+	if err := a.validateAuthor(&author); err != nil {
+		return model.Author{}, err
+	}
+
+	if err := a.authorRepositori.UpdateAuthor(author, userId); err != nil {
+		return model.Author{}, err
+	}
+
+	existing.Name = author.Name
+	return existing, nil
+}
+
+// DeleteAuthor menolak menghapus author yang masih terhubung ke buku, karena
+// nama author tersebut masih dipakai di kolom author buku.
+func (a *authorUsecase) DeleteAuthor(id int) error {
+	author, err := a.GetAuthorById(id)
+	if err != nil {
+		return err
+	}
+	if author.BookCount > 0 {
+		return &ConflictError{Message: "author still has books"}
+	}
+
+	return a.authorRepositori.DeleteAuthor(id)
+}
+
+// SetBookAuthors mengganti semua author sebuah buku sesuai urutan authorIds
+// dan mengembalikan author yang sekarang terhubung.
+func (a *authorUsecase) SetBookAuthors(bookId int, authorIds []int, userId int) ([]model.Author, error) {
+	if _, err := a.bookRepositori.GetBookById(bookId); err != nil {
+		return nil, notFound(err, "book not found")
+	}
+	if err := validateRelationIds(authorIds); err != nil {
+		return nil, err
+	}
+	for _, id := range authorIds {
+		_, err := a.authorRepositori.GetAuthorById(id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &ValidationError{Message: fmt.Sprintf("author %d does not exist", id)}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := a.authorRepositori.SetBookAuthors(bookId, authorIds, userId); err != nil {
+		return nil, err
+	}
+
+	return a.authorRepositori.GetAuthorsByBookId(bookId)
+}
+
+// validateAuthor merapikan nama dan memastikan tidak ada author lain dengan
+// nama yang sama.
+func (a *authorUsecase) validateAuthor(author *model.Author) error {
+	author.Name = strings.TrimSpace(author.Name)
+	if author.Name == "" {
+		return &ValidationError{Message: "name cannot be empty"}
+	}
+
+	existing, err := a.authorRepositori.GetAuthorByName(author.Name)
+	if err == nil && existing.Id != author.Id {
+		return &ConflictError{Message: "author already exists"}
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	return nil
+}
+
+// validateRelationIds memeriksa id yang dikirim untuk dihubungkan ke buku.
+func validateRelationIds(ids []int) error {
+	seen := map[int]bool{}
+	for _, id := range ids {
+		if id <= 0 {
+			return &ValidationError{Message: "ids must be positive"}
+		}
+		if seen[id] {
+			return &ValidationError{Message: fmt.Sprintf("id %d is listed more than once", id)}
+		}
+		seen[id] = true
+	}
+
+	return nil
+}
+
+func NewAuthorUsecase(authorRepositori repositori.AuthorRepositori, bookRepositori repositori.BookRepositori) AuthorUsecase {
+	return &authorUsecase{authorRepositori: authorRepositori, bookRepositori: bookRepositori}
+}
@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"database/sql"
+	"simple-clean-architecture/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuthorRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuthorRepository) CreateAuthor(author model.Author) (model.Author, error) {
+	args := m.Called(author)
+	return args.Get(0).(model.Author), args.Error(1)
+}
+
+func (m *MockAuthorRepository) GetAllAuthor() ([]model.Author, error) {
+	args := m.Called()
+	return args.Get(0).([]model.Author), args.Error(1)
+}
+
+func (m *MockAuthorRepository) GetAuthorById(id int) (model.Author, error) {
+	args := m.Called(id)
+	return args.Get(0).(model.Author), args.Error(1)
+}
+
+func (m *MockAuthorRepository) GetAuthorByName(name string) (model.Author, error) {
+	args := m.Called(name)
+	return args.Get(0).(model.Author), args.Error(1)
+}
+
+func (m *MockAuthorRepository) UpdateAuthor(author model.Author, userId int) error {
+	args := m.Called(author, userId)
+	return args.Error(0)
+}
+
+func (m *MockAuthorRepository) DeleteAuthor(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockAuthorRepository) GetAuthorsByBookId(bookId int) ([]model.Author, error) {
+	args := m.Called(bookId)
+	return args.Get(0).([]model.Author), args.Error(1)
+}
+
+func (m *MockAuthorRepository) SetBookAuthors(bookId int, authorIds []int, userId int) error {
+	args := m.Called(bookId, authorIds, userId)
+	return args.Error(0)
+}
+
+func TestAuthorUsecase_CreateAuthor(t *testing.T) {
+	authorRepo := new(MockAuthorRepository)
+	usecase := NewAuthorUsecase(authorRepo, new(MockBookRepository))
+
+	// Happy Path
+	authorRepo.On("GetAuthorByName", "Alan Donovan").Return(model.Author{}, sql.ErrNoRows)
+	authorRepo.On("CreateAuthor", model.Author{Name: "Alan Donovan"}).Return(model.Author{Id: 1, Name: "Alan Donovan"}, nil).Once()
+
+	author, err := usecase.CreateAuthor(model.Author{Name: " Alan Donovan "})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, author.Id)
+
+	// Sad Path: nama kosong atau sudah dipakai
+	var validationErr *ValidationError
+	_, err = usecase.CreateAuthor(model.Author{Name: " "})
+	assert.ErrorAs(t, err, &validationErr)
+
+	authorRepo.On("GetAuthorByName", "rob pike").Return(model.Author{Id: 2, Name: "Rob Pike"}, nil)
+
+	var conflictErr *ConflictError
+	_, err = usecase.CreateAuthor(model.Author{Name: "rob pike"})
+	assert.ErrorAs(t, err, &conflictErr)
+	authorRepo.AssertExpectations(t)
+}
+
+func TestAuthorUsecase_UpdateAuthor(t *testing.T) {
+	authorRepo := new(MockAuthorRepository)
+	usecase := NewAuthorUsecase(authorRepo, new(MockBookRepository))
+
+	// Happy Path: mengganti kapitalisasi nama sendiri bukan konflik
+	authorRepo.On("GetAuthorById", 2).Return(model.Author{Id: 2, Name: "rob pike", BookCount: 3}, nil)
+	authorRepo.On("GetAuthorByName", "Rob Pike").Return(model.Author{Id: 2, Name: "rob pike", BookCount: 3}, nil)
+	authorRepo.On("UpdateAuthor", model.Author{Id: 2, Name: "Rob Pike"}, 1).Return(nil).Once()
+
+	author, err := usecase.UpdateAuthor(model.Author{Id: 2, Name: "Rob Pike"}, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, model.Author{Id: 2, Name: "Rob Pike", BookCount: 3}, author)
+
+	// Sad Path: author tidak ditemukan
+	authorRepo.On("GetAuthorById", 9).Return(model.Author{}, sql.ErrNoRows)
+
+	var notFoundErr *NotFoundError
+	_, err = usecase.UpdateAuthor(model.Author{Id: 9, Name: "Someone"}, 1)
+	assert.ErrorAs(t, err, &notFoundErr)
+	authorRepo.AssertExpectations(t)
+}
+
+func TestAuthorUsecase_DeleteAuthor(t *testing.T) {
+	authorRepo := new(MockAuthorRepository)
+	usecase := NewAuthorUsecase(authorRepo, new(MockBookRepository))
+
+	// Happy Path
+	authorRepo.On("GetAuthorById", 1).Return(model.Author{Id: 1}, nil)
+	authorRepo.On("DeleteAuthor", 1).Return(nil).Once()
+	assert.NoError(t, usecase.DeleteAuthor(1))
+
+	// Sad Path: author masih punya buku
+	authorRepo.On("GetAuthorById", 2).Return(model.Author{Id: 2, BookCount: 1}, nil)
+
+	var conflictErr *ConflictError
+	assert.ErrorAs(t, usecase.DeleteAuthor(2), &conflictErr)
+	authorRepo.AssertNotCalled(t, "DeleteAuthor", 2)
+}
+
+func TestAuthorUsecase_SetBookAuthors(t *testing.T) {
+	authorRepo := new(MockAuthorRepository)
+	bookRepo := new(MockBookRepository)
+	usecase := NewAuthorUsecase(authorRepo, bookRepo)
+
+	// Happy Path
+	bookRepo.On("GetBookById", 1).Return(model.Book{Id: 1}, nil)
+	authorRepo.On("GetAuthorById", 3).Return(model.Author{Id: 3, Name: "Brian Kernighan"}, nil)
+	authorRepo.On("GetAuthorById", 2).Return(model.Author{Id: 2, Name: "Alan Donovan"}, nil)
+	authorRepo.On("SetBookAuthors", 1, []int{2, 3}, 1).Return(nil).Once()
+	expected := []model.Author{{Id: 2, Name: "Alan Donovan", BookCount: 1}, {Id: 3, Name: "Brian Kernighan", BookCount: 1}}
+	authorRepo.On("GetAuthorsByBookId", 1).Return(expected, nil).Once()
+
+	authors, err := usecase.SetBookAuthors(1, []int{2, 3}, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, authors)
+	authorRepo.AssertExpectations(t)
+
+	// Sad Path: id ganda atau author tidak ada
+	authorRepo.On("GetAuthorById", 8).Return(model.Author{}, sql.ErrNoRows)
+
+	var validationErr *ValidationError
+	_, err = usecase.SetBookAuthors(1, []int{2, 2}, 1)
+	assert.ErrorAs(t, err, &validationErr)
+
+	_, err = usecase.SetBookAuthors(1, []int{2, 8}, 1)
+	assert.EqualError(t, err, "author 8 does not exist")
+
+	// Sad Path: buku tidak ditemukan
+	bookRepo.On("GetBookById", 5).Return(model.Book{}, sql.ErrNoRows)
+
+	var notFoundErr *NotFoundError
+	_, err = usecase.SetBookAuthors(5, []int{2}, 1)
+	assert.ErrorAs(t, err, &notFoundErr)
+	authorRepo.AssertNumberOfCalls(t, "SetBookAuthors", 1)
+}
@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/repositori"
+	"strings"
+	"time"
+)
+
+const defaultCopyCondition = "good"
+
+type bookCopyUsecase struct {
+	copyRepositori repositori.BookCopyRepositori
+	bookRepositori repositori.BookRepositori
+	holdPeriod     time.Duration
+	now            func() time.Time
+}
+
+type BookCopyUsecase interface {
+	CreateCopy(bookId int, copy model.BookCopy) (model.BookCopy, error)
+	GetCopiesByBookId(bookId int) ([]model.BookCopy, error)
+	UpdateCopy(copy model.BookCopy) (model.BookCopy, error)
+}
+
+// CreateCopy mendaftarkan eksemplar baru untuk buku. Barcode wajib diisi dan
+// unik, kondisi default good dan status default available. Eksemplar available
+// langsung disiapkan untuk hold terdepan jika ada antrian.
+func (b *bookCopyUsecase) CreateCopy(bookId int, copy model.BookCopy) (model.BookCopy, error) {
+	if _, err := b.bookRepositori.GetBookById(bookId); err != nil {
+		return model.BookCopy{}, notFound(err, "book not found")
+	}
+
+	copy.BookId = bookId
+	copy.Barcode = strings.TrimSpace(copy.Barcode)
+	if copy.Barcode == "" {
+		return model.BookCopy{}, &ValidationError{Message: "barcode cannot be empty"}
+	}
+	if copy.Condition == "" {
+		copy.Condition = defaultCopyCondition
+	}
+	if copy.Status == "" {
+		copy.Status = model.CopyStatusAvailable
+	}
+	if err := validateCopyStatus(copy.Status); err != nil {
+		return model.BookCopy{}, err
+	}
+
+	_, err := b.copyRepositori.GetCopyByBarcode(copy.Barcode)
+	if err == nil {
+		return model.BookCopy{}, &ConflictError{Message: "barcode is already registered"}
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return model.BookCopy{}, err
+	}
+
+	now := b.now()
+	return b.copyRepositori.CreateCopy(copy, now, now.Add(b.holdPeriod))
+}
+
+func (b *bookCopyUsecase) GetCopiesByBookId(bookId int) ([]model.BookCopy, error) {
+	if _, err := b.bookRepositori.GetBookById(bookId); err != nil {
+		return nil, notFound(err, "book not found")
+	}
+
+	return b.copyRepositori.GetCopiesByBookId(bookId)
+}
+
+// UpdateCopy mengubah kondisi, lokasi rak, dan status eksemplar. Field yang
+// kosong tidak diubah. Status on_loan dan on_hold hanya diatur lewat peminjaman
+// dan reservasi, sehingga eksemplar dengan status tersebut tidak bisa diubah,
+// kecuali eksemplar on_loan yang dilaporkan hilang: statusnya menjadi lost dan
+// loan aktifnya ditutup. Seperti CreateCopy, eksemplar yang kembali available
+// diberikan ke antrian hold terlebih dahulu.
+func (b *bookCopyUsecase) UpdateCopy(copy model.BookCopy) (model.BookCopy, error) {
+	existing, err := b.copyRepositori.GetCopyById(copy.Id)
+	if err != nil {
+		return model.BookCopy{}, notFound(err, "book copy not found")
+	}
+	fromStatus := existing.Status
+	if existing.Status == model.CopyStatusOnLoan && copy.Status != model.CopyStatusLost {
+		return model.BookCopy{}, &ConflictError{Message: "book copy is on loan"}
+	}
+	if existing.Status == model.CopyStatusOnHold {
+		return model.BookCopy{}, &ConflictError{Message: "book copy is on hold for a member"}
+	}
+
+	if copy.Condition != "" {
+		existing.Condition = copy.Condition
+	}
+	if copy.ShelfLocation != "" {
+		existing.ShelfLocation = copy.ShelfLocation
+	}
+	if copy.Status != "" {
+		if err := validateCopyStatus(copy.Status); err != nil {
+			return model.BookCopy{}, err
+		}
+		existing.Status = copy.Status
+	}
+
+	now := b.now()
+	updated, err := b.copyRepositori.UpdateCopy(existing, fromStatus, now, now.Add(b.holdPeriod))
+	if errors.Is(err, repositori.ErrCopyStatusChanged) {
+		return model.BookCopy{}, &ConflictError{Message: "book copy status has changed, please try again"}
+	}
+
+	return updated, err
+}
+
+func validateCopyStatus(status string) error {
+	switch status {
+	case model.CopyStatusAvailable, model.CopyStatusLost, model.CopyStatusRepair:
+		return nil
+	case model.CopyStatusOnLoan, model.CopyStatusOnHold:
+		return &ValidationError{Message: "status " + status + " is set by borrowing or reserving the copy"}
+	default:
+		return &ValidationError{Message: "status must be available, lost or repair"}
+	}
+}
+
+func NewBookCopyUsecase(copyRepositori repositori.BookCopyRepositori, bookRepositori repositori.BookRepositori, holdPeriod time.Duration) BookCopyUsecase {
+	return &bookCopyUsecase{copyRepositori: copyRepositori, bookRepositori: bookRepositori, holdPeriod: holdPeriod, now: time.Now}
+}
@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"database/sql"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/repositori"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBookCopyRepository struct {
+	mock.Mock
+}
+
+func (m *MockBookCopyRepository) CreateCopy(copy model.BookCopy, now time.Time, holdExpiresAt time.Time) (model.BookCopy, error) {
+	args := m.Called(copy, now, holdExpiresAt)
+	return args.Get(0).(model.BookCopy), args.Error(1)
+}
+
+func (m *MockBookCopyRepository) GetCopiesByBookId(bookId int) ([]model.BookCopy, error) {
+	args := m.Called(bookId)
+	return args.Get(0).([]model.BookCopy), args.Error(1)
+}
+
+func (m *MockBookCopyRepository) GetCopyById(id int) (model.BookCopy, error) {
+	args := m.Called(id)
+	return args.Get(0).(model.BookCopy), args.Error(1)
+}
+
+func (m *MockBookCopyRepository) GetCopyByBarcode(barcode string) (model.BookCopy, error) {
+	args := m.Called(barcode)
+	return args.Get(0).(model.BookCopy), args.Error(1)
+}
+
+func (m *MockBookCopyRepository) GetAvailableCopy(bookId int) (model.BookCopy, error) {
+	args := m.Called(bookId)
+	return args.Get(0).(model.BookCopy), args.Error(1)
+}
+
+func (m *MockBookCopyRepository) GetAvailability(bookId int) (model.BookAvailability, error) {
+	args := m.Called(bookId)
+	return args.Get(0).(model.BookAvailability), args.Error(1)
+}
+
+func (m *MockBookCopyRepository) UpdateCopy(copy model.BookCopy, fromStatus string, now time.Time, holdExpiresAt time.Time) (model.BookCopy, error) {
+	args := m.Called(copy, fromStatus, now, holdExpiresAt)
+	return args.Get(0).(model.BookCopy), args.Error(1)
+}
+
+func newTestBookCopyUsecase(copyRepo *MockBookCopyRepository, bookRepo *MockBookRepository) *bookCopyUsecase {
+	usecase := NewBookCopyUsecase(copyRepo, bookRepo, 3*24*time.Hour).(*bookCopyUsecase)
+	usecase.now = func() time.Time { return loanNow }
+	return usecase
+}
+
+func TestBookCopyUsecase_CreateCopy(t *testing.T) {
+	copyRepo := new(MockBookCopyRepository)
+	bookRepo := new(MockBookRepository)
+	usecase := newTestBookCopyUsecase(copyRepo, bookRepo)
+
+	// Happy Path
+	bookRepo.On("GetBookById", 1).Return(model.Book{Id: 1}, nil)
+	copyRepo.On("GetCopyByBarcode", "B-001").Return(model.BookCopy{}, sql.ErrNoRows)
+	expected := model.BookCopy{BookId: 1, Barcode: "B-001", Condition: "good", ShelfLocation: "A1", Status: model.CopyStatusAvailable}
+	copyRepo.On("CreateCopy", expected, loanNow, holdExpiresAt).Return(model.BookCopy{Id: 7, BookId: 1, Barcode: "B-001", Condition: "good", ShelfLocation: "A1", Status: model.CopyStatusAvailable}, nil).Once()
+
+	copy, err := usecase.CreateCopy(1, model.BookCopy{Barcode: " B-001 ", ShelfLocation: "A1"})
+	assert.NoError(t, err)
+	assert.Equal(t, 7, copy.Id)
+	copyRepo.AssertExpectations(t)
+
+	// Happy Path: eksemplar baru langsung disiapkan untuk hold yang menunggu
+	copyRepo.On("GetCopyByBarcode", "B-005").Return(model.BookCopy{}, sql.ErrNoRows)
+	expected = model.BookCopy{BookId: 1, Barcode: "B-005", Condition: "good", Status: model.CopyStatusAvailable}
+	copyRepo.On("CreateCopy", expected, loanNow, holdExpiresAt).Return(model.BookCopy{Id: 8, BookId: 1, Barcode: "B-005", Condition: "good", Status: model.CopyStatusOnHold}, nil).Once()
+
+	copy, err = usecase.CreateCopy(1, model.BookCopy{Barcode: "B-005"})
+	assert.NoError(t, err)
+	assert.Equal(t, model.CopyStatusOnHold, copy.Status)
+
+	// Sad Path: Barcode already registered
+	copyRepo.On("GetCopyByBarcode", "B-002").Return(model.BookCopy{Id: 8, Barcode: "B-002"}, nil)
+
+	_, err = usecase.CreateCopy(1, model.BookCopy{Barcode: "B-002"})
+	var conflictErr *ConflictError
+	assert.ErrorAs(t, err, &conflictErr)
+
+	// Sad Path: Invalid input
+	var validationErr *ValidationError
+	_, err = usecase.CreateCopy(1, model.BookCopy{Barcode: "  "})
+	assert.ErrorAs(t, err, &validationErr)
+
+	_, err = usecase.CreateCopy(1, model.BookCopy{Barcode: "B-003", Status: model.CopyStatusOnLoan})
+	assert.ErrorAs(t, err, &validationErr)
+
+	// Sad Path: Book not found
+	bookRepo.On("GetBookById", 9).Return(model.Book{}, sql.ErrNoRows)
+
+	_, err = usecase.CreateCopy(9, model.BookCopy{Barcode: "B-004"})
+	var notFoundErr *NotFoundError
+	assert.ErrorAs(t, err, &notFoundErr)
+}
+
+func TestBookCopyUsecase_GetCopiesByBookId(t *testing.T) {
+	copyRepo := new(MockBookCopyRepository)
+	bookRepo := new(MockBookRepository)
+	bookRepo.On("GetBookById", 1).Return(model.Book{Id: 1}, nil)
+	copyRepo.On("GetCopiesByBookId", 1).Return([]model.BookCopy{{Id: 1, BookId: 1}, {Id: 2, BookId: 1}}, nil)
+
+	copies, err := newTestBookCopyUsecase(copyRepo, bookRepo).GetCopiesByBookId(1)
+	assert.NoError(t, err)
+	assert.Len(t, copies, 2)
+}
+
+func TestBookCopyUsecase_UpdateCopy(t *testing.T) {
+	copyRepo := new(MockBookCopyRepository)
+	usecase := newTestBookCopyUsecase(copyRepo, new(MockBookRepository))
+
+	// Happy Path
+	copyRepo.On("GetCopyById", 2).Return(model.BookCopy{Id: 2, BookId: 1, Barcode: "B-002", Condition: "good", ShelfLocation: "A1", Status: model.CopyStatusAvailable}, nil)
+	expected := model.BookCopy{Id: 2, BookId: 1, Barcode: "B-002", Condition: "damaged", ShelfLocation: "A1", Status: model.CopyStatusRepair}
+	copyRepo.On("UpdateCopy", expected, model.CopyStatusAvailable, loanNow, holdExpiresAt).Return(expected, nil).Once()
+
+	copy, err := usecase.UpdateCopy(model.BookCopy{Id: 2, Condition: "damaged", Status: model.CopyStatusRepair})
+	assert.NoError(t, err)
+	assert.Equal(t, expected, copy)
+	copyRepo.AssertExpectations(t)
+
+	// Happy Path: eksemplar selesai diperbaiki diberikan ke antrian hold
+	copyRepo.On("GetCopyById", 4).Return(model.BookCopy{Id: 4, BookId: 1, Barcode: "B-004", Condition: "good", Status: model.CopyStatusRepair}, nil)
+	expected = model.BookCopy{Id: 4, BookId: 1, Barcode: "B-004", Condition: "good", Status: model.CopyStatusAvailable}
+	copyRepo.On("UpdateCopy", expected, model.CopyStatusRepair, loanNow, holdExpiresAt).Return(model.BookCopy{Id: 4, BookId: 1, Barcode: "B-004", Condition: "good", Status: model.CopyStatusOnHold}, nil).Once()
+
+	copy, err = usecase.UpdateCopy(model.BookCopy{Id: 4, Status: model.CopyStatusAvailable})
+	assert.NoError(t, err)
+	assert.Equal(t, model.CopyStatusOnHold, copy.Status)
+
+	// Happy Path: eksemplar yang dipinjam dilaporkan hilang
+	copyRepo.On("GetCopyById", 3).Return(model.BookCopy{Id: 3, Status: model.CopyStatusOnLoan}, nil)
+	expected = model.BookCopy{Id: 3, Status: model.CopyStatusLost}
+	copyRepo.On("UpdateCopy", expected, model.CopyStatusOnLoan, loanNow, holdExpiresAt).Return(expected, nil).Once()
+
+	copy, err = usecase.UpdateCopy(model.BookCopy{Id: 3, Status: model.CopyStatusLost})
+	assert.NoError(t, err)
+	assert.Equal(t, model.CopyStatusLost, copy.Status)
+
+	// Sad Path: Copy is on loan
+	_, err = usecase.UpdateCopy(model.BookCopy{Id: 3, Status: model.CopyStatusRepair})
+	var conflictErr *ConflictError
+	assert.ErrorAs(t, err, &conflictErr)
+
+	// Sad Path: status berubah sejak eksemplar dibaca, misalnya baru dipinjam
+	copyRepo.On("GetCopyById", 5).Return(model.BookCopy{Id: 5, Status: model.CopyStatusAvailable}, nil)
+	copyRepo.On("UpdateCopy", model.BookCopy{Id: 5, Condition: "damaged", Status: model.CopyStatusAvailable}, model.CopyStatusAvailable, loanNow, holdExpiresAt).Return(model.BookCopy{}, repositori.ErrCopyStatusChanged).Once()
+
+	_, err = usecase.UpdateCopy(model.BookCopy{Id: 5, Condition: "damaged"})
+	assert.ErrorAs(t, err, &conflictErr)
+
+	// Sad Path: Status on_loan cannot be set directly
+	_, err = usecase.UpdateCopy(model.BookCopy{Id: 2, Status: model.CopyStatusOnLoan})
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+
+	// Sad Path: Copy not found
+	copyRepo.On("GetCopyById", 9).Return(model.BookCopy{}, sql.ErrNoRows)
+
+	_, err = usecase.UpdateCopy(model.BookCopy{Id: 9})
+	var notFoundErr *NotFoundError
+	assert.ErrorAs(t, err, &notFoundErr)
+}
@@ -0,0 +1,293 @@
+package usecase
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/repositori"
+	"strconv"
+	"strings"
+)
+
+const bookImportBatchSize = 500
+
+// bookCsvHeader adalah urutan kolom file export CSV. Kolom id hanya dipakai
+// untuk buku tanpa isbn, agar file yang sama bisa di-import kembali tanpa
+// menggandakan buku tersebut.
+var bookCsvHeader = []string{"id", "isbn", "title", "author", "releaseYear", "pages"}
+
+type bookImportUsecase struct {
+	bookRepositori repositori.BookRepositori
+	metadataSource repositori.MetadataSource
+	batchSize      int
+}
+
+type BookImportUsecase interface {
+	ImportBooks(r io.Reader, format string, dryRun bool, userId int) (model.BookImportReport, error)
+	ExportBooks(w io.Writer, format string) error
+}
+
+// ImportBooks membaca buku dari CSV atau JSON baris per baris. Setiap baris
+// divalidasi dan dilaporkan sendiri-sendiri, baris yang valid disimpan per
+// batch dalam satu transaksi dengan upsert berdasarkan isbn, atau berdasarkan
+// id untuk buku tanpa isbn. Baris yang ditolak database hanya menggagalkan
+// baris itu sendiri. Jika file rusak
+// di tengah jalan, baris sebelumnya tetap diproses dan laporan berhenti di
+// baris yang rusak. Field yang kosong dilengkapi dari sumber metadata
+// berdasarkan isbn sebelum validasi.
+func (b *bookImportUsecase) ImportBooks(r io.Reader, format string, dryRun bool, userId int) (model.BookImportReport, error) {
+	var next func() (model.Book, error)
+	var err error
+	switch format {
+	case "csv":
+		next, err = csvBookReader(r)
+	case "json":
+		next, err = jsonBookReader(r)
+	default:
+		err = &ValidationError{Message: "format must be csv or json"}
+	}
+	if err != nil {
+		return model.BookImportReport{}, err
+	}
+
+	report := model.BookImportReport{DryRun: dryRun, Rows: []model.BookImportRow{}}
+	batch := make([]model.Book, 0, b.batchSize)
+	batchRows := make([]int, 0, b.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		results, err := b.bookRepositori.UpsertBooks(batch, dryRun, userId)
+		for i, index := range batchRows {
+			row := &report.Rows[index]
+			switch {
+			case err != nil:
+				row.Status = model.ImportStatusFailed
+				row.Error = err.Error()
+				report.Failed++
+			case results[i].Error != nil:
+				row.Status = model.ImportStatusFailed
+				row.Error = results[i].Error.Error()
+				report.Failed++
+			case results[i].Created:
+				row.Status = model.ImportStatusCreated
+				report.Created++
+			default:
+				row.Status = model.ImportStatusUpdated
+				report.Updated++
+			}
+			if err == nil && results[i].Error == nil && !dryRun {
+				row.Id = results[i].Id
+			}
+		}
+
+		batch = batch[:0]
+		batchRows = batchRows[:0]
+	}
+
+	for number := 1; ; number++ {
+		book, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var enrichErr error
+		if err == nil {
+			enrichErr = enrichBook(b.metadataSource, &book)
+		}
+		if err == nil && enrichErr == nil {
+			err = validateBook(&book)
+		}
+
+		report.Total++
+		row := model.BookImportRow{Row: number, Isbn: firstNonEmpty(book.Isbn, book.Isbn10), Title: book.Title}
+		if enrichErr != nil {
+			row.Status = model.ImportStatusFailed
+			row.Error = enrichErr.Error()
+			report.Failed++
+			report.Rows = append(report.Rows, row)
+			continue
+		}
+		if err != nil {
+			row.Status = model.ImportStatusInvalid
+			row.Error = err.Error()
+			report.Invalid++
+			report.Rows = append(report.Rows, row)
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				break
+			}
+			continue
+		}
+
+		report.Rows = append(report.Rows, row)
+		batch = append(batch, book)
+		batchRows = append(batchRows, len(report.Rows)-1)
+		if len(batch) == b.batchSize {
+			flush()
+		}
+	}
+	flush()
+
+	return report, nil
+}
+
+// ExportBooks menulis seluruh katalog sebagai CSV atau array JSON sambil
+// membaca dari database, sehingga tabel tidak pernah dimuat utuh ke memori.
+func (b *bookImportUsecase) ExportBooks(w io.Writer, format string) error {
+	switch format {
+	case "csv":
+		writer := csv.NewWriter(w)
+		if err := writer.Write(bookCsvHeader); err != nil {
+			return err
+		}
+
+		err := b.bookRepositori.StreamBooks(func(book model.Book) error {
+			return writer.Write([]string{strconv.Itoa(book.Id), book.Isbn, book.Title, book.Author, strconv.Itoa(book.ReleaseYear), strconv.Itoa(book.Pages)})
+		})
+		writer.Flush()
+		if err != nil {
+			return err
+		}
+
+		return writer.Error()
+	case "json":
+		if _, err := io.WriteString(w, "["); err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(w)
+		separator := ""
+		err := b.bookRepositori.StreamBooks(func(book model.Book) error {
+			if _, err := io.WriteString(w, separator); err != nil {
+				return err
+			}
+			separator = ","
+
+			return encoder.Encode(book)
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = io.WriteString(w, "]")
+		return err
+	default:
+		return &ValidationError{Message: "format must be csv or json"}
+	}
+}
+
+// csvBookReader membaca header CSV lalu mengembalikan fungsi yang membaca satu
+// buku setiap dipanggil, dengan io.EOF di akhir file. Nama kolom tidak
+// membedakan huruf besar kecil dan garis bawah, sehingga release_year juga
+// dikenali sebagai releaseYear.
+func csvBookReader(r io.Reader) (func() (model.Book, error), error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, &ValidationError{Message: "csv file is empty"}
+	}
+	if err != nil {
+		return nil, &ValidationError{Message: "invalid csv header: " + err.Error()}
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.TrimPrefix(name, "\ufeff")
+		columns[strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "_", ""))] = i
+	}
+	for _, required := range []string{"title", "author", "releaseyear", "pages"} {
+		if _, ok := columns[required]; !ok {
+			return nil, &ValidationError{Message: "csv header must contain title, author, releaseYear and pages"}
+		}
+	}
+	idColumn, hasId := columns["id"]
+	isbnColumn, hasIsbn := columns["isbn"]
+	isbn10Column, hasIsbn10 := columns["isbn10"]
+
+	return func() (model.Book, error) {
+		record, err := reader.Read()
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return model.Book{}, &ValidationError{Message: err.Error()}
+		}
+		if err != nil {
+			return model.Book{}, err
+		}
+		if len(record) != len(header) {
+			return model.Book{}, &ValidationError{Message: fmt.Sprintf("expected %d fields, got %d", len(header), len(record))}
+		}
+
+		book := model.Book{Title: record[columns["title"]], Author: record[columns["author"]]}
+		if hasId {
+			if book.Id, err = atoiOrZero(record[idColumn]); err != nil {
+				return book, &ValidationError{Message: "id must be a number"}
+			}
+		}
+		if hasIsbn {
+			book.Isbn = strings.TrimSpace(record[isbnColumn])
+		}
+		if hasIsbn10 {
+			book.Isbn10 = strings.TrimSpace(record[isbn10Column])
+		}
+		// angka yang kosong dibiarkan 0 agar bisa dilengkapi dari sumber metadata
+		if book.ReleaseYear, err = atoiOrZero(record[columns["releaseyear"]]); err != nil {
+			return book, &ValidationError{Message: "releaseYear must be a number"}
+		}
+		if book.Pages, err = atoiOrZero(record[columns["pages"]]); err != nil {
+			return book, &ValidationError{Message: "pages must be a number"}
+		}
+
+		return book, nil
+	}, nil
+}
+
+func atoiOrZero(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// jsonBookReader membaca array JSON satu elemen setiap kali dipanggil, dengan
+// io.EOF setelah elemen terakhir.
+func jsonBookReader(r io.Reader) (func() (model.Book, error), error) {
+	decoder := json.NewDecoder(r)
+
+	token, err := decoder.Token()
+	if err != nil || token != json.Delim('[') {
+		return nil, &ValidationError{Message: "json body must be an array of books"}
+	}
+
+	return func() (model.Book, error) {
+		if !decoder.More() {
+			if _, err := decoder.Token(); err != nil {
+				return model.Book{}, err
+			}
+			return model.Book{}, io.EOF
+		}
+
+		var book model.Book
+		err := decoder.Decode(&book)
+
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return book, &ValidationError{Message: fmt.Sprintf("%s must be a %s", typeErr.Field, typeErr.Type)}
+		}
+
+		return book, err
+	}, nil
+}
+
+func NewBookImportUsecase(bookRepositori repositori.BookRepositori, metadataSource repositori.MetadataSource) BookImportUsecase {
+	return &bookImportUsecase{bookRepositori: bookRepositori, metadataSource: metadataSource, batchSize: bookImportBatchSize}
+}
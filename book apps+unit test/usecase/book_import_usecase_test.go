@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"simple-clean-architecture/model"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBookImportUsecase_ImportBooks_Csv(t *testing.T) {
+	repo := new(MockBookRepository)
+	usecase := NewBookImportUsecase(repo, nil).(*bookImportUsecase)
+	usecase.batchSize = 2
+
+	file := "ISBN,Title,Author,release_year,Pages\n" +
+		"978-0-13-419044-0,The Go Programming Language,Alan Donovan,2015,380\n" +
+		",Untitled Notes,Anonymous,2020,50\n" +
+		"9780134190441,Bad Checksum,Someone,2020,10\n" +
+		",Learning Go,Jon Bodner,2021,abc\n" +
+		",Concurrency in Go,Katherine Cox-Buday,2017,238\n"
+
+	repo.On("UpsertBooks", []model.Book{
+		{Isbn: "9780134190440", Isbn10: "0134190440", Title: "The Go Programming Language", Author: "Alan Donovan", ReleaseYear: 2015, Pages: 380},
+		{Title: "Untitled Notes", Author: "Anonymous", ReleaseYear: 2020, Pages: 50},
+	}, false, 1).Return([]model.BookUpsertResult{{Id: 4, Created: false}, {Id: 9, Created: true}}, nil).Once()
+	repo.On("UpsertBooks", []model.Book{
+		{Title: "Concurrency in Go", Author: "Katherine Cox-Buday", ReleaseYear: 2017, Pages: 238},
+	}, false, 1).Return([]model.BookUpsertResult{{Id: 10, Created: true}}, nil).Once()
+
+	report, err := usecase.ImportBooks(strings.NewReader(file), "csv", false, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, report.Total)
+	assert.Equal(t, 2, report.Created)
+	assert.Equal(t, 1, report.Updated)
+	assert.Equal(t, 2, report.Invalid)
+	assert.Equal(t, model.BookImportRow{Row: 1, Isbn: "9780134190440", Title: "The Go Programming Language", Status: model.ImportStatusUpdated, Id: 4}, report.Rows[0])
+	assert.Equal(t, model.ImportStatusInvalid, report.Rows[2].Status)
+	assert.Equal(t, "isbn must be a valid ISBN-10 or ISBN-13", report.Rows[2].Error)
+	assert.Equal(t, "pages must be a number", report.Rows[3].Error)
+	assert.Equal(t, 10, report.Rows[4].Id)
+	repo.AssertExpectations(t)
+}
+
+func TestBookImportUsecase_ImportBooks_CsvHeader(t *testing.T) {
+	usecase := NewBookImportUsecase(new(MockBookRepository), nil)
+
+	var validationErr *ValidationError
+	_, err := usecase.ImportBooks(strings.NewReader("isbn,title\n123,Book\n"), "csv", false, 1)
+	assert.ErrorAs(t, err, &validationErr)
+
+	_, err = usecase.ImportBooks(strings.NewReader(""), "csv", false, 1)
+	assert.ErrorAs(t, err, &validationErr)
+
+	_, err = usecase.ImportBooks(strings.NewReader("[]"), "xml", false, 1)
+	assert.ErrorAs(t, err, &validationErr)
+}
+
+func TestBookImportUsecase_ImportBooks_JsonDryRun(t *testing.T) {
+	repo := new(MockBookRepository)
+	usecase := NewBookImportUsecase(repo, nil)
+
+	body := `[
+		{"isbn": "0-8044-2957-X", "title": "Book A", "author": "Author A", "releaseYear": 1999, "pages": 120},
+		{"title": "Book B", "author": "Author B", "releaseYear": "2001", "pages": 90},
+		{"title": "", "author": "Author C", "releaseYear": 2001, "pages": 90}
+	]`
+	repo.On("UpsertBooks", []model.Book{{Isbn: "9780804429573", Isbn10: "080442957X", Title: "Book A", Author: "Author A", ReleaseYear: 1999, Pages: 120}}, true, 1).
+		Return([]model.BookUpsertResult{{Id: 12, Created: true}}, nil).Once()
+
+	report, err := usecase.ImportBooks(strings.NewReader(body), "json", true, 1)
+	assert.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 3, report.Total)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 2, report.Invalid)
+	// id tidak dilaporkan pada dry run karena transaksi di-rollback
+	assert.Equal(t, 0, report.Rows[0].Id)
+	assert.Equal(t, "releaseYear must be a int", report.Rows[1].Error)
+	assert.Equal(t, "title cannot be empty", report.Rows[2].Error)
+	repo.AssertExpectations(t)
+}
+
+func TestBookImportUsecase_ImportBooks_Failures(t *testing.T) {
+	repo := new(MockBookRepository)
+	usecase := NewBookImportUsecase(repo, nil)
+
+	// Sad Path: batch gagal disimpan, dan JSON rusak di tengah file
+	body := `[{"title": "Book A", "author": "Author A", "releaseYear": 1999, "pages": 120}, {"title": `
+	repo.On("UpsertBooks", mock.Anything, false, 1).Return(nil, errors.New("connection refused")).Once()
+
+	report, err := usecase.ImportBooks(strings.NewReader(body), "json", false, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Total)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, "connection refused", report.Rows[0].Error)
+	assert.Equal(t, model.ImportStatusInvalid, report.Rows[1].Status)
+
+	var validationErr *ValidationError
+	_, err = usecase.ImportBooks(strings.NewReader(`{"title": "Book"}`), "json", false, 1)
+	assert.ErrorAs(t, err, &validationErr)
+
+	// Sad Path: satu baris ditolak database, baris lain dalam batch tetap tersimpan
+	body = `[{"title": "Book A", "author": "Author A", "releaseYear": 1999, "pages": 120}, {"title": "Book B", "author": "Author B", "releaseYear": 2001, "pages": 90}]`
+	repo.On("UpsertBooks", mock.Anything, false, 1).
+		Return([]model.BookUpsertResult{{Error: errors.New("value too long")}, {Id: 7, Created: true}}, nil).Once()
+
+	report, err = usecase.ImportBooks(strings.NewReader(body), "json", false, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, model.BookImportRow{Row: 1, Title: "Book A", Status: model.ImportStatusFailed, Error: "value too long"}, report.Rows[0])
+	assert.Equal(t, 7, report.Rows[1].Id)
+
+	// Sad Path: title melebihi panjang kolom ditolak sebelum sampai ke database
+	body = `[{"title": "` + strings.Repeat("a", 256) + `", "author": "Author A", "releaseYear": 1999, "pages": 120}]`
+	report, err = usecase.ImportBooks(strings.NewReader(body), "json", false, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Invalid)
+	assert.Equal(t, "title and author cannot be longer than 255 characters", report.Rows[0].Error)
+	repo.AssertExpectations(t)
+}
+
+func TestBookImportUsecase_ImportBooks_Enriched(t *testing.T) {
+	repo := new(MockBookRepository)
+	source := new(MockMetadataSource)
+	usecase := NewBookImportUsecase(repo, source)
+
+	// Happy Path: baris yang hanya berisi isbn dilengkapi dari sumber metadata
+	file := "isbn10,title,author,releaseYear,pages\n" +
+		"0134190440,,,,\n" +
+		"080442957X,,,,\n"
+	source.On("LookupIsbn", "9780134190440").Return(model.BookMetadata{Title: "The Go Programming Language", Author: "Alan Donovan", ReleaseYear: 2015, Pages: 380}, nil)
+	// Sad Path: sumber metadata gagal dibaca
+	source.On("LookupIsbn", "9780804429573").Return(model.BookMetadata{}, errors.New("read error"))
+	repo.On("UpsertBooks", []model.Book{
+		{Isbn: "9780134190440", Isbn10: "0134190440", Title: "The Go Programming Language", Author: "Alan Donovan", ReleaseYear: 2015, Pages: 380},
+	}, false, 1).Return([]model.BookUpsertResult{{Id: 4, Created: true}}, nil).Once()
+
+	report, err := usecase.ImportBooks(strings.NewReader(file), "csv", false, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, model.BookImportRow{Row: 2, Isbn: "080442957X", Status: model.ImportStatusFailed, Error: "read error"}, report.Rows[1])
+	repo.AssertExpectations(t)
+}
+
+func TestBookImportUsecase_ExportBooks(t *testing.T) {
+	repo := new(MockBookRepository)
+	usecase := NewBookImportUsecase(repo, nil)
+	books := []model.Book{
+		{Id: 1, Isbn: "9780134190440", Title: "The Go Programming Language", Author: "Alan Donovan", ReleaseYear: 2015, Pages: 380},
+		{Id: 2, Title: "Notes, Volume 1", Author: "Anonymous", ReleaseYear: 2020, Pages: 50},
+	}
+	repo.On("StreamBooks").Return(books, nil)
+
+	var csvOut bytes.Buffer
+	err := usecase.ExportBooks(&csvOut, "csv")
+	assert.NoError(t, err)
+	assert.Equal(t, "id,isbn,title,author,releaseYear,pages\n"+
+		"1,9780134190440,The Go Programming Language,Alan Donovan,2015,380\n"+
+		"2,,\"Notes, Volume 1\",Anonymous,2020,50\n", csvOut.String())
+
+	var jsonOut bytes.Buffer
+	err = usecase.ExportBooks(&jsonOut, "json")
+	assert.NoError(t, err)
+	var exported []model.Book
+	assert.NoError(t, json.Unmarshal(jsonOut.Bytes(), &exported))
+	assert.Equal(t, books, exported)
+
+	// hasil export bisa di-import kembali, buku tanpa isbn dicocokkan dengan id
+	repo.On("UpsertBooks", []model.Book{
+		{Id: 1, Isbn: "9780134190440", Isbn10: "0134190440", Title: "The Go Programming Language", Author: "Alan Donovan", ReleaseYear: 2015, Pages: 380},
+		{Id: 2, Title: "Notes, Volume 1", Author: "Anonymous", ReleaseYear: 2020, Pages: 50},
+	}, true, 1).Return([]model.BookUpsertResult{{Id: 1}, {Id: 2}}, nil).Once()
+	report, err := usecase.ImportBooks(&csvOut, "csv", true, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Updated)
+}
+
+func TestBookImportUsecase_ExportBooks_Empty(t *testing.T) {
+	repo := new(MockBookRepository)
+	repo.On("StreamBooks").Return([]model.Book{}, nil)
+
+	var out bytes.Buffer
+	err := NewBookImportUsecase(repo, nil).ExportBooks(&out, "json")
+	assert.NoError(t, err)
+	assert.Equal(t, "[]", out.String())
+}
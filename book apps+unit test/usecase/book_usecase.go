@@ -8,25 +8,70 @@
 package usecase
 
 import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"simple-clean-architecture/model"
 	"simple-clean-architecture/repositori"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
 
+const (
+	defaultBookLimit = 10
+	maxBookLimit     = 100
+	// maxBookTextLength adalah panjang kolom VARCHAR(255) title dan author
+	maxBookTextLength = 255
 )
 
+// bookSortFields berisi field yang boleh dipakai untuk sort, bernilai true
+// jika field tersebut berupa angka.
+var bookSortFields = map[string]bool{
+	"id":          true,
+	"title":       false,
+	"author":      false,
+	"releaseYear": true,
+	"pages":       true,
+}
+
 type bookUsecase struct {
-	bookRepositori repositori.BookRepositori
+	bookRepositori     repositori.BookRepositori
+	copyRepositori     repositori.BookCopyRepositori
+	authorRepositori   repositori.AuthorRepositori
+	categoryRepositori repositori.CategoryRepositori
+	metadataSource     repositori.MetadataSource
 }
 
+// BookUsecase menerima userId untuk setiap perubahan buku, yaitu user yang
+// dicatat di riwayat buku.
 type BookUsecase interface {
-	CreateNewBook(book model.Book) (model.Book, error)
-	GetAllBook() ([]model.Book, error)
-	GetBookById(id int) (model.Book, error)
-	UpdateBook(book *model.Book) (model.Book, error)
-	DeleteBook(id int) error
+	CreateNewBook(book model.Book, userId int) (model.Book, error)
+	GetAllBook(filter model.BookFilter) (model.BookPage, error)
+	GetBookById(id int) (model.BookDetail, error)
+	UpdateBook(book *model.Book, userId int) (model.Book, error)
+	DeleteBook(id int, userId int) error
+	RestoreBook(id int, userId int) (model.Book, error)
+	GetBookHistory(id int) ([]model.BookHistoryEntry, error)
+	LookupMetadata(isbn string) (model.BookMetadata, error)
 }
 
-func (b *bookUsecase) CreateNewBook(book model.Book) (model.Book, error) {
-	book, err := b.bookRepositori.CreateNewBook(book)
+// CreateNewBook melengkapi field yang kosong dari sumber metadata sebelum
+// validasi, sehingga client cukup mengirim isbn untuk buku yang dikenal.
+func (b *bookUsecase) CreateNewBook(book model.Book, userId int) (model.Book, error) {
+	if err := enrichBook(b.metadataSource, &book); err != nil {
+		return model.Book{}, err
+	}
+	if err := validateBook(&book); err != nil {
+		return model.Book{}, err
+	}
+	if err := b.checkIsbnAvailable(book.Isbn, 0); err != nil {
+		return model.Book{}, err
+	}
+
+	book, err := b.bookRepositori.CreateNewBook(book, userId)
 
 	if err != nil {
 		return model.Book{}, err
@@ -35,29 +80,201 @@ func (b *bookUsecase) CreateNewBook(book model.Book) (model.Book, error) {
 	return book, nil
 }
 
-func (b *bookUsecase) GetAllBook() ([]model.Book, error) {
-	books, err := b.bookRepositori.GetAllBook()
+// GetAllBook mendukung dua jenis pagination: page untuk offset biasa, atau
+// cursor dari nextCursor halaman sebelumnya yang tetap konsisten walaupun ada
+// buku yang ditambah atau dihapus di antara dua request.
+func (b *bookUsecase) GetAllBook(filter model.BookFilter) (model.BookPage, error) {
+	if err := validateBookFilter(&filter); err != nil {
+		return model.BookPage{}, err
+	}
+
+	limit := filter.Limit
+	// satu buku tambahan diambil untuk mengetahui apakah masih ada halaman berikutnya
+	filter.Limit = limit + 1
+	if filter.After == nil {
+		filter.Offset = (filter.Page - 1) * limit
+	}
+
+	books, total, err := b.bookRepositori.GetAllBook(filter)
 
 	if err != nil {
-		return nil, err
+		return model.BookPage{}, err
 	}
 
-	return books, nil
+	page := model.BookPage{Data: books, Total: total, Page: filter.Page, Limit: limit}
+	if len(books) > limit {
+		page.Data = books[:limit]
+		page.NextCursor = encodeBookCursor(filter.Sort, filter.Order, page.Data[limit-1])
+	}
+
+	return page, nil
 }
 
-func (b *bookUsecase) GetBookById(id int) (model.Book, error) {
+func validateBookFilter(filter *model.BookFilter) error {
+	if filter.MinYear < 0 || filter.MaxYear < 0 || filter.MinPages < 0 || filter.MaxPages < 0 {
+		return &ValidationError{Message: "minYear, maxYear, minPages and maxPages cannot be negative"}
+	}
+	if filter.AuthorId < 0 || filter.CategoryId < 0 {
+		return &ValidationError{Message: "authorId and categoryId cannot be negative"}
+	}
+	if filter.MaxYear != 0 && filter.MinYear > filter.MaxYear {
+		return &ValidationError{Message: "minYear cannot be greater than maxYear"}
+	}
+	if filter.MaxPages != 0 && filter.MinPages > filter.MaxPages {
+		return &ValidationError{Message: "minPages cannot be greater than maxPages"}
+	}
+
+	if filter.Sort == "" {
+		filter.Sort = "id"
+	}
+	if _, ok := bookSortFields[filter.Sort]; !ok {
+		return &ValidationError{Message: "sort must be one of id, title, author, releaseYear or pages"}
+	}
+	if filter.Order == "" {
+		filter.Order = "asc"
+	}
+	if filter.Order != "asc" && filter.Order != "desc" {
+		return &ValidationError{Message: "order must be asc or desc"}
+	}
+
+	if filter.Limit < 0 || filter.Page < 0 {
+		return &ValidationError{Message: "page and limit cannot be negative"}
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultBookLimit
+	}
+	if filter.Limit > maxBookLimit {
+		return &ValidationError{Message: "limit cannot be greater than 100"}
+	}
+
+	if filter.Cursor == "" {
+		if filter.Page == 0 {
+			filter.Page = 1
+		}
+		return nil
+	}
+	if filter.Page != 0 {
+		return &ValidationError{Message: "page and cursor cannot be used together"}
+	}
+	cursor, err := decodeBookCursor(filter.Cursor)
+	if err != nil {
+		return err
+	}
+	if cursor.Sort != filter.Sort || cursor.Order != filter.Order {
+		return &ValidationError{Message: "cursor was created for a different sort or order"}
+	}
+	filter.After = &cursor
+
+	return nil
+}
+
+func encodeBookCursor(sort, order string, last model.Book) string {
+	values := map[string]interface{}{
+		"id":          last.Id,
+		"title":       last.Title,
+		"author":      last.Author,
+		"releaseYear": last.ReleaseYear,
+		"pages":       last.Pages,
+	}
+	cursor, _ := json.Marshal(model.BookCursor{Sort: sort, Order: order, Value: values[sort], Id: last.Id})
+
+	return base64.RawURLEncoding.EncodeToString(cursor)
+}
+
+// decodeBookCursor mengembalikan Value dengan tipe yang sesuai kolomnya,
+// karena json.Unmarshal membaca semua angka sebagai float64.
+func decodeBookCursor(value string) (model.BookCursor, error) {
+	invalid := &ValidationError{Message: "invalid cursor"}
+
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return model.BookCursor{}, invalid
+	}
+	var cursor model.BookCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return model.BookCursor{}, invalid
+	}
+
+	numeric, ok := bookSortFields[cursor.Sort]
+	if !ok {
+		return model.BookCursor{}, invalid
+	}
+	switch v := cursor.Value.(type) {
+	case float64:
+		if !numeric {
+			return model.BookCursor{}, invalid
+		}
+		cursor.Value = int(v)
+	case string:
+		if numeric {
+			return model.BookCursor{}, invalid
+		}
+	default:
+		return model.BookCursor{}, invalid
+	}
+
+	return cursor, nil
+}
+
+// GetBookById mengembalikan buku beserta author, category, dan jumlah
+// eksemplar per status.
+func (b *bookUsecase) GetBookById(id int) (model.BookDetail, error) {
 	book, err := b.bookRepositori.GetBookById(id)
 
 	if err != nil {
-		return model.Book{}, err
+		return model.BookDetail{}, notFound(err, "book not found")
 	}
 
-	return book, nil
+	authors, err := b.authorRepositori.GetAuthorsByBookId(id)
+
+	if err != nil {
+		return model.BookDetail{}, err
+	}
+
+	categories, err := b.categoryRepositori.GetCategoriesByBookId(id)
+
+	if err != nil {
+		return model.BookDetail{}, err
+	}
+
+	availability, err := b.copyRepositori.GetAvailability(id)
+
+	if err != nil {
+		return model.BookDetail{}, err
+	}
+
+	return model.BookDetail{Book: book, Authors: authors, Categories: categories, Availability: availability}, nil
 }
 
-func (b *bookUsecase) UpdateBook(book *model.Book) (model.Book, error) {
-	updatedBook, err := b.bookRepositori.UpdateBook(book)
+// UpdateBook hanya menyimpan perubahan jika book.Version sama dengan version
+// buku saat ini, agar perubahan dari librarian lain tidak tertimpa. Version 0
+// berarti perubahan disimpan tanpa melihat version (If-Match: *).
+func (b *bookUsecase) UpdateBook(book *model.Book, userId int) (model.Book, error) {
+	if err := validateBook(book); err != nil {
+		return model.Book{}, err
+	}
+
+	current, err := b.bookRepositori.GetBookById(book.Id)
+
+	if err != nil {
+		return model.Book{}, notFound(err, "book not found")
+	}
+	if book.Version == 0 {
+		book.Version = current.Version
+	}
+	if book.Version != current.Version {
+		return model.Book{}, &PreconditionFailedError{Message: "book has been modified, reload it and try again"}
+	}
+	if err := b.checkIsbnAvailable(book.Isbn, book.Id); err != nil {
+		return model.Book{}, err
+	}
+
+	updatedBook, err := b.bookRepositori.UpdateBook(book, userId)
 
+	// buku diubah atau dihapus orang lain di antara dua query di atas
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Book{}, &PreconditionFailedError{Message: "book has been modified, reload it and try again"}
+	}
 	if err != nil {
 		return model.Book{}, err
 	}
@@ -65,16 +282,226 @@ func (b *bookUsecase) UpdateBook(book *model.Book) (model.Book, error) {
 	return updatedBook, nil
 }
 
-func (b *bookUsecase) DeleteBook(id int) error {
-	err := b.bookRepositori.DeleteBook(id)
+// checkIsbnAvailable menolak isbn yang sudah dipakai buku selain bookId. Jika
+// pemiliknya buku yang sudah dihapus, client diarahkan untuk mengembalikan
+// buku tersebut.
+func (b *bookUsecase) checkIsbnAvailable(isbn string, bookId int) error {
+	if isbn == "" {
+		return nil
+	}
+
+	id, deleted, err := b.bookRepositori.GetBookIdByIsbn(isbn)
 
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && id == bookId) {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
+	if deleted {
+		return &ConflictError{Message: fmt.Sprintf("isbn is already used by deleted book %d, restore it instead", id)}
+	}
+
+	return &ConflictError{Message: fmt.Sprintf("isbn is already used by book %d", id)}
+}
+
+// DeleteBook menolak menghapus buku yang masih punya eksemplar dipinjam atau
+// menunggu diambil pemegang reservasi. Buku yang dihapus masih bisa
+// dikembalikan dengan RestoreBook.
+func (b *bookUsecase) DeleteBook(id int, userId int) error {
+	err := b.bookRepositori.DeleteBook(id, userId)
+
+	if errors.Is(err, repositori.ErrBookCopiesOnLoan) || errors.Is(err, repositori.ErrBookCopiesOnHold) {
+		return &ConflictError{Message: err.Error()}
+	}
+	if err != nil {
+		return notFound(err, "book not found")
+	}
 
 	return nil
 }	
 
-func NewBookUsecase(bookRepositori repositori.BookRepositori) BookUsecase {
-	return &bookUsecase{bookRepositori: bookRepositori}
+func (b *bookUsecase) RestoreBook(id int, userId int) (model.Book, error) {
+	book, err := b.bookRepositori.RestoreBook(id, userId)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := b.bookRepositori.GetBookById(id); err == nil {
+			return model.Book{}, &ConflictError{Message: "book is not deleted"}
+		}
+		return model.Book{}, &NotFoundError{Message: "book not found"}
+	}
+	if err != nil {
+		return model.Book{}, err
+	}
+
+	return book, nil
+}
+
+// GetBookHistory mengembalikan riwayat perubahan buku dari yang terbaru,
+// masing-masing dengan field yang berubah dibanding revisi sebelumnya.
+// Riwayat buku yang sudah dihapus tetap bisa dilihat.
+func (b *bookUsecase) GetBookHistory(id int) ([]model.BookHistoryEntry, error) {
+	revisions, err := b.bookRepositori.GetBookRevisions(id)
+
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, &NotFoundError{Message: "book not found"}
+	}
+
+	history := make([]model.BookHistoryEntry, len(revisions))
+	var previous *model.BookRevision
+	for i := range revisions {
+		revision := revisions[i]
+		// riwayat diurutkan dari yang terbaru
+		history[len(revisions)-1-i] = model.BookHistoryEntry{
+			Version:   revision.Book.Version,
+			Action:    revision.Action,
+			UserId:    revision.UserId,
+			Username:  revision.Username,
+			ChangedAt: revision.ChangedAt,
+			Changes:   diffBookRevisions(previous, revision),
+		}
+		previous = &revisions[i]
+	}
+
+	return history, nil
+}
+
+// diffBookRevisions membandingkan setiap field yang bisa diubah. Jika previous
+// nil, semua field yang terisi dianggap berubah dari null.
+func diffBookRevisions(previous *model.BookRevision, current model.BookRevision) []model.BookFieldChange {
+	type field struct {
+		name          string
+		before, after interface{}
+	}
+	var last model.BookRevision
+	if previous != nil {
+		last = *previous
+	}
+	fields := []field{
+		{"title", last.Book.Title, current.Book.Title},
+		{"author", last.Book.Author, current.Book.Author},
+		{"releaseYear", last.Book.ReleaseYear, current.Book.ReleaseYear},
+		{"pages", last.Book.Pages, current.Book.Pages},
+		{"isbn", last.Book.Isbn, current.Book.Isbn},
+		{"isbn10", last.Book.Isbn10, current.Book.Isbn10},
+		{"deletedAt", last.DeletedAt, current.DeletedAt},
+	}
+
+	changes := []model.BookFieldChange{}
+	for _, f := range fields {
+		before, after := emptyToNil(f.before), emptyToNil(f.after)
+		if before != after {
+			changes = append(changes, model.BookFieldChange{Field: f.name, Old: before, New: after})
+		}
+	}
+
+	return changes
+}
+
+// emptyToNil mengubah nilai kosong menjadi nil agar field yang tidak diisi
+// tampil sebagai null dan dua waktu dibandingkan berdasarkan nilainya.
+func emptyToNil(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+	case int:
+		if v == 0 {
+			return nil
+		}
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return v.UTC()
+	}
+	return value
+}
+
+// LookupMetadata mengembalikan data buku dari sumber metadata untuk satu ISBN
+// tanpa menyimpannya.
+func (b *bookUsecase) LookupMetadata(isbn string) (model.BookMetadata, error) {
+	isbn, err := normalizeIsbn(isbn)
+	if err != nil {
+		return model.BookMetadata{}, err
+	}
+	if b.metadataSource == nil {
+		return model.BookMetadata{}, &NotFoundError{Message: "metadata not found"}
+	}
+
+	metadata, err := lookupMetadata(b.metadataSource, isbn)
+	if errors.Is(err, repositori.ErrMetadataNotFound) {
+		return model.BookMetadata{}, &NotFoundError{Message: "metadata not found"}
+	}
+	if err != nil {
+		return model.BookMetadata{}, err
+	}
+
+	return metadata, nil
+}
+
+// validateBook merapikan dan memeriksa field buku yang diisi client. Isbn
+// boleh diisi dalam bentuk ISBN-10 atau ISBN-13 dan selalu disimpan sebagai
+// ISBN-13, Isbn10 diisi ulang dari hasil konversinya.
+func validateBook(book *model.Book) error {
+	book.Title = strings.TrimSpace(book.Title)
+	book.Author = strings.TrimSpace(book.Author)
+	if book.Title == "" {
+		return &ValidationError{Message: "title cannot be empty"}
+	}
+	if book.Author == "" {
+		return &ValidationError{Message: "author cannot be empty"}
+	}
+	if utf8.RuneCountInString(book.Title) > maxBookTextLength || utf8.RuneCountInString(book.Author) > maxBookTextLength {
+		return &ValidationError{Message: "title and author cannot be longer than 255 characters"}
+	}
+	if book.ReleaseYear <= 0 || book.Pages <= 0 {
+		return &ValidationError{Message: "releaseYear and pages must be positive"}
+	}
+	if book.Isbn == "" && book.Isbn10 == "" {
+		return nil
+	}
+
+	isbn, err := normalizeIsbn(firstNonEmpty(book.Isbn, book.Isbn10))
+	if err != nil {
+		return err
+	}
+	if book.Isbn != "" && book.Isbn10 != "" {
+		isbn10, err := normalizeIsbn(book.Isbn10)
+		if err != nil {
+			return err
+		}
+		if isbn10 != isbn {
+			return &ValidationError{Message: "isbn and isbn10 refer to different books"}
+		}
+	}
+	book.Isbn = isbn
+	book.Isbn10 = isbn13To10(isbn)
+
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// NewBookUsecase menerima metadataSource nil jika pencarian metadata tidak
+// dipakai.
+func NewBookUsecase(bookRepositori repositori.BookRepositori, copyRepositori repositori.BookCopyRepositori, authorRepositori repositori.AuthorRepositori, categoryRepositori repositori.CategoryRepositori, metadataSource repositori.MetadataSource) BookUsecase {
+	return &bookUsecase{
+		bookRepositori:     bookRepositori,
+		copyRepositori:     copyRepositori,
+		authorRepositori:   authorRepositori,
+		categoryRepositori: categoryRepositori,
+		metadataSource:     metadataSource,
+	}
 }
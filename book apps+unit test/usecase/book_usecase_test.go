@@ -1,8 +1,11 @@
 package usecase
 
 import (
+	"database/sql"
 	"simple-clean-architecture/model"
+	"simple-clean-architecture/repositori"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -12,14 +15,14 @@ type MockBookRepository struct {
 	mock.Mock
 }
 
-func (m *MockBookRepository) CreateNewBook(book model.Book) (model.Book, error) {
-	args := m.Called(book)
+func (m *MockBookRepository) CreateNewBook(book model.Book, userId int) (model.Book, error) {
+	args := m.Called(book, userId)
 	return args.Get(0).(model.Book), args.Error(1)
 }
 
-func (m *MockBookRepository) GetAllBook() ([]model.Book, error) {
-	args := m.Called()
-	return args.Get(0).([]model.Book), args.Error(1)
+func (m *MockBookRepository) GetAllBook(filter model.BookFilter) ([]model.Book, int, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]model.Book), args.Int(1), args.Error(2)
 }
 
 func (m *MockBookRepository) GetBookById(id int) (model.Book, error) {
@@ -27,25 +30,64 @@ func (m *MockBookRepository) GetBookById(id int) (model.Book, error) {
 	return args.Get(0).(model.Book), args.Error(1)
 }
 
-func (m *MockBookRepository) UpdateBook(book *model.Book) (model.Book, error) {
-	args := m.Called(book)
+func (m *MockBookRepository) GetBookIdByIsbn(isbn string) (int, bool, error) {
+	args := m.Called(isbn)
+	return args.Int(0), args.Bool(1), args.Error(2)
+}
+
+func (m *MockBookRepository) UpdateBook(book *model.Book, userId int) (model.Book, error) {
+	args := m.Called(book, userId)
 	return args.Get(0).(model.Book), args.Error(1)
 }
 
-func (m *MockBookRepository) DeleteBook(id int) error {
-	args := m.Called(id)
+func (m *MockBookRepository) DeleteBook(id int, userId int) error {
+	args := m.Called(id, userId)
 	return args.Error(0)
 }
 
+func (m *MockBookRepository) RestoreBook(id int, userId int) (model.Book, error) {
+	args := m.Called(id, userId)
+	return args.Get(0).(model.Book), args.Error(1)
+}
+
+func (m *MockBookRepository) UpsertBooks(books []model.Book, dryRun bool, userId int) ([]model.BookUpsertResult, error) {
+	args := m.Called(books, dryRun, userId)
+	results, _ := args.Get(0).([]model.BookUpsertResult)
+	return results, args.Error(1)
+}
+
+// StreamBooks memanggil fn untuk setiap buku yang diberikan lewat
+// Return(books, err).
+func (m *MockBookRepository) StreamBooks(fn func(book model.Book) error) error {
+	args := m.Called()
+	for _, book := range args.Get(0).([]model.Book) {
+		if err := fn(book); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockBookRepository) GetBookRevisions(bookId int) ([]model.BookRevision, error) {
+	args := m.Called(bookId)
+	return args.Get(0).([]model.BookRevision), args.Error(1)
+}
+
 func TestBookUsecase(t *testing.T) {
 	repo := new(MockBookRepository)
-	repo.On("CreateNewBook", mock.Anything).Return(model.Book{}, nil)
-	repo.On("GetAllBook").Return([]model.Book{}, nil)
+	repo.On("CreateNewBook", mock.Anything, 1).Return(model.Book{}, nil)
+	repo.On("GetAllBook", mock.Anything).Return([]model.Book{}, 0, nil)
 	repo.On("GetBookById", mock.Anything).Return(model.Book{}, nil)
-	repo.On("UpdateBook", mock.Anything).Return(model.Book{}, nil)
-	repo.On("DeleteBook", mock.Anything).Return(nil)
+	repo.On("UpdateBook", mock.Anything, 1).Return(model.Book{}, nil)
+	repo.On("DeleteBook", mock.Anything, 1).Return(nil)
+	copyRepo := new(MockBookCopyRepository)
+	copyRepo.On("GetAvailability", 1).Return(model.BookAvailability{Total: 2, Available: 2}, nil)
+	authorRepo := new(MockAuthorRepository)
+	authorRepo.On("GetAuthorsByBookId", 1).Return([]model.Author{{Id: 3, Name: "Test Author", BookCount: 1}}, nil)
+	categoryRepo := new(MockCategoryRepository)
+	categoryRepo.On("GetCategoriesByBookId", 1).Return([]model.Category{}, nil)
 
-	usecase := NewBookUsecase(repo)
+	usecase := NewBookUsecase(repo, copyRepo, authorRepo, categoryRepo, nil)
 
 	book := model.Book{
 		Title:       "Test Book",
@@ -54,25 +96,304 @@ func TestBookUsecase(t *testing.T) {
 		Pages:       100,
 	}
 
-	createdBook, err := usecase.CreateNewBook(book)
+	createdBook, err := usecase.CreateNewBook(book, 1)
 	assert.NoError(t, err)
 	assert.NotNil(t, createdBook)
 
-	books, err := usecase.GetAllBook()
+	books, err := usecase.GetAllBook(model.BookFilter{})
 	assert.NoError(t, err)
 	assert.NotNil(t, books)
 
 	bookById, err := usecase.GetBookById(1)
 	assert.NoError(t, err)
-	assert.NotNil(t, bookById)
+	assert.Equal(t, 2, bookById.Availability.Available)
+	assert.Equal(t, []model.Author{{Id: 3, Name: "Test Author", BookCount: 1}}, bookById.Authors)
+	assert.Equal(t, []model.Category{}, bookById.Categories)
 
 	// validation if Pages is 100
 	assert.Equal(t, 100, book.Pages)
 
-	updatedBook, err := usecase.UpdateBook(&book)
+	updatedBook, err := usecase.UpdateBook(&book, 1)
 	assert.NoError(t, err)
 	assert.NotNil(t, updatedBook)
 
-	err = usecase.DeleteBook(1)
+	err = usecase.DeleteBook(1, 1)
+	assert.NoError(t, err)
+}
+
+func TestBookUsecase_UpdateBook_Version(t *testing.T) {
+	repo := new(MockBookRepository)
+	repo.On("GetBookById", 1).Return(model.Book{Id: 1, Version: 3}, nil)
+	repo.On("GetBookById", 2).Return(model.Book{}, sql.ErrNoRows)
+	usecase := NewBookUsecase(repo, new(MockBookCopyRepository), new(MockAuthorRepository), new(MockCategoryRepository), nil)
+
+	// Happy Path
+	book := model.Book{Id: 1, Title: "Test Book", Author: "Test Author", ReleaseYear: 2023, Pages: 100, Version: 3}
+	repo.On("UpdateBook", &book, 1).Return(model.Book{Id: 1, Version: 4}, nil).Once()
+
+	updatedBook, err := usecase.UpdateBook(&book, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, updatedBook.Version)
+
+	// Happy Path: version 0 (If-Match: *) memakai version saat ini
+	book = model.Book{Id: 1, Title: "Test Book", Author: "Test Author", ReleaseYear: 2023, Pages: 100}
+	repo.On("UpdateBook", mock.MatchedBy(func(book *model.Book) bool { return book.Version == 3 }), 1).Return(model.Book{Id: 1, Version: 4}, nil).Once()
+
+	_, err = usecase.UpdateBook(&book, 1)
+	assert.NoError(t, err)
+
+	// Sad Path: version sudah berubah
+	var preconditionErr *PreconditionFailedError
+	_, err = usecase.UpdateBook(&model.Book{Id: 1, Title: "Test Book", Author: "Test Author", ReleaseYear: 2023, Pages: 100, Version: 2}, 1)
+	assert.ErrorAs(t, err, &preconditionErr)
+
+	// Sad Path: buku diubah orang lain di antara pengecekan dan update
+	book = model.Book{Id: 1, Title: "Race", Author: "Test Author", ReleaseYear: 2023, Pages: 100, Version: 3}
+	repo.On("UpdateBook", &book, 1).Return(model.Book{}, sql.ErrNoRows).Once()
+
+	_, err = usecase.UpdateBook(&book, 1)
+	assert.ErrorAs(t, err, &preconditionErr)
+
+	// Sad Path: buku tidak ada
+	var notFoundErr *NotFoundError
+	_, err = usecase.UpdateBook(&model.Book{Id: 2, Title: "Test Book", Author: "Test Author", ReleaseYear: 2023, Pages: 100, Version: 1}, 1)
+	assert.ErrorAs(t, err, &notFoundErr)
+	repo.AssertExpectations(t)
+}
+
+func TestBookUsecase_CreateNewBook_Invalid(t *testing.T) {
+	repo := new(MockBookRepository)
+	usecase := NewBookUsecase(repo, new(MockBookCopyRepository), new(MockAuthorRepository), new(MockCategoryRepository), nil)
+
+	var validationErr *ValidationError
+	_, err := usecase.CreateNewBook(model.Book{Title: " ", Author: "Author", ReleaseYear: 2020, Pages: 10}, 0)
+	assert.ErrorAs(t, err, &validationErr)
+
+	_, err = usecase.CreateNewBook(model.Book{Title: "Title", Author: "Author", ReleaseYear: 2020, Pages: 10, Isbn: "978-0-13-419044-1"}, 0)
+	assert.ErrorAs(t, err, &validationErr)
+
+	// Sad Path: isbn dan isbn10 untuk buku yang berbeda
+	_, err = usecase.CreateNewBook(model.Book{Title: "Title", Author: "Author", ReleaseYear: 2020, Pages: 10, Isbn: "9780134190440", Isbn10: "080442957X"}, 0)
+	assert.ErrorAs(t, err, &validationErr)
+
+	repo.AssertNotCalled(t, "CreateNewBook", mock.Anything, mock.Anything)
+}
+
+func TestBookUsecase_DuplicateIsbn(t *testing.T) {
+	repo := new(MockBookRepository)
+	repo.On("GetBookIdByIsbn", "9780134190440").Return(4, false, nil)
+	repo.On("GetBookIdByIsbn", "9780804429573").Return(5, true, nil)
+	repo.On("GetBookById", 1).Return(model.Book{Id: 1, Version: 3}, nil)
+	repo.On("GetBookById", 4).Return(model.Book{Id: 4, Version: 2}, nil)
+	usecase := NewBookUsecase(repo, new(MockBookCopyRepository), new(MockAuthorRepository), new(MockCategoryRepository), nil)
+
+	// Sad Path: isbn sudah dipakai buku lain
+	var conflictErr *ConflictError
+	_, err := usecase.CreateNewBook(model.Book{Title: "Title", Author: "Author", ReleaseYear: 2020, Pages: 10, Isbn: "978-0-13-419044-0"}, 1)
+	assert.ErrorAs(t, err, &conflictErr)
+	assert.EqualError(t, err, "isbn is already used by book 4")
+
+	// Sad Path: isbn dipakai buku yang sudah dihapus
+	_, err = usecase.CreateNewBook(model.Book{Title: "Title", Author: "Author", ReleaseYear: 2020, Pages: 10, Isbn10: "080442957X"}, 1)
+	assert.ErrorAs(t, err, &conflictErr)
+	assert.EqualError(t, err, "isbn is already used by deleted book 5, restore it instead")
+
+	_, err = usecase.UpdateBook(&model.Book{Id: 1, Title: "Title", Author: "Author", ReleaseYear: 2020, Pages: 10, Isbn: "9780134190440"}, 1)
+	assert.ErrorAs(t, err, &conflictErr)
+	repo.AssertNotCalled(t, "CreateNewBook", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "UpdateBook", mock.Anything, mock.Anything)
+
+	// Happy Path: buku tetap boleh disimpan dengan isbn miliknya sendiri
+	book := model.Book{Id: 4, Title: "Title", Author: "Author", ReleaseYear: 2020, Pages: 10, Isbn: "9780134190440"}
+	repo.On("UpdateBook", &book, 1).Return(model.Book{Id: 4, Version: 3}, nil).Once()
+
+	_, err = usecase.UpdateBook(&book, 1)
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestBookUsecase_GetBookById_NotFound(t *testing.T) {
+	repo := new(MockBookRepository)
+	repo.On("GetBookById", 9).Return(model.Book{}, sql.ErrNoRows)
+	copyRepo := new(MockBookCopyRepository)
+
+	_, err := NewBookUsecase(repo, copyRepo, new(MockAuthorRepository), new(MockCategoryRepository), nil).GetBookById(9)
+	var notFoundErr *NotFoundError
+	assert.ErrorAs(t, err, &notFoundErr)
+	copyRepo.AssertNotCalled(t, "GetAvailability", mock.Anything)
+}
+
+func TestBookUsecase_DeleteBook_CopiesOnLoan(t *testing.T) {
+	repo := new(MockBookRepository)
+	repo.On("DeleteBook", 1, 1).Return(repositori.ErrBookCopiesOnLoan).Once()
+	repo.On("DeleteBook", 2, 1).Return(repositori.ErrBookCopiesOnHold).Once()
+	usecase := NewBookUsecase(repo, new(MockBookCopyRepository), new(MockAuthorRepository), new(MockCategoryRepository), nil)
+
+	var conflictErr *ConflictError
+	err := usecase.DeleteBook(1, 1)
+	assert.ErrorAs(t, err, &conflictErr)
+	assert.EqualError(t, err, "book still has copies on loan")
+
+	err = usecase.DeleteBook(2, 1)
+	assert.ErrorAs(t, err, &conflictErr)
+	assert.EqualError(t, err, "book still has copies on hold")
+	repo.AssertExpectations(t)
+}
+
+func TestBookUsecase_DeleteBook_NotFound(t *testing.T) {
+	repo := new(MockBookRepository)
+	repo.On("DeleteBook", 9, 1).Return(sql.ErrNoRows)
+	copyRepo := new(MockBookCopyRepository)
+
+	err := NewBookUsecase(repo, copyRepo, new(MockAuthorRepository), new(MockCategoryRepository), nil).DeleteBook(9, 1)
+	var notFoundErr *NotFoundError
+	assert.ErrorAs(t, err, &notFoundErr)
+}
+
+func TestBookUsecase_RestoreBook(t *testing.T) {
+	repo := new(MockBookRepository)
+	usecase := NewBookUsecase(repo, new(MockBookCopyRepository), new(MockAuthorRepository), new(MockCategoryRepository), nil)
+
+	// Happy Path
+	repo.On("RestoreBook", 1, 1).Return(model.Book{Id: 1, Title: "Test Book", Version: 3}, nil).Once()
+
+	book, err := usecase.RestoreBook(1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, book.Version)
+
+	// Sad Path: buku tidak sedang dihapus
+	repo.On("RestoreBook", 2, 1).Return(model.Book{}, sql.ErrNoRows).Once()
+	repo.On("GetBookById", 2).Return(model.Book{Id: 2}, nil).Once()
+
+	var conflictErr *ConflictError
+	_, err = usecase.RestoreBook(2, 1)
+	assert.ErrorAs(t, err, &conflictErr)
+
+	// Sad Path: buku tidak ada
+	repo.On("RestoreBook", 9, 1).Return(model.Book{}, sql.ErrNoRows).Once()
+	repo.On("GetBookById", 9).Return(model.Book{}, sql.ErrNoRows).Once()
+
+	var notFoundErr *NotFoundError
+	_, err = usecase.RestoreBook(9, 1)
+	assert.ErrorAs(t, err, &notFoundErr)
+	repo.AssertExpectations(t)
+}
+
+func TestBookUsecase_GetBookHistory(t *testing.T) {
+	repo := new(MockBookRepository)
+	usecase := NewBookUsecase(repo, new(MockBookCopyRepository), new(MockAuthorRepository), new(MockCategoryRepository), nil)
+
+	created := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	deletedAt := created.Add(48 * time.Hour)
+	revisions := []model.BookRevision{
+		{Id: 1, Book: model.Book{Id: 1, Title: "Test Book", Author: "Test Author", ReleaseYear: 2023, Pages: 100, Version: 1}, Action: model.BookActionCreate, UserId: 1, Username: "librarian", ChangedAt: created},
+		{Id: 2, Book: model.Book{Id: 1, Title: "Test Book", Author: "Test Author", ReleaseYear: 2023, Pages: 120, Isbn: "9780134190440", Isbn10: "0134190440", Version: 2}, Action: model.BookActionUpdate, UserId: 2, Username: "other", ChangedAt: created.Add(time.Hour)},
+		{Id: 3, Book: model.Book{Id: 1, Title: "Test Book", Author: "Test Author", ReleaseYear: 2023, Pages: 120, Isbn: "9780134190440", Isbn10: "0134190440", Version: 3}, DeletedAt: &deletedAt, Action: model.BookActionDelete, UserId: 1, Username: "librarian", ChangedAt: deletedAt},
+	}
+
+	// Happy Path
+	repo.On("GetBookRevisions", 1).Return(revisions, nil).Once()
+
+	history, err := usecase.GetBookHistory(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.BookHistoryEntry{
+		{Version: 3, Action: model.BookActionDelete, UserId: 1, Username: "librarian", ChangedAt: deletedAt, Changes: []model.BookFieldChange{
+			{Field: "deletedAt", Old: nil, New: deletedAt},
+		}},
+		{Version: 2, Action: model.BookActionUpdate, UserId: 2, Username: "other", ChangedAt: created.Add(time.Hour), Changes: []model.BookFieldChange{
+			{Field: "pages", Old: 100, New: 120},
+			{Field: "isbn", Old: nil, New: "9780134190440"},
+			{Field: "isbn10", Old: nil, New: "0134190440"},
+		}},
+		{Version: 1, Action: model.BookActionCreate, UserId: 1, Username: "librarian", ChangedAt: created, Changes: []model.BookFieldChange{
+			{Field: "title", Old: nil, New: "Test Book"},
+			{Field: "author", Old: nil, New: "Test Author"},
+			{Field: "releaseYear", Old: nil, New: 2023},
+			{Field: "pages", Old: nil, New: 100},
+		}},
+	}, history)
+
+	// Sad Path: buku tidak punya riwayat
+	repo.On("GetBookRevisions", 9).Return([]model.BookRevision{}, nil).Once()
+
+	var notFoundErr *NotFoundError
+	_, err = usecase.GetBookHistory(9)
+	assert.ErrorAs(t, err, &notFoundErr)
+	repo.AssertExpectations(t)
+}
+
+func TestBookUsecase_GetAllBook_Defaults(t *testing.T) {
+	repo := new(MockBookRepository)
+	books := []model.Book{{Id: 1, Title: "Book 1"}, {Id: 2, Title: "Book 2"}}
+	repo.On("GetAllBook", model.BookFilter{Sort: "id", Order: "asc", Page: 1, Limit: 11}).Return(books, 2, nil).Once()
+
+	page, err := NewBookUsecase(repo, new(MockBookCopyRepository), new(MockAuthorRepository), new(MockCategoryRepository), nil).GetAllBook(model.BookFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, model.BookPage{Data: books, Total: 2, Page: 1, Limit: 10}, page)
+	repo.AssertExpectations(t)
+}
+
+func TestBookUsecase_GetAllBook_Offset(t *testing.T) {
+	repo := new(MockBookRepository)
+	repo.On("GetAllBook", model.BookFilter{Title: "go", Sort: "pages", Order: "desc", Page: 3, Limit: 6, Offset: 10}).Return([]model.Book{}, 10, nil).Once()
+
+	page, err := NewBookUsecase(repo, new(MockBookCopyRepository), new(MockAuthorRepository), new(MockCategoryRepository), nil).GetAllBook(model.BookFilter{Title: "go", Sort: "pages", Order: "desc", Page: 3, Limit: 5})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, page.Page)
+	assert.Empty(t, page.NextCursor)
+	repo.AssertExpectations(t)
+}
+
+func TestBookUsecase_GetAllBook_Cursor(t *testing.T) {
+	repo := new(MockBookRepository)
+	firstPage := []model.Book{{Id: 4, Title: "A", Pages: 120}, {Id: 9, Title: "B", Pages: 250}, {Id: 2, Title: "C", Pages: 300}}
+	repo.On("GetAllBook", model.BookFilter{Sort: "pages", Order: "asc", Page: 1, Limit: 3}).Return(firstPage, 5, nil).Once()
+	usecase := NewBookUsecase(repo, new(MockBookCopyRepository), new(MockAuthorRepository), new(MockCategoryRepository), nil)
+
+	// Happy Path: buku tambahan dari repository menandakan masih ada halaman berikutnya
+	page, err := usecase.GetAllBook(model.BookFilter{Sort: "pages", Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, firstPage[:2], page.Data)
+	assert.NotEmpty(t, page.NextCursor)
+
+	// Happy Path: cursor melanjutkan setelah buku terakhir
+	repo.On("GetAllBook", model.BookFilter{Sort: "pages", Order: "asc", Limit: 3, Cursor: page.NextCursor, After: &model.BookCursor{Sort: "pages", Order: "asc", Value: 250, Id: 9}}).
+		Return([]model.Book{{Id: 2, Title: "C", Pages: 300}}, 5, nil).Once()
+
+	next, err := usecase.GetAllBook(model.BookFilter{Sort: "pages", Limit: 2, Cursor: page.NextCursor})
 	assert.NoError(t, err)
-}
\ No newline at end of file
+	assert.Equal(t, 0, next.Page)
+	assert.Len(t, next.Data, 1)
+	assert.Empty(t, next.NextCursor)
+	repo.AssertExpectations(t)
+
+	// Sad Path: cursor dipakai dengan sort yang berbeda
+	_, err = usecase.GetAllBook(model.BookFilter{Sort: "title", Limit: 2, Cursor: page.NextCursor})
+	assert.Error(t, err)
+}
+
+func TestBookUsecase_GetAllBook_InvalidFilter(t *testing.T) {
+	repo := new(MockBookRepository)
+	usecase := NewBookUsecase(repo, new(MockBookCopyRepository), new(MockAuthorRepository), new(MockCategoryRepository), nil)
+
+	filters := []model.BookFilter{
+		{MinYear: -1},
+		{MinYear: 2020, MaxYear: 2000},
+		{MinPages: 500, MaxPages: 100},
+		{AuthorId: -1},
+		{Sort: "price"},
+		{Order: "up"},
+		{Limit: 101},
+		{Page: -1},
+		{Page: 2, Cursor: "abc"},
+		{Cursor: "not a cursor"},
+		{Cursor: "eyJzb3J0IjoiaWQiLCJvcmRlciI6ImFzYyIsInZhbHVlIjoiQm9vayIsImlkIjoxfQ"},
+	}
+	for _, filter := range filters {
+		_, err := usecase.GetAllBook(filter)
+
+		var validationErr *ValidationError
+		assert.ErrorAs(t, err, &validationErr, "filter %+v", filter)
+	}
+	repo.AssertNotCalled(t, "GetAllBook", mock.Anything)
+}
@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/repositori"
+	"strings"
+)
+
+type categoryUsecase struct {
+	categoryRepositori repositori.CategoryRepositori
+	bookRepositori     repositori.BookRepositori
+}
+
+type CategoryUsecase interface {
+	CreateCategory(category model.Category) (model.Category, error)
+	GetAllCategory() ([]model.Category, error)
+	GetCategoryById(id int) (model.Category, error)
+	UpdateCategory(category model.Category) (model.Category, error)
+	DeleteCategory(id int) error
+	SetBookCategories(bookId int, categoryIds []int) ([]model.Category, error)
+}
+
+func (c *categoryUsecase) CreateCategory(category model.Category) (model.Category, error) {
+	if err := c.validateCategory(&category); err != nil {
+		return model.Category{}, err
+	}
+
+	return c.categoryRepositori.CreateCategory(category)
+}
+
+func (c *categoryUsecase) GetAllCategory() ([]model.Category, error) {
+	return c.categoryRepositori.GetAllCategory()
+}
+
+func (c *categoryUsecase) GetCategoryById(id int) (model.Category, error) {
+	category, err := c.categoryRepositori.GetCategoryById(id)
+
+	if err != nil {
+		return model.Category{}, notFound(err, "category not found")
+	}
+
+	return category, nil
+}
+
+func (c *categoryUsecase) UpdateCategory(category model.Category) (model.Category, error) {
+	existing, err := c.GetCategoryById(category.Id)
+	if err != nil {
+		return model.Category{}, err
+	}
+	if err := c.validateCategory(&category); err != nil {
+		return model.Category{}, err
+	}
+
+	if err := c.categoryRepositori.UpdateCategory(category); err != nil {
+		return model.Category{}, err
+	}
+
+	existing.Name = category.Name
+	return existing, nil
+}
+
+// DeleteCategory tetap menghapus category yang masih dipakai, buku-bukunya
+// hanya kehilangan category tersebut.
+func (c *categoryUsecase) DeleteCategory(id int) error {
+	if _, err := c.GetCategoryById(id); err != nil {
+		return err
+	}
+
+	return c.categoryRepositori.DeleteCategory(id)
+}
+
+// SetBookCategories mengganti semua category sebuah buku dan mengembalikan
+// category yang sekarang terhubung.
+func (c *categoryUsecase) SetBookCategories(bookId int, categoryIds []int) ([]model.Category, error) {
+	if _, err := c.bookRepositori.GetBookById(bookId); err != nil {
+		return nil, notFound(err, "book not found")
+	}
+	if err := validateRelationIds(categoryIds); err != nil {
+		return nil, err
+	}
+	for _, id := range categoryIds {
+		_, err := c.categoryRepositori.GetCategoryById(id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &ValidationError{Message: fmt.Sprintf("category %d does not exist", id)}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.categoryRepositori.SetBookCategories(bookId, categoryIds); err != nil {
+		return nil, err
+	}
+
+	return c.categoryRepositori.GetCategoriesByBookId(bookId)
+}
+
+func (c *categoryUsecase) validateCategory(category *model.Category) error {
+	category.Name = strings.TrimSpace(category.Name)
+	if category.Name == "" {
+		return &ValidationError{Message: "name cannot be empty"}
+	}
+
+	existing, err := c.categoryRepositori.GetCategoryByName(category.Name)
+	if err == nil && existing.Id != category.Id {
+		return &ConflictError{Message: "category already exists"}
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	return nil
+}
+
+func NewCategoryUsecase(categoryRepositori repositori.CategoryRepositori, bookRepositori repositori.BookRepositori) CategoryUsecase {
+	return &categoryUsecase{categoryRepositori: categoryRepositori, bookRepositori: bookRepositori}
+}
@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"database/sql"
+	"simple-clean-architecture/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCategoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockCategoryRepository) CreateCategory(category model.Category) (model.Category, error) {
+	args := m.Called(category)
+	return args.Get(0).(model.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) GetAllCategory() ([]model.Category, error) {
+	args := m.Called()
+	return args.Get(0).([]model.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) GetCategoryById(id int) (model.Category, error) {
+	args := m.Called(id)
+	return args.Get(0).(model.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) GetCategoryByName(name string) (model.Category, error) {
+	args := m.Called(name)
+	return args.Get(0).(model.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) UpdateCategory(category model.Category) error {
+	args := m.Called(category)
+	return args.Error(0)
+}
+
+func (m *MockCategoryRepository) DeleteCategory(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockCategoryRepository) GetCategoriesByBookId(bookId int) ([]model.Category, error) {
+	args := m.Called(bookId)
+	return args.Get(0).([]model.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) SetBookCategories(bookId int, categoryIds []int) error {
+	args := m.Called(bookId, categoryIds)
+	return args.Error(0)
+}
+
+func TestCategoryUsecase_CreateCategory(t *testing.T) {
+	categoryRepo := new(MockCategoryRepository)
+	usecase := NewCategoryUsecase(categoryRepo, new(MockBookRepository))
+
+	// Happy Path
+	categoryRepo.On("GetCategoryByName", "Programming").Return(model.Category{}, sql.ErrNoRows)
+	categoryRepo.On("CreateCategory", model.Category{Name: "Programming"}).Return(model.Category{Id: 1, Name: "Programming"}, nil).Once()
+
+	category, err := usecase.CreateCategory(model.Category{Name: "Programming "})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, category.Id)
+
+	// Sad Path: nama sudah dipakai
+	categoryRepo.On("GetCategoryByName", "fiction").Return(model.Category{Id: 2, Name: "Fiction"}, nil)
+
+	var conflictErr *ConflictError
+	_, err = usecase.CreateCategory(model.Category{Name: "fiction"})
+	assert.ErrorAs(t, err, &conflictErr)
+	categoryRepo.AssertExpectations(t)
+}
+
+func TestCategoryUsecase_DeleteCategory(t *testing.T) {
+	categoryRepo := new(MockCategoryRepository)
+	usecase := NewCategoryUsecase(categoryRepo, new(MockBookRepository))
+
+	// Happy Path: category yang masih dipakai tetap boleh dihapus
+	categoryRepo.On("GetCategoryById", 1).Return(model.Category{Id: 1, BookCount: 4}, nil)
+	categoryRepo.On("DeleteCategory", 1).Return(nil).Once()
+	assert.NoError(t, usecase.DeleteCategory(1))
+
+	// Sad Path: category tidak ditemukan
+	categoryRepo.On("GetCategoryById", 9).Return(model.Category{}, sql.ErrNoRows)
+
+	var notFoundErr *NotFoundError
+	assert.ErrorAs(t, usecase.DeleteCategory(9), &notFoundErr)
+	categoryRepo.AssertExpectations(t)
+}
+
+func TestCategoryUsecase_SetBookCategories(t *testing.T) {
+	categoryRepo := new(MockCategoryRepository)
+	bookRepo := new(MockBookRepository)
+	usecase := NewCategoryUsecase(categoryRepo, bookRepo)
+
+	// Happy Path: daftar kosong melepas semua category
+	bookRepo.On("GetBookById", 1).Return(model.Book{Id: 1}, nil)
+	categoryRepo.On("SetBookCategories", 1, []int{}).Return(nil).Once()
+	categoryRepo.On("GetCategoriesByBookId", 1).Return([]model.Category{}, nil).Once()
+
+	categories, err := usecase.SetBookCategories(1, []int{})
+	assert.NoError(t, err)
+	assert.Empty(t, categories)
+	categoryRepo.AssertExpectations(t)
+
+	// Sad Path: category tidak ada atau id tidak valid
+	categoryRepo.On("GetCategoryById", 4).Return(model.Category{}, sql.ErrNoRows)
+
+	_, err = usecase.SetBookCategories(1, []int{4})
+	assert.EqualError(t, err, "category 4 does not exist")
+
+	var validationErr *ValidationError
+	_, err = usecase.SetBookCategories(1, []int{0})
+	assert.ErrorAs(t, err, &validationErr)
+	categoryRepo.AssertNumberOfCalls(t, "SetBookCategories", 1)
+}
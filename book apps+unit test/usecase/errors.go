@@ -0,0 +1,50 @@
+package usecase
+
+// ValidationError menandakan input client tidak valid, controller
+// menjawabnya dengan 400.
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// NotFoundError dijawab dengan 404.
+type NotFoundError struct {
+	Message string
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Message
+}
+
+// UnauthorizedError menandakan username atau password salah, controller
+// menjawabnya dengan 401.
+type UnauthorizedError struct {
+	Message string
+}
+
+func (e *UnauthorizedError) Error() string {
+	return e.Message
+}
+
+// PreconditionFailedError menandakan data sudah diubah orang lain sejak
+// client terakhir membacanya. Controller menjawabnya dengan 412.
+type PreconditionFailedError struct {
+	Message string
+}
+
+func (e *PreconditionFailedError) Error() string {
+	return e.Message
+}
+
+// ConflictError menandakan permintaan bertentangan dengan keadaan data saat
+// ini, misalnya buku yang masih dipinjam. Controller menjawabnya dengan 409.
+type ConflictError struct {
+	Message string
+}
+
+func (e *ConflictError) Error() string {
+	return e.Message
+}
@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/repositori"
+	"time"
+)
+
+type holdUsecase struct {
+	holdRepositori   repositori.HoldRepositori
+	bookRepositori   repositori.BookRepositori
+	copyRepositori   repositori.BookCopyRepositori
+	memberRepositori repositori.MemberRepositori
+	holdPeriod       time.Duration
+	now              func() time.Time
+}
+
+type HoldUsecase interface {
+	PlaceHold(request model.HoldRequest) (model.Hold, error)
+	CancelHold(id int) (model.Hold, error)
+	GetHoldById(id int) (model.Hold, error)
+	GetMemberHolds(memberId int) ([]model.Hold, error)
+}
+
+// PlaceHold memasukkan member ke antrian sebuah buku. Reservasi hanya bisa
+// dibuat jika tidak ada eksemplar yang tersedia, dan satu member hanya punya
+// satu hold aktif per buku.
+func (h *holdUsecase) PlaceHold(request model.HoldRequest) (model.Hold, error) {
+	if err := h.expireHolds(); err != nil {
+		return model.Hold{}, err
+	}
+
+	if _, err := h.bookRepositori.GetBookById(request.BookId); err != nil {
+		return model.Hold{}, notFound(err, "book not found")
+	}
+	if _, err := h.memberRepositori.GetMemberById(request.MemberId); err != nil {
+		return model.Hold{}, notFound(err, "member not found")
+	}
+
+	_, err := h.holdRepositori.GetActiveHold(request.MemberId, request.BookId)
+	if err == nil {
+		return model.Hold{}, &ConflictError{Message: "member already has an active hold for the book"}
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return model.Hold{}, err
+	}
+
+	availability, err := h.copyRepositori.GetAvailability(request.BookId)
+	if err != nil {
+		return model.Hold{}, err
+	}
+	if availability.Available > 0 {
+		return model.Hold{}, &ConflictError{Message: "a copy of the book is available, borrow it instead"}
+	}
+
+	hold, err := h.holdRepositori.CreateHold(model.Hold{BookId: request.BookId, MemberId: request.MemberId})
+	if err != nil {
+		return model.Hold{}, err
+	}
+
+	return h.holdRepositori.GetHoldById(hold.Id)
+}
+
+// CancelHold membatalkan hold yang masih menunggu atau siap diambil. Eksemplar
+// dari hold yang sudah ready diteruskan ke antrian berikutnya.
+func (h *holdUsecase) CancelHold(id int) (model.Hold, error) {
+	if err := h.expireHolds(); err != nil {
+		return model.Hold{}, err
+	}
+
+	hold, err := h.GetHoldById(id)
+	if err != nil {
+		return model.Hold{}, err
+	}
+	if hold.Status != model.HoldStatusWaiting && hold.Status != model.HoldStatusReady {
+		return model.Hold{}, &ConflictError{Message: repositori.ErrHoldNotActive.Error()}
+	}
+
+	now := h.now()
+	err = h.holdRepositori.CancelHold(hold, now, now.Add(h.holdPeriod))
+
+	if errors.Is(err, repositori.ErrHoldNotActive) {
+		return model.Hold{}, &ConflictError{Message: err.Error()}
+	}
+	if err != nil {
+		return model.Hold{}, err
+	}
+
+	hold.Status = model.HoldStatusCancelled
+	hold.Position = 0
+	return hold, nil
+}
+
+func (h *holdUsecase) GetHoldById(id int) (model.Hold, error) {
+	hold, err := h.holdRepositori.GetHoldById(id)
+	if err != nil {
+		return model.Hold{}, notFound(err, "hold not found")
+	}
+
+	return hold, nil
+}
+
+// GetMemberHolds mengembalikan hold aktif member beserta posisi antriannya.
+func (h *holdUsecase) GetMemberHolds(memberId int) ([]model.Hold, error) {
+	if err := h.expireHolds(); err != nil {
+		return nil, err
+	}
+
+	if _, err := h.memberRepositori.GetMemberById(memberId); err != nil {
+		return nil, notFound(err, "member not found")
+	}
+
+	return h.holdRepositori.GetHoldsByMemberId(memberId)
+}
+
+// expireHolds menjalankan kedaluwarsa hold ready yang tidak diambil sebelum
+// data hold dibaca atau diubah.
+func (h *holdUsecase) expireHolds() error {
+	now := h.now()
+	_, err := h.holdRepositori.ExpireHolds(now, now.Add(h.holdPeriod))
+
+	return err
+}
+
+func NewHoldUsecase(holdRepositori repositori.HoldRepositori, bookRepositori repositori.BookRepositori, copyRepositori repositori.BookCopyRepositori, memberRepositori repositori.MemberRepositori, holdPeriod time.Duration) HoldUsecase {
+	return &holdUsecase{
+		holdRepositori:   holdRepositori,
+		bookRepositori:   bookRepositori,
+		copyRepositori:   copyRepositori,
+		memberRepositori: memberRepositori,
+		holdPeriod:       holdPeriod,
+		now:              time.Now,
+	}
+}
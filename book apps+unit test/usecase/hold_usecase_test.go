@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"database/sql"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/repositori"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockHoldRepository struct {
+	mock.Mock
+}
+
+func (m *MockHoldRepository) CreateHold(hold model.Hold) (model.Hold, error) {
+	args := m.Called(hold)
+	return args.Get(0).(model.Hold), args.Error(1)
+}
+
+func (m *MockHoldRepository) GetHoldById(id int) (model.Hold, error) {
+	args := m.Called(id)
+	return args.Get(0).(model.Hold), args.Error(1)
+}
+
+func (m *MockHoldRepository) GetHoldsByMemberId(memberId int) ([]model.Hold, error) {
+	args := m.Called(memberId)
+	return args.Get(0).([]model.Hold), args.Error(1)
+}
+
+func (m *MockHoldRepository) GetActiveHold(memberId int, bookId int) (model.Hold, error) {
+	args := m.Called(memberId, bookId)
+	return args.Get(0).(model.Hold), args.Error(1)
+}
+
+func (m *MockHoldRepository) CancelHold(hold model.Hold, now time.Time, expiresAt time.Time) error {
+	args := m.Called(hold, now, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockHoldRepository) ExpireHolds(now time.Time, expiresAt time.Time) (int, error) {
+	args := m.Called(now, expiresAt)
+	return args.Int(0), args.Error(1)
+}
+
+func newTestHoldUsecase() (*holdUsecase, *MockHoldRepository, *MockBookRepository, *MockBookCopyRepository, *MockMemberRepository) {
+	holdRepo := new(MockHoldRepository)
+	bookRepo := new(MockBookRepository)
+	copyRepo := new(MockBookCopyRepository)
+	memberRepo := new(MockMemberRepository)
+	usecase := NewHoldUsecase(holdRepo, bookRepo, copyRepo, memberRepo, 3*24*time.Hour).(*holdUsecase)
+	usecase.now = func() time.Time { return loanNow }
+	holdRepo.On("ExpireHolds", loanNow, holdExpiresAt).Return(0, nil)
+
+	return usecase, holdRepo, bookRepo, copyRepo, memberRepo
+}
+
+func TestHoldUsecase_PlaceHold(t *testing.T) {
+	usecase, holdRepo, bookRepo, copyRepo, memberRepo := newTestHoldUsecase()
+	bookRepo.On("GetBookById", 1).Return(model.Book{Id: 1}, nil)
+	memberRepo.On("GetMemberById", 2).Return(model.Member{Id: 2}, nil)
+	holdRepo.On("GetActiveHold", 2, 1).Return(model.Hold{}, sql.ErrNoRows)
+	copyRepo.On("GetAvailability", 1).Return(model.BookAvailability{Total: 2, OnLoan: 2}, nil)
+	holdRepo.On("CreateHold", model.Hold{BookId: 1, MemberId: 2}).Return(model.Hold{Id: 8, BookId: 1, MemberId: 2, Status: model.HoldStatusWaiting}, nil).Once()
+	holdRepo.On("GetHoldById", 8).Return(model.Hold{Id: 8, BookId: 1, MemberId: 2, Status: model.HoldStatusWaiting, Position: 3}, nil)
+
+	hold, err := usecase.PlaceHold(model.HoldRequest{BookId: 1, MemberId: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, hold.Position)
+	holdRepo.AssertExpectations(t)
+}
+
+func TestHoldUsecase_PlaceHold_Conflict(t *testing.T) {
+	usecase, holdRepo, bookRepo, copyRepo, memberRepo := newTestHoldUsecase()
+	bookRepo.On("GetBookById", 1).Return(model.Book{Id: 1}, nil)
+	memberRepo.On("GetMemberById", 2).Return(model.Member{Id: 2}, nil)
+	memberRepo.On("GetMemberById", 3).Return(model.Member{Id: 3}, nil)
+	holdRepo.On("GetActiveHold", 2, 1).Return(model.Hold{Id: 8, Status: model.HoldStatusWaiting}, nil)
+	holdRepo.On("GetActiveHold", 3, 1).Return(model.Hold{}, sql.ErrNoRows)
+	copyRepo.On("GetAvailability", 1).Return(model.BookAvailability{Total: 2, Available: 1, OnLoan: 1}, nil)
+
+	var conflictErr *ConflictError
+
+	// Sad Path: member sudah punya hold aktif
+	_, err := usecase.PlaceHold(model.HoldRequest{BookId: 1, MemberId: 2})
+	assert.ErrorAs(t, err, &conflictErr)
+	assert.EqualError(t, err, "member already has an active hold for the book")
+
+	// Sad Path: masih ada eksemplar tersedia
+	_, err = usecase.PlaceHold(model.HoldRequest{BookId: 1, MemberId: 3})
+	assert.ErrorAs(t, err, &conflictErr)
+
+	holdRepo.AssertNotCalled(t, "CreateHold", mock.Anything)
+}
+
+func TestHoldUsecase_CancelHold(t *testing.T) {
+	usecase, holdRepo, _, _, _ := newTestHoldUsecase()
+	copyId := 6
+	ready := model.Hold{Id: 8, BookId: 1, MemberId: 2, CopyId: &copyId, Status: model.HoldStatusReady}
+	holdRepo.On("GetHoldById", 8).Return(ready, nil)
+	holdRepo.On("GetHoldById", 9).Return(model.Hold{Id: 9, Status: model.HoldStatusFulfilled}, nil)
+	holdRepo.On("GetHoldById", 10).Return(model.Hold{}, sql.ErrNoRows)
+	holdRepo.On("CancelHold", ready, loanNow, holdExpiresAt).Return(nil).Once()
+
+	// Happy Path
+	hold, err := usecase.CancelHold(8)
+	assert.NoError(t, err)
+	assert.Equal(t, model.HoldStatusCancelled, hold.Status)
+	holdRepo.AssertCalled(t, "CancelHold", ready, loanNow, holdExpiresAt)
+
+	// Sad Path: hold sudah tidak aktif
+	_, err = usecase.CancelHold(9)
+	var conflictErr *ConflictError
+	assert.ErrorAs(t, err, &conflictErr)
+	assert.EqualError(t, err, repositori.ErrHoldNotActive.Error())
+
+	// Sad Path: hold tidak ditemukan
+	_, err = usecase.CancelHold(10)
+	var notFoundErr *NotFoundError
+	assert.ErrorAs(t, err, &notFoundErr)
+}
+
+func TestHoldUsecase_GetMemberHolds(t *testing.T) {
+	usecase, holdRepo, _, _, memberRepo := newTestHoldUsecase()
+	memberRepo.On("GetMemberById", 2).Return(model.Member{Id: 2}, nil)
+	memberRepo.On("GetMemberById", 9).Return(model.Member{}, sql.ErrNoRows)
+	holdRepo.On("GetHoldsByMemberId", 2).Return([]model.Hold{{Id: 8, Position: 1}, {Id: 9, Position: 4}}, nil)
+
+	holds, err := usecase.GetMemberHolds(2)
+	assert.NoError(t, err)
+	assert.Len(t, holds, 2)
+	holdRepo.AssertCalled(t, "ExpireHolds", loanNow, holdExpiresAt)
+
+	_, err = usecase.GetMemberHolds(9)
+	var notFoundErr *NotFoundError
+	assert.ErrorAs(t, err, &notFoundErr)
+}
@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"strconv"
+	"strings"
+)
+
+// normalizeIsbn menerima ISBN-10 atau ISBN-13, dengan atau tanpa tanda hubung
+// dan spasi, memeriksa check digit-nya, lalu mengembalikan bentuk ISBN-13.
+func normalizeIsbn(value string) (string, error) {
+	isbn := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(value))
+	invalid := &ValidationError{Message: "isbn must be a valid ISBN-10 or ISBN-13"}
+
+	switch len(isbn) {
+	case 10:
+		if !isDigits(isbn[:9]) || isbn10CheckDigit(isbn[:9]) != isbn[9] {
+			return "", invalid
+		}
+		return isbn10To13(isbn), nil
+	case 13:
+		if !isDigits(isbn) || isbn13CheckDigit(isbn[:12]) != isbn[12] {
+			return "", invalid
+		}
+		return isbn, nil
+	default:
+		return "", invalid
+	}
+}
+
+// isbn10To13 mengubah ISBN-10 yang sudah valid menjadi ISBN-13 dengan awalan
+// 978.
+func isbn10To13(isbn10 string) string {
+	first12 := "978" + isbn10[:9]
+	return first12 + string(isbn13CheckDigit(first12))
+}
+
+// isbn13To10 mengubah ISBN-13 yang sudah valid menjadi ISBN-10. ISBN-13 dengan
+// awalan selain 978 tidak punya padanan ISBN-10, sehingga hasilnya kosong.
+func isbn13To10(isbn13 string) string {
+	if !strings.HasPrefix(isbn13, "978") {
+		return ""
+	}
+	first9 := isbn13[3:12]
+	return first9 + string(isbn10CheckDigit(first9))
+}
+
+func isbn10CheckDigit(first9 string) byte {
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += (10 - i) * int(first9[i]-'0')
+	}
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return 'X'
+	}
+	return strconv.Itoa(check)[0]
+}
+
+func isbn13CheckDigit(first12 string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += weight * int(first12[i]-'0')
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
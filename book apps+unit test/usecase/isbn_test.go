@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"simple-clean-architecture/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeIsbn(t *testing.T) {
+	isbn, err := normalizeIsbn("978-0-13-419044-0")
+	assert.NoError(t, err)
+	assert.Equal(t, "9780134190440", isbn)
+
+	// ISBN-10 selalu diubah menjadi ISBN-13
+	isbn, err = normalizeIsbn("0-8044-2957-x")
+	assert.NoError(t, err)
+	assert.Equal(t, "9780804429573", isbn)
+
+	for _, invalid := range []string{"9780134190441", "0804429571", "12345", "97801341904X0", "X804429570"} {
+		_, err = normalizeIsbn(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestIsbnConversion(t *testing.T) {
+	assert.Equal(t, "9780134190440", isbn10To13("0134190440"))
+	assert.Equal(t, "9780804429573", isbn10To13("080442957X"))
+
+	assert.Equal(t, "0134190440", isbn13To10("9780134190440"))
+	assert.Equal(t, "080442957X", isbn13To10("9780804429573"))
+	// ISBN-13 berawalan 979 tidak punya padanan ISBN-10
+	assert.Equal(t, "", isbn13To10("9791000000008"))
+}
+
+func TestValidateBook_Isbn10(t *testing.T) {
+	book := model.Book{Title: "Title", Author: "Author", ReleaseYear: 2020, Pages: 10, Isbn10: "0-13-419044-0"}
+	assert.NoError(t, validateBook(&book))
+	assert.Equal(t, "9780134190440", book.Isbn)
+	assert.Equal(t, "0134190440", book.Isbn10)
+
+	book = model.Book{Title: "Title", Author: "Author", ReleaseYear: 2020, Pages: 10, Isbn: "9791000000008", Isbn10: ""}
+	assert.NoError(t, validateBook(&book))
+	assert.Equal(t, "", book.Isbn10)
+}
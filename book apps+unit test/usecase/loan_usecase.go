@@ -0,0 +1,202 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/repositori"
+	"time"
+)
+
+type loanUsecase struct {
+	loanRepositori   repositori.LoanRepositori
+	bookRepositori   repositori.BookRepositori
+	copyRepositori   repositori.BookCopyRepositori
+	memberRepositori repositori.MemberRepositori
+	holdRepositori   repositori.HoldRepositori
+	loanPeriod       time.Duration
+	finePerDay       int
+	holdPeriod       time.Duration
+	now              func() time.Time
+}
+
+type LoanUsecase interface {
+	BorrowBook(request model.LoanRequest) (model.Loan, error)
+	ReturnBook(id int) (model.Loan, error)
+	PayFine(id int) (model.Loan, error)
+	GetAllLoan(filter model.LoanFilter) ([]model.Loan, error)
+	GetLoanById(id int) (model.Loan, error)
+}
+
+// BorrowBook meminjamkan satu eksemplar buku kepada member dengan jatuh tempo
+// sesuai masa pinjam. Member yang masih punya pinjaman lewat jatuh tempo atau
+// denda yang belum dibayar tidak bisa meminjam. Jika member punya hold ready
+// untuk buku tersebut, eksemplar yang disimpan untuknya yang dipinjamkan.
+func (l *loanUsecase) BorrowBook(request model.LoanRequest) (model.Loan, error) {
+	var copy model.BookCopy
+	if request.Barcode != "" {
+		var err error
+		copy, err = l.copyRepositori.GetCopyByBarcode(request.Barcode)
+		if err != nil {
+			return model.Loan{}, notFound(err, "book copy not found")
+		}
+		if request.BookId != 0 && request.BookId != copy.BookId {
+			return model.Loan{}, &ValidationError{Message: "barcode does not belong to the book"}
+		}
+		request.BookId = copy.BookId
+	}
+
+	if _, err := l.bookRepositori.GetBookById(request.BookId); err != nil {
+		return model.Loan{}, notFound(err, "book not found")
+	}
+	if _, err := l.memberRepositori.GetMemberById(request.MemberId); err != nil {
+		return model.Loan{}, notFound(err, "member not found")
+	}
+
+	now := l.now()
+	overdue, unpaidFines, err := l.loanRepositori.GetMemberStanding(request.MemberId, now)
+	if err != nil {
+		return model.Loan{}, err
+	}
+	if overdue > 0 {
+		return model.Loan{}, &ConflictError{Message: "member has overdue loans"}
+	}
+	if unpaidFines > 0 {
+		return model.Loan{}, &ConflictError{Message: "member has unpaid fines"}
+	}
+
+	if _, err := l.holdRepositori.ExpireHolds(now, now.Add(l.holdPeriod)); err != nil {
+		return model.Loan{}, err
+	}
+
+	holdId := 0
+	hold, err := l.holdRepositori.GetActiveHold(request.MemberId, request.BookId)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return model.Loan{}, err
+	}
+	if err == nil && hold.Status == model.HoldStatusReady && (request.Barcode == "" || copy.Id == *hold.CopyId) {
+		holdId = hold.Id
+		copy = model.BookCopy{Id: *hold.CopyId, BookId: hold.BookId}
+	}
+
+	if request.Barcode == "" && holdId == 0 {
+		copy, err = l.copyRepositori.GetAvailableCopy(request.BookId)
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Loan{}, &ConflictError{Message: "no copy of the book is available"}
+		}
+		if err != nil {
+			return model.Loan{}, err
+		}
+	}
+
+	loan, err := l.loanRepositori.CreateLoan(model.Loan{BookId: request.BookId, CopyId: copy.Id, MemberId: request.MemberId, BorrowedAt: now, DueAt: now.Add(l.loanPeriod)}, holdId)
+
+	if errors.Is(err, repositori.ErrCopyNotAvailable) || errors.Is(err, repositori.ErrHoldNotActive) {
+		return model.Loan{}, &ConflictError{Message: err.Error()}
+	}
+	if err != nil {
+		return model.Loan{}, err
+	}
+
+	return loan, nil
+}
+
+// ReturnBook mencatat pengembalian dan menghitung denda keterlambatan. Loan
+// tanpa denda langsung dianggap lunas.
+func (l *loanUsecase) ReturnBook(id int) (model.Loan, error) {
+	loan, err := l.GetLoanById(id)
+	if err != nil {
+		return model.Loan{}, err
+	}
+	if loan.ReturnedAt != nil {
+		return model.Loan{}, &ConflictError{Message: repositori.ErrLoanNotActive.Error()}
+	}
+
+	returnedAt := l.now()
+	loan.ReturnedAt = &returnedAt
+	loan.Fine = calculateFine(loan.DueAt, returnedAt, l.finePerDay)
+	loan.FinePaid = loan.Fine == 0
+
+	err = l.loanRepositori.ReturnLoan(loan, returnedAt.Add(l.holdPeriod))
+
+	if errors.Is(err, repositori.ErrLoanNotActive) {
+		return model.Loan{}, &ConflictError{Message: err.Error()}
+	}
+	if err != nil {
+		return model.Loan{}, err
+	}
+
+	return loan, nil
+}
+
+func (l *loanUsecase) PayFine(id int) (model.Loan, error) {
+	loan, err := l.GetLoanById(id)
+	if err != nil {
+		return model.Loan{}, err
+	}
+	if loan.ReturnedAt == nil {
+		return model.Loan{}, &ConflictError{Message: "fine is calculated when the book is returned"}
+	}
+	if loan.FinePaid {
+		return model.Loan{}, &ConflictError{Message: "loan has no unpaid fine"}
+	}
+
+	if err := l.loanRepositori.PayFine(id); err != nil {
+		return model.Loan{}, err
+	}
+	loan.FinePaid = true
+
+	return loan, nil
+}
+
+func (l *loanUsecase) GetAllLoan(filter model.LoanFilter) ([]model.Loan, error) {
+	if filter.Status != "" && filter.Status != "active" && filter.Status != "overdue" && filter.Status != "returned" {
+		return nil, &ValidationError{Message: "status must be active, overdue or returned"}
+	}
+
+	return l.loanRepositori.GetAllLoan(filter, l.now())
+}
+
+func (l *loanUsecase) GetLoanById(id int) (model.Loan, error) {
+	loan, err := l.loanRepositori.GetLoanById(id)
+	if err != nil {
+		return model.Loan{}, notFound(err, "loan not found")
+	}
+
+	return loan, nil
+}
+
+// calculateFine mengenakan denda untuk setiap hari keterlambatan, hari yang
+// baru berjalan sebagian dihitung satu hari penuh.
+func calculateFine(dueAt, returnedAt time.Time, finePerDay int) int {
+	late := returnedAt.Sub(dueAt)
+	if late <= 0 {
+		return 0
+	}
+	days := int((late + 24*time.Hour - 1) / (24 * time.Hour))
+
+	return days * finePerDay
+}
+
+// notFound mengubah sql.ErrNoRows menjadi NotFoundError dengan pesan yang
+// diberikan, error lain dikembalikan apa adanya.
+func notFound(err error, message string) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return &NotFoundError{Message: message}
+	}
+	return err
+}
+
+func NewLoanUsecase(loanRepositori repositori.LoanRepositori, bookRepositori repositori.BookRepositori, copyRepositori repositori.BookCopyRepositori, memberRepositori repositori.MemberRepositori, holdRepositori repositori.HoldRepositori, loanPeriod time.Duration, finePerDay int, holdPeriod time.Duration) LoanUsecase {
+	return &loanUsecase{
+		loanRepositori:   loanRepositori,
+		bookRepositori:   bookRepositori,
+		copyRepositori:   copyRepositori,
+		memberRepositori: memberRepositori,
+		holdRepositori:   holdRepositori,
+		loanPeriod:       loanPeriod,
+		finePerDay:       finePerDay,
+		holdPeriod:       holdPeriod,
+		now:              time.Now,
+	}
+}
@@ -0,0 +1,288 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/repositori"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLoanRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoanRepository) CreateLoan(loan model.Loan, holdId int) (model.Loan, error) {
+	args := m.Called(loan, holdId)
+	return args.Get(0).(model.Loan), args.Error(1)
+}
+
+func (m *MockLoanRepository) GetAllLoan(filter model.LoanFilter, now time.Time) ([]model.Loan, error) {
+	args := m.Called(filter, now)
+	return args.Get(0).([]model.Loan), args.Error(1)
+}
+
+func (m *MockLoanRepository) GetLoanById(id int) (model.Loan, error) {
+	args := m.Called(id)
+	return args.Get(0).(model.Loan), args.Error(1)
+}
+
+func (m *MockLoanRepository) GetMemberStanding(memberId int, now time.Time) (int, int, error) {
+	args := m.Called(memberId, now)
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
+func (m *MockLoanRepository) ReturnLoan(loan model.Loan, holdExpiresAt time.Time) error {
+	args := m.Called(loan, holdExpiresAt)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) PayFine(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+var loanNow = time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC)
+
+// holdExpiresAt adalah batas ambil hold yang disiapkan pada loanNow.
+var holdExpiresAt = loanNow.Add(3 * 24 * time.Hour)
+
+func newTestLoanUsecase() (*loanUsecase, *MockLoanRepository, *MockBookRepository, *MockBookCopyRepository, *MockMemberRepository, *MockHoldRepository) {
+	loanRepo := new(MockLoanRepository)
+	bookRepo := new(MockBookRepository)
+	copyRepo := new(MockBookCopyRepository)
+	memberRepo := new(MockMemberRepository)
+	holdRepo := new(MockHoldRepository)
+	usecase := NewLoanUsecase(loanRepo, bookRepo, copyRepo, memberRepo, holdRepo, 14*24*time.Hour, 1000, 3*24*time.Hour).(*loanUsecase)
+	usecase.now = func() time.Time { return loanNow }
+
+	return usecase, loanRepo, bookRepo, copyRepo, memberRepo, holdRepo
+}
+
+func TestLoanUsecase_BorrowBook(t *testing.T) {
+	usecase, loanRepo, bookRepo, copyRepo, memberRepo, holdRepo := newTestLoanUsecase()
+	bookRepo.On("GetBookById", 1).Return(model.Book{Id: 1}, nil)
+	memberRepo.On("GetMemberById", 2).Return(model.Member{Id: 2}, nil)
+	loanRepo.On("GetMemberStanding", 2, loanNow).Return(0, 0, nil)
+	holdRepo.On("ExpireHolds", loanNow, holdExpiresAt).Return(0, nil)
+	holdRepo.On("GetActiveHold", 2, 1).Return(model.Hold{}, sql.ErrNoRows)
+	copyRepo.On("GetAvailableCopy", 1).Return(model.BookCopy{Id: 4, BookId: 1, Status: model.CopyStatusAvailable}, nil)
+	expected := model.Loan{BookId: 1, CopyId: 4, MemberId: 2, BorrowedAt: loanNow, DueAt: time.Date(2024, 5, 24, 9, 0, 0, 0, time.UTC)}
+	loanRepo.On("CreateLoan", expected, 0).Return(model.Loan{Id: 5, BookId: 1, CopyId: 4, MemberId: 2, BorrowedAt: expected.BorrowedAt, DueAt: expected.DueAt}, nil).Once()
+
+	loan, err := usecase.BorrowBook(model.LoanRequest{BookId: 1, MemberId: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, loan.Id)
+	assert.Equal(t, 4, loan.CopyId)
+	assert.Equal(t, expected.DueAt, loan.DueAt)
+	loanRepo.AssertExpectations(t)
+}
+
+func TestLoanUsecase_BorrowBook_Barcode(t *testing.T) {
+	usecase, loanRepo, bookRepo, copyRepo, memberRepo, holdRepo := newTestLoanUsecase()
+	copyRepo.On("GetCopyByBarcode", "B-002").Return(model.BookCopy{Id: 6, BookId: 1, Status: model.CopyStatusAvailable}, nil)
+	bookRepo.On("GetBookById", 1).Return(model.Book{Id: 1}, nil)
+	memberRepo.On("GetMemberById", 2).Return(model.Member{Id: 2}, nil)
+	loanRepo.On("GetMemberStanding", 2, loanNow).Return(0, 0, nil)
+	holdRepo.On("ExpireHolds", loanNow, holdExpiresAt).Return(0, nil)
+	holdRepo.On("GetActiveHold", 2, 1).Return(model.Hold{}, sql.ErrNoRows)
+	loanRepo.On("CreateLoan", mock.Anything, 0).Return(model.Loan{Id: 5, BookId: 1, CopyId: 6, MemberId: 2}, nil)
+
+	loan, err := usecase.BorrowBook(model.LoanRequest{MemberId: 2, Barcode: "B-002"})
+	assert.NoError(t, err)
+	assert.Equal(t, 6, loan.CopyId)
+	loanRepo.AssertCalled(t, "CreateLoan", mock.MatchedBy(func(loan model.Loan) bool { return loan.BookId == 1 && loan.CopyId == 6 }), 0)
+	copyRepo.AssertNotCalled(t, "GetAvailableCopy", mock.Anything)
+
+	// Sad Path: barcode milik buku lain
+	_, err = usecase.BorrowBook(model.LoanRequest{BookId: 3, MemberId: 2, Barcode: "B-002"})
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+
+	// Sad Path: barcode tidak terdaftar
+	copyRepo.On("GetCopyByBarcode", "B-404").Return(model.BookCopy{}, sql.ErrNoRows)
+	_, err = usecase.BorrowBook(model.LoanRequest{MemberId: 2, Barcode: "B-404"})
+	assert.EqualError(t, err, "book copy not found")
+}
+
+func TestLoanUsecase_BorrowBook_ReadyHold(t *testing.T) {
+	usecase, loanRepo, bookRepo, copyRepo, memberRepo, holdRepo := newTestLoanUsecase()
+	bookRepo.On("GetBookById", 1).Return(model.Book{Id: 1}, nil)
+	memberRepo.On("GetMemberById", 2).Return(model.Member{Id: 2}, nil)
+	loanRepo.On("GetMemberStanding", 2, loanNow).Return(0, 0, nil)
+	holdRepo.On("ExpireHolds", loanNow, holdExpiresAt).Return(0, nil)
+	copyId := 6
+	holdRepo.On("GetActiveHold", 2, 1).Return(model.Hold{Id: 8, BookId: 1, MemberId: 2, CopyId: &copyId, Status: model.HoldStatusReady}, nil)
+	loanRepo.On("CreateLoan", mock.Anything, 8).Return(model.Loan{Id: 5, BookId: 1, CopyId: 6, MemberId: 2}, nil)
+
+	loan, err := usecase.BorrowBook(model.LoanRequest{BookId: 1, MemberId: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 6, loan.CopyId)
+	loanRepo.AssertCalled(t, "CreateLoan", mock.MatchedBy(func(loan model.Loan) bool { return loan.CopyId == 6 }), 8)
+	copyRepo.AssertNotCalled(t, "GetAvailableCopy", mock.Anything)
+}
+
+func TestLoanUsecase_BorrowBook_NoCopyAvailable(t *testing.T) {
+	usecase, loanRepo, bookRepo, copyRepo, memberRepo, holdRepo := newTestLoanUsecase()
+	bookRepo.On("GetBookById", 1).Return(model.Book{Id: 1}, nil)
+	memberRepo.On("GetMemberById", 2).Return(model.Member{Id: 2}, nil)
+	loanRepo.On("GetMemberStanding", 2, loanNow).Return(0, 0, nil)
+	holdRepo.On("ExpireHolds", loanNow, holdExpiresAt).Return(0, nil)
+	holdRepo.On("GetActiveHold", 2, 1).Return(model.Hold{}, sql.ErrNoRows)
+	copyRepo.On("GetAvailableCopy", 1).Return(model.BookCopy{}, sql.ErrNoRows)
+
+	_, err := usecase.BorrowBook(model.LoanRequest{BookId: 1, MemberId: 2})
+	var conflictErr *ConflictError
+	assert.ErrorAs(t, err, &conflictErr)
+	loanRepo.AssertNotCalled(t, "CreateLoan", mock.Anything, mock.Anything)
+}
+
+func TestLoanUsecase_BorrowBook_Blocked(t *testing.T) {
+	usecase, loanRepo, bookRepo, _, memberRepo, _ := newTestLoanUsecase()
+	bookRepo.On("GetBookById", 1).Return(model.Book{Id: 1}, nil)
+	memberRepo.On("GetMemberById", 2).Return(model.Member{Id: 2}, nil)
+	memberRepo.On("GetMemberById", 3).Return(model.Member{Id: 3}, nil)
+	loanRepo.On("GetMemberStanding", 2, loanNow).Return(1, 0, nil)
+	loanRepo.On("GetMemberStanding", 3, loanNow).Return(0, 2000, nil)
+
+	_, err := usecase.BorrowBook(model.LoanRequest{BookId: 1, MemberId: 2})
+	assert.EqualError(t, err, "member has overdue loans")
+	var conflictErr *ConflictError
+	assert.ErrorAs(t, err, &conflictErr)
+
+	_, err = usecase.BorrowBook(model.LoanRequest{BookId: 1, MemberId: 3})
+	assert.EqualError(t, err, "member has unpaid fines")
+	assert.ErrorAs(t, err, &conflictErr)
+
+	loanRepo.AssertNotCalled(t, "CreateLoan", mock.Anything, mock.Anything)
+}
+
+func TestLoanUsecase_BorrowBook_CopyNotAvailable(t *testing.T) {
+	usecase, loanRepo, bookRepo, copyRepo, memberRepo, holdRepo := newTestLoanUsecase()
+	bookRepo.On("GetBookById", 1).Return(model.Book{Id: 1}, nil)
+	memberRepo.On("GetMemberById", 2).Return(model.Member{Id: 2}, nil)
+	loanRepo.On("GetMemberStanding", 2, loanNow).Return(0, 0, nil)
+	holdRepo.On("ExpireHolds", loanNow, holdExpiresAt).Return(0, nil)
+	holdRepo.On("GetActiveHold", 2, 1).Return(model.Hold{}, sql.ErrNoRows)
+	copyRepo.On("GetAvailableCopy", 1).Return(model.BookCopy{Id: 4, BookId: 1}, nil)
+	loanRepo.On("CreateLoan", mock.Anything, 0).Return(model.Loan{}, repositori.ErrCopyNotAvailable)
+
+	_, err := usecase.BorrowBook(model.LoanRequest{BookId: 1, MemberId: 2})
+	var conflictErr *ConflictError
+	assert.ErrorAs(t, err, &conflictErr)
+}
+
+func TestLoanUsecase_BorrowBook_NotFound(t *testing.T) {
+	usecase, _, bookRepo, _, memberRepo, _ := newTestLoanUsecase()
+	bookRepo.On("GetBookById", 1).Return(model.Book{}, sql.ErrNoRows)
+	bookRepo.On("GetBookById", 2).Return(model.Book{Id: 2}, nil)
+	memberRepo.On("GetMemberById", 3).Return(model.Member{}, sql.ErrNoRows)
+
+	var notFoundErr *NotFoundError
+	_, err := usecase.BorrowBook(model.LoanRequest{BookId: 1, MemberId: 3})
+	assert.ErrorAs(t, err, &notFoundErr)
+	assert.EqualError(t, err, "book not found")
+
+	_, err = usecase.BorrowBook(model.LoanRequest{BookId: 2, MemberId: 3})
+	assert.ErrorAs(t, err, &notFoundErr)
+	assert.EqualError(t, err, "member not found")
+}
+
+func TestLoanUsecase_ReturnBook(t *testing.T) {
+	usecase, loanRepo, _, _, _, _ := newTestLoanUsecase()
+	// jatuh tempo 2 hari 1 jam yang lalu, dihitung terlambat 3 hari
+	dueAt := loanNow.Add(-49 * time.Hour)
+	loanRepo.On("GetLoanById", 5).Return(model.Loan{Id: 5, DueAt: dueAt}, nil)
+	loanRepo.On("ReturnLoan", mock.Anything, holdExpiresAt).Return(nil)
+
+	loan, err := usecase.ReturnBook(5)
+	assert.NoError(t, err)
+	assert.Equal(t, loanNow, *loan.ReturnedAt)
+	assert.Equal(t, 3000, loan.Fine)
+	assert.False(t, loan.FinePaid)
+	loanRepo.AssertCalled(t, "ReturnLoan", loan, holdExpiresAt)
+}
+
+func TestLoanUsecase_ReturnBook_OnTime(t *testing.T) {
+	usecase, loanRepo, _, _, _, _ := newTestLoanUsecase()
+	loanRepo.On("GetLoanById", 5).Return(model.Loan{Id: 5, DueAt: loanNow.Add(time.Hour)}, nil)
+	loanRepo.On("ReturnLoan", mock.Anything, holdExpiresAt).Return(nil)
+
+	loan, err := usecase.ReturnBook(5)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, loan.Fine)
+	assert.True(t, loan.FinePaid)
+}
+
+func TestLoanUsecase_ReturnBook_AlreadyReturned(t *testing.T) {
+	usecase, loanRepo, _, _, _, _ := newTestLoanUsecase()
+	returnedAt := loanNow.Add(-time.Hour)
+	loanRepo.On("GetLoanById", 5).Return(model.Loan{Id: 5, ReturnedAt: &returnedAt}, nil)
+
+	_, err := usecase.ReturnBook(5)
+	var conflictErr *ConflictError
+	assert.ErrorAs(t, err, &conflictErr)
+	loanRepo.AssertNotCalled(t, "ReturnLoan", mock.Anything, mock.Anything)
+}
+
+func TestLoanUsecase_PayFine(t *testing.T) {
+	usecase, loanRepo, _, _, _, _ := newTestLoanUsecase()
+	returnedAt := loanNow.Add(-time.Hour)
+	loanRepo.On("GetLoanById", 5).Return(model.Loan{Id: 5, ReturnedAt: &returnedAt, Fine: 3000}, nil)
+	loanRepo.On("GetLoanById", 6).Return(model.Loan{Id: 6, ReturnedAt: &returnedAt, FinePaid: true}, nil)
+	loanRepo.On("GetLoanById", 7).Return(model.Loan{Id: 7}, nil)
+	loanRepo.On("PayFine", 5).Return(nil).Once()
+
+	// Happy Path
+	loan, err := usecase.PayFine(5)
+	assert.NoError(t, err)
+	assert.True(t, loan.FinePaid)
+	loanRepo.AssertCalled(t, "PayFine", 5)
+
+	// Sad Path: tidak ada denda, atau buku belum dikembalikan
+	var conflictErr *ConflictError
+	_, err = usecase.PayFine(6)
+	assert.ErrorAs(t, err, &conflictErr)
+	_, err = usecase.PayFine(7)
+	assert.ErrorAs(t, err, &conflictErr)
+}
+
+func TestLoanUsecase_GetAllLoan(t *testing.T) {
+	usecase, loanRepo, _, _, _, _ := newTestLoanUsecase()
+	loanRepo.On("GetAllLoan", model.LoanFilter{Status: "overdue"}, loanNow).Return([]model.Loan{{Id: 5}}, nil)
+
+	loans, err := usecase.GetAllLoan(model.LoanFilter{Status: "overdue"})
+	assert.NoError(t, err)
+	assert.Len(t, loans, 1)
+
+	_, err = usecase.GetAllLoan(model.LoanFilter{Status: "lost"})
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+}
+
+func TestLoanUsecase_GetLoanById_Error(t *testing.T) {
+	usecase, loanRepo, _, _, _, _ := newTestLoanUsecase()
+	loanRepo.On("GetLoanById", 5).Return(model.Loan{}, sql.ErrNoRows)
+	loanRepo.On("GetLoanById", 6).Return(model.Loan{}, errors.New("connection refused"))
+
+	var notFoundErr *NotFoundError
+	_, err := usecase.GetLoanById(5)
+	assert.ErrorAs(t, err, &notFoundErr)
+
+	_, err = usecase.GetLoanById(6)
+	assert.EqualError(t, err, "connection refused")
+}
+
+func TestCalculateFine(t *testing.T) {
+	dueAt := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 0, calculateFine(dueAt, dueAt, 1000))
+	assert.Equal(t, 0, calculateFine(dueAt, dueAt.Add(-time.Hour), 1000))
+	assert.Equal(t, 1000, calculateFine(dueAt, dueAt.Add(time.Minute), 1000))
+	assert.Equal(t, 1000, calculateFine(dueAt, dueAt.Add(24*time.Hour), 1000))
+	assert.Equal(t, 2000, calculateFine(dueAt, dueAt.Add(25*time.Hour), 1000))
+}
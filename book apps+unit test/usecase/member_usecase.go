@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"net/mail"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/repositori"
+	"strings"
+)
+
+type memberUsecase struct {
+	memberRepositori repositori.MemberRepositori
+}
+
+type MemberUsecase interface {
+	CreateNewMember(member model.Member) (model.Member, error)
+	GetAllMember() ([]model.Member, error)
+	GetMemberById(id int) (model.Member, error)
+}
+
+func (m *memberUsecase) CreateNewMember(member model.Member) (model.Member, error) {
+	member.Name = strings.TrimSpace(member.Name)
+	if member.Name == "" {
+		return model.Member{}, &ValidationError{Message: "name cannot be empty"}
+	}
+	if _, err := mail.ParseAddress(member.Email); err != nil || strings.ContainsAny(member.Email, "<>") {
+		return model.Member{}, &ValidationError{Message: "invalid email address"}
+	}
+
+	return m.memberRepositori.CreateNewMember(member)
+}
+
+func (m *memberUsecase) GetAllMember() ([]model.Member, error) {
+	return m.memberRepositori.GetAllMember()
+}
+
+func (m *memberUsecase) GetMemberById(id int) (model.Member, error) {
+	member, err := m.memberRepositori.GetMemberById(id)
+
+	if err != nil {
+		return model.Member{}, notFound(err, "member not found")
+	}
+
+	return member, nil
+}
+
+func NewMemberUsecase(memberRepositori repositori.MemberRepositori) MemberUsecase {
+	return &memberUsecase{memberRepositori: memberRepositori}
+}
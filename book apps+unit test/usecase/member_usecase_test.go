@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"database/sql"
+	"simple-clean-architecture/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMemberRepository struct {
+	mock.Mock
+}
+
+func (m *MockMemberRepository) CreateNewMember(member model.Member) (model.Member, error) {
+	args := m.Called(member)
+	return args.Get(0).(model.Member), args.Error(1)
+}
+
+func (m *MockMemberRepository) GetAllMember() ([]model.Member, error) {
+	args := m.Called()
+	return args.Get(0).([]model.Member), args.Error(1)
+}
+
+func (m *MockMemberRepository) GetMemberById(id int) (model.Member, error) {
+	args := m.Called(id)
+	return args.Get(0).(model.Member), args.Error(1)
+}
+
+func TestMemberUsecase_CreateNewMember(t *testing.T) {
+	repo := new(MockMemberRepository)
+	repo.On("CreateNewMember", model.Member{Name: "Budi", Email: "budi@example.com"}).Return(model.Member{Id: 1, Name: "Budi", Email: "budi@example.com"}, nil).Once()
+	usecase := NewMemberUsecase(repo)
+
+	// Happy Path
+	member, err := usecase.CreateNewMember(model.Member{Name: " Budi ", Email: "budi@example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, member.Id)
+	repo.AssertExpectations(t)
+
+	// Sad Path: input tidak valid
+	for _, member := range []model.Member{{Name: " ", Email: "budi@example.com"}, {Name: "Budi", Email: "budi"}, {Name: "Budi", Email: "Budi <budi@example.com>"}} {
+		_, err := usecase.CreateNewMember(member)
+
+		var validationErr *ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+	}
+}
+
+func TestMemberUsecase_GetMemberById(t *testing.T) {
+	repo := new(MockMemberRepository)
+	repo.On("GetMemberById", 1).Return(model.Member{Id: 1, Name: "Budi"}, nil).Once()
+	repo.On("GetMemberById", 2).Return(model.Member{}, sql.ErrNoRows).Once()
+	usecase := NewMemberUsecase(repo)
+
+	member, err := usecase.GetMemberById(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Budi", member.Name)
+
+	_, err = usecase.GetMemberById(2)
+	var notFoundErr *NotFoundError
+	assert.ErrorAs(t, err, &notFoundErr)
+}
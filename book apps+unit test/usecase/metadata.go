@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"errors"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/repositori"
+	"strings"
+)
+
+// lookupMetadata mencari ISBN-13 yang sudah dinormalisasi di source, lalu
+// padanan ISBN-10-nya karena banyak edition lama hanya tercatat dengan ISBN-10.
+func lookupMetadata(source repositori.MetadataSource, isbn string) (model.BookMetadata, error) {
+	isbn10 := isbn13To10(isbn)
+
+	metadata, err := source.LookupIsbn(isbn)
+	if errors.Is(err, repositori.ErrMetadataNotFound) && isbn10 != "" {
+		metadata, err = source.LookupIsbn(isbn10)
+	}
+	if err != nil {
+		return model.BookMetadata{}, err
+	}
+
+	metadata.Isbn = isbn
+	metadata.Isbn10 = isbn10
+	return metadata, nil
+}
+
+// enrichBook mengisi field buku yang masih kosong dari source berdasarkan
+// isbn-nya. Field yang sudah diisi client tidak pernah ditimpa. Buku tanpa
+// isbn yang valid atau yang tidak ditemukan di source dibiarkan apa adanya,
+// validateBook yang akan menolaknya jika datanya belum lengkap.
+func enrichBook(source repositori.MetadataSource, book *model.Book) error {
+	if source == nil {
+		return nil
+	}
+	if strings.TrimSpace(book.Title) != "" && strings.TrimSpace(book.Author) != "" && book.ReleaseYear != 0 && book.Pages != 0 {
+		return nil
+	}
+	isbn, err := normalizeIsbn(firstNonEmpty(book.Isbn, book.Isbn10))
+	if err != nil {
+		return nil
+	}
+
+	metadata, err := lookupMetadata(source, isbn)
+	if errors.Is(err, repositori.ErrMetadataNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(book.Title) == "" {
+		book.Title = metadata.Title
+	}
+	if strings.TrimSpace(book.Author) == "" {
+		book.Author = metadata.Author
+	}
+	if book.ReleaseYear == 0 {
+		book.ReleaseYear = metadata.ReleaseYear
+	}
+	if book.Pages == 0 {
+		book.Pages = metadata.Pages
+	}
+
+	return nil
+}
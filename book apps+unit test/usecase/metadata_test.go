@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"simple-clean-architecture/model"
+	"simple-clean-architecture/repositori"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMetadataSource struct {
+	mock.Mock
+}
+
+func (m *MockMetadataSource) LookupIsbn(isbn string) (model.BookMetadata, error) {
+	args := m.Called(isbn)
+	return args.Get(0).(model.BookMetadata), args.Error(1)
+}
+
+func TestEnrichBook(t *testing.T) {
+	source := new(MockMetadataSource)
+	// Happy Path: dump hanya mencatat ISBN-10, field yang sudah diisi tidak ditimpa
+	source.On("LookupIsbn", "9780134190440").Return(model.BookMetadata{}, repositori.ErrMetadataNotFound)
+	source.On("LookupIsbn", "0134190440").Return(model.BookMetadata{Title: "The Go Programming Language", Author: "Alan Donovan", ReleaseYear: 2015, Pages: 380}, nil)
+
+	book := model.Book{Isbn: "978-0-13-419044-0", Title: "Go"}
+	err := enrichBook(source, &book)
+	assert.NoError(t, err)
+	assert.Equal(t, model.Book{Isbn: "978-0-13-419044-0", Title: "Go", Author: "Alan Donovan", ReleaseYear: 2015, Pages: 380}, book)
+}
+
+func TestEnrichBook_Skipped(t *testing.T) {
+	source := new(MockMetadataSource)
+	source.On("LookupIsbn", mock.Anything).Return(model.BookMetadata{}, repositori.ErrMetadataNotFound)
+
+	// Sad Path: isbn tidak valid, tidak ditemukan, atau source tidak dipakai
+	book := model.Book{Isbn: "12345"}
+	assert.NoError(t, enrichBook(source, &book))
+	source.AssertNotCalled(t, "LookupIsbn", mock.Anything)
+
+	book = model.Book{Isbn: "9791000000008"}
+	assert.NoError(t, enrichBook(source, &book))
+	assert.Equal(t, model.Book{Isbn: "9791000000008"}, book)
+	source.AssertNumberOfCalls(t, "LookupIsbn", 1)
+
+	assert.NoError(t, enrichBook(nil, &book))
+}
+
+func TestEnrichBook_SourceError(t *testing.T) {
+	source := new(MockMetadataSource)
+	source.On("LookupIsbn", "9780134190440").Return(model.BookMetadata{}, errors.New("read error"))
+
+	book := model.Book{Isbn: "9780134190440"}
+	assert.EqualError(t, enrichBook(source, &book), "read error")
+}
+
+func TestBookUsecase_CreateNewBook_Enriched(t *testing.T) {
+	repo := new(MockBookRepository)
+	source := new(MockMetadataSource)
+	source.On("LookupIsbn", "9780134190440").Return(model.BookMetadata{Title: "The Go Programming Language", Author: "Alan Donovan", ReleaseYear: 2015, Pages: 380}, nil)
+	expected := model.Book{Isbn: "9780134190440", Isbn10: "0134190440", Title: "The Go Programming Language", Author: "Alan Donovan", ReleaseYear: 2015, Pages: 380}
+	repo.On("GetBookIdByIsbn", "9780134190440").Return(0, false, sql.ErrNoRows)
+	repo.On("CreateNewBook", expected, 0).Return(model.Book{Id: 1}, nil)
+
+	book, err := NewBookUsecase(repo, new(MockBookCopyRepository), new(MockAuthorRepository), new(MockCategoryRepository), source).CreateNewBook(model.Book{Isbn10: "0134190440"}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, book.Id)
+	repo.AssertExpectations(t)
+}
+
+func TestBookUsecase_LookupMetadata(t *testing.T) {
+	source := new(MockMetadataSource)
+	source.On("LookupIsbn", "9780134190440").Return(model.BookMetadata{Title: "The Go Programming Language"}, nil)
+	source.On("LookupIsbn", "9780804429573").Return(model.BookMetadata{}, repositori.ErrMetadataNotFound)
+	source.On("LookupIsbn", "080442957X").Return(model.BookMetadata{}, repositori.ErrMetadataNotFound)
+	usecase := NewBookUsecase(new(MockBookRepository), new(MockBookCopyRepository), new(MockAuthorRepository), new(MockCategoryRepository), source)
+
+	// Happy Path
+	metadata, err := usecase.LookupMetadata("0-13-419044-0")
+	assert.NoError(t, err)
+	assert.Equal(t, model.BookMetadata{Isbn: "9780134190440", Isbn10: "0134190440", Title: "The Go Programming Language"}, metadata)
+
+	// Sad Path: isbn tidak valid atau tidak ditemukan
+	var validationErr *ValidationError
+	_, err = usecase.LookupMetadata("12345")
+	assert.ErrorAs(t, err, &validationErr)
+
+	var notFoundErr *NotFoundError
+	_, err = usecase.LookupMetadata("080442957X")
+	assert.ErrorAs(t, err, &notFoundErr)
+
+	_, err = NewBookUsecase(new(MockBookRepository), new(MockBookCopyRepository), new(MockAuthorRepository), new(MockCategoryRepository), nil).LookupMetadata("9780134190440")
+	assert.ErrorAs(t, err, &notFoundErr)
+}
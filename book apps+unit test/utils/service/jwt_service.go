@@ -0,0 +1,78 @@
+package service
+
+import (
+	"fmt"
+	"simple-clean-architecture/config"
+	"simple-clean-architecture/model"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JwtClaims adalah isi token yang diberikan saat login.
+type JwtClaims struct {
+	UserId int    `json:"userId"`
+	Role   string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+type JWTService interface {
+	CreateToken(user model.User) (string, time.Time, error)
+	VerifyToken(tokenString string) (*JwtClaims, error)
+}
+
+type jwtService struct {
+	tokenConfig config.TokenConfig
+}
+
+func (j *jwtService) CreateToken(user model.User) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(j.tokenConfig.AccessTokenLifetime)
+
+	claims := JwtClaims{
+		UserId: user.Id,
+		Role:   user.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    j.tokenConfig.ApplicationName,
+			Subject:   strconv.Itoa(user.Id),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	tokenString, err := jwt.NewWithClaims(j.tokenConfig.JwtSigningMethod, claims).SignedString(j.tokenConfig.JwtSignatureKey)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return tokenString, expiresAt, nil
+}
+
+func (j *jwtService) VerifyToken(tokenString string) (*JwtClaims, error) {
+	claims := &JwtClaims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return j.tokenConfig.JwtSignatureKey, nil
+	}, jwt.WithValidMethods([]string{j.tokenConfig.JwtSigningMethod.Alg()}), jwt.WithIssuer(j.tokenConfig.ApplicationName))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	return claims, nil
+}
+
+func NewJWTService(tokenConfig config.TokenConfig) JWTService {
+	if tokenConfig.JwtSigningMethod == nil {
+		tokenConfig.JwtSigningMethod = jwt.SigningMethodHS256
+	}
+	if tokenConfig.AccessTokenLifetime == 0 {
+		tokenConfig.AccessTokenLifetime = time.Hour
+	}
+	return &jwtService{tokenConfig: tokenConfig}
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"simple-clean-architecture/config"
+	"simple-clean-architecture/model"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJWTService_CreateAndVerifyToken(t *testing.T) {
+	jwtService := NewJWTService(config.TokenConfig{ApplicationName: "test", JwtSignatureKey: []byte("secret"), AccessTokenLifetime: time.Minute})
+
+	// Happy Path
+	token, expiresAt, err := jwtService.CreateToken(model.User{Id: 1, Role: model.RoleLibrarian})
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), expiresAt, 2*time.Second)
+
+	claims, err := jwtService.VerifyToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, claims.UserId)
+	assert.Equal(t, model.RoleLibrarian, claims.Role)
+	assert.Equal(t, "test", claims.Issuer)
+
+	// Sad Path: token ditandatangani dengan key lain
+	other := NewJWTService(config.TokenConfig{ApplicationName: "test", JwtSignatureKey: []byte("other")})
+	_, err = other.VerifyToken(token)
+	assert.Error(t, err)
+
+	// Sad Path: token dari aplikasi lain
+	other = NewJWTService(config.TokenConfig{ApplicationName: "other", JwtSignatureKey: []byte("secret")})
+	_, err = other.VerifyToken(token)
+	assert.Error(t, err)
+
+	// Sad Path: token rusak
+	_, err = jwtService.VerifyToken("not-a-token")
+	assert.Error(t, err)
+}
+
+func TestJWTService_VerifyToken_Expired(t *testing.T) {
+	jwtService := NewJWTService(config.TokenConfig{ApplicationName: "test", JwtSignatureKey: []byte("secret")})
+
+	claims := JwtClaims{
+		UserId: 1,
+		Role:   model.RoleLibrarian,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "test",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+	assert.NoError(t, err)
+
+	_, err = jwtService.VerifyToken(token)
+	assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+}
+
+func TestJWTService_VerifyToken_RejectsNoneAlgorithm(t *testing.T) {
+	jwtService := NewJWTService(config.TokenConfig{ApplicationName: "test", JwtSignatureKey: []byte("secret")})
+
+	claims := JwtClaims{UserId: 1, Role: model.RoleLibrarian, RegisteredClaims: jwt.RegisteredClaims{Issuer: "test"}}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodNone, claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	assert.NoError(t, err)
+
+	_, err = jwtService.VerifyToken(token)
+	assert.Error(t, err)
+}